@@ -35,10 +35,10 @@ import (
 	"net/http"
 	"net/http/httputil"
 	neturl "net/url"
+	"os"
 	"time"
 
 	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
-	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
 
 	"github.com/andybalholm/brotli"
@@ -47,10 +47,18 @@ import (
 )
 
 type Config struct {
-	Debug   bool          `json:"debug" yaml:"debug"`
-	Timeout time.Duration `json:"timeout" yaml:"timeout"`
-	Retry   int           `json:"retry" yaml:"retry"`
-	Cookie  cookie.Config `json:"cookie" yaml:"cookie"`
+	Debug     bool            `json:"debug" yaml:"debug"`
+	Timeout   time.Duration   `json:"timeout" yaml:"timeout"`
+	Retry     int             `json:"retry" yaml:"retry"`
+	Cookie    cookie.Config   `json:"cookie" yaml:"cookie"`
+	Proxy     ProxyConfig     `json:"proxy" yaml:"proxy"`
+	RateLimit RateLimitConfig `json:"ratelimit" yaml:"ratelimit"`
+	Backoff   BackoffConfig   `json:"backoff" yaml:"backoff"`
+	Device    DeviceConfig    `json:"device" yaml:"device"`
+	// DryRun 会修改账号数据的接口只输出请求内容不实际发送,由命令行参数指定
+	DryRun bool `json:"-" yaml:"-"`
+	// Observer 接口调用指标观察者,为空则不统计,由守护进程等长期运行的命令设置
+	Observer Observer `json:"-" yaml:"-"`
 	// Agent   *Agent                     `json:"agent" yaml:"agent"`
 }
 
@@ -61,14 +69,33 @@ func (c *Config) Validate() error {
 	if c.Timeout < 0 {
 		return errors.New("timeout is < 0")
 	}
+	if err := c.Proxy.Validate(); err != nil {
+		return fmt.Errorf("proxy: %w", err)
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("ratelimit: %w", err)
+	}
+	if err := c.Backoff.Validate(); err != nil {
+		return fmt.Errorf("backoff: %w", err)
+	}
+	if err := c.Device.Validate(); err != nil {
+		return fmt.Errorf("device: %w", err)
+	}
 	return nil
 }
 
 type Client struct {
-	cfg    *Config
-	cli    *resty.Client
-	cookie *cookie.Cookie
-	l      *log.Logger
+	cfg         *Config
+	cli         *resty.Client
+	cookie      *cookie.Cookie
+	l           *log.Logger
+	middlewares []Middleware
+	handler     Handler
+	relogin     func(ctx context.Context) error
+	transport   http.RoundTripper
+	device      *device
+	onChallenge ChallengeHandler
+	dryRun      io.Writer // dry-run模式下请求内容的输出位置
 	// agent  *Agent
 }
 
@@ -98,14 +125,30 @@ func NewClient(cfg *Config, l *log.Logger) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("NewCookie: %w", err)
 	}
+	return newClient(cfg, l, jar, os.Stderr)
+}
 
+// newClient 使用指定的cookie存储创建客户端,dryRun 为dry-run模式下请求内容的输出位置
+func newClient(cfg *Config, l *log.Logger, jar *cookie.Cookie, dryRun io.Writer) (*Client, error) {
 	cli := resty.New()
-	cli.SetRetryCount(cfg.Retry)
+	// 启用重试中间件时由其负责重试,避免与resty的重试次数叠加
+	if cfg.Backoff.Attempts > 0 {
+		cli.SetRetryCount(0)
+	} else {
+		cli.SetRetryCount(cfg.Retry)
+	}
 	cli.SetTimeout(cfg.Timeout)
 	cli.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
 	cli.SetDebug(cfg.Debug)
 	cli.SetCookieJar(jar)
 	cli.OnAfterResponse(contentEncoding)
+	if proxy, _ := cfg.Proxy.Func(); proxy != nil {
+		transport, ok := cli.GetClient().Transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("unexpected transport type: %T", cli.GetClient().Transport)
+		}
+		transport.Proxy = proxy
+	}
 	// cli.OnAfterResponse(dump)
 	// cli.OnBeforeRequest(encrypt)
 	// cli.SetLogger(l)
@@ -115,15 +158,46 @@ func NewClient(cfg *Config, l *log.Logger) (*Client, error) {
 	// })
 
 	c := Client{
-		cfg:    cfg,
-		cli:    cli,
-		cookie: jar,
-		l:      l,
+		cfg:       cfg,
+		cli:       cli,
+		cookie:    jar,
+		l:         l,
+		transport: cli.GetClient().Transport,
+		dryRun:    dryRun,
 		// agent:  NewAgent(),
 	}
+	cli.SetTransport(RoundTripFunc(c.roundTrip))
+	c.Use(Tracing(), LoggingWith(l), c.backoff(), c.challenge(), CodeError())
+	if cfg.DryRun {
+		if c.dryRun == nil {
+			c.dryRun = os.Stderr
+		}
+		c.Use(DryRun(c.dryRun))
+	}
+	if cfg.Observer != nil {
+		c.Use(Observe(cfg.Observer))
+	}
+	if cfg.RateLimit.Enable() {
+		c.Use(Limit(cfg.RateLimit))
+	}
+	if cfg.Device.Filepath != "" {
+		d, err := LoadDevice(cfg.Device)
+		if err != nil {
+			return nil, fmt.Errorf("LoadDevice: %w", err)
+		}
+		c.device = &device{cfg: cfg.Device, d: d}
+		c.Intercept(c.device.intercept)
+	}
 	return &c, nil
 }
 
+// Use 添加接口调用中间件,先添加的中间件位于最外层。
+// 需要在发起请求之前调用,不支持并发调用。
+func (c *Client) Use(m ...Middleware) {
+	c.middlewares = append(c.middlewares, m...)
+	c.handler = Chain(c.middlewares...)(c.do)
+}
+
 func (c *Client) Ping(ctx context.Context) error {
 	return nil
 }
@@ -133,6 +207,11 @@ func (c *Client) Close(ctx context.Context) error {
 	return c.cookie.Close(ctx)
 }
 
+// Logger 返回客户端使用的日志对象,为空时日志输出到 log.Default
+func (c *Client) Logger() *log.Logger {
+	return c.l
+}
+
 func (c *Client) NewRequest() *resty.Request {
 	return c.cli.NewRequest()
 }
@@ -145,7 +224,7 @@ func (c *Client) GetClient() *http.Client {
 func (c *Client) Cookie(url, name string) (http.Cookie, bool) {
 	uri, err := neturl.Parse(url)
 	if err != nil {
-		log.Warn("cookie parse(%v) err: ", url, err)
+		c.l.Warn("cookie parse(%v) err: %s", url, err)
 		return http.Cookie{}, false
 	}
 	for _, c := range c.cookie.Cookies(uri) {
@@ -161,6 +240,11 @@ func (c *Client) GetCookies(url *neturl.URL) []*http.Cookie {
 	return c.cookie.Cookies(url)
 }
 
+// ExportCookies 导出所有cookie,包含完整的cookie属性
+func (c *Client) ExportCookies() []*http.Cookie {
+	return c.cookie.All()
+}
+
 // SetCookies 设置cookies
 func (c *Client) SetCookies(url *neturl.URL, cookies []*http.Cookie) {
 	c.cookie.SetCookies(url, cookies)
@@ -170,7 +254,7 @@ func (c *Client) SetCookies(url *neturl.URL, cookies []*http.Cookie) {
 func (c *Client) GetCSRF(url string) (string, bool) {
 	uri, err := neturl.Parse(url)
 	if err != nil {
-		log.Warn("GetCSRF parse(%v) err: %s", url, err)
+		c.l.Warn("GetCSRF parse(%v) err: %s", url, err)
 		return "", false
 	}
 	for _, c := range c.cookie.Cookies(uri) {
@@ -184,7 +268,7 @@ func (c *Client) GetCSRF(url string) (string, bool) {
 	return "", false
 }
 
-// Request 接口请求
+// Request 接口请求,请求会依次经过 Use 添加的中间件
 func (c *Client) Request(ctx context.Context, url string, req, resp interface{}, opts *Options) (*resty.Response, error) {
 	if url == "" || req == nil || resp == nil {
 		return nil, errors.New("request args invalid")
@@ -195,6 +279,22 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 	if opts.Method == "" {
 		opts.Method = http.MethodPost
 	}
+	var handler = c.handler
+	if handler == nil {
+		handler = c.do
+	}
+	return handler(ctx, &Call{Url: url, Req: req, Resp: resp, Opts: opts})
+}
+
+// do 执行接口请求,包含参数加密、响应解密以及解析
+func (c *Client) do(ctx context.Context, call *Call) (*resty.Response, error) {
+	var (
+		url   = call.Url
+		req   = call.Req
+		resp  = call.Resp
+		opts  = call.Opts
+		trace = TraceId(ctx)
+	)
 
 	var (
 		encryptData map[string]string
@@ -202,8 +302,7 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 		response    *resty.Response
 	)
 
-	uri, err := neturl.Parse(url)
-	if err != nil {
+	if _, err := neturl.Parse(url); err != nil {
 		return nil, err
 	}
 
@@ -218,7 +317,7 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 		SetHeader("Content-Type", "application/x-www-form-urlencoded").
 		SetHeader("Accept-language", "zh-CN,zh-Hans;q=0.9").
 		SetHeader("Referer", "https://music.163.com").
-		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034").
+		SetHeader("User-Agent", defaultUserAgent).
 		SetCookie(&http.Cookie{Name: "__remember_me", Value: "true", Domain: ""})
 	// SetHeader("User-Agent", "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/25.1 Chrome/121.0.0.0 Mobile Safari/537.36")
 
@@ -230,91 +329,15 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 		request.SetCookies(opts.Cookies)
 	}
 
-	switch opts.CryptoMode {
-	case CryptoModeEAPI:
-		// todo: set common params
-		// var dataHeader = http.Header{}
-		// dataHeader.Add("osver", getCookie(options.cookies, "osver"))
-		// dataHeader.Add("deviceId", getCookie(options.cookies, "deviceId"))
-		// dataHeader.Add("appver", getCookie(options.cookies, "appver", "6.1.1"))
-		// dataHeader.Add("versioncode", getCookie(options.cookies, "versioncode", "140"))
-		// dataHeader.Add("mobilename", getCookie(options.cookies, "mobilename"))
-		// dataHeader.Add("buildver", getCookie(options.cookies, "buildver"))
-		// dataHeader.Add("resolution", getCookie(options.cookies, "resolution", "1920x1080"))
-		// dataHeader.Add("__csrf", getCookie(options.cookies, "__csrf"))
-		// dataHeader.Add("os", getCookie(options.cookies, "os", "android"))
-		// dataHeader.Add("channel", getCookie(options.cookies, "channel"))
-		// dataHeader.Add("requestId", fmt.Sprintf("%d_%04d", time.Now().UnixNano()/1000000, r.Intn(1000)))
-		// if c := getCookie(options.cookies, "MUSIC_U"); c != "" {
-		// 	dataHeader.Add("MUSIC_U", c)
-		// }
-		// if c := getCookie(options.cookies, "MUSIC_A"); c != "" {
-		// 	dataHeader.Add("MUSIC_A", c)
-		// }
-		// req.Header.Set("Cookie", "")
-		// for k, v := range dataHeader {
-		// 	req.AddCookie(&http.Cookie{
-		// 		Name:  k,
-		// 		Value: v[0],
-		// 	})
-		// }
-		// data["header"] = dataHeader
-
-		encryptData, err = crypto.EApiEncrypt(uri.Path, req)
-		if err != nil {
-			return nil, fmt.Errorf("EApiEncrypt: %w", err)
-		}
-	case CryptoModeWEAPI:
-		// todo: 需要替换？因为有些 https://interface.music.163.com/api 得接口也会走这个逻辑
-		// reg, _ := regexp.Compile(`\w*api`)
-		// url = reg.ReplaceAllString(url, "weapi")
-		// url = strings.ReplaceAll(url, "api", "weapi")
-
-		csrf, has := c.GetCSRF(url)
-		if !has {
-			log.Debug("get csrf token not found")
-		}
-		request.SetQueryParam("csrf_token", csrf)
-
-		// // request.SetCookie(&http.Cookie{Name: "appver", Value: "2.3.17"})
-		// request.SetCookie(&http.Cookie{Name: "appver", Value: "9.0.95"})
-		// // request.SetCookie(&http.Cookie{Name: "os", Value: "osx"})
-		// request.SetCookie(&http.Cookie{Name: "os", Value: "android"})
-		// // request.SetCookie(&http.Cookie{Name: "deviceId", Value: "7A8EB581-E60B-5230-BB5B-E6DAB1FBFA62%7C5FD718A3-0602-4389-B612-EBEFAA7F108B"})
-		// // request.SetCookie(&http.Cookie{Name: "WEVNSM", Value: "1.0.0"})
-		// // request.SetCookie(&http.Cookie{Name: "channel", Value: "netease"})
-		// // request.SetHeader("nm-gcore-status", "1")
-		// request.SetHeader("appver", "9.0.95")
-		// request.SetHeader("os", "android")
-
-		encryptData, err = crypto.WeApiEncrypt(req)
-		if err != nil {
-			return nil, fmt.Errorf("WeApiEncrypt: %w", err)
-		}
-	case CryptoModeLinux:
-		encryptData, err = crypto.LinuxApiEncrypt(req)
-		if err != nil {
-			return nil, fmt.Errorf("LinuxApiEncrypt: %w", err)
-		}
-	case CryptoModeAPI:
-		// 不需要加密处理请求
-		// todo: 待处理,在/api/xx/接口请求时则不需要参数加密处理,此处需要对结构体转换成map[string]string类型
-		b, err := json.Marshal(req)
-		if err != nil {
-			return nil, fmt.Errorf("json.Marshal: %w", err)
-		}
-		var m map[string]interface{}
-		if err := json.Unmarshal(b, &m); err != nil {
-			return nil, fmt.Errorf("json.Unmarshal: %w", err)
-		}
-		encryptData = make(map[string]string)
-		for k, v := range m {
-			encryptData[k] = fmt.Sprint(v)
-		}
-	default:
+	codec, ok := GetCodec(opts.CryptoMode)
+	if !ok {
 		return nil, fmt.Errorf("%s crypto mode unknown", opts.CryptoMode)
 	}
-	log.Debug("[request]: %+v encrypt: %+v", req, encryptData)
+	url, encryptData, err = codec.Encode(c, call, request)
+	if err != nil {
+		return nil, err
+	}
+	c.l.Debug("[request] trace=%s url=%s req=%+v encrypt=%+v", trace, url, req, encryptData)
 
 	switch opts.Method {
 	case http.MethodPost:
@@ -327,47 +350,37 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
-	log.Debug("[response.raw]: %s", string(response.Body()))
-
-	var decryptData []byte
-	switch opts.CryptoMode {
-	case CryptoModeAPI:
-		// tips: api接口返回数据是明文
-		decryptData = response.Body()
-	case CryptoModeEAPI:
-		// TODO: 貌似eapi接口返回数据是否是是明文,跟传入参数e_r: true有关,true为加密，false为明文。此处考虑采用反射req中得字段处理。
-		// see: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/commit/58e9865b70e41197c2ab75c46a775fc45d6efa6e
-		// decryptData, err = crypto.EApiDecrypt(string(response.Body()), "")
-		// if err != nil {
-		// 	return nil, fmt.Errorf("EApiDecrypt: %w", err)
-		// }
-		decryptData = response.Body()
-		log.Debug("[response.decrypt]: %s", string(decryptData))
-	case CryptoModeWEAPI:
-		// tips: weapi接口返回数据是明文
-		decryptData = response.Body()
-	case CryptoModeLinux:
-		decryptData, err = crypto.LinuxApiDecrypt(string(response.Body()))
-		if err != nil {
-			return nil, fmt.Errorf("LinuxApiDecrypt: %w", err)
-		}
-		log.Debug("[response.decrypt]: %s", string(decryptData))
-	default:
-		return nil, fmt.Errorf("%s crypto mode unknown", opts.CryptoMode)
+	c.l.Debug("[response.raw] trace=%s status=%d body=%s", trace, response.StatusCode(), string(response.Body()))
+
+	decryptData, err := codec.Decode(response.Body())
+	if err != nil {
+		return nil, err
 	}
+	if !bytes.Equal(decryptData, response.Body()) {
+		c.l.Debug("[response.decrypt] trace=%s body=%s", trace, string(decryptData))
+	}
+	call.Body = decryptData
 
+	if response.StatusCode() != http.StatusOK {
+		return response, fmt.Errorf("http status code: %d detail: %s", response.StatusCode(), string(decryptData))
+	}
 	decode := json.NewDecoder(bytes.NewReader(decryptData))
 	// decode.DisallowUnknownFields()
 	if err := decode.Decode(&resp); err != nil {
 		return nil, fmt.Errorf("json.NewDecoder: %w", err)
 	}
-	if response.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("http status code: %d detail: %s", response.StatusCode(), string(decryptData))
-	}
 	return response, nil
 }
 
 func (c *Client) Upload(ctx context.Context, url string, headers map[string]string, data io.Reader, resp interface{}, bar *pb.ProgressBar) (*resty.Response, error) {
+	ctx, trace := ensureTraceId(ctx)
+	if c.cfg.DryRun {
+		fmt.Fprintf(c.dryRun, "[dry-run] POST %s upload\n%v\n", url, headers)
+		if err := json.Unmarshal(dryRunResp, &resp); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		return &resty.Response{}, nil
+	}
 	var body any = data
 	if bar != nil {
 		body = bar.NewProxyReader(data)
@@ -379,13 +392,14 @@ func (c *Client) Upload(ctx context.Context, url string, headers map[string]stri
 		SetHeader("Connection", "keep-alive").
 		SetHeader("Accept", "*/*").
 		SetHeader("Referer", "https://music.163.com").
-		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034").
+		SetHeader("User-Agent", defaultUserAgent).
 		SetBody(body).
 		Post(url)
 	if err != nil {
+		c.l.Warn("[upload] trace=%s url=%s err=%s", trace, url, err)
 		return nil, err
 	}
-	log.Debug("response: %+v", string(response.Body()))
+	c.l.Debug("[upload] trace=%s url=%s status=%d response=%s", trace, url, response.StatusCode(), string(response.Body()))
 	if err := json.Unmarshal(response.Body(), &resp); err != nil {
 		return nil, fmt.Errorf("json.Unmarshal: %w", err)
 	}
@@ -395,7 +409,21 @@ func (c *Client) Upload(ctx context.Context, url string, headers map[string]stri
 	return response, nil
 }
 
+// ErrStalled 下载过程中超过请求超时时间未收到任何数据
+var ErrStalled = errors.New("transfer stalled")
+
+// Download 下载文件写入resp. 下载耗时与文件大小相关,因此不受请求超时时间限制,
+// 而是超过请求超时时间未收到数据时中止并返回 ErrStalled,避免连接停滞时一直等待
 func (c *Client) Download(ctx context.Context, url string, headers map[string]string, reqBody io.Reader, resp io.Writer, bar *pb.ProgressBar) (*http.Response, error) {
+	ctx, trace := ensureTraceId(ctx)
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var timer *time.Timer
+	if c.cfg.Timeout > 0 {
+		timer = time.AfterFunc(c.cfg.Timeout, func() { cancel(ErrStalled) })
+		defer timer.Stop()
+	}
+
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("NewRequestWithContext: %w", err)
@@ -405,28 +433,41 @@ func (c *Client) Download(ctx context.Context, url string, headers map[string]st
 	request.Header.Set("Referer", "https://music.163.com")
 	request.Header.Set("Accept-Encoding", "gzip")
 	request.Header.Set("Accept-Language", "zh-CN,zh-Hans;q=0.9")
-	request.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034")
+	request.Header.Set("User-Agent", defaultUserAgent)
 	request.Header.Set("Range", "bytes=0-")
 	for k, v := range headers {
 		request.Header.Set(k, v)
 	}
 
-	response, err := c.cli.GetClient().Do(request)
+	var client = &http.Client{Transport: c.cli.GetClient().Transport, Jar: c.cli.GetClient().Jar}
+	response, err := client.Do(request)
 	if err != nil {
+		c.l.Warn("[download] trace=%s url=%s err=%s", trace, url, err)
+		if cause := context.Cause(ctx); errors.Is(cause, ErrStalled) {
+			return nil, fmt.Errorf("download trace=%s: %w", trace, cause)
+		}
 		return nil, err
 	}
 	defer response.Body.Close()
+	c.l.Debug("[download] trace=%s url=%s status=%d size=%d", trace, url, response.StatusCode, response.ContentLength)
 
 	if response.StatusCode/100 != 2 {
 		return nil, fmt.Errorf("http status code: %d", response.StatusCode)
 	}
 
 	var body io.Reader = response.Body
+	if timer != nil {
+		body = &stallReader{r: body, timer: timer, timeout: c.cfg.Timeout}
+	}
 	if bar != nil {
-		body = bar.NewProxyReader(response.Body)
+		body = bar.NewProxyReader(body)
 	}
 	n, err := io.Copy(resp, body)
 	if err != nil {
+		// 读取响应体过程中ctx超时、取消或停滞时返回对应的错误,便于调用方判断
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("download trace=%s: %w", trace, context.Cause(ctx))
+		}
 		return nil, err
 	}
 	if n != response.ContentLength {
@@ -435,6 +476,21 @@ func (c *Client) Download(ctx context.Context, url string, headers map[string]st
 	return response, nil
 }
 
+// stallReader 每次读取到数据时重置计时器,计时器到期说明传输停滞
+type stallReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if n > 0 {
+		s.timer.Reset(s.timeout)
+	}
+	return n, err
+}
+
 func contentEncoding(c *resty.Client, resp *resty.Response) error {
 	var kind = resp.Header().Get("Content-Encoding")
 	// log.Debug("Content-Encoding: %s Uncompressed: %v", kind, resp.RawResponse.Uncompressed)
@@ -37,7 +37,7 @@ type PlaylistDetailReq struct {
 }
 
 type PlaylistDetailResp struct {
-	types.ApiRespCommon[any]
+	types.ApiRespCommon[types.Empty]
 	RelatedVideos interface{} `json:"relatedVideos"`
 	Playlist      struct {
 		Id                    int64         `json:"id"`
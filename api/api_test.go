@@ -22,3 +22,18 @@
 //
 
 package api
+
+import (
+	"os"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+)
+
+func TestMain(m *testing.M) {
+	log.Default = log.New(&log.Config{
+		Level:  "debug",
+		Stdout: true,
+	})
+	os.Exit(m.Run())
+}
@@ -0,0 +1,177 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package apitest 为接口封装提供离线测试能力,通过录制(record)真实接口的请求及响应生成cassette文件,
+// 之后基于 httptest 回放(replay)。录制及回放均作用于加密之前的请求参数以及解密之后的响应内容,
+// 因此 weapi、eapi、linux 等协议的加解密对cassette透明,新增接口时可直接编写golden文件测试:
+//
+//	func TestSongPlayer(t *testing.T) {
+//		cli := weapi.New(apitest.Client(t, "testdata/song_player.json"))
+//		reply, err := cli.SongPlayer(ctx, &weapi.SongPlayerReq{Ids: types.IntsString{2115747785}, Br: "128000"})
+//		...
+//	}
+//
+// 默认回放cassette,设置环境变量 NCM_RECORD=1 时请求真实接口并覆盖cassette,NCM_COOKIE 指定录制时使用的cookie文件。
+// 注意: 录制的响应中可能包含账号信息,提交前需自行检查。
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+)
+
+const (
+	// EnvRecord 值为1时请求真实接口并录制cassette
+	EnvRecord = "NCM_RECORD"
+	// EnvCookie 录制时使用的cookie文件,为空则不登录
+	EnvCookie = "NCM_COOKIE"
+)
+
+// IgnoreFields 录制时从请求参数中去除、回放时不参与比较的字段,通常是与登录状态相关的值
+var IgnoreFields = []string{"csrf_token"}
+
+// Interaction 一次接口调用的请求及响应
+type Interaction struct {
+	Method   string          `json:"method"`
+	Url      string          `json:"url"`
+	Crypto   api.CryptoMode  `json:"crypto"`
+	Request  json.RawMessage `json:"request"`
+	Status   int             `json:"status,omitempty"` // 为空时为200
+	Response json.RawMessage `json:"response"`
+	// Raw 响应内容不是json,Response 为json字符串
+	Raw bool `json:"raw,omitempty"`
+}
+
+// body 返回响应内容
+func (i *Interaction) body() ([]byte, error) {
+	if !i.Raw {
+		return i.Response, nil
+	}
+	var s string
+	if err := json.Unmarshal(i.Response, &s); err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// Cassette 按调用顺序保存的接口请求及响应
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Load 读取cassette文件
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return &c, nil
+}
+
+// Save 保存cassette文件,目录不存在时自动创建
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Client 创建用于测试的客户端,默认回放path中的cassette,
+// 设置环境变量 NCM_RECORD=1 时请求真实接口,测试结束后将录制内容写入path。
+// 回放时cassette中存在未被调用的接口则测试失败。
+func Client(t testing.TB, path string, opts ...api.ClientOption) *api.Client {
+	t.Helper()
+
+	if os.Getenv(EnvRecord) == "1" {
+		var r Recorder
+		if cookie := os.Getenv(EnvCookie); cookie != "" {
+			opts = append([]api.ClientOption{api.WithCookieFile(cookie)}, opts...)
+		}
+		cli, err := api.NewWithOptions(append(opts, api.WithMiddleware(r.Middleware()))...)
+		if err != nil {
+			t.Fatalf("apitest: NewWithOptions: %s", err)
+		}
+		t.Cleanup(func() {
+			_ = cli.Close(context.Background())
+			if err := r.Cassette().Save(path); err != nil {
+				t.Errorf("apitest: save %s: %s", path, err)
+				return
+			}
+			t.Logf("apitest: recorded %d interactions to %s", len(r.Cassette().Interactions), path)
+		})
+		return cli
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("apitest: load cassette: %s (run with %s=1 to record)", err, EnvRecord)
+	}
+	p := NewPlayer(c)
+	opts = append([]api.ClientOption{api.WithRateLimit(0, 0), api.WithRetry(0)}, opts...)
+	cli, err := api.NewWithOptions(append(opts, api.WithMiddleware(p.Middleware()))...)
+	if err != nil {
+		p.Close()
+		t.Fatalf("apitest: NewWithOptions: %s", err)
+	}
+	cli.Intercept(p.Intercept)
+	t.Cleanup(func() {
+		_ = cli.Close(context.Background())
+		p.Close()
+		if n := p.Remaining(); n > 0 {
+			t.Errorf("apitest: %d interactions in %s were not called", n, path)
+		}
+	})
+	return cli
+}
+
+// normalize 去除 IgnoreFields 中的字段并统一json格式,便于比较
+func normalize(data []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		for _, field := range IgnoreFields {
+			delete(m, field)
+		}
+	}
+	return json.Marshal(v)
+}
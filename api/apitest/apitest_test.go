@@ -0,0 +1,132 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package apitest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reply struct {
+	Code int64  `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func TestRecordReplay(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(t.TempDir(), "cassette.json")
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		_, _ = w.Write([]byte(`{"code":200,"msg":"` + r.Form.Get("id") + `"}`))
+	}))
+	defer srv.Close()
+
+	// 录制
+	var r Recorder
+	cli, err := api.NewWithOptions(api.WithMiddleware(r.Middleware()))
+	assert.NoError(t, err)
+	var (
+		got  reply
+		opts = api.NewOptions()
+	)
+	opts.CryptoMode = api.CryptoModeAPI
+	_, err = cli.Request(ctx, srv.URL+"/api/test", map[string]string{"id": "1", "csrf_token": "secret"}, &got, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", got.Msg)
+	assert.NoError(t, cli.Close(ctx))
+	assert.NoError(t, r.Cassette().Save(path))
+
+	c, err := Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, c.Interactions, 1)
+	assert.JSONEq(t, `{"id":"1"}`, string(c.Interactions[0].Request))
+	assert.JSONEq(t, `{"code":200,"msg":"1"}`, string(c.Interactions[0].Response))
+
+	// 回放,上游服务关闭后仍然可以得到相同的响应
+	srv.Close()
+	var played reply
+	cli = Client(t, path)
+	_, err = cli.Request(ctx, srv.URL+"/api/test", map[string]string{"id": "1", "csrf_token": "other"}, &played, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, got, played)
+}
+
+func TestReplayCrypto(t *testing.T) {
+	var c = Cassette{Interactions: []*Interaction{
+		{Method: http.MethodPost, Url: "https://music.163.com/weapi/test", Crypto: api.CryptoModeWEAPI, Request: []byte(`{"id":1}`), Response: []byte(`{"code":200,"msg":"weapi"}`)},
+		{Method: http.MethodPost, Url: "https://interface.music.163.com/eapi/test", Crypto: api.CryptoModeEAPI, Request: []byte(`{"id":2}`), Response: []byte(`{"code":200,"msg":"eapi"}`)},
+		{Method: http.MethodPost, Url: "https://music.163.com/weapi/test", Crypto: api.CryptoModeLinux, Request: []byte(`{"id":3}`), Response: []byte(`{"code":200,"msg":"linux"}`)},
+	}}
+	var path = filepath.Join(t.TempDir(), "cassette.json")
+	assert.NoError(t, c.Save(path))
+
+	var (
+		ctx = context.Background()
+		cli = Client(t, path)
+	)
+	for n, i := range c.Interactions {
+		var (
+			got  reply
+			opts = api.NewOptions()
+		)
+		opts.CryptoMode = i.Crypto
+		_, err := cli.Request(ctx, i.Url, map[string]int{"id": n + 1}, &got, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, string(i.Crypto), got.Msg)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	var p = NewPlayer(&Cassette{Interactions: []*Interaction{
+		{Method: http.MethodPost, Url: "https://music.163.com/weapi/test", Crypto: api.CryptoModeWEAPI, Request: []byte(`{"id":1}`), Response: []byte(`{"code":200}`)},
+	}})
+	defer p.Close()
+
+	cli, err := api.NewWithOptions(api.WithMiddleware(p.Middleware()))
+	assert.NoError(t, err)
+	defer cli.Close(context.Background())
+	cli.Intercept(p.Intercept)
+
+	var got reply
+	_, err = cli.Request(context.Background(), "https://music.163.com/weapi/test", map[string]int{"id": 2}, &got, api.NewOptions())
+	assert.ErrorContains(t, err, "request mismatch")
+	_, err = cli.Request(context.Background(), "https://music.163.com/weapi/other", map[string]int{"id": 1}, &got, api.NewOptions())
+	assert.ErrorContains(t, err, "want POST")
+	assert.Equal(t, 1, p.Remaining())
+
+	_, err = cli.Request(context.Background(), "https://music.163.com/weapi/test", map[string]int{"id": 1}, &got, api.NewOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, p.Remaining())
+	_, err = cli.Request(context.Background(), "https://music.163.com/weapi/test", map[string]int{"id": 1}, &got, api.NewOptions())
+	assert.ErrorContains(t, err, "cassette exhausted")
+}
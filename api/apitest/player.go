@@ -0,0 +1,162 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type indexKey struct{}
+
+// Player 基于 httptest 回放cassette。中间件按顺序取出录制内容并校验请求参数,
+// 拦截器将加密后的http请求转发到本地测试服务,由测试服务返回录制的响应,
+// 因此回放时仍会经过请求加密、响应解密以及解析的完整流程。
+type Player struct {
+	cassette *Cassette
+	srv      *httptest.Server
+	mu       sync.Mutex
+	next     int
+}
+
+// NewPlayer 创建回放器并启动测试服务,使用完毕后需要调用 Close
+func NewPlayer(c *Cassette) *Player {
+	p := Player{cassette: c}
+	p.srv = httptest.NewServer(http.HandlerFunc(p.serve))
+	return &p
+}
+
+// Close 关闭测试服务
+func (p *Player) Close() {
+	p.srv.Close()
+}
+
+// Remaining 返回尚未被调用的录制内容数量
+func (p *Player) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.cassette.Interactions) - p.next
+}
+
+// Middleware 返回回放中间件,按顺序匹配录制内容,请求地址、方法、加密方式或参数不一致时返回错误
+func (p *Player) Middleware() api.Middleware {
+	return func(next api.Handler) api.Handler {
+		return func(ctx context.Context, call *api.Call) (*resty.Response, error) {
+			index, err := p.match(call)
+			if err != nil {
+				return nil, err
+			}
+			return next(context.WithValue(ctx, indexKey{}, index), call)
+		}
+	}
+}
+
+func (p *Player) match(call *api.Call) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next >= len(p.cassette.Interactions) {
+		return 0, fmt.Errorf("apitest: unexpected call %s %s, cassette exhausted", call.Opts.Method, call.Url)
+	}
+	var (
+		index = p.next
+		i     = p.cassette.Interactions[index]
+	)
+	if i.Method != call.Opts.Method || i.Url != call.Url || i.Crypto != call.Opts.CryptoMode {
+		return 0, fmt.Errorf("apitest: interaction %d want %s %s (%s), got %s %s (%s)",
+			index, i.Method, i.Url, i.Crypto, call.Opts.Method, call.Url, call.Opts.CryptoMode)
+	}
+
+	got, err := json.Marshal(call.Req)
+	if err != nil {
+		return 0, fmt.Errorf("json.Marshal: %w", err)
+	}
+	if got, err = normalize(got); err != nil {
+		return 0, err
+	}
+	want, err := normalize(i.Request)
+	if err != nil {
+		return 0, fmt.Errorf("apitest: interaction %d request: %w", index, err)
+	}
+	if !bytes.Equal(got, want) {
+		return 0, fmt.Errorf("apitest: interaction %d %s request mismatch\nwant: %s\ngot:  %s", index, call.Url, want, got)
+	}
+	p.next++
+	return index, nil
+}
+
+// Intercept http请求拦截器,将请求转发到测试服务,需要通过 api.Client.Intercept 添加到客户端中
+func (p *Player) Intercept(next api.RoundTripFunc) api.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		index, ok := req.Context().Value(indexKey{}).(int)
+		if !ok {
+			return nil, errors.New("apitest: only requests made by api.Client.Request can be replayed")
+		}
+		uri, err := neturl.Parse(p.srv.URL + "/" + strconv.Itoa(index))
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.URL = uri
+		req.Host = uri.Host
+		return next(req)
+	}
+}
+
+func (p *Player) serve(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+	if err != nil || index < 0 || index >= len(p.cassette.Interactions) {
+		http.Error(w, "apitest: unknown interaction", http.StatusNotFound)
+		return
+	}
+	var i = p.cassette.Interactions[index]
+	if r.Method != i.Method {
+		http.Error(w, fmt.Sprintf("apitest: want method %s got %s", i.Method, r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := i.body()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("apitest: interaction %d response: %s", index, err), http.StatusInternalServerError)
+		return
+	}
+	var status = i.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,96 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package apitest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Recorder 录制接口调用的中间件,记录加密之前的请求参数以及解密之后的响应内容
+type Recorder struct {
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// Middleware 返回录制中间件,需要通过 api.WithMiddleware 添加到客户端中
+func (r *Recorder) Middleware() api.Middleware {
+	return func(next api.Handler) api.Handler {
+		return func(ctx context.Context, call *api.Call) (*resty.Response, error) {
+			resp, err := next(ctx, call)
+			if call.Body == nil {
+				return resp, err
+			}
+			if e := r.record(call, resp); e != nil {
+				return resp, fmt.Errorf("apitest: record: %w", e)
+			}
+			return resp, err
+		}
+	}
+}
+
+func (r *Recorder) record(call *api.Call, resp *resty.Response) error {
+	req, err := json.Marshal(call.Req)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if req, err = normalize(req); err != nil {
+		return err
+	}
+
+	var i = Interaction{
+		Method:   call.Opts.Method,
+		Url:      call.Url,
+		Crypto:   call.Opts.CryptoMode,
+		Request:  req,
+		Status:   http.StatusOK,
+		Response: call.Body,
+	}
+	if resp != nil && resp.RawResponse != nil {
+		i.Status = resp.StatusCode()
+	}
+	if !json.Valid(call.Body) {
+		i.Raw = true
+		i.Response, _ = json.Marshal(string(call.Body))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &i)
+	return nil
+}
+
+// Cassette 返回已录制的内容
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]*Interaction(nil), r.cassette.Interactions...)}
+}
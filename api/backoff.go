@@ -0,0 +1,186 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptrace"
+	neturl "net/url"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// BackoffConfig 接口调用失败重试配置,针对网络错误、http 5xx以及指定的业务返回码按指数退避重试。
+// 设置了 Options.Mutating 的接口不是幂等的,网络错误及5xx只有在请求未发送到服务端时才重试,避免重复提交。
+// 启用后客户端的 Config.Retry 不再生效。可通过 Options.Backoff 覆盖单个请求的重试配置。
+type BackoffConfig struct {
+	// Attempts 最大重试次数,0表示不重试
+	Attempts int `json:"attempts" yaml:"attempts"`
+	// Min 首次重试间隔,之后每次重试间隔翻倍
+	Min time.Duration `json:"min" yaml:"min"`
+	// Max 最大重试间隔,0表示不限制
+	Max time.Duration `json:"max" yaml:"max"`
+	// Codes 需要重试的业务返回码 eg: -460、405
+	Codes []int64 `json:"codes" yaml:"codes"`
+}
+
+func (c *BackoffConfig) Validate() error {
+	if c.Attempts < 0 || c.Min < 0 || c.Max < 0 {
+		return errors.New("attempts, min or max is < 0")
+	}
+	return nil
+}
+
+// Delay 第n次(从0开始)重试前等待的时长
+func (c *BackoffConfig) Delay(n int) time.Duration {
+	var d = c.Min
+	for i := 0; i < n && (c.Max <= 0 || d < c.Max); i++ {
+		d *= 2
+	}
+	if c.Max > 0 && d > c.Max {
+		d = c.Max
+	}
+	return d
+}
+
+type reloginKey struct{}
+
+// OnNeedLogin 设置接口返回需要登录(301)时的回调,通常用于刷新登录token。
+// 仅在存在登录cookie时调用,回调返回nil则重新发起请求,每次请求最多回调一次。
+// 需要在发起请求之前调用,不支持并发调用。
+func (c *Client) OnNeedLogin(f func(ctx context.Context) error) {
+	c.relogin = f
+}
+
+// backoff 接口调用失败重试中间件
+func (c *Client) backoff() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			var (
+				cfg      = c.cfg.Backoff
+				mutating = call.Opts != nil && call.Opts.Mutating
+				relogged bool
+			)
+			if call.Opts != nil && call.Opts.Backoff != nil {
+				cfg = *call.Opts.Backoff
+			}
+			for i := 0; ; {
+				var (
+					reqCtx  = ctx
+					written atomic.Bool
+				)
+				if mutating {
+					reqCtx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+						WroteRequest: func(httptrace.WroteRequestInfo) { written.Store(true) },
+					})
+				}
+				resp, err := next(reqCtx, call)
+				if ctx.Err() != nil {
+					return resp, err
+				}
+				var code = respCode(call.Resp, err)
+				if code == 301 && !relogged && c.canRelogin(ctx) {
+					relogged = true
+					if e := c.relogin(context.WithValue(ctx, reloginKey{}, true)); e != nil {
+						c.l.Warn("[backoff] trace=%s url=%s relogin err: %s", TraceId(ctx), call.Url, e)
+						return resp, err
+					}
+					resetResp(call.Resp)
+					continue
+				}
+				if i >= cfg.Attempts || !retryable(resp, err, code, cfg.Codes, !mutating || !written.Load()) {
+					return resp, err
+				}
+
+				var wait = cfg.Delay(i)
+				i++
+				c.l.Debug("[backoff] trace=%s url=%s attempt=%d wait=%s code=%d err=%v", TraceId(ctx), call.Url, i, wait, code, err)
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(wait):
+				}
+				resetResp(call.Resp)
+			}
+		}
+	}
+}
+
+// canRelogin 存在登录cookie且不是在回调中发起的请求时才允许回调,避免递归
+func (c *Client) canRelogin(ctx context.Context) bool {
+	if c.relogin == nil || ctx.Value(reloginKey{}) != nil {
+		return false
+	}
+	_, ok := c.Cookie("https://music.163.com", "MUSIC_U")
+	return ok
+}
+
+// respCode 获取响应中的业务返回码,无法获取时返回0
+func respCode(resp interface{}, err error) int64 {
+	if err != nil {
+		// CodeError 中间件会将业务错误通过err返回
+		if e, ok := AsError(err); ok {
+			return e.Code
+		}
+		return 0
+	}
+	r, ok := resp.(interface{ Err() error })
+	if !ok {
+		return 0
+	}
+	if e, ok := AsError(r.Err()); ok {
+		return e.Code
+	}
+	return 0
+}
+
+// retryable 指定的业务返回码表示服务端拒绝了请求,总是可以重试。
+// 网络错误及http 5xx时服务端可能已经处理了请求,只有 idempotent 为true(幂等接口或请求未发送)时才重试
+func retryable(resp *resty.Response, err error, code int64, codes []int64, idempotent bool) bool {
+	if code != 0 && slices.Contains(codes, code) {
+		return true
+	}
+	if err != nil && idempotent {
+		var ue *neturl.Error
+		if errors.As(err, &ue) {
+			return true
+		}
+		return resp != nil && resp.StatusCode() >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// resetResp 重试前清空上次解析的响应内容
+func resetResp(resp interface{}) {
+	var v = reflect.ValueOf(resp)
+	if v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().CanSet() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
@@ -0,0 +1,194 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResp struct {
+	Code int64
+}
+
+func (r *fakeResp) Err() error {
+	if r.Code == 200 {
+		return nil
+	}
+	return types.NewError(r.Code, "")
+}
+
+func TestBackoffConfigDelay(t *testing.T) {
+	var cfg = BackoffConfig{Min: time.Second, Max: 5 * time.Second}
+	assert.Equal(t, time.Second, cfg.Delay(0))
+	assert.Equal(t, 2*time.Second, cfg.Delay(1))
+	assert.Equal(t, 4*time.Second, cfg.Delay(2))
+	assert.Equal(t, 5*time.Second, cfg.Delay(3))
+	assert.Equal(t, 5*time.Second, cfg.Delay(100))
+
+	cfg.Max = 0
+	assert.Equal(t, 8*time.Second, cfg.Delay(3))
+}
+
+func TestRetryable(t *testing.T) {
+	var codes = []int64{-460}
+	assert.True(t, retryable(nil, &neturl.Error{Op: "Post", Err: errors.New("timeout")}, 0, codes, true))
+	assert.True(t, retryable(&resty.Response{RawResponse: &http.Response{StatusCode: 502}}, errors.New("502"), 0, codes, true))
+	assert.False(t, retryable(&resty.Response{RawResponse: &http.Response{StatusCode: 404}}, errors.New("404"), 0, codes, true))
+	assert.True(t, retryable(nil, nil, -460, codes, true))
+	assert.False(t, retryable(nil, nil, 301, codes, true))
+	assert.False(t, retryable(nil, nil, 0, codes, true))
+
+	// 非幂等接口请求已发送时网络错误及5xx不重试,业务返回码仍然重试
+	assert.False(t, retryable(nil, &neturl.Error{Op: "Post", Err: errors.New("timeout")}, 0, codes, false))
+	assert.False(t, retryable(&resty.Response{RawResponse: &http.Response{StatusCode: 502}}, errors.New("502"), 0, codes, false))
+	assert.True(t, retryable(nil, nil, -460, codes, false))
+}
+
+func TestBackoff(t *testing.T) {
+	var (
+		calls  int
+		client = &Client{cfg: &Config{Backoff: BackoffConfig{Attempts: 2, Min: time.Millisecond, Codes: []int64{-460}}}}
+		h      = client.backoff()(func(ctx context.Context, call *Call) (*resty.Response, error) {
+			calls++
+			if calls < 3 {
+				call.Resp.(*fakeResp).Code = -460
+			} else {
+				call.Resp.(*fakeResp).Code = 200
+			}
+			return nil, nil
+		})
+	)
+	var resp fakeResp
+	_, err := h(context.Background(), &Call{Resp: &resp})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, int64(200), resp.Code)
+
+	// 单个请求覆盖重试配置
+	calls = 0
+	_, err = h(context.Background(), &Call{Resp: &resp, Opts: &Options{Backoff: &BackoffConfig{}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int64(-460), resp.Code)
+}
+
+func TestBackoffRelogin(t *testing.T) {
+	jar, err := cookie.NewCookie(cookie.WithFilePath(filepath.Join(t.TempDir(), "cookie.json")), cookie.WithSyncInterval(0))
+	assert.NoError(t, err)
+	defer jar.Close(context.Background())
+
+	var (
+		calls   int
+		relogin int
+		client  = &Client{cfg: &Config{}, cookie: jar}
+		h       = client.backoff()(func(ctx context.Context, call *Call) (*resty.Response, error) {
+			calls++
+			call.Resp.(*fakeResp).Code = 301
+			return nil, nil
+		})
+	)
+	client.OnNeedLogin(func(ctx context.Context) error {
+		relogin++
+		// 回调中发起的请求不会再次触发回调
+		assert.False(t, client.canRelogin(ctx))
+		return nil
+	})
+
+	// 没有登录cookie时不回调
+	_, err = h(context.Background(), &Call{Resp: &fakeResp{}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 0, relogin)
+
+	uri, _ := neturl.Parse("https://music.163.com")
+	jar.SetCookies(uri, []*http.Cookie{{Name: "MUSIC_U", Value: "token", Path: "/", Expires: time.Now().Add(time.Hour)}})
+	calls = 0
+	_, err = h(context.Background(), &Call{Resp: &fakeResp{}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, relogin)
+}
+
+func TestBackoffMutating(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	var (
+		client = New(&Config{
+			Timeout: time.Second,
+			Retry:   3,
+			Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+			Backoff: BackoffConfig{Attempts: 2, Min: time.Millisecond},
+		})
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+	)
+	opts.CryptoMode = CryptoModeAPI
+
+	// 幂等接口按重试中间件的次数重试,resty不再重试
+	_, err := client.Request(context.Background(), srv.URL+"/api/test", map[string]string{}, &reply, opts)
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+
+	// 非幂等接口请求已发送到服务端,不重试
+	calls = 0
+	opts.Mutating = true
+	_, err = client.Request(context.Background(), srv.URL+"/api/test", map[string]string{}, &reply, opts)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	// 请求未发送(连接失败)时非幂等接口也可以重试
+	var attempts int
+	client.Use(func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			attempts++
+			return next(ctx, call)
+		}
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := ln.Addr().String()
+	assert.NoError(t, ln.Close())
+	_, err = client.Request(context.Background(), "http://"+addr+"/api/test", map[string]string{}, &reply, opts)
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts)
+}
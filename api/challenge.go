@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ChallengeCodes 需要进行安全验证的接口返回码
+// -462: 需要进行安全验证 8810: 登录存在风险 8821: 需要行为验证码验证
+var ChallengeCodes = []int64{-462, 8810, 8821}
+
+// Challenge 风控安全验证信息
+type Challenge struct {
+	Code    int64                  // 接口返回码
+	Message string                 // 接口返回说明
+	Url     string                 // 验证页面地址,可能为空
+	Params  map[string]interface{} // 接口返回的验证参数,例如滑块验证码需要的verifyId、verifyType等
+}
+
+// ChallengeHandler 处理安全验证,返回nil表示已完成验证,将会重新发起原请求
+type ChallengeHandler func(ctx context.Context, c *Challenge) error
+
+// DefaultChallengeHandler 客户端未通过 Client.OnChallenge 设置时使用的安全验证处理方法,为空则不处理
+var DefaultChallengeHandler ChallengeHandler
+
+type challengeKey struct{}
+
+// ParseChallenge 从响应内容中解析安全验证信息,不需要验证时返回false
+func ParseChallenge(body []byte) (*Challenge, bool) {
+	var reply struct {
+		Code        int64                  `json:"code"`
+		Message     string                 `json:"message"`
+		Msg         string                 `json:"msg"`
+		Url         string                 `json:"url"`
+		RedirectUrl string                 `json:"redirectUrl"`
+		Data        map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return nil, false
+	}
+	if !isChallenge(reply.Code) {
+		return nil, false
+	}
+	var c = Challenge{
+		Code:    reply.Code,
+		Message: reply.Message,
+		Url:     reply.Url,
+		Params:  reply.Data,
+	}
+	if c.Message == "" {
+		c.Message = reply.Msg
+	}
+	if c.Url == "" {
+		c.Url = reply.RedirectUrl
+	}
+	for _, key := range []string{"url", "verifyUrl", "redirectUrl"} {
+		if v, ok := reply.Data[key].(string); ok && v != "" && c.Url == "" {
+			c.Url = v
+		}
+	}
+	if v, ok := reply.Data["blockText"].(string); ok && v != "" && c.Message == "" {
+		c.Message = v
+	}
+	return &c, true
+}
+
+func isChallenge(code int64) bool {
+	for _, v := range ChallengeCodes {
+		if v == code {
+			return true
+		}
+	}
+	return false
+}
+
+// OnChallenge 设置接口要求安全验证时的处理方法,优先级高于 DefaultChallengeHandler。
+// 需要在发起请求之前调用,不支持并发调用。
+func (c *Client) OnChallenge(f ChallengeHandler) {
+	c.onChallenge = f
+}
+
+// challenge 安全验证中间件,接口要求安全验证时调用处理方法,验证完成后重新发起一次原请求
+func (c *Client) challenge() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			resp, err := next(ctx, call)
+			if !isChallenge(respCode(call.Resp, err)) || ctx.Value(challengeKey{}) != nil {
+				return resp, err
+			}
+			ch, ok := ParseChallenge(call.Body)
+			if !ok {
+				return resp, err
+			}
+
+			var handler = c.onChallenge
+			if handler == nil {
+				handler = DefaultChallengeHandler
+			}
+			if handler == nil {
+				c.l.Warn("[challenge] trace=%s url=%s code=%d message=%s verify url=%s", TraceId(ctx), call.Url, ch.Code, ch.Message, ch.Url)
+				return resp, err
+			}
+			if e := handler(context.WithValue(ctx, challengeKey{}, true), ch); e != nil {
+				return resp, fmt.Errorf("challenge: %w", e)
+			}
+			c.l.Debug("[challenge] trace=%s url=%s verified, resume request", TraceId(ctx), call.Url)
+			resetResp(call.Resp)
+			call.Body = nil
+			return next(ctx, call)
+		}
+	}
+}
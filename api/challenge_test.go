@@ -0,0 +1,97 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChallenge(t *testing.T) {
+	c, ok := ParseChallenge([]byte(`{"code":-462,"data":{"blockText":"需要进行安全验证","url":"https://st.music.163.com/encrypt-pages?id=1","verifyType":1},"message":""}`))
+	assert.True(t, ok)
+	assert.Equal(t, int64(-462), c.Code)
+	assert.Equal(t, "需要进行安全验证", c.Message)
+	assert.Equal(t, "https://st.music.163.com/encrypt-pages?id=1", c.Url)
+	assert.Equal(t, float64(1), c.Params["verifyType"])
+
+	c, ok = ParseChallenge([]byte(`{"code":8821,"message":"需要行为验证码验证","redirectUrl":"https://music.163.com/verify"}`))
+	assert.True(t, ok)
+	assert.Equal(t, "https://music.163.com/verify", c.Url)
+
+	_, ok = ParseChallenge([]byte(`{"code":200}`))
+	assert.False(t, ok)
+	_, ok = ParseChallenge([]byte(`invalid`))
+	assert.False(t, ok)
+}
+
+func TestChallenge(t *testing.T) {
+	var (
+		calls   int
+		handled int
+		client  = &Client{cfg: &Config{}}
+		h       = client.challenge()(func(ctx context.Context, call *Call) (*resty.Response, error) {
+			calls++
+			if calls == 1 {
+				call.Resp.(*fakeResp).Code = -462
+				call.Body = []byte(`{"code":-462,"data":{"url":"https://st.music.163.com/encrypt-pages"}}`)
+			} else {
+				call.Resp.(*fakeResp).Code = 200
+			}
+			return nil, nil
+		})
+	)
+
+	// 没有处理方法时直接返回
+	var resp fakeResp
+	_, err := h(context.Background(), &Call{Resp: &resp})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-462), resp.Code)
+
+	// 验证完成后重新发起请求
+	calls = 0
+	client.OnChallenge(func(ctx context.Context, c *Challenge) error {
+		handled++
+		assert.Equal(t, "https://st.music.163.com/encrypt-pages", c.Url)
+		return nil
+	})
+	_, err = h(context.Background(), &Call{Resp: &resp})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, handled)
+	assert.Equal(t, int64(200), resp.Code)
+
+	// 放弃验证
+	calls = 0
+	client.OnChallenge(func(ctx context.Context, c *Challenge) error {
+		return errors.New("aborted")
+	})
+	_, err = h(context.Background(), &Call{Resp: &resp})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
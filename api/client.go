@@ -0,0 +1,224 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package api 网易云音乐接口客户端,负责请求加解密、cookie管理、限流以及重试等通用逻辑,
+// 具体接口位于 weapi、eapi、linux 子包中。通过 NewWithOptions 创建客户端即可在其他项目中使用,
+// 客户端之间不共享状态,所有接口的第一个参数均为 context.Context 用于超时及取消控制。
+package api
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+)
+
+// ClientOption 创建客户端时的配置项,见 NewWithOptions
+type ClientOption func(o *clientOptions)
+
+type clientOptions struct {
+	cfg         Config
+	logger      *log.Logger
+	jar         *cookie.Cookie
+	dryRun      io.Writer
+	challenge   ChallengeHandler
+	middlewares []Middleware
+}
+
+// DefaultConfig 返回 NewWithOptions 使用的默认配置,与命令行默认配置文件一致,
+// 区别是cookie只保存在内存中并且不注入设备指纹。
+func DefaultConfig() Config {
+	return Config{
+		Timeout: 60 * time.Second,
+		Retry:   3,
+		Cookie:  cookie.Config{Interval: 3 * time.Second},
+		RateLimit: RateLimitConfig{
+			Rate:  5,
+			Burst: 10,
+		},
+		Backoff: BackoffConfig{
+			Attempts: 2,
+			Min:      time.Second,
+			Max:      10 * time.Second,
+			Codes:    []int64{-460, 405},
+		},
+	}
+}
+
+// NewWithOptions 创建客户端,供其他项目作为sdk引入使用,不依赖命令行的全局配置及日志:
+//
+//	cli, err := api.NewWithOptions(
+//		api.WithCookieFile("./cookie.json"),
+//		api.WithProxy("socks5://127.0.0.1:1080"),
+//		api.WithRateLimit(2, 5),
+//		api.WithLogger(slog.Default()),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	defer cli.Close(ctx)
+//	reply, err := weapi.New(cli).GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+//
+// 未指定的配置使用 DefaultConfig,未指定日志时不输出日志。
+func NewWithOptions(opts ...ClientOption) (*Client, error) {
+	var o = clientOptions{cfg: DefaultConfig(), logger: log.Discard()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+
+	var jar = o.jar
+	if jar == nil {
+		var (
+			err  error
+			copt = []cookie.Option{
+				cookie.WithSyncInterval(o.cfg.Cookie.Interval),
+				cookie.WithFilePath(o.cfg.Cookie.Filepath),
+			}
+		)
+		if opt := o.cfg.Cookie.Options; opt != nil && opt.PublicSuffixList != nil {
+			copt = append(copt, cookie.WithPublicSuffixList(opt.PublicSuffixList))
+		}
+		if jar, err = cookie.NewCookie(copt...); err != nil {
+			return nil, fmt.Errorf("NewCookie: %w", err)
+		}
+	}
+
+	c, err := newClient(&o.cfg, o.logger, jar, o.dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if o.challenge != nil {
+		c.OnChallenge(o.challenge)
+	}
+	if len(o.middlewares) > 0 {
+		c.Use(o.middlewares...)
+	}
+	return c, nil
+}
+
+// WithConfig 使用cfg替换默认配置,之后的配置项在此基础上修改
+func WithConfig(cfg Config) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg = cfg
+	}
+}
+
+// WithCookieFile 登录cookie保存到文件中,为空则只保存在内存中
+func WithCookieFile(path string) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.Cookie.Filepath = path
+	}
+}
+
+// WithCookieStore 使用已创建的cookie存储,可在多个客户端之间共享登录状态。
+// 客户端 Close 时会关闭该存储。
+func WithCookieStore(jar *cookie.Cookie) ClientOption {
+	return func(o *clientOptions) {
+		o.jar = jar
+	}
+}
+
+// WithProxy 设置代理地址以及不走代理的主机,见 ProxyConfig
+func WithProxy(url string, bypass ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.Proxy = ProxyConfig{Url: url, Bypass: bypass}
+	}
+}
+
+// WithRateLimit 设置每秒允许的请求数以及突发请求数,rate为0表示不限制
+func WithRateLimit(rate float64, burst int) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.RateLimit.Rate = rate
+		o.cfg.RateLimit.Burst = burst
+	}
+}
+
+// WithTimeout 设置单次请求超时时间
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.Timeout = timeout
+	}
+}
+
+// WithRetry 设置网络出现问题时的重试次数
+func WithRetry(retry int) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.Retry = retry
+	}
+}
+
+// WithBackoff 设置接口返回网络拥挤等业务错误时的重试策略
+func WithBackoff(backoff BackoffConfig) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.Backoff = backoff
+	}
+}
+
+// WithDevice 设置设备指纹配置,见 DeviceConfig
+func WithDevice(device DeviceConfig) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.Device = device
+	}
+}
+
+// WithLogger 设置客户端日志输出,为空时使用 slog.Default
+func WithLogger(l *slog.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = log.FromSlog(l)
+	}
+}
+
+// WithChallengeHandler 设置接口要求安全验证时的处理方法,见 Client.OnChallenge
+func WithChallengeHandler(h ChallengeHandler) ClientOption {
+	return func(o *clientOptions) {
+		o.challenge = h
+	}
+}
+
+// WithMiddleware 添加接口调用中间件,位于内置中间件的内层,见 Client.Use
+func WithMiddleware(m ...Middleware) ClientOption {
+	return func(o *clientOptions) {
+		o.middlewares = append(o.middlewares, m...)
+	}
+}
+
+// WithObserver 设置接口调用指标观察者,见 Observer
+func WithObserver(observer Observer) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.Observer = observer
+	}
+}
+
+// WithDryRun 会修改账号数据的接口不发送请求,而是将请求内容输出到w,见 DryRun
+func WithDryRun(w io.Writer) ClientOption {
+	return func(o *clientOptions) {
+		o.cfg.DryRun = true
+		o.dryRun = w
+	}
+}
@@ -0,0 +1,137 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":200,"message":"ok"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		logs  bytes.Buffer
+		calls int
+		dir   = t.TempDir()
+		ctx   = context.Background()
+	)
+	wd, _ := os.Getwd()
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	cli, err := NewWithOptions(
+		WithRateLimit(0, 0),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithMiddleware(func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) (*resty.Response, error) {
+				calls++
+				return next(ctx, call)
+			}
+		}),
+	)
+	assert.NoError(t, err)
+
+	var (
+		reply struct {
+			Code    int64  `json:"code"`
+			Message string `json:"message"`
+		}
+		opts = NewOptions()
+	)
+	opts.CryptoMode = CryptoModeAPI
+	_, err = cli.Request(ctx, srv.URL+"/api/test", map[string]string{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), reply.Code)
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, logs.String(), "/api/test")
+
+	assert.NoError(t, cli.Close(ctx))
+
+	// 默认cookie只保存在内存中
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestNewWithOptionsCookieFile(t *testing.T) {
+	var (
+		path = filepath.Join(t.TempDir(), "cookie.json")
+		ctx  = context.Background()
+	)
+	cli, err := NewWithOptions(WithCookieFile(path))
+	assert.NoError(t, err)
+	u, _ := url.Parse("https://music.163.com")
+	cli.SetCookies(u, []*http.Cookie{{Name: "MUSIC_U", Value: "token"}})
+	assert.NoError(t, cli.Close(ctx))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "MUSIC_U")
+}
+
+func TestNewWithOptionsDryRun(t *testing.T) {
+	var buf bytes.Buffer
+	cli, err := NewWithOptions(WithDryRun(&buf))
+	assert.NoError(t, err)
+	defer cli.Close(context.Background())
+
+	var (
+		reply struct {
+			Code int64 `json:"code"`
+		}
+		opts = NewOptions()
+	)
+	opts.Mutating = true
+	_, err = cli.Request(context.Background(), "https://music.163.com/weapi/playlist/create", map[string]string{"name": "test"}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), reply.Code)
+	assert.True(t, strings.HasPrefix(buf.String(), "[dry-run] POST"))
+
+	buf.Reset()
+	_, err = cli.Upload(context.Background(), "https://nos.netease.com/upload", map[string]string{"x-nos-token": "token"}, strings.NewReader("data"), &reply, nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), "[dry-run] POST https://nos.netease.com/upload upload"))
+}
+
+func TestNewWithOptionsValidate(t *testing.T) {
+	_, err := NewWithOptions(WithRetry(-1))
+	assert.Error(t, err)
+	_, err = NewWithOptions(WithProxy("ftp://127.0.0.1"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"sync"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Codec 接口协议编解码器,负责请求参数加密以及响应数据解密。
+// 接口通过 Options.CryptoMode 声明使用的协议,新增协议只需实现 Codec 并调用 RegisterCodec 注册。
+type Codec interface {
+	// Encode 加密请求参数,返回实际请求地址以及表单数据,可通过 request 设置请求头、查询参数等
+	Encode(c *Client, call *Call, request *resty.Request) (string, map[string]string, error)
+	// Decode 解密响应数据,返回json明文
+	Decode(body []byte) ([]byte, error)
+}
+
+var (
+	codecMux sync.RWMutex
+	codecs   = map[CryptoMode]Codec{
+		CryptoModeAPI:   apiCodec{},
+		CryptoModeEAPI:  eapiCodec{},
+		CryptoModeWEAPI: weapiCodec{},
+		CryptoModeLinux: linuxCodec{},
+	}
+)
+
+// RegisterCodec 注册协议编解码器,已存在时覆盖
+func RegisterCodec(mode CryptoMode, codec Codec) {
+	codecMux.Lock()
+	defer codecMux.Unlock()
+	codecs[mode] = codec
+}
+
+// GetCodec 获取协议编解码器
+func GetCodec(mode CryptoMode) (Codec, bool) {
+	codecMux.RLock()
+	defer codecMux.RUnlock()
+	codec, ok := codecs[mode]
+	return codec, ok
+}
+
+// apiCodec /api/xx 接口不需要加密,返回数据为明文
+type apiCodec struct{}
+
+func (apiCodec) Encode(_ *Client, call *Call, _ *resty.Request) (string, map[string]string, error) {
+	// todo: 待处理,在/api/xx/接口请求时则不需要参数加密处理,此处需要对结构体转换成map[string]string类型
+	b, err := json.Marshal(call.Req)
+	if err != nil {
+		return "", nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	var data = make(map[string]string, len(m))
+	for k, v := range m {
+		data[k] = fmt.Sprint(v)
+	}
+	return call.Url, data, nil
+}
+
+func (apiCodec) Decode(body []byte) ([]byte, error) {
+	return body, nil
+}
+
+// eapiCodec 通常在MAC、windows、android、ios客户端中使用
+type eapiCodec struct{}
+
+func (eapiCodec) Encode(_ *Client, call *Call, _ *resty.Request) (string, map[string]string, error) {
+	// todo: set common params. see: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/blob/main/util/request.js
+	uri, err := neturl.Parse(call.Url)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := crypto.EApiEncrypt(uri.Path, call.Req)
+	if err != nil {
+		return "", nil, fmt.Errorf("EApiEncrypt: %w", err)
+	}
+	return call.Url, data, nil
+}
+
+func (eapiCodec) Decode(body []byte) ([]byte, error) {
+	// TODO: 貌似eapi接口返回数据是否是是明文,跟传入参数e_r: true有关,true为加密，false为明文。此处考虑采用反射req中得字段处理。
+	// see: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/commit/58e9865b70e41197c2ab75c46a775fc45d6efa6e
+	return body, nil
+}
+
+// weapiCodec 网页端使用
+type weapiCodec struct{}
+
+func (weapiCodec) Encode(c *Client, call *Call, request *resty.Request) (string, map[string]string, error) {
+	// todo: 需要替换？因为有些 https://interface.music.163.com/api 得接口也会走这个逻辑
+	csrf, has := c.GetCSRF(call.Url)
+	if !has {
+		c.l.Debug("get csrf token not found")
+	}
+	request.SetQueryParam("csrf_token", csrf)
+
+	data, err := crypto.WeApiEncrypt(call.Req)
+	if err != nil {
+		return "", nil, fmt.Errorf("WeApiEncrypt: %w", err)
+	}
+	return call.Url, data, nil
+}
+
+func (weapiCodec) Decode(body []byte) ([]byte, error) {
+	// tips: weapi接口返回数据是明文
+	return body, nil
+}
+
+// linuxCodec linux客户端所有接口统一经由转发地址请求,真实地址包装在加密参数中
+type linuxCodec struct{}
+
+func (linuxCodec) Encode(_ *Client, call *Call, request *resty.Request) (string, map[string]string, error) {
+	data, err := crypto.LinuxApiForward(call.Url, call.Req)
+	if err != nil {
+		return "", nil, fmt.Errorf("LinuxApiForward: %w", err)
+	}
+	if _, ok := call.Opts.Headers["User-Agent"]; !ok {
+		request.SetHeader("User-Agent", linuxUserAgent)
+	}
+	return crypto.LinuxApiForwardUrl, data, nil
+}
+
+func (linuxCodec) Decode(body []byte) ([]byte, error) {
+	// tips: 转发接口通常返回明文,非json时再尝试解密
+	if json.Valid(body) {
+		return body, nil
+	}
+	data, err := crypto.LinuxApiDecrypt(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("LinuxApiDecrypt: %w", err)
+	}
+	return data, nil
+}
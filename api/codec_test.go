@@ -0,0 +1,89 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCodec struct{}
+
+func (testCodec) Encode(_ *Client, call *Call, _ *resty.Request) (string, map[string]string, error) {
+	return call.Url + "/test", map[string]string{"k": "v"}, nil
+}
+
+func (testCodec) Decode(body []byte) ([]byte, error) {
+	return body, nil
+}
+
+func TestGetCodec(t *testing.T) {
+	for _, mode := range []CryptoMode{CryptoModeAPI, CryptoModeEAPI, CryptoModeWEAPI, CryptoModeLinux} {
+		_, ok := GetCodec(mode)
+		assert.Truef(t, ok, "codec %s not registered", mode)
+	}
+	_, ok := GetCodec("unknown")
+	assert.False(t, ok)
+
+	RegisterCodec("test", testCodec{})
+	codec, ok := GetCodec("test")
+	assert.True(t, ok)
+	url, data, err := codec.Encode(nil, &Call{Url: "https://music.163.com"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://music.163.com/test", url)
+	assert.Equal(t, map[string]string{"k": "v"}, data)
+}
+
+func TestApiCodec(t *testing.T) {
+	url, data, err := apiCodec{}.Encode(nil, &Call{Url: "https://music.163.com/api/x", Req: map[string]interface{}{"id": 1, "name": "a"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://music.163.com/api/x", url)
+	assert.Equal(t, map[string]string{"id": "1", "name": "a"}, data)
+}
+
+func TestLinuxCodec(t *testing.T) {
+	var (
+		codec   = linuxCodec{}
+		request = resty.New().R()
+	)
+	url, data, err := codec.Encode(nil, &Call{Url: "https://music.163.com/weapi/x", Req: map[string]string{}, Opts: NewOptions()}, request)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.LinuxApiForwardUrl, url)
+	assert.NotEmpty(t, data["eparams"])
+	assert.Equal(t, linuxUserAgent, request.Header.Get("User-Agent"))
+
+	plain, err := codec.Decode([]byte(`{"code":200}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"code":200}`, string(plain))
+
+	encrypted, err := crypto.LinuxApiEncrypt(map[string]int{"code": 200})
+	assert.NoError(t, err)
+	plain, err = codec.Decode([]byte(encrypted["eparams"]))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"code":200}`, string(plain))
+}
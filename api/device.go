@@ -0,0 +1,283 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
+)
+
+// DeviceConfig 设备指纹配置,同一个账号固定使用同一份设备指纹,避免频繁变化的请求特征触发风控验证
+type DeviceConfig struct {
+	// Filepath 设备指纹保存路径,为空则不注入设备指纹
+	Filepath string `json:"filepath" yaml:"filepath"`
+	// Os 设备系统 pc、osx、android、iphone,为空则在pc、osx中随机选择
+	Os string `json:"os" yaml:"os"`
+	// Rotate 设备指纹有效时长,超过后重新生成,0表示固定不变
+	Rotate time.Duration `json:"rotate" yaml:"rotate"`
+}
+
+func (c *DeviceConfig) Validate() error {
+	if c.Os != "" {
+		if _, ok := deviceTemplates[c.Os]; !ok {
+			return fmt.Errorf("unsupported device os: %s", c.Os)
+		}
+	}
+	if c.Rotate < 0 {
+		return fmt.Errorf("rotate is < 0")
+	}
+	return nil
+}
+
+// Device 设备指纹,以cookie以及User-Agent的形式注入到每个请求中
+type Device struct {
+	DeviceId   string    `json:"deviceId"`
+	Os         string    `json:"os"`
+	OsVer      string    `json:"osver"`
+	AppVer     string    `json:"appver"`
+	UserAgent  string    `json:"userAgent"`
+	WNMCID     string    `json:"WNMCID"`
+	NMTID      string    `json:"NMTID"`
+	NUID       string    `json:"_ntes_nuid"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+type deviceTemplate struct {
+	osver     string
+	appver    string
+	userAgent string
+}
+
+// deviceTemplates 各系统客户端的版本信息
+var deviceTemplates = map[string]deviceTemplate{
+	"pc": {
+		osver:     "Microsoft-Windows-10-Professional-build-19045-64bit",
+		appver:    "3.0.18.203152",
+		userAgent: "Mozilla/5.0 (Windows NT 10.0; WOW64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.164 NeteaseMusicDesktop/3.0.18.203152",
+	},
+	"osx": {
+		osver:     "%E7%89%88%E6%9C%AC14.5%EF%BC%88%E7%89%88%E5%8F%B723F79%EF%BC%89",
+		appver:    "2.3.17",
+		userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034",
+	},
+	"android": {
+		osver:     "14",
+		appver:    "9.1.65",
+		userAgent: "NeteaseMusic/9.1.65.240927161425(9001065);Dalvik/2.1.0 (Linux; U; Android 14; 23013RK75C Build/UKQ1.230804.001)",
+	},
+	"iphone": {
+		osver:     "17.5",
+		appver:    "9.0.90",
+		userAgent: "NeteaseMusic 9.0.90/5038 (iPhone; iOS 17.5; zh_CN)",
+	},
+}
+
+// NewDevice 随机生成设备指纹,os为空则在pc、osx中随机选择
+func NewDevice(os string) (*Device, error) {
+	if os == "" {
+		os = []string{"pc", "osx"}[randInt(2)]
+	}
+	t, ok := deviceTemplates[os]
+	if !ok {
+		return nil, fmt.Errorf("unsupported device os: %s", os)
+	}
+	deviceId, err := randHex(16)
+	if err != nil {
+		return nil, err
+	}
+	nuid, err := randHex(16)
+	if err != nil {
+		return nil, err
+	}
+	tid := make([]byte, 30)
+	if _, err := rand.Read(tid); err != nil {
+		return nil, err
+	}
+	return &Device{
+		DeviceId:   strings.ToUpper(deviceId),
+		Os:         os,
+		OsVer:      t.osver,
+		AppVer:     t.appver,
+		UserAgent:  t.userAgent,
+		WNMCID:     crypto.GenerateWNMCID(),
+		NMTID:      "00O" + base64.RawURLEncoding.EncodeToString(tid),
+		NUID:       nuid,
+		CreateTime: time.Now(),
+	}, nil
+}
+
+// LoadDevice 读取保存的设备指纹,文件不存在、已过期或者系统与配置不一致时重新生成并保存
+func LoadDevice(cfg DeviceConfig) (*Device, error) {
+	var d Device
+	data, err := os.ReadFile(cfg.Filepath)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("Unmarshal: %w", err)
+		}
+		var expired = cfg.Rotate > 0 && time.Since(d.CreateTime) > cfg.Rotate
+		if !expired && d.DeviceId != "" && (cfg.Os == "" || cfg.Os == d.Os) {
+			return &d, nil
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+
+	device, err := NewDevice(cfg.Os)
+	if err != nil {
+		return nil, err
+	}
+	if err := device.Save(cfg.Filepath); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// Save 保存设备指纹
+func (d *Device) Save(path string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("MarshalIndent: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	return nil
+}
+
+// Cookies 设备指纹对应的cookie
+func (d *Device) Cookies() []*http.Cookie {
+	return []*http.Cookie{
+		{Name: "os", Value: d.Os},
+		{Name: "osver", Value: d.OsVer},
+		{Name: "appver", Value: d.AppVer},
+		{Name: "deviceId", Value: d.DeviceId},
+		{Name: "WNMCID", Value: d.WNMCID},
+		{Name: "NMTID", Value: d.NMTID},
+		{Name: "_ntes_nuid", Value: d.NUID},
+	}
+}
+
+// device 客户端当前使用的设备指纹
+type device struct {
+	mu  sync.RWMutex
+	cfg DeviceConfig
+	d   *Device
+}
+
+func (d *device) get() *Device {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.d
+}
+
+// intercept 向网易云音乐域名的请求注入设备指纹,请求中已存在的同名cookie以及通过 Options.Headers 指定的User-Agent优先
+func (d *device) intercept(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		var dev = d.get()
+		if dev == nil || !strings.HasSuffix(req.URL.Hostname(), "163.com") {
+			return next(req)
+		}
+		req = req.Clone(req.Context())
+		for _, c := range dev.Cookies() {
+			if _, err := req.Cookie(c.Name); err != nil {
+				req.AddCookie(c)
+			}
+		}
+		if req.Header.Get("User-Agent") == defaultUserAgent {
+			req.Header.Set("User-Agent", dev.UserAgent)
+		}
+		return next(req)
+	}
+}
+
+// Device 获取当前使用的设备指纹,未启用设备指纹时返回nil
+func (c *Client) Device() *Device {
+	if c.device == nil {
+		return nil
+	}
+	return c.device.get()
+}
+
+// SetDevice 固定使用指定的设备指纹,设置了保存路径时会同时保存
+func (c *Client) SetDevice(d *Device) error {
+	if c.device == nil {
+		return fmt.Errorf("device is not enabled")
+	}
+	c.device.mu.Lock()
+	defer c.device.mu.Unlock()
+	if path := c.device.cfg.Filepath; path != "" {
+		if err := d.Save(path); err != nil {
+			return err
+		}
+	}
+	c.device.d = d
+	return nil
+}
+
+// RotateDevice 重新生成设备指纹
+func (c *Client) RotateDevice() (*Device, error) {
+	if c.device == nil {
+		return nil, fmt.Errorf("device is not enabled")
+	}
+	d, err := NewDevice(c.device.cfg.Os)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetDevice(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func randHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func randInt(n int64) int64 {
+	v, err := rand.Int(rand.Reader, big.NewInt(n))
+	if err != nil {
+		return 0
+	}
+	return v.Int64()
+}
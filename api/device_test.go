@@ -0,0 +1,112 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDevice(t *testing.T) {
+	d, err := NewDevice("android")
+	assert.NoError(t, err)
+	assert.Equal(t, "android", d.Os)
+	assert.Len(t, d.DeviceId, 32)
+	assert.Len(t, d.NUID, 32)
+	assert.NotEmpty(t, d.WNMCID)
+	assert.NotEmpty(t, d.UserAgent)
+
+	d, err = NewDevice("")
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"pc", "osx"}, d.Os)
+
+	_, err = NewDevice("symbian")
+	assert.Error(t, err)
+}
+
+func TestLoadDevice(t *testing.T) {
+	var cfg = DeviceConfig{Filepath: filepath.Join(t.TempDir(), "device.json")}
+	first, err := LoadDevice(cfg)
+	assert.NoError(t, err)
+
+	// 固定使用已保存的设备指纹
+	second, err := LoadDevice(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, first.DeviceId, second.DeviceId)
+
+	// 系统与配置不一致时重新生成
+	cfg.Os = "iphone"
+	third, err := LoadDevice(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "iphone", third.Os)
+	assert.NotEqual(t, first.DeviceId, third.DeviceId)
+
+	// 超过有效时长重新生成
+	third.CreateTime = time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, third.Save(cfg.Filepath))
+	cfg.Rotate = time.Hour
+	fourth, err := LoadDevice(cfg)
+	assert.NoError(t, err)
+	assert.NotEqual(t, third.DeviceId, fourth.DeviceId)
+}
+
+func TestDeviceIntercept(t *testing.T) {
+	d, err := NewDevice("pc")
+	assert.NoError(t, err)
+
+	var (
+		got  *http.Request
+		next = func(req *http.Request) (*http.Response, error) {
+			got = req
+			return nil, nil
+		}
+		h = (&device{d: d}).intercept(next)
+	)
+
+	req, _ := http.NewRequest(http.MethodPost, "https://music.163.com/weapi/song/detail", nil)
+	req.Header.Set("User-Agent", defaultUserAgent)
+	req.AddCookie(&http.Cookie{Name: "os", Value: "pc"})
+	req.AddCookie(&http.Cookie{Name: "deviceId", Value: "custom"})
+	_, _ = h(req)
+	assert.Equal(t, d.UserAgent, got.Header.Get("User-Agent"))
+	c, err := got.Cookie("deviceId")
+	assert.NoError(t, err)
+	assert.Equal(t, "custom", c.Value)
+	c, err = got.Cookie("NMTID")
+	assert.NoError(t, err)
+	assert.Equal(t, d.NMTID, c.Value)
+	// 原请求不被修改
+	_, err = req.Cookie("NMTID")
+	assert.Error(t, err)
+
+	// 非网易云音乐域名不注入
+	req, _ = http.NewRequest(http.MethodGet, "https://example.com/a.mp3", nil)
+	_, _ = h(req)
+	_, err = got.Cookie("NMTID")
+	assert.Error(t, err)
+}
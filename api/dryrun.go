@@ -0,0 +1,57 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// dryRunResp dry-run模式下写入响应的内容,表示请求成功
+var dryRunResp = []byte(`{"code":200}`)
+
+// DryRun 设置了 Options.Mutating 的接口不会发送请求,而是将请求地址及参数输出到w并返回成功响应,便于调试脚本
+func DryRun(w io.Writer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			if call.Opts == nil || !call.Opts.Mutating {
+				return next(ctx, call)
+			}
+			payload, err := json.Marshal(call.Req)
+			if err != nil {
+				return nil, fmt.Errorf("json.Marshal: %w", err)
+			}
+			fmt.Fprintf(w, "[dry-run] %s %s %s\n%s\n", call.Opts.Method, call.Url, call.Opts.CryptoMode, payload)
+			call.Body = dryRunResp
+			if err := json.Unmarshal(dryRunResp, call.Resp); err != nil {
+				return nil, fmt.Errorf("json.Unmarshal: %w", err)
+			}
+			return &resty.Response{}, nil
+		}
+	}
+}
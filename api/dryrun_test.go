@@ -0,0 +1,64 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	var (
+		out     bytes.Buffer
+		calls   int
+		handler = DryRun(&out)(func(ctx context.Context, call *Call) (*resty.Response, error) {
+			calls++
+			return &resty.Response{}, nil
+		})
+		req = map[string]any{"op": "add", "pid": 1}
+	)
+
+	var (
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+	)
+	opts.Mutating = true
+	_, err := handler(context.Background(), &Call{Url: "https://music.163.com/weapi/playlist/manipulate/tracks", Req: req, Resp: &reply, Opts: opts})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, calls)
+	assert.NoError(t, reply.Err())
+	assert.Equal(t, "[dry-run] POST https://music.163.com/weapi/playlist/manipulate/tracks weapi\n{\"op\":\"add\",\"pid\":1}\n", out.String())
+
+	out.Reset()
+	_, err = handler(context.Background(), &Call{Url: "https://music.163.com/weapi/v6/playlist/detail", Req: req, Resp: &reply, Opts: NewOptions()})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, out.String())
+}
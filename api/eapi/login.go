@@ -42,7 +42,11 @@ type LoginPhoneReq struct {
 }
 
 type LoginPhoneResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
+	LoginType int64                  `json:"loginType"`
+	Token     string                 `json:"token"` // MUSIC_U
+	Account   GetUserInfoRespAccount `json:"account"`
+	Profile   GetUserInfoRespProfile `json:"profile"`
 }
 
 // LoginPhone 手机号登录
@@ -58,7 +62,7 @@ type QrcodeCreateKeyReq struct {
 }
 
 type QrcodeCreateKeyResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	UniKey string `json:"unikey"`
 }
 
@@ -87,7 +91,7 @@ type QrcodeGenerateReq struct {
 }
 
 type QrcodeGenerateResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Qrcode      []byte //
 	QrcodePrint string
 }
@@ -123,8 +127,13 @@ type QrcodeCheckReq struct {
 	Type int64  `json:"type"` // 目前传3 weapi中好像传1
 }
 
+// QrcodeCheckResp 扫码状态,Code 800:二维码不存在或已过期 801:等待扫码 802:正在扫码授权中 803:授权登录成功
+// 802响应示例: {"code":802,"message":"授权中","nickname":"test","avatarUrl":"https://p1.music.126.net/xxx.jpg"}
 type QrcodeCheckResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
+	Nickname  string `json:"nickname"`  // 扫码用户昵称,Code为802时返回
+	AvatarUrl string `json:"avatarUrl"` // 扫码用户头像,Code为802时返回
+	Cookie    string `json:"cookie"`    // 登录cookie,Code为803时返回
 }
 
 // QrcodeCheck 查询扫码状态
@@ -139,6 +148,7 @@ func (a *Api) QrcodeCheck(ctx context.Context, req *QrcodeCheckReq) (*QrcodeChec
 		reply QrcodeCheckResp
 		opts  = api.NewOptions()
 	)
+	opts.Codes = []int64{800, 801, 802, 803}
 	opts.CryptoMode = api.CryptoModeEAPI
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
@@ -154,7 +164,7 @@ type GetUserInfoReq struct {
 }
 
 type GetUserInfoResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Account GetUserInfoRespAccount `json:"account"`
 	Profile GetUserInfoRespProfile `json:"profile"`
 }
@@ -236,7 +246,8 @@ type TokenRefreshReq struct {
 }
 
 type TokenRefreshResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
+	BizCode string `json:"bizCode"` // 201:貌似刷新成功 400:貌似刷新不成功 504:貌似token已经过期了或者无效了
 }
 
 // TokenRefresh token刷新
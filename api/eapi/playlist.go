@@ -122,7 +122,7 @@ type PlaylistRespList struct {
 }
 
 type PlaylistResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Version  string             `json:"version"` // 时间戳1703557080686
 	More     bool               `json:"more"`
 	Playlist []PlaylistRespList `json:"playlist"`
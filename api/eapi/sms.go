@@ -34,8 +34,9 @@ type CaptchaSendReq struct {
 	CTCode string
 }
 
+// CaptchaSendResp Data为true表示发送成功
 type CaptchaSendResp struct {
-	types.RespCommon[any]
+	types.RespCommon[bool]
 }
 
 // CaptchaSend 发送验证码 PC客户端
@@ -50,8 +51,9 @@ type CaptchaVerifyReq struct {
 	Captcha string `json:"captcha"`
 }
 
+// CaptchaVerifyResp Data为true表示验证通过
 type CaptchaVerifyResp struct {
-	types.RespCommon[any]
+	types.RespCommon[bool]
 }
 
 // CaptchaVerify 验证验证码
@@ -125,7 +125,7 @@ type v3SongDetailReq struct {
 // cp: u64, 功能未知
 // publish_time: i64, 毫秒为单位的Unix时间戳
 type V3SongDetailResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Songs      []V3SongDetailRespSongs      `json:"songs"`
 	Privileges []V3SongDetailRespPrivileges `json:"privileges"`
 }
@@ -256,3 +256,58 @@ func (a *Api) V3SongDetail(ctx context.Context, req *V3SongDetailReq) (*V3SongDe
 	_ = resp
 	return &reply, nil
 }
+
+type SongPlayerV1Req struct {
+	Ids         types.IntsString `json:"ids"`         // 歌曲id
+	Level       types.Level      `json:"level"`       // 音乐质量 standard、higher、exhigh、lossless、hires、jyeffect、sky、jymaster
+	EncodeType  string           `json:"encodeType"`  // 音乐格式 eg: mp3、aac、flac
+	ImmerseType string           `json:"immerseType"` // 只有Level为sky时生效 eg: c51
+}
+
+type SongPlayerV1Resp struct {
+	types.RespCommon[[]SongPlayerV1RespData]
+}
+
+type SongPlayerV1RespData struct {
+	Id            int64               `json:"id"`            // 歌曲id
+	Url           string              `json:"url"`           // 歌曲资源url有时效性
+	Br            int64               `json:"br"`            // 码率
+	Size          int64               `json:"size"`          // 文件大小单位字节
+	Md5           string              `json:"md5"`           // 文件MD5值
+	Code          int64               `json:"code"`          // 歌曲状态 200:正常 404:歌曲下架(也就是变灰歌曲)
+	Expi          int64               `json:"expi"`          // 可访问url的过期时间,目前为1200秒
+	Type          string              `json:"type"`          // 类型eg: mp3、FLAC
+	Gain          float64             `json:"gain"`          // 响度增益
+	Peak          float64             `json:"peak"`          // 峰值
+	Fee           int64               `json:"fee"`           // 收费类型 see: types.Free
+	Payed         int64               `json:"payed"`         // 是否已购买
+	Flag          int64               `json:"flag"`          // 未知
+	Level         string              `json:"level"`         // 音质水平 see: types.Level
+	EncodeType    string              `json:"encodeType"`    // eg: mp3
+	ChannelLayout interface{}         `json:"channelLayout"` // 声道布局,空间音频音质时返回 eg: 5.1、7.1.4
+	FreeTrialInfo types.FreeTrialInfo `json:"freeTrialInfo"` // 试听信息,非空时url为试听片段
+	Time          int64               `json:"time"`          // 音乐时长,单位毫秒
+}
+
+// SongPlayerV1 音乐播放详情,eapi版本支持全部音质级别,包括 hires、jyeffect、sky、jymaster
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e9%9f%b3%e4%b9%90-url---%e6%96%b0%e7%89%88
+// needLogin: 否(非会员只能获取部分音质)
+// 提示: 获取的歌曲url有时效性,失效时间目前测试为20分钟,过期访问则会出现403错误
+func (a *Api) SongPlayerV1(ctx context.Context, req *SongPlayerV1Req) (*SongPlayerV1Resp, error) {
+	var (
+		url   = "https://interface.music.163.com/eapi/song/enhance/player/url/v1"
+		reply SongPlayerV1Resp
+		opts  = api.NewOptions()
+	)
+	opts.CryptoMode = api.CryptoModeEAPI
+	if req.Level == types.LevelSky && req.ImmerseType == "" {
+		req.ImmerseType = types.ImmerseTypeC51
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
@@ -39,7 +39,7 @@ type YunBeiSignInReq struct {
 // YunBeiSignInResp 签到返回
 type YunBeiSignInResp struct {
 	// Code 错误码 -2:重复签到 200:成功(会有例外会出现“功能暂不支持”) 301:未登录
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	// Point 签到获得积分奖励数量
 	Point int64 `json:"point"`
 }
@@ -54,7 +54,9 @@ func (a *Api) YunBeiSignIn(ctx context.Context, req *YunBeiSignInReq) (*YunBeiSi
 		reply YunBeiSignInResp
 		opts  = api.NewOptions()
 	)
+	opts.Codes = []int64{-2} // 重复签到
 	opts.CryptoMode = api.CryptoModeEAPI
+	opts.Mutating = true
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
 		return nil, fmt.Errorf("Request: %w", err)
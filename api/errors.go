@@ -0,0 +1,99 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Error 接口业务错误,包含接口返回码、返回说明以及错误分类,see types.Error
+type Error = types.Error
+
+// ErrorCategory 错误分类
+type ErrorCategory = types.ErrorCategory
+
+const (
+	CategoryNeedLogin     = types.CategoryNeedLogin
+	CategoryBadRequest    = types.CategoryBadRequest
+	CategoryForbidden     = types.CategoryForbidden
+	CategoryNotFound      = types.CategoryNotFound
+	CategoryRateLimit     = types.CategoryRateLimit
+	CategoryRiskControl   = types.CategoryRiskControl
+	CategoryAuth          = types.CategoryAuth
+	CategoryRegionBlocked = types.CategoryRegionBlocked
+	CategoryServer        = types.CategoryServer
+	CategoryUnknown       = types.CategoryUnknown
+)
+
+// 可通过 errors.Is 判断错误分类,例如: errors.Is(err, api.ErrNeedLogin)
+var (
+	ErrNeedLogin     = types.ErrNeedLogin
+	ErrBadRequest    = types.ErrBadRequest
+	ErrForbidden     = types.ErrForbidden
+	ErrNotFound      = types.ErrNotFound
+	ErrRateLimit     = types.ErrRateLimit
+	ErrRiskControl   = types.ErrRiskControl
+	ErrAuth          = types.ErrAuth
+	ErrRegionBlocked = types.ErrRegionBlocked
+	ErrServer        = types.ErrServer
+)
+
+// AsError 获取错误链中的业务错误
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// CodeError 接口返回码非200时返回 *Error,调用方可直接通过 errors.Is 判断错误分类,而不需要再调用响应的Err()方法。
+// 客户端默认已添加该中间件,出错时解析后的响应内容仍然可以读取。
+// 部分接口会用非200的返回码表示正常的业务状态,例如扫码登录的800~803,这类接口通过 Options.Codes 指定,由调用方自行判断返回码。
+// 返回码为0时认为响应中没有返回码,不作为错误处理。
+func CodeError() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			resp, err := next(ctx, call)
+			if err != nil {
+				return resp, err
+			}
+			r, ok := call.Resp.(interface{ Err() error })
+			if !ok {
+				return resp, nil
+			}
+			e, ok := AsError(r.Err())
+			if !ok || e.Code == 0 || (call.Opts != nil && slices.Contains(call.Opts.Codes, e.Code)) {
+				return resp, nil
+			}
+			return resp, e
+		}
+	}
+}
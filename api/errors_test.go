@@ -0,0 +1,111 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeError(t *testing.T) {
+	var (
+		h = CodeError()(func(ctx context.Context, call *Call) (*resty.Response, error) {
+			return nil, nil
+		})
+		resp = fakeResp{Code: 301}
+	)
+	_, err := h(context.Background(), &Call{Resp: &resp})
+	assert.ErrorIs(t, fmt.Errorf("Request: %w", err), ErrNeedLogin)
+	e, ok := AsError(err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(301), e.Code)
+	// 出错时响应内容仍然可以读取
+	assert.Equal(t, int64(301), resp.Code)
+
+	resp.Code = 200
+	_, err = h(context.Background(), &Call{Resp: &resp})
+	assert.NoError(t, err)
+
+	// 响应中没有返回码
+	resp.Code = 0
+	_, err = h(context.Background(), &Call{Resp: &resp})
+	assert.NoError(t, err)
+
+	_, ok = AsError(errors.New("failed"))
+	assert.False(t, ok)
+}
+
+func TestCodeErrorBackoff(t *testing.T) {
+	var (
+		calls  int
+		client = &Client{cfg: &Config{Backoff: BackoffConfig{Attempts: 1, Min: time.Millisecond, Codes: []int64{-460}}}}
+		h      = Chain(client.backoff(), CodeError())(func(ctx context.Context, call *Call) (*resty.Response, error) {
+			calls++
+			call.Resp.(*fakeResp).Code = -460
+			return nil, nil
+		})
+	)
+	_, err := h(context.Background(), &Call{Resp: &fakeResp{}})
+	assert.ErrorIs(t, err, ErrRiskControl)
+	assert.Equal(t, 2, calls)
+}
+
+func TestClientCodeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":301,"message":"需要登录"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		client = New(&Config{Timeout: time.Second, Cookie: cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")}})
+		reply  types.RespCommon[any]
+		opts   = NewOptions()
+	)
+	opts.CryptoMode = CryptoModeAPI
+	_, err := client.Request(context.Background(), srv.URL+"/api/test", map[string]string{}, &reply, opts)
+	assert.ErrorIs(t, fmt.Errorf("Request: %w", err), ErrNeedLogin)
+	e, ok := AsError(err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(301), e.Code)
+	assert.Equal(t, "需要登录", e.Message)
+	assert.Equal(t, int64(301), reply.Code)
+
+	// 通过 Options.Codes 指定的返回码由调用方自行判断
+	opts.Codes = []int64{301}
+	_, err = client.Request(context.Background(), srv.URL+"/api/test", map[string]string{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(301), reply.Code)
+}
@@ -0,0 +1,85 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RoundTripFunc 发送一次http请求,实现了 http.RoundTripper 接口
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Interceptor http请求拦截器,作用于参数加密之后实际发送的http请求,可用于添加请求头、修改请求以及记录原始请求响应等。
+// 拦截器通过 Intercept 中间件加入调用链,只作用于经过中间件的接口调用,resty层面的每次重试都会经过拦截器。
+// 注意: 修改请求时应先调用 req.Clone 复制一份请求。
+type Interceptor func(next RoundTripFunc) RoundTripFunc
+
+type interceptorsKey struct{}
+
+// Intercept 返回一个中间件,使位于其之后的接口调用实际发送的http请求经过拦截器i,先传入的拦截器位于最外层
+func Intercept(i ...Interceptor) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			var outer, _ = ctx.Value(interceptorsKey{}).([]Interceptor)
+			return next(context.WithValue(ctx, interceptorsKey{}, append(slices.Clip(outer), i...)), call)
+		}
+	}
+}
+
+// Intercept 添加http请求拦截器,等价于 c.Use(Intercept(i...))。
+// 需要在发起请求之前调用,不支持并发调用。
+func (c *Client) Intercept(i ...Interceptor) {
+	c.Use(Intercept(i...))
+}
+
+// roundTrip 按请求context中由 Intercept 中间件设置的拦截器发送http请求
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		interceptors, _ = req.Context().Value(interceptorsKey{}).([]Interceptor)
+		next            = RoundTripFunc(c.transport.RoundTrip)
+	)
+	for j := len(interceptors) - 1; j >= 0; j-- {
+		next = interceptors[j](next)
+	}
+	return next(req)
+}
+
+// SetHeader 为每个http请求设置请求头,会覆盖已存在的同名请求头
+func SetHeader(key, value string) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set(key, value)
+			return next(req)
+		}
+	}
+}
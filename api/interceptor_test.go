@@ -0,0 +1,79 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntercept(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"code":200,"message":%q}`, r.Header.Get("X-Test"))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{Cookie: cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")}}, log.Default)
+	assert.NoError(t, err)
+
+	var (
+		order []string
+		mark  = func(name string) Interceptor {
+			return func(next RoundTripFunc) RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					order = append(order, name+":"+req.Header.Get("X-Test"))
+					return next(req)
+				}
+			}
+		}
+	)
+	client.Intercept(mark("a"), SetHeader("X-Test", "1"))
+	client.Intercept(mark("b"))
+
+	var (
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+	)
+	opts.CryptoMode = CryptoModeAPI
+	_, err = client.Request(context.Background(), srv.URL+"/api/test", map[string]string{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", reply.Message)
+	assert.Equal(t, []string{"a:", "b:1"}, order)
+
+	// 拦截器只作用于经过中间件的接口调用
+	order = nil
+	_, err = client.NewRequest().Get(srv.URL)
+	assert.NoError(t, err)
+	assert.Empty(t, order)
+}
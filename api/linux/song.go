@@ -0,0 +1,75 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package linux
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+)
+
+type SongPlayerReq struct {
+	Ids types.IntsString `json:"ids"` // 歌曲id
+	Br  int64            `json:"br"`  // 音乐bit率 例如:128000 320000 999000
+}
+
+type SongPlayerResp struct {
+	types.RespCommon[[]SongPlayerRespData]
+}
+
+type SongPlayerRespData struct {
+	Id            int64               `json:"id"`
+	Url           string              `json:"url"`
+	Br            int64               `json:"br"`
+	Size          int64               `json:"size"`
+	Md5           string              `json:"md5"`
+	Code          int64               `json:"code"` // 歌曲状态 200:正常 404:歌曲下架(也就是变灰歌曲)
+	Expi          int64               `json:"expi"`
+	Type          string              `json:"type"` // 类型eg: mp3、FLAC
+	Fee           int64               `json:"fee"`
+	Level         string              `json:"level"`
+	EncodeType    string              `json:"encodeType"`
+	FreeTrialInfo types.FreeTrialInfo `json:"freeTrialInfo"`
+}
+
+// SongPlayer 音乐播放详情,模拟linux客户端请求
+// url:
+// needLogin: 未知
+// 提示: 获取的歌曲url有时效性,失效时间目前测试为20分钟,过期访问则会出现403错误
+func (a *Api) SongPlayer(ctx context.Context, req *SongPlayerReq) (*SongPlayerResp, error) {
+	var (
+		url   = "https://music.163.com/api/song/enhance/player/url"
+		reply SongPlayerResp
+		opts  = api.NewOptions()
+	)
+	opts.CryptoMode = api.CryptoModeLinux
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
@@ -0,0 +1,89 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Call 一次接口调用的请求信息,中间件可以读取或修改其中的内容
+type Call struct {
+	Url  string
+	Req  interface{}
+	Resp interface{}
+	Opts *Options
+	Body []byte        // 解密后的响应内容,请求完成后设置
+	Wait time.Duration // 限流等待时长,由 Limit 中间件设置
+}
+
+// Handler 执行一次接口调用
+type Handler func(ctx context.Context, call *Call) (*resty.Response, error)
+
+// Middleware 接口调用中间件,用于组合日志、限流、重试、统计等通用逻辑
+type Middleware func(next Handler) Handler
+
+// Chain 将多个中间件组合成一个,先传入的中间件位于最外层
+func Chain(m ...Middleware) Middleware {
+	return func(next Handler) Handler {
+		for i := len(m) - 1; i >= 0; i-- {
+			next = m[i](next)
+		}
+		return next
+	}
+}
+
+// Logging 记录每次接口调用的耗时以及错误信息,日志输出到 log.Default
+func Logging() Middleware {
+	return LoggingWith(nil)
+}
+
+// LoggingWith 记录每次接口调用的耗时以及错误信息,日志输出到l,l为空时输出到 log.Default
+func LoggingWith(l *log.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			var start = time.Now()
+			resp, err := next(ctx, call)
+			if err != nil {
+				l.Warn("[api] trace=%s url=%s cost=%s err=%s", TraceId(ctx), call.Url, time.Since(start), err)
+				return resp, err
+			}
+			l.Debug("[api] trace=%s url=%s cost=%s", TraceId(ctx), call.Url, time.Since(start))
+			return resp, nil
+		}
+	}
+}
+
+// RateLimit 限制两次接口调用之间的最小间隔,避免请求过于频繁触发风控。
+// 等价于 Limit(RateLimitConfig{Rate: 1/interval, Burst: 1}),interval<=0时不限制
+func RateLimit(interval time.Duration) Middleware {
+	if interval <= 0 {
+		return func(next Handler) Handler { return next }
+	}
+	return Limit(RateLimitConfig{Rate: float64(time.Second) / float64(interval), Burst: 1})
+}
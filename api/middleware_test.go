@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	var (
+		order []string
+		mark  = func(name string) Middleware {
+			return func(next Handler) Handler {
+				return func(ctx context.Context, call *Call) (*resty.Response, error) {
+					order = append(order, name)
+					return next(ctx, call)
+				}
+			}
+		}
+		handler = func(ctx context.Context, call *Call) (*resty.Response, error) {
+			order = append(order, "handler")
+			return nil, nil
+		}
+	)
+	_, err := Chain(mark("a"), mark("b"))(handler)(context.Background(), &Call{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "handler"}, order)
+}
+
+func TestRateLimit(t *testing.T) {
+	var (
+		handler = func(ctx context.Context, call *Call) (*resty.Response, error) { return nil, nil }
+		limit   = RateLimit(time.Millisecond * 20)(handler)
+		start   = time.Now()
+	)
+	for i := 0; i < 3; i++ {
+		_, err := limit(context.Background(), &Call{})
+		assert.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*40)
+
+	// 不限制
+	limit, start = RateLimit(0)(handler), time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := limit(context.Background(), &Call{})
+		assert.NoError(t, err)
+	}
+	assert.Less(t, time.Since(start), time.Millisecond*20)
+}
+
+func TestTracing(t *testing.T) {
+	var (
+		got     []string
+		handler = func(ctx context.Context, call *Call) (*resty.Response, error) {
+			got = append(got, TraceId(ctx))
+			return nil, nil
+		}
+		h = Tracing()(handler)
+	)
+	_, _ = h(context.Background(), &Call{})
+	_, _ = h(context.Background(), &Call{})
+	assert.Len(t, got, 2)
+	assert.NotEmpty(t, got[0])
+	assert.NotEqual(t, got[0], got[1])
+
+	ctx := WithTraceId(context.Background(), "trace-1")
+	_, _ = h(ctx, &Call{})
+	assert.Equal(t, "trace-1", got[2])
+}
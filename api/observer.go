@@ -0,0 +1,124 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Observer 接口调用指标观察者,用于统计接口调用次数、耗时以及限流等待时长等,实现需要并发安全。
+// 通过 Config.Observer 或 WithObserver 设置,由 Observe 中间件上报。
+type Observer interface {
+	// ObserveCall 一次接口调用完成,code为业务返回码,成功时为200,网络错误等无法获取返回码时为0
+	ObserveCall(url string, code int64, cost time.Duration, err error)
+	// ObserveWait 接口调用因限流等待了wait时长
+	ObserveWait(url string, wait time.Duration)
+}
+
+// Observe 将每次接口调用的结果上报给o,backoff重试时每次调用都会上报。
+// 需要位于 Limit 中间件之前才能上报限流等待时长,上报的调用耗时不包含限流等待时长
+func Observe(o Observer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			var start = time.Now()
+			call.Wait = 0
+			resp, err := next(ctx, call)
+			var cost = time.Since(start) - call.Wait
+			if call.Wait > 0 {
+				o.ObserveWait(call.Url, call.Wait)
+			}
+			var code = respCode(call.Resp, err)
+			if code == 0 && err == nil {
+				code = 200
+			}
+			o.ObserveCall(call.Url, code, cost, err)
+			return resp, err
+		}
+	}
+}
+
+// Stat 接口调用统计信息
+type Stat struct {
+	Calls    int64         // 调用次数
+	Errors   int64         // 失败次数
+	Duration time.Duration // 累计耗时
+	Wait     time.Duration // 累计限流等待时长
+}
+
+// Metrics 按接口路径在内存中统计调用次数、失败次数以及耗时,实现了 Observer,
+// 通过 WithObserver 设置或者 Client.Use(api.Observe(m)) 添加。
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]Stat
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[string]Stat)}
+}
+
+func (m *Metrics) ObserveCall(rawUrl string, code int64, cost time.Duration, err error) {
+	var path = urlPath(rawUrl)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.stats[path]
+	s.Calls++
+	s.Duration += cost
+	if err != nil {
+		s.Errors++
+	}
+	m.stats[path] = s
+}
+
+func (m *Metrics) ObserveWait(rawUrl string, wait time.Duration) {
+	var path = urlPath(rawUrl)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.stats[path]
+	s.Wait += wait
+	m.stats[path] = s
+}
+
+// Snapshot 获取当前统计信息
+func (m *Metrics) Snapshot() map[string]Stat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var stats = make(map[string]Stat, len(m.stats))
+	for k, v := range m.stats {
+		stats[k] = v
+	}
+	return stats
+}
+
+// urlPath 获取接口路径,解析失败时返回原始地址
+func urlPath(rawUrl string) string {
+	if u, err := url.Parse(rawUrl); err == nil {
+		return u.Path
+	}
+	return rawUrl
+}
@@ -0,0 +1,117 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordObserver struct {
+	mu    sync.Mutex
+	codes []int64
+	waits []time.Duration
+}
+
+func (r *recordObserver) ObserveCall(url string, code int64, cost time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codes = append(r.codes, code)
+}
+
+func (r *recordObserver) ObserveWait(url string, wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waits = append(r.waits, wait)
+}
+
+func TestObserve(t *testing.T) {
+	var (
+		o       recordObserver
+		code    int64
+		fail    error
+		handler = Observe(&o)(func(ctx context.Context, call *Call) (*resty.Response, error) {
+			call.Resp.(*types.RespCommon[any]).Code = code
+			return nil, fail
+		})
+	)
+	for _, c := range []int64{200, 301} {
+		code = c
+		_, err := handler(context.Background(), &Call{Url: "https://music.163.com/weapi/test", Resp: &types.RespCommon[any]{}})
+		assert.NoError(t, err)
+	}
+	code, fail = 0, errors.New("network")
+	_, err := handler(context.Background(), &Call{Url: "https://music.163.com/weapi/test", Resp: &types.RespCommon[any]{}})
+	assert.Error(t, err)
+	assert.Equal(t, []int64{200, 301, 0}, o.codes)
+}
+
+func TestLimitObserveWait(t *testing.T) {
+	var (
+		o       recordObserver
+		handler = Chain(Observe(&o), Limit(RateLimitConfig{Rate: 100, Burst: 1}))(func(ctx context.Context, call *Call) (*resty.Response, error) {
+			return nil, nil
+		})
+	)
+	for i := 0; i < 3; i++ {
+		_, err := handler(context.Background(), &Call{Url: "https://music.163.com/weapi/test"})
+		assert.NoError(t, err)
+	}
+	assert.Len(t, o.codes, 3)
+	assert.Len(t, o.waits, 2)
+	for _, w := range o.waits {
+		assert.Greater(t, w, time.Duration(0))
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	var (
+		m       = NewMetrics()
+		handler = func(ctx context.Context, call *Call) (*resty.Response, error) {
+			if call.Url == "https://music.163.com/weapi/fail" {
+				return nil, errors.New("failed")
+			}
+			return nil, nil
+		}
+		h = Observe(m)(handler)
+	)
+	_, _ = h(context.Background(), &Call{Url: "https://music.163.com/weapi/ok?csrf_token="})
+	_, _ = h(context.Background(), &Call{Url: "https://music.163.com/weapi/ok"})
+	_, _ = h(context.Background(), &Call{Url: "https://music.163.com/weapi/fail"})
+	m.ObserveWait("https://music.163.com/weapi/ok", time.Second)
+
+	stats := m.Snapshot()
+	assert.Equal(t, int64(2), stats["/weapi/ok"].Calls)
+	assert.Equal(t, int64(0), stats["/weapi/ok"].Errors)
+	assert.Equal(t, time.Second, stats["/weapi/ok"].Wait)
+	assert.Equal(t, int64(1), stats["/weapi/fail"].Errors)
+}
@@ -39,6 +39,9 @@ type Options struct {
 	CryptoMode CryptoMode
 	Headers    map[string]string
 	Cookies    []*http.Cookie
+	Backoff    *BackoffConfig // 不为空时覆盖客户端的重试配置
+	Codes      []int64        // 表示正常业务状态的非200返回码,不会作为 *Error 返回 eg: 扫码登录的800~803
+	Mutating   bool           // 会修改账号数据的接口,例如编辑歌单、喜欢歌曲、上传云盘、发表评论、听歌打卡,dry-run模式下不会发送
 }
 
 func (o *Options) SetCookies(c ...*http.Cookie) {
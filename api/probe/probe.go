@@ -0,0 +1,246 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package probe 对比接口实际返回的json与本库定义的响应结构体,
+// 用于及早发现网易云接口字段变更(新增字段、字段类型变化等)。
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Record 一次接口调用的原始响应
+type Record struct {
+	Url  string
+	Body []byte      // 原始响应内容
+	Resp interface{} // 本库定义的响应结构体
+}
+
+// Recorder 记录接口调用的原始响应,仅适用于返回明文json的接口
+type Recorder struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Middleware 返回记录响应的中间件
+func (r *Recorder) Middleware() api.Middleware {
+	return func(next api.Handler) api.Handler {
+		return func(ctx context.Context, call *api.Call) (*resty.Response, error) {
+			resp, err := next(ctx, call)
+			if resp != nil {
+				r.mu.Lock()
+				r.records = append(r.records, Record{Url: call.Url, Body: resp.Body(), Resp: call.Resp})
+				r.mu.Unlock()
+			}
+			return resp, err
+		}
+	}
+}
+
+// Take 返回已记录的响应并清空
+func (r *Recorder) Take() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list = r.records
+	r.records = nil
+	return list
+}
+
+// Report 响应结构对比结果,路径格式为 a.b[].c
+type Report struct {
+	Unknown  []string // 响应中存在但结构体未定义的字段
+	Mismatch []string // 响应字段类型与结构体定义不一致
+}
+
+// Changed 结构是否发生变化
+func (r *Report) Changed() bool {
+	return len(r.Unknown) > 0 || len(r.Mismatch) > 0
+}
+
+var unmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Check 对比原始响应data与结构体v的定义,v为结构体或结构体指针
+func Check(data []byte, v interface{}) (*Report, error) {
+	var (
+		value   interface{}
+		decoder = json.NewDecoder(bytes.NewReader(data))
+	)
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	var (
+		unknown  = make(map[string]struct{})
+		mismatch = make(map[string]struct{})
+	)
+	walk("", value, reflect.TypeOf(v), unknown, mismatch)
+	return &Report{Unknown: sortedKeys(unknown), Mismatch: sortedKeys(mismatch)}, nil
+}
+
+func walk(path string, value interface{}, t reflect.Type, unknown, mismatch map[string]struct{}) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// 未定义类型、interface{}以及自定义解析的类型不做检查
+	if value == nil || t == nil || t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(unmarshaler) {
+		return
+	}
+
+	var got string
+	switch val := value.(type) {
+	case map[string]interface{}:
+		got = "object"
+		switch t.Kind() {
+		case reflect.Struct:
+			var fields = structFields(t)
+			for k, v := range val {
+				f, ok := lookup(fields, k)
+				if !ok {
+					unknown[join(path, k)] = struct{}{}
+					continue
+				}
+				walk(join(path, k), v, f.Type, unknown, mismatch)
+			}
+			return
+		case reflect.Map:
+			for _, v := range val {
+				walk(path+".*", v, t.Elem(), unknown, mismatch)
+			}
+			return
+		}
+	case []interface{}:
+		got = "array"
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, v := range val {
+				walk(path+"[]", v, t.Elem(), unknown, mismatch)
+			}
+			return
+		}
+	case json.Number:
+		got = "number"
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if strings.ContainsAny(val.String(), ".eE") {
+				got = "float"
+				break
+			}
+			return
+		case reflect.Float32, reflect.Float64:
+			return
+		}
+	case string:
+		got = "string"
+		if t.Kind() == reflect.String {
+			return
+		}
+	case bool:
+		got = "bool"
+		if t.Kind() == reflect.Bool {
+			return
+		}
+	}
+	mismatch[fmt.Sprintf("%s: want %s got %s", strings.TrimPrefix(path, "."), t.Kind(), got)] = struct{}{}
+}
+
+// structFields 返回结构体json字段名->字段,匿名嵌入且没有json名称的结构体字段会被展开
+func structFields(t reflect.Type) map[string]reflect.StructField {
+	var fields = make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			f            = t.Field(i)
+			name, opt, _ = strings.Cut(f.Tag.Get("json"), ",")
+		)
+		if name == "-" && opt == "" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			var ft = f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range structFields(ft) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		// 使用 ,string 选项的字段在json中为字符串
+		if strings.Contains(opt, "string") {
+			f.Type = reflect.TypeOf("")
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// lookup 与encoding/json一致,精确匹配失败时忽略大小写匹配
+func lookup(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if f, ok := fields[key]; ok {
+		return f, true
+	}
+	for k, f := range fields {
+		if strings.EqualFold(k, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func join(path, key string) string {
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	var list = make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, strings.TrimPrefix(k, "."))
+	}
+	sort.Strings(list)
+	return list
+}
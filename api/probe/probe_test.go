@@ -0,0 +1,114 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package probe
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type song struct {
+	Id     int64          `json:"id"`
+	Name   string         `json:"name"`
+	Ar     []types.Artist `json:"ar"`
+	Extra  interface{}    `json:"extra"`
+	Count  int64          `json:"count,string"`
+	Scores map[string]int `json:"scores"`
+}
+
+type songResp struct {
+	types.RespCommon[any]
+	Songs []song `json:"songs"`
+}
+
+func TestCheck(t *testing.T) {
+	var tests = []struct {
+		name     string
+		data     string
+		unknown  []string
+		mismatch []string
+	}{
+		{
+			name: "same",
+			data: `{"code":200,"data":{"a":1},"songs":[{"id":1,"name":"a","ar":[{"id":1,"name":"b"}],"extra":{"x":1},"count":"3","scores":{"a":1}}]}`,
+		},
+		{
+			name:    "unknown",
+			data:    `{"code":200,"more":true,"songs":[{"id":1,"mv":0},{"id":2,"mv":1,"ar":[{"id":1,"nick":"c"}]}]}`,
+			unknown: []string{"more", "songs[].ar[].nick", "songs[].mv"},
+		},
+		{
+			name:     "mismatch",
+			data:     `{"code":"200","songs":[{"id":1.5,"name":1,"ar":{},"scores":{"a":"1"}}]}`,
+			mismatch: []string{"code: want int64 got string", "songs[].ar: want slice got object", "songs[].id: want int64 got float", "songs[].name: want string got number", "songs[].scores.*: want int got string"},
+		},
+		{
+			name: "null",
+			data: `{"code":200,"songs":null,"msg":null}`,
+		},
+		{
+			name: "case insensitive",
+			data: `{"Code":200,"SONGS":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Check([]byte(tt.data), &songResp{})
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.unknown, report.Unknown)
+			assert.ElementsMatch(t, tt.mismatch, report.Mismatch)
+			assert.Equal(t, len(tt.unknown)+len(tt.mismatch) > 0, report.Changed())
+		})
+	}
+
+	_, err := Check([]byte(`{`), &songResp{})
+	assert.Error(t, err)
+}
+
+func TestRecorder(t *testing.T) {
+	var (
+		r    = NewRecorder()
+		body = []byte(`{"code":200}`)
+		resp = &songResp{}
+		next = func(ctx context.Context, call *api.Call) (*resty.Response, error) {
+			return (&resty.Response{RawResponse: &http.Response{}}).SetBody(body), nil
+		}
+	)
+	_, err := r.Middleware()(next)(context.Background(), &api.Call{Url: "u", Resp: resp})
+	assert.NoError(t, err)
+
+	list := r.Take()
+	assert.Len(t, list, 1)
+	assert.Equal(t, "u", list[0].Url)
+	assert.Equal(t, body, list[0].Body)
+	assert.Same(t, resp, list[0].Resp)
+	assert.Empty(t, r.Take())
+}
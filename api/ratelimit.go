@@ -0,0 +1,168 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RateLimitConfig 接口请求限流配置,用于批量操作时控制请求频率避免触发风控
+type RateLimitConfig struct {
+	// Rate 每秒允许的请求数,0表示不限制
+	Rate float64 `json:"rate" yaml:"rate"`
+	// Burst 允许的突发请求数,小于1时为1
+	Burst int `json:"burst" yaml:"burst"`
+	// Jitter 每次请求前额外随机等待[0,Jitter)时长,模拟人为操作
+	Jitter time.Duration `json:"jitter" yaml:"jitter"`
+	// Endpoints 指定接口的限流配置,按接口路径前缀匹配,匹配多个时使用最长的前缀。
+	// 匹配的接口同时受全局限流限制
+	Endpoints []EndpointRateLimit `json:"endpoints" yaml:"endpoints"`
+}
+
+// EndpointRateLimit 单个接口的限流配置
+type EndpointRateLimit struct {
+	// Path 接口路径前缀 eg: /weapi/v1/playlist/manipulate/tracks
+	Path  string  `json:"path" yaml:"path"`
+	Rate  float64 `json:"rate" yaml:"rate"`
+	Burst int     `json:"burst" yaml:"burst"`
+}
+
+func (c *RateLimitConfig) Validate() error {
+	if c.Rate < 0 || c.Burst < 0 || c.Jitter < 0 {
+		return errors.New("rate, burst or jitter is < 0")
+	}
+	for _, e := range c.Endpoints {
+		if e.Path == "" {
+			return errors.New("endpoint path is empty")
+		}
+		if e.Rate <= 0 || e.Burst < 0 {
+			return fmt.Errorf("endpoint %s rate is <= 0 or burst is < 0", e.Path)
+		}
+	}
+	return nil
+}
+
+// Enable 是否配置了限流
+func (c *RateLimitConfig) Enable() bool {
+	return c.Rate > 0 || c.Jitter > 0 || len(c.Endpoints) > 0
+}
+
+// limiter 令牌桶限流器
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒生成的令牌数
+	burst  float64 // 令牌桶容量
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve 预留一个令牌,返回获取令牌需要等待的时长
+func (l *limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	if now.After(l.last) {
+		l.last = now
+	}
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Limit 按配置对接口请求限流,全局限流及接口限流需要同时满足,等待时长累加到 Call.Wait
+func Limit(cfg RateLimitConfig) Middleware {
+	var (
+		global    *limiter
+		endpoints = make(map[string]*limiter, len(cfg.Endpoints))
+	)
+	if cfg.Rate > 0 {
+		global = newLimiter(cfg.Rate, cfg.Burst)
+	}
+	for _, e := range cfg.Endpoints {
+		endpoints[e.Path] = newLimiter(e.Rate, e.Burst)
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			var (
+				now  = time.Now()
+				wait time.Duration
+			)
+			if global != nil {
+				wait = global.reserve(now)
+			}
+			if l := matchEndpoint(endpoints, call.Url); l != nil {
+				wait = max(wait, l.reserve(now))
+			}
+			if cfg.Jitter > 0 {
+				wait += time.Duration(rand.Int63n(int64(cfg.Jitter)))
+			}
+			if wait > 0 {
+				call.Wait += wait
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// matchEndpoint 返回路径前缀最长的接口限流器
+func matchEndpoint(endpoints map[string]*limiter, rawUrl string) *limiter {
+	if len(endpoints) <= 0 {
+		return nil
+	}
+	var (
+		path   = urlPath(rawUrl)
+		match  *limiter
+		length int
+	)
+	for prefix, l := range endpoints {
+		if strings.HasPrefix(path, prefix) && len(prefix) > length {
+			match, length = l, len(prefix)
+		}
+	}
+	return match
+}
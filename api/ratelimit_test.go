@@ -0,0 +1,102 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	var (
+		l   = newLimiter(2, 2)
+		now = time.Now()
+	)
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, l.reserve(now))
+	assert.Equal(t, time.Second, l.reserve(now))
+	// 经过1秒补充2个令牌,仍欠1个令牌
+	assert.Equal(t, 500*time.Millisecond, l.reserve(now.Add(time.Second)))
+	// 令牌数量不超过容量
+	assert.Equal(t, time.Duration(0), l.reserve(now.Add(time.Hour)))
+	assert.Equal(t, time.Duration(0), l.reserve(now.Add(time.Hour)))
+	assert.Equal(t, 500*time.Millisecond, l.reserve(now.Add(time.Hour)))
+}
+
+func TestMatchEndpoint(t *testing.T) {
+	var (
+		short     = newLimiter(1, 1)
+		long      = newLimiter(1, 1)
+		endpoints = map[string]*limiter{
+			"/weapi/v1/playlist":                   short,
+			"/weapi/v1/playlist/manipulate/tracks": long,
+		}
+	)
+	assert.Equal(t, long, matchEndpoint(endpoints, "https://music.163.com/weapi/v1/playlist/manipulate/tracks"))
+	assert.Equal(t, short, matchEndpoint(endpoints, "https://music.163.com/weapi/v1/playlist/detail"))
+	assert.Nil(t, matchEndpoint(endpoints, "https://music.163.com/weapi/song/detail"))
+	assert.Nil(t, matchEndpoint(nil, "https://music.163.com/weapi/song/detail"))
+}
+
+func TestLimit(t *testing.T) {
+	var (
+		calls   int
+		handler = func(ctx context.Context, call *Call) (*resty.Response, error) {
+			calls++
+			return nil, nil
+		}
+		limit = Limit(RateLimitConfig{
+			Rate:      1000,
+			Burst:     1,
+			Endpoints: []EndpointRateLimit{{Path: "/weapi/slow", Rate: 10, Burst: 1}},
+		})(handler)
+	)
+	var start = time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := limit(context.Background(), &Call{Url: "https://music.163.com/weapi/slow"})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := limit(ctx, &Call{Url: "https://music.163.com/weapi/slow"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	assert.NoError(t, (&RateLimitConfig{}).Validate())
+	assert.NoError(t, (&RateLimitConfig{Rate: 5, Burst: 10, Endpoints: []EndpointRateLimit{{Path: "/weapi", Rate: 1}}}).Validate())
+	assert.Error(t, (&RateLimitConfig{Rate: -1}).Validate())
+	assert.Error(t, (&RateLimitConfig{Endpoints: []EndpointRateLimit{{Path: "", Rate: 1}}}).Validate())
+	assert.Error(t, (&RateLimitConfig{Endpoints: []EndpointRateLimit{{Path: "/weapi", Rate: 0}}}).Validate())
+	assert.False(t, (&RateLimitConfig{}).Enable())
+	assert.True(t, (&RateLimitConfig{Rate: 1}).Enable())
+}
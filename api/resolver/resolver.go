@@ -0,0 +1,247 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package resolver 根据歌曲id解析出可播放、下载的音乐资源地址。
+// 内部处理了版权检查、音质降级以及地址缓存,供下载、播放等场景复用。
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+)
+
+var (
+	// ErrNotFound 歌曲不存在
+	ErrNotFound = errors.New("song not found")
+	// ErrNoCopyright 资源已下架或无版权(变灰歌曲)
+	ErrNoCopyright = errors.New("no copyright")
+	// ErrNoSource 无音源
+	ErrNoSource = errors.New("no source")
+	// ErrQualityUnavailable 歌曲不支持指定得音质,仅在 Options.Strict 为true时返回
+	ErrQualityUnavailable = errors.New("quality unavailable")
+)
+
+// Error 解析歌曲地址失败时返回得错误,可通过 errors.Is 判断具体原因
+type Error struct {
+	SongId int64 // 歌曲id
+	Code   int64 // 服务端返回得状态码
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("resolve %d: %s (code: %d)", e.SongId, e.Err, e.Code)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Stream 歌曲资源信息
+type Stream struct {
+	Id         int64       // 歌曲id
+	Url        string      // 资源地址,有时效性
+	Level      types.Level // 实际音质
+	Br         int64       // 码率
+	Size       int64       // 文件大小单位字节
+	Md5        string      // 文件MD5值
+	Type       string      // 文件类型 eg: mp3、flac
+	EncodeType string      // 编码类型
+	Fee        int64       // 费用情况 see: types.Free
+	Time       int64       // 音乐时长,单位毫秒
+	Expire     time.Time   // 资源地址过期时间
+}
+
+// Expired 资源地址是否已过期
+func (s *Stream) Expired() bool {
+	return !time.Now().Before(s.Expire)
+}
+
+type Options struct {
+	// Strict 严格模式,当歌曲不支持指定音质时返回 ErrQualityUnavailable 而不是降级
+	Strict bool
+	// EncodeType 音乐格式 eg: mp3、aac、flac
+	EncodeType string
+	// Margin 地址过期前预留得时间,避免拿到地址后马上过期,默认1分钟
+	Margin time.Duration
+}
+
+type Resolver struct {
+	api   *weapi.Api
+	opts  Options
+	mu    sync.Mutex
+	cache map[cacheKey]*Stream
+}
+
+type cacheKey struct {
+	id    int64
+	level types.Level
+}
+
+func New(request *weapi.Api, opts *Options) *Resolver {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Margin <= 0 {
+		o.Margin = time.Minute
+	}
+	return &Resolver{
+		api:   request,
+		opts:  o,
+		cache: make(map[cacheKey]*Stream),
+	}
+}
+
+// StreamURL 获取歌曲资源地址,当歌曲不支持指定音质时会降级到最接近得音质。
+// 获取到得地址会缓存至过期前,过期后重新获取。
+func (r *Resolver) StreamURL(ctx context.Context, songId int64, level types.Level) (*Stream, error) {
+	var key = cacheKey{id: songId, level: level}
+	if s, ok := r.get(key); ok {
+		return s, nil
+	}
+
+	if err := r.privilege(ctx, songId); err != nil {
+		return nil, err
+	}
+
+	// 查询音乐支持哪些音质
+	quality, err := r.api.SongMusicQuality(ctx, &weapi.SongMusicQualityReq{SongId: strconv.FormatInt(songId, 10)})
+	if err != nil {
+		return nil, requestError(songId, "SongMusicQuality", err)
+	}
+	_, real, ok := quality.Data.Qualities.FindBetter(level)
+	if !ok && r.opts.Strict {
+		return nil, &Error{SongId: songId, Code: quality.Code, Err: ErrQualityUnavailable}
+	}
+
+	resp, err := r.api.SongPlayerV1(ctx, &weapi.SongPlayerV1Req{
+		Ids:        types.IntsString{songId},
+		Level:      real,
+		EncodeType: r.opts.EncodeType,
+	})
+	if err != nil {
+		return nil, requestError(songId, "SongPlayerV1", err)
+	}
+	if len(resp.Data) <= 0 {
+		return nil, &Error{SongId: songId, Code: resp.Code, Err: ErrNotFound}
+	}
+	var data = resp.Data[0]
+	if data.Code != 200 || data.Url == "" {
+		switch data.Code {
+		case -110:
+			return nil, &Error{SongId: songId, Code: data.Code, Err: ErrNoSource}
+		default:
+			return nil, &Error{SongId: songId, Code: data.Code, Err: ErrNoCopyright}
+		}
+	}
+
+	var s = &Stream{
+		Id:         data.Id,
+		Url:        data.Url,
+		Level:      types.Level(data.Level),
+		Br:         data.Br,
+		Size:       data.Size,
+		Md5:        data.Md5,
+		Type:       data.Type,
+		EncodeType: data.EncodeType,
+		Fee:        data.Fee,
+		Time:       data.Time,
+		Expire:     time.Now().Add(time.Duration(data.Expi)*time.Second - r.opts.Margin),
+	}
+	r.set(key, s)
+	return s, nil
+}
+
+// Invalidate 清除歌曲所有音质得缓存地址,通常用于访问地址出现403时
+func (r *Resolver) Invalidate(songId int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k := range r.cache {
+		if k.id == songId {
+			delete(r.cache, k)
+		}
+	}
+}
+
+// requestError 接口返回码非200时转换为 *Error,其它错误包装后返回
+func requestError(songId int64, name string, err error) error {
+	var e *types.Error
+	if errors.As(err, &e) {
+		return &Error{SongId: songId, Code: e.Code, Err: err}
+	}
+	return fmt.Errorf("%s(%v): %w", name, songId, err)
+}
+
+// privilege 检查歌曲是否存在以及是否有版权
+func (r *Resolver) privilege(ctx context.Context, songId int64) error {
+	detail, err := r.api.SongDetail(ctx, &weapi.SongDetailReq{C: []weapi.SongDetailReqList{{Id: strconv.FormatInt(songId, 10), V: 0}}})
+	if err != nil {
+		return requestError(songId, "SongDetail", err)
+	}
+	if len(detail.Songs) <= 0 {
+		return &Error{SongId: songId, Code: detail.Code, Err: ErrNotFound}
+	}
+	for _, p := range detail.Privileges {
+		if p.Id != songId {
+			continue
+		}
+		// 云盘歌曲解灰后st为0,因此只判断非云盘歌曲
+		if (p.St < 0 && !p.Cs) || p.Toast {
+			return &Error{SongId: songId, Code: p.St, Err: ErrNoCopyright}
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) get(key cacheKey) (*Stream, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if s.Expired() {
+		delete(r.cache, key)
+		return nil, false
+	}
+	return s, true
+}
+
+func (r *Resolver) set(key cacheKey, s *Stream) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// 顺带清理已过期得地址,避免长时间运行时缓存无限增长
+	for k, v := range r.cache {
+		if v.Expired() {
+			delete(r.cache, k)
+		}
+	}
+	r.cache[key] = s
+}
@@ -0,0 +1,67 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolverCache(t *testing.T) {
+	var (
+		r       = New(nil, nil)
+		key     = cacheKey{id: 1, level: types.LevelLossless}
+		expired = cacheKey{id: 2, level: types.LevelLossless}
+	)
+	r.set(key, &Stream{Id: 1, Url: "a", Expire: time.Now().Add(time.Minute)})
+	r.set(expired, &Stream{Id: 2, Url: "b", Expire: time.Now().Add(-time.Second)})
+
+	s, ok := r.get(key)
+	assert.True(t, ok)
+	assert.Equal(t, "a", s.Url)
+
+	_, ok = r.get(expired)
+	assert.False(t, ok)
+
+	r.Invalidate(1)
+	_, ok = r.get(key)
+	assert.False(t, ok)
+}
+
+func TestError(t *testing.T) {
+	var err error = &Error{SongId: 1, Code: -110, Err: ErrNoSource}
+	err = fmt.Errorf("wrap: %w", err)
+	assert.True(t, errors.Is(err, ErrNoSource))
+	assert.False(t, errors.Is(err, ErrNoCopyright))
+
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(-110), e.Code)
+}
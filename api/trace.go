@@ -0,0 +1,75 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type traceIdKey struct{}
+
+// WithTraceId 设置请求链路id,同一个ctx发起的请求会使用相同的链路id,便于在日志中关联一次操作中的所有请求
+func WithTraceId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIdKey{}, id)
+}
+
+// TraceId 获取ctx中的链路id,不存在时返回空字符串
+func TraceId(ctx context.Context) string {
+	id, _ := ctx.Value(traceIdKey{}).(string)
+	return id
+}
+
+// NewTraceId 生成链路id
+func NewTraceId() string {
+	var b = make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ensureTraceId ctx中不存在链路id时生成一个新的
+func ensureTraceId(ctx context.Context) (context.Context, string) {
+	if id := TraceId(ctx); id != "" {
+		return ctx, id
+	}
+	var id = NewTraceId()
+	return WithTraceId(ctx, id), id
+}
+
+// Tracing 为每次接口调用设置链路id,应位于中间件的最外层
+func Tracing() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*resty.Response, error) {
+			ctx, _ = ensureTraceId(ctx)
+			return next(ctx, call)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCategory 错误分类
+type ErrorCategory string
+
+const (
+	// CategoryNeedLogin 未登录或登录已过期
+	CategoryNeedLogin ErrorCategory = "need_login"
+	// CategoryBadRequest 请求参数错误
+	CategoryBadRequest ErrorCategory = "bad_request"
+	// CategoryForbidden 无权限、无版权或需要会员
+	CategoryForbidden ErrorCategory = "forbidden"
+	// CategoryNotFound 资源不存在
+	CategoryNotFound ErrorCategory = "not_found"
+	// CategoryRateLimit 操作频繁
+	CategoryRateLimit ErrorCategory = "rate_limit"
+	// CategoryRiskControl 触发风控,需要行为验证或更换网络环境
+	CategoryRiskControl ErrorCategory = "risk_control"
+	// CategoryAuth 账号密码或验证码错误
+	CategoryAuth ErrorCategory = "auth"
+	// CategoryRegionBlocked 版权保护,所在地区无法使用
+	CategoryRegionBlocked ErrorCategory = "region_blocked"
+	// CategoryServer 服务端内部错误
+	CategoryServer ErrorCategory = "server"
+	// CategoryUnknown 未知错误
+	CategoryUnknown ErrorCategory = "unknown"
+)
+
+// 可通过 errors.Is 判断错误分类,例如: errors.Is(err, types.ErrNeedLogin)
+var (
+	ErrNeedLogin     = &Error{Category: CategoryNeedLogin}
+	ErrBadRequest    = &Error{Category: CategoryBadRequest}
+	ErrForbidden     = &Error{Category: CategoryForbidden}
+	ErrNotFound      = &Error{Category: CategoryNotFound}
+	ErrRateLimit     = &Error{Category: CategoryRateLimit}
+	ErrRiskControl   = &Error{Category: CategoryRiskControl}
+	ErrAuth          = &Error{Category: CategoryAuth}
+	ErrRegionBlocked = &Error{Category: CategoryRegionBlocked}
+	ErrServer        = &Error{Category: CategoryServer}
+)
+
+type codeInfo struct {
+	category ErrorCategory
+	message  string
+}
+
+// codeCatalog 已知的接口返回码
+// see: https://github.com/Binaryify/NeteaseCloudMusicApi/issues
+var codeCatalog = map[int64]codeInfo{
+	250:  {CategoryRiskControl, "风控拦截,请稍后再试"},
+	301:  {CategoryNeedLogin, "需要登录"},
+	400:  {CategoryBadRequest, "参数错误"},
+	401:  {CategoryForbidden, "没有权限"},
+	403:  {CategoryForbidden, "禁止访问"},
+	404:  {CategoryNotFound, "资源不存在"},
+	405:  {CategoryRateLimit, "操作频繁,请稍后再试"},
+	406:  {CategoryRateLimit, "操作频繁,请稍后再试"},
+	500:  {CategoryServer, "服务器内部错误"},
+	501:  {CategoryAuth, "账号不存在"},
+	502:  {CategoryAuth, "密码错误"},
+	503:  {CategoryAuth, "验证码错误"},
+	509:  {CategoryRateLimit, "密码错误次数过多,请稍后再试"},
+	-460: {CategoryRiskControl, "网络环境异常(Cheating),请更换网络环境或稍后再试"},
+	-462: {CategoryRiskControl, "需要进行安全验证"},
+	8810: {CategoryRiskControl, "登录存在风险,需要进行安全验证"},
+	8821: {CategoryRiskControl, "需要行为验证码验证"},
+}
+
+// LookupCode 根据接口返回码查询错误分类及说明,未知返回码返回 CategoryUnknown
+func LookupCode(code int64) (ErrorCategory, string) {
+	if info, ok := codeCatalog[code]; ok {
+		return info.category, info.message
+	}
+	return CategoryUnknown, "未知错误"
+}
+
+// Error 接口返回码非200时的错误信息
+type Error struct {
+	Code     int64         // 接口返回码
+	Category ErrorCategory // 错误分类
+	Message  string        // 错误说明,优先使用接口返回的说明
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code: %d category: %s message: %s", e.Code, e.Category, e.Message)
+}
+
+// Is 错误分类相同则认为是同一类错误
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Category == e.Category && (t.Code == 0 || t.Code == e.Code)
+}
+
+// regionKeywords 地区限制时接口返回说明中包含的关键字,地区限制没有固定的返回码
+var regionKeywords = []string{"所在的地区", "所在地区", "海外"}
+
+// NewError 根据接口返回码以及返回说明生成错误
+func NewError(code int64, message string) *Error {
+	category, msg := LookupCode(code)
+	if message == "" {
+		message = msg
+	}
+	for _, k := range regionKeywords {
+		if strings.Contains(message, k) {
+			category = CategoryRegionBlocked
+			break
+		}
+	}
+	return &Error{Code: code, Category: category, Message: message}
+}
+
+// Err 返回码为200时返回nil,否则返回 *Error
+func (r RespCommon[T]) Err() error {
+	if r.Code == 200 {
+		return nil
+	}
+	return NewError(r.Code, r.message())
+}
+
+func (r RespCommon[T]) message() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return r.Msg
+}
+
+// Err 返回码为200时返回nil,否则返回 *Error
+func (r ApiRespCommon[T]) Err() error {
+	if r.Code == 200 {
+		return nil
+	}
+	var message = r.Message
+	if message == "" {
+		message = r.Msg
+	}
+	return NewError(r.Code, message)
+}
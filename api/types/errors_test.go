@@ -0,0 +1,67 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespCommonErr(t *testing.T) {
+	var tests = []struct {
+		name     string
+		resp     RespCommon[any]
+		target   error
+		category ErrorCategory
+		message  string
+	}{
+		{name: "ok", resp: RespCommon[any]{Code: 200}},
+		{name: "need login", resp: RespCommon[any]{Code: 301}, target: ErrNeedLogin, category: CategoryNeedLogin, message: "需要登录"},
+		{name: "server message", resp: RespCommon[any]{Code: 405, Message: "发送验证码间隔过短"}, target: ErrRateLimit, category: CategoryRateLimit, message: "发送验证码间隔过短"},
+		{name: "msg", resp: RespCommon[any]{Code: -460, Msg: "Cheating"}, target: ErrRiskControl, category: CategoryRiskControl, message: "Cheating"},
+		{name: "region", resp: RespCommon[any]{Code: 404, Message: "由于版权保护，您所在的地区暂时无法使用。"}, target: ErrRegionBlocked, category: CategoryRegionBlocked, message: "由于版权保护，您所在的地区暂时无法使用。"},
+		{name: "unknown", resp: RespCommon[any]{Code: 12345}, category: CategoryUnknown, message: "未知错误"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.resp.Err()
+			if tt.resp.Code == 200 {
+				assert.NoError(t, err)
+				return
+			}
+			var e *Error
+			assert.True(t, errors.As(fmt.Errorf("wrap: %w", err), &e))
+			assert.Equal(t, tt.resp.Code, e.Code)
+			assert.Equal(t, tt.category, e.Category)
+			assert.Equal(t, tt.message, e.Message)
+			if tt.target != nil {
+				assert.ErrorIs(t, err, tt.target)
+			}
+			assert.NotErrorIs(t, err, ErrServer)
+		})
+	}
+}
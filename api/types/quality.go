@@ -60,6 +60,14 @@ var LevelString = map[Level]string{
 	// LevelDolby: "杜比全景声(Dolby Atmos)",
 }
 
+// ImmerseTypeC51 沉浸环绕声(sky)音质使用的声道类型
+const ImmerseTypeC51 = "c51"
+
+// Spatial 是否为空间音频(高清臻音、沉浸环绕声)音质
+func (l Level) Spatial() bool {
+	return l == LevelJyeffect || l == LevelSky
+}
+
 // Quality 音质信息
 type Quality struct {
 	// Br(Bit Rate) 码率
@@ -101,8 +109,8 @@ func (q Qualities) FindBetter(l Level) (*Quality, Level, bool) {
 	var match = true
 	switch l {
 	case LevelJymaster:
-		if q.M != nil {
-			return q.M, LevelJymaster, true
+		if q.Jm != nil {
+			return q.Jm, LevelJymaster, true
 		}
 		match = false
 		fallthrough
@@ -151,3 +159,14 @@ func (q Qualities) FindBetter(l Level) (*Quality, Level, bool) {
 		return q.L, LevelStandard, false
 	}
 }
+
+// FindSpatial 获取空间音频音质信息,优先沉浸环绕声其次高清臻音,如果都不支持则返回false
+func (q Qualities) FindSpatial() (*Quality, Level, bool) {
+	if q.Sk != nil {
+		return q.Sk, LevelSky, true
+	}
+	if q.Je != nil {
+		return q.Je, LevelJyeffect, true
+	}
+	return nil, "", false
+}
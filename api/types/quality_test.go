@@ -0,0 +1,83 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualitiesFindBetter(t *testing.T) {
+	var q = Qualities{
+		L:  &Quality{Br: 128000},
+		H:  &Quality{Br: 320000},
+		Sq: &Quality{Br: 999000},
+		Jm: &Quality{Br: 1999000},
+	}
+	var tests = []struct {
+		name  string
+		level Level
+		br    int64
+		lv    Level
+		match bool
+	}{
+		{name: "jymaster", level: LevelJymaster, br: 1999000, lv: LevelJymaster, match: true},
+		{name: "sky fallback lossless", level: LevelSky, br: 999000, lv: LevelLossless, match: false},
+		{name: "hires fallback lossless", level: LevelHires, br: 999000, lv: LevelLossless, match: false},
+		{name: "exhigh", level: LevelExhigh, br: 320000, lv: LevelExhigh, match: true},
+		{name: "higher fallback standard", level: LevelHigher, br: 128000, lv: LevelStandard, match: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, lv, ok := q.FindBetter(tt.level)
+			assert.Equal(t, tt.br, got.Br)
+			assert.Equal(t, tt.lv, lv)
+			assert.Equal(t, tt.match, ok)
+		})
+	}
+}
+
+func TestQualitiesFindSpatial(t *testing.T) {
+	got, level, ok := Qualities{Je: &Quality{Br: 1}, Sk: &Quality{Br: 2}}.FindSpatial()
+	assert.True(t, ok)
+	assert.Equal(t, LevelSky, level)
+	assert.Equal(t, int64(2), got.Br)
+
+	got, level, ok = Qualities{Je: &Quality{Br: 1}}.FindSpatial()
+	assert.True(t, ok)
+	assert.Equal(t, LevelJyeffect, level)
+	assert.Equal(t, int64(1), got.Br)
+
+	got, _, ok = Qualities{Sq: &Quality{Br: 1}}.FindSpatial()
+	assert.False(t, ok)
+	assert.Nil(t, got)
+}
+
+func TestLevelSpatial(t *testing.T) {
+	assert.True(t, LevelSky.Spatial())
+	assert.True(t, LevelJyeffect.Spatial())
+	assert.False(t, LevelHires.Spatial())
+	assert.False(t, LevelJymaster.Spatial())
+}
@@ -14,9 +14,16 @@ type RespCommon[T any] struct {
 	Code    int64  `json:"code,omitempty"`
 	Message string `json:"message,omitempty"`
 	Msg     string `json:"msg,omitempty"`
-	Data    T      `json:"data,omitempty"`
+	Data    T      `json:"data,omitempty,omitzero"`
 }
 
+// Empty 用于没有data字段或忽略data内容的接口,如仅返回 {"code":200} 的操作类接口
+// 以及数据位于顶层字段的查询类接口。解码时忽略data中的任何内容,编码时省略data字段。
+type Empty struct{}
+
+// UnmarshalJSON 忽略data字段的内容,避免接口返回非对象类型时解码失败
+func (*Empty) UnmarshalJSON([]byte) error { return nil }
+
 // ApiRespCommon api接口通用返回结构
 type ApiRespCommon[T any] struct {
 	Code      int64       `json:"code,omitempty"`
@@ -24,7 +31,7 @@ type ApiRespCommon[T any] struct {
 	Msg       string      `json:"msg,omitempty"`
 	DebugInfo interface{} `json:"debugInfo,omitempty"`
 	FailData  interface{} `json:"failData,omitempty"`
-	Data      T           `json:"data,omitempty"`
+	Data      T           `json:"data,omitempty,omitzero"`
 }
 
 // // SendSMSReq 暂定此结构
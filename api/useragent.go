@@ -25,6 +25,12 @@ package api
 
 import "sync"
 
+// defaultUserAgent 默认请求使用的User-Agent,启用设备指纹时替换为设备对应的User-Agent
+const defaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034"
+
+// linuxUserAgent linux客户端请求使用的User-Agent
+const linuxUserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/60.0.3112.90 Safari/537.36"
+
 type UserAgent struct {
 	Android []string `json:"android" yaml:"android"`
 	IOS     []string `json:"ios" yaml:"ios"`
@@ -36,7 +36,7 @@ type AlbumReq struct {
 }
 
 type AlbumResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	ResourceState bool             `json:"resourceState"`
 	Songs         []AlbumRespSongs `json:"songs"`
 	Album         AlbumRespAlbum   `json:"album"`
@@ -182,3 +182,46 @@ func (a *Api) Album(ctx context.Context, req *AlbumReq) (*AlbumResp, error) {
 	_ = resp
 	return &reply, nil
 }
+
+type AlbumSublistReq struct {
+	Limit  int64 `json:"limit"`  // 每页条数,默认25
+	Offset int64 `json:"offset"` // 偏移量
+	Total  bool  `json:"total"`  // 是否返回总数
+}
+
+type AlbumSublistResp struct {
+	types.RespCommon[[]AlbumSublistRespData]
+	HasMore bool  `json:"hasMore"`
+	Count   int64 `json:"count"`
+}
+
+type AlbumSublistRespData struct {
+	Id      int64          `json:"id"`
+	Name    string         `json:"name"`
+	PicUrl  string         `json:"picUrl"`
+	Alias   []string       `json:"alias"`
+	Artists []types.Artist `json:"artists"`
+	Size    int64          `json:"size"`    // 专辑歌曲数量
+	SubTime int64          `json:"subTime"` // 收藏时间毫秒
+}
+
+// AlbumSublist 收藏的专辑列表
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e5%b7%b2%e6%94%b6%e8%97%8f%e4%b8%93%e8%be%91%e5%88%97%e8%a1%a8
+// needLogin: 是
+func (a *Api) AlbumSublist(ctx context.Context, req *AlbumSublistReq) (*AlbumSublistResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/album/sublist"
+		reply AlbumSublistResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 25
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
@@ -26,6 +26,7 @@ package weapi
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/types"
@@ -41,7 +42,7 @@ type ArtistSongsReq struct {
 }
 
 type ArtistSongsResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	More  bool                   `json:"more"`
 	Total int64                  `json:"total"`
 	Songs []ArtistSongsRespSongs `json:"songs"`
@@ -113,3 +114,191 @@ func (a *Api) ArtistSongs(ctx context.Context, req *ArtistSongsReq) (*ArtistSong
 	_ = resp
 	return &reply, nil
 }
+
+type ArtistSublistReq struct {
+	Limit  int64 `json:"limit"`  // 每页条数,默认25
+	Offset int64 `json:"offset"` // 偏移量
+	Total  bool  `json:"total"`  // 是否返回总数
+}
+
+type ArtistSublistResp struct {
+	types.RespCommon[[]ArtistSublistRespData]
+	HasMore bool  `json:"hasMore"`
+	Count   int64 `json:"count"`
+}
+
+type ArtistSublistRespData struct {
+	Id        int64    `json:"id"`
+	Name      string   `json:"name"`
+	PicUrl    string   `json:"picUrl"`
+	Alias     []string `json:"alias"`
+	AlbumSize int64    `json:"albumSize"`
+	MvSize    int64    `json:"mvSize"`
+}
+
+// ArtistSublist 关注(收藏)的歌手列表
+// url: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/blob/main/module/artist_sublist.js
+// needLogin: 是
+func (a *Api) ArtistSublist(ctx context.Context, req *ArtistSublistReq) (*ArtistSublistResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/artist/sublist"
+		reply ArtistSublistResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 25
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type ArtistAlbumsReq struct {
+	Id     int64 `json:"-"`      // 歌手id
+	Limit  int64 `json:"limit"`  // 每页条数,默认30
+	Offset int64 `json:"offset"` // 偏移量
+	Total  bool  `json:"total"`  // 是否返回总数
+}
+
+type ArtistAlbumsResp struct {
+	types.RespCommon[types.Empty]
+	More      bool                    `json:"more"`
+	Artist    ArtistAlbumsRespArtist  `json:"artist"`
+	HotAlbums []ArtistAlbumsRespAlbum `json:"hotAlbums"`
+}
+
+// ArtistAlbumsRespArtist 歌手信息
+type ArtistAlbumsRespArtist struct {
+	Id        int64  `json:"id"`
+	Name      string `json:"name"`
+	PicUrl    string `json:"picUrl"`    // 歌手图片
+	Img1V1Url string `json:"img1v1Url"` // 歌手头像(正方形)
+	BriefDesc string `json:"briefDesc"`
+	AlbumSize int64  `json:"albumSize"` // 专辑数量
+	MusicSize int64  `json:"musicSize"` // 歌曲数量
+}
+
+type ArtistAlbumsRespAlbum struct {
+	Id          int64          `json:"id"`
+	Name        string         `json:"name"`
+	PicUrl      string         `json:"picUrl"`
+	Size        int64          `json:"size"`        // 专辑歌曲数量
+	PublishTime int64          `json:"publishTime"` // 发行时间毫秒,预售专辑为未来时间
+	OnSale      bool           `json:"onSale"`
+	Paid        bool           `json:"paid"`
+	Type        string         `json:"type"`
+	SubType     string         `json:"subType"`
+	Company     string         `json:"company"`
+	Artist      types.Artist   `json:"artist"`
+	Artists     []types.Artist `json:"artists"`
+}
+
+// ArtistAlbums 歌手专辑列表,按发行时间倒序
+// url: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/blob/main/module/artist_album.js
+// needLogin: 否
+func (a *Api) ArtistAlbums(ctx context.Context, req *ArtistAlbumsReq) (*ArtistAlbumsResp, error) {
+	var (
+		url   = fmt.Sprintf("https://music.163.com/weapi/artist/albums/%d", req.Id)
+		reply ArtistAlbumsResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type ArtistSubReq struct {
+	ArtistId  string `json:"artistId"`  // 歌手id
+	ArtistIds string `json:"artistIds"` // 歌手id数组 eg: "[6452]"
+	Sub       bool   `json:"-"`         // true:关注 false:取消关注
+}
+
+// ArtistSubResp 接口没有返回数据 成功响应: {"code":200}
+type ArtistSubResp struct {
+	types.RespCommon[types.Empty]
+}
+
+// ArtistSub 关注(收藏)或取消关注歌手
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e6%94%b6%e8%97%8f%e5%8f%96%e6%b6%88%e6%94%b6%e8%97%8f%e6%ad%8c%e6%89%8b
+// needLogin: 是
+func (a *Api) ArtistSub(ctx context.Context, req *ArtistSubReq) (*ArtistSubResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/artist/unsub"
+		reply ArtistSubResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	if req.Sub {
+		url = "https://music.163.com/weapi/artist/sub"
+	}
+	if req.ArtistIds == "" {
+		req.ArtistIds = fmt.Sprintf("[%s]", req.ArtistId)
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type ArtistNewSongsReq struct {
+	Limit int64 `json:"limit"` // 每页条数,默认20
+	// StartTimestamp 分页游标,首页为当前时间毫秒,下一页为上一页最后一首歌曲的发行时间
+	StartTimestamp int64 `json:"startTimestamp"`
+}
+
+type ArtistNewSongsResp struct {
+	types.RespCommon[ArtistNewSongsRespData]
+}
+
+type ArtistNewSongsRespData struct {
+	NewSongCount int64                    `json:"newSongCount"`
+	HasMore      bool                     `json:"hasMore"`
+	NewWorks     []ArtistNewSongsRespWork `json:"newWorks"`
+}
+
+type ArtistNewSongsRespWork struct {
+	Id          int64          `json:"id"`
+	Name        string         `json:"name"`
+	Ar          []types.Artist `json:"ar"`
+	Al          types.Album    `json:"al"`
+	Dt          int64          `json:"dt"`
+	PublishTime int64          `json:"publishTime"` // 发行时间毫秒
+}
+
+// ArtistNewSongs 关注歌手的新歌动态,按发行时间倒序
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/artist_new_song.js
+// needLogin: 是
+func (a *Api) ArtistNewSongs(ctx context.Context, req *ArtistNewSongsReq) (*ArtistNewSongsResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/sub/artist/new/works/song/list"
+		reply ArtistNewSongsResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+	if req.StartTimestamp == 0 {
+		req.StartTimestamp = time.Now().UnixMilli()
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
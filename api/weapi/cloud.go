@@ -37,7 +37,6 @@ import (
 
 	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/types"
-	"github.com/chaunsin/netease-cloud-music/pkg/log"
 	"github.com/chaunsin/netease-cloud-music/pkg/utils"
 
 	"github.com/cheggaaa/pb/v3"
@@ -155,7 +154,7 @@ type CloudTokenAllocReq struct {
 }
 
 type CloudTokenAllocResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	CloudTokenAllocRespResult `json:"result,omitempty"`
 }
 
@@ -186,6 +185,8 @@ func (a *Api) CloudTokenAlloc(ctx context.Context, req *CloudTokenAllocReq) (*Cl
 		reply CloudTokenAllocResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
+
 	if req.CSRFToken == "" {
 		csrf, _ := a.client.GetCSRF(url)
 		req.CSRFToken = csrf
@@ -212,7 +213,7 @@ type CloudUploadCheckReq struct {
 
 type CloudUploadCheckResp struct {
 	// code 501:貌似上传得文件过大
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	SongId string `json:"songId,omitempty"`
 	// NeedUpload 是否需要上传 true:需要上传说明网易云网盘没有此音乐文件
 	NeedUpload bool `json:"needUpload" json:"needUpload,omitempty"`
@@ -354,12 +355,12 @@ func (a *Api) CloudUpload(ctx context.Context, req *CloudUploadReq) (*CloudUploa
 		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034"). // todo: hard code
 		Get(addr)
 	if err != nil || resp.StatusCode() != http.StatusOK {
-		log.Error("user default upload lbs node. get %s error: %v", addr, err)
+		a.client.Logger().Error("user default upload lbs node. get %s error: %v", addr, err)
 		return nil, fmt.Errorf("Get: %w", err)
 	} else {
 		var lbs CloudUploadLbsResp
 		if err := json.Unmarshal(resp.Body(), &lbs); err != nil {
-			log.Error("user default upload lbs node. Unmarshal %s error: %v", addr, err)
+			a.client.Logger().Error("user default upload lbs node. Unmarshal %s error: %v", addr, err)
 		} else {
 			if len(lbs.Upload) > 0 {
 				ip = lbs.Upload[rand.Intn(len(lbs.Upload))]
@@ -442,7 +443,7 @@ func (a *Api) CloudUpload(ctx context.Context, req *CloudUploadReq) (*CloudUploa
 		}
 
 		resp, err = a.client.Upload(ctx, _addr, headers, bytes.NewReader(partData), &reply, req.ProgressBar)
-		log.Debug("upload addr: %s chunk %d/%d, offset: %d, complete: %v, resp: %+v",
+		a.client.Logger().Debug("upload addr: %s chunk %d/%d, offset: %d, complete: %v, resp: %+v",
 			addr, i+1, chunks, start, complete, reply.ErrCode)
 		if err != nil {
 			return nil, fmt.Errorf("Upload: %w", err)
@@ -493,7 +494,7 @@ type CloudInfoResp struct {
 	// Code
 	// 404: 错误未知,目前在上传文件时文件大于200MB时出现此错误，经后来测试多试了几次重传发现又好了貌似是临时性错误，待确认排查。
 	// 410: 涉嫌违规,无法上传
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	// Code           int64        `json:"code,omitempty"`
 	SongId         string       `json:"songId,omitempty"`
 	SongIdLong     int64        `json:"songIdLong"` // songId和songIdLong相等只不过类型不同
@@ -568,6 +569,8 @@ func (a *Api) CloudInfo(ctx context.Context, req *CloudInfoReq) (*CloudInfoResp,
 		reply CloudInfoResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
+
 	if req.Album == "" {
 		req.Album = "未知专辑"
 	}
@@ -588,7 +591,7 @@ type CloudMusicStatusReq struct {
 }
 
 type CloudMusicStatusResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	// Key为歌曲的id
 	Statuses map[string]CloudMusicStatusRespData `json:"statuses"`
 }
@@ -637,6 +640,7 @@ func (a *Api) CloudPublish(ctx context.Context, req *CloudPublishReq) (*CloudPub
 		reply CloudPublishResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -651,7 +655,7 @@ type CloudDownloadReq struct {
 }
 
 type CloudDownloadResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Name string `json:"name"`
 	Url  string `json:"url"`
 	// Size 单位字节(B)
@@ -684,7 +688,7 @@ type CloudLyricReq struct {
 }
 
 type CloudLyricResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Lyc string `json:"lrc"`
 	Krc string `json:"krc"`
 }
@@ -713,7 +717,7 @@ type CloudDelReq struct {
 
 type CloudDelResp struct {
 	// Code 200:成功 404:删除失败(当重复删除同一个id时会出现)
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	// FailIds 删除失败的歌曲id
 	FailIds []int64 `json:"failIds"`
 	// SuccIds 删除成功的歌曲id
@@ -729,6 +733,7 @@ func (a *Api) CloudDel(ctx context.Context, req *CloudDelReq) (*CloudDelResp, er
 		reply CloudDelResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -770,3 +775,35 @@ func (a *Api) CloudUploadNode(ctx context.Context, req *CloudUploadNodeReq) (*Cl
 	_ = resp
 	return &reply, nil
 }
+
+type CloudMatchReq struct {
+	types.ReqCommon
+	UserId       string `json:"userId"`       // 当前登录用户id
+	SongId       string `json:"songId"`       // 云盘中的歌曲id see: CloudListRespData.SongId
+	AdjustSongId string `json:"adjustSongId"` // 需要纠正匹配到的网易云歌曲id
+}
+
+type CloudMatchResp struct {
+	// Code 200:成功 400:参数错误 404:云盘歌曲不存在
+	types.RespCommon[types.Empty]
+	Matched bool `json:"matched"`
+}
+
+// CloudMatch 云盘歌曲信息匹配纠正,将上传到云盘的歌曲关联到指定得网易云歌曲上
+// url: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/blob/main/module/cloud_match.js
+// needLogin: 是
+func (a *Api) CloudMatch(ctx context.Context, req *CloudMatchReq) (*CloudMatchResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/cloud/user/song/match"
+		reply CloudMatchResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
@@ -92,12 +92,65 @@ type CommentsReq struct {
 }
 
 type CommentsResp struct {
-	IsMusician  bool          `json:"isMusician"`
-	Cnum        int64         `json:"cnum"`
-	UserId      int64         `json:"userId"`
-	TopComments []interface{} `json:"topComments"`
-	Code        int64         `json:"code"`
-	Comments    []struct {
+	types.RespCommon[types.Empty]
+	IsMusician  bool                   `json:"isMusician"`
+	Cnum        int64                  `json:"cnum"`
+	UserId      int64                  `json:"userId"`
+	TopComments []interface{}          `json:"topComments"`
+	Comments    []CommentsRespComments `json:"comments"`
+	Total       int64                  `json:"total"`
+	More        bool                   `json:"more"`
+}
+
+// CommentsRespComments 评论内容
+type CommentsRespComments struct {
+	User struct {
+		LocationInfo interface{} `json:"locationInfo"`
+		LiveInfo     interface{} `json:"liveInfo"`
+		Anonym       int64       `json:"anonym"`
+		Highlight    bool        `json:"highlight"`
+		AvatarUrl    string      `json:"avatarUrl"`
+		AvatarDetail *struct {
+			UserType        int64  `json:"userType"`
+			IdentityLevel   int64  `json:"identityLevel"`
+			IdentityIconUrl string `json:"identityIconUrl"`
+		} `json:"avatarDetail"`
+		UserType     int64       `json:"userType"`
+		Followed     bool        `json:"followed"`
+		Mutual       bool        `json:"mutual"`
+		RemarkName   interface{} `json:"remarkName"`
+		SocialUserId interface{} `json:"socialUserId"`
+		VipRights    *struct {
+			Associator *struct {
+				VipCode int64  `json:"vipCode"`
+				Rights  bool   `json:"rights"`
+				IconUrl string `json:"iconUrl"`
+			} `json:"associator"`
+			MusicPackage *struct {
+				VipCode int64  `json:"vipCode"`
+				Rights  bool   `json:"rights"`
+				IconUrl string `json:"iconUrl"`
+			} `json:"musicPackage"`
+			Redplus *struct {
+				VipCode int64  `json:"vipCode"`
+				Rights  bool   `json:"rights"`
+				IconUrl string `json:"iconUrl"`
+			} `json:"redplus"`
+			RedVipAnnualCount int64       `json:"redVipAnnualCount"`
+			RedVipLevel       int64       `json:"redVipLevel"`
+			RelationType      int64       `json:"relationType"`
+			MemberLogo        interface{} `json:"memberLogo"`
+		} `json:"vipRights"`
+		Nickname       string      `json:"nickname"`
+		AuthStatus     int64       `json:"authStatus"`
+		ExpertTags     interface{} `json:"expertTags"`
+		Experts        interface{} `json:"experts"`
+		VipType        int64       `json:"vipType"`
+		CommonIdentity interface{} `json:"commonIdentity"`
+		UserId         int64       `json:"userId"`
+		Target         interface{} `json:"target"`
+	} `json:"user"`
+	BeReplied []struct {
 		User struct {
 			LocationInfo interface{} `json:"locationInfo"`
 			LiveInfo     interface{} `json:"liveInfo"`
@@ -109,32 +162,12 @@ type CommentsResp struct {
 				IdentityLevel   int64  `json:"identityLevel"`
 				IdentityIconUrl string `json:"identityIconUrl"`
 			} `json:"avatarDetail"`
-			UserType     int64       `json:"userType"`
-			Followed     bool        `json:"followed"`
-			Mutual       bool        `json:"mutual"`
-			RemarkName   interface{} `json:"remarkName"`
-			SocialUserId interface{} `json:"socialUserId"`
-			VipRights    *struct {
-				Associator *struct {
-					VipCode int64  `json:"vipCode"`
-					Rights  bool   `json:"rights"`
-					IconUrl string `json:"iconUrl"`
-				} `json:"associator"`
-				MusicPackage *struct {
-					VipCode int64  `json:"vipCode"`
-					Rights  bool   `json:"rights"`
-					IconUrl string `json:"iconUrl"`
-				} `json:"musicPackage"`
-				Redplus *struct {
-					VipCode int64  `json:"vipCode"`
-					Rights  bool   `json:"rights"`
-					IconUrl string `json:"iconUrl"`
-				} `json:"redplus"`
-				RedVipAnnualCount int64       `json:"redVipAnnualCount"`
-				RedVipLevel       int64       `json:"redVipLevel"`
-				RelationType      int64       `json:"relationType"`
-				MemberLogo        interface{} `json:"memberLogo"`
-			} `json:"vipRights"`
+			UserType       int64       `json:"userType"`
+			Followed       bool        `json:"followed"`
+			Mutual         bool        `json:"mutual"`
+			RemarkName     interface{} `json:"remarkName"`
+			SocialUserId   interface{} `json:"socialUserId"`
+			VipRights      interface{} `json:"vipRights"`
 			Nickname       string      `json:"nickname"`
 			AuthStatus     int64       `json:"authStatus"`
 			ExpertTags     interface{} `json:"expertTags"`
@@ -144,79 +177,49 @@ type CommentsResp struct {
 			UserId         int64       `json:"userId"`
 			Target         interface{} `json:"target"`
 		} `json:"user"`
-		BeReplied []struct {
-			User struct {
-				LocationInfo interface{} `json:"locationInfo"`
-				LiveInfo     interface{} `json:"liveInfo"`
-				Anonym       int64       `json:"anonym"`
-				Highlight    bool        `json:"highlight"`
-				AvatarUrl    string      `json:"avatarUrl"`
-				AvatarDetail *struct {
-					UserType        int64  `json:"userType"`
-					IdentityLevel   int64  `json:"identityLevel"`
-					IdentityIconUrl string `json:"identityIconUrl"`
-				} `json:"avatarDetail"`
-				UserType       int64       `json:"userType"`
-				Followed       bool        `json:"followed"`
-				Mutual         bool        `json:"mutual"`
-				RemarkName     interface{} `json:"remarkName"`
-				SocialUserId   interface{} `json:"socialUserId"`
-				VipRights      interface{} `json:"vipRights"`
-				Nickname       string      `json:"nickname"`
-				AuthStatus     int64       `json:"authStatus"`
-				ExpertTags     interface{} `json:"expertTags"`
-				Experts        interface{} `json:"experts"`
-				VipType        int64       `json:"vipType"`
-				CommonIdentity interface{} `json:"commonIdentity"`
-				UserId         int64       `json:"userId"`
-				Target         interface{} `json:"target"`
-			} `json:"user"`
-			BeRepliedCommentId int64       `json:"beRepliedCommentId"`
-			Content            *string     `json:"content"`
-			RichContent        *string     `json:"richContent"`
-			Status             int64       `json:"status"`
-			ExpressionUrl      interface{} `json:"expressionUrl"`
-			IpLocation         struct {
-				Ip       interface{} `json:"ip"`
-				Location string      `json:"location"`
-				UserId   int64       `json:"userId"`
-			} `json:"ipLocation"`
-		} `json:"beReplied"`
-		PendantData *struct {
-			Id       int64  `json:"id"`
-			ImageUrl string `json:"imageUrl"`
-		} `json:"pendantData"`
-		ShowFloorComment    interface{} `json:"showFloorComment"`
-		Status              int64       `json:"status"`
-		CommentId           int64       `json:"commentId"`
-		Content             string      `json:"content"` // 评论内容
-		RichContent         *string     `json:"richContent"`
-		ContentResource     interface{} `json:"contentResource"`
-		Time                int64       `json:"time"`
-		TimeStr             string      `json:"timeStr"`
-		NeedDisplayTime     bool        `json:"needDisplayTime"`
-		LikedCount          int64       `json:"likedCount"`
-		ExpressionUrl       interface{} `json:"expressionUrl"`
-		CommentLocationType int64       `json:"commentLocationType"`
-		ParentCommentId     int64       `json:"parentCommentId"`
-		Decoration          struct {
-		} `json:"decoration"`
-		RepliedMark   interface{} `json:"repliedMark"`
-		Grade         interface{} `json:"grade"`
-		UserBizLevels interface{} `json:"userBizLevels"`
-		IpLocation    struct {
+		BeRepliedCommentId int64       `json:"beRepliedCommentId"`
+		Content            *string     `json:"content"`
+		RichContent        *string     `json:"richContent"`
+		Status             int64       `json:"status"`
+		ExpressionUrl      interface{} `json:"expressionUrl"`
+		IpLocation         struct {
 			Ip       interface{} `json:"ip"`
 			Location string      `json:"location"`
 			UserId   int64       `json:"userId"`
 		} `json:"ipLocation"`
-		Owner            bool        `json:"owner"`
-		Medal            interface{} `json:"medal"`
-		LikeAnimationMap struct {
-		} `json:"likeAnimationMap"`
-		Liked bool `json:"liked"`
-	} `json:"comments"`
-	Total int64 `json:"total"`
-	More  bool  `json:"more"`
+	} `json:"beReplied"`
+	PendantData *struct {
+		Id       int64  `json:"id"`
+		ImageUrl string `json:"imageUrl"`
+	} `json:"pendantData"`
+	ShowFloorComment    interface{} `json:"showFloorComment"`
+	Status              int64       `json:"status"`
+	CommentId           int64       `json:"commentId"`
+	Content             string      `json:"content"` // 评论内容
+	RichContent         *string     `json:"richContent"`
+	ContentResource     interface{} `json:"contentResource"`
+	Time                int64       `json:"time"`
+	TimeStr             string      `json:"timeStr"`
+	NeedDisplayTime     bool        `json:"needDisplayTime"`
+	LikedCount          int64       `json:"likedCount"`
+	ExpressionUrl       interface{} `json:"expressionUrl"`
+	CommentLocationType int64       `json:"commentLocationType"`
+	ParentCommentId     int64       `json:"parentCommentId"`
+	Decoration          struct {
+	} `json:"decoration"`
+	RepliedMark   interface{} `json:"repliedMark"`
+	Grade         interface{} `json:"grade"`
+	UserBizLevels interface{} `json:"userBizLevels"`
+	IpLocation    struct {
+		Ip       interface{} `json:"ip"`
+		Location string      `json:"location"`
+		UserId   int64       `json:"userId"`
+	} `json:"ipLocation"`
+	Owner            bool        `json:"owner"`
+	Medal            interface{} `json:"medal"`
+	LikeAnimationMap struct {
+	} `json:"likeAnimationMap"`
+	Liked bool `json:"liked"`
 }
 
 // Comments 获取歌曲评论列表
@@ -224,7 +227,7 @@ type CommentsResp struct {
 // needLogin: 未知
 func (a *Api) Comments(ctx context.Context, req *CommentsReq) (*CommentsResp, error) {
 	var (
-		url   = fmt.Sprintf("https://interface.music.163.com/weapi/v1/resource/comments/" + req.ThreadId)
+		url   = "https://interface.music.163.com/weapi/v1/resource/comments/" + req.ThreadId
 		reply CommentsResp
 		opts  = api.NewOptions()
 	)
@@ -241,3 +244,190 @@ func (a *Api) Comments(ctx context.Context, req *CommentsReq) (*CommentsResp, er
 	_ = resp
 	return &reply, nil
 }
+
+// 评论资源线程id前缀,拼接资源id即为threadId,例如 R_SO_4_2128846655
+const (
+	CommentThreadSong     = "R_SO_4_" // 歌曲
+	CommentThreadMV       = "R_MV_5_" // MV
+	CommentThreadPlaylist = "A_PL_0_" // 歌单
+	CommentThreadAlbum    = "R_AL_3_" // 专辑
+	CommentThreadDJ       = "A_DJ_1_" // 电台节目
+)
+
+type CommentHotReq struct {
+	types.ReqCommon
+	ThreadId   string `json:"-"`          // see CommentThreadSong
+	Rid        string `json:"rid"`        // 资源id
+	Offset     int64  `json:"offset"`     // 偏移量
+	Limit      int64  `json:"limit"`      // 每页数量
+	BeforeTime int64  `json:"beforeTime"` // 分页参数,取上一页最后一条评论的time
+}
+
+type CommentHotResp struct {
+	types.RespCommon[types.Empty]
+	TopComments []CommentsRespComments `json:"topComments"`
+	HotComments []CommentsRespComments `json:"hotComments"`
+	HasMore     bool                   `json:"hasMore"`
+	Total       int64                  `json:"total"`
+}
+
+// CommentHot 获取资源热门评论
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e7%83%ad%e9%97%a8%e8%af%84%e8%ae%ba
+// needLogin: 否
+func (a *Api) CommentHot(ctx context.Context, req *CommentHotReq) (*CommentHotResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/v1/resource/hotcomments/" + req.ThreadId
+		reply CommentHotResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type CommentFloorReq struct {
+	types.ReqCommon
+	ParentCommentId int64  `json:"parentCommentId"` // 楼层评论id
+	ThreadId        string `json:"threadId"`        // see CommentThreadSong
+	Time            int64  `json:"time"`            // 分页参数,取上一页返回的Data.Time,第一页传-1
+	Limit           int64  `json:"limit"`           // 每页数量
+}
+
+type CommentFloorResp struct {
+	types.RespCommon[CommentFloorRespData]
+}
+
+type CommentFloorRespData struct {
+	OwnerComment *CommentsRespComments  `json:"ownerComment"` // 楼主评论
+	Comments     []CommentsRespComments `json:"comments"`
+	HasMore      bool                   `json:"hasMore"`
+	TotalCount   int64                  `json:"totalCount"`
+	Time         int64                  `json:"time"` // 下一页分页参数
+}
+
+// CommentFloor 获取楼层评论即某条评论的回复列表
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e6%a5%bc%e5%b1%82%e8%af%84%e8%ae%ba
+// needLogin: 否
+func (a *Api) CommentFloor(ctx context.Context, req *CommentFloorReq) (*CommentFloorResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/resource/comment/floor/get"
+		reply CommentFloorResp
+		opts  = api.NewOptions()
+	)
+	if req.Time == 0 {
+		req.Time = -1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type CommentAddReq struct {
+	types.ReqCommon
+	ThreadId  string `json:"threadId"`            // see CommentThreadSong
+	Content   string `json:"content"`             // 评论内容
+	CommentId int64  `json:"commentId,omitempty"` // 回复的评论id,不为0时为回复评论
+}
+
+type CommentAddResp struct {
+	types.RespCommon[types.Empty]
+	Comment *CommentsRespComments `json:"comment"` // 发送成功的评论
+}
+
+// CommentAdd 发送评论,CommentId不为0时回复该评论
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%8f%91%e9%80%81%e5%88%a0%e9%99%a4%e8%af%84%e8%ae%ba
+// needLogin: 是
+func (a *Api) CommentAdd(ctx context.Context, req *CommentAddReq) (*CommentAddResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/resource/comments/add"
+		reply CommentAddResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	if req.CommentId != 0 {
+		url = "https://music.163.com/weapi/resource/comments/reply"
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type CommentDeleteReq struct {
+	types.ReqCommon
+	ThreadId  string `json:"threadId"`  // see CommentThreadSong
+	CommentId int64  `json:"commentId"` // 评论id
+}
+
+// CommentDeleteResp 接口没有返回数据 成功响应: {"code":200}
+type CommentDeleteResp struct {
+	types.RespCommon[types.Empty]
+}
+
+// CommentDelete 删除自己发送的评论
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%8f%91%e9%80%81%e5%88%a0%e9%99%a4%e8%af%84%e8%ae%ba
+// needLogin: 是
+func (a *Api) CommentDelete(ctx context.Context, req *CommentDeleteReq) (*CommentDeleteResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/resource/comments/delete"
+		reply CommentDeleteResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type CommentLikeReq struct {
+	types.ReqCommon
+	ThreadId  string `json:"threadId"`  // see CommentThreadSong
+	CommentId int64  `json:"commentId"` // 评论id
+	Like      bool   `json:"-"`         // true:点赞 false:取消点赞
+}
+
+// CommentLikeResp 接口没有返回数据 成功响应: {"code":200}
+type CommentLikeResp struct {
+	types.RespCommon[types.Empty]
+}
+
+// CommentLike 给评论点赞或取消点赞
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e7%bb%99%e8%af%84%e8%ae%ba%e7%82%b9%e8%b5%9e
+// needLogin: 是
+func (a *Api) CommentLike(ctx context.Context, req *CommentLikeReq) (*CommentLikeResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/v1/comment/unlike"
+		reply CommentLikeResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	if req.Like {
+		url = "https://music.163.com/weapi/v1/comment/like"
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
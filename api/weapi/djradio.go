@@ -28,6 +28,7 @@ import (
 	"fmt"
 
 	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
 )
 
 type DjRadioSub struct {
@@ -36,6 +37,7 @@ type DjRadioSub struct {
 }
 
 type DjRadioSubResp struct {
+	types.RespCommon[types.Empty]
 	Count    int64 `json:"count"` // 总条数
 	DjRadios []struct {
 		Dj struct {
@@ -123,7 +125,6 @@ type DjRadioSubResp struct {
 	} `json:"djRadios"`
 	Time    int64 `json:"time"` // eg:1625317200000
 	HasMore bool  `json:"hasMore"`
-	Code    int64 `json:"code"` // 200: success
 }
 
 // DjRadioSub 获取订阅博客列表
@@ -145,3 +146,108 @@ func (a *Api) DjRadioSub(ctx context.Context, req *DjRadioSub) (*DjRadioSubResp,
 	_ = resp
 	return &reply, nil
 }
+
+type DjRadioDetailReq struct {
+	types.ReqCommon
+	Id string `json:"id"` // 电台id
+}
+
+type DjRadioDetailResp struct {
+	types.RespCommon[DjRadioDetailRespData]
+}
+
+type DjRadioDetailRespData struct {
+	Id                    int64  `json:"id"`
+	Name                  string `json:"name"`
+	Desc                  string `json:"desc"`
+	PicUrl                string `json:"picUrl"`
+	Category              string `json:"category"`
+	ProgramCount          int64  `json:"programCount"` // 节目数量
+	SubCount              int64  `json:"subCount"`     // 订阅数量
+	CreateTime            int64  `json:"createTime"`
+	LastProgramCreateTime int64  `json:"lastProgramCreateTime"`
+	Subed                 bool   `json:"subed"` // 当前用户是否订阅
+	Dj                    struct {
+		UserId   int64  `json:"userId"`
+		Nickname string `json:"nickname"`
+	} `json:"dj"`
+}
+
+// DjRadioDetail 获取电台详情
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e7%94%b5%e5%8f%b0---%e8%af%a6%e6%83%85
+// needLogin: 否
+func (a *Api) DjRadioDetail(ctx context.Context, req *DjRadioDetailReq) (*DjRadioDetailResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/djradio/v2/get"
+		reply DjRadioDetailResp
+		opts  = api.NewOptions()
+	)
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type DjProgramReq struct {
+	types.ReqCommon
+	RadioId string `json:"radioId"` // 电台id
+	Limit   int64  `json:"limit"`   // 每页数量
+	Offset  int64  `json:"offset"`  // 偏移量
+	Asc     bool   `json:"asc"`     // true:按发布时间升序 false:降序
+}
+
+type DjProgramResp struct {
+	types.RespCommon[types.Empty]
+	Count    int64                   `json:"count"` // 节目总数
+	More     bool                    `json:"more"`
+	Programs []DjProgramRespPrograms `json:"programs"`
+}
+
+type DjProgramRespPrograms struct {
+	Id            int64  `json:"id"` // 节目id
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	SerialNum     int64  `json:"serialNum"`  // 节目期数
+	CreateTime    int64  `json:"createTime"` // 发布时间,毫秒时间戳
+	Duration      int64  `json:"duration"`   // 时长,毫秒
+	CoverUrl      string `json:"coverUrl"`
+	ListenerCount int64  `json:"listenerCount"`
+	// MainSong 节目音频,id可以和歌曲一样获取下载链接
+	MainSong struct {
+		Id       int64          `json:"id"`
+		Name     string         `json:"name"`
+		Duration int64          `json:"duration"`
+		Artists  []types.Artist `json:"artists"`
+		Album    types.Album    `json:"album"`
+	} `json:"mainSong"`
+	Radio struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"radio"`
+	Dj struct {
+		UserId   int64  `json:"userId"`
+		Nickname string `json:"nickname"`
+	} `json:"dj"`
+}
+
+// DjProgram 获取电台节目列表
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e7%94%b5%e5%8f%b0---%e8%8a%82%e7%9b%ae
+// needLogin: 否
+func (a *Api) DjProgram(ctx context.Context, req *DjProgramReq) (*DjProgramResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/dj/program/byradio"
+		reply DjProgramResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
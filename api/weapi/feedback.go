@@ -55,8 +55,9 @@ type ApiWebLogReq struct {
 	Json        ApiWebLogReqJson `json:"json"`
 }
 
+// ApiWebLogResp 响应示例: {"code":200,"data":"success","message":""}
 type ApiWebLogResp struct {
-	types.RespCommon[any]
+	types.RespCommon[string]
 }
 
 // ApiWebLog 日志上报
@@ -68,6 +69,8 @@ func (a *Api) ApiWebLog(ctx context.Context, req *ApiWebLogReq) (*ApiWebLogResp,
 		resp ApiWebLogResp
 		opts = api.NewOptions()
 	)
+	opts.Mutating = true
+
 	if req.CsrfToken == "" {
 		csrf, _ := a.client.GetCSRF(url)
 		req.CsrfToken = csrf
@@ -118,6 +121,8 @@ func (a *Api) WebLog(ctx context.Context, req *WebLogReq) (*WebLogResp, error) {
 		resp WebLogResp
 		opts = api.NewOptions()
 	)
+	opts.Mutating = true
+
 	if req.CsrfToken == "" {
 		csrf, _ := a.client.GetCSRF(url)
 		req.CsrfToken = csrf
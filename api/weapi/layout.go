@@ -12,8 +12,9 @@ type LayoutReq struct {
 	CsrfToken string `json:"csrf_token"`
 }
 
+// LayoutResp 接口没有返回数据 成功响应: {"code":200}
 type LayoutResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 }
 
 // Layout 退出
@@ -41,7 +41,7 @@ type QrcodeCreateKeyReq struct {
 }
 
 type QrcodeCreateKeyResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	UniKey string `json:"unikey"`
 }
 
@@ -67,10 +67,11 @@ func (a *Api) QrcodeCreateKey(ctx context.Context, req *QrcodeCreateKeyReq) (*Qr
 type QrcodeGenerateReq struct {
 	CodeKey string
 	Level   qrcode.RecoveryLevel // 二维码恢复率
+	Invert  bool                 // 终端二维码是否反色显示,适用于浅色背景的终端
 }
 
 type QrcodeGenerateResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Qrcode      []byte
 	QrcodePrint string
 }
@@ -90,7 +91,7 @@ func (a *Api) QrcodeGenerate(ctx context.Context, req *QrcodeGenerateReq) (*Qrco
 	if err != nil {
 		return nil, fmt.Errorf("PNG: %w", err)
 	}
-	reply.QrcodePrint = qr.ToSmallString(false)
+	reply.QrcodePrint = qr.ToSmallString(req.Invert)
 	// if err := qr.WriteFile(256, "./qrcode.png"); err != nil {
 	// 	return nil, fmt.Errorf("WriteFile: %w", err)
 	// }
@@ -106,8 +107,13 @@ type QrcodeCheckReq struct {
 	Type int64  `json:"type"` // 目前传1
 }
 
+// QrcodeCheckResp 扫码状态,Code 800:二维码不存在或已过期 801:等待扫码 802:正在扫码授权中 803:授权登录成功
+// 802响应示例: {"code":802,"message":"授权中","nickname":"test","avatarUrl":"https://p1.music.126.net/xxx.jpg"}
 type QrcodeCheckResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
+	Nickname  string `json:"nickname"`  // 扫码用户昵称,Code为802时返回
+	AvatarUrl string `json:"avatarUrl"` // 扫码用户头像,Code为802时返回
+	Cookie    string `json:"cookie"`    // 登录cookie,Code为803时返回
 }
 
 // QrcodeCheck 查询扫码状态
@@ -122,6 +128,7 @@ func (a *Api) QrcodeCheck(ctx context.Context, req *QrcodeCheckReq) (*QrcodeChec
 		reply QrcodeCheckResp
 		opts  = api.NewOptions()
 	)
+	opts.Codes = []int64{800, 801, 802, 803}
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -136,7 +143,7 @@ type GetUserInfoReq struct {
 }
 
 type GetUserInfoResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Account *GetUserInfoRespAccount `json:"account"`
 	Profile *GetUserInfoRespProfile `json:"profile"`
 }
@@ -218,7 +225,7 @@ type TokenRefreshReq struct {
 }
 
 type TokenRefreshResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	BizCode string `json:"bizCode"` // 201:貌似刷新成功 400:貌似刷新不成功 504:貌似token已经过期了或者无效了
 }
 
@@ -260,8 +267,11 @@ type RegisterAnonymousReq struct {
 	Username string `json:"username"` // 设备id如果为空则设备id为ncmctl
 }
 
+// RegisterAnonymousResp 响应示例: {"code":200,"userId":8023474819,"createTime":1704464373629}
 type RegisterAnonymousResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
+	UserId     int64 `json:"userId"`     // 游客用户id
+	CreateTime int64 `json:"createTime"` // 创建时间
 }
 
 // RegisterAnonymous 匿名用户注册
@@ -356,7 +366,7 @@ type LoginCellphoneReq struct {
 }
 
 type LoginCellphoneResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	LoginType int64                        `json:"loginType"`
 	Token     string                       `json:"token"` // MUSIC_U
 	Account   LoginCellphoneRespAccount    `json:"account"`
@@ -41,7 +41,7 @@ type LyricReq struct {
 }
 
 type LyricResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Sgc       bool      `json:"sgc"`
 	Sfy       bool      `json:"sfy"`
 	Qfy       bool      `json:"qfy"`
@@ -113,7 +113,7 @@ type LyricV1Req struct {
 }
 
 type LyricV1Resp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Sgc       bool        `json:"sgc"`
 	Sfy       bool        `json:"sfy"`
 	Qfy       bool        `json:"qfy"`
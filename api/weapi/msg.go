@@ -0,0 +1,266 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package weapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+)
+
+// MsgUser 消息中的用户信息
+type MsgUser struct {
+	UserId    int64  `json:"userId"`
+	Nickname  string `json:"nickname"`
+	AvatarUrl string `json:"avatarUrl"`
+	Followed  bool   `json:"followed"`
+}
+
+type MsgPrivateReq struct {
+	types.ReqCommon
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Total  string `json:"total"` // eg: "true"
+}
+
+type MsgPrivateResp struct {
+	types.RespCommon[types.Empty]
+	Msgs []struct {
+		FromUser          MsgUser `json:"fromUser"`
+		ToUser            MsgUser `json:"toUser"`
+		LastMsg           string  `json:"lastMsg"`     // json字符串 see: MsgContent
+		LastMsgTime       int64   `json:"lastMsgTime"` // 毫秒时间戳
+		NewMsgCount       int64   `json:"newMsgCount"` // 未读数量
+		NoticeAccountFlag bool    `json:"noticeAccountFlag"`
+	} `json:"msgs"`
+	More        bool  `json:"more"`
+	NewMsgCount int64 `json:"newMsgCount"` // 全部未读私信数量
+}
+
+// MsgPrivate 获取私信会话列表
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e7%a7%81%e4%bf%a1
+// needLogin: 是
+func (a *Api) MsgPrivate(ctx context.Context, req *MsgPrivateReq) (*MsgPrivateResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/msg/private/users"
+		reply MsgPrivateResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+	if req.Total == "" {
+		req.Total = "true"
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type MsgPrivateHistoryReq struct {
+	types.ReqCommon
+	UserId string `json:"userId"` // 对方用户id
+	Limit  int64  `json:"limit"`
+	Time   int64  `json:"time"`  // 分页参数,取上一页最后一条消息的time,第一页传0
+	Total  string `json:"total"` // eg: "true"
+}
+
+type MsgPrivateHistoryResp struct {
+	types.RespCommon[types.Empty]
+	Msgs []struct {
+		Id       int64   `json:"id"`
+		FromUser MsgUser `json:"fromUser"`
+		ToUser   MsgUser `json:"toUser"`
+		Msg      string  `json:"msg"`  // json字符串 see: MsgContent
+		Time     int64   `json:"time"` // 毫秒时间戳
+	} `json:"msgs"`
+	More bool `json:"more"`
+}
+
+// MsgPrivateHistory 获取与某个用户的私信内容
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e7%a7%81%e4%bf%a1%e5%86%85%e5%ae%b9
+// needLogin: 是
+func (a *Api) MsgPrivateHistory(ctx context.Context, req *MsgPrivateHistoryReq) (*MsgPrivateHistoryResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/msg/private/history"
+		reply MsgPrivateHistoryResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+	if req.Total == "" {
+		req.Total = "true"
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+// MsgContent 私信内容,由 MsgPrivateResp.LastMsg 及 MsgPrivateHistoryResp.Msg 解析得到
+type MsgContent struct {
+	Msg  string `json:"msg"`  // 文本内容
+	Type int64  `json:"type"` // 1:歌曲 6:文本 其他暂时未知
+	Song *struct {
+		Id      int64  `json:"id"`
+		Name    string `json:"name"`
+		Artists []struct {
+			Name string `json:"name"`
+		} `json:"artists"`
+	} `json:"song,omitempty"`
+}
+
+type MsgForwardsReq struct {
+	types.ReqCommon
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Total  string `json:"total"` // eg: "true"
+}
+
+type MsgForwardsResp struct {
+	types.RespCommon[types.Empty]
+	Forwards []struct {
+		Id   int64   `json:"id"`
+		User MsgUser `json:"user"`
+		Json string  `json:"json"` // json字符串,包含动态及@内容
+		Time int64   `json:"time"` // 毫秒时间戳
+		Read bool    `json:"read"`
+		Type int64   `json:"type"`
+	} `json:"forwards"`
+	More     bool  `json:"more"`
+	NewCount int64 `json:"newCount"` // 未读数量
+}
+
+// MsgForwards 获取@我的消息
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e9%80%9a%e7%9f%a5---%e6%88%91
+// needLogin: 是
+func (a *Api) MsgForwards(ctx context.Context, req *MsgForwardsReq) (*MsgForwardsResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/forwards/get"
+		reply MsgForwardsResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+	if req.Total == "" {
+		req.Total = "true"
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type MsgNoticesReq struct {
+	types.ReqCommon
+	Limit int64 `json:"limit"`
+	Time  int64 `json:"time"` // 分页参数,取上一页最后一条通知的time,第一页传-1
+}
+
+type MsgNoticesResp struct {
+	types.RespCommon[types.Empty]
+	Notices []struct {
+		Id     int64  `json:"id"`
+		Notice string `json:"notice"` // json字符串,包含通知类型及内容
+		Time   int64  `json:"time"`   // 毫秒时间戳
+		Type   int64  `json:"type"`
+		Read   bool   `json:"read"`
+	} `json:"notices"`
+	More bool `json:"more"`
+}
+
+// MsgNotices 获取通知
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e9%80%9a%e7%9f%a5---%e9%80%9a%e7%9f%a5
+// needLogin: 是
+func (a *Api) MsgNotices(ctx context.Context, req *MsgNoticesReq) (*MsgNoticesResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/msg/notices"
+		reply MsgNoticesResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+	if req.Time == 0 {
+		req.Time = -1
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type MsgSendReq struct {
+	types.ReqCommon
+	UserIds string `json:"userIds"`      // 接收者用户id数组 eg: "[32953014]"
+	Msg     string `json:"msg"`          // 文本内容
+	Type    string `json:"type"`         // text:文本 song:分享歌曲
+	Id      string `json:"id,omitempty"` // 分享的歌曲id,Type为song时必填
+}
+
+type MsgSendResp struct {
+	types.RespCommon[types.Empty]
+	// SendBlacklist 被对方拉黑等原因发送失败的用户
+	SendBlacklist []interface{} `json:"sendblacklist"`
+	NewMsgs       []struct {
+		Id   int64  `json:"id"`
+		Msg  string `json:"msg"`
+		Time int64  `json:"time"`
+	} `json:"newMsgs"`
+}
+
+// MsgSend 发送私信,Type为song时同时分享歌曲
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%8f%91%e9%80%81%e7%a7%81%e4%bf%a1
+// needLogin: 是
+func (a *Api) MsgSend(ctx context.Context, req *MsgSendReq) (*MsgSendResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/msg/private/send"
+		reply MsgSendResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	if req.Type == "" {
+		req.Type = "text"
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
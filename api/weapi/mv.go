@@ -0,0 +1,134 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package weapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+)
+
+type MvDetailReq struct {
+	Id string `json:"id"`
+}
+
+type MvDetailResp struct {
+	types.RespCommon[MvDetailRespData]
+	BufferPic   string `json:"bufferPic"`
+	BufferPicFS string `json:"bufferPicFS"`
+	Subed       bool   `json:"subed"`
+}
+
+type MvDetailRespData struct {
+	Id         int64  `json:"id"`
+	Name       string `json:"name"`
+	ArtistId   int64  `json:"artistId"`
+	ArtistName string `json:"artistName"`
+	BriefDesc  string `json:"briefDesc"`
+	Desc       string `json:"desc"`
+	Cover      string `json:"cover"`
+	CoverId    int64  `json:"coverId"`
+	PlayCount  int64  `json:"playCount"`
+	SubCount   int64  `json:"subCount"`
+	ShareCount int64  `json:"shareCount"`
+	// PublishTime 发布日期 例如: 2021-08-19
+	PublishTime string `json:"publishTime"`
+	// Duration 时长,单位毫秒
+	Duration int64 `json:"duration"`
+	// Brs 支持的分辨率
+	Brs []struct {
+		// Br 分辨率 例如: 240、480、720、1080
+		Br int64 `json:"br"`
+		// Size 文件大小
+		Size  int64 `json:"size"`
+		Point int64 `json:"point"`
+	} `json:"brs"`
+	Artists []types.Artist `json:"artists"`
+}
+
+// MvDetail 获取MV详情
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/mv_detail.js
+// needLogin: 否
+func (a *Api) MvDetail(ctx context.Context, req *MvDetailReq) (*MvDetailResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/v1/mv/detail"
+		reply MvDetailResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type MvUrlReq struct {
+	Id string `json:"id"`
+	// R 分辨率 240、480、720、1080,不支持时返回最接近的分辨率
+	R int64 `json:"r"`
+}
+
+type MvUrlResp struct {
+	types.RespCommon[MvUrlRespData]
+}
+
+type MvUrlRespData struct {
+	Id   int64  `json:"id"`
+	Url  string `json:"url"`
+	R    int64  `json:"r"`
+	Size int64  `json:"size"`
+	Md5  string `json:"md5"`
+	Code int64  `json:"code"`
+	// Expi 地址有效期,单位秒
+	Expi  int64  `json:"expi"`
+	Fee   int64  `json:"fee"`
+	MvFee int64  `json:"mvFee"`
+	St    int64  `json:"st"`
+	Msg   string `json:"msg"`
+}
+
+// MvUrl 获取MV播放地址
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/mv_url.js
+// needLogin: 否
+func (a *Api) MvUrl(ctx context.Context, req *MvUrlReq) (*MvUrlResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/song/enhance/play/mv/url"
+		reply MvUrlResp
+		opts  = api.NewOptions()
+	)
+	if req.R == 0 {
+		req.R = 1080
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
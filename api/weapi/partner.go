@@ -792,6 +792,8 @@ func (a *Api) PartnerEvaluate(ctx context.Context, req *PartnerEvaluateReq) (*Pa
 		reply PartnerEvaluateResp
 		opts  = api.NewOptions()
 	)
+	opts.Codes = []int64{405} // 当前歌曲已完成测评
+	opts.Mutating = true
 	if req.CSRFToken == "" {
 		csrf, _ := a.client.GetCSRF(url)
 		req.CSRFToken = csrf
@@ -874,6 +876,8 @@ func (a *Api) PartnerExtraReport(ctx context.Context, req *PartnerExtraReportReq
 		reply PartnerExtraReportResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
+
 	if req.CSRFToken == "" {
 		csrf, _ := a.client.GetCSRF(url)
 		req.CSRFToken = csrf
@@ -900,7 +904,7 @@ type PartnerContentAntispamReq struct {
 // PartnerContentAntispamResp
 // 成功响应: {"code":200,"data":{},"message":""}
 type PartnerContentAntispamResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 }
 
 // PartnerContentAntispam 内容安审
@@ -39,7 +39,7 @@ type PlaylistReq struct {
 }
 
 type PlaylistResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Version  string             `json:"version"` // 时间戳1703557080686
 	More     bool               `json:"more"`
 	Playlist []PlaylistRespList `json:"playlist"`
@@ -156,7 +156,7 @@ type PlaylistDetailReq struct {
 }
 
 type PlaylistDetailResp struct {
-	types.ApiRespCommon[any]
+	types.ApiRespCommon[types.Empty]
 	RelatedVideos interface{} `json:"relatedVideos"`
 	Playlist      struct {
 		Id                    int64         `json:"id"`
@@ -509,6 +509,76 @@ func (a *Api) Radio(ctx context.Context, req *RadioReq) (*RadioResp, error) {
 	return &reply, nil
 }
 
+type RadioTrashReq struct {
+	types.ReqCommon
+	SongId int64  `json:"songId"` // 歌曲id
+	Alg    string `json:"alg"`    // 推荐算法,私人FM返回的 RadioRespData.Alg 为空时使用RT
+	Time   int64  `json:"time"`   // 已播放时长单位秒
+}
+
+type RadioTrashResp struct {
+	types.RespCommon[types.Empty]
+	Count int64 `json:"count"`
+}
+
+// RadioTrash 私人FM将歌曲移至垃圾桶(不再推荐)
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%9e%83%e5%9c%be%e6%a1%b6
+// needLogin: 是
+func (a *Api) RadioTrash(ctx context.Context, req *RadioTrashReq) (*RadioTrashResp, error) {
+	if req.Alg == "" {
+		req.Alg = "RT"
+	}
+	var (
+		url   = fmt.Sprintf("https://music.163.com/weapi/radio/trash/add?alg=%s&songId=%d&time=%d", req.Alg, req.SongId, req.Time)
+		reply RadioTrashResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type RadioLikeReq struct {
+	types.ReqCommon
+	TrackId int64  `json:"trackId"` // 歌曲id
+	Like    bool   `json:"like"`    // true:喜欢 false:取消喜欢
+	Alg     string `json:"alg"`     // 推荐算法,为空时使用itembased
+	Time    int64  `json:"time"`    // 已播放时长单位秒
+}
+
+type RadioLikeResp struct {
+	types.RespCommon[types.Empty]
+	PlaylistId int64 `json:"playlistId"` // 我喜欢的音乐歌单id
+}
+
+// RadioLike 私人FM喜欢歌曲,歌曲会加入"我喜欢的音乐"歌单
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%96%9c%e6%ac%a2%e9%9f%b3%e4%b9%90
+// needLogin: 是
+func (a *Api) RadioLike(ctx context.Context, req *RadioLikeReq) (*RadioLikeResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/radio/like"
+		reply RadioLikeResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	if req.Alg == "" {
+		req.Alg = "itembased"
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
 type PCRecentListenListReq struct {
 	types.ReqCommon
 }
@@ -569,7 +639,7 @@ type PlaylistAddOrDelReq struct {
 }
 
 type PlaylistAddOrDelResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	TrackIds   string `json:"trackIds"`   // 成功添加的歌曲id(返回为string类型数组如"[349823,423521]")
 	Count      int64  `json:"count"`      // 该歌单歌曲数量(添加后)
 	CloudCount int64  `json:"cloudCount"` // 该歌单内云盘歌曲数量(添加后)
@@ -583,6 +653,214 @@ func (a *Api) PlaylistAddOrDel(ctx context.Context, req *PlaylistAddOrDelReq) (*
 		reply PlaylistAddOrDelResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type PlaylistSubscribeReq struct {
+	Id string `json:"id"` // 歌单id
+}
+
+// PlaylistSubscribeResp 接口没有返回数据 成功响应: {"code":200}
+type PlaylistSubscribeResp struct {
+	types.RespCommon[types.Empty]
+}
+
+// PlaylistSubscribe 收藏歌单
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e6%94%b6%e8%97%8f%e5%8f%96%e6%b6%88%e6%94%b6%e8%97%8f%e6%ad%8c%e5%8d%95
+// needLogin: 是
+func (a *Api) PlaylistSubscribe(ctx context.Context, req *PlaylistSubscribeReq) (*PlaylistSubscribeResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/playlist/subscribe"
+		reply PlaylistSubscribeResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type PlaylistUnsubscribeReq struct {
+	Id string `json:"id"` // 歌单id
+}
+
+// PlaylistUnsubscribeResp 接口没有返回数据 成功响应: {"code":200}
+type PlaylistUnsubscribeResp struct {
+	types.RespCommon[types.Empty]
+}
+
+// PlaylistUnsubscribe 取消收藏歌单
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e6%94%b6%e8%97%8f%e5%8f%96%e6%b6%88%e6%94%b6%e8%97%8f%e6%ad%8c%e5%8d%95
+// needLogin: 是
+func (a *Api) PlaylistUnsubscribe(ctx context.Context, req *PlaylistUnsubscribeReq) (*PlaylistUnsubscribeResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/playlist/unsubscribe"
+		reply PlaylistUnsubscribeResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type PlaylistCreateReq struct {
+	Name    string `json:"name"`    // 歌单名称
+	Privacy string `json:"privacy"` // 0:公开 10:隐私
+	Type    string `json:"type"`    // NORMAL:普通歌单 VIDEO:视频歌单 SHARED:共享歌单
+}
+
+type PlaylistCreateResp struct {
+	types.RespCommon[types.Empty]
+	Id       int64 `json:"id"` // 新建的歌单id
+	Playlist struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"playlist"`
+}
+
+// PlaylistCreate 新建歌单
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e6%96%b0%e5%bb%ba%e6%ad%8c%e5%8d%95
+// needLogin: 是
+func (a *Api) PlaylistCreate(ctx context.Context, req *PlaylistCreateReq) (*PlaylistCreateResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/playlist/create"
+		reply PlaylistCreateResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	if req.Privacy == "" {
+		req.Privacy = "0"
+	}
+	if req.Type == "" {
+		req.Type = "NORMAL"
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type PlaylistRemoveReq struct {
+	Ids types.IntsString `json:"ids"` // 歌单id列表
+}
+
+// PlaylistRemoveResp 接口没有返回数据 成功响应: {"code":200}
+type PlaylistRemoveResp struct {
+	types.RespCommon[types.Empty]
+}
+
+// PlaylistRemove 删除自己创建的歌单
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%88%a0%e9%99%a4%e6%ad%8c%e5%8d%95
+// needLogin: 是
+func (a *Api) PlaylistRemove(ctx context.Context, req *PlaylistRemoveReq) (*PlaylistRemoveResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/playlist/remove"
+		reply PlaylistRemoveResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type PlaylistUpdateNameReq struct {
+	Id   int64  `json:"id"`   // 歌单id
+	Name string `json:"name"` // 新的歌单名称
+}
+
+// PlaylistUpdateNameResp 接口没有返回数据 成功响应: {"code":200}
+type PlaylistUpdateNameResp struct {
+	types.RespCommon[types.Empty]
+}
+
+// PlaylistUpdateName 更新歌单名称
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e6%9b%b4%e6%96%b0%e6%ad%8c%e5%8d%95%e5%90%8d
+// needLogin: 是
+func (a *Api) PlaylistUpdateName(ctx context.Context, req *PlaylistUpdateNameReq) (*PlaylistUpdateNameResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/playlist/update/name"
+		reply PlaylistUpdateNameResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type PlaymodeIntelligenceListReq struct {
+	types.ReqCommon
+	SongId       int64  `json:"songId"`       // 种子歌曲id
+	Type         string `json:"type"`         // 默认fromPlayOne
+	PlaylistId   int64  `json:"playlistId"`   // 歌单id,通常为"我喜欢的音乐"歌单id
+	StartMusicId int64  `json:"startMusicId"` // 起始歌曲id,默认与SongId相同
+	Count        int64  `json:"count"`        // 作用未知默认1
+}
+
+type PlaymodeIntelligenceListResp struct {
+	types.RespCommon[[]PlaymodeIntelligenceListRespData]
+}
+
+type PlaymodeIntelligenceListRespData struct {
+	Id          int64  `json:"id"`          // 歌曲id
+	Alg         string `json:"alg"`         // 推荐算法
+	Recommended bool   `json:"recommended"` // true:推荐得歌曲 false:来自歌单中得歌曲
+	SongInfo    struct {
+		Id   int64          `json:"id"`
+		Name string         `json:"name"`
+		Ar   []types.Artist `json:"ar"`
+		Al   types.Album    `json:"al"`
+		Dt   int64          `json:"dt"` // 歌曲时长单位毫秒
+	} `json:"songInfo"`
+}
+
+// PlaymodeIntelligenceList 心动模式/智能播放,根据种子歌曲生成播放列表
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%bf%83%e5%8a%a8%e6%a8%a1%e5%bc%8f%e6%99%ba%e8%83%bd%e6%92%ad%e6%94%be
+// needLogin: 是
+func (a *Api) PlaymodeIntelligenceList(ctx context.Context, req *PlaymodeIntelligenceListReq) (*PlaymodeIntelligenceListResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/playmode/intelligence/list"
+		reply PlaymodeIntelligenceListResp
+		opts  = api.NewOptions()
+	)
+	if req.Type == "" {
+		req.Type = "fromPlayOne"
+	}
+	if req.StartMusicId == 0 {
+		req.StartMusicId = req.SongId
+	}
+	if req.Count == 0 {
+		req.Count = 1
+	}
+
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
 		return nil, fmt.Errorf("Request: %w", err)
@@ -12,8 +12,9 @@ type PlaylistUpdatePlayCountReq struct {
 	Id string `json:"id"`
 }
 
+// PlaylistUpdatePlayCountResp 接口没有返回数据 成功响应: {"code":200}
 type PlaylistUpdatePlayCountResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 }
 
 func (a *Api) PlaylistUpdatePlayCount(ctx context.Context, req *PlaylistUpdatePlayCountReq) (*PlaylistUpdatePlayCountResp, error) {
@@ -22,6 +23,7 @@ func (a *Api) PlaylistUpdatePlayCount(ctx context.Context, req *PlaylistUpdatePl
 		reply PlaylistUpdatePlayCountResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -172,6 +172,99 @@ func (a *Api) RecommendSongs(ctx context.Context, req *RecommendSongsReq) (*Reco
 	return &reply, nil
 }
 
+type RecommendSongsDislikeReq struct {
+	types.ReqCommon
+	ResId     int64 `json:"resId"`     // 不感兴趣的歌曲id
+	ResType   int64 `json:"resType"`   // 资源类型 4:歌曲
+	SceneType int64 `json:"sceneType"` // 场景 1:每日推荐
+}
+
+type RecommendSongsDislikeResp struct {
+	types.RespCommon[RecommendSongsDislikeRespData]
+}
+
+// RecommendSongsDislikeRespData 用于替换不感兴趣歌曲的新推荐歌曲
+type RecommendSongsDislikeRespData struct {
+	Id   int64          `json:"id"`
+	Name string         `json:"name"`
+	Ar   []types.Artist `json:"ar"`
+	Al   types.Album    `json:"al"`
+	Dt   int64          `json:"dt"` // 歌曲时长单位毫秒
+	Alg  string         `json:"alg"`
+}
+
+// RecommendSongsDislike 每日推荐歌曲不感兴趣,返回一首新的推荐歌曲替换
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e6%af%8f%e6%97%a5%e6%8e%a8%e8%8d%90%e6%ad%8c%e6%9b%b2-%e4%b8%8d%e6%84%9f%e5%85%b4%e8%b6%a3
+// needLogin: 是
+func (a *Api) RecommendSongsDislike(ctx context.Context, req *RecommendSongsDislikeReq) (*RecommendSongsDislikeResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/v2/discovery/recommend/dislike"
+		reply RecommendSongsDislikeResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	if req.ResType == 0 {
+		req.ResType = 4
+	}
+	if req.SceneType == 0 {
+		req.SceneType = 1
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type RecommendResourceReq struct {
+	types.ReqCommon
+}
+
+type RecommendResourceResp struct {
+	types.RespCommon[types.Empty]
+	FeatureFirst  bool                            `json:"featureFirst"`
+	HaveRcmdSongs bool                            `json:"haveRcmdSongs"`
+	Recommend     []RecommendResourceRespPlaylist `json:"recommend"`
+}
+
+type RecommendResourceRespPlaylist struct {
+	Id         int64  `json:"id"`   // 歌单id
+	Type       int64  `json:"type"` // 类型
+	Name       string `json:"name"`
+	Copywriter string `json:"copywriter"` // 推荐语
+	PicUrl     string `json:"picUrl"`
+	Playcount  int64  `json:"playcount"`
+	CreateTime int64  `json:"createTime"`
+	TrackCount int64  `json:"trackCount"`
+	UserId     int64  `json:"userId"`
+	Alg        string `json:"alg"`
+	Creator    struct {
+		UserId   int64  `json:"userId"`
+		Nickname string `json:"nickname"`
+	} `json:"creator"`
+}
+
+// RecommendResource 每日推荐歌单
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e6%af%8f%e6%97%a5%e6%8e%a8%e8%8d%90%e6%ad%8c%e5%8d%95
+// needLogin: 是
+func (a *Api) RecommendResource(ctx context.Context, req *RecommendResourceReq) (*RecommendResourceResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/v1/discovery/recommend/resource"
+		reply RecommendResourceResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
 type PCDailyRecommendBlockReq struct {
 	// types.ReqCommon
 }
@@ -362,3 +455,80 @@ func (a *Api) PCDailyRecommendBlock(ctx context.Context, req *PCDailyRecommendBl
 	_ = resp
 	return &reply, nil
 }
+
+type RecommendSongsHistoryRecentReq struct{}
+
+type RecommendSongsHistoryRecentResp struct {
+	types.RespCommon[RecommendSongsHistoryRecentRespData]
+}
+
+type RecommendSongsHistoryRecentRespData struct {
+	Dates            []string `json:"dates"` // 有历史日推记录的日期,格式2006-01-02,按时间倒序
+	Description      string   `json:"description"`
+	NoHistoryMessage string   `json:"noHistoryMessage"`
+	PurchaseUrl      string   `json:"purchaseUrl"`
+}
+
+// RecommendSongsHistoryRecent 获取最近可查看的历史日推日期列表,目前需要黑胶vip
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e5%8e%86%e5%8f%b2%e6%97%a5%e6%8e%a8%e5%8f%af%e7%94%a8%e6%97%a5%e6%9c%9f%e5%88%97%e8%a1%a8
+// needLogin: 是
+func (a *Api) RecommendSongsHistoryRecent(ctx context.Context, req *RecommendSongsHistoryRecentReq) (*RecommendSongsHistoryRecentResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/discovery/recommend/songs/history/recent"
+		reply RecommendSongsHistoryRecentResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type RecommendSongsHistoryDetailReq struct {
+	Date string `json:"date"` // 日期,格式2006-01-02,从 RecommendSongsHistoryRecent 中获取
+}
+
+type RecommendSongsHistoryDetailResp struct {
+	types.RespCommon[RecommendSongsHistoryDetailRespData]
+}
+
+type RecommendSongsHistoryDetailRespData struct {
+	Songs []struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+		Ar   []struct {
+			Id   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"ar"`
+		Al struct {
+			Id     int64  `json:"id"`
+			Name   string `json:"name"`
+			PicUrl string `json:"picUrl"`
+		} `json:"al"`
+		Dt  int64 `json:"dt"`
+		Fee int64 `json:"fee"`
+	} `json:"songs"`
+	Description      string `json:"description"`
+	NoHistoryMessage string `json:"noHistoryMessage"`
+}
+
+// RecommendSongsHistoryDetail 获取某一天的历史日推歌曲列表
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e5%8e%86%e5%8f%b2%e6%97%a5%e6%8e%a8%e8%af%a6%e7%bb%86%e6%95%b0%e6%8d%ae
+// needLogin: 是
+func (a *Api) RecommendSongsHistoryDetail(ctx context.Context, req *RecommendSongsHistoryDetailReq) (*RecommendSongsHistoryDetailResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/discovery/recommend/songs/history/detail"
+		reply RecommendSongsHistoryDetailResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
@@ -77,3 +77,127 @@ func (a *Api) SearchDefault(ctx context.Context, req *SearchDefaultReq) (*Search
 	_ = resp
 	return &reply, nil
 }
+
+// SearchType 搜索类型
+type SearchType int64
+
+const (
+	SearchTypeSong     SearchType = 1    // 单曲
+	SearchTypeAlbum    SearchType = 10   // 专辑
+	SearchTypeArtist   SearchType = 100  // 歌手
+	SearchTypePlaylist SearchType = 1000 // 歌单
+	SearchTypeUser     SearchType = 1002 // 用户
+	SearchTypeMV       SearchType = 1004 // MV
+	SearchTypeLyric    SearchType = 1006 // 歌词
+	SearchTypeDjRadio  SearchType = 1009 // 电台
+	SearchTypeVideo    SearchType = 1014 // 视频
+)
+
+type CloudSearchReq struct {
+	types.ReqCommon
+	S      string     `json:"s"`      // 搜索关键词
+	Type   SearchType `json:"type"`   // 搜索类型 see: SearchType
+	Limit  int64      `json:"limit"`  // 每页数量 默认30
+	Offset int64      `json:"offset"` // 偏移量
+	Total  bool       `json:"total"`  // 是否返回总数
+}
+
+type CloudSearchResp struct {
+	types.RespCommon[types.Empty]
+	Result CloudSearchRespResult `json:"result"`
+}
+
+// CloudSearchRespResult 根据搜索类型不同,只有对应得字段有值
+type CloudSearchRespResult struct {
+	SongCount     int64                     `json:"songCount"`
+	Songs         []CloudSearchRespSong     `json:"songs"`
+	AlbumCount    int64                     `json:"albumCount"`
+	Albums        []CloudSearchRespAlbum    `json:"albums"`
+	ArtistCount   int64                     `json:"artistCount"`
+	Artists       []CloudSearchRespArtist   `json:"artists"`
+	PlaylistCount int64                     `json:"playlistCount"`
+	Playlists     []CloudSearchRespPlaylist `json:"playlists"`
+	DjRadiosCount int64                     `json:"djRadiosCount"`
+	DjRadios      []CloudSearchRespDjRadio  `json:"djRadios"`
+}
+
+type CloudSearchRespSong struct {
+	Id          int64          `json:"id"`
+	Name        string         `json:"name"`
+	Ar          []types.Artist `json:"ar"`
+	Al          types.Album    `json:"al"`
+	Dt          int64          `json:"dt"`  // 歌曲时长单位毫秒
+	Fee         int64          `json:"fee"` // see: types.Free
+	Mv          int64          `json:"mv"`
+	PublishTime int64          `json:"publishTime"`
+	// Lyrics 只有搜索类型为歌词时才有值
+	Lyrics    interface{}      `json:"lyrics"`
+	Privilege types.Privileges `json:"privilege"`
+}
+
+type CloudSearchRespAlbum struct {
+	Id          int64          `json:"id"`
+	Name        string         `json:"name"`
+	PicUrl      string         `json:"picUrl"`
+	Size        int64          `json:"size"` // 专辑歌曲数量
+	PublishTime int64          `json:"publishTime"`
+	Company     string         `json:"company"`
+	Artist      types.Artist   `json:"artist"`
+	Artists     []types.Artist `json:"artists"`
+}
+
+type CloudSearchRespArtist struct {
+	Id        int64    `json:"id"`
+	Name      string   `json:"name"`
+	PicUrl    string   `json:"picUrl"`
+	Alias     []string `json:"alias"`
+	AlbumSize int64    `json:"albumSize"`
+	MvSize    int64    `json:"mvSize"`
+}
+
+type CloudSearchRespPlaylist struct {
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	CoverImgUrl string `json:"coverImgUrl"`
+	TrackCount  int64  `json:"trackCount"`
+	PlayCount   int64  `json:"playCount"`
+	Creator     struct {
+		UserId   int64  `json:"userId"`
+		Nickname string `json:"nickname"`
+	} `json:"creator"`
+}
+
+type CloudSearchRespDjRadio struct {
+	Id           int64  `json:"id"`
+	Name         string `json:"name"`
+	PicUrl       string `json:"picUrl"`
+	ProgramCount int64  `json:"programCount"`
+	Dj           struct {
+		UserId   int64  `json:"userId"`
+		Nickname string `json:"nickname"`
+	} `json:"dj"`
+}
+
+// CloudSearch 综合搜索,支持单曲、专辑、歌手、歌单、电台等类型
+// url: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/blob/main/module/cloudsearch.js
+// needLogin: 否
+func (a *Api) CloudSearch(ctx context.Context, req *CloudSearchReq) (*CloudSearchResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/cloudsearch/get/web"
+		reply CloudSearchResp
+		opts  = api.NewOptions()
+	)
+	if req.Type == 0 {
+		req.Type = SearchTypeSong
+	}
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
@@ -0,0 +1,63 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package weapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+)
+
+type ShareShortUrlReq struct {
+	types.ReqCommon
+	Url string `json:"url"` // 需要生成短链接的分享地址 eg: https://music.163.com/song?id=1955097630
+}
+
+type ShareShortUrlResp struct {
+	types.RespCommon[ShareShortUrlRespData]
+}
+
+type ShareShortUrlRespData struct {
+	ShortUrl string `json:"shortUrl"` // 短链接 eg: http://163cn.tv/xxxxx
+}
+
+// ShareShortUrl 根据分享地址生成短链接
+// url:
+// needLogin: 未知
+func (a *Api) ShareShortUrl(ctx context.Context, req *ShareShortUrlReq) (*ShareShortUrlResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/middle/shorturl/generate"
+		reply ShareShortUrlResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
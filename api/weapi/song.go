@@ -27,9 +27,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/types"
+
+	"golang.org/x/sync/errgroup"
 )
 
 type SongDetailReq struct {
@@ -47,7 +50,7 @@ type songDetailReq struct {
 
 // SongDetailResp .
 type SongDetailResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	Songs      []SongDetailRespSongs `json:"songs"`
 	Privileges []types.Privileges    `json:"privileges"`
 }
@@ -135,7 +138,7 @@ type SongDetailRespSongs struct {
 	// OriginCoverType 0:未知 1:原曲 2:翻唱
 	OriginCoverType int64 `json:"originCoverType"`
 	// OriginSongSimpleData 对于翻唱曲，可选提供原曲简单格式的信息
-	OriginSongSimpleData interface{} `json:"originSongSimpleData"`
+	OriginSongSimpleData *OriginSongSimpleData `json:"originSongSimpleData"`
 	// SongMeiZuData 功能未知
 	TagPicList interface{} `json:"tagPicList"`
 	// ResourceState 未知
@@ -190,6 +193,62 @@ func (a *Api) SongDetail(ctx context.Context, req *SongDetailReq) (*SongDetailRe
 	return &reply, nil
 }
 
+// SongDetailLimit SongDetail 单次请求最多支持的歌曲数量
+const SongDetailLimit = 1000
+
+// songDetailParallel SongDetailBatch 并发请求数量
+const songDetailParallel = 4
+
+// SongDetailBatch 批量获取歌曲详情,ids数量不限,内部按 SongDetailLimit 分批并发请求后合并结果。
+// 重复的id只查询一次,返回的歌曲按分批顺序排列,不存在的歌曲不会出现在结果中。任意一批请求失败则返回错误。
+func (a *Api) SongDetailBatch(ctx context.Context, ids []int64) (*SongDetailResp, error) {
+	var (
+		set    = make(map[int64]struct{}, len(ids))
+		chunks [][]SongDetailReqList
+		chunk  []SongDetailReqList
+	)
+	for _, id := range ids {
+		if _, ok := set[id]; ok {
+			continue
+		}
+		set[id] = struct{}{}
+		chunk = append(chunk, SongDetailReqList{Id: strconv.FormatInt(id, 10), V: 0})
+		if len(chunk) >= SongDetailLimit {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	var (
+		replies = make([]*SongDetailResp, len(chunks))
+		g, gctx = errgroup.WithContext(ctx)
+	)
+	g.SetLimit(songDetailParallel)
+	for i, c := range chunks {
+		g.Go(func() error {
+			reply, err := a.SongDetail(gctx, &SongDetailReq{C: c})
+			if err != nil {
+				return fmt.Errorf("chunk %d: %w", i, err)
+			}
+			replies[i] = reply
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var merged = SongDetailResp{RespCommon: types.RespCommon[types.Empty]{Code: 200}}
+	for _, r := range replies {
+		merged.Songs = append(merged.Songs, r.Songs...)
+		merged.Privileges = append(merged.Privileges, r.Privileges...)
+	}
+	return &merged, nil
+}
+
 type SongMusicQualityReq struct {
 	SongId string `json:"songId"`
 }
@@ -262,7 +321,7 @@ type SongPlayerRespData struct {
 	FreeTrialInfo          types.FreeTrialInfo          `json:"freeTrialInfo"`
 	Level                  string                       `json:"level"` // 通常所说的音质水平 eg: standard、exhigh、higher、lossless、hires
 	EncodeType             string                       `json:"encodeType"`
-	ChannelLayout          interface{}                  `json:"channelLayout"`
+	ChannelLayout          interface{}                  `json:"channelLayout"` // 声道布局,空间音频音质时返回 eg: 5.1、7.1.4
 	FreeTrialPrivilege     types.FreeTrialPrivilege     `json:"freeTrialPrivilege"`
 	FreeTimeTrialPrivilege types.FreeTimeTrialPrivilege `json:"freeTimeTrialPrivilege"`
 	UrlSource              int64                        `json:"urlSource"`
@@ -326,9 +385,9 @@ type SongPlayerRespV1Data struct {
 	Flag                   int64                        `json:"flag"`
 	CanExtend              bool                         `json:"canExtend"`
 	FreeTrialInfo          types.FreeTrialInfo          `json:"freeTrialInfo"`
-	Level                  string                       `json:"level"`      // 音质水平 see: types.Level
-	EncodeType             string                       `json:"encodeType"` // eg: mp3
-	ChannelLayout          interface{}                  `json:"channelLayout"`
+	Level                  string                       `json:"level"`         // 音质水平 see: types.Level
+	EncodeType             string                       `json:"encodeType"`    // eg: mp3
+	ChannelLayout          interface{}                  `json:"channelLayout"` // 声道布局,空间音频音质时返回 eg: 5.1、7.1.4
 	FreeTrialPrivilege     types.FreeTrialPrivilege     `json:"freeTrialPrivilege"`
 	FreeTimeTrialPrivilege types.FreeTimeTrialPrivilege `json:"freeTimeTrialPrivilege"`
 	UrlSource              int64                        `json:"urlSource"`
@@ -361,7 +420,7 @@ func (a *Api) SongPlayerV1(ctx context.Context, req *SongPlayerV1Req) (*SongPlay
 		req.CSRFToken = csrf
 	}
 	if req.Level == types.LevelSky {
-		req.ImmerseType = "c51"
+		req.ImmerseType = types.ImmerseTypeC51
 	}
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
@@ -486,10 +545,10 @@ func (a *Api) SongDownloadUrlV1(ctx context.Context, req *SongDownloadUrlV1Req)
 		opts  = api.NewOptions()
 	)
 
-	// 目前不传值也没发现什么问题
-	// if req.Level == types.LevelSky {
-	// 	req.ImmerseType = "c51"
-	// }
+	// 目前不传值也没发现什么问题,为了和 SongPlayerV1 保持一致沉浸环绕声音质默认传c51
+	if req.Level == types.LevelSky && req.ImmerseType == "" {
+		req.ImmerseType = types.ImmerseTypeC51
+	}
 
 	resp, err := a.client.Request(ctx, url, &req, &reply, opts)
 	if err != nil {
@@ -498,3 +557,30 @@ func (a *Api) SongDownloadUrlV1(ctx context.Context, req *SongDownloadUrlV1Req)
 	_ = resp
 	return &reply, nil
 }
+
+type SongLikeListReq struct {
+	Uid string `json:"uid"` // 用户id
+}
+
+type SongLikeListResp struct {
+	types.RespCommon[types.Empty]
+	Ids        []int64 `json:"ids"`        // 喜欢的歌曲id,按喜欢时间倒序
+	CheckPoint int64   `json:"checkPoint"` // 毫秒时间戳
+}
+
+// SongLikeList 获取用户喜欢的全部歌曲id
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%96%9c%e6%ac%a2%e9%9f%b3%e4%b9%90%e5%88%97%e8%a1%a8
+// needLogin: 是
+func (a *Api) SongLikeList(ctx context.Context, req *SongLikeListReq) (*SongLikeListResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/song/like/get"
+		reply SongLikeListResp
+		opts  = api.NewOptions()
+	)
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
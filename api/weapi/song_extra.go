@@ -13,7 +13,12 @@ type SongDynamicCoverReq struct {
 }
 
 type SongDynamicCoverResp struct {
-	types.RespCommon[any]
+	types.RespCommon[SongDynamicCoverRespData]
+}
+
+type SongDynamicCoverRespData struct {
+	VideoPlayUrl   string `json:"videoPlayUrl"`   // 动态封面视频地址,歌曲没有动态封面时为空
+	NeedTransition bool   `json:"needTransition"` // 是否需要过渡动画
 }
 
 func (a *Api) SongDynamicCover(ctx context.Context, req *SongDynamicCoverReq) (*SongDynamicCoverResp, error) {
@@ -55,3 +60,145 @@ func (a *Api) SongLyricsMark(ctx context.Context, req *SongLyricsMarkReq) (*Song
 	_ = resp
 	return &reply, nil
 }
+
+// OriginSongSimpleData 翻唱曲对应的原曲信息
+type OriginSongSimpleData struct {
+	SongId    int64          `json:"songId"`
+	Name      string         `json:"name"`
+	Artists   []types.Artist `json:"artists"`
+	AlbumMeta types.Album    `json:"albumMeta"`
+}
+
+type SongWikiSummaryReq struct {
+	SongId int64 `json:"songId"`
+}
+
+type SongWikiSummaryResp struct {
+	types.RespCommon[SongWikiSummaryRespData]
+}
+
+type SongWikiSummaryRespData struct {
+	Blocks []SongWikiBlock `json:"blocks"`
+}
+
+// SongWikiBlock 音乐百科中的一个板块,例如: 音乐百科、音乐故事、相似歌曲等
+type SongWikiBlock struct {
+	Code      string                  `json:"code"`
+	UiElement SongWikiUiElement       `json:"uiElement"`
+	Creatives []SongWikiBlockCreative `json:"creatives"`
+}
+
+// SongWikiBlockCreative 板块中的一个条目,CreativeType常见取值: songTag(曲风) songBizTag(推荐标签) language(语种) bpm sheet(乐谱)
+type SongWikiBlockCreative struct {
+	CreativeType string             `json:"creativeType"`
+	UiElement    SongWikiUiElement  `json:"uiElement"`
+	Resources    []SongWikiResource `json:"resources"`
+}
+
+type SongWikiResource struct {
+	ResourceType string            `json:"resourceType"`
+	ResourceId   string            `json:"resourceId"`
+	UiElement    SongWikiUiElement `json:"uiElement"`
+}
+
+type SongWikiUiElement struct {
+	MainTitle struct {
+		Title string `json:"title"`
+	} `json:"mainTitle"`
+	TextLinks []struct {
+		Text string `json:"text"`
+		Url  string `json:"url"`
+	} `json:"textLinks"`
+}
+
+// Values 返回条目的值,优先取文本链接,其次取关联资源的标题
+func (c SongWikiBlockCreative) Values() []string {
+	var values = make([]string, 0, len(c.UiElement.TextLinks)+len(c.Resources))
+	for _, v := range c.UiElement.TextLinks {
+		if v.Text != "" {
+			values = append(values, v.Text)
+		}
+	}
+	if len(values) > 0 {
+		return values
+	}
+	for _, v := range c.Resources {
+		if v.UiElement.MainTitle.Title != "" {
+			values = append(values, v.UiElement.MainTitle.Title)
+		}
+	}
+	return values
+}
+
+// SongWikiSummary 歌曲音乐百科摘要,包含曲风、语种、BPM等信息
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/song_wiki_summary.js
+// needLogin: 否
+func (a *Api) SongWikiSummary(ctx context.Context, req *SongWikiSummaryReq) (*SongWikiSummaryResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/song/play/about/block/page"
+		reply SongWikiSummaryResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type AudioMatchReq struct {
+	AlgorithmCode string  `json:"algorithmCode"` // 默认: shazam_v2
+	Times         int64   `json:"times"`
+	SessionId     string  `json:"sessionId"`
+	Duration      float64 `json:"duration"` // 采样音频时长,单位秒
+	From          string  `json:"from"`     // 默认: recognize-song
+	RawData       string  `json:"rawdata"`  // base64编码的音频指纹
+}
+
+type AudioMatchResp struct {
+	types.RespCommon[AudioMatchRespData]
+}
+
+type AudioMatchRespData struct {
+	Type   int64                      `json:"type"`
+	Result []AudioMatchRespDataResult `json:"result"`
+}
+
+type AudioMatchRespDataResult struct {
+	StartTime int64 `json:"startTime"` // 匹配片段在歌曲中的位置,单位毫秒
+	Song      struct {
+		Id      int64          `json:"id"`
+		Name    string         `json:"name"`
+		Artists []types.Artist `json:"artists"`
+		Album   types.Album    `json:"album"`
+	} `json:"song"`
+}
+
+// AudioMatch 听歌识曲,根据音频指纹匹配歌曲。指纹由网页端听歌识曲的wasm模块根据采样音频生成
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/audio_match.js
+// needLogin: 否
+func (a *Api) AudioMatch(ctx context.Context, req *AudioMatchReq) (*AudioMatchResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/music/audio/match"
+		reply AudioMatchResp
+		opts  = api.NewOptions()
+	)
+	if req.AlgorithmCode == "" {
+		req.AlgorithmCode = "shazam_v2"
+	}
+	if req.Times == 0 {
+		req.Times = 1
+	}
+	if req.From == "" {
+		req.From = "recognize-song"
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
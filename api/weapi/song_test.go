@@ -26,6 +26,7 @@ package weapi
 import (
 	"testing"
 
+	"github.com/chaunsin/netease-cloud-music/api/apitest"
 	"github.com/chaunsin/netease-cloud-music/api/types"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +37,14 @@ func TestSongPlayer(t *testing.T) {
 	assert.NoError(t, err)
 	t.Logf("resp:%+v\n", got)
 }
+
+func TestSongPlayerReplay(t *testing.T) {
+	var request = New(apitest.Client(t, "testdata/song_player.json"))
+	got, err := request.SongPlayer(ctx, &SongPlayerReq{Ids: types.IntsString{2115747785}, Br: "128000"})
+	assert.NoError(t, err)
+	assert.NoError(t, got.Err())
+	assert.Len(t, got.Data, 1)
+	assert.Equal(t, int64(2115747785), got.Data[0].Id)
+	assert.Equal(t, "standard", got.Data[0].Level)
+	assert.Equal(t, int64(209293), got.Data[0].Time)
+}
@@ -240,7 +240,7 @@ func (a *Api) TopNewSongs(ctx context.Context, req *TopNewSongsReq) (*TopNewSong
 type TopListReq struct{}
 
 type TopListResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	List []TopListRespList `json:"list"`
 }
 
@@ -37,7 +37,8 @@ type GetUserInfoDetailReq struct {
 }
 
 type GetUserInfoDetailResp struct {
-	Code        int64 `json:"code"`  // 200:成功 404:未找到用户
+	// Code 200:成功 404:未找到用户
+	types.RespCommon[types.Empty]
 	Level       int64 `json:"level"` // 账号等级
 	ListenSongs int64 `json:"listenSongs"`
 	// UserPoint 云贝信息
@@ -184,3 +185,236 @@ func (a *Api) GetUserBindings(ctx context.Context, req *GetUserBindingsReq) (*Ge
 	_ = resp
 	return &reply, nil
 }
+
+type UserLevelReq struct {
+	types.ReqCommon
+}
+
+type UserLevelResp struct {
+	types.RespCommon[UserLevelRespData]
+	Full bool `json:"full"` // 是否已满级
+}
+
+type UserLevelRespData struct {
+	UserId         int64   `json:"userId"`
+	Info           string  `json:"info"`           // 当前等级权益说明
+	Progress       float64 `json:"progress"`       // 升级进度 0~1
+	NextPlayCount  int64   `json:"nextPlayCount"`  // 升级所需听歌数量
+	NextLoginCount int64   `json:"nextLoginCount"` // 升级所需登录天数
+	NowPlayCount   int64   `json:"nowPlayCount"`   // 当前累计听歌数量
+	NowLoginCount  int64   `json:"nowLoginCount"`  // 当前累计登录天数
+	Level          int64   `json:"level"`          // 当前等级
+}
+
+// UserLevel 获取账号等级及升级进度
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e7%94%a8%e6%88%b7%e7%ad%89%e7%ba%a7%e4%bf%a1%e6%81%af
+// needLogin: 是
+func (a *Api) UserLevel(ctx context.Context, req *UserLevelReq) (*UserLevelResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/user/level"
+		reply UserLevelResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type UserFollowsReq struct {
+	types.ReqCommon
+	Uid    int64 `json:"-"`      // 用户id
+	Offset int64 `json:"offset"` // 偏移量
+	Limit  int64 `json:"limit"`  // 每页数量
+	Order  bool  `json:"order"`  // true:按关注时间倒序
+}
+
+type UserFollowsResp struct {
+	types.RespCommon[types.Empty]
+	Follow []UserFollowsRespFollow `json:"follow"`
+	More   bool                    `json:"more"`
+}
+
+type UserFollowsRespFollow struct {
+	UserId        int64  `json:"userId"`
+	Nickname      string `json:"nickname"`
+	Signature     string `json:"signature"`
+	Followed      bool   `json:"followed"` // 当前用户是否关注了该用户
+	Mutual        bool   `json:"mutual"`   // 是否互相关注
+	Follows       int64  `json:"follows"`
+	Followeds     int64  `json:"followeds"`
+	EventCount    int64  `json:"eventCount"`
+	PlaylistCount int64  `json:"playlistCount"`
+	VipType       int64  `json:"vipType"`
+	Time          int64  `json:"time"` // 关注时间,粉丝列表分页参数
+}
+
+// UserFollows 获取用户关注列表
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e7%94%a8%e6%88%b7%e5%85%b3%e6%b3%a8%e5%88%97%e8%a1%a8
+// needLogin: 否
+func (a *Api) UserFollows(ctx context.Context, req *UserFollowsReq) (*UserFollowsResp, error) {
+	var (
+		url   = fmt.Sprintf("https://music.163.com/weapi/user/getfollows/%d", req.Uid)
+		reply UserFollowsResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type UserFollowedsReq struct {
+	types.ReqCommon
+	UserId string `json:"userId"` // 用户id
+	Time   int64  `json:"time"`   // 分页参数,取上一页最后一个粉丝的time,第一页传-1
+	Limit  int64  `json:"limit"`  // 每页数量
+}
+
+type UserFollowedsResp struct {
+	types.RespCommon[types.Empty]
+	Followeds []UserFollowsRespFollow `json:"followeds"`
+	More      bool                    `json:"more"`
+	Size      int64                   `json:"size"` // 粉丝总数
+}
+
+// UserFolloweds 获取用户粉丝列表
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e7%94%a8%e6%88%b7%e7%b2%89%e4%b8%9d%e5%88%97%e8%a1%a8
+// needLogin: 否
+func (a *Api) UserFolloweds(ctx context.Context, req *UserFollowedsReq) (*UserFollowedsResp, error) {
+	var (
+		url   = fmt.Sprintf("https://music.163.com/weapi/user/getfolloweds/%s", req.UserId)
+		reply UserFollowedsResp
+		opts  = api.NewOptions()
+	)
+	if req.Time == 0 {
+		req.Time = -1
+	}
+	if req.Limit == 0 {
+		req.Limit = 30
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type UserFollowReq struct {
+	types.ReqCommon
+	Id     int64 `json:"-"` // 用户id
+	Follow bool  `json:"-"` // true:关注 false:取消关注
+}
+
+type UserFollowResp struct {
+	types.RespCommon[types.Empty]
+	FollowContent string `json:"followContent"`
+}
+
+// UserFollow 关注或取消关注用户
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e5%85%b3%e6%b3%a8%e5%8f%96%e6%b6%88%e5%85%b3%e6%b3%a8%e7%94%a8%e6%88%b7
+// needLogin: 是
+func (a *Api) UserFollow(ctx context.Context, req *UserFollowReq) (*UserFollowResp, error) {
+	var (
+		url   = fmt.Sprintf("https://music.163.com/weapi/user/delfollow/%d", req.Id)
+		reply UserFollowResp
+		opts  = api.NewOptions()
+	)
+	opts.Mutating = true
+
+	if req.Follow {
+		url = fmt.Sprintf("https://music.163.com/weapi/user/follow/%d", req.Id)
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type RecentSongsReq struct {
+	types.ReqCommon
+	Limit int64 `json:"limit"` // 数量,最多300
+}
+
+type RecentSongsResp struct {
+	types.RespCommon[RecentSongsRespData]
+}
+
+type RecentSongsRespData struct {
+	Total int64                     `json:"total"`
+	List  []RecentSongsRespDataList `json:"list"`
+}
+
+type RecentSongsRespDataList struct {
+	ResourceId   string              `json:"resourceId"`   // 歌曲id
+	ResourceType string              `json:"resourceType"` // eg: SONG
+	PlayTime     int64               `json:"playTime"`     // 最近一次播放时间,毫秒时间戳
+	Data         SongDetailRespSongs `json:"data"`
+}
+
+// RecentSongs 获取最近播放的歌曲,同一首歌曲只保留最近一次播放记录
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e6%9c%80%e8%bf%91%e6%92%ad%e6%94%be-%e6%ad%8c%e6%9b%b2
+// needLogin: 是
+func (a *Api) RecentSongs(ctx context.Context, req *RecentSongsReq) (*RecentSongsResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/play-record/song/list"
+		reply RecentSongsResp
+		opts  = api.NewOptions()
+	)
+	if req.Limit == 0 {
+		req.Limit = 100
+	}
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type UserPlayRecordReq struct {
+	types.ReqCommon
+	Uid  string `json:"uid"`  // 用户id
+	Type int64  `json:"type"` // 0:所有时间 1:最近一周
+}
+
+type UserPlayRecordResp struct {
+	types.RespCommon[types.Empty]
+	WeekData []UserPlayRecordRespData `json:"weekData"`
+	AllData  []UserPlayRecordRespData `json:"allData"`
+}
+
+type UserPlayRecordRespData struct {
+	PlayCount int64               `json:"playCount"` // 播放次数
+	Score     int64               `json:"score"`     // 播放热度,最高为100
+	Song      SongDetailRespSongs `json:"song"`
+}
+
+// UserPlayRecord 获取用户听歌排行,最多返回播放次数最多的100首歌曲.用户关闭听歌排行公开时只有本人可以查看
+// url: https://docs-neteasecloudmusicapi.vercel.app/docs/#/?id=%e8%8e%b7%e5%8f%96%e7%94%a8%e6%88%b7%e6%92%ad%e6%94%be%e8%ae%b0%e5%bd%95
+// needLogin: 否
+func (a *Api) UserPlayRecord(ctx context.Context, req *UserPlayRecordReq) (*UserPlayRecordResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/v1/play/record"
+		reply UserPlayRecordResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
@@ -0,0 +1,270 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package weapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+)
+
+type ArtistVideoReq struct {
+	ArtistId string `json:"artistId"`
+	// Page 分页参数json字符串 例如: {"size":10,"cursor":0}
+	Page  string `json:"page"`
+	Tab   int64  `json:"tab"`
+	Order int64  `json:"order"` // 0:最新 1:最热
+}
+
+type ArtistVideoResp struct {
+	types.RespCommon[ArtistVideoRespData]
+}
+
+type ArtistVideoRespData struct {
+	Records []ArtistVideoRespRecord `json:"records"`
+	Page    struct {
+		Size int64 `json:"size"`
+		// Cursor 下一页游标,原样放入下一次请求的page参数中
+		Cursor json.RawMessage `json:"cursor"`
+		More   bool            `json:"more"`
+	} `json:"page"`
+}
+
+type ArtistVideoRespRecord struct {
+	Id       string `json:"id"`
+	Type     int64  `json:"type"`
+	Resource struct {
+		MlogBaseData MlogBaseData `json:"mlogBaseData"`
+	} `json:"resource"`
+}
+
+// MlogBaseData Mlog及视频的基础信息
+type MlogBaseData struct {
+	Id       string `json:"id"`
+	Type     int64  `json:"type"`
+	Text     string `json:"text"`
+	Desc     string `json:"desc"`
+	CoverUrl string `json:"coverUrl"`
+	// Duration 时长,单位毫秒
+	Duration int64 `json:"duration"`
+	PubTime  int64 `json:"pubTime"`
+}
+
+// ArtistVideo 获取歌手的视频列表,包含演唱会、现场及Mlog等MV接口获取不到的视频
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/artist_video.js
+// needLogin: 否
+func (a *Api) ArtistVideo(ctx context.Context, req *ArtistVideoReq) (*ArtistVideoResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/mlog/artist/video"
+		reply ArtistVideoResp
+		opts  = api.NewOptions()
+	)
+	if req.Page == "" {
+		req.Page = `{"size":10,"cursor":0}`
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type MlogDetailReq struct {
+	Id         string `json:"id"`
+	Resolution int64  `json:"resolution"`
+	Type       int64  `json:"type"`
+}
+
+type MlogDetailResp struct {
+	types.RespCommon[MlogDetailRespData]
+}
+
+type MlogDetailRespData struct {
+	Id       string `json:"id"`
+	Type     int64  `json:"type"`
+	Resource struct {
+		MlogBaseData MlogBaseData `json:"mlogBaseData"`
+		UserProfile  struct {
+			UserId   int64  `json:"userId"`
+			Nickname string `json:"nickname"`
+		} `json:"userProfile"`
+		Content struct {
+			Title string `json:"title"`
+			Video struct {
+				Duration int64 `json:"duration"`
+				Width    int64 `json:"width"`
+				Height   int64 `json:"height"`
+				UrlInfo  struct {
+					Id   string `json:"id"`
+					Url  string `json:"url"`
+					Size int64  `json:"size"`
+					R    int64  `json:"r"`
+				} `json:"urlInfo"`
+			} `json:"video"`
+		} `json:"content"`
+	} `json:"resource"`
+}
+
+// MlogDetail 获取Mlog详情及播放地址
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/mlog_url.js
+// needLogin: 否
+func (a *Api) MlogDetail(ctx context.Context, req *MlogDetailReq) (*MlogDetailResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/mlog/detail/v1"
+		reply MlogDetailResp
+		opts  = api.NewOptions()
+	)
+	if req.Resolution == 0 {
+		req.Resolution = 1080
+	}
+	if req.Type == 0 {
+		req.Type = 1
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type MlogToVideoReq struct {
+	MlogId string `json:"mlogId"`
+}
+
+// MlogToVideoResp Data为视频id
+type MlogToVideoResp struct {
+	types.RespCommon[string]
+}
+
+// MlogToVideo 将Mlog id转换为视频id
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/mlog_to_video.js
+// needLogin: 否
+func (a *Api) MlogToVideo(ctx context.Context, req *MlogToVideoReq) (*MlogToVideoResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/mlog/video/convert/id"
+		reply MlogToVideoResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type VideoDetailReq struct {
+	Id string `json:"id"`
+}
+
+type VideoDetailResp struct {
+	types.RespCommon[VideoDetailRespData]
+}
+
+type VideoDetailRespData struct {
+	Vid         string `json:"vid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CoverUrl    string `json:"coverUrl"`
+	// DurationMs 时长,单位毫秒
+	DurationMs  int64 `json:"durationms"`
+	PublishTime int64 `json:"publishTime"`
+	PlayTime    int64 `json:"playTime"`
+	Creator     struct {
+		UserId   int64  `json:"userId"`
+		Nickname string `json:"nickname"`
+	} `json:"creator"`
+	// Resolutions 支持的分辨率
+	Resolutions []struct {
+		Resolution int64 `json:"resolution"`
+		Size       int64 `json:"size"`
+	} `json:"resolutions"`
+}
+
+// VideoDetail 获取视频详情
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/video_detail.js
+// needLogin: 否
+func (a *Api) VideoDetail(ctx context.Context, req *VideoDetailReq) (*VideoDetailResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/cloudvideo/v1/video/detail"
+		reply VideoDetailResp
+		opts  = api.NewOptions()
+	)
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
+
+type VideoUrlReq struct {
+	// Ids 视频id列表json字符串 例如: ["89ADDE33C0AAE8EC14B99F6750DB954D"]
+	Ids        string `json:"ids"`
+	Resolution int64  `json:"resolution"`
+}
+
+type VideoUrlResp struct {
+	types.RespCommon[types.Empty]
+	Urls []VideoUrlRespUrl `json:"urls"`
+}
+
+type VideoUrlRespUrl struct {
+	Id           string `json:"id"`
+	Url          string `json:"url"`
+	Size         int64  `json:"size"`
+	R            int64  `json:"r"`
+	ValidityTime int64  `json:"validityTime"`
+	NeedPay      bool   `json:"needPay"`
+}
+
+// VideoUrl 获取视频播放地址
+// url: https://github.com/Binaryify/NeteaseCloudMusicApi/blob/master/module/video_url.js
+// needLogin: 否
+func (a *Api) VideoUrl(ctx context.Context, req *VideoUrlReq) (*VideoUrlResp, error) {
+	var (
+		url   = "https://music.163.com/weapi/cloudvideo/playurl"
+		reply VideoUrlResp
+		opts  = api.NewOptions()
+	)
+	if req.Resolution == 0 {
+		req.Resolution = 1080
+	}
+
+	resp, err := a.client.Request(ctx, url, req, &reply, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Request: %w", err)
+	}
+	_ = resp
+	return &reply, nil
+}
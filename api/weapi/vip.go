@@ -60,6 +60,7 @@ func (a *Api) VipRewardGet(ctx context.Context, req *VipRewardGetReq) (*VipRewar
 			TaskIds: strings.Join(req.TaskIds, ","),
 		}
 	)
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, &request, &reply, opts)
 	if err != nil {
@@ -88,6 +89,7 @@ func (a *Api) VipRewardGetAll(ctx context.Context, req *VipRewardGetAllReq) (*Vi
 		reply VipRewardGetAllResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -1300,6 +1302,8 @@ func (a *Api) VipTaskSign(ctx context.Context, req *VipTaskSignReq) (*VipTaskSig
 		reply VipTaskSignResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
+
 	if req.IsNew != "" {
 		url = url + "?isNew=" + req.IsNew
 	}
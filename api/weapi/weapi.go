@@ -21,6 +21,14 @@
 // SOFTWARE.
 //
 
+// Package weapi 网页端、小程序使用的接口,使用方式:
+//
+//	cli, err := api.NewWithOptions(api.WithCookieFile("./cookie.json"))
+//	if err != nil {
+//		return err
+//	}
+//	defer cli.Close(ctx)
+//	reply, err := weapi.New(cli).GetUserInfo(ctx, &weapi.GetUserInfoReq{})
 package weapi
 
 import (
@@ -29,7 +37,6 @@ import (
 	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
-	"github.com/chaunsin/netease-cloud-music/pkg/log"
 )
 
 type Api struct {
@@ -38,9 +45,16 @@ type Api struct {
 
 func New(client *api.Client) *Api {
 	a := Api{client: client}
+	client.OnNeedLogin(a.refreshToken)
 	return &a
 }
 
+// refreshToken 接口返回需要登录时尝试刷新登录token
+func (a *Api) refreshToken(ctx context.Context) error {
+	_, err := a.TokenRefresh(ctx, &TokenRefreshReq{})
+	return err
+}
+
 func (a *Api) NeedLogin(ctx context.Context) bool {
 	u, _ := url.Parse("https://music.163.com")
 	for _, ck := range a.client.GetClient().Jar.Cookies(u) {
@@ -50,8 +64,8 @@ func (a *Api) NeedLogin(ctx context.Context) bool {
 			if err != nil {
 				return true
 			}
-			log.Debug("NeedLogin: %+v", reply)
-			if reply.Code != 200 || reply.Account == nil || reply.Profile == nil {
+			a.client.Logger().Debug("NeedLogin: %+v", reply)
+			if reply.Account == nil || reply.Profile == nil {
 				return true
 			}
 			return false
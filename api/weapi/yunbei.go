@@ -41,7 +41,7 @@ type SignInReq struct {
 // SignInResp 签到返回
 type SignInResp struct {
 	// Code 错误码 -2:重复签到 200:成功(会有例外会出现“功能暂不支持”) 301:未登录
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	// Point 签到获得积分奖励数量,目前签到规则已经更改变成连续几天签到才能拿获取奖励
 	Point int64 `json:"point"`
 }
@@ -59,6 +59,8 @@ func (a *Api) SignIn(ctx context.Context, req *SignInReq) (*SignInResp, error) {
 		reply SignInResp
 		opts  = api.NewOptions()
 	)
+	opts.Codes = []int64{-2} // 重复签到
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -245,7 +247,7 @@ func (a *Api) YunBeiSignInfo(ctx context.Context, req *YunBeiSignInfoReq) (*YunB
 type YunBeiUserInfoReq struct{}
 
 type YunBeiUserInfoResp struct {
-	types.RespCommon[any]
+	types.RespCommon[types.Empty]
 	// Level 账号等级L1~L10
 	Level     int64 `json:"level"`
 	UserPoint struct {
@@ -313,6 +315,7 @@ func (a *Api) YunBeiSignIn(ctx context.Context, req *YunBeiSignInReq) (*YunBeiSi
 		reply YunBeiSignInResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -639,6 +642,7 @@ func (a *Api) YunBeiTaskFinish(ctx context.Context, req *YunBeiTaskFinishReq) (*
 		reply YunBeiTaskFinishResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -1096,7 +1100,7 @@ func (a *Api) YunBeiTaskRecommendV2(ctx context.Context, req *YunBeiTaskRecommen
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("data: %+v\n", string(data))
+	a.client.Logger().Debug("YunBeiTaskRecommendV2 adExtJson: %s", data)
 	url += neturl.QueryEscape(string(data))
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
@@ -1125,6 +1129,7 @@ func (a *Api) YunBeiCoinRecordInsert(ctx context.Context, req *YunBeiCoinRecordI
 		reply YunBeiCoinRecordInsertResp
 		opts  = api.NewOptions()
 	)
+	opts.Mutating = true
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
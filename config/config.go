@@ -2,15 +2,21 @@ package config
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/pkg/alert"
 	"github.com/chaunsin/netease-cloud-music/pkg/database"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/normalize"
+	"github.com/chaunsin/netease-cloud-music/pkg/scrobbler"
 
 	"github.com/go-viper/mapstructure/v2"
+	"github.com/robfig/cron/v3"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
@@ -24,11 +30,8 @@ var (
 )
 
 func init() {
-	var err error
-	HomeDir, err = os.UserHomeDir()
-	if err != nil {
-		panic(err)
-	}
+	// 容器中可能没有设置HOME,此时需要通过 NCMCTL_HOME 或 --home 指定目录
+	HomeDir, _ = os.UserHomeDir()
 	if err := yaml.Unmarshal(defaultConfigByte, &defaultConfig); err != nil {
 		panic(fmt.Sprintf("defaultConfig.Unmarshal: %s", err))
 	}
@@ -39,27 +42,194 @@ func init() {
 }
 
 type Config struct {
-	v        *viper.Viper
-	Version  string           `json:"version" yaml:"version"`
-	Log      *log.Config      `json:"log" yaml:"log"`
-	Network  *api.Config      `json:"network" yaml:"network"`
-	Database *database.Config `json:"database" yaml:"database"`
+	v         *viper.Viper
+	Version   string            `json:"version" yaml:"version"`
+	Profile   string            `json:"profile" yaml:"profile"`
+	Log       *log.Config       `json:"log" yaml:"log"`
+	Network   *api.Config       `json:"network" yaml:"network"`
+	Database  *database.Config  `json:"database" yaml:"database"`
+	Normalize *normalize.Config `json:"normalize" yaml:"normalize"`
+	Alert     *alert.Config     `json:"alert" yaml:"alert"`
+	Scrobbler *scrobbler.Config `json:"scrobbler" yaml:"scrobbler"`
+	Daemon    *Daemon           `json:"daemon" yaml:"daemon"`
+	Mirrors   []*Mirror         `json:"mirrors" yaml:"mirrors"`
+	Download  *Download         `json:"download" yaml:"download"`
+}
+
+// Download 下载命令的默认参数,命令行未指定对应参数时使用
+type Download struct {
+	// Output 下载目录
+	Output string `json:"output" yaml:"output"`
+	// Level 下载歌曲品质
+	Level string `json:"level" yaml:"level"`
+	// Parallel 并发下载数量
+	Parallel int64 `json:"parallel" yaml:"parallel"`
+}
+
+func (d *Download) Validate() error {
+	if d == nil {
+		return nil
+	}
+	if d.Parallel < 0 || d.Parallel > 20 {
+		return fmt.Errorf("parallel must be between 0 and 20")
+	}
+	if d.Level != "" && !validLevel(d.Level) {
+		return fmt.Errorf("level is not support: %s", d.Level)
+	}
+	return nil
+}
+
+// Mirror 歌单镜像配置,由 ncmctl playlist mirror 命令将远程歌单同步到本地目录
+type Mirror struct {
+	// Playlist 歌单id或分享链接
+	Playlist string `json:"playlist" yaml:"playlist"`
+	// Output 本地目录,下载的歌曲、同步清单及播放列表保存在该目录下
+	Output string `json:"output" yaml:"output"`
+	// Level 下载歌曲品质
+	Level string `json:"level" yaml:"level"`
+	// Prune 删除已从歌单中移除的歌曲的本地文件
+	Prune bool `json:"prune" yaml:"prune"`
+	// Format 播放列表格式 m3u、m3u8,为空时不生成
+	Format string `json:"format" yaml:"format"`
+}
+
+func (m *Mirror) Validate() error {
+	if m.Playlist == "" {
+		return fmt.Errorf("playlist is required")
+	}
+	if m.Output == "" {
+		return fmt.Errorf("output is required")
+	}
+	if m.Level != "" && !validLevel(m.Level) {
+		return fmt.Errorf("level is not support: %s", m.Level)
+	}
+	switch m.Format {
+	case "", "m3u", "m3u8":
+	default:
+		return fmt.Errorf("format is not support: %s", m.Format)
+	}
+	return nil
+}
+
+// Daemon 常驻定时任务配置,由 ncmctl daemon 命令加载执行
+type Daemon struct {
+	// Location 定时任务时区
+	Location string `json:"location" yaml:"location"`
+	// History 任务执行记录文件路径,digest 命令根据该文件生成周报/月报
+	History string `json:"history" yaml:"history"`
+	// Metrics Prometheus 监控指标监听地址,为空则不开启
+	Metrics string `json:"metrics" yaml:"metrics"`
+	// State 调度状态文件路径,记录各任务上次/下次执行时间以及是否被中断,重启后据此补跑错过或中断的任务,为空则不记录
+	State string `json:"state" yaml:"state"`
+	// Grace 收到退出信号后等待正在执行的任务完成的最长时长,超时后中止任务并保存进度,为0时使用默认值30s
+	Grace time.Duration `json:"grace" yaml:"grace"`
+	// Jobs 定时任务列表
+	Jobs []*Job `json:"jobs" yaml:"jobs"`
+}
+
+// Validate 校验任务配置,任务command是否支持以及时区由daemon命令校验
+func (d *Daemon) Validate() error {
+	if d == nil {
+		return nil
+	}
+	var (
+		errs  []error
+		names = make(map[string]struct{}, len(d.Jobs))
+	)
+	if d.Grace < 0 {
+		errs = append(errs, fmt.Errorf("grace must be >= 0"))
+	}
+	for i, job := range d.Jobs {
+		if job.Name == "" {
+			errs = append(errs, fmt.Errorf("jobs[%d]: name is required", i))
+			continue
+		}
+		if _, ok := names[job.Name]; ok {
+			errs = append(errs, fmt.Errorf("[%s] duplicate job name", job.Name))
+		}
+		names[job.Name] = struct{}{}
+		if _, err := cron.ParseStandard(job.Cron); err != nil {
+			errs = append(errs, fmt.Errorf("[%s] ParseStandard: %w", job.Name, err))
+		}
+		if job.Jitter < 0 {
+			errs = append(errs, fmt.Errorf("[%s] jitter must be >= 0", job.Name))
+		}
+		if job.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("[%s] timeout must be >= 0", job.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Job 单个定时任务配置
+type Job struct {
+	// Name 任务名称,用于日志输出,需唯一
+	Name string `json:"name" yaml:"name"`
+	// Enable 是否启用
+	Enable bool `json:"enable" yaml:"enable"`
+	// Command 执行得子命令名称,比如 sign、scrobble、partner、download
+	Command string `json:"command" yaml:"command"`
+	// Args 子命令参数
+	Args []string `json:"args" yaml:"args"`
+	// Cron 标准crontab表达式
+	Cron string `json:"cron" yaml:"cron"`
+	// Jitter 随机延迟执行的最大时长,避免每次都在固定时间点请求
+	Jitter time.Duration `json:"jitter" yaml:"jitter"`
+	// Timeout 单次执行的最长时长,超时后中止任务,为0时不限制
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
 }
 
 func (c *Config) Validate() error {
+	if err := c.Log.Validate(); err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+	if err := c.Normalize.Validate(); err != nil {
+		return fmt.Errorf("normalize: %w", err)
+	}
+	if c.Network != nil {
+		if err := c.Network.Validate(); err != nil {
+			return fmt.Errorf("network: %w", err)
+		}
+	}
+	if err := c.Daemon.Validate(); err != nil {
+		return fmt.Errorf("daemon: %w", err)
+	}
+	for i, m := range c.Mirrors {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("mirrors[%d]: %w", i, err)
+		}
+	}
+	if err := c.Download.Validate(); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
 	return nil
 }
 
+// validLevel 判断歌曲品质是否支持,包含128、HQ等别名
+func validLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "standard", "higher", "exhigh", "lossless", "hires", "jyeffect", "sky", "jymaster",
+		"128", "192", "320", "hq", "sq", "hr":
+		return true
+	}
+	return false
+}
+
 func GetDefault() *Config {
 	return defaultConfig
 }
 
+// Template 返回带注释说明的默认配置文件内容,可作为新配置文件的模板
+func Template() []byte {
+	return defaultConfigByte
+}
+
 func New(cfgPath ...string) (*Config, error) {
 	var (
 		conf Config
-		opts = viper.DecodeHook(func(m *mapstructure.DecoderConfig) {
+		opts viper.DecoderConfigOption = func(m *mapstructure.DecoderConfig) {
 			m.TagName = "yaml"
-		})
+		}
 		_cfgPath string
 	)
 	if len(cfgPath) > 0 {
@@ -86,16 +256,15 @@ func New(cfgPath ...string) (*Config, error) {
 	return &conf, nil
 }
 
-// ReplaceMagicVariables 替换配置文件中的魔法变量。注意该方法只能调用一次再次调用则不会生效.
-func (c *Config) ReplaceMagicVariables(name, value string) (*Config, bool) {
+// ReplaceMagicVariables 替换配置文件中的魔法变量,例如 ${HOME}、${STATE}。注意该方法只能调用一次再次调用则不会生效.
+func (c *Config) ReplaceMagicVariables(vars map[string]string) (*Config, bool) {
 
 	var (
 		isset   bool
 		mapping = func(k string) string {
-			switch k {
-			case name:
+			if v, ok := vars[k]; ok {
 				isset = true
-				return value
+				return v
 			}
 			return ""
 		}
@@ -103,6 +272,24 @@ func (c *Config) ReplaceMagicVariables(name, value string) (*Config, bool) {
 
 	c.Log.Rotate.Filename = os.Expand(c.Log.Rotate.Filename, mapping)
 	c.Network.Cookie.Filepath = os.Expand(c.Network.Cookie.Filepath, mapping)
+	c.Network.Device.Filepath = os.Expand(c.Network.Device.Filepath, mapping)
 	c.Database.Path = os.Expand(c.Database.Path, mapping)
+	if c.Scrobbler != nil {
+		c.Scrobbler.Path = os.Expand(c.Scrobbler.Path, mapping)
+	}
+	for _, m := range c.Mirrors {
+		m.Output = os.Expand(m.Output, mapping)
+	}
+	if c.Download != nil {
+		c.Download.Output = os.Expand(c.Download.Output, mapping)
+	}
+	if c.Daemon != nil {
+		c.Daemon.History = os.Expand(c.Daemon.History, mapping)
+		for _, job := range c.Daemon.Jobs {
+			for i := range job.Args {
+				job.Args[i] = os.Expand(job.Args[i], mapping)
+			}
+		}
+	}
 	return c, isset
 }
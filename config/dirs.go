@@ -0,0 +1,114 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// EnvConfig 配置文件路径环境变量,优先级低于 -c 参数
+	EnvConfig = "NCMCTL_CONFIG"
+	// EnvHome 数据目录环境变量,设置后配置、状态及缓存文件都存放在该目录下
+	EnvHome = "NCMCTL_HOME"
+)
+
+// Dirs ncmctl运行时使用的目录,配置文件中可通过 ${CONFIG}、${STATE}、${CACHE}、${DATA} 引用
+type Dirs struct {
+	Config string // 配置文件目录
+	State  string // 登录cookie、设备指纹、日志、本地数据库及执行历史等状态目录
+	Cache  string // 可以随时删除的缓存目录
+	Data   string // 下载、备份等数据的默认目录
+	Legacy bool   // 是否为旧版本的 ${HOME}/.ncmctl 单目录布局
+}
+
+// LegacyDir 旧版本所有文件都存放在 ${HOME}/.ncmctl 目录下
+func LegacyDir(home string) string {
+	return filepath.Join(home, ".ncmctl")
+}
+
+// ResolveDirs 按以下优先级确定目录:
+// 1. 环境变量 NCMCTL_HOME,所有文件存放在同一目录下,便于容器挂载数据卷
+// 2. 旧版本目录 ${HOME}/.ncmctl 已存在时继续使用,可通过 ncmctl config migrate 迁移
+// 3. XDG Base Directory 规范,参考 XDGDirs
+//
+// getenv 用于读取环境变量,传入返回空值的函数时只根据home确定目录
+func ResolveDirs(home string, getenv func(string) string) (Dirs, error) {
+	if dir := getenv(EnvHome); dir != "" {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return Dirs{}, fmt.Errorf("%s: %w", EnvHome, err)
+		}
+		return Dirs{Config: dir, State: dir, Cache: dir, Data: dir}, nil
+	}
+	if home == "" {
+		return Dirs{}, errors.New("unable to determine home directory, please specify --home or " + EnvHome)
+	}
+	if info, err := os.Stat(LegacyDir(home)); err == nil && info.IsDir() {
+		var dir = LegacyDir(home)
+		return Dirs{Config: dir, State: dir, Cache: dir, Data: dir, Legacy: true}, nil
+	}
+	return XDGDirs(home, getenv), nil
+}
+
+// XDGDirs 按 XDG Base Directory 规范确定目录,环境变量未设置或不是绝对路径时使用规范中的默认值。
+// windows及macOS没有设置XDG环境变量时沿用旧版本目录
+func XDGDirs(home string, getenv func(string) string) Dirs {
+	var (
+		set bool
+		xdg = func(env, def string) string {
+			if dir := getenv(env); filepath.IsAbs(dir) {
+				set = true
+				return filepath.Join(dir, "ncmctl")
+			}
+			return filepath.Join(home, def, "ncmctl")
+		}
+		dirs = Dirs{
+			Config: xdg("XDG_CONFIG_HOME", ".config"),
+			State:  xdg("XDG_STATE_HOME", filepath.Join(".local", "state")),
+			Cache:  xdg("XDG_CACHE_HOME", ".cache"),
+			Data:   xdg("XDG_DATA_HOME", filepath.Join(".local", "share")),
+		}
+	)
+	if !set && (runtime.GOOS == "windows" || runtime.GOOS == "darwin") {
+		var dir = LegacyDir(home)
+		return Dirs{Config: dir, State: dir, Cache: dir, Data: dir, Legacy: true}
+	}
+	return dirs
+}
+
+// Variables 配置文件中可使用的魔法变量
+func (d Dirs) Variables(home string) map[string]string {
+	return map[string]string{
+		"HOME":   home,
+		"CONFIG": d.Config,
+		"STATE":  d.State,
+		"CACHE":  d.Cache,
+		"DATA":   d.Data,
+	}
+}
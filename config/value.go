@@ -0,0 +1,148 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GetValue 按点分隔的key获取yaml配置中的值,序列使用下标,例如 daemon.jobs.0.cron。
+// 标量返回原始值,映射及序列返回yaml格式内容,key为空时返回整个配置
+func GetValue(data []byte, key string) (string, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return "", err
+	}
+	node, err := lookup(doc.Content[0], key, false)
+	if err != nil {
+		return "", err
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, nil
+	}
+	out, err := encode(node)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// SetValue 按点分隔的key设置yaml配置中的值并保留注释,value按yaml语法解析,
+// 比如 true、30s、[a, b]。不存在的映射key会自动创建,是否为合法配置项由调用方加载校验
+func SetValue(data []byte, key, value string) ([]byte, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key is empty")
+	}
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	var v yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+		return nil, fmt.Errorf("value: %w", err)
+	}
+	var val = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""}
+	if len(v.Content) > 0 {
+		val = v.Content[0]
+	}
+
+	node, err := lookup(doc.Content[0], key, true)
+	if err != nil {
+		return nil, err
+	}
+	// 保留原有的注释
+	val.HeadComment, val.LineComment, val.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = *val
+	return encode(&doc)
+}
+
+func parseDocument(data []byte) (yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return doc, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+	if len(doc.Content) <= 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return doc, fmt.Errorf("config must be a mapping")
+	}
+	return doc, nil
+}
+
+// lookup 查找key对应的节点,create为true时自动创建不存在的映射key
+func lookup(node *yaml.Node, key string, create bool) (*yaml.Node, error) {
+	if key == "" {
+		return node, nil
+	}
+	var path string
+	for _, part := range strings.Split(key, ".") {
+		path = strings.TrimPrefix(path+"."+part, ".")
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == part {
+					next = node.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				if !create {
+					return nil, fmt.Errorf("%s not found", path)
+				}
+				next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, next)
+			}
+			node = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil, fmt.Errorf("%s: index out of range, length %d", path, len(node.Content))
+			}
+			node = node.Content[i]
+		default:
+			return nil, fmt.Errorf("%s: parent is not a mapping or sequence", path)
+		}
+	}
+	return node, nil
+}
+
+func encode(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	var encoder = yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("yaml.Encode: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("yaml.Encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,74 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package example
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// TestMiddleware 自定义接口调用中间件以及http请求拦截器
+func TestMiddleware(t *testing.T) {
+	// 1. 接口调用中间件,拿到的是加密之前的请求参数以及解析后的响应,可用于日志、统计、修改请求参数等
+	cli.Use(func(next api.Handler) api.Handler {
+		return func(ctx context.Context, call *api.Call) (*resty.Response, error) {
+			var start = time.Now()
+			resp, err := next(ctx, call)
+			t.Logf("url=%s cost=%s err=%v", call.Url, time.Since(start), err)
+			return resp, err
+		}
+	})
+
+	// 2. http请求拦截器,拿到的是加密之后实际发送的http请求,可用于添加请求头、记录原始请求响应等
+	cli.Intercept(
+		api.SetHeader("X-Real-IP", "118.88.88.88"),
+		func(next api.RoundTripFunc) api.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				resp, err := next(req)
+				if err == nil {
+					t.Logf("method=%s url=%s status=%d", req.Method, req.URL, resp.StatusCode)
+				}
+				return resp, err
+			}
+		},
+	)
+
+	// 3. 接口返回码非200时默认返回 *api.Error,可通过 errors.Is 判断错误分类
+	resp, err := weapi.New(cli).GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if errors.Is(err, api.ErrNeedLogin) {
+		t.Skip("need login")
+	}
+	if err != nil {
+		t.Fatalf("GetUserInfo: %s", err)
+	}
+	t.Logf("GetUserInfo: %+v", resp)
+}
@@ -25,12 +25,11 @@ package example
 
 import (
 	"context"
+	"log/slog"
 	"os"
 	"testing"
 
 	"github.com/chaunsin/netease-cloud-music/api"
-	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
-	"github.com/chaunsin/netease-cloud-music/pkg/log"
 )
 
 var (
@@ -39,21 +38,14 @@ var (
 )
 
 func TestMain(t *testing.M) {
-	log.Default = log.New(&log.Config{
-		Level:  "info",
-		Stdout: true,
-	})
-	cfg := api.Config{
-		Debug:   false,
-		Timeout: 0,
-		Retry:   0,
-		Cookie: cookie.Config{
-			Options:  nil,
-			Filepath: "../testdata/cookie.json",
-			Interval: 0,
-		},
+	var err error
+	cli, err = api.NewWithOptions(
+		api.WithCookieFile("../testdata/cookie.json"),
+		api.WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))),
+	)
+	if err != nil {
+		panic(err)
 	}
-	cli = api.New(&cfg)
 	defer cli.Close(ctx)
 	os.Exit(t.Run())
 }
@@ -0,0 +1,166 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+)
+
+// accountCommands 与账号绑定的命令,指定 --all-accounts 时在每个已登录账号上分别执行。
+// 值为true表示多个账号需要依次执行,比如 scrobble 使用的badger数据库同一目录同时只能打开一次。
+var accountCommands = map[string]bool{
+	"sign":      false,
+	"signin":    false,
+	"partner":   false,
+	"scrobble":  true,
+	"keepalive": false,
+	"yunbei":    false,
+}
+
+// accountResult 单个账号的执行结果
+type accountResult struct {
+	Profile string
+	Cost    time.Duration
+	Err     error
+}
+
+// profiles 返回所有存在登录cookie的账号名称及cookie文件路径
+func (c *Root) profiles() (map[string]string, error) {
+	var profiles = make(map[string]string)
+	if utils.FileExists(c.defaultCookie) {
+		profiles[defaultProfile] = c.defaultCookie
+	}
+	entries, err := os.ReadDir(filepath.Dir(filepath.Dir(profileCookiePath(c.dirs, defaultProfile))))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return profiles, nil
+		}
+		return nil, fmt.Errorf("ReadDir: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !profileRegexp.MatchString(e.Name()) {
+			continue
+		}
+		var path = profileCookiePath(c.dirs, e.Name())
+		if utils.FileExists(path) {
+			profiles[e.Name()] = path
+		}
+	}
+	return profiles, nil
+}
+
+// profileNames 返回所有已登录账号名称,没有账号时返回错误
+func (c *Root) profileNames() ([]string, error) {
+	profiles, err := c.profiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) <= 0 {
+		return nil, fmt.Errorf("no profile found, login first")
+	}
+	var names = make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// withProfile 返回使用指定账号cookie、设备指纹及听歌记录同步进度的Root副本,
+// 命令在副本上执行时创建的客户端拥有独立的cookie及限流器
+func (c *Root) withProfile(name string) *Root {
+	var (
+		root    = *c
+		cfg     = *c.Cfg
+		network = *c.Cfg.Network
+	)
+	root.Opts.Profile = name
+	network.Cookie.Filepath = utils.Ternary(name == defaultProfile, c.defaultCookie, profileCookiePath(c.dirs, name))
+	if network.Device.Filepath != "" {
+		network.Device.Filepath = utils.Ternary(name == defaultProfile, c.defaultDevice, profileDevicePath(c.dirs, name))
+	}
+	cfg.Network = &network
+	if c.Cfg.Scrobbler != nil {
+		var scrobbler = *c.Cfg.Scrobbler
+		scrobbler.Path = utils.Ternary(name == defaultProfile, c.defaultScrobbler, profileScrobblerPath(c.dirs, name))
+		cfg.Scrobbler = &scrobbler
+	}
+	root.Cfg = &cfg
+	return &root
+}
+
+// eachProfile 在所有已登录账号上执行fn,sequential为false时并发执行,结果按账号名称排序
+func (c *Root) eachProfile(ctx context.Context, sequential bool, fn func(ctx context.Context, root *Root) error) ([]accountResult, error) {
+	names, err := c.profileNames()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		results = make([]accountResult, len(names))
+		wg      sync.WaitGroup
+	)
+	for i, name := range names {
+		var run = func() {
+			var start = time.Now()
+			results[i] = accountResult{Profile: name, Err: fn(ctx, c.withProfile(name))}
+			results[i].Cost = time.Since(start)
+		}
+		if sequential {
+			run()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run()
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// reportAccounts 输出每个账号的执行结果,返回所有失败账号的错误
+func reportAccounts(name string, results []accountResult) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			log.Error("[%s@%s] execute err: %s", name, r.Profile, r.Err)
+			errs = append(errs, fmt.Errorf("%s: %w", r.Profile, r.Err))
+			continue
+		}
+		log.Info("[%s@%s] execute success, cost %s", name, r.Profile, r.Cost.Round(time.Millisecond))
+	}
+	log.Info("[%s] %d/%d accounts succeeded", name, len(results)-len(errs), len(results))
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type Api struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewApi(root *Root, l *log.Logger) *Api {
+	c := &Api{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:     "api",
+			Short:   "Tools for maintaining the netease cloud music api",
+			Example: "  ncmctl api probe\n  ncmctl api probe --only SongDetail,Lyric",
+		},
+	}
+	c.addFlags()
+	c.Add(apiProbe(c, l))
+	return c
+}
+
+func (c *Api) addFlags() {}
+
+func (c *Api) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Api) Command() *cobra.Command {
+	return c.cmd
+}
@@ -0,0 +1,237 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/probe"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+// 探测使用的固定资源
+const (
+	probeSongId     = 186016   // 周杰伦 - 晴天
+	probeArtistId   = 6452     // 周杰伦
+	probePlaylistId = 19723756 // 云音乐飙升榜
+)
+
+// apiProbeCase 探测的只读接口
+type apiProbeCase struct {
+	name      string
+	needLogin bool
+	call      func(ctx context.Context, request *weapi.Api) error
+}
+
+var apiProbeCases = []apiProbeCase{
+	{name: "GetUserInfo", needLogin: true, call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+		return err
+	}},
+	{name: "UserLevel", needLogin: true, call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.UserLevel(ctx, &weapi.UserLevelReq{})
+		return err
+	}},
+	{name: "VipInfo", needLogin: true, call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.VipInfo(ctx, &weapi.VipInfoReq{})
+		return err
+	}},
+	{name: "RecommendSongs", needLogin: true, call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.RecommendSongs(ctx, &weapi.RecommendSongsReq{})
+		return err
+	}},
+	{name: "SongDetail", call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.SongDetail(ctx, &weapi.SongDetailReq{C: []weapi.SongDetailReqList{{Id: fmt.Sprintf("%d", probeSongId)}}})
+		return err
+	}},
+	{name: "SongMusicQuality", call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.SongMusicQuality(ctx, &weapi.SongMusicQualityReq{SongId: fmt.Sprintf("%d", probeSongId)})
+		return err
+	}},
+	{name: "Lyric", call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.Lyric(ctx, &weapi.LyricReq{Id: probeSongId})
+		return err
+	}},
+	{name: "CloudSearch", call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.CloudSearch(ctx, &weapi.CloudSearchReq{S: "晴天", Type: weapi.SearchTypeSong, Limit: 5})
+		return err
+	}},
+	{name: "PlaylistDetail", call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.PlaylistDetail(ctx, &weapi.PlaylistDetailReq{Id: fmt.Sprintf("%d", probePlaylistId)})
+		return err
+	}},
+	{name: "ArtistSongs", call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.ArtistSongs(ctx, &weapi.ArtistSongsReq{Id: probeArtistId, PrivateCloud: "true", WorkType: 1, Order: "hot", Limit: 5})
+		return err
+	}},
+	{name: "CommentHot", call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.CommentHot(ctx, &weapi.CommentHotReq{ThreadId: fmt.Sprintf("%s%d", weapi.CommentThreadSong, probeSongId), Rid: fmt.Sprintf("%d", probeSongId), Limit: 5})
+		return err
+	}},
+	{name: "TopList", call: func(ctx context.Context, request *weapi.Api) error {
+		_, err := request.TopList(ctx, &weapi.TopListReq{})
+		return err
+	}},
+}
+
+type apiProbeCmd struct {
+	root *Api
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	only    []string // 只探测指定的接口
+	verbose bool     // 输出所有变化的字段
+}
+
+func apiProbe(root *Api, l *log.Logger) *cobra.Command {
+	c := &apiProbeCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "probe",
+		Short: "Call a curated set of read-only endpoints and report response shape changes",
+		Long: "Call a curated set of read-only endpoints against the live service and compare the raw responses\n" +
+			"with the response structs defined in this library. Fields not defined in the structs and fields\n" +
+			"whose json type differs from the definition are reported. Endpoints that need login are skipped\n" +
+			"when not logged in.",
+		Example: "  ncmctl api probe\n" +
+			"  ncmctl api probe --only SongDetail,Lyric -v",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *apiProbeCmd) addFlags() {
+	c.cmd.Flags().StringSliceVar(&c.only, "only", nil, "only probe the given endpoints")
+	c.cmd.Flags().BoolVarP(&c.verbose, "verbose", "v", false, "print all changed fields, otherwise only the first 10 of each endpoint")
+}
+
+func (c *apiProbeCmd) validate() error {
+	for _, name := range c.only {
+		if !slices.ContainsFunc(apiProbeCases, func(p apiProbeCase) bool { return p.name == name }) {
+			return fmt.Errorf("unknown endpoint: %s", name)
+		}
+	}
+	return nil
+}
+
+func (c *apiProbeCmd) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var (
+		request  = weapi.New(cli)
+		recorder = probe.NewRecorder()
+		login    = !request.NeedLogin(ctx)
+	)
+	cli.Use(recorder.Middleware())
+
+	var (
+		w       = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		details []string
+		failed  int
+	)
+	_, _ = fmt.Fprintln(w, "ENDPOINT\tPATH\tSTATUS\tUNKNOWN\tMISMATCH")
+	for _, p := range apiProbeCases {
+		if len(c.only) > 0 && !slices.Contains(c.only, p.name) {
+			continue
+		}
+		if p.needLogin && !login {
+			_, _ = fmt.Fprintf(w, "%s\t-\tskipped(need login)\t-\t-\n", p.name)
+			continue
+		}
+		_ = recorder.Take()
+		if err := p.call(ctx, request); err != nil {
+			failed++
+			_, _ = fmt.Fprintf(w, "%s\t-\terror\t-\t-\n", p.name)
+			details = append(details, fmt.Sprintf("[%s] %s", p.name, err))
+			continue
+		}
+		for _, r := range recorder.Take() {
+			var path = r.Url
+			if u, err := url.Parse(r.Url); err == nil {
+				path = u.Path
+			}
+			report, err := probe.Check(r.Body, r.Resp)
+			if err != nil {
+				failed++
+				_, _ = fmt.Fprintf(w, "%s\t%s\terror\t-\t-\n", p.name, path)
+				details = append(details, fmt.Sprintf("[%s] %s", p.name, err))
+				continue
+			}
+			var status = "ok"
+			if report.Changed() {
+				failed++
+				status = "changed"
+				for _, f := range c.limit(report.Unknown) {
+					details = append(details, fmt.Sprintf("[%s] unknown: %s", p.name, f))
+				}
+				for _, f := range c.limit(report.Mismatch) {
+					details = append(details, fmt.Sprintf("[%s] mismatch: %s", p.name, f))
+				}
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", p.name, path, status, len(report.Unknown), len(report.Mismatch))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("Flush: %w", err)
+	}
+	if len(details) > 0 {
+		c.cmd.Println()
+		for _, d := range details {
+			c.cmd.Println(d)
+		}
+	}
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d endpoints changed or failed", failed)}
+	}
+	return nil
+}
+
+// limit 非verbose模式下每个接口最多输出10个字段
+func (c *apiProbeCmd) limit(list []string) []string {
+	if c.verbose || len(list) <= 10 {
+		return list
+	}
+	return append(list[:10:10], fmt.Sprintf("... %d more, use -v to show all", len(list)-10))
+}
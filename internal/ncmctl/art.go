@@ -0,0 +1,394 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/semaphore"
+)
+
+// artIndexFile 已导出图片的哈希索引,位于输出目录下,重复执行时内容相同的图片只保存一份
+const artIndexFile = ".ncmctl-art.json"
+
+// artExts 支持保存的图片格式扩展名
+var artExts = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+type ArtOpts struct {
+	Output   string // 输出目录
+	Parallel int64  // 并发下载数量
+	MinSize  int    // 图片宽、高均不小于该像素值才保存,为0时不限制
+	MaxSize  int    // 图片宽、高均不大于该像素值才保存,为0时不限制
+	Force    bool   // 覆盖已存在的图片
+}
+
+type Art struct {
+	root *Root
+	cmd  *cobra.Command
+	opts ArtOpts
+	l    *log.Logger
+}
+
+// artwork 单张专辑封面或歌手图片的导出结果
+type artwork struct {
+	Kind   string `json:"kind"` // album: 专辑封面 artist: 歌手图片
+	Id     int64  `json:"id"`
+	Name   string `json:"name"`
+	Artist string `json:"artist,omitempty"` // 专辑所属歌手,用于确定保存目录
+	Url    string `json:"url"`
+	File   string `json:"file,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Hash   string `json:"hash,omitempty"`
+	// Status saved: 已保存 exists: 文件已存在 duplicate: 与已保存的图片内容相同 small: 小于--min-size large: 大于--max-size failed: 失败
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func NewArt(root *Root, l *log.Logger) *Art {
+	c := &Art{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "art <playlist|album|artist>...",
+			Short: "Export album covers and artist photos at original resolution",
+			Long: "Download the cover of every album in the playlists/albums/songs and the photo and album covers of the artists\n" +
+				"at original resolution, organized as <artist>/folder.<ext> and <artist>/<album>/cover.<ext>\n" +
+				"which Plex/Jellyfin pick up as artwork. Images with the same content are saved only once,\n" +
+				"the sha256 of saved images is recorded in " + artIndexFile + " of the output path.",
+			Example: "  ncmctl art 'https://music.163.com/#/playlist?id=19723756'\n" +
+				"  ncmctl art 'https://music.163.com/#/album?id=34608111' -o ./artwork\n" +
+				"  ncmctl art 'https://music.163.com/#/artist?id=6452' --min-size 1000",
+			Args: cobra.MinimumNArgs(1),
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args)
+	}
+	return c
+}
+
+func (c *Art) addFlags() {
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./artwork", "artwork output path")
+	c.cmd.Flags().Int64VarP(&c.opts.Parallel, "parallel", "p", 5, "concurrent download count")
+	c.cmd.Flags().IntVar(&c.opts.MinSize, "min-size", 0, "skip images whose width or height is smaller than this many pixels, 0 means no limit")
+	c.cmd.Flags().IntVar(&c.opts.MaxSize, "max-size", 0, "skip images whose width or height is larger than this many pixels, 0 means no limit")
+	c.cmd.Flags().BoolVar(&c.opts.Force, "force", false, "download again and overwrite existing images")
+}
+
+func (c *Art) validate() error {
+	if c.opts.Parallel < 1 || c.opts.Parallel > 20 {
+		return fmt.Errorf("parallel must be between 1 and 20")
+	}
+	if c.opts.MinSize < 0 || c.opts.MaxSize < 0 {
+		return fmt.Errorf("min-size and max-size must be >= 0")
+	}
+	if c.opts.MaxSize > 0 && c.opts.MinSize > c.opts.MaxSize {
+		return fmt.Errorf("min-size must be <= max-size")
+	}
+	return nil
+}
+
+func (c *Art) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Art) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Art) execute(ctx context.Context, args []string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	list, err := c.collect(ctx, request, args)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.opts.Output, 0755); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	index, err := loadArtIndex(filepath.Join(c.opts.Output, artIndexFile))
+	if err != nil {
+		return fmt.Errorf("loadArtIndex: %w", err)
+	}
+
+	var (
+		sema = semaphore.NewWeighted(c.opts.Parallel)
+		mu   sync.Mutex
+	)
+	for _, a := range list {
+		if err := sema.Acquire(ctx, 1); err != nil {
+			break
+		}
+		go func() {
+			defer sema.Release(1)
+			c.save(ctx, a, index, &mu)
+		}()
+	}
+	if err := sema.Acquire(context.Background(), c.opts.Parallel); err != nil {
+		return fmt.Errorf("wait: %w", err)
+	}
+	if err := index.save(); err != nil {
+		log.Warn("save %s err: %s", artIndexFile, err)
+	}
+
+	var (
+		counts = make(map[string]int)
+		v      = view{Data: list, Header: []string{"KIND", "ID", "NAME", "SIZE", "STATUS", "FILE"}}
+	)
+	for _, a := range list {
+		counts[a.Status]++
+		var size = "-"
+		if a.Width > 0 {
+			size = fmt.Sprintf("%dx%d", a.Width, a.Height)
+		}
+		v.Rows = append(v.Rows, []string{a.Kind, strconv.FormatInt(a.Id, 10), a.Name, size, a.Status, utils.Ternary(a.File != "", a.File, "-")})
+	}
+	if err := render(c.cmd.OutOrStdout(), c.root.outputFormat(outputTable), v); err != nil {
+		return err
+	}
+	c.cmd.PrintErrf("saved %d, already exists %d, duplicate %d, too small %d, too large %d, failed %d\n",
+		counts["saved"], counts["exists"], counts["duplicate"], counts["small"], counts["large"], counts["failed"])
+	if failed := counts["failed"]; failed > 0 {
+		if failed == len(list) {
+			return fmt.Errorf("all %d images failed", failed)
+		}
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d images failed", failed)}
+	}
+	return nil
+}
+
+// collect 解析输入的歌单、专辑、歌手及歌曲,返回去重后的专辑封面及歌手图片
+func (c *Art) collect(ctx context.Context, request *weapi.Api, args []string) ([]*artwork, error) {
+	var (
+		list []*artwork
+		set  = make(map[string]struct{})
+		add  = func(a *artwork) {
+			var key = a.Kind + ":" + strconv.FormatInt(a.Id, 10)
+			if _, ok := set[key]; ok || a.Url == "" {
+				return
+			}
+			set[key] = struct{}{}
+			list = append(list, a)
+		}
+	)
+	for _, arg := range args {
+		kind, id, err := Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("Parse: %w", err)
+		}
+		switch kind {
+		case "album":
+			resp, err := request.Album(ctx, &weapi.AlbumReq{Id: strconv.FormatInt(id, 10)})
+			if err != nil {
+				return nil, fmt.Errorf("Album(%v): %w", id, err)
+			}
+			var ar = resp.Album.Artist
+			add(&artwork{Kind: "artist", Id: ar.Id, Name: ar.Name, Url: ar.PicUrl})
+			add(&artwork{Kind: "album", Id: resp.Album.Id, Name: resp.Album.Name, Artist: ar.Name, Url: resp.Album.PicUrl})
+		case "artist":
+			for offset := int64(0); ; offset += 100 {
+				resp, err := request.ArtistAlbums(ctx, &weapi.ArtistAlbumsReq{Id: id, Limit: 100, Offset: offset, Total: true})
+				if err != nil {
+					return nil, fmt.Errorf("ArtistAlbums(%v): %w", id, err)
+				}
+				var ar = resp.Artist
+				add(&artwork{Kind: "artist", Id: ar.Id, Name: ar.Name, Url: ar.PicUrl})
+				for _, al := range resp.HotAlbums {
+					add(&artwork{Kind: "album", Id: al.Id, Name: al.Name, Artist: ar.Name, Url: al.PicUrl})
+				}
+				if !resp.More || len(resp.HotAlbums) <= 0 {
+					break
+				}
+			}
+		case "playlist", "song":
+			songs, err := NewDownload(c.root, c.l).inputParse(ctx, []string{arg}, request)
+			if err != nil {
+				return nil, fmt.Errorf("inputParse: %w", err)
+			}
+			for _, s := range songs {
+				var artist string
+				if len(s.Artist) > 0 {
+					artist = s.Artist[0].Name
+				}
+				add(&artwork{Kind: "album", Id: s.Album.Id, Name: s.Album.Name, Artist: artist, Url: s.Album.PicUrl})
+			}
+		default:
+			return nil, fmt.Errorf("%s is not supported, input playlist, album, artist or song", kind)
+		}
+	}
+	return list, nil
+}
+
+// save 下载并保存单张图片,结果写入a
+func (c *Art) save(ctx context.Context, a *artwork, index *artIndex, mu *sync.Mutex) {
+	var base = a.filename()
+	if !c.opts.Force {
+		for _, ext := range artExts {
+			if file := base + "." + ext; utils.FileExists(filepath.Join(c.opts.Output, file)) {
+				a.File, a.Status = file, "exists"
+				return
+			}
+		}
+	}
+
+	data, err := fetchCover(ctx, originalImage(a.Url))
+	if err != nil {
+		a.Status, a.Reason = "failed", err.Error()
+		log.Warn("[art] fetch %s %d(%s) err: %s", a.Kind, a.Id, a.Url, err)
+		return
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		a.Status, a.Reason = "failed", fmt.Sprintf("DecodeConfig: %s", err)
+		return
+	}
+	a.Width, a.Height = cfg.Width, cfg.Height
+	switch {
+	case c.opts.MinSize > 0 && min(cfg.Width, cfg.Height) < c.opts.MinSize:
+		a.Status = "small"
+		return
+	case c.opts.MaxSize > 0 && max(cfg.Width, cfg.Height) > c.opts.MaxSize:
+		a.Status = "large"
+		return
+	}
+
+	var sum = sha256.Sum256(data)
+	a.Hash = hex.EncodeToString(sum[:])
+	ext, ok := artExts[http.DetectContentType(data)]
+	if !ok {
+		ext = strings.TrimPrefix(path.Ext(originalImage(a.Url)), ".")
+	}
+	a.File = base + "." + ext
+
+	// 同一张图片可能被多个专辑使用,比如单曲与所属专辑,只保存第一次出现的图片
+	mu.Lock()
+	defer mu.Unlock()
+	if file, ok := index.Files[a.Hash]; ok && file != a.File && utils.FileExists(filepath.Join(c.opts.Output, file)) {
+		a.File, a.Status = file, "duplicate"
+		return
+	}
+	var dest = filepath.Join(c.opts.Output, a.File)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		a.Status, a.Reason = "failed", err.Error()
+		return
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		a.Status, a.Reason = "failed", err.Error()
+		return
+	}
+	index.Files[a.Hash] = a.File
+	a.Status = "saved"
+}
+
+// filename 返回相对输出目录且不含扩展名的保存路径,歌手图片为 <artist>/folder,专辑封面为 <artist>/<album>/cover
+func (a *artwork) filename() string {
+	if a.Kind == "artist" {
+		return filepath.Join(artDirname(a.Name, a.Id), "folder")
+	}
+	return filepath.Join(artDirname(a.Artist, 0), artDirname(a.Name, a.Id), "cover")
+}
+
+// artDirname 返回可用作目录名的名称,名称为空时使用id
+func artDirname(name string, id int64) string {
+	name = strings.Trim(utils.Filename(name, "_"), ". ")
+	if name == "" {
+		return utils.Ternary(id > 0, strconv.FormatInt(id, 10), "Unknown")
+	}
+	return name
+}
+
+// originalImage 去掉图片地址中的缩放参数(如 ?param=300y300)获取原始分辨率图片
+func originalImage(url string) string {
+	if i := strings.IndexByte(url, '?'); i >= 0 {
+		return url[:i]
+	}
+	return url
+}
+
+// artIndex 已保存图片的sha256及文件路径
+type artIndex struct {
+	path  string
+	Files map[string]string `json:"files"` // sha256 -> 相对输出目录的文件路径
+}
+
+func loadArtIndex(path string) (*artIndex, error) {
+	var index = &artIndex{path: path, Files: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if index.Files == nil {
+		index.Files = make(map[string]string)
+	}
+	return index, nil
+}
+
+func (i *artIndex) save() error {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(i.path, data, 0644)
+}
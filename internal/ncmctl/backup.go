@@ -0,0 +1,383 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// backupVersion 快照格式版本
+const backupVersion = 1
+
+type BackupOpts struct {
+	Output string // 快照保存目录
+	Format string // 快照格式 json、ndjson
+}
+
+type Backup struct {
+	root *Root
+	cmd  *cobra.Command
+	opts BackupOpts
+	l    *log.Logger
+}
+
+// backupSnapshot 账号曲库快照
+type backupSnapshot struct {
+	Version   int              `json:"version"`
+	Time      time.Time        `json:"time"`
+	UserId    int64            `json:"userId"`
+	Nickname  string           `json:"nickname"`
+	Playlists []backupPlaylist `json:"playlists"` // 创建及收藏的歌单,不包含"我喜欢的音乐"
+	Liked     []backupTrack    `json:"liked"`     // "我喜欢的音乐"中的歌曲
+	Artists   []backupArtist   `json:"artists"`   // 关注的歌手
+	Cloud     []backupCloud    `json:"cloud"`     // 云盘歌曲
+}
+
+type backupPlaylist struct {
+	Id          int64         `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Privacy     int64         `json:"privacy"`    // 10: 隐私歌单
+	Subscribed  bool          `json:"subscribed"` // true: 收藏的歌单 false: 创建的歌单
+	Creator     string        `json:"creator"`
+	Tracks      []backupTrack `json:"tracks"`
+}
+
+type backupTrack struct {
+	Id       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Artists  []string `json:"artists"`
+	Album    string   `json:"album"`
+	AlbumId  int64    `json:"albumId"`
+	Duration int64    `json:"duration"` // 毫秒
+}
+
+type backupArtist struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type backupCloud struct {
+	Id       int64  `json:"id"`     // 云盘歌曲id
+	SongId   int64  `json:"songId"` // 匹配的歌曲id
+	Name     string `json:"name"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	FileName string `json:"fileName"`
+	FileSize int64  `json:"fileSize"`
+	AddTime  int64  `json:"addTime"`
+}
+
+// backupLine ndjson格式每行的记录,Type为 meta、playlist、liked、artist、cloud
+type backupLine struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+func NewBackup(root *Root, l *log.Logger) *Backup {
+	c := &Backup{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "backup",
+			Short: "[need login] Snapshot playlists, liked songs, followed artists and cloud songs into a json file",
+			Example: "  ncmctl backup\n" +
+				"  ncmctl backup -o ./backup --format ndjson\n" +
+				"  ncmctl backup diff ./backup/backup-1-20240101-000000.json ./backup/backup-1-20240201-000000.json",
+			Args: cobra.NoArgs,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	c.Add(backupDiff(c, l))
+	return c
+}
+
+func (c *Backup) addFlags() {
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./backup", "snapshot directory")
+	c.cmd.Flags().StringVar(&c.opts.Format, "format", "json", "snapshot format, support: json、ndjson")
+}
+
+func (c *Backup) validate() error {
+	if c.opts.Format != "json" && c.opts.Format != "ndjson" {
+		return fmt.Errorf("format is not support: %s", c.opts.Format)
+	}
+	return nil
+}
+
+func (c *Backup) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Backup) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Backup) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	output, err := utils.ExpandTilde(c.opts.Output)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if err := utils.MkdirIfNotExist(output, 0755); err != nil {
+		return fmt.Errorf("MkdirIfNotExist: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var (
+		uid  = user.Account.Id
+		snap = backupSnapshot{Version: backupVersion, Time: time.Now(), UserId: uid}
+	)
+	if user.Profile != nil {
+		snap.Nickname = user.Profile.Nickname
+	}
+
+	playlists, err := userPlaylists(ctx, request, uid)
+	if err != nil {
+		return err
+	}
+	c.root.cachePlaylists(playlists)
+	for i, v := range playlists {
+		c.cmd.Printf("[%d/%d] playlist %s\n", i+1, len(playlists), v.Name)
+		_, songs, err := playlistSongs(ctx, c.root, request, v.Id)
+		if err != nil {
+			return err
+		}
+		var tracks = backupTracks(songs)
+		// "我喜欢的音乐"单独保存,恢复时通过喜欢接口还原
+		if v.SpecialType == 5 && v.UserId == uid {
+			snap.Liked = tracks
+			continue
+		}
+		var p = backupPlaylist{
+			Id:         v.Id,
+			Name:       v.Name,
+			Tags:       v.Tags,
+			Privacy:    v.Privacy,
+			Subscribed: v.UserId != uid,
+			Creator:    v.Creator.Nickname,
+			Tracks:     tracks,
+		}
+		if v.Description != nil {
+			p.Description = *v.Description
+		}
+		snap.Playlists = append(snap.Playlists, p)
+	}
+
+	artists, err := artistSublist(ctx, request)
+	if err != nil {
+		return err
+	}
+	for _, ar := range artists {
+		snap.Artists = append(snap.Artists, backupArtist{Id: ar.Id, Name: ar.Name})
+	}
+
+	cloud, err := cloudSongs(ctx, request, nil)
+	if err != nil {
+		return fmt.Errorf("cloudSongs: %w", err)
+	}
+	for _, v := range cloud {
+		snap.Cloud = append(snap.Cloud, backupCloud{
+			Id:       v.SongId,
+			SongId:   v.SimpleSong.Id,
+			Name:     v.SongName,
+			Artist:   v.Artist,
+			Album:    v.Album,
+			FileName: v.FileName,
+			FileSize: v.FileSize,
+			AddTime:  v.AddTime,
+		})
+	}
+
+	var file = filepath.Join(output, fmt.Sprintf("backup-%d-%s.%s", uid, snap.Time.Format("20060102-150405"), c.opts.Format))
+	if err := saveSnapshot(file, &snap); err != nil {
+		return err
+	}
+	c.cmd.Printf("backup %d playlists, %d liked songs, %d artists, %d cloud songs to %s\n",
+		len(snap.Playlists), len(snap.Liked), len(snap.Artists), len(snap.Cloud), file)
+	return nil
+}
+
+func backupTracks(songs []Music) []backupTrack {
+	var list = make([]backupTrack, 0, len(songs))
+	for _, s := range songs {
+		var artists = make([]string, 0, len(s.Artist))
+		for _, ar := range s.Artist {
+			artists = append(artists, ar.Name)
+		}
+		list = append(list, backupTrack{
+			Id:       s.Id,
+			Name:     s.Name,
+			Artists:  artists,
+			Album:    s.Album.Name,
+			AlbumId:  s.AlbumId,
+			Duration: s.Time,
+		})
+	}
+	return list
+}
+
+// saveSnapshot 根据文件扩展名保存为json或ndjson格式
+func saveSnapshot(path string, snap *backupSnapshot) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Create: %w", err)
+	}
+	defer f.Close()
+
+	var w = bufio.NewWriter(f)
+	if strings.ToLower(filepath.Ext(path)) != ".ndjson" {
+		var encoder = json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(snap); err != nil {
+			return fmt.Errorf("Encode: %w", err)
+		}
+		return w.Flush()
+	}
+
+	var (
+		encoder = json.NewEncoder(w)
+		write   = func(kind string, v any) error {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			return encoder.Encode(backupLine{Type: kind, Data: data})
+		}
+		meta = backupSnapshot{Version: snap.Version, Time: snap.Time, UserId: snap.UserId, Nickname: snap.Nickname}
+	)
+	if err := write("meta", meta); err != nil {
+		return fmt.Errorf("Encode: %w", err)
+	}
+	for _, v := range snap.Playlists {
+		if err := write("playlist", v); err != nil {
+			return fmt.Errorf("Encode: %w", err)
+		}
+	}
+	for _, v := range snap.Liked {
+		if err := write("liked", v); err != nil {
+			return fmt.Errorf("Encode: %w", err)
+		}
+	}
+	for _, v := range snap.Artists {
+		if err := write("artist", v); err != nil {
+			return fmt.Errorf("Encode: %w", err)
+		}
+	}
+	for _, v := range snap.Cloud {
+		if err := write("cloud", v); err != nil {
+			return fmt.Errorf("Encode: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+// loadSnapshot 根据文件扩展名读取json或ndjson格式快照
+func loadSnapshot(path string) (*backupSnapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Open: %w", err)
+	}
+	defer f.Close()
+
+	var snap backupSnapshot
+	if strings.ToLower(filepath.Ext(path)) != ".ndjson" {
+		if err := json.NewDecoder(f).Decode(&snap); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+		return &snap, nil
+	}
+
+	var decoder = json.NewDecoder(f)
+	for decoder.More() {
+		var line backupLine
+		if err := decoder.Decode(&line); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+		var err error
+		switch line.Type {
+		case "meta":
+			err = json.Unmarshal(line.Data, &snap)
+		case "playlist":
+			var v backupPlaylist
+			err = json.Unmarshal(line.Data, &v)
+			snap.Playlists = append(snap.Playlists, v)
+		case "liked":
+			var v backupTrack
+			err = json.Unmarshal(line.Data, &v)
+			snap.Liked = append(snap.Liked, v)
+		case "artist":
+			var v backupArtist
+			err = json.Unmarshal(line.Data, &v)
+			snap.Artists = append(snap.Artists, v)
+		case "cloud":
+			var v backupCloud
+			err = json.Unmarshal(line.Data, &v)
+			snap.Cloud = append(snap.Cloud, v)
+		default:
+			log.Warn("[backup] unknown record type: %s", line.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode %s %s: %w", path, line.Type, err)
+		}
+	}
+	return &snap, nil
+}
+
+func (t backupTrack) String() string {
+	return fmt.Sprintf("%s - %s(%d)", strings.Join(t.Artists, ","), t.Name, t.Id)
+}
@@ -0,0 +1,101 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/plan"
+
+	"github.com/spf13/cobra"
+)
+
+type backupDiffCmd struct {
+	root *Backup
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func backupDiff(root *Backup, l *log.Logger) *cobra.Command {
+	c := &backupDiffCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "diff <old> <new>",
+		Short:   "Compare two backup snapshots",
+		Example: "  ncmctl backup diff ./backup/backup-1-20240101-000000.json ./backup/backup-1-20240201-000000.ndjson",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(args[0], args[1])
+		},
+	}
+	return c.cmd
+}
+
+func (c *backupDiffCmd) execute(oldPath, newPath string) error {
+	before, err := loadSnapshot(oldPath)
+	if err != nil {
+		return err
+	}
+	after, err := loadSnapshot(newPath)
+	if err != nil {
+		return err
+	}
+	if before.UserId != after.UserId {
+		c.cmd.Printf("warning: snapshots belong to different users %d and %d\n", before.UserId, after.UserId)
+	}
+
+	var p = plan.Diff(backupItems(before), backupItems(after))
+	c.cmd.Printf("--- %s (%s)\n+++ %s (%s)\n", oldPath, before.Time.Format("2006-01-02 15:04:05"), newPath, after.Time.Format("2006-01-02 15:04:05"))
+	return p.Write(os.Stdout)
+}
+
+// backupItems 将快照展开为 名称->大小 集合,歌曲名称以所属分类为前缀便于按分类查看差异
+func backupItems(snap *backupSnapshot) map[string]int64 {
+	var items = make(map[string]int64)
+	for _, p := range snap.Playlists {
+		var kind = "playlist"
+		if p.Subscribed {
+			kind = "subscribed"
+		}
+		var name = fmt.Sprintf("%s/%s(%d)", kind, p.Name, p.Id)
+		items[name] = 0
+		for _, t := range p.Tracks {
+			items[fmt.Sprintf("%s/%s", name, t)] = 0
+		}
+	}
+	for _, t := range snap.Liked {
+		items[fmt.Sprintf("liked/%s", t)] = 0
+	}
+	for _, ar := range snap.Artists {
+		items[fmt.Sprintf("artist/%s(%d)", ar.Name, ar.Id)] = 0
+	}
+	for _, v := range snap.Cloud {
+		items[fmt.Sprintf("cloud/%s(%d)", v.FileName, v.Id)] = v.FileSize
+	}
+	return items
+}
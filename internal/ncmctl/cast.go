@@ -0,0 +1,527 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/resolver"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/dlna"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/ncm"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+type CastOpts struct {
+	Device  string        // 设备名称或地址,为空时使用搜索到的唯一设备
+	List    bool          // 只列出搜索到的设备
+	Timeout time.Duration // 设备搜索时长
+	Level   string        // 播放音质
+	Relay   bool          // 通过本机转发音频流
+	Port    int           // 本机转发服务端口,0为随机端口
+}
+
+type Cast struct {
+	root *Root
+	cmd  *cobra.Command
+	opts CastOpts
+	l    *log.Logger
+
+	cli      *api.Client
+	resolver *resolver.Resolver
+	items    []castItem
+	mu       sync.Mutex
+	decoded  map[int][]byte // 正在投放的ncm文件解密后的内容
+}
+
+// castItem 投放的歌曲,File不为空时为本地文件
+type castItem struct {
+	Music
+	File        string
+	ContentType string
+}
+
+func NewCast(root *Root, l *log.Logger) *Cast {
+	c := &Cast{
+		root:    root,
+		l:       l,
+		decoded: make(map[int][]byte),
+		cmd: &cobra.Command{
+			Use:   "cast",
+			Short: "[need login] Cast songs to DLNA renderers such as smart speakers or TVs in the LAN",
+			Long: "Cast songs to DLNA renderers such as smart speakers or TVs in the LAN.\n" +
+				"Renderers are discovered by SSDP, songs are played one by one and the renderer is stopped when pressing ctrl+c.\n" +
+				"Local files(including .ncm) are always served by a local relay server, use --relay to relay online songs too\n" +
+				"when the renderer can not access the NetEase cdn directly.",
+			Example: "  ncmctl cast --list\n" +
+				"  ncmctl cast --device 'Living Room' 2161154646\n" +
+				"  ncmctl cast --device 192.168.1.10 --relay 'https://music.163.com/#/playlist?id=3136952023'\n" +
+				"  ncmctl cast ./download/music.ncm",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args)
+	}
+	return c
+}
+
+func (c *Cast) addFlags() {
+	c.cmd.Flags().StringVarP(&c.opts.Device, "device", "d", "", "renderer name(case-insensitive substring) or address. can be empty when only one renderer is found")
+	c.cmd.Flags().BoolVar(&c.opts.List, "list", false, "list the renderers in the LAN")
+	c.cmd.Flags().DurationVar(&c.opts.Timeout, "timeout", 3*time.Second, "renderer discovery duration, use the global --request-timeout for network requests")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelExhigh), "song quality level. support: standard,higher,exhigh,lossless,hires")
+	c.cmd.Flags().BoolVar(&c.opts.Relay, "relay", false, "relay online songs through the local server")
+	c.cmd.Flags().IntVar(&c.opts.Port, "port", 0, "local relay server port, 0 means random port")
+}
+
+func (c *Cast) validate(args []string) error {
+	if c.opts.List {
+		return nil
+	}
+	if len(args) <= 0 {
+		return fmt.Errorf("songs are required")
+	}
+	if c.opts.Timeout <= 0 {
+		return fmt.Errorf("timeout must be greater than 0")
+	}
+	if _, ok := types.LevelString[types.Level(c.opts.Level)]; !ok {
+		return fmt.Errorf("[%s] quality is not support", c.opts.Level)
+	}
+	if c.opts.Port < 0 || c.opts.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", c.opts.Port)
+	}
+	return nil
+}
+
+func (c *Cast) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Cast) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Cast) execute(ctx context.Context, args []string) error {
+	if err := c.validate(args); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	devices, err := dlna.Discover(ctx, c.opts.Timeout)
+	if err != nil {
+		return fmt.Errorf("Discover: %w", err)
+	}
+	if c.opts.List {
+		var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tMODEL\tADDRESS")
+		for _, d := range devices {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", d.Name, d.Model, d.Host())
+		}
+		return w.Flush()
+	}
+	device, err := c.choose(devices)
+	if err != nil {
+		return err
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	c.cli = cli
+	var request = weapi.New(cli)
+	c.resolver = resolver.New(request, nil)
+
+	if err := c.parse(ctx, args, request); err != nil {
+		return err
+	}
+	if len(c.items) <= 0 {
+		return fmt.Errorf("no songs to cast")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 本地文件或指定转发时启动转发服务,渲染器通过局域网地址访问
+	var relay string
+	if c.opts.Relay || c.hasLocal() {
+		ip, err := localIP(device.Host())
+		if err != nil {
+			return fmt.Errorf("localIP: %w", err)
+		}
+		ln, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(c.opts.Port)))
+		if err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+		var srv = &http.Server{Handler: c.handler(), ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("[cast] serve: %s", err)
+			}
+		}()
+		defer srv.Close()
+		relay = fmt.Sprintf("http://%s", net.JoinHostPort(ip, strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)))
+		log.Info("[cast] relay server listening on %s", relay)
+	}
+
+	for i, item := range c.items {
+		if ctx.Err() != nil {
+			break
+		}
+		uri, meta, err := c.prepare(ctx, i, relay)
+		if err != nil {
+			log.Warn("[cast] %s skip: %s", item.Name, err)
+			continue
+		}
+		if err := device.SetURI(ctx, uri, meta); err != nil {
+			return fmt.Errorf("SetURI: %w", err)
+		}
+		if err := device.Play(ctx); err != nil {
+			return fmt.Errorf("Play: %w", err)
+		}
+		c.cmd.Printf("[%d/%d] %s => %s\n", i+1, len(c.items), meta.Title, device)
+
+		// 单首在线歌曲且未转发时渲染器可独立播放,无需等待
+		if len(c.items) == 1 && relay == "" {
+			return nil
+		}
+		err = c.wait(ctx, device)
+		c.release(i)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		// 使用新的context,原context已取消
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := device.Stop(ctx); err != nil {
+			log.Warn("[cast] Stop: %s", err)
+		}
+	}
+	return nil
+}
+
+// choose 根据名称或地址选择设备
+func (c *Cast) choose(devices []*dlna.Device) (*dlna.Device, error) {
+	if len(devices) <= 0 {
+		return nil, fmt.Errorf("no renderer found, make sure the renderer is in the same LAN or increase --timeout")
+	}
+	if c.opts.Device == "" {
+		if len(devices) == 1 {
+			return devices[0], nil
+		}
+		return nil, fmt.Errorf("found %d renderers, please specify one by --device. see --list", len(devices))
+	}
+
+	var (
+		keyword = strings.ToLower(c.opts.Device)
+		matched []*dlna.Device
+	)
+	for _, d := range devices {
+		host, _, _ := net.SplitHostPort(d.Host())
+		if c.opts.Device == d.Host() || c.opts.Device == host {
+			return d, nil
+		}
+		if strings.Contains(strings.ToLower(d.Name), keyword) {
+			matched = append(matched, d)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("renderer %s not found. see --list", c.opts.Device)
+	case 1:
+		return matched[0], nil
+	default:
+		var names = make([]string, 0, len(matched))
+		for _, d := range matched {
+			names = append(names, d.String())
+		}
+		return nil, fmt.Errorf("renderer %s is ambiguous: %s", c.opts.Device, strings.Join(names, ", "))
+	}
+}
+
+// parse 解析输入参数,已存在的文件作为本地文件投放,其余按歌曲id或链接解析
+func (c *Cast) parse(ctx context.Context, args []string, request *weapi.Api) error {
+	var online []string
+	for _, arg := range args {
+		if !utils.FileExists(arg) {
+			online = append(online, arg)
+			continue
+		}
+		item, err := localItem(arg)
+		if err != nil {
+			return err
+		}
+		c.items = append(c.items, item)
+	}
+	if len(online) <= 0 {
+		return nil
+	}
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	songs, err := NewDownload(c.root, c.l).inputParse(ctx, online, request)
+	if err != nil {
+		return fmt.Errorf("inputParse: %w", err)
+	}
+	for _, song := range songs {
+		c.items = append(c.items, castItem{Music: song})
+	}
+	return nil
+}
+
+// localItem 读取本地文件信息,ncm文件从元数据中获取歌曲信息
+func localItem(filename string) (castItem, error) {
+	var item = castItem{
+		Music: Music{Name: strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))},
+		File:  filename,
+	}
+	if !strings.EqualFold(filepath.Ext(filename), ".ncm") {
+		return item, nil
+	}
+
+	file, err := ncm.Open(filename)
+	if err != nil {
+		return item, fmt.Errorf("open %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	var music *ncm.MetadataMusic
+	if meta := file.Metadata(); meta.GetType() == ncm.MetadataTypeDJ {
+		music = &meta.GetDJ().MainMusic
+	} else {
+		music = meta.GetMusic()
+	}
+	if music == nil {
+		return item, nil
+	}
+	item.Id, item.Time = music.Id, music.Duration
+	if music.Name != "" {
+		item.Name = music.Name
+	}
+	for _, a := range music.Artists {
+		item.Artist = append(item.Artist, types.Artist{Id: a.Id, Name: a.Name})
+	}
+	item.Album = types.Album{Id: music.AlbumId, Name: music.Album, PicUrl: music.AlbumPic}
+	return item, nil
+}
+
+func (c *Cast) hasLocal() bool {
+	for _, item := range c.items {
+		if item.File != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// prepare 获取第i首歌曲的播放地址及媒体信息,ncm文件提前解密以便渲染器请求
+func (c *Cast) prepare(ctx context.Context, i int, relay string) (string, *dlna.Metadata, error) {
+	var (
+		item = c.items[i]
+		meta = &dlna.Metadata{
+			Title:       item.Name,
+			Artist:      artistNames(item.Artist),
+			Album:       item.Album.Name,
+			AlbumArtURI: item.Album.PicUrl,
+		}
+		uri = fmt.Sprintf("%s/cast/%d", relay, i)
+	)
+
+	if item.File != "" {
+		var (
+			ext  = strings.TrimPrefix(strings.ToLower(filepath.Ext(item.File)), ".")
+			data []byte
+		)
+		if ext == "ncm" {
+			file, err := ncm.Open(item.File)
+			if err != nil {
+				return "", nil, fmt.Errorf("open: %w", err)
+			}
+			defer file.Close()
+			var buf bytes.Buffer
+			if err := file.DecodeMusic(&buf); err != nil {
+				return "", nil, fmt.Errorf("DecodeMusic: %w", err)
+			}
+			ext = "mp3"
+			if music := file.Metadata().GetMusic(); music != nil && music.Format != "" {
+				ext = music.Format
+			}
+			data = buf.Bytes()
+		}
+		meta.ContentType = audioContentType(ext)
+		c.mu.Lock()
+		c.items[i].ContentType = meta.ContentType
+		if data != nil {
+			c.decoded[i] = data
+		}
+		c.mu.Unlock()
+		return uri, meta, nil
+	}
+
+	stream, err := c.resolver.StreamURL(ctx, item.Id, types.Level(c.opts.Level))
+	if err != nil {
+		return "", nil, err
+	}
+	meta.ContentType = audioContentType(stream.Type)
+	if relay == "" {
+		uri = stream.Url
+	}
+	return uri, meta, nil
+}
+
+// release 释放已播放完成的ncm文件内容
+func (c *Cast) release(i int) {
+	c.mu.Lock()
+	delete(c.decoded, i)
+	c.mu.Unlock()
+}
+
+// wait 轮询渲染器播放状态,播放结束后返回.渲染器长时间未开始播放时视为播放失败跳过
+func (c *Cast) wait(ctx context.Context, device *dlna.Device) error {
+	var (
+		ticker  = time.NewTicker(2 * time.Second)
+		start   = time.Now()
+		played  bool
+		failure int
+	)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		state, err := device.TransportState(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if failure++; failure >= 5 {
+				return fmt.Errorf("TransportState: %w", err)
+			}
+			log.Warn("[cast] TransportState: %s", err)
+			continue
+		}
+		failure = 0
+		switch state {
+		case dlna.StatePlaying, dlna.StatePaused:
+			played = true
+		case dlna.StateStopped, dlna.StateNoMediaPresent:
+			if played || time.Since(start) > 30*time.Second {
+				return nil
+			}
+		}
+	}
+}
+
+func (c *Cast) handler() http.Handler {
+	var mux = http.NewServeMux()
+	mux.HandleFunc("GET /cast/{index}", func(w http.ResponseWriter, r *http.Request) {
+		i, err := strconv.Atoi(r.PathValue("index"))
+		if err != nil || i < 0 || i >= len(c.items) {
+			http.NotFound(w, r)
+			return
+		}
+		c.mu.Lock()
+		var item = c.items[i]
+		data, ok := c.decoded[i]
+		c.mu.Unlock()
+		log.Debug("[cast] %s %s range=%s", r.RemoteAddr, item.Name, r.Header.Get("Range"))
+
+		if item.File == "" {
+			stream, err := c.resolver.StreamURL(r.Context(), item.Id, types.Level(c.opts.Level))
+			if err != nil {
+				log.Warn("[cast] StreamURL(%d): %s", item.Id, err)
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			if err := proxyStream(w, r, c.cli, stream.Url); err != nil {
+				log.Warn("[cast] %s", err)
+				http.Error(w, err.Error(), http.StatusBadGateway)
+			}
+			return
+		}
+
+		if item.ContentType != "" {
+			w.Header().Set("Content-Type", item.ContentType)
+		}
+		if !ok {
+			http.ServeFile(w, r, item.File)
+			return
+		}
+		http.ServeContent(w, r, item.Name, time.Time{}, bytes.NewReader(data))
+	})
+	return mux
+}
+
+// localIP 获取与addr通信时使用的本机局域网地址
+func localIP(addr string) (string, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
+
+// audioContentType 根据文件类型返回音频MIME类型
+func audioContentType(ext string) string {
+	switch strings.ToLower(ext) {
+	case "flac":
+		return "audio/flac"
+	case "m4a", "mp4":
+		return "audio/mp4"
+	case "ogg":
+		return "audio/ogg"
+	case "wav":
+		return "audio/wav"
+	default:
+		return "audio/mpeg"
+	}
+}
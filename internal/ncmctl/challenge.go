@@ -0,0 +1,92 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+)
+
+// challengePrompt 终端交互式处理风控安全验证,并发请求同时触发验证时只提示一次
+type challengePrompt struct {
+	mu       sync.Mutex
+	verified time.Time
+}
+
+func (p *challengePrompt) handle(ctx context.Context, c *api.Challenge) error {
+	var start = time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// 等待期间其他请求已经完成了验证
+	if p.verified.After(start) {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "risk control verification required, code: %d message: %s\n", c.Code, c.Message)
+	if c.Url != "" {
+		fmt.Fprintf(os.Stderr, "please finish the verification in browser: %s\n", c.Url)
+		if err := openBrowser(c.Url); err != nil {
+			fmt.Fprintf(os.Stderr, "open browser failed(%s), please open the url manually\n", err)
+		}
+	} else if len(c.Params) > 0 {
+		params, _ := json.Marshal(c.Params)
+		fmt.Fprintf(os.Stderr, "please finish the verification in official app, verify params: %s\n", params)
+	} else {
+		fmt.Fprintln(os.Stderr, "please finish the verification in official app")
+	}
+	fmt.Fprint(os.Stderr, "press enter to retry after verified, or input q to abort: ")
+
+	line, err := promptLine(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(line, "q") {
+		return fmt.Errorf("verification aborted")
+	}
+	p.verified = time.Now()
+	return nil
+}
+
+// openBrowser 使用系统默认浏览器打开地址
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type Chart struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewChart(root *Root, l *log.Logger) *Chart {
+	c := &Chart{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "chart",
+			Short: "Official toplists such as 飙升榜、新歌榜 and genre charts",
+			Example: "  ncmctl chart list\n" +
+				"  ncmctl chart download 飙升榜 --top 50\n" +
+				"  ncmctl chart download 19723756 3779629 -o ./chart",
+		},
+	}
+	c.addFlags()
+	c.Add(chartList(c, l))
+	c.Add(chartDownload(c, l))
+	return c
+}
+
+func (c *Chart) addFlags() {}
+
+func (c *Chart) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Chart) Command() *cobra.Command {
+	return c.cmd
+}
+
+// charts 获取官方榜单列表
+func charts(ctx context.Context, request *weapi.Api) ([]weapi.TopListRespList, error) {
+	resp, err := request.TopList(ctx, &weapi.TopListReq{})
+	if err != nil {
+		return nil, fmt.Errorf("TopList: %w", err)
+	}
+	if len(resp.List) <= 0 {
+		return nil, fmt.Errorf("TopList is empty")
+	}
+	return resp.List, nil
+}
+
+// findChart 根据榜单id或名称查找榜单,名称优先完全匹配,其次唯一的部分匹配
+func findChart(list []weapi.TopListRespList, source string) (*weapi.TopListRespList, error) {
+	if id, err := strconv.ParseInt(source, 10, 64); err == nil {
+		for i := range list {
+			if list[i].Id == id {
+				return &list[i], nil
+			}
+		}
+		return nil, fmt.Errorf("chart %d not found", id)
+	}
+
+	var matched []int
+	for i := range list {
+		if list[i].Name == source {
+			return &list[i], nil
+		}
+		if strings.Contains(list[i].Name, source) {
+			matched = append(matched, i)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("chart %s not found", source)
+	case 1:
+		return &list[matched[0]], nil
+	default:
+		var names = make([]string, 0, len(matched))
+		for _, i := range matched {
+			names = append(names, list[i].Name)
+		}
+		return nil, fmt.Errorf("chart %s is ambiguous: %s", source, strings.Join(names, "、"))
+	}
+}
@@ -0,0 +1,174 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+type chartDownloadCmd struct {
+	root *Chart
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	output   string // 输出目录,每个榜单保存在 <榜单名称>/<榜单更新日期> 子目录中
+	top      int64  // 只下载榜单前N首歌曲,0表示全部
+	level    string
+	parallel int64
+}
+
+func chartDownload(root *Chart, l *log.Logger) *cobra.Command {
+	c := &chartDownloadCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "download <chartId|name>...",
+		Short: "[need login] Download chart songs into dated folders for archiving",
+		Long: "Download chart songs into <output>/<chart name>/<update date>, files are prefixed with the rank\n" +
+			"eg: 01 - artist - name.flac, so the same chart downloaded on different days is archived separately.",
+		Example: "  ncmctl chart download 飙升榜\n" +
+			"  ncmctl chart download 新歌榜 --top 20 -l HQ\n" +
+			"  ncmctl chart download 19723756 3779629 -o ./chart",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *chartDownloadCmd) addFlags() {
+	c.cmd.Flags().StringVarP(&c.output, "output", "o", "./chart", "output path, songs of each chart are saved in <chart name>/<update date>")
+	c.cmd.Flags().Int64VarP(&c.top, "top", "n", 0, "only download the top n songs of the chart, 0 means all")
+	c.cmd.Flags().StringVarP(&c.level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().Int64VarP(&c.parallel, "parallel", "p", 5, "concurrent download count")
+}
+
+func (c *chartDownloadCmd) execute(ctx context.Context, args []string) error {
+	if c.top < 0 {
+		return fmt.Errorf("validate: top must be >= 0")
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	list, err := charts(ctx, request)
+	if err != nil {
+		return err
+	}
+	var selected = make([]*weapi.TopListRespList, 0, len(args))
+	for _, arg := range args {
+		chart, err := findChart(list, arg)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, chart)
+	}
+
+	var failed int
+	for _, chart := range selected {
+		if err := c.download(ctx, request, chart); err != nil {
+			failed++
+			c.cmd.PrintErrf("%s: %s\n", chart.Name, err)
+			log.Error("[chart] %s download err: %s", chart.Name, err)
+		}
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(selected):
+		return fmt.Errorf("all %d charts failed", failed)
+	default:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d charts failed", failed, len(selected))}
+	}
+}
+
+func (c *chartDownloadCmd) download(ctx context.Context, request *weapi.Api, chart *weapi.TopListRespList) error {
+	resp, err := request.PlaylistDetail(ctx, &weapi.PlaylistDetailReq{Id: strconv.FormatInt(chart.Id, 10)})
+	if err != nil {
+		return fmt.Errorf("PlaylistDetail: %w", err)
+	}
+	var tracks = resp.Playlist.TrackIds
+	if len(tracks) <= 0 {
+		return fmt.Errorf("no songs")
+	}
+	if c.top > 0 && int64(len(tracks)) > c.top {
+		tracks = tracks[:c.top]
+	}
+
+	var (
+		ids  = make([]string, 0, len(tracks))
+		rank = make(map[int64]int, len(tracks))
+		date = time.Now().Format(time.DateOnly)
+	)
+	for i, v := range tracks {
+		ids = append(ids, strconv.FormatInt(v.Id, 10))
+		rank[v.Id] = i + 1
+	}
+	if t := resp.Playlist.UpdateTime; t > 0 {
+		date = time.UnixMilli(t).Format(time.DateOnly)
+	}
+	c.cmd.Printf("%s %s: %d songs\n", chart.Name, date, len(ids))
+
+	d := NewDownload(c.root.root, c.l)
+	d.opts.Output = filepath.Join(c.output, utils.Filename(chart.Name, "_"), date)
+	d.opts.Level = c.level
+	d.opts.Parallel = c.parallel
+	return d.run(ctx, func(ctx context.Context, request *weapi.Api) ([]Music, error) {
+		songs, err := d.inputParse(ctx, ids, request)
+		if err != nil {
+			return nil, fmt.Errorf("inputParse: %w", err)
+		}
+		// 文件名以排名开头并按歌曲数量补齐位数便于排序
+		var width = max(len(strconv.Itoa(len(ids))), 2)
+		for i, s := range songs {
+			if r, ok := rank[s.Id]; ok {
+				songs[i].Filename = fmt.Sprintf("%0*d - %s - %s", width, r, s.ArtistString(), s.NameString())
+			}
+		}
+		return songs, nil
+	})
+}
@@ -0,0 +1,84 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type chartListCmd struct {
+	root *Chart
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func chartList(root *Chart, l *log.Logger) *cobra.Command {
+	c := &chartListCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "list",
+		Short:   "List official charts",
+		Example: "  ncmctl chart list\n  ncmctl chart list --output-format json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	return c.cmd
+}
+
+func (c *chartListCmd) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	list, err := charts(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	var v = view{Data: list, Header: []string{"ID", "NAME", "UPDATE", "UPDATED", "TRACKS"}}
+	for _, l := range list {
+		var updated = "-"
+		if l.UpdateTime > 0 {
+			updated = time.UnixMilli(l.UpdateTime).Format(time.DateOnly)
+		}
+		v.Rows = append(v.Rows, []string{strconv.FormatInt(l.Id, 10), l.Name, l.UpdateFrequency, updated, strconv.Itoa(l.TrackCount)})
+	}
+	return render(c.cmd.OutOrStdout(), c.root.root.outputFormat(outputTable), v)
+}
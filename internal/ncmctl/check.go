@@ -0,0 +1,278 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	checkOk          = "ok"
+	checkUnavailable = "unavailable" // 无版权、已下架或地区限制
+	checkVip         = "vip"         // 需要会员或购买专辑
+	checkCloud       = "cloud"       // 仅云盘可播放
+	checkTrial       = "trial"       // 仅能试听片段
+)
+
+// checkPlayerChunk SongPlayer 单次校验的歌曲数量
+const checkPlayerChunk = 100
+
+type CheckOpts struct {
+	Format   string // 输出格式 table、json
+	Problems bool   // 只输出有问题的歌曲
+}
+
+type Check struct {
+	root *Root
+	cmd  *cobra.Command
+	opts CheckOpts
+	l    *log.Logger
+}
+
+// checkResult 歌曲可用性检查结果
+type checkResult struct {
+	Id      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Artists []string `json:"artists,omitempty"`
+	Status  string   `json:"status"`
+	Reason  string   `json:"reason,omitempty"`
+	Fee     int64    `json:"fee"`
+	Level   string   `json:"level,omitempty"` // 当前账号可播放的最高音质
+}
+
+func NewCheck(root *Root, l *log.Logger) *Check {
+	c := &Check{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "check",
+			Short: "Check songs availability, report unavailable, vip-only or cloud-only tracks",
+			Example: "  ncmctl check 1820944399 1953867286\n" +
+				"  ncmctl check 'https://music.163.com/playlist?id=2710322547' --problems\n" +
+				"  ncmctl check 'https://music.163.com/playlist?id=2710322547' --format json",
+			Args: cobra.MinimumNArgs(1),
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args)
+	}
+	return c
+}
+
+func (c *Check) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Format, "format", "table", "output format, support: table、json、plain. overridden by --output-format")
+	c.cmd.Flags().BoolVar(&c.opts.Problems, "problems", false, "only output songs which are not playable normally")
+}
+
+func (c *Check) validate() error {
+	c.opts.Format = c.root.outputFormat(c.opts.Format)
+	switch c.opts.Format {
+	case outputTable, outputJson, outputPlain:
+	default:
+		return fmt.Errorf("format is not support: %s", c.opts.Format)
+	}
+	return nil
+}
+
+func (c *Check) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Check) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Check) execute(ctx context.Context, args []string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	ids, err := c.inputIds(ctx, request, args)
+	if err != nil {
+		return err
+	}
+	if len(ids) <= 0 {
+		return fmt.Errorf("input resource is empty")
+	}
+
+	results, err := checkSongs(ctx, request, ids)
+	if err != nil {
+		return err
+	}
+
+	var (
+		summary = make(map[string]int)
+		output  = make([]checkResult, 0, len(results))
+	)
+	for _, r := range results {
+		summary[r.Status]++
+		if c.opts.Problems && r.Status == checkOk {
+			continue
+		}
+		output = append(output, r)
+	}
+
+	var v = view{Data: output, Header: []string{"ID", "NAME", "ARTIST", "STATUS", "LEVEL", "REASON"}}
+	for _, r := range output {
+		v.Rows = append(v.Rows, []string{strconv.FormatInt(r.Id, 10), r.Name, strings.Join(r.Artists, ","), r.Status, r.Level, r.Reason})
+	}
+	if err := render(os.Stdout, c.opts.Format, v); err != nil {
+		return err
+	}
+	if c.opts.Format != outputTable {
+		return nil
+	}
+	c.cmd.Printf("total: %d ok: %d unavailable: %d vip: %d cloud: %d trial: %d\n", len(results),
+		summary[checkOk], summary[checkUnavailable], summary[checkVip], summary[checkCloud], summary[checkTrial])
+	return nil
+}
+
+// inputIds 解析歌曲id、歌曲链接或歌单链接为歌曲id列表
+func (c *Check) inputIds(ctx context.Context, request *weapi.Api, args []string) ([]int64, error) {
+	var ids []int64
+	for _, arg := range args {
+		kind, id, err := Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("Parse: %w", err)
+		}
+		switch kind {
+		case "song":
+			ids = append(ids, id)
+		case "playlist":
+			_, songs, err := playlistSongs(ctx, c.root, request, id)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range songs {
+				ids = append(ids, s.Id)
+			}
+		default:
+			return nil, fmt.Errorf("[%s] is not support", kind)
+		}
+	}
+	return ids, nil
+}
+
+// checkSongs 根据歌曲详情中的权限信息以及播放链接判断歌曲可用性,结果按ids顺序返回并去重
+func checkSongs(ctx context.Context, request *weapi.Api, ids []int64) ([]checkResult, error) {
+	detail, err := request.SongDetailBatch(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("SongDetailBatch: %w", err)
+	}
+	var (
+		songs      = make(map[int64]weapi.SongDetailRespSongs, len(detail.Songs))
+		privileges = make(map[int64]types.Privileges, len(detail.Privileges))
+		players    = make(map[int64]weapi.SongPlayerRespData, len(ids))
+	)
+	var found = make([]int64, 0, len(detail.Songs))
+	for _, s := range detail.Songs {
+		songs[s.Id] = s
+		found = append(found, s.Id)
+	}
+	for _, p := range detail.Privileges {
+		privileges[p.Id] = p
+	}
+
+	// 获取播放链接,能获取到完整链接才认为可以正常播放
+	pages, _ := utils.SplitSlice(found, checkPlayerChunk)
+	for _, page := range pages {
+		resp, err := request.SongPlayer(ctx, &weapi.SongPlayerReq{Ids: page, Br: "999000"})
+		if err != nil {
+			return nil, fmt.Errorf("SongPlayer: %w", err)
+		}
+		for _, v := range resp.Data {
+			players[v.Id] = v
+		}
+	}
+
+	var (
+		set     = make(map[int64]struct{}, len(ids))
+		results = make([]checkResult, 0, len(ids))
+	)
+	for _, id := range ids {
+		if _, ok := set[id]; ok {
+			continue
+		}
+		set[id] = struct{}{}
+
+		s, ok := songs[id]
+		if !ok {
+			results = append(results, checkResult{Id: id, Status: checkUnavailable, Reason: "song not found"})
+			continue
+		}
+		var artists = make([]string, 0, len(s.Ar))
+		for _, ar := range s.Ar {
+			artists = append(artists, ar.Name)
+		}
+		var (
+			p      = privileges[id]
+			player = players[id]
+			r      = checkResult{Id: id, Name: s.Name, Artists: artists, Fee: p.Fee, Level: p.PlLevel}
+		)
+		r.Status, r.Reason = checkStatus(p, player)
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// checkStatus 根据权限及播放链接返回歌曲状态及原因
+func checkStatus(p types.Privileges, player weapi.SongPlayerRespData) (string, string) {
+	switch {
+	case p.Toast:
+		return checkUnavailable, "region locked"
+	case p.St < 0:
+		return checkUnavailable, "no copyright or removed"
+	case p.Cs:
+		return checkCloud, "cloud disk only"
+	case player.Code != 200 || player.Url == "":
+		if p.Fee == 1 || p.Fee == 4 {
+			return checkVip, types.Free(p.Fee).String()
+		}
+		return checkUnavailable, fmt.Sprintf("no playable url, code: %d", player.Code)
+	case player.FreeTrialInfo.End > 0:
+		return checkTrial, fmt.Sprintf("trial %ds-%ds, %s", player.FreeTrialInfo.Start, player.FreeTrialInfo.End, types.Free(p.Fee))
+	default:
+		return checkOk, ""
+	}
+}
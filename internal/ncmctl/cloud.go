@@ -39,6 +39,7 @@ import (
 	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/weapi"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/plan"
 	"github.com/chaunsin/netease-cloud-music/pkg/utils"
 
 	"github.com/cheggaaa/pb/v3"
@@ -53,6 +54,7 @@ type CloudOpts struct {
 	Parallel int64  // 并发上传文件数量
 	MinSize  string // 上传文件最低大小限制
 	Regexp   string // 上传过滤正则表达式
+	DryRun   bool   // 仅输出上传计划不实际上传
 }
 
 type Cloud struct {
@@ -69,11 +71,12 @@ func NewCloud(root *Root, l *log.Logger) *Cloud {
 		cmd: &cobra.Command{
 			Use:     "cloud",
 			Short:   "[need login] Used to upload music files to netease cloud disk",
-			Example: "  ncmctl cloud -h\n  ncmctl cloud ./mymusic.mp3\n  ncmctl cloud ./my/music/ (Use directory)",
+			Example: "  ncmctl cloud -h\n  ncmctl cloud ./mymusic.mp3\n  ncmctl cloud ./my/music/ (Use directory)\n  ncmctl cloud match <cloudId> <songId>",
 			Args:    cobra.RangeArgs(0, 1),
 		},
 	}
 	c.addFlags()
+	c.Add(cloudMatch(c, l))
 	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		return c.execute(cmd.Context(), args)
 	}
@@ -85,6 +88,7 @@ func (c *Cloud) addFlags() {
 	c.cmd.PersistentFlags().Int64VarP(&c.opts.Parallel, "parallel", "p", 3, "concurrent upload count")
 	c.cmd.PersistentFlags().StringVarP(&c.opts.MinSize, "minsize", "m", "", "upload music minimum file size limit. supporting unit:b、k/kb/KB、m/mb/MB")
 	c.cmd.PersistentFlags().StringVarP(&c.opts.Regexp, "regexp", "r", "", "upload music file name filter regular expression")
+	c.cmd.Flags().BoolVar(&c.opts.DryRun, "dry-run", false, "only print the upload plan")
 }
 
 func (c *Cloud) Add(command ...*cobra.Command) {
@@ -230,6 +234,17 @@ func (c *Cloud) execute(ctx context.Context, input []string) error {
 
 	fileList = slices.Compact(fileList)
 	log.Debug("Ready to upload list: %v", fileList)
+	if c.opts.DryRun {
+		var p plan.Plan
+		for _, f := range fileList {
+			stat, err := os.Stat(f)
+			if err != nil {
+				return fmt.Errorf("%s stat: %w", f, err)
+			}
+			p.Add(f, stat.Size())
+		}
+		return p.Write(c.cmd.OutOrStdout())
+	}
 	var total = int64(len(fileList))
 	defer func() {
 		c.cmd.Printf("report total: %v success: %v failed: %v skip: %v\n",
@@ -254,9 +269,8 @@ func (c *Cloud) execute(ctx context.Context, input []string) error {
 
 	// 刷新token过期时间
 	defer func() {
-		refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-		if err != nil || refresh.Code != 200 {
-			log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+		if _, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{}); err != nil {
+			log.Warn("TokenRefresh: %s", err)
 		}
 	}()
 
@@ -275,7 +289,7 @@ func (c *Cloud) execute(ctx context.Context, input []string) error {
 		}
 		go func(filename string) {
 			defer sema.Release(1)
-			if err := c.upload(ctx, request, filename, bar); err != nil {
+			if _, err := c.upload(ctx, request, filename, bar); err != nil {
 				fail.Add(1)
 				c.cmd.Printf("%s upload failed: %s", filepath.Base(filename), err)
 				log.Error("upload(%s): %s", filename, err)
@@ -289,7 +303,8 @@ func (c *Cloud) execute(ctx context.Context, input []string) error {
 	return nil
 }
 
-func (c *Cloud) upload(ctx context.Context, client *weapi.Api, filename string, bar *pb.ProgressBar) error {
+// upload 上传单个文件到云盘并发布,返回云盘歌曲id. bar可为nil
+func (c *Cloud) upload(ctx context.Context, client *weapi.Api, filename string, bar *pb.ProgressBar) (string, error) {
 	// 1.读取文件
 	var (
 		ext     = filepath.Ext(filename)
@@ -298,29 +313,29 @@ func (c *Cloud) upload(ctx context.Context, client *weapi.Api, filename string,
 
 	file, err := os.Open(filename)
 	if err != nil {
-		return fmt.Errorf("Open: %w", err)
+		return "", fmt.Errorf("Open: %w", err)
 	}
 	defer file.Close()
 
 	stat, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf("Stat: %w", err)
+		return "", fmt.Errorf("Stat: %w", err)
 	}
 	var fileSize = stat.Size()
 
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return fmt.Errorf("ReadAll: %w", err)
+		return "", fmt.Errorf("ReadAll: %w", err)
 	}
 
 	md5, err := utils.MD5Hex(data)
 	if err != nil {
-		return fmt.Errorf("MD5Hex: %w", err)
+		return "", fmt.Errorf("MD5Hex: %w", err)
 	}
 
 	// 重新设置文件指针到开头
 	if _, err = file.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("Seek: %w", err)
+		return "", fmt.Errorf("Seek: %w", err)
 	}
 
 	// 2.检查此文件是否需要上传
@@ -334,12 +349,9 @@ func (c *Cloud) upload(ctx context.Context, client *weapi.Api, filename string,
 	}
 	resp, err := client.CloudUploadCheck(ctx, &checkReq)
 	if err != nil {
-		return fmt.Errorf("CloudUploadCheck: %w", err)
+		return "", fmt.Errorf("CloudUploadCheck: %w", err)
 	}
 	log.Debug("CloudUploadCheck resp: %+v\n", resp)
-	if resp.Code != 200 {
-		return fmt.Errorf("CloudUploadCheck resp: %+v\n", resp)
-	}
 
 	// 3.获取上传凭证
 	var allocReq = weapi.CloudTokenAllocReq{
@@ -353,12 +365,9 @@ func (c *Cloud) upload(ctx context.Context, client *weapi.Api, filename string,
 	}
 	allocResp, err := client.CloudTokenAlloc(ctx, &allocReq)
 	if err != nil {
-		return fmt.Errorf("CloudTokenAlloc: %w", err)
+		return "", fmt.Errorf("CloudTokenAlloc: %w", err)
 	}
 	log.Debug("CloudTokenAlloc resp: %+v\n", allocResp)
-	if allocResp.Code != 200 {
-		return fmt.Errorf("CloudTokenAlloc resp: %+v\n", allocResp)
-	}
 
 	// 4.上传文件
 	if resp.NeedUpload {
@@ -372,18 +381,18 @@ func (c *Cloud) upload(ctx context.Context, client *weapi.Api, filename string,
 		}
 		uploadResp, err := client.CloudUpload(ctx, &uploadReq)
 		if err != nil {
-			return fmt.Errorf("CloudUpload: %w", err)
+			return "", fmt.Errorf("CloudUpload: %w", err)
 		}
 		log.Debug("CloudUpload resp: %+v\n", uploadResp)
 		if uploadResp.ErrCode != "" {
-			return fmt.Errorf("CloudUpload resp: %+v\n", uploadResp)
+			return "", fmt.Errorf("CloudUpload resp: %+v\n", uploadResp)
 		}
 	}
 
 	// 5.上传歌曲相关信息
 	metadata, err := tag.ReadFrom(file)
 	if err != nil {
-		return fmt.Errorf("ReadFrom: %w", err)
+		return "", fmt.Errorf("ReadFrom: %w", err)
 	}
 
 	var InfoReq = weapi.CloudInfoReq{
@@ -401,29 +410,23 @@ func (c *Cloud) upload(ctx context.Context, client *weapi.Api, filename string,
 	log.Debug("CloudInfo req: %+v", InfoReq)
 	infoResp, err := client.CloudInfo(ctx, &InfoReq)
 	if err != nil {
-		return fmt.Errorf("CloudInfo: %w", err)
+		return "", fmt.Errorf("CloudInfo: %w", err)
 	}
 	log.Debug("CloudInfo resp: %+v\n", infoResp)
-	if infoResp.Code != 200 {
-		return fmt.Errorf("CloudInfo: %+v", infoResp.RespCommon)
-	}
 
 	// todo: 此步骤貌似是判断上传文件转码状态,具体有待商榷,另外此处貌似不用进行重试处理？
 	var retryNum int64
 retry:
 	retryNum++
 	if retryNum > 3 {
-		return fmt.Errorf("CloudInfo retry too many times")
+		return "", fmt.Errorf("CloudInfo retry too many times")
 	}
 	songId, _ := strconv.ParseInt(infoResp.SongId, 10, 64)
 	statusResp, err := client.CloudMusicStatus(ctx, &weapi.CloudMusicStatusReq{SongIds: []int64{songId}})
 	if err != nil {
-		return fmt.Errorf("CloudMusicStatus: %w", err)
+		return "", fmt.Errorf("CloudMusicStatus: %w", err)
 	}
 	log.Debug("CloudMusicStatus #%v resp: %+v\n", retryNum, statusResp)
-	if statusResp.Code != 200 {
-		log.Error("CloudMusicStatus #%v resp: %+v\n", retryNum, statusResp)
-	}
 	// v.Status=9得条件下出现过云盘上传成功的情况,即使不走下面的CloudPublish逻辑,目前暂时未找到原因
 	if v, ok := statusResp.Statuses[infoResp.SongId]; ok && v.Status != 0 {
 		log.Warn("CloudMusicStatus status: %v retry #%v\n", statusResp.Statuses, retryNum)
@@ -434,22 +437,22 @@ retry:
 	// 6.对上传得歌曲进行发布，和自己账户做关联,不然云盘列表看不到上传得歌曲信息
 	publishResp, err := client.CloudPublish(ctx, &weapi.CloudPublishReq{SongId: infoResp.SongId})
 	if err != nil {
-		return fmt.Errorf("CloudPublish: %w", err)
+		return "", fmt.Errorf("CloudPublish: %w", err)
 	}
 	log.Debug("CloudPublish resp: %+v\n", publishResp)
 	switch publishResp.Code {
 	case 200:
-		if !resp.NeedUpload {
+		if !resp.NeedUpload && bar != nil {
 			bar.Add64(fileSize)
 		}
 		log.Debug("上传成功: %s", filename)
 	case 201:
-		if !resp.NeedUpload {
+		if !resp.NeedUpload && bar != nil {
 			bar.Add64(fileSize)
 		}
 		log.Debug("重复上传: %s", filename)
 	default:
-		return fmt.Errorf("CloudPublish: %+v", publishResp)
+		return "", fmt.Errorf("CloudPublish: %+v", publishResp)
 	}
-	return nil
+	return infoResp.SongId, nil
 }
@@ -0,0 +1,245 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type cloudMatchCmd struct {
+	root *Cloud
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	auto      bool  // 自动搜索匹配模式
+	candidate int64 // 自动模式下每首歌曲展示的候选数量
+}
+
+func cloudMatch(root *Cloud, l *log.Logger) *cobra.Command {
+	c := &cloudMatchCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "match",
+		Short:   "[need login] Correct the matching song of the cloud disk music",
+		Example: "  ncmctl cloud match <cloudId> <songId>\n  ncmctl cloud match --auto\n  ncmctl cloud match --auto <cloudId> <cloudId>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *cloudMatchCmd) addFlags() {
+	c.cmd.Flags().BoolVarP(&c.auto, "auto", "a", false, "search by the uploaded file tags and interactively choose the matching song")
+	c.cmd.Flags().Int64VarP(&c.candidate, "candidate", "n", 5, "number of candidate songs displayed in auto mode")
+}
+
+func (c *cloudMatchCmd) validate(args []string) error {
+	if c.candidate <= 0 || c.candidate > 30 {
+		return fmt.Errorf("candidate must be between 1 and 30")
+	}
+	if c.auto {
+		for _, v := range args {
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				return fmt.Errorf("invalid cloud id: %s", v)
+			}
+		}
+		return nil
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("requires <cloudId> <songId> or use --auto")
+	}
+	for _, v := range args {
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return fmt.Errorf("invalid id: %s", v)
+		}
+	}
+	return nil
+}
+
+func (c *cloudMatchCmd) execute(ctx context.Context, args []string) error {
+	if err := c.validate(args); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	request := weapi.New(cli)
+
+	// 判断是否需要登录
+	if request.NeedLogin(ctx) {
+		return fmt.Errorf("need login")
+	}
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return fmt.Errorf("need login")
+	}
+	var uid = fmt.Sprintf("%d", user.Account.Id)
+
+	if !c.auto {
+		return c.match(ctx, request, uid, args[0], args[1])
+	}
+
+	// 自动模式: 获取需要匹配的云盘歌曲
+	list, err := cloudSongs(ctx, request, args)
+	if err != nil {
+		return fmt.Errorf("cloudSongs: %w", err)
+	}
+	if len(list) <= 0 {
+		c.cmd.Println("no cloud songs found")
+		return nil
+	}
+
+	var (
+		reader  = bufio.NewReader(os.Stdin)
+		matched int
+	)
+	for i, v := range list {
+		keyword := c.keyword(v)
+		c.cmd.Printf("\n[%d/%d] cloudId=%d file=%s current=%s - %s(%d)\n",
+			i+1, len(list), v.SongId, v.FileName, v.SimpleSong.Name, artistNames(v.SimpleSong.Ar), v.SimpleSong.Id)
+		c.cmd.Printf("search: %s\n", keyword)
+
+		resp, err := request.CloudSearch(ctx, &weapi.CloudSearchReq{S: keyword, Type: weapi.SearchTypeSong, Limit: c.candidate})
+		if err != nil {
+			return fmt.Errorf("CloudSearch: %w", err)
+		}
+		if len(resp.Result.Songs) <= 0 {
+			c.cmd.Println("no candidate found, skip")
+			continue
+		}
+		for j, s := range resp.Result.Songs {
+			c.cmd.Printf("  %d) %s - %s [%s] (%d)\n", j+1, artistNames(s.Ar), s.Name, s.Al.Name, s.Id)
+		}
+
+	input:
+		c.cmd.Printf("choose [1-%d], s: skip, q: quit (default s): ", len(resp.Result.Songs))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		switch line = strings.TrimSpace(line); line {
+		case "", "s":
+			continue
+		case "q":
+			c.cmd.Printf("matched: %d\n", matched)
+			return nil
+		}
+		index, err := strconv.Atoi(line)
+		if err != nil || index < 1 || index > len(resp.Result.Songs) {
+			c.cmd.Println("invalid input, please retry")
+			goto input
+		}
+		var song = resp.Result.Songs[index-1]
+		if song.Id == v.SimpleSong.Id {
+			c.cmd.Println("already matched, skip")
+			continue
+		}
+		if err := c.match(ctx, request, uid, fmt.Sprintf("%d", v.SongId), fmt.Sprintf("%d", song.Id)); err != nil {
+			c.cmd.Printf("match failed: %s\n", err)
+			continue
+		}
+		matched++
+	}
+	c.cmd.Printf("matched: %d\n", matched)
+	return nil
+}
+
+func (c *cloudMatchCmd) match(ctx context.Context, request *weapi.Api, uid, cloudId, songId string) error {
+	_, err := request.CloudMatch(ctx, &weapi.CloudMatchReq{
+		UserId:       uid,
+		SongId:       cloudId,
+		AdjustSongId: songId,
+	})
+	if err != nil {
+		return fmt.Errorf("CloudMatch: %w", err)
+	}
+	c.cmd.Printf("cloud song %s matched to %s\n", cloudId, songId)
+	return nil
+}
+
+// cloudSongs 获取云盘歌曲列表,如果指定了ids则只返回对应的歌曲
+func cloudSongs(ctx context.Context, request *weapi.Api, ids []string) ([]weapi.CloudListRespData, error) {
+	var (
+		want = make(map[int64]struct{}, len(ids))
+		list []weapi.CloudListRespData
+	)
+	for _, v := range ids {
+		id, _ := strconv.ParseInt(v, 10, 64)
+		want[id] = struct{}{}
+	}
+
+	for offset := int64(0); ; {
+		resp, err := request.CloudList(ctx, &weapi.CloudListReq{Offset: offset, Limit: 200})
+		if err != nil {
+			return nil, fmt.Errorf("CloudList: %w", err)
+		}
+		for _, v := range resp.Data {
+			if len(want) > 0 {
+				if _, ok := want[v.SongId]; !ok {
+					continue
+				}
+			}
+			list = append(list, v)
+		}
+		offset += int64(len(resp.Data))
+		if !resp.HasMore || len(resp.Data) <= 0 {
+			break
+		}
+	}
+	return list, nil
+}
+
+// keyword 根据上传时的歌曲标签生成搜索关键词,标签缺失时使用文件名
+func (c *cloudMatchCmd) keyword(v weapi.CloudListRespData) string {
+	var name = v.SongName
+	if name == "" {
+		name = strings.TrimSuffix(v.FileName, filepath.Ext(v.FileName))
+	}
+	if v.Artist != "" && v.Artist != "未知艺术家" && !strings.Contains(name, v.Artist) {
+		name = name + " " + v.Artist
+	}
+	return name
+}
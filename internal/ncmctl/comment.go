@@ -0,0 +1,93 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type Comment struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewComment(root *Root, l *log.Logger) *Comment {
+	c := &Comment{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "comment",
+			Short: "Read and post comments of songs, playlists and albums",
+			Example: "  ncmctl comment list 2161154646 --hot\n" +
+				"  ncmctl comment list 'https://music.163.com/playlist?id=19723756' -n 100\n" +
+				"  ncmctl comment post 2161154646 \"nice song\"\n" +
+				"  ncmctl comment like 2161154646 7030937285",
+		},
+	}
+	c.addFlags()
+	c.Add(commentList(c, l))
+	c.Add(commentPost(c, l))
+	c.Add(commentRm(c, l))
+	c.Add(commentLike(c, l))
+	return c
+}
+
+func (c *Comment) addFlags() {}
+
+func (c *Comment) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Comment) Command() *cobra.Command {
+	return c.cmd
+}
+
+// commentThreadId 将歌曲、歌单、专辑的id或链接转换为评论线程id,纯数字id根据kind判断资源类型
+func commentThreadId(source, kind string) (string, error) {
+	k, id, err := Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("Parse(%s): %w", source, err)
+	}
+	// Parse 对纯数字id统一返回song
+	if _, err := strconv.ParseInt(source, 10, 64); err == nil && kind != "" {
+		k = kind
+	}
+	switch k {
+	case "song":
+		return fmt.Sprintf("%s%d", weapi.CommentThreadSong, id), nil
+	case "playlist":
+		return fmt.Sprintf("%s%d", weapi.CommentThreadPlaylist, id), nil
+	case "album":
+		return fmt.Sprintf("%s%d", weapi.CommentThreadAlbum, id), nil
+	default:
+		return "", fmt.Errorf("%s is not support comment", k)
+	}
+}
@@ -0,0 +1,194 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+// commentPageSize 每次请求获取的评论数量
+const commentPageSize = 50
+
+type commentListCmd struct {
+	root *Comment
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	kind  string // 纯数字id的资源类型
+	hot   bool   // 热门评论
+	limit int64  // 获取的评论总数
+	floor int64  // 楼层评论id,不为0时获取该评论的回复
+}
+
+func commentList(root *Comment, l *log.Logger) *cobra.Command {
+	c := &commentListCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "list <resource>",
+		Short: "List newest or hot comments, or replies of a comment",
+		Example: "  ncmctl comment list 2161154646\n" +
+			"  ncmctl comment list 2161154646 --hot -n 100\n" +
+			"  ncmctl comment list 19723756 --type playlist\n" +
+			"  ncmctl comment list 2161154646 --floor 7030937285",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args[0])
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *commentListCmd) addFlags() {
+	c.cmd.Flags().StringVar(&c.kind, "type", "song", "resource type of numeric id, support: song、playlist、album")
+	c.cmd.Flags().BoolVar(&c.hot, "hot", false, "list hot comments")
+	c.cmd.Flags().Int64VarP(&c.limit, "limit", "n", 20, "number of comments to fetch")
+	c.cmd.Flags().Int64Var(&c.floor, "floor", 0, "list replies of the comment id")
+}
+
+func (c *commentListCmd) execute(ctx context.Context, source string) error {
+	if c.limit <= 0 {
+		return fmt.Errorf("limit must be greater than 0")
+	}
+	threadId, err := commentThreadId(source, c.kind)
+	if err != nil {
+		return err
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	var list []weapi.CommentsRespComments
+	switch {
+	case c.floor != 0:
+		list, err = c.floorComments(ctx, request, threadId)
+	case c.hot:
+		list, err = c.hotComments(ctx, request, threadId)
+	default:
+		list, err = c.newComments(ctx, request, threadId)
+	}
+	if err != nil {
+		return err
+	}
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSER\tLIKED\tTIME\tCONTENT")
+	for _, v := range list {
+		var content = strings.Join(strings.Fields(v.Content), " ")
+		for _, r := range v.BeReplied {
+			if r.Content != nil {
+				content += fmt.Sprintf(" //@%s: %s", r.User.Nickname, strings.Join(strings.Fields(*r.Content), " "))
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", v.CommentId, v.User.Nickname, v.LikedCount, time.UnixMilli(v.Time).Format(time.DateTime), content)
+	}
+	return w.Flush()
+}
+
+// newComments 按时间倒序获取最新评论,使用上一页最后一条评论时间翻页
+func (c *commentListCmd) newComments(ctx context.Context, request *weapi.Api, threadId string) ([]weapi.CommentsRespComments, error) {
+	var (
+		list   []weapi.CommentsRespComments
+		before int64
+	)
+	for int64(len(list)) < c.limit {
+		resp, err := request.Comments(ctx, &weapi.CommentsReq{
+			ThreadId:   threadId,
+			Offset:     "0",
+			Limit:      strconv.FormatInt(min(commentPageSize, c.limit-int64(len(list))), 10),
+			BeforeTime: strconv.FormatInt(before, 10),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Comments: %w", err)
+		}
+		list = append(list, resp.Comments...)
+		if !resp.More || len(resp.Comments) <= 0 {
+			break
+		}
+		before = resp.Comments[len(resp.Comments)-1].Time
+	}
+	return list, nil
+}
+
+func (c *commentListCmd) hotComments(ctx context.Context, request *weapi.Api, threadId string) ([]weapi.CommentsRespComments, error) {
+	var list []weapi.CommentsRespComments
+	for int64(len(list)) < c.limit {
+		resp, err := request.CommentHot(ctx, &weapi.CommentHotReq{
+			ThreadId: threadId,
+			Rid:      threadId[strings.LastIndex(threadId, "_")+1:],
+			Offset:   int64(len(list)),
+			Limit:    min(commentPageSize, c.limit-int64(len(list))),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("CommentHot: %w", err)
+		}
+		list = append(list, resp.HotComments...)
+		if !resp.HasMore || len(resp.HotComments) <= 0 {
+			break
+		}
+	}
+	return list, nil
+}
+
+func (c *commentListCmd) floorComments(ctx context.Context, request *weapi.Api, threadId string) ([]weapi.CommentsRespComments, error) {
+	var (
+		list   []weapi.CommentsRespComments
+		cursor int64 = -1
+	)
+	for int64(len(list)) < c.limit {
+		resp, err := request.CommentFloor(ctx, &weapi.CommentFloorReq{
+			ParentCommentId: c.floor,
+			ThreadId:        threadId,
+			Time:            cursor,
+			Limit:           min(commentPageSize, c.limit-int64(len(list))),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("CommentFloor: %w", err)
+		}
+		list = append(list, resp.Data.Comments...)
+		if !resp.Data.HasMore || len(resp.Data.Comments) <= 0 {
+			break
+		}
+		cursor = resp.Data.Time
+	}
+	return list, nil
+}
@@ -0,0 +1,170 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type commentManageCmd struct {
+	root *Comment
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	kind  string // 纯数字id的资源类型
+	reply int64  // 回复的评论id
+	undo  bool   // 取消点赞
+}
+
+func commentPost(root *Comment, l *log.Logger) *cobra.Command {
+	c := &commentManageCmd{root: root, l: l}
+	c.cmd = &cobra.Command{
+		Use:   "post <resource> <content>",
+		Short: "[need login] Post a comment or reply to a comment",
+		Example: "  ncmctl comment post 2161154646 \"nice song\"\n" +
+			"  ncmctl comment post 2161154646 \"agree\" --reply 7030937285",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(cmd.Context(), args[0], func(ctx context.Context, request *weapi.Api, threadId string) error {
+				return c.post(ctx, request, threadId, strings.Join(args[1:], " "))
+			})
+		},
+	}
+	c.cmd.Flags().Int64Var(&c.reply, "reply", 0, "reply to the comment id")
+	c.addFlags()
+	return c.cmd
+}
+
+func commentRm(root *Comment, l *log.Logger) *cobra.Command {
+	c := &commentManageCmd{root: root, l: l}
+	c.cmd = &cobra.Command{
+		Use:     "rm <resource> <commentId>",
+		Short:   "[need login] Delete your comment",
+		Example: "  ncmctl comment rm 2161154646 7030937285",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(cmd.Context(), args[0], func(ctx context.Context, request *weapi.Api, threadId string) error {
+				return c.remove(ctx, request, threadId, args[1])
+			})
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func commentLike(root *Comment, l *log.Logger) *cobra.Command {
+	c := &commentManageCmd{root: root, l: l}
+	c.cmd = &cobra.Command{
+		Use:   "like <resource> <commentId>",
+		Short: "[need login] Like or unlike a comment",
+		Example: "  ncmctl comment like 2161154646 7030937285\n" +
+			"  ncmctl comment like 2161154646 7030937285 --undo",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(cmd.Context(), args[0], func(ctx context.Context, request *weapi.Api, threadId string) error {
+				return c.like(ctx, request, threadId, args[1])
+			})
+		},
+	}
+	c.cmd.Flags().BoolVar(&c.undo, "undo", false, "unlike the comment")
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *commentManageCmd) addFlags() {
+	c.cmd.Flags().StringVar(&c.kind, "type", "song", "resource type of numeric id, support: song、playlist、album")
+}
+
+// run 解析评论线程id,创建客户端并检查登录状态后执行fn
+func (c *commentManageCmd) run(ctx context.Context, source string, fn func(ctx context.Context, request *weapi.Api, threadId string) error) error {
+	threadId, err := commentThreadId(source, c.kind)
+	if err != nil {
+		return err
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	return fn(ctx, request, threadId)
+}
+
+func (c *commentManageCmd) post(ctx context.Context, request *weapi.Api, threadId, content string) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("content is empty")
+	}
+	resp, err := request.CommentAdd(ctx, &weapi.CommentAddReq{ThreadId: threadId, Content: content, CommentId: c.reply})
+	if err != nil {
+		return fmt.Errorf("CommentAdd: %w", err)
+	}
+	if resp.Comment != nil {
+		c.cmd.Println(resp.Comment.CommentId)
+	}
+	return nil
+}
+
+func (c *commentManageCmd) remove(ctx context.Context, request *weapi.Api, threadId, commentId string) error {
+	id, err := strconv.ParseInt(commentId, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid comment id: %s", commentId)
+	}
+	_, err = request.CommentDelete(ctx, &weapi.CommentDeleteReq{ThreadId: threadId, CommentId: id})
+	if err != nil {
+		return fmt.Errorf("CommentDelete: %w", err)
+	}
+	c.cmd.Printf("%d: deleted\n", id)
+	return nil
+}
+
+func (c *commentManageCmd) like(ctx context.Context, request *weapi.Api, threadId, commentId string) error {
+	id, err := strconv.ParseInt(commentId, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid comment id: %s", commentId)
+	}
+	_, err = request.CommentLike(ctx, &weapi.CommentLikeReq{ThreadId: threadId, CommentId: id, Like: !c.undo})
+	if err != nil {
+		return fmt.Errorf("CommentLike: %w", err)
+	}
+	if c.undo {
+		c.cmd.Printf("%d: unliked\n", id)
+	} else {
+		c.cmd.Printf("%d: liked\n", id)
+	}
+	return nil
+}
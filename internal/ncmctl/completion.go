@@ -0,0 +1,288 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// completionSongsLimit 补全缓存中保留的最近使用歌曲数量
+const completionSongsLimit = 100
+
+type Completion struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewCompletion(root *Root, l *log.Logger) *Completion {
+	c := &Completion{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "completion <bash|zsh|fish|powershell>",
+			Short: "Generate the shell completion script, playlist and song ids are completed from the local cache",
+			Long: "Generate the shell completion script. Besides commands and flags, playlist ids of the account\n" +
+				"and recently used song ids are completed with their names as description.\n" +
+				"Playlists are cached when running playlist list、playlist unsub、backup or completion refresh,\n" +
+				"songs are cached when running like、info、lyric、download or playlist add-tracks.",
+			Example: "  source <(ncmctl completion bash)\n" +
+				"  ncmctl completion zsh > \"${fpath[1]}/_ncmctl\"\n" +
+				"  ncmctl completion fish > ~/.config/fish/completions/ncmctl.fish\n" +
+				"  ncmctl completion powershell | Out-String | Invoke-Expression\n" +
+				"  ncmctl completion refresh",
+			ValidArgs:   []string{"bash", "zsh", "fish", "powershell"},
+			Args:        cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+			Annotations: map[string]string{lenientConfig: "", skipKeepAlive: ""},
+		},
+	}
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(args[0])
+	}
+	c.Add(completionRefresh(c, l))
+	return c
+}
+
+func (c *Completion) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Completion) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Completion) execute(shell string) error {
+	var (
+		root = c.root.cmd
+		w    = c.cmd.OutOrStdout()
+	)
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(w, true)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(w)
+	}
+	return fmt.Errorf("unsupported shell: %s", shell)
+}
+
+type completionRefreshCmd struct {
+	root *Completion
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func completionRefresh(root *Completion, l *log.Logger) *cobra.Command {
+	c := &completionRefreshCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "refresh",
+		Short:   "Refresh the cached playlists of the account used by completion",
+		Example: "  ncmctl completion refresh\n  ncmctl completion refresh --profile work",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	return c.cmd
+}
+
+func (c *completionRefreshCmd) execute(ctx context.Context) error {
+	var root = c.root.root
+	cli, err := api.NewClient(root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	list, err := userPlaylists(ctx, request, user.Account.Id)
+	if err != nil {
+		return err
+	}
+	root.cachePlaylists(list)
+	c.cmd.Printf("cached %d playlists to %s\n", len(list), root.completionPath())
+	return nil
+}
+
+// completionCache 补全使用的账号数据缓存,按账号分别保存在 ${CACHE}/completion/<profile>.json
+type completionCache struct {
+	Time      time.Time        `json:"time"`
+	Playlists []completionItem `json:"playlists"`
+	Songs     []completionItem `json:"songs"` // 最近使用的歌曲,最近的在前
+}
+
+type completionItem struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func (c *Root) completionPath() string {
+	return filepath.Join(c.dirs.Cache, "completion", utils.Ternary(c.Opts.Profile != "", c.Opts.Profile, defaultProfile)+".json")
+}
+
+// loadCompletionCache 读取补全缓存,文件不存在或损坏时返回空缓存
+func (c *Root) loadCompletionCache() *completionCache {
+	var cache completionCache
+	data, err := os.ReadFile(c.completionPath())
+	if err != nil {
+		return &cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		log.Debug("[completion] unmarshal %s: %s", c.completionPath(), err)
+		return &completionCache{}
+	}
+	return &cache
+}
+
+// updateCompletionCache 更新补全缓存,缓存只用于补全,失败时仅记录日志不影响命令执行
+func (c *Root) updateCompletionCache(fn func(cache *completionCache)) {
+	if c.dirs.Cache == "" {
+		return
+	}
+	var (
+		path  = c.completionPath()
+		cache = c.loadCompletionCache()
+	)
+	fn(cache)
+	cache.Time = time.Now()
+	data, err := json.Marshal(cache)
+	if err != nil {
+		log.Debug("[completion] marshal: %s", err)
+		return
+	}
+	if err := utils.MkdirIfNotExist(filepath.Dir(path), 0755); err != nil {
+		log.Debug("[completion] MkdirIfNotExist: %s", err)
+		return
+	}
+	// 多个命令可能同时更新缓存,先写临时文件再重命名
+	var tmp = fmt.Sprintf("%s.%d", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		log.Debug("[completion] write %s: %s", tmp, err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		log.Debug("[completion] rename %s: %s", path, err)
+	}
+}
+
+// cachePlaylists 缓存当前账号创建及收藏的歌单
+func (c *Root) cachePlaylists(list []weapi.PlaylistRespList) {
+	c.updateCompletionCache(func(cache *completionCache) {
+		cache.Playlists = make([]completionItem, 0, len(list))
+		for _, v := range list {
+			cache.Playlists = append(cache.Playlists, completionItem{Id: v.Id, Name: v.Name})
+		}
+	})
+}
+
+// cacheSongs 记录最近使用的歌曲,已存在的歌曲移动到最前面
+func (c *Root) cacheSongs(songs ...completionItem) {
+	if len(songs) <= 0 {
+		return
+	}
+	c.updateCompletionCache(func(cache *completionCache) {
+		var list = make([]completionItem, 0, len(songs)+len(cache.Songs))
+		for _, v := range slices.Concat(songs, cache.Songs) {
+			i := slices.IndexFunc(list, func(e completionItem) bool { return e.Id == v.Id })
+			switch {
+			case i < 0:
+				list = append(list, v)
+			case list[i].Name == "":
+				list[i].Name = v.Name
+			}
+		}
+		cache.Songs = list[:min(len(list), completionSongsLimit)]
+	})
+}
+
+// completionSongIds 没有歌曲名称时只记录歌曲id
+func completionSongIds(ids []int64) []completionItem {
+	var list = make([]completionItem, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, completionItem{Id: id})
+	}
+	return list
+}
+
+// completePlaylists 补全缓存中的歌单id,以歌单名称作为说明
+func (c *Root) completePlaylists(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return c.complete(cmd, args, toComplete, func(cache *completionCache) []completionItem { return cache.Playlists })
+}
+
+// completeSongs 补全最近使用的歌曲id,以歌曲名称作为说明
+func (c *Root) completeSongs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return c.complete(cmd, args, toComplete, func(cache *completionCache) []completionItem { return cache.Songs })
+}
+
+func (c *Root) complete(cmd *cobra.Command, args []string, toComplete string, items func(*completionCache) []completionItem) ([]cobra.Completion, cobra.ShellCompDirective) {
+	// 补全时不会执行PersistentPreRunE,需要单独确定缓存目录及账号
+	if err := c.load(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var list []cobra.Completion
+	for _, v := range items(c.loadCompletionCache()) {
+		var id = strconv.FormatInt(v.Id, 10)
+		if !strings.HasPrefix(id, toComplete) || slices.Contains(args, id) {
+			continue
+		}
+		list = append(list, cobra.CompletionWithDesc(id, v.Name))
+	}
+	return list, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeArgs 按参数位置使用对应的补全函数,超出的参数使用最后一个补全函数
+func completeArgs(fn ...cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return fn[min(len(args), len(fn)-1)](cmd, args, toComplete)
+	}
+}
@@ -0,0 +1,136 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/chaunsin/netease-cloud-music/config"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// lenientConfig 命令注解,设置后配置文件加载失败时使用默认配置继续执行,便于修复有误的配置文件
+const lenientConfig = "lenientConfig"
+
+type Config struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewConfig(root *Root, l *log.Logger) *Config {
+	c := &Config{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "config",
+			Short: "Get, set, edit or validate the configuration file",
+			Long: "Manage the configuration file specified by -c, default ${CONFIG}/config.yaml.\n" +
+				"The file is created from the documented default configuration when it does not exist,\n" +
+				"every change is validated strictly before being saved, unknown keys are rejected.",
+			Example: "  ncmctl config get download\n" +
+				"  ncmctl config set download.level hires\n" +
+				"  ncmctl config set network.proxy.url socks5://127.0.0.1:1080\n" +
+				"  ncmctl config edit\n" +
+				"  ncmctl config validate ./config.yaml",
+			Annotations: map[string]string{lenientConfig: "", skipKeepAlive: ""},
+		},
+	}
+	c.addFlags()
+	c.Add(configGet(c, l))
+	c.Add(configSet(c, l))
+	c.Add(configEdit(c, l))
+	c.Add(configValidate(c, l))
+	c.Add(configMigrate(c, l))
+	return c
+}
+
+func (c *Config) addFlags() {}
+
+func (c *Config) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Config) Command() *cobra.Command {
+	return c.cmd
+}
+
+// read 读取配置文件内容,文件不存在时返回默认配置模板
+func (c *Config) read() ([]byte, error) {
+	data, err := os.ReadFile(c.root.cfgPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config.Template(), nil
+		}
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	return data, nil
+}
+
+// save 校验通过后写入配置文件,先写临时文件再重命名避免写入中断导致配置文件损坏
+func (c *Config) save(data []byte) error {
+	var (
+		path             = c.root.cfgPath
+		mode fs.FileMode = 0600 // 配置文件可能包含通知服务token等敏感信息
+	)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := utils.MkdirIfNotExist(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("MkdirIfNotExist: %w", err)
+	}
+	var tmp = path + ".tmp"
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	if err := validateConfigFile(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
+// validateConfigFile 严格加载配置文件,未知配置项、类型错误及取值错误都会返回错误,
+// 同时校验定时任务的command是否支持
+func validateConfigFile(path string) error {
+	cfg, err := config.New(path)
+	if err != nil {
+		return err
+	}
+	if cfg.Daemon != nil && len(cfg.Daemon.Jobs) > 0 {
+		if err := validateJobs(cfg.Daemon); err != nil {
+			return fmt.Errorf("daemon: %w", err)
+		}
+	}
+	return nil
+}
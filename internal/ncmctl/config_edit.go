@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type configEditCmd struct {
+	root *Config
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func configEdit(root *Config, l *log.Logger) *cobra.Command {
+	c := &configEditCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the configuration file with $VISUAL or $EDITOR, the result is validated before saving",
+		Example: "  ncmctl config edit\n" +
+			"  EDITOR=nano ncmctl config edit",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute()
+		},
+	}
+	return c.cmd
+}
+
+func (c *configEditCmd) execute() error {
+	data, err := c.root.read()
+	if err != nil {
+		return err
+	}
+
+	// 在临时文件中编辑,校验通过后才覆盖原配置文件
+	f, err := os.CreateTemp("", "config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("CreateTemp: %w", err)
+	}
+	var tmp = f.Name()
+	defer os.Remove(tmp)
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+
+	var (
+		interactive = isTerminal(os.Stdin) && isTerminal(os.Stderr)
+		reader      = bufio.NewReader(os.Stdin)
+	)
+	for {
+		if err := c.edit(tmp); err != nil {
+			return err
+		}
+		err := validateConfigFile(tmp)
+		if err == nil {
+			break
+		}
+		if !interactive {
+			return err
+		}
+		c.cmd.PrintErrf("invalid config: %s\nre-edit? [Y/n]: ", err)
+		answer, _ := reader.ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a == "n" || a == "no" {
+			return fmt.Errorf("changes discarded: %w", err)
+		}
+	}
+
+	data, err = os.ReadFile(tmp)
+	if err != nil {
+		return fmt.Errorf("ReadFile: %w", err)
+	}
+	if err := c.root.save(data); err != nil {
+		return err
+	}
+	c.cmd.Printf("saved to %s\n", c.root.root.cfgPath)
+	return nil
+}
+
+// edit 调用编辑器打开文件,编辑器可包含参数例如 EDITOR="code --wait"
+func (c *configEditCmd) edit(path string) error {
+	var editor = os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	var fields = strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", fields[0], err)
+	}
+	return nil
+}
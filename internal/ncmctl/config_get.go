@@ -0,0 +1,73 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"github.com/chaunsin/netease-cloud-music/config"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type configGetCmd struct {
+	root *Config
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func configGet(root *Config, l *log.Logger) *cobra.Command {
+	c := &configGetCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "get [key]",
+		Short: "Print a configuration value, the whole file when key is empty",
+		Example: "  ncmctl config get\n" +
+			"  ncmctl config get network.timeout\n" +
+			"  ncmctl config get daemon.jobs.0",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var key string
+			if len(args) > 0 {
+				key = args[0]
+			}
+			return c.execute(key)
+		},
+	}
+	return c.cmd
+}
+
+func (c *configGetCmd) execute(key string) error {
+	data, err := c.root.read()
+	if err != nil {
+		return err
+	}
+	value, err := config.GetValue(data, key)
+	if err != nil {
+		return err
+	}
+	c.cmd.Println(value)
+	return nil
+}
@@ -0,0 +1,212 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/chaunsin/netease-cloud-music/config"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// migrateState 旧版本目录中属于状态目录的文件,config.yaml迁移到配置目录,其余迁移到数据目录
+var migrateState = map[string]bool{
+	"cookie.json": true,
+	"database":    true,
+	"device.json": true,
+	"profiles":    true,
+	"scrobbler":   true,
+	"log":         true,
+	"daemon":      true,
+	"history":     true,
+}
+
+// migrateRegexp 匹配配置文件中引用旧版本目录的路径,例如 ${HOME}/.ncmctl/cookie.json
+var migrateRegexp = regexp.MustCompile(`\$\{?HOME\}?/\.ncmctl/([^/"'\s,\]]+)`)
+
+type configMigrateCmd struct {
+	root   *Config
+	cmd    *cobra.Command
+	l      *log.Logger
+	dryRun bool
+}
+
+type configMigrateResult struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Status string `json:"status"` // moved、exists、failed、pending
+	Reason string `json:"reason,omitempty"`
+}
+
+func configMigrate(root *Config, l *log.Logger) *cobra.Command {
+	c := &configMigrateCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate files from the legacy ${HOME}/.ncmctl directory to XDG base directories",
+		Long: "Move files from the legacy ${HOME}/.ncmctl directory to XDG base directories:\n" +
+			"  config.yaml                                       -> $XDG_CONFIG_HOME/ncmctl (~/.config/ncmctl)\n" +
+			"  cookie.json device.json profiles scrobbler log daemon history database\n" +
+			"                                                    -> $XDG_STATE_HOME/ncmctl (~/.local/state/ncmctl)\n" +
+			"  others, eg: download backup                       -> $XDG_DATA_HOME/ncmctl (~/.local/share/ncmctl)\n" +
+			"Paths such as ${HOME}/.ncmctl/cookie.json in config.yaml are rewritten to ${STATE}/cookie.json.\n" +
+			"Existing files in the target directories are never overwritten, the legacy directory is removed when empty.",
+		Example: "  ncmctl config migrate --dry-run\n" +
+			"  ncmctl config migrate",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute()
+		},
+	}
+	c.cmd.Flags().BoolVar(&c.dryRun, "dry-run", false, "only print files to be migrated")
+	return c.cmd
+}
+
+func (c *configMigrateCmd) execute() error {
+	var (
+		root   = c.root.root
+		legacy = config.LegacyDir(root.home)
+	)
+	if os.Getenv(config.EnvHome) != "" {
+		return fmt.Errorf("%s is set, all files are stored in %s", config.EnvHome, os.Getenv(config.EnvHome))
+	}
+	if root.home == "" || !utils.DirExists(legacy) {
+		c.cmd.Println("legacy directory not found, nothing to migrate")
+		return nil
+	}
+	var dirs = config.XDGDirs(root.home, os.Getenv)
+	if dirs.Legacy {
+		c.cmd.Printf("XDG_*_HOME environment variables are not set on this platform, keep using %s\n", legacy)
+		return nil
+	}
+
+	entries, err := os.ReadDir(legacy)
+	if err != nil {
+		return fmt.Errorf("ReadDir: %w", err)
+	}
+	var (
+		results = make([]configMigrateResult, 0, len(entries))
+		failed  int
+	)
+	for _, e := range entries {
+		var (
+			from = filepath.Join(legacy, e.Name())
+			to   = filepath.Join(migrateTarget(dirs, e.Name()), e.Name())
+			r    = configMigrateResult{From: from, To: to}
+		)
+		switch {
+		case utils.FileExists(to) || utils.DirExists(to):
+			r.Status = "exists"
+		case c.dryRun:
+			r.Status = "pending"
+		default:
+			if err := c.move(from, to, e.Name() == "config.yaml"); err != nil {
+				r.Status, r.Reason = "failed", err.Error()
+				failed++
+				log.Warn("[migrate] %s: %s", from, err)
+				break
+			}
+			r.Status = "moved"
+		}
+		results = append(results, r)
+	}
+	// 只在目录为空时才会删除成功,旧版本目录存在时仍会继续使用
+	if !c.dryRun && os.Remove(legacy) != nil {
+		c.cmd.PrintErrf("%s is not empty and still in use, resolve the conflicts and run again\n", legacy)
+	}
+
+	var v = view{Data: results, Header: []string{"FROM", "TO", "STATUS"}}
+	for _, r := range results {
+		v.Rows = append(v.Rows, []string{r.From, r.To, r.Status})
+	}
+	if err := render(c.cmd.OutOrStdout(), root.outputFormat(outputTable), v); err != nil {
+		return err
+	}
+	if failed > 0 {
+		if failed == len(results) {
+			return fmt.Errorf("all %d files failed", failed)
+		}
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d files failed", failed)}
+	}
+	return nil
+}
+
+// move 移动文件,配置文件中引用旧版本目录的路径同时替换为对应的目录变量
+func (c *configMigrateCmd) move(from, to string, rewrite bool) error {
+	if err := utils.MkdirIfNotExist(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("MkdirIfNotExist: %w", err)
+	}
+	if !rewrite {
+		return os.Rename(from, to)
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	data = migrateRegexp.ReplaceAllFunc(data, func(b []byte) []byte {
+		var name = migrateRegexp.FindSubmatch(b)[1]
+		return []byte(fmt.Sprintf("${%s}/%s", migrateVariable(string(name)), name))
+	})
+	if err := os.WriteFile(to, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+// migrateVariable 旧版本目录中的文件迁移后所在目录对应的配置文件变量
+func migrateVariable(name string) string {
+	switch {
+	case name == "config.yaml":
+		return "CONFIG"
+	case migrateState[name]:
+		return "STATE"
+	default:
+		return "DATA"
+	}
+}
+
+// migrateTarget 旧版本目录中的文件迁移后所在的目录
+func migrateTarget(dirs config.Dirs, name string) string {
+	switch migrateVariable(name) {
+	case "CONFIG":
+		return dirs.Config
+	case "STATE":
+		return dirs.State
+	default:
+		return dirs.Data
+	}
+}
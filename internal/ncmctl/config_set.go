@@ -0,0 +1,73 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"github.com/chaunsin/netease-cloud-music/config"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type configSetCmd struct {
+	root *Config
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func configSet(root *Config, l *log.Logger) *cobra.Command {
+	c := &configSetCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a configuration value, the value is parsed as yaml and validated before saving",
+		Example: "  ncmctl config set download.output ~/Music\n" +
+			"  ncmctl config set download.parallel 10\n" +
+			"  ncmctl config set network.proxy.bypass '[\"*.126.net\"]'\n" +
+			"  ncmctl config set daemon.jobs.0.enable false",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(args[0], args[1])
+		},
+	}
+	return c.cmd
+}
+
+func (c *configSetCmd) execute(key, value string) error {
+	data, err := c.root.read()
+	if err != nil {
+		return err
+	}
+	data, err = config.SetValue(data, key, value)
+	if err != nil {
+		return err
+	}
+	if err := c.root.save(data); err != nil {
+		return err
+	}
+	c.cmd.Printf("%s saved to %s\n", key, c.root.root.cfgPath)
+	return nil
+}
@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+type configValidateCmd struct {
+	root *Config
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func configValidate(root *Config, l *log.Logger) *cobra.Command {
+	c := &configValidateCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Validate the configuration file strictly, unknown keys and invalid values are reported",
+		Example: "  ncmctl config validate\n" +
+			"  ncmctl config validate ./config.yaml",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var path = c.root.root.cfgPath
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return c.execute(path)
+		},
+	}
+	return c.cmd
+}
+
+func (c *configValidateCmd) execute(path string) error {
+	if !utils.FileExists(path) {
+		return fmt.Errorf("config file not exists: %s", path)
+	}
+	if err := validateConfigFile(path); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	c.cmd.Printf("%s: ok\n", path)
+	return nil
+}
@@ -0,0 +1,411 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/config"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/nohup"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+)
+
+// daemonCommands daemon 支持调度执行得子命令,每次执行都会创建新的命令实例避免参数相互污染
+var daemonCommands = map[string]func(root *Root, l *log.Logger) *cobra.Command{
+	"sign":       func(root *Root, l *log.Logger) *cobra.Command { return NewSignIn(root, l).Command() },
+	"signin":     func(root *Root, l *log.Logger) *cobra.Command { return NewDailySignIn(root, l).Command() },
+	"scrobble":   func(root *Root, l *log.Logger) *cobra.Command { return NewScrobble(root, l).Command() },
+	"partner":    func(root *Root, l *log.Logger) *cobra.Command { return NewPartner(root, l).Command() },
+	"keepalive":  func(root *Root, l *log.Logger) *cobra.Command { return NewKeepAlive(root, l).Command() },
+	"download":   func(root *Root, l *log.Logger) *cobra.Command { return NewDownload(root, l).Command() },
+	"daily":      func(root *Root, l *log.Logger) *cobra.Command { return NewDaily(root, l).Command() },
+	"vip":        func(root *Root, l *log.Logger) *cobra.Command { return NewVip(root, l).Command() },
+	"prerelease": func(root *Root, l *log.Logger) *cobra.Command { return NewPreRelease(root, l).Command() },
+	"recommend":  func(root *Root, l *log.Logger) *cobra.Command { return NewRecommend(root, l).Command() },
+	"verify":     func(root *Root, l *log.Logger) *cobra.Command { return NewVerify(root, l).Command() },
+	"digest":     func(root *Root, l *log.Logger) *cobra.Command { return NewDigest(root, l).Command() },
+	"backup":     func(root *Root, l *log.Logger) *cobra.Command { return NewBackup(root, l).Command() },
+	"library":    func(root *Root, l *log.Logger) *cobra.Command { return NewLibrary(root, l).Command() },
+	"listens":    func(root *Root, l *log.Logger) *cobra.Command { return NewListens(root, l).Command() },
+	"history":    func(root *Root, l *log.Logger) *cobra.Command { return NewHistory(root, l).Command() },
+	"yunbei":     func(root *Root, l *log.Logger) *cobra.Command { return NewYunBei(root, l).Command() },
+	"chart":      func(root *Root, l *log.Logger) *cobra.Command { return NewChart(root, l).Command() },
+	"watch":      func(root *Root, l *log.Logger) *cobra.Command { return NewWatch(root, l).Command() },
+	"playlist":   func(root *Root, l *log.Logger) *cobra.Command { return NewPlaylist(root, l).Command() },
+	"liked":      func(root *Root, l *log.Logger) *cobra.Command { return NewLiked(root, l).Command() },
+}
+
+const (
+	// defaultGrace 收到退出信号后默认等待正在执行的任务完成的时长
+	defaultGrace = 30 * time.Second
+	// abortTimeout 中止任务后等待任务保存进度并返回的最长时长
+	abortTimeout = 10 * time.Second
+)
+
+type DaemonOpts struct {
+	List        bool          // 仅输出任务计划不运行
+	Metrics     string        // 监控指标监听地址,覆盖配置文件
+	Grace       time.Duration // 退出时等待正在执行的任务完成的最长时长,覆盖配置文件
+	AllAccounts bool          // 签到、刷歌等与账号绑定的任务在所有已登录账号上分别执行 see: accountCommands
+}
+
+type Daemon struct {
+	root     *Root
+	cmd      *cobra.Command
+	opts     DaemonOpts
+	l        *log.Logger
+	location *time.Location
+	state    *stateStore
+}
+
+func NewDaemon(root *Root, l *log.Logger) *Daemon {
+	c := &Daemon{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "daemon",
+			Short: "[need login] Run the scheduled jobs defined in the config file persistently",
+			Example: `  ncmctl daemon -c ./config.yaml
+  ncmctl daemon -c ./config.yaml --list
+  ncmctl daemon -c ./config.yaml --metrics-addr :9100
+  ncmctl daemon -c ./config.yaml --grace 2m
+  ncmctl daemon -c ./config.yaml --all-accounts`,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args)
+	}
+	return c
+}
+
+func (c *Daemon) addFlags() {
+	c.cmd.Flags().BoolVar(&c.opts.List, "list", false, "print the enabled jobs and their next execution time then exit")
+	c.cmd.Flags().StringVar(&c.opts.Metrics, "metrics-addr", "", "listen address of prometheus /metrics endpoint, overrides daemon.metrics of config file. eg: :9100")
+	c.cmd.Flags().DurationVar(&c.opts.Grace, "grace", 0, "max time to wait for running jobs on SIGTERM/SIGINT before aborting them, overrides daemon.grace of config file. default 30s")
+	c.cmd.Flags().BoolVar(&c.opts.AllAccounts, "all-accounts", false, "run account bound jobs(sign、signin、partner、scrobble、keepalive、yunbei) on every logged in profile, each with its own cookie and rate limit")
+}
+
+func (c *Daemon) validate(conf *config.Daemon) error {
+	if conf == nil || len(conf.Jobs) <= 0 {
+		return fmt.Errorf("no jobs configured, please check the daemon section of config file")
+	}
+	return validateJobs(conf)
+}
+
+// validateJobs 校验任务配置以及任务command是否支持由daemon调度执行
+func validateJobs(conf *config.Daemon) error {
+	var errs []error
+	if err := conf.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, job := range conf.Jobs {
+		if _, ok := daemonCommands[job.Command]; !ok && job.Name != "" {
+			errs = append(errs, fmt.Errorf("[%s] unsupported command: %q", job.Name, job.Command))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Daemon) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Daemon) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Daemon) execute(ctx context.Context, args []string) error {
+	var conf = c.root.Cfg.Daemon
+	if err := c.validate(conf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	log.Debug("daemon config: %+v", conf)
+
+	local, err := time.LoadLocation(conf.Location)
+	if err != nil {
+		return fmt.Errorf("wrong time zone: %w", err)
+	}
+	c.location = local
+
+	c.state, err = loadDaemonState(conf.State)
+	if err != nil {
+		log.Warn("load daemon state %s err: %s", conf.State, err)
+	}
+
+	var (
+		job  = cron.New(cron.WithLocation(local))
+		now  = time.Now().In(local)
+		skip = cron.SkipIfStillRunning(cron.DiscardLogger)
+		// stopping 收到退出信号时取消,不再开始新的任务; running 超过宽限时长后取消,中止正在执行的任务
+		stopping, stop = context.WithCancel(ctx)
+		running, abort = context.WithCancel(ctx)
+		catchup        []cron.Job
+		catchupNames   []string
+	)
+	defer stop()
+	defer abort()
+	for _, j := range conf.Jobs {
+		if !j.Enable {
+			log.Debug("[%s] job disabled", j.Name)
+			continue
+		}
+		schedule, err := cron.ParseStandard(j.Cron)
+		if err != nil {
+			return fmt.Errorf("[%s] crontab error: %v", j.Name, err)
+		}
+		var (
+			fn   = skip(cron.FuncJob(c.run(running, stopping.Done(), j, schedule)))
+			next = schedule.Next(now)
+		)
+		job.Schedule(schedule, fn)
+		c.cmd.Printf("[%s] %s %v cron=%q jitter=%s next=%s\n", j.Name, j.Command, j.Args, j.Cron, j.Jitter, next)
+		log.Info("[%s] job register, next execute: %s", j.Name, next)
+
+		// 上次执行被中断或停机期间错过了计划执行时间,启动后补跑一次
+		if st, ok := c.state.get(j.Name, j.Command); ok && (st.Running || (!st.Next.IsZero() && st.Next.Before(now))) {
+			var reason = utils.Ternary(st.Running, "interrupted at "+st.Last.In(local).String(), "missed at "+st.Next.In(local).String())
+			c.cmd.Printf("[%s] %s, will run once after start\n", j.Name, reason)
+			catchup, catchupNames = append(catchup, fn), append(catchupNames, j.Name)
+		} else if !c.opts.List {
+			// 需要补跑的任务执行结束后再更新下次执行时间
+			c.state.update(j.Name, j.Command, func(s *jobState) { s.Next = next })
+		}
+	}
+	if len(job.Entries()) <= 0 {
+		return fmt.Errorf("no enabled job")
+	}
+	if c.opts.List {
+		return nil
+	}
+
+	var addr = utils.Ternary(c.opts.Metrics != "", c.opts.Metrics, conf.Metrics)
+	if addr != "" {
+		c.root.enableMetrics()
+	}
+
+	if err := c.checkLogin(ctx); err != nil {
+		return err
+	}
+
+	var closeMetrics = func() {}
+	if addr != "" {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("metrics: %w", err)
+		}
+		closeMetrics = c.root.monitor.serve(ln)
+		c.cmd.Printf("metrics listening on http://%s/metrics\n", ln.Addr())
+	}
+
+	var wg sync.WaitGroup
+	for i, fn := range catchup {
+		log.Info("[%s] catch up job", catchupNames[i])
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn.Run()
+		}()
+	}
+	job.Start()
+
+	var grace = utils.Ternary(c.opts.Grace > 0, c.opts.Grace, conf.Grace)
+	if grace <= 0 {
+		grace = defaultGrace
+	}
+	nohup.Daemon(nohup.CloseHook(func(context.Context) error {
+		defer closeMetrics()
+		stop()
+		var done = make(chan struct{})
+		go func() {
+			<-job.Stop().Done()
+			wg.Wait()
+			close(done)
+		}()
+		log.Info("daemon stopping, waiting up to %s for running jobs", grace)
+		select {
+		case <-done:
+			return nil
+		case <-time.After(grace):
+		}
+		// 中止正在执行的任务,下载任务会保存未完成的歌曲,重启后以 --resume 方式继续
+		log.Warn("grace period %s exceeded, aborting running jobs", grace)
+		abort()
+		select {
+		case <-done:
+		case <-time.After(abortTimeout):
+			log.Warn("running jobs not stopped after %s, exit anyway", abortTimeout)
+		}
+		return nil
+	}))
+	return nil
+}
+
+// checkLogin 检查账号是否登录,指定 --all-accounts 时只要求至少有一个已登录账号,各账号登录状态由任务执行时检查
+func (c *Daemon) checkLogin(ctx context.Context) error {
+	if c.opts.AllAccounts {
+		names, err := c.root.profileNames()
+		if err != nil {
+			return &exitError{code: ExitNeedLogin, err: err}
+		}
+		c.cmd.Printf("accounts: %s\n", strings.Join(names, ", "))
+		return nil
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	if weapi.New(cli).NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	return nil
+}
+
+// run 返回定时任务执行函数,执行前会在 [0, jitter) 范围内随机等待一段时间。
+// ctx 取消时中止正在执行的任务,stopping 关闭后不再开始执行。
+func (c *Daemon) run(ctx context.Context, stopping <-chan struct{}, j *config.Job, schedule cron.Schedule) func() {
+	return func() {
+		var (
+			fired = time.Now()
+			delay time.Duration
+		)
+		if j.Jitter > 0 {
+			delay = rand.N(j.Jitter)
+			log.Info("[%s] job start after %s", j.Name, delay)
+		}
+		select {
+		case <-stopping:
+			// 尚未开始执行,记录为错过以便重启后补跑
+			c.state.update(j.Name, j.Command, func(s *jobState) { s.Next = fired })
+			return
+		case <-time.After(delay):
+		}
+
+		log.Info("[%s] job start", j.Name)
+		var (
+			stats  = &jobStats{}
+			start  = time.Now()
+			args   = j.Args
+			jobCtx = ctx
+		)
+		// 上次执行被中断时支持 --resume 的命令只继续未完成的部分
+		var interrupted bool
+		c.state.update(j.Name, j.Command, func(s *jobState) {
+			interrupted = s.Running
+			s.Last, s.Running = start, true
+		})
+		if interrupted {
+			if resume, ok := resumeArgs(daemonCommands[j.Command](c.root, c.l), j.Args); ok {
+				log.Info("[%s] resume interrupted job with args: %v", j.Name, resume)
+				args = resume
+			}
+		}
+		// 限制单次执行时长,避免连接停滞等原因导致任务一直占用,后续调度被跳过
+		if j.Timeout > 0 {
+			var cancel context.CancelFunc
+			jobCtx, cancel = context.WithTimeout(ctx, j.Timeout)
+			defer cancel()
+		}
+		var exec = func(ctx context.Context, root *Root) error {
+			cmd := daemonCommands[j.Command](root, c.l)
+			cmd.SetArgs(append([]string{}, args...))
+			return cmd.ExecuteContext(withJobStats(ctx, stats))
+		}
+		var err error
+		if sequential, ok := accountCommands[j.Command]; ok && c.opts.AllAccounts {
+			var results []accountResult
+			if results, err = c.root.eachProfile(jobCtx, sequential, exec); err == nil {
+				err = reportAccounts(j.Name, results)
+			}
+		} else {
+			err = exec(jobCtx, c.root)
+		}
+		if errors.Is(jobCtx.Err(), context.DeadlineExceeded) {
+			if err != nil {
+				err = fmt.Errorf("timeout after %s: %w", j.Timeout, err)
+			} else {
+				err = fmt.Errorf("timeout after %s", j.Timeout)
+			}
+		}
+		// daemon 退出时中止的任务保持running状态,重启后继续
+		c.state.update(j.Name, j.Command, func(s *jobState) {
+			s.Running = ctx.Err() != nil
+			s.Next = schedule.Next(time.Now().In(c.location))
+		})
+		var result = "success"
+		switch {
+		case errors.Is(jobCtx.Err(), context.DeadlineExceeded):
+			result = "timeout"
+		case ctx.Err() != nil:
+			result = "aborted"
+		case err != nil:
+			result = "failed"
+		}
+		c.root.monitor.taskDone(j.Name, j.Command, result, time.Since(start))
+		switch {
+		case ctx.Err() != nil:
+			log.Warn("[%s] job aborted by daemon stopping: %v", j.Name, err)
+		case err != nil:
+			log.Error("[%s] execute err: %s", j.Name, err)
+			c.root.notify(ctx, "定时任务执行失败", fmt.Sprintf("[%s] %s %v: %s", j.Name, j.Command, j.Args, err))
+		default:
+			log.Info("[%s] execute success", j.Name)
+		}
+
+		// 记录执行结果供 digest 命令生成报告
+		if c.root.Cfg.Daemon.History == "" {
+			return
+		}
+		var record = historyRecord{
+			Name:     j.Name,
+			Command:  j.Command,
+			Start:    start,
+			Duration: time.Since(start),
+			Songs:    stats.Songs.Load(),
+			Bytes:    stats.Bytes.Load(),
+			Failed:   stats.Failed.Load(),
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		if err := appendHistory(c.root.Cfg.Daemon.History, record); err != nil {
+			log.Warn("[%s] appendHistory: %s", j.Name, err)
+		}
+	}
+}
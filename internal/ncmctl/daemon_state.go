@@ -0,0 +1,153 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// daemonState daemon 调度状态,任务执行前后及注册时保存,重启后据此补跑停机期间错过或被中断的任务
+type daemonState struct {
+	Time time.Time            `json:"time"`
+	Jobs map[string]*jobState `json:"jobs"`
+}
+
+type jobState struct {
+	Command string    `json:"command"`
+	Last    time.Time `json:"last"`              // 上次开始执行时间
+	Next    time.Time `json:"next"`              // 下次计划执行时间
+	Running bool      `json:"running,omitempty"` // 正在执行,重启时仍为true说明上次执行被中断
+}
+
+// stateStore 并发安全的调度状态文件,path为空时只记录在内存中
+type stateStore struct {
+	mu    sync.Mutex
+	path  string
+	state daemonState
+}
+
+// loadDaemonState 读取调度状态文件,文件不存在或无法解析时返回空状态
+func loadDaemonState(path string) (*stateStore, error) {
+	var s = &stateStore{path: path, state: daemonState{Jobs: make(map[string]*jobState)}}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	var state daemonState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return s, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	for name, job := range state.Jobs {
+		if job != nil {
+			s.state.Jobs[name] = job
+		}
+	}
+	return s, nil
+}
+
+// get 返回任务状态副本,command变更过的任务视为没有状态
+func (s *stateStore) get(name, command string) (jobState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.state.Jobs[name]
+	if !ok || job.Command != command {
+		return jobState{}, false
+	}
+	return *job, true
+}
+
+// update 修改任务状态并保存
+func (s *stateStore) update(name, command string, fn func(job *jobState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.state.Jobs[name]
+	if !ok || job.Command != command {
+		job = &jobState{Command: command}
+		s.state.Jobs[name] = job
+	}
+	fn(job)
+	if err := s.save(); err != nil {
+		log.Warn("save daemon state %s err: %s", s.path, err)
+	}
+}
+
+// save 先写入临时文件再重命名,避免退出时写入一半导致状态文件损坏
+func (s *stateStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.state.Time = time.Now()
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	var tmp = s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("Rename: %w", err)
+	}
+	return nil
+}
+
+// resumeArgs 返回继续被中断任务的参数,只保留args中的选项并追加 --resume,命令不支持 --resume 时返回false。
+// 例如中断的 download 任务只下载中断时保存的未完成歌曲,不再重新下载全部歌曲。
+func resumeArgs(cmd *cobra.Command, args []string) ([]string, bool) {
+	// LocalFlags 会合并 PersistentFlags 到 Flags 中
+	cmd.LocalFlags()
+	var fs = cmd.Flags()
+	if fs.Lookup("resume") == nil {
+		return nil, false
+	}
+	var resume []string
+	err := fs.ParseAll(args, func(flag *pflag.Flag, value string) error {
+		resume = append(resume, fmt.Sprintf("--%s=%s", flag.Name, value))
+		return fs.Set(flag.Name, value)
+	})
+	if err != nil {
+		return nil, false
+	}
+	return append(resume, "--resume"), true
+}
@@ -0,0 +1,80 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type Daily struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewDaily(root *Root, l *log.Logger) *Daily {
+	c := &Daily{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "daily",
+			Short: "[need login] Daily recommendation songs history",
+			Example: "  ncmctl daily list\n" +
+				"  ncmctl daily download 2024-06-01\n" +
+				"  ncmctl daily download --missing -o ./daily",
+		},
+	}
+	c.addFlags()
+	c.Add(dailyList(c, l))
+	c.Add(dailyDownload(c, l))
+	return c
+}
+
+func (c *Daily) addFlags() {}
+
+func (c *Daily) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Daily) Command() *cobra.Command {
+	return c.cmd
+}
+
+// historyDates 获取可查看的历史日推日期列表
+func historyDates(ctx context.Context, request *weapi.Api) ([]string, error) {
+	resp, err := request.RecommendSongsHistoryRecent(ctx, &weapi.RecommendSongsHistoryRecentReq{})
+	if err != nil {
+		return nil, fmt.Errorf("RecommendSongsHistoryRecent: %w", err)
+	}
+	if len(resp.Data.Dates) <= 0 && resp.Data.NoHistoryMessage != "" {
+		return nil, fmt.Errorf("%s", resp.Data.NoHistoryMessage)
+	}
+	return resp.Data.Dates, nil
+}
@@ -0,0 +1,166 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+type dailyDownloadCmd struct {
+	root *Daily
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	output   string // 输出目录,每一天的歌曲保存在以日期命名的子目录中
+	level    string
+	parallel int64
+	missing  bool // 下载所有本地不存在日期目录的历史日推
+}
+
+func dailyDownload(root *Daily, l *log.Logger) *cobra.Command {
+	c := &dailyDownloadCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "download",
+		Short: "Download the recommended songs of past days into dated folders",
+		Example: "  ncmctl daily download 2024-06-01\n" +
+			"  ncmctl daily download 2024-06-01 2024-06-02 -l SQ\n" +
+			"  ncmctl daily download --missing -o ./daily",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *dailyDownloadCmd) addFlags() {
+	c.cmd.Flags().StringVarP(&c.output, "output", "o", "./daily", "output path, songs of each day are saved in a sub directory named by date")
+	c.cmd.Flags().StringVarP(&c.level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().Int64VarP(&c.parallel, "parallel", "p", 5, "concurrent download count")
+	c.cmd.Flags().BoolVar(&c.missing, "missing", false, "download all available days whose dated folder does not exist yet")
+}
+
+func (c *dailyDownloadCmd) validate(args []string) error {
+	if len(args) > 0 && c.missing {
+		return fmt.Errorf("dates and --missing can not be used together")
+	}
+	if len(args) <= 0 && !c.missing {
+		return fmt.Errorf("please enter the dates or use --missing")
+	}
+	for _, d := range args {
+		if _, err := time.Parse(time.DateOnly, d); err != nil {
+			return fmt.Errorf("invalid date %s, format: 2006-01-02", d)
+		}
+	}
+	return nil
+}
+
+func (c *dailyDownloadCmd) execute(ctx context.Context, args []string) error {
+	if err := c.validate(args); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var dates = args
+	if c.missing {
+		all, err := historyDates(ctx, request)
+		if err != nil {
+			return err
+		}
+		dates = nil
+		for _, d := range all {
+			if !utils.DirExists(filepath.Join(c.output, d)) {
+				dates = append(dates, d)
+			}
+		}
+		if len(dates) <= 0 {
+			c.cmd.Println("no missing days")
+			return nil
+		}
+	}
+
+	var failed int
+	for _, date := range dates {
+		if err := c.download(ctx, request, date); err != nil {
+			failed++
+			c.cmd.PrintErrf("%s: %s\n", date, err)
+			log.Error("[daily] %s download err: %s", date, err)
+		}
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(dates):
+		return fmt.Errorf("all %d days failed", failed)
+	default:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d days failed", failed, len(dates))}
+	}
+}
+
+func (c *dailyDownloadCmd) download(ctx context.Context, request *weapi.Api, date string) error {
+	resp, err := request.RecommendSongsHistoryDetail(ctx, &weapi.RecommendSongsHistoryDetailReq{Date: date})
+	if err != nil {
+		return fmt.Errorf("RecommendSongsHistoryDetail: %w", err)
+	}
+	if len(resp.Data.Songs) <= 0 {
+		return fmt.Errorf("no songs")
+	}
+
+	var ids = make([]string, 0, len(resp.Data.Songs))
+	for _, v := range resp.Data.Songs {
+		ids = append(ids, strconv.FormatInt(v.Id, 10))
+	}
+	c.cmd.Printf("%s: %d songs\n", date, len(ids))
+
+	d := NewDownload(c.root.root, c.l)
+	d.opts.Output = filepath.Join(c.output, date)
+	d.opts.Level = c.level
+	d.opts.Parallel = c.parallel
+	return d.execute(ctx, ids)
+}
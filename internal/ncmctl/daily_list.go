@@ -0,0 +1,102 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type dailyListCmd struct {
+	root *Daily
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	date string // 查看某一天的歌曲列表
+}
+
+func dailyList(root *Daily, l *log.Logger) *cobra.Command {
+	c := &dailyListCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "list",
+		Short:   "List past days or the songs of a specific day",
+		Example: "  ncmctl daily list\n  ncmctl daily list -d 2024-06-01",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *dailyListCmd) addFlags() {
+	c.cmd.Flags().StringVarP(&c.date, "date", "d", "", "list the songs of the day, format: 2006-01-02")
+}
+
+func (c *dailyListCmd) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	if c.date == "" {
+		dates, err := historyDates(ctx, request)
+		if err != nil {
+			return err
+		}
+		for _, d := range dates {
+			c.cmd.Println(d)
+		}
+		return nil
+	}
+
+	resp, err := request.RecommendSongsHistoryDetail(ctx, &weapi.RecommendSongsHistoryDetailReq{Date: c.date})
+	if err != nil {
+		return fmt.Errorf("RecommendSongsHistoryDetail: %w", err)
+	}
+	for _, v := range resp.Data.Songs {
+		var artists = make([]string, 0, len(v.Ar))
+		for _, ar := range v.Ar {
+			artists = append(artists, ar.Name)
+		}
+		c.cmd.Printf("%d\t%s - %s\n", v.Id, strings.Join(artists, ","), v.Name)
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// historyMu 守护进程中多个任务可能同时结束,追加记录时需要串行写入
+var historyMu sync.Mutex
+
+// jobStats 守护进程单次任务执行的统计信息,通过context传递给子命令,非守护进程执行时为nil
+type jobStats struct {
+	Songs  atomic.Int64 // 下载成功歌曲数量
+	Bytes  atomic.Int64 // 下载字节数
+	Failed atomic.Int64 // 下载失败歌曲数量
+}
+
+type jobStatsKey struct{}
+
+func withJobStats(ctx context.Context, s *jobStats) context.Context {
+	return context.WithValue(ctx, jobStatsKey{}, s)
+}
+
+// jobStatsFrom 获取context中的任务统计,不存在时返回nil
+func jobStatsFrom(ctx context.Context) *jobStats {
+	s, _ := ctx.Value(jobStatsKey{}).(*jobStats)
+	return s
+}
+
+// historyRecord 任务执行记录,每行一条json记录
+type historyRecord struct {
+	Name        string        `json:"name"`
+	Command     string        `json:"command"`
+	Start       time.Time     `json:"start"`
+	Duration    time.Duration `json:"duration"`
+	Error       string        `json:"error,omitempty"`
+	Songs       int64         `json:"songs,omitempty"`
+	Bytes       int64         `json:"bytes,omitempty"`
+	Failed      int64         `json:"failed,omitempty"`
+	Level       int64         `json:"level,omitempty"`       // 仅digest记录,账号等级
+	ListenSongs int64         `json:"listenSongs,omitempty"` // 仅digest记录,累计听歌数量
+}
+
+func appendHistory(path string, r historyRecord) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("OpenFile: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("Write: %w", err)
+	}
+	return nil
+}
+
+// loadHistory 读取since之后的任务执行记录,无法解析的行会被忽略
+func loadHistory(path string, since time.Time) ([]historyRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Open: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		list    []historyRecord
+		scanner = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		var r historyRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			log.Warn("[digest] skip invalid history: %s", err)
+			continue
+		}
+		if r.Start.Before(since) {
+			continue
+		}
+		list = append(list, r)
+	}
+	return list, scanner.Err()
+}
+
+type DigestOpts struct {
+	Period string // 统计周期 week、month
+	Output string // markdown输出目录
+	Notify bool   // 是否通过alert发送
+}
+
+type Digest struct {
+	root *Root
+	cmd  *cobra.Command
+	opts DigestOpts
+	l    *log.Logger
+}
+
+func NewDigest(root *Root, l *log.Logger) *Digest {
+	c := &Digest{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "digest",
+			Short: "[need login] Summarize daemon activity of the last week or month",
+			Long: "Summarize the daemon job history(daemon.history in config): job runs and failures, songs and bytes\n" +
+				"downloaded and account level progress. The report is sent through alert and can also be written\n" +
+				"as markdown into a directory such as the music library.",
+			Example: "  ncmctl digest\n" +
+				"  ncmctl digest --period month -o ./download --notify=false",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *Digest) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Period, "period", "week", "summary period, support: week、month")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "", "write the markdown report into the directory, file name is digest-<date>.md")
+	c.cmd.Flags().BoolVar(&c.opts.Notify, "notify", true, "send the report through alert")
+}
+
+func (c *Digest) validate() error {
+	if c.opts.Period != "week" && c.opts.Period != "month" {
+		return fmt.Errorf("period is not support: %s", c.opts.Period)
+	}
+	if c.root.Cfg.Daemon == nil || c.root.Cfg.Daemon.History == "" {
+		return fmt.Errorf("daemon.history is not configured")
+	}
+	return nil
+}
+
+func (c *Digest) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Digest) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Digest) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	var (
+		now   = time.Now()
+		since = now.AddDate(0, 0, -7)
+		title = "ncmctl 周报"
+		path  = c.root.Cfg.Daemon.History
+	)
+	if c.opts.Period == "month" {
+		since, title = now.AddDate(0, -1, 0), "ncmctl 月报"
+	}
+	records, err := loadHistory(path, since)
+	if err != nil {
+		return fmt.Errorf("loadHistory: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	detail, err := request.GetUserInfoDetail(ctx, &weapi.GetUserInfoDetailReq{UserId: user.Account.Id})
+	if err != nil {
+		return fmt.Errorf("GetUserInfoDetail: %w", err)
+	}
+	level, err := request.UserLevel(ctx, &weapi.UserLevelReq{})
+	if err != nil {
+		return fmt.Errorf("UserLevel: %w", err)
+	}
+
+	var report = c.report(title, since, now, records, detail, level)
+	if c.opts.Output != "" {
+		dir, err := utils.ExpandTilde(c.opts.Output)
+		if err != nil {
+			return fmt.Errorf("ExpandTilde: %w", err)
+		}
+		if err := utils.MkdirIfNotExist(dir, 0755); err != nil {
+			return fmt.Errorf("MkdirIfNotExist: %w", err)
+		}
+		var file = filepath.Join(dir, fmt.Sprintf("digest-%s.md", now.Format(time.DateOnly)))
+		if err := os.WriteFile(file, []byte(report), 0644); err != nil {
+			return fmt.Errorf("WriteFile: %w", err)
+		}
+		c.cmd.Printf("digest written to %s\n", file)
+	} else {
+		c.cmd.Println(report)
+	}
+	if c.opts.Notify {
+		c.root.notify(ctx, title, report)
+	}
+
+	// 记录本次的账号信息,下次生成报告时用于计算增量
+	var record = historyRecord{
+		Name:        "digest",
+		Command:     "digest",
+		Start:       now,
+		Duration:    time.Since(now),
+		Level:       detail.Level,
+		ListenSongs: detail.ListenSongs,
+	}
+	if err := appendHistory(path, record); err != nil {
+		log.Warn("[digest] appendHistory: %s", err)
+	}
+	return nil
+}
+
+// report 生成markdown格式报告
+func (c *Digest) report(title string, since, now time.Time, records []historyRecord, detail *weapi.GetUserInfoDetailResp, level *weapi.UserLevelResp) string {
+	type summary struct {
+		runs, failures, songs, failed, bytes int64
+		lastError                            string
+	}
+	var (
+		jobs  = make(map[string]*summary)
+		total summary
+		prev  *historyRecord
+		b     strings.Builder
+	)
+	for i, r := range records {
+		// digest任务本身由守护进程记录的结果不含账号信息,只统计digest命令自身写入的记录
+		if r.Command == "digest" {
+			if prev == nil && r.ListenSongs > 0 {
+				prev = &records[i]
+			}
+			continue
+		}
+		s, ok := jobs[r.Name]
+		if !ok {
+			s = &summary{}
+			jobs[r.Name] = s
+		}
+		for _, v := range []*summary{s, &total} {
+			v.runs++
+			v.songs += r.Songs
+			v.failed += r.Failed
+			v.bytes += r.Bytes
+			if r.Error != "" {
+				v.failures++
+				v.lastError = r.Error
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "# %s %s ~ %s\n\n", title, since.Format(time.DateOnly), now.Format(time.DateOnly))
+	fmt.Fprintf(&b, "- 任务执行: %d 次, 失败 %d 次\n", total.runs, total.failures)
+	fmt.Fprintf(&b, "- 下载歌曲: %d 首, %.2fM, 失败 %d 首\n", total.songs, float64(total.bytes)/float64(utils.MB), total.failed)
+	fmt.Fprintf(&b, "- 账号等级: Lv.%d, 升级进度 %.0f%% (听歌 %d/%d, 登录 %d/%d 天)\n", level.Data.Level, level.Data.Progress*100,
+		level.Data.NowPlayCount, level.Data.NextPlayCount, level.Data.NowLoginCount, level.Data.NextLoginCount)
+	if prev != nil {
+		fmt.Fprintf(&b, "- 累计听歌: %d 首, 较上次报告(%s)增加 %d 首\n", detail.ListenSongs, prev.Start.Format(time.DateOnly), detail.ListenSongs-prev.ListenSongs)
+	} else {
+		fmt.Fprintf(&b, "- 累计听歌: %d 首\n", detail.ListenSongs)
+	}
+	if len(jobs) <= 0 {
+		b.WriteString("\n暂无任务执行记录\n")
+		return b.String()
+	}
+
+	var names = make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.WriteString("\n| 任务 | 执行 | 失败 | 下载 | 大小 | 最近错误 |\n|---|---|---|---|---|---|\n")
+	for _, name := range names {
+		var s = jobs[name]
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %.2fM | %s |\n", name, s.runs, s.failures, s.songs, float64(s.bytes)/float64(utils.MB),
+			strings.ReplaceAll(s.lastError, "|", "\\|"))
+	}
+	return b.String()
+}
@@ -27,53 +27,114 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"net/http/httputil"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/eapi"
 	"github.com/chaunsin/netease-cloud-music/api/types"
 	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/checksum"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
 	"github.com/chaunsin/netease-cloud-music/pkg/ncm"
+	"github.com/chaunsin/netease-cloud-music/pkg/normalize"
+	"github.com/chaunsin/netease-cloud-music/pkg/unlock"
 	"github.com/chaunsin/netease-cloud-music/pkg/utils"
 
 	pb "github.com/cheggaaa/pb/v3"
+	"github.com/cheggaaa/pb/v3/termutil"
 	"github.com/mattn/go-runewidth"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/semaphore"
 )
 
+// 下载进度条模板元素,速度及剩余时间由pb根据EWMA计算
 const (
-	barNameWidth        = 35
-	downloadBarTemplate = `{{string . "prefix"}} {{bar . }} {{percent . "%6.2f%%"}}`
+	barPercent  = `{{percent . "%5.1f%%"}}`
+	barCounters = `{{printf "%21s" (counters . "%s/%s")}}`
+	barSpeed    = `{{printf "%11s" (speed . "%s/s" "-")}}`
+	barETA      = `{{printf "%7s" (rtime . "%s" "%s" "-")}}`
 )
 
 func fixedWidthName(s string, width int) string {
 	return runewidth.FillRight(runewidth.Truncate(s, width, ".."), width)
 }
 
+// newDownloadBar 根据终端宽度创建下载进度条
+func newDownloadBar(name string, total int64) *pb.ProgressBar {
+	width, err := termutil.TerminalWidth()
+	if err != nil || width <= 0 {
+		width = 100
+	}
+	tmpl, nameWidth := barLayout(width)
+	return pb.New64(total).
+		Set(pb.Bytes, true).
+		Set("prefix", fixedWidthName(name, nameWidth)).
+		SetTemplateString(tmpl)
+}
+
+// barLayout 返回进度条模板及歌曲名称宽度,终端较窄时依次省略已下载大小、速度及剩余时间
+func barLayout(width int) (tmpl string, nameWidth int) {
+	var prefix = `{{string . "prefix"}} {{bar . }} `
+	switch {
+	case width >= 120:
+		return prefix + barPercent + " " + barCounters + " " + barSpeed + " " + barETA, min(width/4, 48)
+	case width >= 90:
+		return prefix + barPercent + " " + barSpeed + " " + barETA, width / 4
+	default:
+		return prefix + barPercent, max(width/3, 12)
+	}
+}
+
 type DownloadOpts struct {
-	Output      string // 输出目录
-	Parallel    int64  // 并发下载数量
-	Level       string // 歌曲品质 types.Level
-	EncodeType  string // 编码类型
-	ImmerseType string // 沉浸式类型
-	Strict      bool   // 严格模式。当开起时指定的歌曲品质不符合要求,则不进行下载
-	Tag         bool
+	Output        string // 输出目录
+	Parallel      int64  // 并发下载数量
+	Level         string // 歌曲品质 types.Level
+	EncodeType    string // 编码类型
+	ImmerseType   string // 沉浸式类型
+	Strict        bool   // 严格模式。当开起时指定的歌曲品质不符合要求,则不进行下载
+	Tag           bool
+	PreferSpatial bool     // 优先下载空间音频(沉浸环绕声、高清臻音)音质,歌曲不支持或无权益时使用Level音质
+	Checksum      string   // 记录下载文件校验和使用的算法 see: checksum.Algorithm
+	Unlock        bool     // 歌曲无版权或已下架时从第三方平台查找替代音源
+	UnlockSources []string // 第三方音源平台,按顺序查找 see: unlock.Providers
+	Report        string   // 下载报告文件,相对路径时位于输出目录下,为空则不生成
+	Progress      string   // 进度显示方式 auto、bar、plain、none
+	Resume        bool     // 继续下载上次中断时未完成的歌曲
+	ExecAfter     string   // 每首歌曲下载成功后执行的命令 see: hookVars
+	Notify        bool     // 下载完成后根据配置文件alert发送通知
 }
 
+var (
+	// errSongUnavailable 歌曲无版权、已下架或无音源
+	errSongUnavailable = errors.New("song unavailable")
+	// errSongSkipped 严格模式下歌曲没有指定的音质
+	errSongSkipped = errors.New("song skipped")
+	// errMd5Mismatch 下载的文件与接口返回的md5不一致,通常是传输过程中文件损坏,重新下载即可
+	errMd5Mismatch = errors.New("md5 not match")
+)
+
+// downloadAttempts md5校验失败时最多下载次数
+const downloadAttempts = 3
+
 type Download struct {
-	root *Root
-	cmd  *cobra.Command
-	opts DownloadOpts
-	l    *log.Logger
+	root     *Root
+	cmd      *cobra.Command
+	opts     DownloadOpts
+	l        *log.Logger
+	unlocker *unlock.Unlocker
+	batch    *batchBar       // 批量下载汇总进度条,只下载一首歌曲时为nil
+	report   *downloadReport // 最近一次下载的报告,供调用方获取每首歌曲的下载结果
 }
 
 func NewDownload(root *Root, l *log.Logger) *Download {
@@ -81,35 +142,77 @@ func NewDownload(root *Root, l *log.Logger) *Download {
 		root: root,
 		l:    l,
 		cmd: &cobra.Command{
-			Use:     "download",
-			Short:   "[need login] Download songs",
-			Example: `  ncmctl download 2161154646`,
+			Use:               "download",
+			Short:             "[need login] Download songs",
+			Example:           `  ncmctl download 2161154646`,
+			ValidArgsFunction: root.completeSongs,
 		},
 	}
 	c.addFlags()
+	c.Add(downloadMv(c, l))
+	c.Add(downloadVideo(c, l))
 	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		if len(args) == 0 {
+		if len(args) == 0 && !c.opts.Resume {
 			return fmt.Errorf("input is empty, please enter the song id or song link")
 		}
+		c.defaults(cmd)
 		return c.execute(cmd.Context(), args)
 	}
 	return c
 }
 
+// defaults 命令行未指定的下载目录、品质、并发数使用配置文件download中的值
+func (c *Download) defaults(cmd *cobra.Command) {
+	var conf = c.root.Cfg.Download
+	if conf == nil {
+		return
+	}
+	if conf.Output != "" && !cmd.Flags().Changed("output") {
+		c.opts.Output = conf.Output
+	}
+	if conf.Level != "" && !cmd.Flags().Changed("level") {
+		c.opts.Level = conf.Level
+	}
+	if conf.Parallel > 0 && !cmd.Flags().Changed("parallel") {
+		c.opts.Parallel = conf.Parallel
+	}
+}
+
 func (c *Download) addFlags() {
 	c.cmd.PersistentFlags().StringVarP(&c.opts.Output, "output", "o", "./download", "music file output path")
 	c.cmd.PersistentFlags().Int64VarP(&c.opts.Parallel, "parallel", "p", 5, "concurrent download count")
-	c.cmd.PersistentFlags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.PersistentFlags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR,jyeffect,sky,jymaster")
 	c.cmd.PersistentFlags().StringVarP(&c.opts.EncodeType, "encode-type", "", "flac", "song encode type")
 	c.cmd.PersistentFlags().StringVarP(&c.opts.ImmerseType, "immerse-type", "", "c51", "song immerse type")
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Strict, "strict", false, "strict mode. when the downloaded song does not find the corresponding quality, it will not be downloaded.")
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Tag, "tag", true, "whether to set song tag information,default enable")
+	c.cmd.PersistentFlags().StringVar(&c.opts.Checksum, "checksum", string(checksum.MD5), "checksum algorithm recorded for downloaded files, used by verify command. support: md5、sha256、blake3")
+	c.cmd.PersistentFlags().BoolVar(&c.opts.PreferSpatial, "prefer-spatial", false, "prefer spatial audio(sky/jyeffect) quality when the song supports it and the account is entitled, otherwise use --level")
+	c.cmd.PersistentFlags().BoolVar(&c.opts.Unlock, "unlock", false, "search alternative sources from third-party platforms when the song is removed or region-locked")
+	c.cmd.PersistentFlags().StringSliceVar(&c.opts.UnlockSources, "unlock-sources", []string{"kugou", "kuwo", "migu"}, "third-party platforms used by --unlock in order, support: "+strings.Join(unlock.Providers(), "、"))
+	c.cmd.PersistentFlags().StringVar(&c.opts.Report, "report", "report.json", "download report file listing succeeded/skipped/failed songs, relative to output path. empty to disable")
+	c.cmd.PersistentFlags().StringVar(&c.opts.ExecAfter, "exec-after", "", "command run after each song is downloaded, eg: 'beet import {file}'. variables: {id}、{file}、{dir}、{title}、{artist}、{album}、{quality}")
+	c.cmd.PersistentFlags().BoolVar(&c.opts.Notify, "notify", false, "send a notification through the alert configuration when the download finishes or fails")
+	c.cmd.PersistentFlags().BoolVar(&c.opts.Resume, "resume", false, "continue downloading the songs left unfinished by the last interrupted download in the output path")
+	c.cmd.PersistentFlags().StringVar(&c.opts.Progress, "progress", progressAuto, "progress output mode. support: auto、bar、plain、none. auto uses plain when stderr is not a terminal")
 }
 
 func (c *Download) validate() error {
 	if c.opts.Parallel <= 0 || c.opts.Parallel > 20 {
 		return fmt.Errorf("parallel <= 0 or > 10")
 	}
+	if c.opts.Checksum == "" {
+		c.opts.Checksum = string(checksum.MD5)
+	}
+	if _, err := checksum.New(checksum.Algorithm(c.opts.Checksum)); err != nil {
+		return err
+	}
+	if c.opts.Progress == "" {
+		c.opts.Progress = progressAuto
+	}
+	if err := validProgress(c.opts.Progress); err != nil {
+		return err
+	}
 
 	lv, err := strconv.ParseInt(c.opts.Level, 10, 64)
 	if err == nil {
@@ -132,10 +235,10 @@ func (c *Download) validate() error {
 		types.LevelHigher,
 		types.LevelExhigh,
 		types.LevelLossless,
-		types.LevelHires:
-		// types.LevelJyeffect,
-		// types.LevelSky,
-		// types.LevelJymaster:
+		types.LevelHires,
+		types.LevelJyeffect,
+		types.LevelSky,
+		types.LevelJymaster:
 		// validate ok
 	default:
 		switch strings.ToUpper(c.opts.Level) {
@@ -161,6 +264,30 @@ func (c *Download) Command() *cobra.Command {
 }
 
 func (c *Download) execute(ctx context.Context, args []string) error {
+	if c.opts.Resume {
+		ids, err := c.loadState()
+		// daemon 重启后继续中断的任务时,中断前可能已全部下载完成
+		if errors.Is(err, os.ErrNotExist) && len(args) == 0 && jobStatsFrom(ctx) != nil {
+			log.Info("no unfinished songs to resume in %s", c.opts.Output)
+			return nil
+		}
+		if err != nil && (len(args) == 0 || !errors.Is(err, os.ErrNotExist)) {
+			return fmt.Errorf("loadState: %w", err)
+		}
+		args = append(args, ids...)
+	}
+	return c.run(ctx, func(ctx context.Context, request *weapi.Api) ([]Music, error) {
+		// 解析处理输入的资源类型
+		songs, err := c.inputParse(ctx, args, request)
+		if err != nil {
+			return nil, fmt.Errorf("inputParse: %w", err)
+		}
+		return songs, nil
+	})
+}
+
+// run 创建客户端并检查登录状态后下载list返回的歌曲
+func (c *Download) run(ctx context.Context, list func(ctx context.Context, request *weapi.Api) ([]Music, error)) error {
 	if err := c.validate(); err != nil {
 		return fmt.Errorf("validate: %w", err)
 	}
@@ -174,14 +301,20 @@ func (c *Download) execute(ctx context.Context, args []string) error {
 
 	// 判断是否需要登录
 	if request.NeedLogin(ctx) {
-		return fmt.Errorf("need login")
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	if c.opts.Unlock {
+		c.unlocker, err = unlock.New(cli.GetClient(), c.opts.UnlockSources...)
+		if err != nil {
+			return fmt.Errorf("unlock: %w", err)
+		}
 	}
 
 	// 刷新token过期时间
 	defer func() {
-		refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-		if err != nil || refresh.Code != 200 {
-			log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+		if _, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{}); err != nil {
+			log.Warn("TokenRefresh: %s", err)
 		}
 	}()
 
@@ -189,41 +322,309 @@ func (c *Download) execute(ctx context.Context, args []string) error {
 		return fmt.Errorf("MkdirIfNotExist: %w", err)
 	}
 
-	// 解析处理输入的资源类型
-	songs, err := c.inputParse(ctx, args, request)
+	songs, err := list(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	// 生成文件名及写入tag之前对歌曲信息进行规范化处理
+	n, err := normalize.New(c.root.Cfg.Normalize)
 	if err != nil {
-		return fmt.Errorf("inputParse: %w", err)
+		return fmt.Errorf("normalize: %w", err)
+	}
+	for i := range songs {
+		songs[i] = normalizeMusic(n, songs[i])
 	}
 
 	var (
-		failed atomic.Int64
-		sema   = semaphore.NewWeighted(c.opts.Parallel)
+		needLogin atomic.Bool
+		sema      = semaphore.NewWeighted(c.opts.Parallel)
+		report    = downloadReport{Output: c.opts.Output, StartTime: time.Now(), Songs: make([]downloadResult, len(songs))}
 	)
 
-	pool, err := pb.StartPool()
-	if err != nil {
-		return fmt.Errorf("StartPool: %w", err)
+	var (
+		mode = progressMode(c.opts.Progress)
+		pool *pb.Pool
+	)
+	c.batch = nil
+	switch mode {
+	case progressBar:
+		// 进度条显示期间暂存终端日志,进度条结束后再输出
+		defer log.Default.Hold()()
+		if pool, err = startPool(); err != nil {
+			return fmt.Errorf("StartPool: %w", err)
+		}
+		defer pool.Stop()
+		// 多首歌曲时在最上方显示汇总进度
+		if len(songs) > 1 {
+			c.batch = newBatchBar(len(songs))
+			pool.Add(c.batch.bar)
+			defer c.batch.bar.Finish()
+		}
+	case progressPlain:
+		c.batch = newBatchBar(len(songs))
+		c.batch.bar.Set(pb.Static, true).Start()
+		defer c.batch.bar.Finish()
+		defer c.batch.print(os.Stderr, plainInterval)()
+	}
+
+	for i, song := range songs {
+		report.Songs[i] = downloadResult{Id: song.Id, Name: song.NameString(), Artist: song.ArtistString(), Status: downloadPending}
 	}
-	defer pool.Stop()
 
-	for _, song := range songs {
-		var song = song
+	// 终端中直接执行时支持中断暂停并保存进度,以及快捷键暂停下载、取消单首歌曲
+	var (
+		q     = newQueue()
+		saved atomic.Bool
+		keys  bool
+	)
+	ctx, abort := context.WithCancel(ctx)
+	defer abort()
+	if c.interactive(ctx) {
+		keys = mode == progressBar && isTerminal(os.Stdin)
+		var interrupt = c.interrupter(q, abort, func() error {
+			saved.Store(true)
+			return c.saveState(report.pending())
+		}, keys)
+		defer c.watchInterrupt(interrupt)()
+		if keys {
+			defer c.watchKeys(q, interrupt)()
+		}
+	}
+	for i, song := range songs {
+		var (
+			i    = i
+			song = song
+		)
+		if !q.wait() {
+			break
+		}
 		if err := sema.Acquire(ctx, 1); err != nil {
-			return fmt.Errorf("acquire: %w", err)
+			break
 		}
 		go func() {
 			defer sema.Release(1)
-			if err := c.download(ctx, cli, request, &song, pool); err != nil {
-				failed.Add(1)
-				log.Error("download %s err: %v", song.String(), err)
-				return
+			defer c.batch.step()
+			// 同一首歌曲的所有请求使用相同的链路id,便于通过日志排查下载失败原因
+			var (
+				ctx         = api.WithTraceId(ctx, api.NewTraceId())
+				job, cancel = context.WithCancel(ctx)
+				result      = &report.Songs[i]
+			)
+			defer cancel()
+			if keys {
+				job = withJobNumber(job, i+1)
+			}
+			q.start(i+1, song.NameString(), cancel)
+			defer q.finish(i + 1)
+
+			err := c.download(job, cli, request, &song, pool, result)
+			report.mu.Lock()
+			defer report.mu.Unlock()
+			switch {
+			case err == nil:
+				result.Status = downloadOk
+			case ctx.Err() != nil:
+				// 中止下载,保留为未完成状态以便继续下载
+				result.Reason = err.Error()
+			case job.Err() != nil:
+				// 通过快捷键单独取消的歌曲
+				result.Status, result.Reason = downloadSkipped, "canceled"
+				log.Warn("cancel %s trace=%s", song.String(), api.TraceId(ctx))
+			case errors.Is(err, errSongSkipped) || errors.Is(err, errSongUnavailable):
+				// 无版权或不满足音质要求的歌曲重试也无法下载,不算作失败
+				result.Status, result.Reason = downloadSkipped, err.Error()
+				log.Warn("skip %s trace=%s reason: %v", song.String(), api.TraceId(ctx), err)
+			default:
+				if errors.Is(err, api.ErrNeedLogin) {
+					needLogin.Store(true)
+				}
+				if s := jobStatsFrom(ctx); s != nil {
+					s.Failed.Add(1)
+				}
+				c.root.monitor.failed("download")
+				result.Status, result.Reason = downloadFailed, err.Error()
+				log.Error("download %s trace=%s err: %v", song.String(), api.TraceId(ctx), err)
 			}
 		}()
 	}
-	if err := sema.Acquire(ctx, c.opts.Parallel); err != nil {
+	// 等待已开始下载的歌曲结束,中止时ctx已取消因此不能使用ctx等待
+	if err := sema.Acquire(context.Background(), c.opts.Parallel); err != nil {
 		return fmt.Errorf("wait: %w", err)
 	}
-	return nil
+	report.done()
+	c.report = &report
+
+	// 保存未完成的歌曲,通过 --resume 继续下载,中断暂停时保存的进度在全部完成后删除
+	if pending := report.pending(); len(pending) > 0 || c.opts.Resume || saved.Load() {
+		if err := c.saveState(pending); err != nil {
+			log.Warn("save download state err: %s", err)
+		}
+	}
+
+	if c.opts.Report != "" {
+		var file = c.opts.Report
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(c.opts.Output, file)
+		}
+		if err := report.write(file); err != nil {
+			log.Warn("write download report %s err: %s", file, err)
+		}
+	}
+	if format := c.root.Opts.Output; format != "" {
+		// 先停止进度条避免与报告输出交错
+		if pool != nil {
+			_ = pool.Stop()
+		}
+		if err := render(os.Stdout, format, report.view()); err != nil {
+			return fmt.Errorf("render: %w", err)
+		}
+	}
+
+	if c.opts.Notify {
+		c.notify(ctx, &report)
+	}
+
+	switch {
+	case report.Pending > 0:
+		return &exitError{code: ExitInterrupted, err: fmt.Errorf("download interrupted, %d songs pending, run again with --resume to continue", report.Pending)}
+	case report.Failed == 0:
+		return nil
+	case needLogin.Load():
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login: %d/%d songs failed", report.Failed, report.Total)}
+	case report.Succeeded == 0 && report.Skipped == 0:
+		return fmt.Errorf("all %d songs failed", report.Failed)
+	default:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d songs failed", report.Failed, report.Total)}
+	}
+}
+
+const (
+	downloadOk      = "ok"
+	downloadSkipped = "skipped"
+	downloadFailed  = "failed"
+	downloadPending = "pending" // 下载中断未完成
+)
+
+// downloadResult 单首歌曲的下载结果
+type downloadResult struct {
+	Id     int64  `json:"id"`
+	Name   string `json:"name"`
+	Artist string `json:"artist"`
+	Status string `json:"status"`           // ok: 下载成功 skipped: 无版权或音质不满足要求而跳过 failed: 下载失败 pending: 下载中断未完成
+	Level  string `json:"level,omitempty"`  // 实际下载的音质
+	Source string `json:"source,omitempty"` // 音源 netease 或第三方平台名称
+	File   string `json:"file,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+	Reason string `json:"reason,omitempty"` // 跳过或失败原因
+}
+
+// downloadReport 批量下载报告,下载完成后写入输出目录供脚本解析
+type downloadReport struct {
+	Output    string           `json:"output"`
+	StartTime time.Time        `json:"startTime"`
+	EndTime   time.Time        `json:"endTime"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Skipped   int              `json:"skipped"`
+	Failed    int              `json:"failed"`
+	Pending   int              `json:"pending"`
+	Songs     []downloadResult `json:"songs"`
+
+	mu sync.Mutex // 保护下载过程中歌曲状态的更新
+}
+
+// done 统计各状态歌曲数量
+func (r *downloadReport) done() {
+	r.EndTime = time.Now()
+	r.Total = len(r.Songs)
+	for _, s := range r.Songs {
+		switch s.Status {
+		case downloadOk:
+			r.Succeeded++
+		case downloadSkipped:
+			r.Skipped++
+		case downloadPending:
+			r.Pending++
+		default:
+			r.Failed++
+		}
+	}
+}
+
+// notify 发送下载结果通知,只列出部分失败歌曲避免内容过长
+func (c *Download) notify(ctx context.Context, r *downloadReport) {
+	const maxFailed = 10
+	var (
+		title  = "歌曲下载完成"
+		failed []downloadResult
+		b      strings.Builder
+	)
+	switch {
+	case r.Pending > 0:
+		title = "歌曲下载中断"
+	case r.Failed > 0:
+		title = "歌曲下载失败"
+	}
+	fmt.Fprintf(&b, "共%d首 成功%d首 跳过%d首 失败%d首 未完成%d首 耗时%s\n输出目录: %s",
+		r.Total, r.Succeeded, r.Skipped, r.Failed, r.Pending, r.EndTime.Sub(r.StartTime).Round(time.Second), r.Output)
+	for _, s := range r.Songs {
+		if s.Status != downloadFailed {
+			continue
+		}
+		if len(failed) < maxFailed {
+			fmt.Fprintf(&b, "\n%s - %s: %s", s.Artist, s.Name, s.Reason)
+		}
+		failed = append(failed, s)
+	}
+	if len(failed) > maxFailed {
+		fmt.Fprintf(&b, "\n...等%d首", len(failed))
+	}
+
+	// 中断时ctx可能已经取消,使用新的ctx发送
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	c.root.notifyData(ctx, title, b.String(), map[string]any{
+		"output":    r.Output,
+		"total":     r.Total,
+		"succeeded": r.Succeeded,
+		"skipped":   r.Skipped,
+		"failed":    r.Failed,
+		"pending":   r.Pending,
+		"songs":     failed,
+	})
+}
+
+// pending 返回未完成的歌曲id
+func (r *downloadReport) pending() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []int64
+	for _, s := range r.Songs {
+		if s.Status == downloadPending {
+			ids = append(ids, s.Id)
+		}
+	}
+	return ids
+}
+
+func (r *downloadReport) write(file string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+func (r *downloadReport) view() view {
+	var v = view{
+		Data:   r,
+		Header: []string{"ID", "NAME", "ARTIST", "STATUS", "LEVEL", "SOURCE", "FILE", "REASON"},
+	}
+	for _, s := range r.Songs {
+		v.Rows = append(v.Rows, []string{strconv.FormatInt(s.Id, 10), s.Name, s.Artist, s.Status, s.Level, s.Source, s.File, s.Reason})
+	}
+	return v
 }
 
 func (c *Download) inputParse(ctx context.Context, args []string, request *weapi.Api) ([]Music, error) {
@@ -257,36 +658,27 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 					tmp = append(tmp, id)
 				}
 
-				// 分页处理
-				pages, _ := utils.SplitSlice(tmp, 500)
-				for _, p := range pages {
-					var c = make([]weapi.SongDetailReqList, 0, len(p))
-					for _, v := range p {
-						c = append(c, weapi.SongDetailReqList{Id: fmt.Sprintf("%v", v), V: 0})
-					}
-					resp, err := request.SongDetail(ctx, &weapi.SongDetailReq{C: c})
-					if err != nil {
-						return nil, fmt.Errorf("SongDetail: %w", err)
-					}
-					if resp.Code != 200 {
-						return nil, fmt.Errorf("SongDetail err: %+v", resp)
-					}
-					if len(resp.Songs) <= 0 {
-						log.Warn("SongDetail() Songs is empty")
-						continue
-					}
-					for _, v := range resp.Songs {
-						list = append(list, Music{
-							Id:      v.Id,
-							Name:    v.Name,
-							Artist:  v.Ar,
-							Album:   v.Al,
-							AlbumId: v.Al.Id,
-							Time:    v.Dt,
-						})
-					}
-					// todo: 处理版权,状态等有效性校验
+				resp, err := request.SongDetailBatch(ctx, tmp)
+				if err != nil {
+					return nil, fmt.Errorf("SongDetailBatch: %w", err)
+				}
+				if len(resp.Songs) <= 0 {
+					log.Warn("SongDetailBatch() Songs is empty")
+				}
+				var songs = make([]completionItem, 0, len(resp.Songs))
+				for _, v := range resp.Songs {
+					list = append(list, Music{
+						Id:      v.Id,
+						Name:    v.Name,
+						Artist:  v.Ar,
+						Album:   v.Al,
+						AlbumId: v.Al.Id,
+						Time:    v.Dt,
+					})
+					songs = append(songs, completionItem{Id: v.Id, Name: v.Name})
 				}
+				c.root.cacheSongs(songs...)
+				// todo: 处理版权,状态等有效性校验
 			}
 		case "artist":
 			for _, id := range ids {
@@ -302,9 +694,6 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 					if err != nil {
 						return nil, fmt.Errorf("ArtistSongs(%v): %w", id, err)
 					}
-					if artist.Code != 200 {
-						return nil, fmt.Errorf("ArtistSongs(%v) err: %+v", id, artist)
-					}
 					if len(artist.Songs) <= 0 {
 						log.Warn("ArtistSongs(%v) songs is empty", id)
 						break
@@ -335,9 +724,6 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 				if err != nil {
 					return nil, fmt.Errorf("Album(%v): %w", id, err)
 				}
-				if album.Code != 200 {
-					return nil, fmt.Errorf("Album(%v) err: %+v", id, album)
-				}
 				if len(album.Songs) <= 0 {
 					log.Warn("Album(%v) Songs is empty", id)
 					continue
@@ -364,9 +750,6 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 				if err != nil {
 					return nil, fmt.Errorf("PlaylistDetail(%v): %w", id, err)
 				}
-				if playlist.Code != 200 {
-					return nil, fmt.Errorf("PlaylistDetail(%v) err: %+v", id, playlist)
-				}
 				if playlist.Playlist.TrackIds == nil {
 					log.Warn("PlaylistDetail(%v) Tracks is nil", id)
 					continue
@@ -391,50 +774,35 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 					}
 				}
 
-				// 分页处理
-				pages, _ := utils.SplitSlice(tmp, 500)
-				for _, p := range pages {
-					// 检查这些 ID 是否都在 trackMap 中
-					var missingIds []int64
-					for _, id := range p {
-						if _, ok := trackMap[id]; !ok {
-							missingIds = append(missingIds, id)
-						} else {
-							list = append(list, trackMap[id])
-						}
+				// 补全 Tracks 中缺失的歌曲详情
+				var missingIds []int64
+				for _, id := range tmp {
+					if _, ok := trackMap[id]; !ok {
+						missingIds = append(missingIds, id)
 					}
-
-					if len(missingIds) == 0 {
-						continue
-					}
-
-					var c = make([]weapi.SongDetailReqList, 0, len(missingIds))
-					for _, v := range missingIds {
-						c = append(c, weapi.SongDetailReqList{Id: fmt.Sprintf("%v", v), V: 0})
-					}
-					resp, err := request.SongDetail(ctx, &weapi.SongDetailReq{C: c})
+				}
+				if len(missingIds) > 0 {
+					resp, err := request.SongDetailBatch(ctx, missingIds)
 					if err != nil {
-						return nil, fmt.Errorf("SongDetail: %w", err)
-					}
-					if resp.Code != 200 {
-						return nil, fmt.Errorf("SongDetail err: %+v", resp)
-					}
-					if len(resp.Songs) <= 0 {
-						log.Warn("SongDetail Songs is empty")
-						continue
+						return nil, fmt.Errorf("SongDetailBatch: %w", err)
 					}
 					for _, v := range resp.Songs {
-						list = append(list, Music{
+						trackMap[v.Id] = Music{
 							Id:      v.Id,
 							Name:    v.Name,
 							Artist:  v.Ar,
 							Album:   v.Al,
 							AlbumId: v.Al.Id,
 							Time:    v.Dt,
-						})
+						}
+					}
+				}
+				for _, id := range tmp {
+					if v, ok := trackMap[id]; ok {
+						list = append(list, v)
 					}
-					// todo: 处理版权,状态等有效性校验
 				}
+				// todo: 处理版权,状态等有效性校验
 			}
 		default:
 			return nil, fmt.Errorf("[%s] is not support", k)
@@ -446,63 +814,100 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 	return list, nil
 }
 
-func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi.Api, music *Music, pool *pb.Pool) error {
-	var (
-		songId    = music.Id
-		songIdStr = fmt.Sprintf("%d", songId)
-	)
+// songUrl 获取歌曲下载地址,歌曲无版权、已下架或无音源时返回的错误包含 errSongUnavailable
+func (c *Download) songUrl(ctx context.Context, cli *api.Client, request *weapi.Api, songId int64) (*eapi.SongPlayerV1RespData, error) {
+	var songIdStr = fmt.Sprintf("%d", songId)
 
 	// 查询音乐支持哪些音质
 	qualityResp, err := request.SongMusicQuality(ctx, &weapi.SongMusicQualityReq{SongId: songIdStr})
 	if err != nil {
-		return fmt.Errorf("SongMusicQuality(%v): %w", songId, err)
+		if _, ok := api.AsError(err); ok {
+			return nil, fmt.Errorf("%w: SongMusicQuality(%v): %s", errSongUnavailable, songId, err)
+		}
+		return nil, fmt.Errorf("SongMusicQuality(%v): %w", songId, err)
 	}
-	if qualityResp.Code != 200 {
-		return fmt.Errorf("SongMusicQuality(%v) err: %+v", songId, qualityResp)
+	var want = types.Level(c.opts.Level)
+	quality, level, ok := qualityResp.Data.Qualities.FindBetter(want)
+	if c.opts.PreferSpatial {
+		if q, lv, has := qualityResp.Data.Qualities.FindSpatial(); has {
+			quality, level, ok, want = q, lv, true, lv
+		}
 	}
-	quality, level, ok := qualityResp.Data.Qualities.FindBetter(types.Level(c.opts.Level))
 	log.Debug("SongMusicQuality(%v) quality level=%s info=%+v", songId, types.LevelString[level], quality)
 	if !ok && c.opts.Strict {
-		return fmt.Errorf("SongMusicQuality(%v) not support %v", songId, types.Level(c.opts.Level))
+		return nil, fmt.Errorf("%w: SongMusicQuality(%v) not support %v", errSongSkipped, songId, types.Level(c.opts.Level))
 	}
 
-	// 获取下载链接地址
-	var downReq = &weapi.SongPlayerV1Req{
+	// 获取下载链接地址,eapi版本可获取hires、空间音频、超清母带等全部音质
+	var downReq = &eapi.SongPlayerV1Req{
 		Ids:         types.IntsString{songId},
-		Level:       types.Level(c.opts.Level),
+		Level:       want,
 		EncodeType:  c.opts.EncodeType,
 		ImmerseType: c.opts.ImmerseType,
 	}
-	downResp, err := request.SongPlayerV1(ctx, downReq)
+	downResp, err := eapi.New(cli).SongPlayerV1(ctx, downReq)
 	if err != nil {
-		return fmt.Errorf("SongPlayerV1(%v): %w", songId, err)
-	}
-	if downResp.Code != 200 {
-		return fmt.Errorf("SongPlayerV1(%v) err: %+v", songId, downResp)
+		return nil, fmt.Errorf("SongPlayerV1(%v): %w", songId, err)
 	}
 	if len(downResp.Data) <= 0 {
-		return fmt.Errorf("SongPlayerV1(%v) is empty: %+v", songId, downResp)
+		return nil, fmt.Errorf("SongPlayerV1(%v) is empty: %+v", songId, downResp)
 	}
 	// 歌曲变灰则不能下载
 	if downResp.Data[0].Code != 200 || downResp.Data[0].Url == "" {
 		var msg error
 		switch downResp.Data[0].Code {
 		case -110:
-			msg = fmt.Errorf("无音源(%v) br: %v code: %v", songId, quality.Br, downResp.Data[0].Code)
+			msg = fmt.Errorf("%w: 无音源(%v) br: %v code: %v", errSongUnavailable, songId, quality.Br, downResp.Data[0].Code)
 		case -105: // todo: 待确定完善,目前测试发现,当用户没有会员权益时,会返回-105，其他情况可能也会返回此值
 			fallthrough
 		default:
-			msg = fmt.Errorf("资源已下架或无版权(%v) br: %v code: %v", songId, quality.Br, downResp.Data[0].Code)
+			msg = fmt.Errorf("%w: 资源已下架或无版权(%v) br: %v code: %v", errSongUnavailable, songId, quality.Br, downResp.Data[0].Code)
 		}
 		log.Warn("资源已下架或无版权(%v) detail: %+v", songId, downResp)
-		return msg
+		return nil, msg
+	}
+	// 没有空间音频权益时服务端会返回较低音质
+	if want.Spatial() && !types.Level(downResp.Data[0].Level).Spatial() {
+		log.Warn("song(%v) want %s but got %s, maybe not entitled", songId, want, downResp.Data[0].Level)
+	}
+	return &downResp.Data[0], nil
+}
+
+func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi.Api, music *Music, pool *pb.Pool, result *downloadResult) error {
+	result.Source = "netease"
+	drd, err := c.songUrl(ctx, cli, request, music.Id)
+	if err != nil {
+		if c.unlocker == nil || !errors.Is(err, errSongUnavailable) {
+			return err
+		}
+		// 从第三方平台查找替代音源
+		stream, uerr := c.unlocker.Find(ctx, unlockSong(music))
+		if uerr != nil {
+			log.Warn("unlock song(%v) err: %s", music.Id, uerr)
+			return err
+		}
+		log.Info("unlock song(%v) %s from %s(%s)", music.Id, music.NameString(), stream.Provider, stream.Id)
+		result.Source = stream.Provider
+		drd = &eapi.SongPlayerV1RespData{
+			Id:   music.Id,
+			Url:  stream.Url,
+			Br:   stream.Br,
+			Size: stream.Size,
+			Md5:  stream.Md5,
+			Type: stream.Type,
+			Code: 200,
+		}
 	}
 
 	var (
-		drd      = downResp.Data[0]
-		dest     = filepath.Join(c.opts.Output, fmt.Sprintf("%s - %s.%s", music.ArtistString(), music.NameString(), strings.ToLower(drd.Type)))
+		filename = fmt.Sprintf("%s - %s", music.ArtistString(), music.NameString())
 		tempName = fmt.Sprintf("download-*-%s.tmp", music.NameString())
 	)
+	if music.Filename != "" {
+		filename = music.Filename
+	}
+	var dest = filepath.Join(c.opts.Output, fmt.Sprintf("%s.%s", filename, strings.ToLower(drd.Type)))
+	result.Level, result.Size = drd.Level, drd.Size
 
 	// 创建临时文件
 	file, err := os.CreateTemp(c.opts.Output, tempName)
@@ -512,44 +917,30 @@ func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi
 	defer file.Close()
 
 	// 下载
-	bar := pb.New64(drd.Size).
-		Set(pb.Bytes, true).
-		Set("prefix", fixedWidthName(fmt.Sprintf("%s - %s", music.ArtistString(), music.NameString()), barNameWidth)).
-		SetTemplateString(downloadBarTemplate)
-	pool.Add(bar)
-	defer bar.Finish()
-
-	resp, err := cli.Download(ctx, drd.Url, nil, nil, file, bar)
-	if err != nil {
-		_ = os.Remove(file.Name())
-		return fmt.Errorf("download: %w", err)
-	}
-	if c.root.Opts.Debug {
-		dump, err := httputil.DumpResponse(resp, false)
-		if err != nil {
-			log.Debug("DumpResponse err: %s", err)
-		} else {
-			log.Debug("Download DumpResponse: %s", dump)
+	var bar *pb.ProgressBar
+	if pool != nil {
+		var name = fmt.Sprintf("%s - %s", music.ArtistString(), music.NameString())
+		if n := jobNumberFrom(ctx); n > 0 {
+			// 显示序号以便通过快捷键取消
+			name = fmt.Sprintf("#%d %s", n, name)
 		}
+		bar = newDownloadBar(name, drd.Size)
+		pool.Add(bar)
+		defer bar.Finish()
 	}
 
-	size, _ := strconv.ParseFloat(resp.Header.Get("Content-Length"), 10)
-	log.Debug("id=%v downloadUrl=%v wantLevel=%v-%v realLevel=%v-%v encodeType=%v type=%v size=%0.2fM,%vKB free=%v tempFile=%s outDir=%s",
-		drd.Id, drd.Url, c.opts.Level, quality.Br, drd.Level, drd.Br, drd.EncodeType, drd.Type, size/float64(utils.MB), int64(size), types.Free(drd.Fee), file.Name(), dest)
-
-	// 校验md5文件完整性
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		_ = os.Remove(file.Name())
-		return fmt.Errorf("Seek: %w", err)
-	}
-	var m = md5.New()
-	if _, err := io.Copy(m, file); err != nil {
-		_ = os.Remove(file.Name())
-		return err
-	}
-	if m := hex.EncodeToString(m.Sum(nil)); m != drd.Md5 {
-		_ = os.Remove(file.Name())
-		return fmt.Errorf("file %v md5 not match, want=%s, got=%s", file.Name(), drd.Md5, m)
+	// 传输过程中文件损坏导致md5不一致时重新下载
+	var w = c.batch.writer(file, drd.Size)
+	for attempt := 1; ; attempt++ {
+		err := c.fetch(ctx, cli, drd, file, w, bar)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errMd5Mismatch) || attempt >= downloadAttempts || ctx.Err() != nil {
+			_ = os.Remove(file.Name())
+			return err
+		}
+		log.Warn("song(%v) %s, retry %d/%d", music.Id, err, attempt, downloadAttempts-1)
 	}
 
 	// 设置歌曲tag值
@@ -567,6 +958,13 @@ func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi
 			Album:    music.Album.Name,
 			AlbumPic: music.Album.PicUrl,
 			Format:   drd.Type,
+			Track:    music.Track,
+		}
+		if music.PublishTime > 0 {
+			meta.Date = time.UnixMilli(music.PublishTime).Format(time.DateOnly)
+		}
+		if types.Level(drd.Level).Spatial() {
+			meta.ChannelLayout = channelLayout(drd.ChannelLayout)
 		}
 		for _, ar := range music.Artist {
 			meta.Artists = append(meta.Artists, ncm.Artist{Name: ar.Name, Id: ar.Id})
@@ -576,7 +974,7 @@ func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi
 		lyricResp, err := request.Lyric(ctx, &weapi.LyricReq{Id: music.Id})
 		if err != nil {
 			log.Warn("get lyric %d err: %v", music.Id, err)
-		} else if lyricResp.Code == 200 {
+		} else {
 			if lyricResp.Lrc.Lyric != "" {
 				// todo: 翻译歌词
 				meta.Comment = lyricResp.Lrc.Lyric
@@ -591,29 +989,22 @@ func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi
 			if idx := strings.Index(meta.AlbumPic, "?"); idx > 0 {
 				meta.AlbumPic = meta.AlbumPic[:idx]
 			}
-			resp, err := http.Get(meta.AlbumPic)
-			if err == nil && resp.StatusCode == 200 {
-				coverData, _ = io.ReadAll(resp.Body)
-				resp.Body.Close()
-			} else {
-				log.Warn("download cover %s err: %v", meta.AlbumPic, err)
+			coverData, err = fetchCover(ctx, meta.AlbumPic)
+			if err != nil {
+				log.Warn("download cover %s trace=%s err: %v", meta.AlbumPic, api.TraceId(ctx), err)
 			}
 		}
 
 		if len(coverData) == 0 {
 			if music.AlbumId != 0 {
 				albumResp, err := request.Album(ctx, &weapi.AlbumReq{Id: fmt.Sprintf("%d", music.AlbumId)})
-				if err == nil && albumResp.Code == 200 && albumResp.Album.PicUrl != "" {
+				if err == nil && albumResp.Album.PicUrl != "" {
 					meta.AlbumPic = albumResp.Album.PicUrl
 					// 移除 URL 中的 query 参数，通常能获取到原图
 					if idx := strings.Index(meta.AlbumPic, "?"); idx > 0 {
 						meta.AlbumPic = meta.AlbumPic[:idx]
 					}
-					resp, err := http.Get(meta.AlbumPic)
-					if err == nil && resp.StatusCode == 200 {
-						coverData, _ = io.ReadAll(resp.Body)
-						resp.Body.Close()
-					}
+					coverData, _ = fetchCover(ctx, meta.AlbumPic)
 				}
 			}
 		}
@@ -645,5 +1036,87 @@ func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi
 	if err := os.Chmod(dest, 0644); err != nil {
 		return fmt.Errorf("chmod: %w", err)
 	}
+	result.File = dest
+	if s := jobStatsFrom(ctx); s != nil {
+		s.Songs.Add(1)
+		s.Bytes.Add(drd.Size)
+	}
+	c.root.monitor.downloaded(drd.Size)
+
+	// 记录写入tag后最终文件的校验和,供 verify 命令校验文件是否损坏
+	var alg = checksum.Algorithm(c.opts.Checksum)
+	sum, err := checksum.Sum(dest, alg)
+	if err != nil {
+		log.Warn("checksum %s err: %s", dest, err)
+	} else if err := checksum.Append(c.opts.Output, filepath.Base(dest), alg, sum); err != nil {
+		log.Warn("record checksum %s err: %s", dest, err)
+	}
+
+	// hook命令执行失败不影响下载结果
+	var vars = hookVars{
+		Id:      music.Id,
+		File:    dest,
+		Dir:     filepath.Dir(dest),
+		Title:   music.NameString(),
+		Artist:  music.ArtistString(),
+		Album:   music.Album.Name,
+		Quality: drd.Level,
+	}
+	if err := runHook(ctx, c.opts.ExecAfter, vars); err != nil {
+		log.Warn("[hook] song(%v) %s", music.Id, err)
+	}
 	return nil
 }
+
+// fetch 下载歌曲到file并校验md5,w为写入file的writer
+func (c *Download) fetch(ctx context.Context, cli *api.Client, drd *eapi.SongPlayerV1RespData, file *os.File, w io.Writer, bar *pb.ProgressBar) error {
+	// 重新下载时清空之前写入的内容
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("Truncate: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("Seek: %w", err)
+	}
+	if bar != nil {
+		bar.SetCurrent(0)
+	}
+
+	resp, err := cli.Download(ctx, drd.Url, nil, nil, w, bar)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	if c.root.Opts.Debug {
+		dump, err := httputil.DumpResponse(resp, false)
+		if err != nil {
+			log.Debug("DumpResponse err: %s", err)
+		} else {
+			log.Debug("Download DumpResponse: %s", dump)
+		}
+	}
+
+	size, _ := strconv.ParseFloat(resp.Header.Get("Content-Length"), 10)
+	log.Debug("id=%v downloadUrl=%v wantLevel=%v realLevel=%v-%v encodeType=%v type=%v size=%0.2fM,%vKB free=%v tempFile=%s outDir=%s",
+		drd.Id, drd.Url, c.opts.Level, drd.Level, drd.Br, drd.EncodeType, drd.Type, size/float64(utils.MB), int64(size), types.Free(drd.Fee), file.Name(), c.opts.Output)
+
+	// 校验md5文件完整性
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("Seek: %w", err)
+	}
+	var m = md5.New()
+	if _, err := io.Copy(m, file); err != nil {
+		return err
+	}
+	if m := hex.EncodeToString(m.Sum(nil)); drd.Md5 != "" && m != drd.Md5 {
+		return fmt.Errorf("%w: file %v want=%s, got=%s", errMd5Mismatch, file.Name(), drd.Md5, m)
+	}
+	return nil
+}
+
+// unlockSong 转换为第三方音源查找使用的歌曲信息
+func unlockSong(music *Music) unlock.Song {
+	var song = unlock.Song{Name: music.Name, Album: music.Album.Name, Duration: music.Time}
+	for _, ar := range music.Artist {
+		song.Artists = append(song.Artists, ar.Name)
+	}
+	return song
+}
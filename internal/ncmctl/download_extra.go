@@ -2,7 +2,9 @@ package ncmctl
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"image"
 	"image/jpeg"
 	_ "image/png" // register png decoder
@@ -17,6 +19,26 @@ import (
 	_ "golang.org/x/image/webp" // register webp decoder
 )
 
+// songIdTag 记录网易云歌曲id的自定义标签名,mp3写入TXXX帧,flac写入vorbis comment
+const songIdTag = "NCM_ID"
+
+// fetchCover 下载封面图片
+func fetchCover(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http status code: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
 // ensureJpeg 确保图片数据为 JPEG 格式
 func ensureJpeg(data []byte) ([]byte, error) {
 	if len(data) == 0 {
@@ -57,6 +79,17 @@ func writeID3v2(filePath string, meta *ncm.MetadataMusic, coverData []byte) erro
 	}
 	tag.SetArtist(strings.Join(artists, "/"))
 	tag.SetAlbum(meta.Album)
+	if meta.Track > 0 {
+		tag.AddTextFrame(tag.CommonID("Track number/Position in set"), tag.DefaultEncoding(), fmt.Sprintf("%d", meta.Track))
+	}
+	if meta.Date != "" {
+		// ID3v2.3 的 TYER 只支持年份
+		var date = meta.Date
+		if tag.Version() < 4 && len(date) > 4 {
+			date = date[:4]
+		}
+		tag.SetYear(date)
+	}
 
 	if meta.Comment != "" {
 		uslt := id3v2.UnsynchronisedLyricsFrame{
@@ -68,6 +101,22 @@ func writeID3v2(filePath string, meta *ncm.MetadataMusic, coverData []byte) erro
 		tag.AddUnsynchronisedLyricsFrame(uslt)
 	}
 
+	if meta.Id != 0 {
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    id3v2.EncodingUTF8,
+			Description: songIdTag,
+			Value:       fmt.Sprintf("%d", meta.Id),
+		})
+	}
+
+	if meta.ChannelLayout != "" {
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    id3v2.EncodingUTF8,
+			Description: "CHANNEL_LAYOUT",
+			Value:       meta.ChannelLayout,
+		})
+	}
+
 	if len(coverData) > 0 {
 		jpegData, err := ensureJpeg(coverData)
 		if err != nil {
@@ -128,9 +177,21 @@ func writeFlac(filePath string, meta *ncm.MetadataMusic, coverData []byte) error
 	cmts.Add(flacvorbis.FIELD_TITLE, meta.Name)
 	cmts.Add(flacvorbis.FIELD_ARTIST, strings.Join(artists, "/"))
 	cmts.Add(flacvorbis.FIELD_ALBUM, meta.Album)
+	if meta.Track > 0 {
+		cmts.Add(flacvorbis.FIELD_TRACKNUMBER, fmt.Sprintf("%d", meta.Track))
+	}
+	if meta.Date != "" {
+		cmts.Add(flacvorbis.FIELD_DATE, meta.Date)
+	}
 	if meta.Comment != "" {
 		cmts.Add("LYRICS", meta.Comment)
 	}
+	if meta.Id != 0 {
+		cmts.Add(songIdTag, fmt.Sprintf("%d", meta.Id))
+	}
+	if meta.ChannelLayout != "" {
+		cmts.Add("CHANNEL_LAYOUT", meta.ChannelLayout)
+	}
 
 	res := cmts.Marshal()
 
@@ -0,0 +1,427 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"cmp"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/checksum"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/spf13/cobra"
+)
+
+// mvResolutions 支持的MV分辨率
+var mvResolutions = []int64{240, 480, 720, 1080}
+
+// lrcTime 匹配lrc歌词的时间标签,例如: [01:02.34]
+var lrcTime = regexp.MustCompile(`\[(\d+):(\d+)(?:[.:](\d+))?]`)
+
+type downloadMvCmd struct {
+	root *Download
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	resolution int64  // 分辨率
+	lyric      string // 作为字幕的歌曲id或链接
+	mux        bool   // 使用ffmpeg将字幕封装进mp4文件
+}
+
+func downloadMv(root *Download, l *log.Logger) *cobra.Command {
+	c := &downloadMvCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "mv <mvId|link>...",
+		Short: "Download MVs",
+		Long: "Download MVs as <artist> - <name>.mp4, unfinished downloads are kept as .part files and resumed\n" +
+			"next time. --lyric converts the lyric of the given song into an .srt subtitle next to the video,\n" +
+			"with --mux the subtitle is embedded into the mp4 by ffmpeg.",
+		Example: "  ncmctl download mv 14572641\n" +
+			"  ncmctl download mv https://music.163.com/#/mv?id=14572641 -r 720\n" +
+			"  ncmctl download mv 14572641 --lyric 1901371647 --mux",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *downloadMvCmd) addFlags() {
+	c.cmd.Flags().Int64VarP(&c.resolution, "resolution", "r", 1080, "max video resolution. support: 1080、720、480、240, falls back to the closest lower one")
+	c.cmd.Flags().StringVar(&c.lyric, "lyric", "", "song id or link whose lyric is saved as an .srt subtitle next to the video")
+	c.cmd.Flags().BoolVar(&c.mux, "mux", false, "embed the --lyric subtitle into the mp4 file, requires ffmpeg")
+}
+
+func (c *downloadMvCmd) validate() error {
+	if err := c.root.validate(); err != nil {
+		return err
+	}
+	if !slices.Contains(mvResolutions, c.resolution) {
+		return fmt.Errorf("%d resolution is not support", c.resolution)
+	}
+	if c.mux {
+		if c.lyric == "" {
+			return fmt.Errorf("--mux requires --lyric")
+		}
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			return fmt.Errorf("--mux requires ffmpeg: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *downloadMvCmd) execute(ctx context.Context, args []string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	var ids = make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			kind, v, err := Parse(arg)
+			if err != nil {
+				return fmt.Errorf("Parse(%s): %w", arg, err)
+			}
+			if kind != "mv" {
+				return fmt.Errorf("%s is not a mv link", arg)
+			}
+			id = v
+		}
+		ids = append(ids, id)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	var subtitle string
+	if c.lyric != "" {
+		songs, err := parseSongIds([]string{c.lyric})
+		if err != nil {
+			return err
+		}
+		resp, err := request.Lyric(ctx, &weapi.LyricReq{Id: songs[0]})
+		if err != nil {
+			return fmt.Errorf("Lyric: %w", err)
+		}
+		if subtitle = lrcToSrt(resp.Lrc.Lyric); subtitle == "" {
+			return fmt.Errorf("song %d has no timed lyric", songs[0])
+		}
+	}
+
+	if err := utils.MkdirIfNotExist(c.root.opts.Output, 0755); err != nil {
+		return fmt.Errorf("MkdirIfNotExist: %w", err)
+	}
+
+	var pool *pb.Pool
+	if progressMode(c.root.opts.Progress) == progressBar {
+		// 进度条显示期间暂存终端日志,进度条结束后再输出
+		defer log.Default.Hold()()
+		if pool, err = startPool(); err != nil {
+			return fmt.Errorf("StartPool: %w", err)
+		}
+		defer pool.Stop()
+	}
+
+	var failed int
+	for _, id := range ids {
+		dest, err := c.download(ctx, cli, request, id, pool)
+		if err != nil {
+			log.Error("download mv(%d) err: %s", id, err)
+			failed++
+			continue
+		}
+		if subtitle != "" {
+			if err := c.subtitle(ctx, dest, subtitle); err != nil {
+				log.Warn("mv(%d) subtitle: %s", id, err)
+			}
+		}
+		log.Info("mv(%d) saved to %s", id, dest)
+	}
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d mvs failed", failed, len(ids))}
+	}
+	return nil
+}
+
+// download 获取MV指定分辨率的地址并下载,返回文件路径
+func (c *downloadMvCmd) download(ctx context.Context, cli *api.Client, request *weapi.Api, id int64, pool *pb.Pool) (string, error) {
+	detail, err := request.MvDetail(ctx, &weapi.MvDetailReq{Id: strconv.FormatInt(id, 10)})
+	if err != nil {
+		return "", fmt.Errorf("MvDetail: %w", err)
+	}
+	var mv = detail.Data
+
+	var brs = make([]int64, 0, len(mv.Brs))
+	for _, br := range mv.Brs {
+		brs = append(brs, br.Br)
+	}
+	resp, err := request.MvUrl(ctx, &weapi.MvUrlReq{Id: strconv.FormatInt(id, 10), R: pickResolution(brs, c.resolution)})
+	if err != nil {
+		return "", fmt.Errorf("MvUrl: %w", err)
+	}
+	var data = resp.Data
+	if data.Code != 200 || data.Url == "" {
+		return "", fmt.Errorf("mv unavailable: code=%d msg=%s", data.Code, data.Msg)
+	}
+
+	return c.root.saveVideo(ctx, cli, videoFile{
+		Id:   strconv.FormatInt(id, 10),
+		Name: fmt.Sprintf("%s - %s", mv.ArtistName, mv.Name),
+		Url:  data.Url,
+		R:    data.R,
+		Size: data.Size,
+		Md5:  data.Md5,
+	}, pool)
+}
+
+// pickResolution 选择不超过limit的最高分辨率,都超过时使用最低分辨率,brs为空时返回limit
+func pickResolution(brs []int64, limit int64) int64 {
+	var r int64
+	for _, br := range brs {
+		if br <= limit && br > r {
+			r = br
+		}
+	}
+	if r == 0 && len(brs) > 0 {
+		r = slices.Min(brs)
+	}
+	if r == 0 {
+		r = limit
+	}
+	return r
+}
+
+// videoFile 待下载的视频,MV、视频及Mlog共用
+type videoFile struct {
+	Id   string
+	Name string // 不含扩展名的文件名
+	Url  string
+	R    int64 // 分辨率
+	Size int64
+	Md5  string
+}
+
+// saveVideo 下载视频到输出目录并返回文件路径,未完成的下载保存为.part文件,再次下载时从断点继续
+func (c *Download) saveVideo(ctx context.Context, cli *api.Client, v videoFile, pool *pb.Pool) (string, error) {
+	var (
+		dest = filepath.Join(c.opts.Output, utils.Filename(v.Name, "_")+".mp4")
+		part = dest + ".part"
+	)
+	if stat, err := os.Stat(dest); err == nil && (v.Size <= 0 || stat.Size() == v.Size) {
+		log.Info("video(%s) %s already exists, skip", v.Id, dest)
+		return dest, nil
+	}
+	log.Debug("video(%s) resolution=%d size=%d url=%s", v.Id, v.R, v.Size, v.Url)
+
+	var bar *pb.ProgressBar
+	if pool != nil {
+		bar = newDownloadBar(fmt.Sprintf("%s %dP", v.Name, v.R), v.Size)
+		pool.Add(bar)
+		defer bar.Finish()
+	}
+	if err := fetchVideo(ctx, cli, v, part, bar); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(part, dest); err != nil {
+		return "", fmt.Errorf("rename: %w", err)
+	}
+	if err := os.Chmod(dest, 0644); err != nil {
+		return "", fmt.Errorf("chmod: %w", err)
+	}
+	if s := jobStatsFrom(ctx); s != nil {
+		s.Bytes.Add(v.Size)
+	}
+	c.root.monitor.downloaded(v.Size)
+
+	// 记录文件校验和,供 verify 命令校验文件是否损坏
+	var alg = checksum.Algorithm(c.opts.Checksum)
+	sum, err := checksum.Sum(dest, alg)
+	if err != nil {
+		log.Warn("checksum %s err: %s", dest, err)
+	} else if err := checksum.Append(c.opts.Output, filepath.Base(dest), alg, sum); err != nil {
+		log.Warn("record checksum %s err: %s", dest, err)
+	}
+	return dest, nil
+}
+
+// fetchVideo 下载到part文件,part文件已存在时通过Range从断点继续下载,服务端不支持Range时重新下载
+func fetchVideo(ctx context.Context, cli *api.Client, v videoFile, part string, bar *pb.ProgressBar) error {
+	file, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("OpenFile: %w", err)
+	}
+	defer file.Close()
+
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("Seek: %w", err)
+	}
+	if v.Size > 0 && offset >= v.Size {
+		offset = 0
+	}
+
+	for {
+		if err := file.Truncate(offset); err != nil {
+			return fmt.Errorf("Truncate: %w", err)
+		}
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("Seek: %w", err)
+		}
+		if bar != nil {
+			bar.SetCurrent(offset)
+		}
+		var headers = map[string]string{"Range": fmt.Sprintf("bytes=%d-", offset)}
+		resp, err := cli.Download(ctx, v.Url, headers, nil, file, bar)
+		if err != nil {
+			return fmt.Errorf("download: %w", err)
+		}
+		if offset > 0 && resp.StatusCode != http.StatusPartialContent {
+			log.Warn("video(%s) server does not support resume, download again", v.Id)
+			offset = 0
+			continue
+		}
+		break
+	}
+
+	if v.Md5 == "" {
+		return nil
+	}
+	f, err := os.Open(part)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var m = md5.New()
+	if _, err := io.Copy(m, f); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(m.Sum(nil)); !strings.EqualFold(sum, v.Md5) {
+		// 文件已损坏无法继续下载,删除后下次重新下载
+		_ = file.Close()
+		_ = os.Remove(part)
+		return fmt.Errorf("%w: want=%s, got=%s", errMd5Mismatch, v.Md5, sum)
+	}
+	return nil
+}
+
+// subtitle 将字幕保存为视频同名的.srt文件,需要时使用ffmpeg封装进mp4
+func (c *downloadMvCmd) subtitle(ctx context.Context, dest, srt string) error {
+	var file = strings.TrimSuffix(dest, filepath.Ext(dest)) + ".srt"
+	if err := os.WriteFile(file, []byte(srt), 0644); err != nil {
+		return err
+	}
+	if !c.mux {
+		return nil
+	}
+
+	var temp = strings.TrimSuffix(dest, filepath.Ext(dest)) + ".mux.mp4"
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-loglevel", "error", "-i", dest, "-i", file,
+		"-map", "0", "-map", "1", "-c", "copy", "-c:s", "mov_text", temp)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(temp)
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if err := os.Rename(temp, dest); err != nil {
+		_ = os.Remove(temp)
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
+// lrcToSrt 将lrc歌词转换为srt字幕,每行歌词显示到下一行歌词开始,最后一行显示5秒。没有时间标签的行被忽略
+func lrcToSrt(lrc string) string {
+	type cue struct {
+		start time.Duration
+		text  string
+	}
+	var cues []cue
+	for _, line := range strings.Split(lrc, "\n") {
+		var tags = lrcTime.FindAllStringSubmatchIndex(line, -1)
+		if len(tags) <= 0 {
+			continue
+		}
+		var text = strings.TrimSpace(line[tags[len(tags)-1][1]:])
+		if text == "" {
+			continue
+		}
+		for _, tag := range tags {
+			var (
+				m, _ = strconv.ParseInt(line[tag[2]:tag[3]], 10, 64)
+				s, _ = strconv.ParseInt(line[tag[4]:tag[5]], 10, 64)
+				t    = time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+			)
+			if tag[6] >= 0 {
+				// 小数部分位数不固定,例如: .3 .34 .345
+				var frac = line[tag[6]:tag[7]]
+				ms, _ := strconv.ParseInt((frac + "00")[:3], 10, 64)
+				t += time.Duration(ms) * time.Millisecond
+			}
+			cues = append(cues, cue{start: t, text: text})
+		}
+	}
+	slices.SortStableFunc(cues, func(a, b cue) int { return cmp.Compare(a.start, b.start) })
+
+	var (
+		b      strings.Builder
+		format = func(d time.Duration) string {
+			return fmt.Sprintf("%02d:%02d:%02d,%03d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000)
+		}
+	)
+	for i, v := range cues {
+		var end = v.start + 5*time.Second
+		if i+1 < len(cues) && cues[i+1].start > v.start {
+			end = cues[i+1].start
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, format(v.start), format(end), v.text)
+	}
+	return b.String()
+}
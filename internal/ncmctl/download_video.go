@@ -0,0 +1,284 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// videoIdReg 视频id为32位十六进制字符串,其他字符串视为Mlog id
+	videoIdReg = regexp.MustCompile(`^[0-9A-Fa-f]{32}$`)
+	// videoLinkReg 匹配视频及Mlog链接中的id 例如: https://music.163.com/#/video?id=89ADDE33C0AAE8EC14B99F6750DB954D
+	videoLinkReg = regexp.MustCompile(`(?:video|mlog)[^?]*\?(?:.*&)?id=([0-9A-Za-z]+)`)
+)
+
+type downloadVideoCmd struct {
+	root *Download
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	resolution int64  // 分辨率
+	artist     string // 下载歌手的视频
+	limit      int64  // 下载歌手最新的视频数量
+}
+
+func downloadVideo(root *Download, l *log.Logger) *cobra.Command {
+	c := &downloadVideoCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "video [videoId|mlogId|link]...",
+		Short: "Download videos and Mlogs, such as concerts and live videos not available as MV",
+		Long: "Download videos and Mlogs as <creator> - <title>.mp4, unfinished downloads are kept as .part files\n" +
+			"and resumed next time. --artist downloads the latest videos of the artist.",
+		Example: "  ncmctl download video 89ADDE33C0AAE8EC14B99F6750DB954D\n" +
+			"  ncmctl download video 'https://music.163.com/#/video?id=89ADDE33C0AAE8EC14B99F6750DB954D' -r 720\n" +
+			"  ncmctl download video --artist 6452 -n 20",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *downloadVideoCmd) addFlags() {
+	c.cmd.Flags().Int64VarP(&c.resolution, "resolution", "r", 1080, "max video resolution. support: 1080、720、480、240, falls back to the closest lower one")
+	c.cmd.Flags().StringVar(&c.artist, "artist", "", "artist id or link, download the latest videos of the artist")
+	c.cmd.Flags().Int64VarP(&c.limit, "limit", "n", 10, "number of the latest artist videos to download")
+}
+
+func (c *downloadVideoCmd) validate(args []string) error {
+	if err := c.root.validate(); err != nil {
+		return err
+	}
+	if len(args) == 0 && c.artist == "" {
+		return fmt.Errorf("input is empty, please enter the video id, mlog id, link or --artist")
+	}
+	if !slices.Contains(mvResolutions, c.resolution) {
+		return fmt.Errorf("%d resolution is not support", c.resolution)
+	}
+	if c.limit <= 0 {
+		return fmt.Errorf("limit must be > 0")
+	}
+	return nil
+}
+
+func (c *downloadVideoCmd) execute(ctx context.Context, args []string) error {
+	if err := c.validate(args); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	var ids = make([]string, 0, len(args))
+	for _, arg := range args {
+		if m := videoLinkReg.FindStringSubmatch(arg); m != nil {
+			arg = m[1]
+		}
+		ids = append(ids, arg)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if c.artist != "" {
+		list, err := c.artistVideos(ctx, request)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, list...)
+	}
+
+	if err := utils.MkdirIfNotExist(c.root.opts.Output, 0755); err != nil {
+		return fmt.Errorf("MkdirIfNotExist: %w", err)
+	}
+
+	var pool *pb.Pool
+	if progressMode(c.root.opts.Progress) == progressBar {
+		// 进度条显示期间暂存终端日志,进度条结束后再输出
+		defer log.Default.Hold()()
+		if pool, err = startPool(); err != nil {
+			return fmt.Errorf("StartPool: %w", err)
+		}
+		defer pool.Stop()
+	}
+
+	var failed int
+	for _, id := range ids {
+		v, err := c.resolve(ctx, request, id)
+		if err != nil {
+			log.Error("resolve video(%s) err: %s", id, err)
+			failed++
+			continue
+		}
+		dest, err := c.root.saveVideo(ctx, cli, *v, pool)
+		if err != nil {
+			log.Error("download video(%s) err: %s", id, err)
+			failed++
+			continue
+		}
+		log.Info("video(%s) saved to %s", id, dest)
+	}
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d videos failed", failed, len(ids))}
+	}
+	return nil
+}
+
+// artistVideos 分页获取歌手最新的视频id
+func (c *downloadVideoCmd) artistVideos(ctx context.Context, request *weapi.Api) ([]string, error) {
+	id, err := parseFollowId("artist", c.artist)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ids    []string
+		cursor = json.RawMessage("0")
+	)
+	for int64(len(ids)) < c.limit {
+		var size = min(c.limit-int64(len(ids)), 50)
+		resp, err := request.ArtistVideo(ctx, &weapi.ArtistVideoReq{
+			ArtistId: strconv.FormatInt(id, 10),
+			Page:     fmt.Sprintf(`{"size":%d,"cursor":%s}`, size, cursor),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ArtistVideo: %w", err)
+		}
+		for _, r := range resp.Data.Records {
+			var vid = r.Resource.MlogBaseData.Id
+			if vid == "" {
+				vid = r.Id
+			}
+			ids = append(ids, vid)
+		}
+		if !resp.Data.Page.More || len(resp.Data.Records) <= 0 || len(resp.Data.Page.Cursor) <= 0 {
+			break
+		}
+		cursor = resp.Data.Page.Cursor
+	}
+	if int64(len(ids)) > c.limit {
+		ids = ids[:c.limit]
+	}
+	log.Info("artist(%d) %d videos", id, len(ids))
+	return ids, nil
+}
+
+// resolve 获取视频或Mlog的标题及播放地址,Mlog没有播放地址时转换为视频id获取
+func (c *downloadVideoCmd) resolve(ctx context.Context, request *weapi.Api, id string) (*videoFile, error) {
+	if videoIdReg.MatchString(id) {
+		return c.video(ctx, request, id)
+	}
+
+	resp, err := request.MlogDetail(ctx, &weapi.MlogDetailReq{Id: id, Resolution: c.resolution})
+	if err != nil {
+		return nil, fmt.Errorf("MlogDetail: %w", err)
+	}
+	var (
+		res  = resp.Data.Resource
+		info = res.Content.Video.UrlInfo
+	)
+	if info.Url == "" {
+		convert, err := request.MlogToVideo(ctx, &weapi.MlogToVideoReq{MlogId: id})
+		if err != nil {
+			return nil, fmt.Errorf("MlogToVideo: %w", err)
+		}
+		if convert.Data == "" {
+			return nil, fmt.Errorf("mlog has no video")
+		}
+		return c.video(ctx, request, convert.Data)
+	}
+
+	var title = res.Content.Title
+	if title == "" {
+		title = res.MlogBaseData.Text
+	}
+	return &videoFile{
+		Id:   id,
+		Name: videoName(res.UserProfile.Nickname, title, id),
+		Url:  info.Url,
+		R:    info.R,
+		Size: info.Size,
+	}, nil
+}
+
+// video 获取视频不超过指定分辨率的最高分辨率播放地址
+func (c *downloadVideoCmd) video(ctx context.Context, request *weapi.Api, id string) (*videoFile, error) {
+	detail, err := request.VideoDetail(ctx, &weapi.VideoDetailReq{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("VideoDetail: %w", err)
+	}
+
+	var brs = make([]int64, 0, len(detail.Data.Resolutions))
+	for _, r := range detail.Data.Resolutions {
+		brs = append(brs, r.Resolution)
+	}
+	ids, _ := json.Marshal([]string{id})
+	resp, err := request.VideoUrl(ctx, &weapi.VideoUrlReq{Ids: string(ids), Resolution: pickResolution(brs, c.resolution)})
+	if err != nil {
+		return nil, fmt.Errorf("VideoUrl: %w", err)
+	}
+	if len(resp.Urls) <= 0 || resp.Urls[0].Url == "" {
+		return nil, fmt.Errorf("video unavailable")
+	}
+	var u = resp.Urls[0]
+	return &videoFile{
+		Id:   id,
+		Name: videoName(detail.Data.Creator.Nickname, detail.Data.Title, id),
+		Url:  u.Url,
+		R:    u.R,
+		Size: u.Size,
+	}, nil
+}
+
+// videoName 生成视频文件名: 作者 - 标题,标题过长时截断,没有标题时使用id
+func videoName(creator, title, id string) string {
+	title = runewidth.Truncate(title, 80, "..")
+	if title == "" {
+		title = id
+	}
+	if creator == "" {
+		return title
+	}
+	return fmt.Sprintf("%s - %s", creator, title)
+}
@@ -0,0 +1,285 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/database"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+const sinceLastExport = "last-export"
+
+type exportChangedCmd struct {
+	root *Export
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	dir     string // 需要导出的音乐目录
+	since   string // 起始时间
+	archive string // 归档文件路径,为空则只列出文件
+	format  string // 归档格式 tar、zip
+}
+
+type changedFile struct {
+	path    string // 绝对路径
+	name    string // 相对于导出目录的路径
+	size    int64
+	modTime time.Time
+}
+
+func exportChanged(root *Export, l *log.Logger) *cobra.Command {
+	c := &exportChangedCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "changed",
+		Short: "List or archive music files added since the given point",
+		Example: "  ncmctl export changed --since 2024-06-01\n" +
+			"  ncmctl export changed --since last-export -a ./new.zip\n" +
+			"  ncmctl export changed -d ./download --since 1717200000 -a ./new.tar",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *exportChangedCmd) addFlags() {
+	c.cmd.Flags().StringVarP(&c.dir, "dir", "d", "./download", "music file directory")
+	c.cmd.Flags().StringVarP(&c.since, "since", "s", sinceLastExport, "start point. support unix timestamp、2006-01-02、2006-01-02 15:04:05、RFC3339 or 'last-export'")
+	c.cmd.Flags().StringVarP(&c.archive, "archive", "a", "", "archive file path, only list files when empty. the last export time is recorded after archiving")
+	c.cmd.Flags().StringVar(&c.format, "format", "", "archive format tar or zip. default detect by the archive file extension")
+}
+
+func (c *exportChangedCmd) validate() error {
+	if c.dir == "" {
+		return fmt.Errorf("dir is required")
+	}
+	if c.archive == "" {
+		return nil
+	}
+	if c.format == "" {
+		switch strings.ToLower(filepath.Ext(c.archive)) {
+		case ".zip":
+			c.format = "zip"
+		case ".tar":
+			c.format = "tar"
+		default:
+			return fmt.Errorf("can not detect archive format: %s", c.archive)
+		}
+	}
+	if c.format != "tar" && c.format != "zip" {
+		return fmt.Errorf("format is not support: %s", c.format)
+	}
+	return nil
+}
+
+func (c *exportChangedCmd) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	dir, err := utils.ExpandTilde(c.dir)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return fmt.Errorf("Abs: %w", err)
+	}
+	if !utils.DirExists(dir) {
+		return fmt.Errorf("%s not found", dir)
+	}
+
+	db, err := database.New(c.root.root.Cfg.Database)
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	defer db.Close(ctx)
+
+	var since time.Time
+	if c.since == sinceLastExport {
+		record, err := db.Get(ctx, exportLastKey(dir))
+		if err != nil && !strings.Contains(err.Error(), "Key not found") {
+			return fmt.Errorf("get last export time: %w", err)
+		}
+		if record != "" {
+			ts, err := strconv.ParseInt(record, 10, 64)
+			if err != nil {
+				return fmt.Errorf("ParseInt(%v): %w", record, err)
+			}
+			since = time.UnixMilli(ts)
+		}
+	} else {
+		if since, err = utils.ParseTime(c.since); err != nil {
+			return fmt.Errorf("ParseTime: %w", err)
+		}
+	}
+	log.Debug("[export] dir=%s since=%s archive=%s", dir, since, c.archive)
+
+	// 记录本次扫描开始的时间,避免导出过程中新增的文件在下次导出时被遗漏
+	var now = time.Now()
+	files, err := c.changed(dir, since)
+	if err != nil {
+		return fmt.Errorf("changed: %w", err)
+	}
+	if len(files) <= 0 {
+		c.cmd.Printf("no files added since %s\n", since.Format(time.DateTime))
+		return nil
+	}
+
+	if c.archive == "" {
+		var total int64
+		for _, f := range files {
+			total += f.size
+			c.cmd.Printf("%s  %8.2fM  %s\n", f.modTime.Format(time.DateTime), float64(f.size)/float64(utils.MB), f.name)
+		}
+		c.cmd.Printf("total: %d files %.2fM\n", len(files), float64(total)/float64(utils.MB))
+		return nil
+	}
+
+	archive, err := utils.ExpandTilde(c.archive)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(archive), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	if err := c.write(archive, files); err != nil {
+		_ = os.Remove(archive)
+		return fmt.Errorf("write %s: %w", c.format, err)
+	}
+	if err := db.Set(ctx, exportLastKey(dir), fmt.Sprintf("%d", now.UnixMilli())); err != nil {
+		return fmt.Errorf("set last export time: %w", err)
+	}
+	c.cmd.Printf("archived %d files to %s\n", len(files), archive)
+	return nil
+}
+
+// changed 获取目录中修改时间晚于since的音乐文件
+func (c *exportChangedCmd) changed(dir string, since time.Time) ([]changedFile, error) {
+	var list []changedFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !utils.IsMusicExt(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.ModTime().After(since) {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		list = append(list, changedFile{
+			path:    path,
+			name:    filepath.ToSlash(name),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].modTime.Before(list[j].modTime) })
+	return list, nil
+}
+
+func (c *exportChangedCmd) write(archive string, files []changedFile) error {
+	out, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	switch c.format {
+	case "zip":
+		w := zip.NewWriter(out)
+		for _, f := range files {
+			// 音频文件本身已经是压缩格式,因此直接存储
+			fw, err := w.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Store, Modified: f.modTime})
+			if err != nil {
+				return err
+			}
+			if err := copyFile(fw, f.path); err != nil {
+				return err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+	case "tar":
+		w := tar.NewWriter(out)
+		for _, f := range files {
+			if err := w.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: f.size, ModTime: f.modTime}); err != nil {
+				return err
+			}
+			if err := copyFile(w, f.path); err != nil {
+				return err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func exportLastKey(dir string) string {
+	return fmt.Sprintf("export:last:%v", dir)
+}
@@ -0,0 +1,239 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/resolver"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type FmOpts struct {
+	Download bool   // 下载而不是播放
+	Output   string // 下载输出目录
+	Level    string // 歌曲品质
+	Player   string // 播放器命令及参数
+	Count    int64  // 最多处理歌曲数量,0为不限制
+}
+
+type Fm struct {
+	root *Root
+	cmd  *cobra.Command
+	opts FmOpts
+	l    *log.Logger
+}
+
+func NewFm(root *Root, l *log.Logger) *Fm {
+	c := &Fm{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "fm",
+			Short: "[need login] Play or download songs from personal FM with like/trash feedback",
+			Long: "Pull songs from personal FM and play(default) or download them one by one.\n" +
+				"Before each song enter: <enter> play/download, n skip, l like, t trash(never recommend again), q quit.",
+			Example: "  ncmctl fm\n" +
+				"  ncmctl fm --download -o ./fm -l SQ\n" +
+				"  ncmctl fm --player 'ffplay -nodisp -autoexit' -n 20",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *Fm) addFlags() {
+	c.cmd.Flags().BoolVar(&c.opts.Download, "download", false, "download the songs instead of playing")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./download", "music file output path")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelExhigh), "song quality level. support: standard,higher,exhigh,lossless,hires")
+	c.cmd.Flags().StringVar(&c.opts.Player, "player", "mpv --no-video", "player command, the song url is appended as the last argument")
+	c.cmd.Flags().Int64VarP(&c.opts.Count, "count", "n", 0, "maximum number of songs, 0 means unlimited")
+}
+
+func (c *Fm) validate() error {
+	if c.opts.Count < 0 {
+		return fmt.Errorf("count must be >= 0")
+	}
+	if _, ok := types.LevelString[types.Level(c.opts.Level)]; !ok {
+		return fmt.Errorf("[%s] quality is not support", c.opts.Level)
+	}
+	if !c.opts.Download {
+		var player = strings.Fields(c.opts.Player)
+		if len(player) <= 0 {
+			return fmt.Errorf("player is empty")
+		}
+		if _, err := exec.LookPath(player[0]); err != nil {
+			return fmt.Errorf("player %s not found: %w", player[0], err)
+		}
+	}
+	return nil
+}
+
+func (c *Fm) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Fm) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Fm) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var (
+		request   = weapi.New(cli)
+		r         = resolver.New(request, nil)
+		reader    = bufio.NewReader(os.Stdin)
+		forwarder = newForwarder(c.root)
+		count     int64
+	)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	for {
+		resp, err := request.Radio(ctx, &weapi.RadioReq{ImageFm: "0"})
+		if err != nil {
+			return fmt.Errorf("Radio: %w", err)
+		}
+		if len(resp.Data) <= 0 {
+			c.cmd.Println("personal fm is empty")
+			return nil
+		}
+
+		for _, v := range resp.Data {
+			if ctx.Err() != nil || (c.opts.Count > 0 && count >= c.opts.Count) {
+				return nil
+			}
+			count++
+
+			var song = fmMusic(v)
+			c.cmd.Printf("[%d] %s - %s 《%s》 (<enter> %s, n skip, l like, t trash, q quit): ",
+				count, artistNames(song.Artist), song.Name, song.Album.Name, c.action())
+			line, err := reader.ReadString('\n')
+			if err != nil && (err != io.EOF || line == "") {
+				return nil
+			}
+			switch strings.TrimSpace(line) {
+			case "q":
+				return nil
+			case "n":
+				continue
+			case "t":
+				c.trash(ctx, request, v)
+				continue
+			case "l":
+				c.like(ctx, request, v)
+			}
+
+			if c.opts.Download {
+				d := NewDownload(c.root, c.l)
+				d.opts.Output = c.opts.Output
+				d.opts.Level = c.opts.Level
+				d.opts.Parallel = 1
+				if err := d.execute(ctx, []string{fmt.Sprintf("%d", song.Id)}); err != nil {
+					log.Error("[fm] download %d: %s", song.Id, err)
+				}
+				continue
+			}
+
+			stream, err := r.StreamURL(ctx, song.Id, types.Level(c.opts.Level))
+			if err != nil {
+				log.Warn("[fm] %s skip: %s", song, err)
+				continue
+			}
+			var start = time.Now()
+			played, err := runPlayer(ctx, strings.Fields(c.opts.Player), stream.Url)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			reportPlay(ctx, request, song, "userfm", played)
+			forwardPlay(ctx, forwarder, song, start, played)
+		}
+	}
+}
+
+func (c *Fm) action() string {
+	if c.opts.Download {
+		return "download"
+	}
+	return "play"
+}
+
+func (c *Fm) like(ctx context.Context, request *weapi.Api, v weapi.RadioRespData) {
+	if _, err := request.RadioLike(ctx, &weapi.RadioLikeReq{TrackId: v.Id, Like: true, Time: 3}); err != nil {
+		log.Error("[fm] RadioLike(%d): %s", v.Id, err)
+		return
+	}
+	c.cmd.Printf("liked %s\n", v.Name)
+}
+
+func (c *Fm) trash(ctx context.Context, request *weapi.Api, v weapi.RadioRespData) {
+	if _, err := request.RadioTrash(ctx, &weapi.RadioTrashReq{SongId: v.Id, Alg: v.Alg, Time: 25}); err != nil {
+		log.Error("[fm] RadioTrash(%d): %s", v.Id, err)
+		return
+	}
+	c.cmd.Printf("trashed %s\n", v.Name)
+}
+
+// fmMusic 将私人FM歌曲转换为 Music
+func fmMusic(v weapi.RadioRespData) Music {
+	var m = Music{
+		Id:      v.Id,
+		Name:    v.Name,
+		AlbumId: v.Album.Id,
+		Time:    v.Duration,
+		Album:   types.Album{Id: v.Album.Id, Name: v.Album.Name, PicUrl: v.Album.PicUrl},
+	}
+	for _, ar := range v.Artists {
+		m.Artist = append(m.Artist, types.Artist{Id: ar.Id, Name: ar.Name})
+	}
+	return m
+}
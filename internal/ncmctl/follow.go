@@ -0,0 +1,316 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+var userReg = regexp.MustCompile(`/user/home\?id=(\d+)`)
+
+type Follow struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewFollow(root *Root, l *log.Logger) *Follow {
+	c := &Follow{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "follow",
+			Short: "[need login] List followings and followers, follow users and artists",
+			Example: "  ncmctl follow list\n" +
+				"  ncmctl follow list --type follower\n" +
+				"  ncmctl follow user 32953014\n" +
+				"  ncmctl follow artist 6452",
+		},
+	}
+	c.addFlags()
+	c.Add(followList(c, l))
+	c.Add(followTarget(root, l, "user", true))
+	c.Add(followTarget(root, l, "artist", true))
+	return c
+}
+
+func (c *Follow) addFlags() {}
+
+func (c *Follow) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Follow) Command() *cobra.Command {
+	return c.cmd
+}
+
+type followListCmd struct {
+	root *Follow
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	kind string // user、artist、follower
+}
+
+func followList(root *Follow, l *log.Logger) *cobra.Command {
+	c := &followListCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "list",
+		Short: "[need login] List followed users, followed artists or followers",
+		Example: "  ncmctl follow list\n" +
+			"  ncmctl follow list --type artist",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.cmd.Flags().StringVar(&c.kind, "type", "user", "list type, support: user、artist、follower")
+	return c.cmd
+}
+
+func (c *followListCmd) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	switch c.kind {
+	case "user", "follower":
+		var list []weapi.UserFollowsRespFollow
+		if c.kind == "user" {
+			list, err = userFollows(ctx, request, user.Account.Id)
+		} else {
+			list, err = userFolloweds(ctx, request, user.Account.Id)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "USERID\tNICKNAME\tMUTUAL\tPLAYLISTS\tFOLLOWERS")
+		for _, v := range list {
+			fmt.Fprintf(w, "%d\t%s\t%v\t%d\t%d\n", v.UserId, v.Nickname, v.Mutual, v.PlaylistCount, v.Followeds)
+		}
+	case "artist":
+		list, err := artistSublist(ctx, request)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "ID\tNAME\tALBUMS\tMVS")
+		for _, v := range list {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\n", v.Id, v.Name, v.AlbumSize, v.MvSize)
+		}
+	default:
+		return fmt.Errorf("type is not support: %s", c.kind)
+	}
+	return w.Flush()
+}
+
+type followTargetCmd struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	kind   string // user、artist
+	follow bool   // true:关注 false:取消关注
+}
+
+// followTarget 关注或取消关注指定的用户、歌手,follow 和 unfollow 命令共用
+func followTarget(root *Root, l *log.Logger, kind string, follow bool) *cobra.Command {
+	c := &followTargetCmd{
+		root:   root,
+		l:      l,
+		kind:   kind,
+		follow: follow,
+	}
+	var action, short, example = "follow", "Follow", "32953014"
+	if !follow {
+		action, short = "unfollow", "Unfollow"
+	}
+	if kind == "artist" {
+		example = "6452"
+	}
+	c.cmd = &cobra.Command{
+		Use:     fmt.Sprintf("%s <ids...>", kind),
+		Short:   fmt.Sprintf("[need login] %s %ss by id or link", short, kind),
+		Example: fmt.Sprintf("  ncmctl %s %s %s", action, kind, example),
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	return c.cmd
+}
+
+func (c *followTargetCmd) execute(ctx context.Context, args []string) error {
+	var ids = make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := parseFollowId(c.kind, arg)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var failed int
+	for _, id := range ids {
+		if err := setFollow(ctx, request, c.kind, id, c.follow); err != nil {
+			failed++
+			log.Error("[follow] %s %d follow=%v: %s", c.kind, id, c.follow, err)
+			c.cmd.PrintErrf("%s %d: %s\n", c.kind, id, err)
+			continue
+		}
+		c.cmd.Printf("%s %d: ok\n", c.kind, id)
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(ids):
+		return fmt.Errorf("all %d %ss failed", failed, c.kind)
+	default:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d %ss failed", failed, len(ids), c.kind)}
+	}
+}
+
+// parseFollowId 解析用户或歌手的id及链接
+func parseFollowId(kind, source string) (int64, error) {
+	if id, err := strconv.ParseInt(source, 10, 64); err == nil {
+		return id, nil
+	}
+	if kind == "user" {
+		matched := userReg.FindStringSubmatch(source)
+		if len(matched) < 2 {
+			return 0, fmt.Errorf("could not parse the user: %s", source)
+		}
+		return strconv.ParseInt(matched[1], 10, 64)
+	}
+	k, id, err := Parse(source)
+	if err != nil {
+		return 0, fmt.Errorf("Parse(%s): %w", source, err)
+	}
+	if k != kind {
+		return 0, fmt.Errorf("%s is not a %s link", source, kind)
+	}
+	return id, nil
+}
+
+func setFollow(ctx context.Context, request *weapi.Api, kind string, id int64, follow bool) error {
+	switch kind {
+	case "user":
+		if _, err := request.UserFollow(ctx, &weapi.UserFollowReq{Id: id, Follow: follow}); err != nil {
+			return fmt.Errorf("UserFollow: %w", err)
+		}
+		return nil
+	case "artist":
+		if _, err := request.ArtistSub(ctx, &weapi.ArtistSubReq{ArtistId: fmt.Sprintf("%d", id), Sub: follow}); err != nil {
+			return fmt.Errorf("ArtistSub: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s is not support", kind)
+	}
+}
+
+// userFollows 获取用户关注的全部用户
+func userFollows(ctx context.Context, request *weapi.Api, uid int64) ([]weapi.UserFollowsRespFollow, error) {
+	var list []weapi.UserFollowsRespFollow
+	for {
+		resp, err := request.UserFollows(ctx, &weapi.UserFollowsReq{Uid: uid, Offset: int64(len(list)), Limit: 100, Order: true})
+		if err != nil {
+			return nil, fmt.Errorf("UserFollows: %w", err)
+		}
+		list = append(list, resp.Follow...)
+		if !resp.More || len(resp.Follow) <= 0 {
+			return list, nil
+		}
+	}
+}
+
+// userFolloweds 获取用户的全部粉丝
+func userFolloweds(ctx context.Context, request *weapi.Api, uid int64) ([]weapi.UserFollowsRespFollow, error) {
+	var (
+		list   []weapi.UserFollowsRespFollow
+		cursor int64 = -1
+	)
+	for {
+		resp, err := request.UserFolloweds(ctx, &weapi.UserFollowedsReq{UserId: fmt.Sprintf("%d", uid), Time: cursor, Limit: 100})
+		if err != nil {
+			return nil, fmt.Errorf("UserFolloweds: %w", err)
+		}
+		list = append(list, resp.Followeds...)
+		if !resp.More || len(resp.Followeds) <= 0 {
+			return list, nil
+		}
+		cursor = resp.Followeds[len(resp.Followeds)-1].Time
+	}
+}
+
+// artistSublist 获取关注的全部歌手
+func artistSublist(ctx context.Context, request *weapi.Api) ([]weapi.ArtistSublistRespData, error) {
+	var list []weapi.ArtistSublistRespData
+	for {
+		resp, err := request.ArtistSublist(ctx, &weapi.ArtistSublistReq{Limit: 100, Offset: int64(len(list)), Total: true})
+		if err != nil {
+			return nil, fmt.Errorf("ArtistSublist: %w", err)
+		}
+		list = append(list, resp.Data...)
+		if !resp.HasMore || len(resp.Data) <= 0 {
+			return list, nil
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type HistoryOpts struct {
+	Type   string // week、all
+	Format string // table、csv、json
+	Output string // 导出文件路径,-表示标准输出
+	Uid    int64  // 查看其他用户的听歌排行,为0时查看自己
+	Stats  bool   // 输出听歌报告
+	Year   int    // 听歌报告年份
+	Top    int    // 听歌报告排行数量
+	Dir    string // 听歌排行快照保存目录
+}
+
+type History struct {
+	root *Root
+	cmd  *cobra.Command
+	opts HistoryOpts
+	l    *log.Logger
+}
+
+func NewHistory(root *Root, l *log.Logger) *History {
+	c := &History{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "history",
+			Short: "[need login] Export listening history and generate a yearly listening report",
+			Long: "Export the weekly or all-time play ranking(top 100 songs) to table/csv/json, or generate a yearly listening report by --stats.\n" +
+				"NetEase only provides play counts without time, so the all-time ranking is saved as a daily snapshot on each run,\n" +
+				"the yearly report and year-over-year comparison are computed from the difference between snapshots.\n" +
+				"Run it periodically(eg: by daemon) to make the report accurate.",
+			Example: "  ncmctl history\n" +
+				"  ncmctl history --type all --format csv -o history.csv\n" +
+				"  ncmctl history --stats\n" +
+				"  ncmctl history --stats --year 2025 --top 20",
+			Args: cobra.NoArgs,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *History) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Type, "type", "week", "ranking type, support: week、all")
+	c.cmd.Flags().StringVar(&c.opts.Format, "format", "table", "output format, support: table、csv、json. the report only supports table and json")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "-", "output file path, '-' means stdout")
+	c.cmd.Flags().Int64Var(&c.opts.Uid, "uid", 0, "view the ranking of other user whose ranking is public")
+	c.cmd.Flags().BoolVar(&c.opts.Stats, "stats", false, "generate the yearly listening report")
+	c.cmd.Flags().IntVar(&c.opts.Year, "year", time.Now().Year(), "year of the listening report")
+	c.cmd.Flags().IntVar(&c.opts.Top, "top", 10, "number of top songs, artists and albums in the report")
+	c.cmd.Flags().StringVar(&c.opts.Dir, "dir", "", "snapshot directory, default ${STATE}/history")
+}
+
+func (c *History) validate() error {
+	switch c.opts.Type {
+	case "week", "all":
+	default:
+		return fmt.Errorf("type is not support: %s", c.opts.Type)
+	}
+	switch c.opts.Format {
+	case "table", "json":
+	case "csv":
+		if c.opts.Stats {
+			return fmt.Errorf("the report does not support csv format")
+		}
+	default:
+		return fmt.Errorf("format is not support: %s", c.opts.Format)
+	}
+	if c.opts.Top <= 0 {
+		return fmt.Errorf("top must be greater than 0")
+	}
+	return nil
+}
+
+func (c *History) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *History) Command() *cobra.Command {
+	return c.cmd
+}
+
+// listenRecord 听歌排行中的一首歌曲
+type listenRecord struct {
+	Id        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Artists   []string `json:"artists"`
+	Album     string   `json:"album"`
+	PlayCount int64    `json:"playCount"`
+	Score     int64    `json:"score"` // 播放热度,最高为100
+}
+
+func (c *History) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	if c.opts.Dir == "" {
+		c.opts.Dir = filepath.Join(c.root.dirs.State, "history")
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var (
+		request = weapi.New(cli)
+		uid     = c.opts.Uid
+		self    bool
+	)
+	if uid <= 0 {
+		user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+		if err != nil {
+			return fmt.Errorf("GetUserInfo: %w", err)
+		}
+		if user.Account == nil {
+			return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+		}
+		uid, self = user.Account.Id, true
+	}
+
+	var kind = c.opts.Type
+	if c.opts.Stats {
+		kind = "all"
+	}
+	records, err := playRecords(ctx, request, uid, kind)
+	if err != nil {
+		return err
+	}
+	// 所有时间的排行保存为当天的快照,用于计算时间段内的播放次数
+	if kind == "all" && len(records) > 0 {
+		if err := saveHistorySnapshot(c.opts.Dir, uid, time.Now(), records); err != nil {
+			log.Warn("[history] save snapshot: %s", err)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if c.opts.Output != "-" {
+		f, err := os.Create(c.opts.Output)
+		if err != nil {
+			return fmt.Errorf("Create: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if !c.opts.Stats {
+		if err := writeHistoryRecords(w, c.opts.Format, records); err != nil {
+			return err
+		}
+		if c.opts.Output != "-" {
+			c.cmd.Printf("export %d songs to %s\n", len(records), c.opts.Output)
+		}
+		return nil
+	}
+
+	snapshots, err := loadHistorySnapshots(c.opts.Dir, uid)
+	if err != nil {
+		return err
+	}
+	var report = newHistoryReport(c.opts.Year, c.opts.Top, time.Now(), records, snapshots)
+	// 最近播放只能查看自己的
+	if self {
+		resp, err := request.RecentSongs(ctx, &weapi.RecentSongsReq{Limit: 300})
+		if err != nil {
+			log.Warn("[history] RecentSongs: %s", err)
+		} else {
+			for _, v := range resp.Data.List {
+				report.Hours[time.UnixMilli(v.PlayTime).Hour()]++
+				report.HourSample++
+			}
+		}
+	}
+	if c.opts.Format == "json" {
+		var encoder = json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	return report.write(w)
+}
+
+// playRecords 获取听歌排行 kind: week、all
+func playRecords(ctx context.Context, request *weapi.Api, uid int64, kind string) ([]listenRecord, error) {
+	var req = &weapi.UserPlayRecordReq{Uid: strconv.FormatInt(uid, 10), Type: 1}
+	if kind == "all" {
+		req.Type = 0
+	}
+	resp, err := request.UserPlayRecord(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("UserPlayRecord: %w", err)
+	}
+	var data = resp.WeekData
+	if kind == "all" {
+		data = resp.AllData
+	}
+	var records = make([]listenRecord, 0, len(data))
+	for _, v := range data {
+		var artists = make([]string, 0, len(v.Song.Ar))
+		for _, ar := range v.Song.Ar {
+			artists = append(artists, ar.Name)
+		}
+		records = append(records, listenRecord{
+			Id:        v.Song.Id,
+			Name:      v.Song.Name,
+			Artists:   artists,
+			Album:     v.Song.Al.Name,
+			PlayCount: v.PlayCount,
+			Score:     v.Score,
+		})
+	}
+	return records, nil
+}
+
+// writeHistoryRecords 按格式输出听歌排行
+func writeHistoryRecords(w io.Writer, format string, records []listenRecord) error {
+	if format == "json" {
+		var encoder = json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if records == nil {
+			records = []listenRecord{}
+		}
+		return encoder.Encode(records)
+	}
+
+	var header = []string{"RANK", "ID", "NAME", "ARTISTS", "ALBUM", "PLAYS", "SCORE"}
+	var row = func(i int, r listenRecord) []string {
+		return []string{strconv.Itoa(i + 1), strconv.FormatInt(r.Id, 10), r.Name, strings.Join(r.Artists, "/"), r.Album,
+			strconv.FormatInt(r.PlayCount, 10), strconv.FormatInt(r.Score, 10)}
+	}
+
+	if format == "csv" {
+		var cw = csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for i, r := range records {
+			if err := cw.Write(row(i, r)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	var tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for i, r := range records {
+		fmt.Fprintln(tw, strings.Join(row(i, r), "\t"))
+	}
+	return tw.Flush()
+}
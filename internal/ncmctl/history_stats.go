@@ -0,0 +1,277 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historySnapshot 某一天的所有时间听歌排行
+type historySnapshot struct {
+	Time    time.Time      `json:"time"`
+	Records []listenRecord `json:"records"`
+}
+
+// saveHistorySnapshot 保存听歌排行快照,每个用户每天保存一份,同一天多次运行时覆盖
+func saveHistorySnapshot(dir string, uid int64, t time.Time, records []listenRecord) error {
+	dir = filepath.Join(dir, strconv.FormatInt(uid, 10))
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	data, err := json.Marshal(historySnapshot{Time: t, Records: records})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, t.Format(time.DateOnly)+".json"), data, 0o644)
+}
+
+// loadHistorySnapshots 加载用户的所有快照,按时间升序排列
+func loadHistorySnapshots(dir string, uid int64) ([]historySnapshot, error) {
+	files, err := filepath.Glob(filepath.Join(dir, strconv.FormatInt(uid, 10), "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("Glob: %w", err)
+	}
+	var snapshots = make([]historySnapshot, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("ReadFile: %w", err)
+		}
+		var s historySnapshot
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", file, err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	slices.SortFunc(snapshots, func(a, b historySnapshot) int {
+		return a.Time.Compare(b.Time)
+	})
+	return snapshots, nil
+}
+
+// playsBetween 根据from当天及之前、to之前最近的两份快照计算时间段内每首歌曲的播放次数,返回实际使用的快照时间.
+// 排行只有前100首,新进入排行的歌曲之前的播放次数按上一份快照中的最少播放次数估算
+func playsBetween(snapshots []historySnapshot, from, to time.Time) ([]listenRecord, [2]time.Time, bool) {
+	var base, last *historySnapshot
+	for i := range snapshots {
+		var s = &snapshots[i]
+		if s.Time.Before(from.AddDate(0, 0, 1)) {
+			base = s
+		}
+		if !s.Time.After(to) {
+			last = s
+		}
+	}
+	if base == nil || last == nil || !last.Time.After(base.Time) {
+		return nil, [2]time.Time{}, false
+	}
+
+	var (
+		prev    = make(map[int64]int64, len(base.Records))
+		missing int64
+	)
+	for _, r := range base.Records {
+		prev[r.Id] = r.PlayCount
+	}
+	if len(base.Records) >= 100 {
+		missing = slices.MinFunc(base.Records, func(a, b listenRecord) int {
+			return cmp.Compare(a.PlayCount, b.PlayCount)
+		}).PlayCount
+	}
+
+	var list []listenRecord
+	for _, r := range last.Records {
+		before, ok := prev[r.Id]
+		if !ok {
+			before = missing
+		}
+		if r.PlayCount-before <= 0 {
+			continue
+		}
+		r.PlayCount -= before
+		list = append(list, r)
+	}
+	return list, [2]time.Time{base.Time, last.Time}, true
+}
+
+// historyRank 排行项
+type historyRank struct {
+	Name  string `json:"name"`
+	Plays int64  `json:"plays"`
+}
+
+// historyYear 去年同期的听歌情况
+type historyYear struct {
+	Year    int           `json:"year"`
+	Plays   int64         `json:"plays"`
+	Artists []historyRank `json:"artists"` // 今年排行中的歌手去年同期的播放次数
+}
+
+// historyReport 年度听歌报告
+type historyReport struct {
+	Year       int           `json:"year"`
+	Source     string        `json:"source"` // 数据来源说明
+	Plays      int64         `json:"plays"`
+	Songs      []historyRank `json:"songs"`
+	Artists    []historyRank `json:"artists"`
+	Albums     []historyRank `json:"albums"`
+	Hours      []int64       `json:"hours"`      // 最近播放的歌曲按小时分布
+	HourSample int           `json:"hourSample"` // 按小时分布统计的歌曲数量
+	LastYear   *historyYear  `json:"lastYear,omitempty"`
+}
+
+// newHistoryReport 生成年度听歌报告,快照不足以计算年度播放次数时使用所有时间的排行
+func newHistoryReport(year, top int, now time.Time, records []listenRecord, snapshots []historySnapshot) *historyReport {
+	var (
+		start  = time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
+		end    = start.AddDate(1, 0, 0)
+		report = &historyReport{Year: year, Hours: make([]int64, 24)}
+	)
+	if end.After(now) {
+		end = now
+	}
+
+	plays, span, ok := playsBetween(snapshots, start, end)
+	if ok {
+		report.Source = fmt.Sprintf("snapshots %s ~ %s", span[0].Format(time.DateOnly), span[1].Format(time.DateOnly))
+	} else {
+		plays = records
+		report.Source = fmt.Sprintf("all-time ranking, no snapshot on or before %s", start.Format(time.DateOnly))
+	}
+	report.Plays = totalPlays(plays)
+	report.Songs = historyTop(plays, top, func(r listenRecord) []string {
+		return []string{fmt.Sprintf("%s - %s", r.Name, strings.Join(r.Artists, "/"))}
+	})
+	report.Artists = historyTop(plays, top, func(r listenRecord) []string { return r.Artists })
+	report.Albums = historyTop(plays, top, func(r listenRecord) []string { return []string{r.Album} })
+
+	// 去年同期对比
+	last, _, lastOk := playsBetween(snapshots, start.AddDate(-1, 0, 0), end.AddDate(-1, 0, 0))
+	if !ok || !lastOk {
+		return report
+	}
+	var artists = make(map[string]int64)
+	for _, r := range last {
+		for _, a := range r.Artists {
+			artists[a] += r.PlayCount
+		}
+	}
+	report.LastYear = &historyYear{Year: year - 1, Plays: totalPlays(last)}
+	for _, a := range report.Artists {
+		report.LastYear.Artists = append(report.LastYear.Artists, historyRank{Name: a.Name, Plays: artists[a.Name]})
+	}
+	return report
+}
+
+func totalPlays(records []listenRecord) int64 {
+	var n int64
+	for _, r := range records {
+		n += r.PlayCount
+	}
+	return n
+}
+
+// historyTop 按keys分组累加播放次数并返回前top项,一首歌曲有多个歌手时每个歌手都计入
+func historyTop(records []listenRecord, top int, keys func(r listenRecord) []string) []historyRank {
+	var plays = make(map[string]int64)
+	for _, r := range records {
+		for _, k := range keys(r) {
+			if k != "" {
+				plays[k] += r.PlayCount
+			}
+		}
+	}
+	var list = make([]historyRank, 0, len(plays))
+	for k, v := range plays {
+		list = append(list, historyRank{Name: k, Plays: v})
+	}
+	slices.SortFunc(list, func(a, b historyRank) int {
+		if n := cmp.Compare(b.Plays, a.Plays); n != 0 {
+			return n
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return list[:min(top, len(list))]
+}
+
+// write 以文本形式输出听歌报告
+func (r *historyReport) write(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d listening report\n", r.Year)
+	fmt.Fprintf(&b, "source: %s\n", r.Source)
+	fmt.Fprintf(&b, "plays: %d", r.Plays)
+	if r.LastYear != nil {
+		fmt.Fprintf(&b, " (%d same period: %d, %s)", r.LastYear.Year, r.LastYear.Plays, growth(r.Plays, r.LastYear.Plays))
+	}
+	b.WriteString("\n")
+
+	var section = func(title string, list []historyRank, last []historyRank) {
+		fmt.Fprintf(&b, "\n%s\n", title)
+		for i, v := range list {
+			fmt.Fprintf(&b, "  %2d. %s (%d", i+1, v.Name, v.Plays)
+			if i < len(last) {
+				fmt.Fprintf(&b, ", last year %d", last[i].Plays)
+			}
+			b.WriteString(")\n")
+		}
+	}
+	section("Top songs", r.Songs, nil)
+	var last []historyRank
+	if r.LastYear != nil {
+		last = r.LastYear.Artists
+	}
+	section("Top artists", r.Artists, last)
+	section("Top albums", r.Albums, nil)
+
+	if r.HourSample > 0 {
+		fmt.Fprintf(&b, "\nListening by hour (latest %d songs)\n", r.HourSample)
+		var peak = slices.Max(r.Hours)
+		for hour, n := range r.Hours {
+			var bar int
+			if peak > 0 {
+				bar = int(n * 40 / peak)
+			}
+			fmt.Fprintf(&b, "  %02d %s %d\n", hour, strings.Repeat("█", bar), n)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// growth 计算增长率
+func growth(cur, prev int64) string {
+	if prev <= 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", float64(cur-prev)*100/float64(prev))
+}
@@ -0,0 +1,79 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/hook"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+)
+
+// hookTimeout 单次hook命令最长执行时间
+const hookTimeout = 10 * time.Minute
+
+// hookVars 下载或解密成功后执行hook命令可用的变量,比如 --exec-after 'beet import {file}'
+type hookVars struct {
+	Id      int64  // {id} 歌曲id
+	File    string // {file} 文件路径
+	Dir     string // {dir} 文件所在目录
+	Title   string // {title} 歌曲名称
+	Artist  string // {artist} 歌手,多个时以逗号分隔
+	Album   string // {album} 专辑名称
+	Quality string // {quality} 音质
+}
+
+// vars 变量值通过 NCM_ 开头的环境变量传递给命令,不会被shell解析,命令中无需再加引号,也便于在脚本中使用
+func (v hookVars) vars() []hook.Var {
+	return []hook.Var{
+		{Name: "id", Env: "NCM_ID", Value: strconv.FormatInt(v.Id, 10)},
+		{Name: "file", Env: "NCM_FILE", Value: v.File},
+		{Name: "dir", Env: "NCM_DIR", Value: v.Dir},
+		{Name: "title", Env: "NCM_TITLE", Value: v.Title},
+		{Name: "artist", Env: "NCM_ARTIST", Value: v.Artist},
+		{Name: "album", Env: "NCM_ALBUM", Value: v.Album},
+		{Name: "quality", Env: "NCM_QUALITY", Value: v.Quality},
+	}
+}
+
+// runHook 通过系统shell执行hook命令,命令输出到标准错误,避免与标准输出中的命令结果混在一起
+func runHook(ctx context.Context, command string, v hookVars) error {
+	if command == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	cmd := hook.Command(ctx, command, v.vars())
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	log.Debug("[hook] exec: %s", command)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("exec %q: %w", command, err)
+	}
+	return nil
+}
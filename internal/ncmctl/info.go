@@ -0,0 +1,270 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+// creditLine 匹配歌词中的制作人员信息行,例如: 作词 : 方文山
+var creditLine = regexp.MustCompile(`^\s*(作词|作曲|编曲|制作人)\s*[:：]\s*(.+?)\s*$`)
+
+// songInfo 歌曲元数据,可用于补充音乐文件标签
+type songInfo struct {
+	Id          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Artists     []string `json:"artists"`
+	Album       string   `json:"album"`
+	PublishDate string   `json:"publishDate,omitempty"`
+	Duration    string   `json:"duration"`
+	Origin      string   `json:"origin"`               // 原唱/翻唱/未知
+	OriginSong  string   `json:"originSong,omitempty"` // 翻唱曲对应的原曲,格式: 歌名 - 歌手
+	Language    string   `json:"language,omitempty"`   // 语种
+	Styles      []string `json:"styles,omitempty"`     // 曲风
+	Tags        []string `json:"tags,omitempty"`       // 推荐标签
+	Bpm         string   `json:"bpm,omitempty"`        // BPM
+	Lyricists   []string `json:"lyricists,omitempty"`  // 作词
+	Composers   []string `json:"composers,omitempty"`  // 作曲
+	Arrangers   []string `json:"arrangers,omitempty"`  // 编曲
+	Producers   []string `json:"producers,omitempty"`  // 制作人
+}
+
+type Info struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewInfo(root *Root, l *log.Logger) *Info {
+	c := &Info{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "info",
+			Short: "Show song metadata such as original work, language, lyricists, composers, release date and style tags",
+			Example: "  ncmctl info 1989404376\n" +
+				"  ncmctl info https://music.163.com/song?id=1989404376\n" +
+				"  ncmctl info 1989404376 --output-format json",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: completeArgs(root.completeSongs, cobra.NoFileCompletions),
+		},
+	}
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args[0])
+	}
+	return c
+}
+
+func (c *Info) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Info) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Info) execute(ctx context.Context, arg string) error {
+	ids, err := parseSongIds([]string{arg})
+	if err != nil {
+		return err
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	info, err := songMetadata(ctx, request, ids[0])
+	if err != nil {
+		return err
+	}
+	c.root.cacheSongs(completionItem{Id: info.Id, Name: info.Name})
+	return render(c.cmd.OutOrStdout(), c.root.outputFormat(outputTable), info.view())
+}
+
+// songMetadata 汇总歌曲详情、歌词中的制作人员信息及音乐百科,歌词及百科获取失败时仅记录日志
+func songMetadata(ctx context.Context, request *weapi.Api, id int64) (*songInfo, error) {
+	detail, err := request.SongDetail(ctx, &weapi.SongDetailReq{C: []weapi.SongDetailReqList{{Id: strconv.FormatInt(id, 10)}}})
+	if err != nil {
+		return nil, fmt.Errorf("SongDetail: %w", err)
+	}
+	if len(detail.Songs) <= 0 {
+		return nil, fmt.Errorf("song %d not found", id)
+	}
+
+	var (
+		song = detail.Songs[0]
+		info = songInfo{
+			Id:       song.Id,
+			Name:     song.Name,
+			Album:    song.Al.Name,
+			Duration: (time.Duration(song.Dt) * time.Millisecond).Round(time.Second).String(),
+			Origin:   "unknown",
+		}
+	)
+	for _, ar := range song.Ar {
+		info.Artists = append(info.Artists, ar.Name)
+	}
+	if song.PublishTime > 0 {
+		info.PublishDate = time.UnixMilli(song.PublishTime).Format(time.DateOnly)
+	}
+	switch song.OriginCoverType {
+	case 1:
+		info.Origin = "original"
+	case 2:
+		info.Origin = "cover"
+	}
+	if o := song.OriginSongSimpleData; o != nil && o.Name != "" {
+		var artists = make([]string, 0, len(o.Artists))
+		for _, ar := range o.Artists {
+			artists = append(artists, ar.Name)
+		}
+		info.OriginSong = o.Name
+		if len(artists) > 0 {
+			info.OriginSong += " - " + strings.Join(artists, ",")
+		}
+	}
+
+	lyric, err := request.Lyric(ctx, &weapi.LyricReq{Id: id})
+	if err != nil {
+		log.Warn("Lyric(%d): %s", id, err)
+	} else {
+		info.credits(lyric.Lrc.Lyric)
+	}
+
+	wiki, err := request.SongWikiSummary(ctx, &weapi.SongWikiSummaryReq{SongId: id})
+	if err != nil {
+		log.Warn("SongWikiSummary(%d): %s", id, err)
+	} else {
+		info.wiki(wiki.Data.Blocks)
+	}
+	return &info, nil
+}
+
+// credits 从歌词中解析作词、作曲等制作人员信息,兼容普通lrc行及新版json格式的行
+func (s *songInfo) credits(lyric string) {
+	for _, line := range strings.Split(lyric, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "{") {
+			var v struct {
+				C []struct {
+					Tx string `json:"tx"`
+				} `json:"c"`
+			}
+			if err := json.Unmarshal([]byte(line), &v); err != nil {
+				continue
+			}
+			var b strings.Builder
+			for _, c := range v.C {
+				b.WriteString(c.Tx)
+			}
+			line = b.String()
+		} else if i := strings.LastIndex(line, "]"); strings.HasPrefix(line, "[") && i > 0 {
+			line = line[i+1:]
+		}
+
+		var m = creditLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var names = strings.FieldsFunc(m[2], func(r rune) bool { return r == '/' || r == '、' || r == ',' || r == '，' })
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		switch m[1] {
+		case "作词":
+			s.Lyricists = append(s.Lyricists, names...)
+		case "作曲":
+			s.Composers = append(s.Composers, names...)
+		case "编曲":
+			s.Arrangers = append(s.Arrangers, names...)
+		case "制作人":
+			s.Producers = append(s.Producers, names...)
+		}
+	}
+}
+
+// wiki 从音乐百科中提取语种、曲风、推荐标签及BPM
+func (s *songInfo) wiki(blocks []weapi.SongWikiBlock) {
+	for _, block := range blocks {
+		for _, creative := range block.Creatives {
+			var values = creative.Values()
+			if len(values) <= 0 {
+				continue
+			}
+			switch creative.CreativeType {
+			case "language":
+				s.Language = strings.Join(values, ",")
+			case "songTag":
+				s.Styles = append(s.Styles, values...)
+			case "songBizTag":
+				s.Tags = append(s.Tags, values...)
+			case "bpm":
+				s.Bpm = values[0]
+			}
+		}
+	}
+}
+
+func (s *songInfo) view() view {
+	var v = view{Data: s, Header: []string{"KEY", "VALUE"}}
+	for _, kv := range [][2]string{
+		{"id", strconv.FormatInt(s.Id, 10)},
+		{"name", s.Name},
+		{"artists", strings.Join(s.Artists, ",")},
+		{"album", s.Album},
+		{"publish date", s.PublishDate},
+		{"duration", s.Duration},
+		{"origin", s.Origin},
+		{"origin song", s.OriginSong},
+		{"language", s.Language},
+		{"styles", strings.Join(s.Styles, ",")},
+		{"tags", strings.Join(s.Tags, ",")},
+		{"bpm", s.Bpm},
+		{"lyricists", strings.Join(s.Lyricists, ",")},
+		{"composers", strings.Join(s.Composers, ",")},
+		{"arrangers", strings.Join(s.Arrangers, ",")},
+		{"producers", strings.Join(s.Producers, ",")},
+	} {
+		if kv[1] == "" {
+			continue
+		}
+		v.Rows = append(v.Rows, []string{kv[0], kv[1]})
+	}
+	return v
+}
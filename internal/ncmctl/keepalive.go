@@ -0,0 +1,192 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+// skipKeepAlive 命令注解,设置后执行命令前不检查登录cookie有效期
+const skipKeepAlive = "skipKeepAlive"
+
+type KeepAliveOpts struct {
+	Threshold time.Duration // 登录cookie剩余有效期小于此值时刷新
+	Anonymous bool          // 未登录时是否注册匿名用户
+}
+
+type KeepAlive struct {
+	root *Root
+	cmd  *cobra.Command
+	opts KeepAliveOpts
+	l    *log.Logger
+}
+
+func NewKeepAlive(root *Root, l *log.Logger) *KeepAlive {
+	c := &KeepAlive{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:         "keepalive",
+			Annotations: map[string]string{skipKeepAlive: ""},
+			Short:       "Refresh the login token when it is about to expire",
+			Example:     "  ncmctl keepalive\n  ncmctl keepalive --threshold 168h\n  ncmctl keepalive --anonymous",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *KeepAlive) addFlags() {
+	c.cmd.Flags().DurationVar(&c.opts.Threshold, "threshold", keepAliveThreshold, "refresh when the login cookie expires within this duration")
+	c.cmd.Flags().BoolVar(&c.opts.Anonymous, "anonymous", false, "register anonymous token when not logged in")
+}
+
+func (c *KeepAlive) validate() error {
+	if c.opts.Threshold <= 0 {
+		return fmt.Errorf("threshold must be greater than 0")
+	}
+	return nil
+}
+
+func (c *KeepAlive) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *KeepAlive) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *KeepAlive) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	request := weapi.New(cli)
+
+	expires, ok := loginExpires(cli)
+	if !ok {
+		if !c.opts.Anonymous {
+			return fmt.Errorf("need login")
+		}
+		if hasCookie(cli, "MUSIC_A") {
+			c.cmd.Println("anonymous token already exists")
+			return nil
+		}
+		if _, err := request.RegisterAnonymous(ctx, &weapi.RegisterAnonymousReq{}); err != nil {
+			return fmt.Errorf("RegisterAnonymous: %w", err)
+		}
+		c.cmd.Println("register anonymous token success")
+		return nil
+	}
+
+	// 先校验登录状态是否有效,cookie未过期但服务端可能已经失效
+	if request.NeedLogin(ctx) {
+		return fmt.Errorf("login has expired, please login again")
+	}
+	refreshed, err := refreshToken(ctx, request, expires, c.opts.Threshold)
+	if err != nil {
+		return err
+	}
+	if refreshed {
+		expires, _ = loginExpires(cli)
+		c.cmd.Printf("refresh token success, expires: %s\n", expires.Format(time.DateTime))
+	} else {
+		c.cmd.Printf("token is valid, expires: %s\n", expires.Format(time.DateTime))
+	}
+	return nil
+}
+
+// keepAliveThreshold 默认登录cookie剩余有效期小于3天时刷新
+const keepAliveThreshold = time.Hour * 72
+
+// keepAlive 执行命令前检查登录cookie有效期,临近过期时自动刷新。
+// 仅在本地检查cookie有效期,只有需要刷新时才会请求接口。
+func (c *Root) keepAlive(ctx context.Context) error {
+	cli, err := api.NewClient(c.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+
+	expires, ok := loginExpires(cli)
+	if !ok {
+		return nil
+	}
+	refreshed, err := refreshToken(ctx, weapi.New(cli), expires, keepAliveThreshold)
+	if err != nil {
+		return err
+	}
+	if refreshed {
+		log.Info("[keepalive] refresh token success")
+	}
+	return nil
+}
+
+// refreshToken 当登录cookie剩余有效期小于threshold时刷新token
+func refreshToken(ctx context.Context, request *weapi.Api, expires time.Time, threshold time.Duration) (bool, error) {
+	// 会话cookie没有过期时间,无需刷新
+	if expires.IsZero() || time.Until(expires) > threshold {
+		return false, nil
+	}
+	log.Debug("[keepalive] token expires at %s, refresh it", expires)
+	if _, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{}); err != nil {
+		return false, fmt.Errorf("TokenRefresh: %w", err)
+	}
+	return true, nil
+}
+
+// loginExpires 获取登录cookie(MUSIC_U)的过期时间,未登录时返回false
+func loginExpires(cli *api.Client) (time.Time, bool) {
+	for _, ck := range cli.ExportCookies() {
+		if ck.Name == "MUSIC_U" && ck.Value != "" {
+			return ck.Expires, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func hasCookie(cli *api.Client, name string) bool {
+	for _, ck := range cli.ExportCookies() {
+		if ck.Name == name && ck.Value != "" {
+			return true
+		}
+	}
+	return false
+}
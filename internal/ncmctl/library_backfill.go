@@ -0,0 +1,574 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/checksum"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+	"github.com/go-flac/flacpicture/v2"
+	"github.com/go-flac/flacvorbis/v2"
+	"github.com/go-flac/go-flac/v2"
+
+	"github.com/spf13/cobra"
+)
+
+// backfillStateName 补全进度文件名,位于扫描目录下
+const backfillStateName = ".backfill"
+
+// backfillFields 支持补全的内容
+var backfillFields = []string{"id", "cover", "lyric"}
+
+type libraryBackfillCmd struct {
+	root *Library
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	fields      []string      // 需要补全的内容
+	interval    time.Duration // 每首歌曲请求接口的间隔
+	maxDuration time.Duration // 单次运行最长时间
+	restart     bool          // 忽略进度从头开始扫描
+}
+
+// backfillState 补全进度,Cursor为上次处理到的文件,中断后从Cursor的下一个文件继续
+type backfillState struct {
+	Cursor    string    `json:"cursor"`
+	Updated   int64     `json:"updated"`
+	Unmatched []string  `json:"unmatched"`
+	Time      time.Time `json:"time"`
+}
+
+// libraryFile 本地音乐文件已有的标签信息
+type libraryFile struct {
+	Title    string
+	Artist   string
+	Album    string
+	Id       int64
+	HasCover bool
+	HasLyric bool
+}
+
+func libraryBackfill(root *Library, l *log.Logger) *cobra.Command {
+	c := &libraryBackfillCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "backfill <dir>",
+		Short: "[need login] Slowly fill missing song id, cover and lyric tags of local mp3/flac files",
+		Long: "Walk all mp3/flac files under dir and fill missing song id(NCM_ID), cover and lyric tags.\n" +
+			"Songs without id tag are matched by searching the title and artist, only exact title matches are accepted.\n" +
+			"Only one song is requested per interval, progress is saved in <dir>/.backfill after every song,\n" +
+			"so it can be interrupted at any time and resumed by running the same command again.\n" +
+			"Checksums recorded by download are updated after the file is modified.",
+		Example: "  ncmctl library backfill ./download\n" +
+			"  ncmctl library backfill ./download --fields cover,lyric --interval 1m --max-duration 6h\n" +
+			"  ncmctl library backfill ./download --restart",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args[0])
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *libraryBackfillCmd) addFlags() {
+	c.cmd.Flags().StringSliceVar(&c.fields, "fields", backfillFields, "content to fill, support: id、cover、lyric")
+	c.cmd.Flags().DurationVar(&c.interval, "interval", 30*time.Second, "wait time between two songs that need to request api")
+	c.cmd.Flags().DurationVar(&c.maxDuration, "max-duration", 0, "stop after the duration, 0 means run until all files are processed")
+	c.cmd.Flags().BoolVar(&c.restart, "restart", false, "ignore saved progress and scan from the beginning")
+}
+
+func (c *libraryBackfillCmd) validate() error {
+	if len(c.fields) <= 0 {
+		return fmt.Errorf("fields is empty")
+	}
+	for _, f := range c.fields {
+		if !slices.Contains(backfillFields, f) {
+			return fmt.Errorf("unsupported field: %s", f)
+		}
+	}
+	if c.interval < 0 {
+		return fmt.Errorf("interval must be >= 0")
+	}
+	if c.maxDuration < 0 {
+		return fmt.Errorf("max-duration must be >= 0")
+	}
+	return nil
+}
+
+func (c *libraryBackfillCmd) execute(ctx context.Context, dir string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	dir, err := utils.ExpandTilde(dir)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if !utils.DirExists(dir) {
+		return fmt.Errorf("%s not found", dir)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	names, err := c.files(dir)
+	if err != nil {
+		return err
+	}
+	state, err := c.load(dir)
+	if err != nil {
+		return err
+	}
+	if c.restart {
+		state = &backfillState{}
+	}
+
+	var (
+		begin   = time.Now()
+		start   = sort.SearchStrings(names, state.Cursor)
+		scanned int
+		updated int
+		failed  int
+		last    time.Time
+	)
+	if start < len(names) && names[start] == state.Cursor {
+		start++
+	}
+	if start >= len(names) {
+		c.cmd.Printf("all %d files in %s have been processed, use --restart to scan again\n", len(names), dir)
+		return nil
+	}
+
+	for _, name := range names[start:] {
+		if ctx.Err() != nil || (c.maxDuration > 0 && time.Since(begin) >= c.maxDuration) {
+			break
+		}
+		var path = filepath.Join(dir, filepath.FromSlash(name))
+		file, err := readLibraryFile(path)
+		if err != nil {
+			log.Warn("[backfill] read %s: %s", name, err)
+			failed++
+			state.Cursor = name
+			continue
+		}
+		if !c.missing(file) {
+			scanned++
+			state.Cursor = name
+			continue
+		}
+
+		// 需要请求接口的歌曲之间保持间隔,避免长时间运行触发风控
+		if wait := c.interval - time.Since(last); !last.IsZero() && wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		last = time.Now()
+
+		ok, err := c.backfill(ctx, request, path, file)
+		if ctx.Err() != nil {
+			break
+		}
+		switch {
+		case err != nil:
+			log.Warn("[backfill] %s: %s", name, err)
+			failed++
+		case !ok:
+			state.Unmatched = append(state.Unmatched, name)
+			c.cmd.Printf("unmatched: %s\n", name)
+		default:
+			updated++
+			state.Updated++
+			c.cmd.Printf("updated: %s\n", name)
+		}
+		scanned++
+		state.Cursor = name
+		state.Time = time.Now()
+		if err := c.save(dir, state); err != nil {
+			return err
+		}
+	}
+
+	state.Time = time.Now()
+	if err := c.save(dir, state); err != nil {
+		return err
+	}
+	c.cmd.Printf("processed %d/%d files in %s, updated %d, failed %d, unmatched total %d\n",
+		scanned, len(names)-start, time.Since(begin).Truncate(time.Second), updated, failed, len(state.Unmatched))
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d files failed", failed)}
+	}
+	return nil
+}
+
+// files 递归获取目录下所有mp3/flac文件,返回排序后相对dir的路径
+func (c *libraryBackfillCmd) files(dir string) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".mp3", ".flac":
+		default:
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("WalkDir: %w", err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// missing 判断文件是否缺少需要补全的内容
+func (c *libraryBackfillCmd) missing(file *libraryFile) bool {
+	return (slices.Contains(c.fields, "id") && file.Id == 0) ||
+		(slices.Contains(c.fields, "cover") && !file.HasCover) ||
+		(slices.Contains(c.fields, "lyric") && !file.HasLyric)
+}
+
+// backfill 补全单个文件,歌曲匹配失败时返回false
+func (c *libraryBackfillCmd) backfill(ctx context.Context, request *weapi.Api, path string, file *libraryFile) (bool, error) {
+	var id = file.Id
+	if id == 0 {
+		v, err := c.match(ctx, request, path, file)
+		if err != nil {
+			return false, err
+		}
+		if v == 0 {
+			return false, nil
+		}
+		id = v
+	}
+
+	var (
+		writeId = slices.Contains(c.fields, "id") && file.Id == 0
+		lyric   string
+		cover   []byte
+	)
+	if slices.Contains(c.fields, "lyric") && !file.HasLyric {
+		resp, err := request.Lyric(ctx, &weapi.LyricReq{Id: id})
+		if err != nil {
+			return false, fmt.Errorf("Lyric(%d): %w", id, err)
+		}
+		lyric = resp.Lrc.Lyric
+	}
+	if slices.Contains(c.fields, "cover") && !file.HasCover {
+		resp, err := request.SongDetail(ctx, &weapi.SongDetailReq{C: []weapi.SongDetailReqList{{Id: fmt.Sprintf("%d", id)}}})
+		if err != nil {
+			return false, fmt.Errorf("SongDetail(%d): %w", id, err)
+		}
+		if len(resp.Songs) > 0 && resp.Songs[0].Al.PicUrl != "" {
+			var url = resp.Songs[0].Al.PicUrl
+			// 移除 URL 中的 query 参数，通常能获取到原图
+			if idx := strings.Index(url, "?"); idx > 0 {
+				url = url[:idx]
+			}
+			data, err := fetchCover(ctx, url)
+			if err != nil {
+				return false, fmt.Errorf("fetchCover(%s): %w", url, err)
+			}
+			if cover, err = ensureJpeg(data); err != nil {
+				return false, fmt.Errorf("ensureJpeg: %w", err)
+			}
+		}
+	}
+	if !writeId && lyric == "" && len(cover) == 0 {
+		return true, nil
+	}
+	if !writeId {
+		id = 0
+	}
+
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		err = backfillID3v2(path, id, lyric, cover)
+	case ".flac":
+		err = backfillFlac(path, id, lyric, cover)
+	}
+	if err != nil {
+		return false, err
+	}
+	updateLibraryChecksum(path)
+	return true, nil
+}
+
+// match 根据标题和歌手搜索歌曲,只接受标题完全一致的结果,标签缺失时使用文件名(歌手 - 标题)
+func (c *libraryBackfillCmd) match(ctx context.Context, request *weapi.Api, path string, file *libraryFile) (int64, error) {
+	var title, artist = file.Title, file.Artist
+	if title == "" {
+		var name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if ar, t, ok := strings.Cut(name, " - "); ok {
+			artist, title = ar, t
+		} else {
+			title = name
+		}
+	}
+	var keyword = strings.TrimSpace(title + " " + artist)
+	resp, err := request.CloudSearch(ctx, &weapi.CloudSearchReq{S: keyword, Type: weapi.SearchTypeSong, Limit: 10})
+	if err != nil {
+		return 0, fmt.Errorf("CloudSearch: %w", err)
+	}
+	for _, s := range resp.Result.Songs {
+		if strings.EqualFold(strings.TrimSpace(s.Name), strings.TrimSpace(title)) {
+			return s.Id, nil
+		}
+	}
+	return 0, nil
+}
+
+// updateLibraryChecksum 文件修改后更新所在目录清单中记录的校验和,未记录的文件不处理
+func updateLibraryChecksum(path string) {
+	var dir, name = filepath.Dir(path), filepath.Base(path)
+	for _, alg := range []checksum.Algorithm{checksum.MD5, checksum.SHA256} {
+		list, err := checksum.Load(dir, alg)
+		if err != nil {
+			log.Warn("[library] load checksum %s: %s", dir, err)
+			continue
+		}
+		if _, ok := list[name]; !ok {
+			continue
+		}
+		sum, err := checksum.Sum(path, alg)
+		if err != nil {
+			log.Warn("[library] checksum %s: %s", path, err)
+			continue
+		}
+		if err := checksum.Append(dir, name, alg, sum); err != nil {
+			log.Warn("[library] record checksum %s: %s", path, err)
+		}
+	}
+}
+
+func (c *libraryBackfillCmd) load(dir string) (*backfillState, error) {
+	var state backfillState
+	data, err := os.ReadFile(filepath.Join(dir, backfillStateName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &state, nil
+		}
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return &state, nil
+}
+
+// save 先写临时文件再重命名,避免中断时进度文件损坏
+func (c *libraryBackfillCmd) save(dir string, state *backfillState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	var (
+		path = filepath.Join(dir, backfillStateName)
+		tmp  = path + ".tmp"
+	)
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("Rename: %w", err)
+	}
+	return nil
+}
+
+// readLibraryFile 读取mp3/flac文件已有的标签
+func readLibraryFile(path string) (*libraryFile, error) {
+	var file libraryFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+		if err != nil {
+			return nil, err
+		}
+		defer tag.Close()
+		file.Title, file.Artist, file.Album = tag.Title(), tag.Artist(), tag.Album()
+		file.HasCover = len(tag.GetFrames(tag.CommonID("Attached picture"))) > 0
+		for _, f := range tag.GetFrames(tag.CommonID("Unsynchronised lyrics/text transcription")) {
+			if uslt, ok := f.(id3v2.UnsynchronisedLyricsFrame); ok && uslt.Lyrics != "" {
+				file.HasLyric = true
+			}
+		}
+		for _, f := range tag.GetFrames(tag.CommonID("User defined text information frame")) {
+			if txxx, ok := f.(id3v2.UserDefinedTextFrame); ok && txxx.Description == songIdTag {
+				file.Id, _ = strconv.ParseInt(txxx.Value, 10, 64)
+			}
+		}
+	case ".flac":
+		f, err := flac.ParseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		for _, b := range f.Meta {
+			switch b.Type {
+			case flac.Picture:
+				file.HasCover = true
+			case flac.VorbisComment:
+				cmts, err := flacvorbis.ParseFromMetaDataBlock(*b)
+				if err != nil {
+					return nil, err
+				}
+				var get = func(key string) string {
+					v, _ := cmts.Get(key)
+					if len(v) <= 0 {
+						return ""
+					}
+					return v[0]
+				}
+				file.Title, file.Artist, file.Album = get(flacvorbis.FIELD_TITLE), get(flacvorbis.FIELD_ARTIST), get(flacvorbis.FIELD_ALBUM)
+				file.HasLyric = get("LYRICS") != ""
+				file.Id, _ = strconv.ParseInt(get(songIdTag), 10, 64)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", filepath.Ext(path))
+	}
+	return &file, nil
+}
+
+// backfillID3v2 追加写入歌曲id、歌词、封面,值为空时不写入,已有的标签保持不变
+func backfillID3v2(path string, id int64, lyric string, cover []byte) error {
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		return err
+	}
+	defer tag.Close()
+
+	tag.SetDefaultEncoding(id3v2.EncodingUTF8)
+	if id != 0 {
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    id3v2.EncodingUTF8,
+			Description: songIdTag,
+			Value:       fmt.Sprintf("%d", id),
+		})
+	}
+	if lyric != "" {
+		tag.AddUnsynchronisedLyricsFrame(id3v2.UnsynchronisedLyricsFrame{
+			Encoding: id3v2.EncodingUTF8,
+			Language: "zho",
+			Lyrics:   lyric,
+		})
+	}
+	if len(cover) > 0 {
+		tag.AddAttachedPicture(id3v2.PictureFrame{
+			Encoding:    id3v2.EncodingUTF8,
+			MimeType:    "image/jpeg",
+			PictureType: id3v2.PTFrontCover,
+			Description: "Cover",
+			Picture:     cover,
+		})
+	}
+	return tag.Save()
+}
+
+// backfillFlac 追加写入歌曲id、歌词、封面,值为空时不写入,已有的标签保持不变
+func backfillFlac(path string, id int64, lyric string, cover []byte) error {
+	f, err := flac.ParseFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		cmts   *flacvorbis.MetaDataBlockVorbisComment
+		cmtIdx = -1
+	)
+	for i, b := range f.Meta {
+		if b.Type == flac.VorbisComment {
+			if cmts, err = flacvorbis.ParseFromMetaDataBlock(*b); err != nil {
+				return err
+			}
+			cmtIdx = i
+			break
+		}
+	}
+	if cmts == nil {
+		cmts = flacvorbis.New()
+	}
+	if id != 0 {
+		_ = cmts.Add(songIdTag, fmt.Sprintf("%d", id))
+	}
+	if lyric != "" {
+		_ = cmts.Add("LYRICS", lyric)
+	}
+	var res = cmts.Marshal()
+	if cmtIdx >= 0 {
+		f.Meta[cmtIdx] = &res
+	} else {
+		f.Meta = append(f.Meta, &res)
+	}
+
+	if len(cover) > 0 {
+		picture, err := flacpicture.NewFromImageData(flacpicture.PictureTypeFrontCover, "Front Cover", cover, "image/jpeg")
+		if err != nil {
+			return fmt.Errorf("NewFromImageData: %w", err)
+		}
+		var block = picture.Marshal()
+		f.Meta = append(f.Meta, &block)
+	}
+	return f.Save(path)
+}
@@ -0,0 +1,213 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type LikeOpts struct {
+	FromFile string        // 从文件读取歌曲id,-表示标准输入
+	Unlike   bool          // 取消喜欢
+	Batch    int           // 每批处理的歌曲数量
+	Interval time.Duration // 两批之间的间隔
+}
+
+type Like struct {
+	root *Root
+	cmd  *cobra.Command
+	opts LikeOpts
+	l    *log.Logger
+}
+
+func NewLike(root *Root, l *log.Logger) *Like {
+	c := &Like{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "like <songids...>",
+			Short: "[need login] Like or unlike songs in batches",
+			Example: "  ncmctl like 1958384591 https://music.163.com/song?id=1820944399\n" +
+				"  ncmctl like --from-file ids.txt --interval 3s\n" +
+				"  ncmctl like --unlike 1958384591\n" +
+				"  cat ids.txt | ncmctl like --from-file -",
+			ValidArgsFunction: root.completeSongs,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args)
+	}
+	return c
+}
+
+func (c *Like) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.FromFile, "from-file", "", "read song ids or links from file, one per line, - means stdin")
+	c.cmd.Flags().BoolVar(&c.opts.Unlike, "unlike", false, "unlike songs")
+	c.cmd.Flags().IntVar(&c.opts.Batch, "batch", playlistTracksBatch, "songs count per request, max 1000")
+	c.cmd.Flags().DurationVar(&c.opts.Interval, "interval", time.Second, "interval between two requests")
+}
+
+func (c *Like) validate() error {
+	if c.opts.Batch <= 0 || c.opts.Batch > 1000 {
+		return fmt.Errorf("batch must be in range 1-1000")
+	}
+	if c.opts.Interval < 0 {
+		return fmt.Errorf("interval must be greater than 0")
+	}
+	return nil
+}
+
+func (c *Like) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Like) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Like) execute(ctx context.Context, args []string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	if c.opts.FromFile != "" {
+		var f = os.Stdin
+		if c.opts.FromFile != "-" {
+			file, err := os.Open(c.opts.FromFile)
+			if err != nil {
+				return fmt.Errorf("Open: %w", err)
+			}
+			defer file.Close()
+			f = file
+		}
+		fields, err := readFields(f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", c.opts.FromFile, err)
+		}
+		args = append(args, fields...)
+	}
+	ids, err := parseSongIds(args)
+	if err != nil {
+		return err
+	}
+	if len(ids) <= 0 {
+		return fmt.Errorf("no song entered")
+	}
+	c.root.cacheSongs(completionSongIds(ids)...)
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	var uid = user.Account.Id
+
+	// 过滤掉已经是目标状态的歌曲,减少请求次数
+	liked, err := likedSongIds(ctx, request, uid)
+	if err != nil {
+		return err
+	}
+	var (
+		set    = make(map[int64]bool, len(liked))
+		todo   types.IntsString
+		op     = "add"
+		action = "like"
+	)
+	for _, id := range liked {
+		set[id] = true
+	}
+	if c.opts.Unlike {
+		op, action = "del", "unlike"
+	}
+	for _, id := range ids {
+		if set[id] == c.opts.Unlike {
+			todo = append(todo, id)
+		}
+	}
+	if skip := len(ids) - len(todo); skip > 0 {
+		c.cmd.Printf("skip %d songs already in target state\n", skip)
+	}
+	if len(todo) <= 0 {
+		return nil
+	}
+
+	// 喜欢的歌曲即"我喜欢的音乐"歌单中的歌曲,批量操作该歌单比逐首调用喜欢接口请求次数少得多
+	pid, err := likedPlaylistId(ctx, request, uid)
+	if err != nil {
+		return err
+	}
+	var failed int
+	for i := 0; i < len(todo); i += c.opts.Batch {
+		if i > 0 {
+			if err := sleep(ctx, c.opts.Interval); err != nil {
+				return err
+			}
+		}
+		var batch = todo[i:min(i+c.opts.Batch, len(todo))]
+		_, err := request.PlaylistAddOrDel(ctx, &weapi.PlaylistAddOrDelReq{Op: op, Pid: pid, TrackIds: batch, Imme: true})
+		if err != nil {
+			failed += len(batch)
+			log.Error("[like] %s %v: %s", op, batch, err)
+			c.cmd.PrintErrf("%d songs failed: %s\n", len(batch), err)
+			continue
+		}
+		c.cmd.Printf("[%d/%d] %s %d songs\n", min(i+c.opts.Batch, len(todo)), len(todo), action, len(batch))
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(todo):
+		return fmt.Errorf("all %d songs failed", failed)
+	default:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d songs failed", failed, len(todo))}
+	}
+}
+
+// likedSongIds 获取用户喜欢的全部歌曲id,按喜欢时间倒序
+func likedSongIds(ctx context.Context, request *weapi.Api, uid int64) ([]int64, error) {
+	resp, err := request.SongLikeList(ctx, &weapi.SongLikeListReq{Uid: fmt.Sprintf("%d", uid)})
+	if err != nil {
+		return nil, fmt.Errorf("SongLikeList: %w", err)
+	}
+	return resp.Ids, nil
+}
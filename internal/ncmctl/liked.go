@@ -0,0 +1,145 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type LikedOpts struct {
+	Download bool
+	Output   string
+	Level    string
+	Parallel int64
+}
+
+type Liked struct {
+	root *Root
+	cmd  *cobra.Command
+	opts LikedOpts
+	l    *log.Logger
+}
+
+func NewLiked(root *Root, l *log.Logger) *Liked {
+	c := &Liked{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "liked",
+			Short: "[need login] List or download all songs in the liked playlist",
+			Example: "  ncmctl liked\n" +
+				"  ncmctl liked --download -o ./liked -l hires\n" +
+				"  ncmctl liked sync ./liked --watch 1m",
+			Args: cobra.NoArgs,
+		},
+	}
+	c.addFlags()
+	c.Add(likedSync(c, l))
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *Liked) addFlags() {
+	c.cmd.Flags().BoolVar(&c.opts.Download, "download", false, "download all liked songs")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./download", "music file output path")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().Int64VarP(&c.opts.Parallel, "parallel", "p", 5, "concurrent download count")
+}
+
+func (c *Liked) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Liked) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Liked) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	if c.opts.Download {
+		liked, err := likedSongIds(ctx, request, user.Account.Id)
+		if err != nil {
+			return err
+		}
+		if len(liked) <= 0 {
+			c.cmd.Println("no liked songs")
+			return nil
+		}
+		var ids = make([]string, 0, len(liked))
+		for _, id := range liked {
+			ids = append(ids, strconv.FormatInt(id, 10))
+		}
+		c.cmd.Printf("liked: %d songs\n", len(ids))
+
+		d := NewDownload(c.root, c.l)
+		d.opts.Output = c.opts.Output
+		d.opts.Level = c.opts.Level
+		d.opts.Parallel = c.opts.Parallel
+		return d.execute(ctx, ids)
+	}
+
+	pid, err := likedPlaylistId(ctx, request, user.Account.Id)
+	if err != nil {
+		return err
+	}
+	_, songs, err := playlistSongs(ctx, c.root, request, pid)
+	if err != nil {
+		return err
+	}
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tID\tNAME\tARTIST\tALBUM\tDURATION")
+	for i, s := range songs {
+		var d = time.Duration(s.Time) * time.Millisecond
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%02d:%02d\n", i+1, s.Id, s.Name, artistNames(s.Artist), s.Album.Name, int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return w.Flush()
+}
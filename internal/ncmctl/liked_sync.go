@@ -0,0 +1,492 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/plan"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// likedSyncStateFile 同步目录中的状态文件,记录本地文件与喜欢歌曲的对应关系
+const likedSyncStateFile = ".ncmctl-liked.json"
+
+const (
+	likedOriginLocal  = "local"  // 本地放入后上传到云盘
+	likedOriginRemote = "remote" // 喜欢后下载到本地
+)
+
+type likedSyncOpts struct {
+	Level         string
+	Parallel      int64
+	Settle        time.Duration // 文件最后修改时间距今超过该时长才上传,避免上传未复制完成的文件
+	Watch         time.Duration // 轮询间隔,为0时仅同步一次
+	OnLocalDelete string        // 本地删除已同步文件时的处理方式 keep: 保持喜欢且不再下载 unlike: 取消喜欢 restore: 重新下载
+	OnUnlike      string        // 取消喜欢歌曲时的处理方式 keep: 保留本地文件 delete: 删除本地文件
+	DryRun        bool
+}
+
+type likedSyncCmd struct {
+	root *Liked
+	cmd  *cobra.Command
+	opts likedSyncOpts
+	l    *log.Logger
+}
+
+// likedSyncState 同步状态,Ignored为本地删除后保持喜欢的歌曲,不会再次下载
+type likedSyncState struct {
+	SyncTime int64           `json:"syncTime"`
+	Files    []likedSyncFile `json:"files"`
+	Ignored  []int64         `json:"ignored,omitempty"`
+}
+
+type likedSyncFile struct {
+	Id      int64  `json:"id"`                // 歌曲id,本地上传的文件为云盘歌曲id
+	File    string `json:"file"`              // 相对同步目录的路径
+	Origin  string `json:"origin"`            // local: 本地放入 remote: 喜欢后下载
+	Unliked bool   `json:"unliked,omitempty"` // 已取消喜欢但保留了本地文件
+}
+
+func likedSync(root *Liked, l *log.Logger) *cobra.Command {
+	c := &likedSyncCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "sync <dir>",
+		Short: "[need login] Two-way sync between the liked songs and a local folder",
+		Long: "Two-way sync between the liked songs and a local folder: audio files dropped into the folder are uploaded\n" +
+			"to the cloud disk and liked, newly liked songs are downloaded into the folder.\n\n" +
+			"Conflict rules:\n" +
+			"  - a dropped file named \"artist - name\" of a liked song that is not synced yet is linked to that song,\n" +
+			"    it is neither uploaded nor downloaded again\n" +
+			"  - a liked song already linked to a local file is never downloaded again\n" +
+			"  - files changed after sync are not uploaded again\n" +
+			"  - --on-unlike delete only deletes files downloaded by sync, dropped files are always kept\n" +
+			"  - a kept file of an unliked song is not uploaded again, liking the song again links it back\n" +
+			"  - --on-local-delete keep remembers the song so it will not be downloaded again",
+		Example: "  ncmctl liked sync ./liked\n" +
+			"  ncmctl liked sync ./liked --watch 1m --on-unlike delete\n" +
+			"  ncmctl liked sync ./liked --on-local-delete unlike --dry-run",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args[0])
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *likedSyncCmd) addFlags() {
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().Int64VarP(&c.opts.Parallel, "parallel", "p", 5, "concurrent download count")
+	c.cmd.Flags().DurationVar(&c.opts.Settle, "settle", 30*time.Second, "only upload files not modified within this duration")
+	c.cmd.Flags().DurationVar(&c.opts.Watch, "watch", 0, "keep watching the folder and sync at this interval, 0 to sync once")
+	c.cmd.Flags().StringVar(&c.opts.OnLocalDelete, "on-local-delete", "keep", "when a synced file is deleted locally. support: keep、unlike、restore")
+	c.cmd.Flags().StringVar(&c.opts.OnUnlike, "on-unlike", "keep", "when a synced song is unliked. support: keep、delete")
+	c.cmd.Flags().BoolVar(&c.opts.DryRun, "dry-run", false, "only print the sync plan")
+}
+
+func (c *likedSyncCmd) validate() error {
+	switch c.opts.OnLocalDelete {
+	case "keep", "unlike", "restore":
+	default:
+		return fmt.Errorf("on-local-delete is not support: %s", c.opts.OnLocalDelete)
+	}
+	switch c.opts.OnUnlike {
+	case "keep", "delete":
+	default:
+		return fmt.Errorf("on-unlike is not support: %s", c.opts.OnUnlike)
+	}
+	if c.opts.Watch < 0 || c.opts.Settle < 0 {
+		return fmt.Errorf("watch and settle must not be negative")
+	}
+	return nil
+}
+
+func (c *likedSyncCmd) execute(ctx context.Context, dir string) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	dir, err := utils.ExpandTilde(dir)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if err := utils.MkdirIfNotExist(dir, 0755); err != nil {
+		return fmt.Errorf("MkdirIfNotExist: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	pid, err := likedPlaylistId(ctx, request, user.Account.Id)
+	if err != nil {
+		return err
+	}
+
+	if c.opts.Watch <= 0 {
+		return c.sync(ctx, request, pid, dir)
+	}
+	for {
+		// 轮询模式下单次同步失败不退出,下次继续重试
+		if err := c.sync(ctx, request, pid, dir); err != nil {
+			var e *exitError
+			if errors.As(err, &e) && e.code == ExitNeedLogin {
+				return err
+			}
+			c.cmd.PrintErrf("sync: %s\n", err)
+			log.Error("[liked-sync] sync err: %s", err)
+		}
+		if err := sleep(ctx, c.opts.Watch); err != nil {
+			return nil
+		}
+	}
+}
+
+// sync 执行一次双向同步:本地新文件上传并喜欢,新喜欢的歌曲下载,并按冲突规则处理两端的删除。
+// dry-run时只输出同步计划
+func (c *likedSyncCmd) sync(ctx context.Context, request *weapi.Api, pid int64, dir string) error {
+	_, songs, err := playlistSongs(ctx, c.root.root, request, pid)
+	if err != nil {
+		return err
+	}
+	state, err := loadLikedSyncState(dir)
+	if err != nil {
+		return err
+	}
+	var (
+		liked   = make(map[int64]Music, len(songs))
+		ignored = make(map[int64]bool, len(state.Ignored))
+		tracked = make(map[string]bool, len(state.Files))
+		linked  = make(map[int64]bool, len(state.Files))
+		byName  = make(map[string]Music, len(songs))
+		files   []likedSyncFile
+		deleted []likedSyncFile
+		errs    []error
+		p       plan.Plan
+	)
+	for _, s := range songs {
+		liked[s.Id] = s
+		byName[fmt.Sprintf("%s - %s", s.ArtistString(), s.NameString())] = s
+	}
+	for _, id := range state.Ignored {
+		// 取消喜欢后不再需要忽略
+		if _, ok := liked[id]; ok {
+			ignored[id] = true
+		}
+	}
+	for _, f := range state.Files {
+		tracked[f.File] = true
+		if !utils.FileExists(filepath.Join(dir, f.File)) {
+			deleted = append(deleted, f)
+			continue
+		}
+		files = append(files, f)
+		linked[f.Id] = true
+	}
+
+	// 1.扫描本地新放入的文件,与未同步的喜欢歌曲同名的直接关联,其余上传
+	dropped, err := c.scan(dir, tracked)
+	if err != nil {
+		return err
+	}
+	var uploads []string
+	for _, file := range dropped {
+		var name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if s, ok := byName[name]; ok && !linked[s.Id] {
+			log.Info("[liked-sync] link %s to liked song %d", file, s.Id)
+			files = append(files, likedSyncFile{Id: s.Id, File: file, Origin: likedOriginLocal})
+			linked[s.Id] = true
+			continue
+		}
+		uploads = append(uploads, file)
+	}
+
+	// 2.处理取消喜欢的歌曲,本地放入的文件始终保留
+	var unliked int
+	for i, f := range files {
+		_, ok := liked[f.Id]
+		if ok || f.Unliked {
+			files[i].Unliked = f.Unliked && !ok
+			continue
+		}
+		unliked++
+		if c.opts.OnUnlike == "delete" && f.Origin == likedOriginRemote {
+			if c.opts.DryRun {
+				var size int64
+				if info, err := os.Stat(filepath.Join(dir, f.File)); err == nil {
+					size = info.Size()
+				}
+				p.Remove("local/"+f.File, size)
+				continue
+			}
+			c.cmd.Printf("unliked: delete %s\n", f.File)
+			if err := os.Remove(filepath.Join(dir, f.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Warn("[liked-sync] remove %s: %s", f.File, err)
+				files[i].Unliked = true
+				continue
+			}
+			files[i].File = ""
+			continue
+		}
+		if !c.opts.DryRun {
+			c.cmd.Printf("unliked: keep %s\n", f.File)
+		}
+		files[i].Unliked = true
+	}
+	files = slices.DeleteFunc(files, func(f likedSyncFile) bool { return f.File == "" })
+
+	// 3.处理本地删除的文件
+	var unlikes types.IntsString
+	for _, f := range deleted {
+		if _, ok := liked[f.Id]; !ok || linked[f.Id] {
+			continue
+		}
+		if !c.opts.DryRun {
+			c.cmd.Printf("deleted locally: %s (%s)\n", f.File, c.opts.OnLocalDelete)
+		}
+		switch c.opts.OnLocalDelete {
+		case "keep":
+			ignored[f.Id] = true
+		case "unlike":
+			unlikes = append(unlikes, f.Id)
+			p.Remove("liked/"+liked[f.Id].String(), 0)
+		case "restore":
+			// 未关联本地文件的喜欢歌曲会在下载阶段重新下载
+		}
+	}
+
+	// 4.下载新喜欢的歌曲
+	var downloads []Music
+	for _, s := range songs {
+		if !linked[s.Id] && !ignored[s.Id] && !slices.Contains(unlikes, s.Id) {
+			downloads = append(downloads, s)
+		}
+	}
+	if c.opts.DryRun {
+		// 上传的文件同时会被喜欢,下载大小在获取下载地址后才能确定
+		for _, f := range uploads {
+			var size int64
+			if info, err := os.Stat(filepath.Join(dir, f)); err == nil {
+				size = info.Size()
+			}
+			p.Add("upload/"+f, size)
+		}
+		for _, s := range downloads {
+			p.Add("download/"+s.String(), 0)
+		}
+		return p.Write(c.cmd.OutOrStdout())
+	}
+	if len(uploads) > 0 || len(downloads) > 0 || len(unlikes) > 0 {
+		c.cmd.Printf("sync %s: %d to upload, %d to download, %d to unlike\n", dir, len(uploads), len(downloads), len(unlikes))
+	}
+
+	if len(unlikes) > 0 {
+		if err := c.edit(ctx, request, pid, "del", unlikes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(downloads) > 0 {
+		d := NewDownload(c.root.root, c.l)
+		d.opts.Output = dir
+		d.opts.Level = c.opts.Level
+		d.opts.Parallel = c.opts.Parallel
+		d.opts.Report = ""
+		if err := d.run(ctx, func(ctx context.Context, request *weapi.Api) ([]Music, error) {
+			return downloads, nil
+		}); err != nil {
+			errs = append(errs, err)
+		}
+		if d.report != nil {
+			for _, r := range d.report.Songs {
+				if r.Status != downloadOk || r.File == "" {
+					continue
+				}
+				rel, err := filepath.Rel(dir, r.File)
+				if err != nil {
+					rel = filepath.Base(r.File)
+				}
+				files = append(files, likedSyncFile{Id: r.Id, File: rel, Origin: likedOriginRemote})
+			}
+		}
+	}
+
+	// 5.上传本地新放入的文件并喜欢,上传失败的文件下次同步时重试
+	var (
+		cloud = NewCloud(c.root.root, c.l)
+		likes types.IntsString
+	)
+	for i, file := range uploads {
+		c.cmd.Printf("[%d/%d] upload %s\n", i+1, len(uploads), file)
+		songId, err := cloud.upload(ctx, request, filepath.Join(dir, file), nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("upload %s: %w", file, err))
+			log.Error("[liked-sync] upload %s: %s", file, err)
+			continue
+		}
+		id, _ := strconv.ParseInt(songId, 10, 64)
+		files = append(files, likedSyncFile{Id: id, File: file, Origin: likedOriginLocal})
+		if _, ok := liked[id]; !ok && !slices.Contains(likes, id) {
+			likes = append(likes, id)
+		}
+	}
+	if len(likes) > 0 {
+		if err := c.edit(ctx, request, pid, "add", likes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var next = likedSyncState{SyncTime: time.Now().UnixMilli(), Files: files}
+	for id := range ignored {
+		next.Ignored = append(next.Ignored, id)
+	}
+	slices.Sort(next.Ignored)
+	if err := next.write(dir); err != nil {
+		return err
+	}
+	if unliked > 0 {
+		log.Info("[liked-sync] %d songs unliked", unliked)
+	}
+	return errors.Join(errs...)
+}
+
+// scan 返回目录中未同步的音频文件相对路径,跳过隐藏文件及最近仍在修改的文件
+func (c *likedSyncCmd) scan(dir string, tracked map[string]bool) ([]string, error) {
+	var (
+		list   []string
+		settle = time.Now().Add(-c.opts.Settle)
+	)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !utils.IsMusicExt(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if tracked[rel] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(settle) {
+			log.Debug("[liked-sync] %s is still changing, skip", rel)
+			return nil
+		}
+		if info.Size() <= 0 || info.Size() > maxSize {
+			log.Warn("[liked-sync] %s file size %d is not supported, skip", rel, info.Size())
+			return nil
+		}
+		list = append(list, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("WalkDir: %w", err)
+	}
+	return list, nil
+}
+
+// edit 批量喜欢或取消喜欢歌曲,即向"我喜欢的音乐"歌单添加或删除歌曲
+func (c *likedSyncCmd) edit(ctx context.Context, request *weapi.Api, pid int64, op string, ids types.IntsString) error {
+	for i := 0; i < len(ids); i += playlistTracksBatch {
+		var batch = ids[i:min(i+playlistTracksBatch, len(ids))]
+		_, err := request.PlaylistAddOrDel(ctx, &weapi.PlaylistAddOrDelReq{Op: op, Pid: pid, TrackIds: batch, Imme: true})
+		if err != nil {
+			log.Error("[liked-sync] %s %v: %s", op, batch, err)
+			return fmt.Errorf("PlaylistAddOrDel(%s): %w", op, err)
+		}
+	}
+	return nil
+}
+
+func loadLikedSyncState(dir string) (*likedSyncState, error) {
+	var state likedSyncState
+	data, err := os.ReadFile(filepath.Join(dir, likedSyncStateFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &state, nil
+		}
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return &state, nil
+}
+
+// write 先写入临时文件再重命名,避免中断时状态文件损坏
+func (s *likedSyncState) write(dir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	var file = filepath.Join(dir, likedSyncStateFile)
+	if err := os.WriteFile(file+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/scrobbler"
+
+	"github.com/spf13/cobra"
+)
+
+type Listens struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewListens(root *Root, l *log.Logger) *Listens {
+	c := &Listens{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "listens",
+			Short: "Forward listening history to Last.fm and ListenBrainz",
+			Long: "Forward listening history to Last.fm and ListenBrainz, services are configured in the scrobbler section of config file.\n" +
+				"Songs played by play, fm and mpd commands are forwarded in real time, songs played by other clients\n" +
+				"can be forwarded by polling the recently played list with the sync subcommand.",
+			Example: "  ncmctl listens auth\n" +
+				"  ncmctl listens sync\n" +
+				"  ncmctl listens flush",
+		},
+	}
+	c.addFlags()
+	c.Add(listensSync(c, l))
+	c.Add(listensAuth(c, l))
+	c.Add(listensFlush(c, l))
+	return c
+}
+
+func (c *Listens) addFlags() {}
+
+func (c *Listens) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Listens) Command() *cobra.Command {
+	return c.cmd
+}
+
+// newForwarder 创建听歌记录转发器,未配置任何服务时返回nil
+func newForwarder(root *Root) *scrobbler.Forwarder {
+	f, err := scrobbler.New(root.Cfg.Scrobbler)
+	if err != nil {
+		if !errors.Is(err, scrobbler.ErrNotConfigured) {
+			log.Warn("[listens] init scrobbler: %s", err)
+		}
+		return nil
+	}
+	return f
+}
+
+// musicTrack 转换为听歌记录,多个歌手时只使用第一个歌手便于服务匹配
+func musicTrack(song Music, start time.Time) scrobbler.Track {
+	var track = scrobbler.Track{
+		SongId:    song.Id,
+		Title:     song.Name,
+		Album:     song.Album.Name,
+		Duration:  time.Duration(song.Time) * time.Millisecond,
+		Timestamp: start,
+	}
+	if len(song.Artist) > 0 {
+		track.Artist = song.Artist[0].Name
+	}
+	return track
+}
+
+// forwardPlay 转发播放模式下的听歌记录,未配置转发或播放时长不满足提交条件时忽略
+func forwardPlay(ctx context.Context, f *scrobbler.Forwarder, song Music, start time.Time, played time.Duration) {
+	if f == nil || !scrobbler.Eligible(time.Duration(song.Time)*time.Millisecond, played) {
+		return
+	}
+	if _, err := f.Scrobble(ctx, musicTrack(song, start)); err != nil {
+		log.Warn("[listens] forward %s: %s", song, err)
+	}
+}
+
+type listensFlushCmd struct {
+	root *Listens
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func listensFlush(root *Listens, l *log.Logger) *cobra.Command {
+	c := &listensFlushCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "flush",
+		Short:   "Retry the listens saved in the offline queue",
+		Example: "  ncmctl listens flush",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	return c.cmd
+}
+
+func (c *listensFlushCmd) execute(ctx context.Context) error {
+	f, err := scrobbler.New(c.root.root.Cfg.Scrobbler)
+	if err != nil {
+		return fmt.Errorf("%w, configure lastfm or listenbrainz in the scrobbler section of config file", err)
+	}
+	left, err := f.Flush(ctx)
+	if err != nil {
+		return err
+	}
+	c.cmd.Printf("offline queue flushed, %d listens left\n", left)
+	return nil
+}
@@ -0,0 +1,88 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/scrobbler"
+
+	"github.com/spf13/cobra"
+)
+
+type listensAuthCmd struct {
+	root *Listens
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func listensAuth(root *Listens, l *log.Logger) *cobra.Command {
+	c := &listensAuthCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "auth",
+		Short: "Authorize Last.fm and print the session key",
+		Long: "Authorize Last.fm and print the session key, scrobbler.lastfm.apiKey and secret must be configured first.\n" +
+			"Write the printed session key to scrobbler.lastfm.sessionKey of config file to enable forwarding.",
+		Example: "  ncmctl listens auth",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	return c.cmd
+}
+
+func (c *listensAuthCmd) execute(ctx context.Context) error {
+	var cfg = c.root.root.Cfg.Scrobbler
+	if cfg == nil || cfg.Lastfm == nil {
+		return fmt.Errorf("scrobbler.lastfm is not configured")
+	}
+	lastfm, err := scrobbler.NewLastfm(cfg.Lastfm)
+	if err != nil {
+		return err
+	}
+
+	token, err := lastfm.Token(ctx)
+	if err != nil {
+		return err
+	}
+	c.cmd.Printf("open the url in browser and allow access, then press enter to continue:\n%s\n", lastfm.AuthURL(token))
+	if _, err := bufio.NewReader(os.Stdin).ReadString('\n'); err != nil {
+		return fmt.Errorf("read stdin: %w", err)
+	}
+
+	name, key, err := lastfm.Session(ctx, token)
+	if err != nil {
+		return err
+	}
+	c.cmd.Printf("authorized as %s, write the session key to scrobbler.lastfm.sessionKey of config file:\n%s\n", name, key)
+	return nil
+}
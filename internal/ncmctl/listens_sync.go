@@ -0,0 +1,155 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/scrobbler"
+
+	"github.com/spf13/cobra"
+)
+
+type listensSyncCmd struct {
+	root *Listens
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	limit int64 // 获取最近播放的歌曲数量
+	all   bool  // 首次同步时提交所有最近播放的歌曲
+}
+
+func listensSync(root *Listens, l *log.Logger) *cobra.Command {
+	c := &listensSyncCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "sync",
+		Short: "[need login] Forward the recently played songs since last sync",
+		Long: "Forward the recently played songs since last sync, suitable for running periodically by daemon.\n" +
+			"The recently played list only keeps the latest play of each song, so repeated plays between two syncs are counted once.\n" +
+			"The first sync only records the progress unless --all is specified.",
+		Example: "  ncmctl listens sync\n" +
+			"  ncmctl listens sync --all --limit 100",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *listensSyncCmd) addFlags() {
+	c.cmd.Flags().Int64Var(&c.limit, "limit", 300, "number of recently played songs to fetch, max 300")
+	c.cmd.Flags().BoolVar(&c.all, "all", false, "forward all fetched songs on the first sync")
+}
+
+func (c *listensSyncCmd) execute(ctx context.Context) error {
+	if c.limit <= 0 || c.limit > 300 {
+		return fmt.Errorf("limit must be in range [1,300]")
+	}
+	f, err := scrobbler.New(c.root.root.Cfg.Scrobbler)
+	if err != nil {
+		return fmt.Errorf("%w, configure lastfm or listenbrainz in the scrobbler section of config file", err)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	resp, err := request.RecentSongs(ctx, &weapi.RecentSongsReq{Limit: c.limit})
+	if err != nil {
+		return fmt.Errorf("RecentSongs: %w", err)
+	}
+
+	checkpoint, err := f.Checkpoint()
+	if err != nil {
+		return err
+	}
+	var (
+		list   = resp.Data.List
+		latest = checkpoint
+	)
+	slices.SortFunc(list, func(a, b weapi.RecentSongsRespDataList) int {
+		return cmp.Compare(a.PlayTime, b.PlayTime)
+	})
+	if len(list) > 0 {
+		if last := time.UnixMilli(list[len(list)-1].PlayTime); last.After(latest) {
+			latest = last
+		}
+	}
+	if checkpoint.IsZero() && !c.all {
+		if err := f.SetCheckpoint(latest); err != nil {
+			return err
+		}
+		c.cmd.Printf("first sync, progress is recorded at %s. use --all to forward the recently played songs\n", latest.Format(time.DateTime))
+		return nil
+	}
+
+	var tracks []scrobbler.Track
+	for _, v := range list {
+		var played = time.UnixMilli(v.PlayTime)
+		if !played.After(checkpoint) || v.Data.Id <= 0 || !strings.EqualFold(v.ResourceType, "SONG") {
+			continue
+		}
+		var song = Music{
+			Id:     v.Data.Id,
+			Name:   v.Data.Name,
+			Artist: v.Data.Ar,
+			Album:  v.Data.Al,
+			Time:   v.Data.Dt,
+		}
+		// 播放时间为播放结束时上报的时间,减去歌曲时长作为开始播放时间
+		tracks = append(tracks, musicTrack(song, played.Add(-time.Duration(song.Time)*time.Millisecond)))
+	}
+
+	n, err := f.Scrobble(ctx, tracks...)
+	// 提交失败的记录已保存到离线队列,同步进度照常更新
+	if err := f.SetCheckpoint(latest); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("[listens] synced %d/%d listens to %s", n, len(tracks), strings.Join(f.Services(), ","))
+	c.cmd.Printf("forwarded %d listens to %s\n", n, strings.Join(f.Services(), ","))
+	return nil
+}
@@ -40,9 +40,10 @@ func NewLogin(root *Root, l *log.Logger) *Login {
 		root: root,
 		l:    l,
 		cmd: &cobra.Command{
-			Use:     "login",
-			Short:   "Login netease cloud music",
-			Example: "  ncmctl login -h\n  ncmctl login qrcode\n  ncmctl login phone\n  ncmctl login cookiecloud\n  ncmctl login cookie",
+			Use:         "login",
+			Annotations: map[string]string{skipKeepAlive: ""},
+			Short:       "Login netease cloud music",
+			Example:     "  ncmctl login -h\n  ncmctl login qrcode\n  ncmctl login phone\n  ncmctl login cookiecloud\n  ncmctl login cookie\n  ncmctl login export",
 		},
 	}
 	c.addFlags()
@@ -50,6 +51,7 @@ func NewLogin(root *Root, l *log.Logger) *Login {
 	c.Add(phone(c, l))
 	c.Add(cookieCloud(c, l))
 	c.Add(cookie(c, l))
+	c.Add(export(c, l))
 
 	return c
 }
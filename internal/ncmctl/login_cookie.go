@@ -122,9 +122,10 @@ func cookie(root *Login, l *log.Logger) *cobra.Command {
 	}
 	c.cmd = &cobra.Command{
 		Use:     "cookie",
+		Aliases: []string{"import"},
 		Short:   "use cookie login",
 		Long:    cookieLongUse,
-		Example: "  ncmctl login cookie -f cookie.txt\n  ncmctl login cookie --format netscaple -f cookie.json\n  ncmctl login cookie 'value'\n  ncmctl login cookie --format json 'value'",
+		Example: "  ncmctl login cookie -f cookie.txt\n  ncmctl login cookie --format netscaple -f cookie.json\n  ncmctl login cookie 'value'\n  ncmctl login cookie --format json 'value'\n  ncmctl login import -f cookies.json --profile work",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return c.execute(cmd.Context(), args)
 		},
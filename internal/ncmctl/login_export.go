@@ -0,0 +1,167 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookiecloud"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"codeberg.org/sbinet/mozcookie"
+	"github.com/spf13/cobra"
+)
+
+type loginExportCmd struct {
+	root *Login
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	file   string
+	format string
+}
+
+func export(root *Login, l *log.Logger) *cobra.Command {
+	c := &loginExportCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "export",
+		Short: "export login cookie, can be imported by 'ncmctl login import'",
+		Example: "  ncmctl login export\n" +
+			"  ncmctl login export -f cookies.json\n" +
+			"  ncmctl login export --format netscape -f cookies.txt\n" +
+			"  ncmctl login export --profile work -f work.json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *loginExportCmd) addFlags() {
+	c.cmd.Flags().StringVarP(&c.file, "file", "f", "", "export cookie file path, output to stdout when empty")
+	c.cmd.Flags().StringVar(&c.format, "format", "json", "export cookie file format. eg: json、netscape、header")
+}
+
+func (c *loginExportCmd) execute(ctx context.Context) error {
+	if c.format != "json" &&
+		c.format != "netscape" &&
+		c.format != "header" {
+		return fmt.Errorf("format is not support: %v", c.format)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+
+	var cookies = cli.ExportCookies()
+	if len(cookies) <= 0 {
+		return fmt.Errorf("cookie is empty, please login first")
+	}
+
+	var buf bytes.Buffer
+	switch c.format {
+	case "json":
+		var list = make([]cookiecloud.CookieData, 0, len(cookies))
+		for _, v := range cookies {
+			var data = cookiecloud.CookieData{
+				Domain:   v.Domain,
+				HostOnly: !strings.HasPrefix(v.Domain, "."),
+				HttpOnly: v.HttpOnly,
+				Name:     v.Name,
+				Path:     v.Path,
+				SameSite: sameSiteString(v.SameSite),
+				Secure:   v.Secure,
+				Session:  v.Expires.IsZero(),
+				Value:    v.Value,
+			}
+			if !v.Expires.IsZero() {
+				data.ExpirationDate = float64(v.Expires.UnixNano()) / 1e9
+			}
+			list = append(list, data)
+		}
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(list); err != nil {
+			return fmt.Errorf("Encode: %w", err)
+		}
+	case "netscape":
+		if err := mozcookie.Encode(&buf, cookies); err != nil {
+			return fmt.Errorf("mozcookie.Encode: %w", err)
+		}
+	case "header":
+		var list = make([]string, 0, len(cookies))
+		for _, v := range cookies {
+			list = append(list, (&http.Cookie{Name: v.Name, Value: v.Value}).String())
+		}
+		buf.WriteString(strings.Join(list, "; "))
+		buf.WriteString("\n")
+	}
+
+	if c.file == "" {
+		c.cmd.Print(buf.String())
+		return nil
+	}
+	file, err := utils.ExpandTilde(c.file)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	// cookie中包含登录凭证,因此只允许当前用户读写
+	if err := os.WriteFile(file, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	c.cmd.Printf("export %d cookies to %s\n", len(cookies), file)
+	return nil
+}
+
+// sameSiteString 与 sameSite 相反,将http.SameSite转换为浏览器插件导出的格式
+func sameSiteString(val http.SameSite) string {
+	switch val {
+	case http.SameSiteLaxMode:
+		return "lax"
+	case http.SameSiteStrictMode:
+		return "strict"
+	case http.SameSiteNoneMode:
+		return "none"
+	default:
+		return "unspecified"
+	}
+}
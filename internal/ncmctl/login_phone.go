@@ -27,6 +27,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
@@ -54,7 +55,7 @@ func phone(root *Login, l *log.Logger) *cobra.Command {
 	c.cmd = &cobra.Command{
 		Use:     "phone",
 		Short:   "use phone login",
-		Example: "  ncmctl login phone 188xxxx8888\n  ncmctl login phone 188xxxx8888 -p password",
+		Example: "  ncmctl login phone 188xxxx8888\n  ncmctl login phone 188xxxx8888 -p password\n  ncmctl login phone 188xxxx8888 --countrycode 852",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return c.execute(cmd.Context(), args)
 		},
@@ -94,32 +95,92 @@ func (c *loginPhoneCmd) execute(ctx context.Context, args []string) error {
 	// 如果密码为空则走短信验证登录逻辑
 	var captcha string
 	if c.password == "" {
+		if captcha, err = c.captcha(ctx, request, cellphone); err != nil {
+			return err
+		}
+	}
+
+	login, err := request.LoginCellphone(ctx, &weapi.LoginCellphoneReq{
+		Phone:       cellphone,
+		Countrycode: c.countrycode,
+		Remember:    true,
+		Password:    c.password,
+		Captcha:     captcha,
+	})
+	if err != nil {
+		return fmt.Errorf("login failed: %w", phoneLoginError(err))
+	}
+	log.Debug("LoginCellphone resp: %+v", login)
+
+	// 查询登录信息是否成功
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %s", err)
+	}
+	c.cmd.Printf("login success: %+v\n", user)
+	return nil
+}
+
+// captchaInterval 短信验证码重新发送间隔
+const captchaInterval = time.Second * 60
+
+// captcha 发送短信验证码并等待用户在终端输入,倒计时结束后输入r可重新发送
+func (c *loginPhoneCmd) captcha(ctx context.Context, request *weapi.Api, cellphone string) (string, error) {
+	var (
+		fail   int
+		sentAt time.Time
+	)
+	send := func() error {
 		sms, err := request.SendSMS(ctx, &weapi.SendSMSReq{
 			Cellphone: cellphone,
 			CtCode:    c.countrycode,
 		})
 		if err != nil {
-			return fmt.Errorf("SendSMS: %s", err)
+			return fmt.Errorf("send sms failed: %w", phoneLoginError(err))
 		}
-		if sms.Code == 200 && sms.Data {
-			c.cmd.Println("send sms success")
-		} else {
-			return fmt.Errorf("send sms failed, code: %d, msg: %s\n", sms.Code, sms.Msg)
+		if !sms.Data {
+			return fmt.Errorf("send sms failed: %+v", sms)
 		}
+		sentAt = time.Now()
+		c.cmd.Printf("send sms success, you can resend after %s\n", captchaInterval)
+		return nil
+	}
+	if err := send(); err != nil {
+		return "", err
+	}
 
-		// 等待用户在终端输入验证码
-		var fail int
-	retry:
+	// 等待用户在终端输入验证码
+	for {
 		if fail > 5 {
-			return fmt.Errorf("too many failed attempts")
+			return "", fmt.Errorf("too many failed attempts")
 		}
-		c.cmd.Printf("please input sms captcha: ")
+		if remain := captchaInterval - time.Since(sentAt); remain > 0 {
+			c.cmd.Printf("please input sms captcha (resend after %ds): ", int(remain.Seconds()))
+		} else {
+			c.cmd.Printf("please input sms captcha (input r to resend): ")
+		}
+
+		var captcha string
 		if _, err := fmt.Scanln(&captcha); err != nil {
-			return fmt.Errorf("input sms captcha: %s", err)
+			return "", fmt.Errorf("input sms captcha: %s", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
 		}
-		if captcha == "" || len(captcha) < 4 {
+		captcha = strings.TrimSpace(captcha)
+		if strings.EqualFold(captcha, "r") {
+			if remain := captchaInterval - time.Since(sentAt); remain > 0 {
+				c.cmd.Printf("please wait %ds before resend\n", int(remain.Seconds()))
+				continue
+			}
+			if err := send(); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if len(captcha) < 4 {
 			c.cmd.Println("invalid captcha, please retry")
-			goto retry
+			continue
 		}
 
 		verify, err := request.SMSVerify(ctx, &weapi.SMSVerifyReq{
@@ -127,37 +188,46 @@ func (c *loginPhoneCmd) execute(ctx context.Context, args []string) error {
 			Captcha:   captcha,
 			CtCode:    c.countrycode,
 		})
-		if err != nil {
-			return fmt.Errorf("SMSVerify: %s", err)
+		if _, ok := api.AsError(err); err != nil && !ok {
+			return "", fmt.Errorf("SMSVerify: %w", err)
 		}
-		if verify.Code == 200 && verify.Data {
+		if err == nil && verify.Data {
 			c.cmd.Println("verify sms success")
+			return captcha, nil
+		}
+		fail++
+		if err != nil {
+			c.cmd.Printf("verify sms failed: %s\n", phoneLoginError(err))
 		} else {
-			fail++
-			c.cmd.Printf("verify sms failed, code: %d, msg: %s\n", verify.Code, verify.Msg)
-			goto retry
+			c.cmd.Println("verify sms failed, please retry")
 		}
 	}
+}
 
-	login, err := request.LoginCellphone(ctx, &weapi.LoginCellphoneReq{
-		Phone:       cellphone,
-		Countrycode: c.countrycode,
-		Remember:    true,
-		Password:    c.password,
-		Captcha:     captcha,
-	})
-	if err != nil {
-		return fmt.Errorf("LoginCellphone: %s", err)
-	}
-	if login.Code != 200 {
-		return fmt.Errorf("login failed, code: %d, msg: %s, message: %s", login.Code, login.Msg, login.Message)
+// phoneLoginError 将手机号登录相关接口的错误码转换为可读的错误信息
+func phoneLoginError(err error) error {
+	e, ok := api.AsError(err)
+	if !ok {
+		return err
 	}
-
-	// 查询登录信息是否成功
-	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
-	if err != nil {
-		return fmt.Errorf("GetUserInfo: %s", err)
+	var reason string
+	switch e.Code {
+	case 400:
+		reason = "invalid parameter"
+	case 405:
+		reason = "too many attempts, captcha sent too frequently or exceeded the daily limit, please try again later"
+	case 501:
+		reason = "account does not exist"
+	case 502:
+		reason = "wrong password"
+	case 503:
+		reason = "wrong captcha"
+	case 509:
+		reason = "too many attempts, wrong password too many times, please try again later"
+	case 8821:
+		reason = "need behavior verification, please use qrcode login instead"
+	default:
+		reason = "unknown error"
 	}
-	c.cmd.Printf("login success: %+v\n", user)
-	return nil
+	return fmt.Errorf("%s: %w", reason, e)
 }
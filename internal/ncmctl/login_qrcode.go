@@ -46,6 +46,8 @@ type loginQrcodeCmd struct {
 	timeout time.Duration // 登录超时时间
 	dir     string        // 二维码文件路径
 	level   int           // 二维码恢复能力等级
+	noFile  bool          // 不生成二维码图片文件,仅在终端中显示
+	invert  bool          // 终端二维码反色显示
 }
 
 func qrcode(root *Login, l *log.Logger) *cobra.Command {
@@ -56,7 +58,7 @@ func qrcode(root *Login, l *log.Logger) *cobra.Command {
 	c.cmd = &cobra.Command{
 		Use:     "qrcode",
 		Short:   "use qrcode login",
-		Example: "  ncmctl login qrcode",
+		Example: "  ncmctl login qrcode\n  ncmctl login qrcode --no-file\n  ncmctl login qrcode --no-file --invert",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return c.execute(cmd.Context(), args)
 		},
@@ -69,6 +71,8 @@ func (c *loginQrcodeCmd) addFlags() {
 	c.cmd.Flags().DurationVarP(&c.timeout, "timeout", "t", time.Minute*5, "login timeout, eg: 1s、1m")
 	c.cmd.Flags().StringVarP(&c.dir, "dir", "d", "", "qrcode file output path. default ./")
 	c.cmd.Flags().IntVarP(&c.level, "level", "l", 1, "qrcode recovery capacity,0->7% 1->15%(default) 2->25% 3->30%")
+	c.cmd.Flags().BoolVar(&c.noFile, "no-file", false, "only render qrcode in terminal, do not write qrcode image file")
+	c.cmd.Flags().BoolVar(&c.invert, "invert", false, "invert terminal qrcode color, use it when the terminal background is light")
 }
 
 func (c *loginQrcodeCmd) execute(ctx context.Context, args []string) error {
@@ -96,64 +100,44 @@ func (c *loginQrcodeCmd) execute(ctx context.Context, args []string) error {
 	}
 
 	// 2. 生成二维码
-	qr, err := request.QrcodeGenerate(ctx, &weapi.QrcodeGenerateReq{CodeKey: key.UniKey, Level: qrcode2.RecoveryLevel(c.level)})
+	qr, err := request.QrcodeGenerate(ctx, &weapi.QrcodeGenerateReq{CodeKey: key.UniKey, Level: qrcode2.RecoveryLevel(c.level), Invert: c.invert})
 	if err != nil {
 		return fmt.Errorf("QrcodeGenerate: %s", err)
 	}
 
 	// 3. 手机扫码
-	if c.dir == "" {
-		dir, err := os.Getwd()
-		if err != nil {
+	var file string
+	if !c.noFile {
+		if c.dir == "" {
+			dir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			c.dir = dir
+		}
+		if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+			return fmt.Errorf("MkdirAll: %w", err)
+		}
+		file = filepath.Join(c.dir, "qrcode.png")
+		if err := os.WriteFile(file, qr.Qrcode, os.ModePerm); err != nil {
 			return err
 		}
-		c.dir = dir
-	}
-	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
-		return fmt.Errorf("MkdirAll: %w", err)
-	}
-	var file = filepath.Join(c.dir, "qrcode.png")
-	if err := os.WriteFile(file, qr.Qrcode, os.ModePerm); err != nil {
-		return err
+		defer func() {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				log.Info("remove qrcode file: %s", err)
+			}
+		}()
 	}
 	c.cmd.Println(">>>>> please scan qrcode in your phone <<<<<")
 	c.cmd.Printf("qrcode content: https://music.163.com/login?codekey=%s\n", key.UniKey)
-	c.cmd.Printf("qrcode file: %s\n", file)
+	if file != "" {
+		c.cmd.Printf("qrcode file: %s\n", file)
+	}
 	c.cmd.Printf("qrcode: \n%s\n", qr.QrcodePrint)
 
 	// 4. 轮训获取扫码状态
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		time.Sleep(time.Second * 3)
-		resp, err := request.QrcodeCheck(ctx, &weapi.QrcodeCheckReq{Type: 1, Key: key.UniKey})
-		if err != nil {
-			return fmt.Errorf("QrcodeCheck: %w", err)
-		}
-		log.Debug("QrcodeCheck resp: %v\n", resp)
-		switch resp.Code {
-		case 800: // 二维码不存在、已过期、用户取消授权
-			return fmt.Errorf("current QrcodeCheck resp: %v\n", resp)
-		case 801: // 等待扫码
-			continue
-		case 802: // 正在扫码授权中
-			continue
-		case 803: // 授权登录成功
-			goto ok
-		default:
-			return fmt.Errorf("登录失败 QrcodeCheck resp: %v\n", resp)
-		}
-	}
-ok:
-
-	if err := os.Remove(file); err != nil {
-		if !os.IsNotExist(err) {
-			log.Info("remove qrcode file: %s", err)
-		}
+	if err := c.wait(ctx, request, key.UniKey); err != nil {
+		return err
 	}
 
 	// 5. 查询登录信息是否成功
@@ -162,5 +146,53 @@ ok:
 		return fmt.Errorf("GetUserInfo: %s", err)
 	}
 	c.cmd.Printf("login success: %+v\n", user)
+	if path := c.root.root.Cfg.Network.Cookie.Filepath; path != "" {
+		c.cmd.Printf("cookie saved: %s\n", path)
+	}
 	return nil
 }
+
+// wait 轮询扫码状态直到授权成功,期间在终端中显示等待动画
+func (c *loginQrcodeCmd) wait(ctx context.Context, request *weapi.Api, key string) error {
+	var (
+		frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+		status = "waiting for scan"
+		tick   = time.NewTicker(time.Millisecond * 100)
+		poll   = time.NewTicker(time.Second * 2)
+		out    = c.cmd.ErrOrStderr()
+	)
+	defer tick.Stop()
+	defer poll.Stop()
+	// 清除等待动画所在行
+	defer fmt.Fprint(out, "\r\033[K")
+
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+			fmt.Fprintf(out, "\r\033[K%s %s", frames[i%len(frames)], status)
+		case <-poll.C:
+			resp, err := request.QrcodeCheck(ctx, &weapi.QrcodeCheckReq{Type: 1, Key: key})
+			if err != nil {
+				return fmt.Errorf("QrcodeCheck: %w", err)
+			}
+			log.Debug("QrcodeCheck resp: %v\n", resp)
+			switch resp.Code {
+			case 800: // 二维码不存在、已过期、用户取消授权
+				return fmt.Errorf("qrcode expired or canceled: %v", resp)
+			case 801: // 等待扫码
+				status = "waiting for scan"
+			case 802: // 正在扫码授权中
+				status = "scanned, please confirm login in your phone"
+				if resp.Nickname != "" {
+					status = fmt.Sprintf("%s scanned, please confirm login in your phone", resp.Nickname)
+				}
+			case 803: // 授权登录成功
+				return nil
+			default:
+				return fmt.Errorf("登录失败 QrcodeCheck resp: %v\n", resp)
+			}
+		}
+	}
+}
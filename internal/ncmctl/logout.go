@@ -27,6 +27,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/weapi"
@@ -49,9 +50,10 @@ func NewLogout(root *Root, l *log.Logger) *Logout {
 		root: root,
 		l:    l,
 		cmd: &cobra.Command{
-			Use:     "logout",
-			Short:   "Logout netease cloud music",
-			Example: "  ncmctl logout",
+			Use:         "logout",
+			Annotations: map[string]string{skipKeepAlive: ""},
+			Short:       "Logout netease cloud music",
+			Example:     "  ncmctl logout",
 		},
 	}
 	c.addFlags()
@@ -80,16 +82,13 @@ func (c *Logout) execute(ctx context.Context, args []string) error {
 	defer cli.Close(ctx)
 
 	request := weapi.New(cli)
-	resp, err := request.Layout(ctx, &weapi.LayoutReq{})
+	_, err = request.Layout(ctx, &weapi.LayoutReq{})
 	if err != nil {
 		return fmt.Errorf("layout: %w", err)
 	}
-	if resp.Code != 200 {
-		return fmt.Errorf("layout: %+v", resp)
-	}
 
 	// 只清理默认目录下得文件
-	if err := os.Remove(c.root.Opts.Home + "/.ncmctl/cookie.json"); err != nil {
+	if err := os.Remove(filepath.Join(c.root.dirs.State, "cookie.json")); err != nil {
 		log.Debug("remove cookie.json: %w", err)
 	}
 	c.cmd.Println("Logout success")
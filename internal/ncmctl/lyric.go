@@ -0,0 +1,279 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+type LyricOpts struct {
+	Library  string        // 为本地曲库批量生成歌词的目录
+	Mapping  string        // scan生成的映射文件路径
+	MinScore float64       // 只为匹配置信度不低于该值的文件生成歌词
+	Interval time.Duration // 每次请求歌词的间隔
+	Force    bool          // 覆盖已存在的lrc文件
+}
+
+type Lyric struct {
+	root *Root
+	cmd  *cobra.Command
+	opts LyricOpts
+	l    *log.Logger
+}
+
+// lyricResult 曲库中单个文件的歌词生成结果
+type lyricResult struct {
+	File   string  `json:"file"`
+	Id     int64   `json:"id"`
+	Score  float64 `json:"score"`  // scan记录的匹配置信度
+	Status string  `json:"status"` // written: 已写入 exists: 已有lrc文件 embedded: 文件已内嵌歌词 nolyric: 无歌词 lowscore: 置信度过低 failed: 失败
+	Reason string  `json:"reason,omitempty"`
+}
+
+func NewLyric(root *Root, l *log.Logger) *Lyric {
+	c := &Lyric{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "lyric [song]",
+			Short: "Print song lyric or write .lrc files for a local music library",
+			Long: "Print the lyric of a song, or with --for-library write a .lrc file next to every local music file\n" +
+				"matched by \"ncmctl scan\". Files that already have a .lrc file or embedded lyric are skipped,\n" +
+				"the match confidence recorded by scan is reported for every written file.",
+			Example: "  ncmctl lyric 1989404376\n" +
+				"  ncmctl lyric --for-library ./music\n" +
+				"  ncmctl lyric --for-library ./music --min-score 0.8 --output-format json",
+			Args:              cobra.MaximumNArgs(1),
+			ValidArgsFunction: completeArgs(root.completeSongs, cobra.NoFileCompletions),
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args)
+	}
+	return c
+}
+
+func (c *Lyric) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Library, "for-library", "", "write .lrc files for the local music library scanned by \"ncmctl scan\"")
+	c.cmd.Flags().StringVar(&c.opts.Mapping, "mapping", "", "mapping file path written by scan, default <dir>/"+scanMappingFile)
+	c.cmd.Flags().Float64Var(&c.opts.MinScore, "min-score", 0, "only write lyrics for files matched with at least this confidence")
+	c.cmd.Flags().DurationVar(&c.opts.Interval, "interval", 500*time.Millisecond, "wait time between two lyric requests")
+	c.cmd.Flags().BoolVar(&c.opts.Force, "force", false, "overwrite existing .lrc files")
+}
+
+func (c *Lyric) validate(args []string) error {
+	if (c.opts.Library == "") == (len(args) == 0) {
+		return fmt.Errorf("specify either a song or --for-library")
+	}
+	if c.opts.MinScore < 0 || c.opts.MinScore > 1 {
+		return fmt.Errorf("min-score must be between 0 and 1")
+	}
+	if c.opts.Interval < 0 {
+		return fmt.Errorf("interval must be >= 0")
+	}
+	return nil
+}
+
+func (c *Lyric) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Lyric) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Lyric) execute(ctx context.Context, args []string) error {
+	if err := c.validate(args); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if c.opts.Library != "" {
+		return c.library(ctx, request)
+	}
+
+	ids, err := parseSongIds(args)
+	if err != nil {
+		return err
+	}
+	c.root.cacheSongs(completionSongIds(ids)...)
+	lyric, err := songLyric(ctx, request, ids[0])
+	if err != nil {
+		return err
+	}
+	if lyric == "" {
+		return fmt.Errorf("song %d has no lyric", ids[0])
+	}
+	c.cmd.Print(lyric)
+	if !strings.HasSuffix(lyric, "\n") {
+		c.cmd.Println()
+	}
+	return nil
+}
+
+// library 为scan映射文件中已匹配的文件生成同名lrc文件
+func (c *Lyric) library(ctx context.Context, request *weapi.Api) error {
+	dir, err := utils.ExpandTilde(c.opts.Library)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if !utils.DirExists(dir) {
+		return fmt.Errorf("%s not found", dir)
+	}
+	var path = c.opts.Mapping
+	if path == "" {
+		path = filepath.Join(dir, scanMappingFile)
+	}
+	if !utils.FileExists(path) {
+		return fmt.Errorf("mapping file %s not found, please run \"ncmctl scan %s\" first", path, c.opts.Library)
+	}
+	mapping, err := loadScanMapping(path)
+	if err != nil {
+		return err
+	}
+
+	var (
+		results []lyricResult
+		counts  = make(map[string]int)
+		last    time.Time
+	)
+	for _, e := range mapping.Files {
+		if e.Id <= 0 {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		var (
+			file   = filepath.Join(dir, filepath.FromSlash(e.File))
+			lrc    = strings.TrimSuffix(file, filepath.Ext(file)) + ".lrc"
+			result = lyricResult{File: e.File, Id: e.Id, Score: e.Score}
+		)
+		if !utils.FileExists(file) {
+			continue
+		}
+		switch {
+		case e.Score < c.opts.MinScore:
+			result.Status = "lowscore"
+		case !c.opts.Force && utils.FileExists(lrc):
+			result.Status = "exists"
+		case embeddedLyric(file):
+			result.Status = "embedded"
+		}
+		if result.Status != "" {
+			counts[result.Status]++
+			if result.Status == "lowscore" {
+				results = append(results, result)
+			}
+			continue
+		}
+
+		// 请求之间保持间隔,避免曲库较大时触发风控
+		if wait := c.opts.Interval - time.Since(last); !last.IsZero() && wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				break
+			}
+		}
+		last = time.Now()
+
+		lyric, err := songLyric(ctx, request, e.Id)
+		switch {
+		case err != nil:
+			result.Status, result.Reason = "failed", err.Error()
+			log.Warn("[lyric] %s: %s", e.File, err)
+		case lyric == "":
+			result.Status = "nolyric"
+		default:
+			if err := os.WriteFile(lrc, []byte(lyric), 0644); err != nil {
+				result.Status, result.Reason = "failed", err.Error()
+				log.Warn("[lyric] write %s: %s", lrc, err)
+				break
+			}
+			result.Status = "written"
+		}
+		counts[result.Status]++
+		results = append(results, result)
+	}
+
+	var v = view{Data: results, Header: []string{"FILE", "ID", "SCORE", "STATUS"}}
+	for _, r := range results {
+		v.Rows = append(v.Rows, []string{r.File, strconv.FormatInt(r.Id, 10), fmt.Sprintf("%.2f", r.Score), r.Status})
+	}
+	if err := render(c.cmd.OutOrStdout(), c.root.outputFormat(outputTable), v); err != nil {
+		return err
+	}
+	c.cmd.PrintErrf("written %d, no lyric %d, already exists %d, embedded %d, low score %d, failed %d\n",
+		counts["written"], counts["nolyric"], counts["exists"], counts["embedded"], counts["lowscore"], counts["failed"])
+	if failed := counts["failed"]; failed > 0 {
+		if failed == len(results) {
+			return fmt.Errorf("all %d songs failed", failed)
+		}
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d songs failed", failed)}
+	}
+	return nil
+}
+
+// songLyric 获取歌曲lrc歌词,纯音乐或暂无歌词时返回空字符串
+func songLyric(ctx context.Context, request *weapi.Api, id int64) (string, error) {
+	resp, err := request.Lyric(ctx, &weapi.LyricReq{Id: id})
+	if err != nil {
+		return "", fmt.Errorf("Lyric(%d): %w", id, err)
+	}
+	return resp.Lrc.Lyric, nil
+}
+
+// embeddedLyric 判断mp3/flac文件是否已内嵌歌词,读取失败或其他格式视为没有
+func embeddedLyric(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3", ".flac":
+	default:
+		return false
+	}
+	file, err := readLibraryFile(path)
+	if err != nil {
+		log.Debug("[lyric] read %s: %s", path, err)
+		return false
+	}
+	return file.HasLyric
+}
@@ -0,0 +1,163 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/metrics"
+)
+
+// monitor 守护进程及api服务运行时的监控指标,通过 /metrics 以 Prometheus 格式输出。
+// 方法均允许在nil上调用,未开启监控时不统计。
+type monitor struct {
+	reg           *metrics.Registry
+	apiCalls      *metrics.Vec
+	apiSeconds    *metrics.Vec
+	waits         *metrics.Vec
+	waitSeconds   *metrics.Vec
+	downloads     *metrics.Vec
+	downloadBytes *metrics.Vec
+	failures      *metrics.Vec
+	taskRuns      *metrics.Vec
+	taskSeconds   *metrics.Vec
+	taskLast      *metrics.Vec
+}
+
+func newMonitor() *monitor {
+	var reg = metrics.NewRegistry()
+	return &monitor{
+		reg:           reg,
+		apiCalls:      reg.Counter("ncmctl_api_calls_total", "Number of NetEase api calls by endpoint and response code, code 0 means network error.", "endpoint", "code"),
+		apiSeconds:    reg.Counter("ncmctl_api_call_seconds_total", "Total time spent on NetEase api calls by endpoint.", "endpoint"),
+		waits:         reg.Counter("ncmctl_ratelimit_waits_total", "Number of api calls delayed by the rate limiter.", "endpoint"),
+		waitSeconds:   reg.Counter("ncmctl_ratelimit_wait_seconds_total", "Total time api calls waited for the rate limiter.", "endpoint"),
+		downloads:     reg.Counter("ncmctl_downloads_total", "Number of downloaded files."),
+		downloadBytes: reg.Counter("ncmctl_download_bytes_total", "Total size of downloaded files in bytes."),
+		failures:      reg.Counter("ncmctl_failures_total", "Number of failures by kind: download, task.", "kind"),
+		taskRuns:      reg.Counter("ncmctl_task_runs_total", "Number of daemon job runs by result: success, failed, timeout.", "job", "command", "result"),
+		taskSeconds:   reg.Gauge("ncmctl_task_last_duration_seconds", "Duration of the last run of daemon job.", "job"),
+		taskLast:      reg.Gauge("ncmctl_task_last_run_timestamp_seconds", "Unix time the daemon job last finished.", "job"),
+	}
+}
+
+// ObserveCall 实现 api.Observer 统计接口调用
+func (m *monitor) ObserveCall(url string, code int64, cost time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	var endpoint = metricEndpoint(url)
+	m.apiCalls.Inc(endpoint, strconv.FormatInt(code, 10))
+	m.apiSeconds.Add(cost.Seconds(), endpoint)
+}
+
+// ObserveWait 实现 api.Observer 统计限流等待
+func (m *monitor) ObserveWait(url string, wait time.Duration) {
+	if m == nil {
+		return
+	}
+	var endpoint = metricEndpoint(url)
+	m.waits.Inc(endpoint)
+	m.waitSeconds.Add(wait.Seconds(), endpoint)
+}
+
+// downloaded 记录下载完成的文件
+func (m *monitor) downloaded(size int64) {
+	if m == nil {
+		return
+	}
+	m.downloads.Inc()
+	m.downloadBytes.Add(float64(max(size, 0)))
+}
+
+// failed 记录失败次数,kind为失败类型
+func (m *monitor) failed(kind string) {
+	if m == nil {
+		return
+	}
+	m.failures.Inc(kind)
+}
+
+// taskDone 记录定时任务执行结果
+func (m *monitor) taskDone(job, command, result string, cost time.Duration) {
+	if m == nil {
+		return
+	}
+	m.taskRuns.Inc(job, command, result)
+	m.taskSeconds.Set(cost.Seconds(), job)
+	m.taskLast.Set(float64(time.Now().Unix()), job)
+	// daemon 退出时中止的任务重启后会继续执行,不算作失败
+	if result != "success" && result != "aborted" {
+		m.failures.Inc("task")
+	}
+}
+
+// serve 在ln上提供 /metrics 服务,返回的函数用于关闭服务
+func (m *monitor) serve(ln net.Listener) func() {
+	var mux = http.NewServeMux()
+	mux.Handle("GET /metrics", m.reg)
+	var srv = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("[metrics] serve: %s", err)
+		}
+	}()
+	log.Info("[metrics] listening on %s", ln.Addr())
+	return func() { _ = srv.Close() }
+}
+
+// metricIdRegexp 路径中包含资源id的部分,例如 /weapi/v1/resource/comments/R_SO_4_123
+var metricIdRegexp = regexp.MustCompile(`\d{4,}`)
+
+// metricEndpoint 获取接口路径作为指标标签,路径中的资源id替换为:id避免标签数量过多
+func metricEndpoint(rawUrl string) string {
+	u, err := neturl.Parse(rawUrl)
+	if err != nil {
+		return "unknown"
+	}
+	var segments = strings.Split(u.Path, "/")
+	for i, s := range segments {
+		if metricIdRegexp.MatchString(s) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// enableMetrics 开启监控指标统计,之后创建的api客户端均会上报接口调用指标
+func (c *Root) enableMetrics() *monitor {
+	if c.monitor == nil {
+		c.monitor = newMonitor()
+		c.Cfg.Network.Observer = c.monitor
+	}
+	return c.monitor
+}
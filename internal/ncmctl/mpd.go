@@ -0,0 +1,583 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/resolver"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/nohup"
+	"github.com/chaunsin/netease-cloud-music/pkg/scrobbler"
+
+	"github.com/spf13/cobra"
+)
+
+// mpdVersion 兼容的MPD协议版本
+const mpdVersion = "0.23.5"
+
+// mpdSongScheme 队列及歌单中歌曲的uri前缀
+const mpdSongScheme = "ncm://song/"
+
+// MPD 错误码 see: https://mpd.readthedocs.io/en/latest/protocol.html#failure-responses
+const (
+	mpdErrNotList    = 1
+	mpdErrArg        = 2
+	mpdErrPassword   = 3
+	mpdErrPermission = 4
+	mpdErrUnknown    = 5
+	mpdErrNoExist    = 50
+	mpdErrSystem     = 52
+)
+
+// mpdSubsystems idle命令支持的子系统
+var mpdSubsystems = []string{"player", "playlist", "options", "mixer", "output", "stored_playlist", "database", "update"}
+
+type MpdOpts struct {
+	Addr     string // 监听地址
+	Password string // 客户端连接密码,为空时不校验
+	Player   string // 播放器命令及参数
+	Level    string // 播放音质
+	Scrobble bool   // 播放结束后上报听歌记录
+}
+
+type Mpd struct {
+	root *Root
+	cmd  *cobra.Command
+	opts MpdOpts
+	l    *log.Logger
+
+	ctx       context.Context
+	request   *weapi.Api
+	resolver  *resolver.Resolver
+	forwarder *scrobbler.Forwarder
+	player    []string
+	uid       int64
+	started   time.Time // 服务启动时间
+
+	mu        sync.Mutex
+	conns     map[*mpdConn]struct{}
+	queue     mpdQueue
+	playlists map[string]int64 // 歌单名称与歌单id映射
+}
+
+// mpdError 命令执行失败时返回的错误
+type mpdError struct {
+	Code    int
+	Message string
+}
+
+func (e *mpdError) Error() string {
+	return e.Message
+}
+
+func mpdErrorf(code int, format string, args ...any) error {
+	return &mpdError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// mpdConn 客户端连接
+type mpdConn struct {
+	net.Conn
+	w      *bufio.Writer
+	authed bool
+	events map[string]bool // 上次idle之后发生变化的子系统
+	wake   chan struct{}
+}
+
+func (c *mpdConn) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(c.w, format, args...)
+}
+
+func NewMpd(root *Root, l *log.Logger) *Mpd {
+	c := &Mpd{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "mpd",
+			Short: "[need login] Run a MPD protocol server so that MPD clients like ncmpcpp/MALP can play the NetEase playlists",
+			Long: "Run a MPD protocol server so that MPD clients like ncmpcpp/MALP can play the NetEase playlists.\n" +
+				"Playlists of the account are exposed as stored playlists, songs are played by an external player(default mpv) on this machine.",
+			Example: `  ncmctl mpd
+  ncmctl mpd --addr :6600 --password secret --level lossless`,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *Mpd) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Addr, "addr", "127.0.0.1:6600", "listen address")
+	c.cmd.Flags().StringVar(&c.opts.Password, "password", os.Getenv("NCMCTL_MPD_PASSWORD"), "password required by clients, empty to disable. also can be set by NCMCTL_MPD_PASSWORD env")
+	c.cmd.Flags().StringVar(&c.opts.Player, "player", "mpv --no-video --really-quiet", "player command, the song url is appended as the last argument")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelExhigh), "song quality level. support: standard,higher,exhigh,lossless,hires")
+	c.cmd.Flags().BoolVar(&c.opts.Scrobble, "scrobble", true, "report the play record after each song")
+}
+
+func (c *Mpd) validate() error {
+	if len(strings.Fields(c.opts.Player)) <= 0 {
+		return fmt.Errorf("player is empty")
+	}
+	if _, ok := types.LevelString[types.Level(c.opts.Level)]; !ok {
+		return fmt.Errorf("[%s] quality is not support", c.opts.Level)
+	}
+	return nil
+}
+
+func (c *Mpd) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Mpd) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Mpd) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	c.player = strings.Fields(c.opts.Player)
+	if _, err := exec.LookPath(c.player[0]); err != nil {
+		return fmt.Errorf("player %s not found: %w", c.player[0], err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	c.request = weapi.New(cli)
+	c.resolver = resolver.New(c.request, nil)
+	c.forwarder = newForwarder(c.root)
+
+	user, err := c.request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	c.uid = user.Account.Id
+
+	ln, err := net.Listen("tcp", c.opts.Addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c.ctx = ctx
+	c.started = time.Now()
+	c.conns = make(map[*mpdConn]struct{})
+	c.queue.state = "stop"
+	go c.serve(ln)
+	c.cmd.Printf("mpd server listening on %s\n", ln.Addr())
+	log.Info("[mpd] listening on %s", ln.Addr())
+
+	nohup.Daemon(nohup.CloseHook(func(ctx context.Context) error {
+		_ = ln.Close()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.stopLocked()
+		for conn := range c.conns {
+			_ = conn.Close()
+		}
+		return nil
+	}))
+	return nil
+}
+
+func (c *Mpd) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error("[mpd] accept: %s", err)
+			}
+			return
+		}
+		go c.handle(conn)
+	}
+}
+
+// handle 处理客户端连接,按行读取命令并返回结果,支持command_list及idle
+func (c *Mpd) handle(nc net.Conn) {
+	var conn = &mpdConn{
+		Conn:   nc,
+		w:      bufio.NewWriter(nc),
+		authed: c.opts.Password == "",
+		events: make(map[string]bool),
+		wake:   make(chan struct{}, 1),
+	}
+	c.mu.Lock()
+	c.conns[conn] = struct{}{}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.conns, conn)
+		c.mu.Unlock()
+		_ = conn.Close()
+	}()
+	log.Debug("[mpd] %s connected", nc.RemoteAddr())
+
+	var lines = make(chan string)
+	go func() {
+		defer close(lines)
+		var scanner = bufio.NewScanner(nc)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	conn.printf("OK MPD %s\n", mpdVersion)
+	_ = conn.w.Flush()
+	for line := range lines {
+		args, err := mpdArgs(line)
+		switch {
+		case err != nil:
+			conn.printf("ACK [%d@0] {} %s\n", mpdErrArg, err)
+		case len(args) <= 0:
+			conn.printf("ACK [%d@0] {} No command given\n", mpdErrUnknown)
+		case args[0] == "close":
+			return
+		case args[0] == "noidle":
+			// 未处于idle状态时忽略
+			continue
+		case args[0] == "idle":
+			if !c.idle(conn, args[1:], lines) {
+				return
+			}
+		case args[0] == "command_list_begin" || args[0] == "command_list_ok_begin":
+			var list [][]string
+			for line := range lines {
+				if line == "command_list_end" {
+					break
+				}
+				args, err := mpdArgs(line)
+				if err != nil || len(args) <= 0 {
+					args = []string{line}
+				}
+				list = append(list, args)
+			}
+			c.commandList(conn, list, args[0] == "command_list_ok_begin")
+		default:
+			if c.exec(conn, args, 0) {
+				conn.printf("OK\n")
+			}
+		}
+		if err := conn.w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// commandList 依次执行命令列表,遇到错误时停止执行
+func (c *Mpd) commandList(conn *mpdConn, list [][]string, ok bool) {
+	for i, args := range list {
+		if !c.exec(conn, args, i) {
+			return
+		}
+		if ok {
+			conn.printf("list_OK\n")
+		}
+	}
+	conn.printf("OK\n")
+}
+
+// exec 执行单个命令,失败时输出ACK并返回false
+func (c *Mpd) exec(conn *mpdConn, args []string, index int) bool {
+	log.Debug("[mpd] %s %q", conn.RemoteAddr(), args)
+	fn, ok := mpdCommands[args[0]]
+	var err error
+	switch {
+	case !ok:
+		err = mpdErrorf(mpdErrUnknown, "unknown command %q", args[0])
+	case !conn.authed && !mpdPublicCommands[args[0]]:
+		err = mpdErrorf(mpdErrPermission, "you don't have permission for %q", args[0])
+	default:
+		err = fn(c, conn, args[1:])
+	}
+	if err != nil {
+		var e *mpdError
+		if !errors.As(err, &e) {
+			e = &mpdError{Code: mpdErrSystem, Message: err.Error()}
+			log.Warn("[mpd] %s: %s", args[0], err)
+		}
+		conn.printf("ACK [%d@%d] {%s} %s\n", e.Code, index, args[0], e.Message)
+		return false
+	}
+	return true
+}
+
+// idle 等待子系统发生变化,期间客户端只能发送noidle,返回false时关闭连接
+func (c *Mpd) idle(conn *mpdConn, subsystems []string, lines <-chan string) bool {
+	if len(subsystems) <= 0 {
+		subsystems = mpdSubsystems
+	}
+	var changed = func() []string {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		var list []string
+		for _, s := range subsystems {
+			if conn.events[s] {
+				list = append(list, s)
+				delete(conn.events, s)
+			}
+		}
+		return list
+	}
+
+	for {
+		if list := changed(); len(list) > 0 {
+			for _, s := range list {
+				conn.printf("changed: %s\n", s)
+			}
+			conn.printf("OK\n")
+			return true
+		}
+		select {
+		case <-conn.wake:
+		case line, ok := <-lines:
+			if !ok || strings.TrimSpace(line) != "noidle" {
+				return false
+			}
+			conn.printf("OK\n")
+			return true
+		}
+	}
+}
+
+// emit 通知所有客户端子系统发生变化,调用时需持有锁
+func (c *Mpd) emitLocked(subsystems ...string) {
+	for conn := range c.conns {
+		for _, s := range subsystems {
+			conn.events[s] = true
+		}
+		select {
+		case conn.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// mpdArgs 解析命令参数,参数以空白分隔,包含空白的参数使用双引号并以反斜杠转义
+func mpdArgs(line string) ([]string, error) {
+	var (
+		args []string
+		b    strings.Builder
+	)
+	line = strings.TrimSpace(line)
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ', '\t':
+			i++
+		case '"':
+			b.Reset()
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				b.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, fmt.Errorf("missing closing '\"'")
+			}
+			i++
+			args = append(args, b.String())
+		default:
+			var start = i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			args = append(args, line[start:i])
+		}
+	}
+	return args, nil
+}
+
+// mpdRange 解析位置参数,支持 POS 及 START:END 格式,END为空表示到末尾
+func mpdRange(arg string, n int) (int, int, error) {
+	from, to, ok := strings.Cut(arg, ":")
+	start, err := strconv.Atoi(from)
+	if err != nil || start < 0 {
+		return 0, 0, mpdErrorf(mpdErrArg, "Integer expected: %s", arg)
+	}
+	var end = start + 1
+	if ok {
+		end = n
+		if to != "" {
+			if end, err = strconv.Atoi(to); err != nil {
+				return 0, 0, mpdErrorf(mpdErrArg, "Integer expected: %s", arg)
+			}
+		}
+	}
+	if start > n || end > n || start > end || (!ok && start >= n) {
+		return 0, 0, mpdErrorf(mpdErrArg, "Bad song index")
+	}
+	return start, end, nil
+}
+
+// mpdArgInt 解析整数参数
+func mpdArgInt(args []string, i int) (int64, error) {
+	if len(args) <= i {
+		return 0, mpdErrorf(mpdErrArg, "too few arguments")
+	}
+	v, err := strconv.ParseInt(args[i], 10, 64)
+	if err != nil {
+		return 0, mpdErrorf(mpdErrArg, "Integer expected: %s", args[i])
+	}
+	return v, nil
+}
+
+// writeSong 输出歌曲信息,pos小于0时表示歌曲不在播放队列中
+func writeSong(conn *mpdConn, s mpdSong, pos int) {
+	conn.printf("file: %s%d\n", mpdSongScheme, s.Music.Id)
+	conn.printf("Title: %s\n", s.Name)
+	if len(s.Artist) > 0 {
+		conn.printf("Artist: %s\n", artistNames(s.Artist))
+	}
+	if s.Album.Name != "" {
+		conn.printf("Album: %s\n", s.Album.Name)
+	}
+	if s.Track > 0 {
+		conn.printf("Track: %d\n", s.Track)
+	}
+	if s.PublishTime > 0 {
+		conn.printf("Date: %d\n", time.UnixMilli(s.PublishTime).Year())
+	}
+	if s.Time > 0 {
+		conn.printf("Time: %d\nduration: %.3f\n", s.Time/1000, float64(s.Time)/1000)
+	}
+	if pos >= 0 {
+		conn.printf("Pos: %d\nId: %d\n", pos, s.Id)
+	}
+}
+
+// parseSongUri 将歌曲uri转换为 download 命令可识别的输入
+func parseSongUri(uri string) string {
+	return strings.TrimPrefix(uri, mpdSongScheme)
+}
+
+// mpdQueryRegexp 提取过滤表达式中的引号内容 eg: (any contains 'foo')
+var mpdQueryRegexp = regexp.MustCompile(`'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)"`)
+
+// mpdKeyword 将search、find命令的参数转换为搜索关键字,支持 TYPE VALUE 及过滤表达式两种格式
+func mpdKeyword(args []string) string {
+	var list []string
+	if len(args) > 0 && strings.HasPrefix(args[0], "(") {
+		for _, m := range mpdQueryRegexp.FindAllStringSubmatch(args[0], -1) {
+			list = append(list, strings.ReplaceAll(m[1]+m[2], `\`, ""))
+		}
+		return strings.Join(list, " ")
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		switch strings.ToLower(args[i]) {
+		case "window", "sort", "position":
+			continue
+		}
+		list = append(list, args[i+1])
+	}
+	return strings.Join(list, " ")
+}
+
+// loadPlaylists 加载账号歌单,歌单名称重复时追加歌单id区分
+func (c *Mpd) loadPlaylists(ctx context.Context) ([]weapi.PlaylistRespList, error) {
+	list, err := userPlaylists(ctx, c.request, c.uid)
+	if err != nil {
+		return nil, err
+	}
+	var names = make(map[string]int64, len(list))
+	for i, p := range list {
+		if _, ok := names[p.Name]; ok {
+			list[i].Name = fmt.Sprintf("%s (%d)", p.Name, p.Id)
+		}
+		names[list[i].Name] = p.Id
+	}
+	c.mu.Lock()
+	c.playlists = names
+	c.mu.Unlock()
+	return list, nil
+}
+
+// playlistSongs 根据歌单名称获取歌曲
+func (c *Mpd) playlistSongs(ctx context.Context, name string) ([]Music, error) {
+	c.mu.Lock()
+	id, ok := c.playlists[name]
+	c.mu.Unlock()
+	if !ok {
+		if _, err := c.loadPlaylists(ctx); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		id, ok = c.playlists[name]
+		c.mu.Unlock()
+	}
+	if !ok {
+		return nil, mpdErrorf(mpdErrNoExist, "No such playlist")
+	}
+	_, songs, err := playlistSongs(ctx, c.root, c.request, id)
+	return songs, err
+}
+
+// search 搜索歌曲
+func (c *Mpd) search(ctx context.Context, args []string) ([]Music, error) {
+	var keyword = mpdKeyword(args)
+	if keyword == "" {
+		return nil, mpdErrorf(mpdErrArg, "incorrect arguments")
+	}
+	resp, err := c.request.CloudSearch(ctx, &weapi.CloudSearchReq{S: keyword, Type: weapi.SearchTypeSong, Limit: 100})
+	if err != nil {
+		return nil, fmt.Errorf("CloudSearch: %w", err)
+	}
+	var songs = make([]Music, 0, len(resp.Result.Songs))
+	for _, s := range resp.Result.Songs {
+		songs = append(songs, Music{Id: s.Id, Name: s.Name, Artist: s.Ar, Album: s.Al, Time: s.Dt, PublishTime: s.PublishTime})
+	}
+	return songs, nil
+}
+
+// songs 解析歌曲uri或链接
+func (c *Mpd) songs(ctx context.Context, uri string) ([]Music, error) {
+	songs, err := NewDownload(c.root, c.l).inputParse(ctx, []string{parseSongUri(uri)}, c.request)
+	if err != nil {
+		return nil, mpdErrorf(mpdErrNoExist, "No such song: %s", err)
+	}
+	return songs, nil
+}
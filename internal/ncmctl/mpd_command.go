@@ -0,0 +1,688 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type mpdCommand func(c *Mpd, conn *mpdConn, args []string) error
+
+// mpdPublicCommands 设置了密码时未认证的客户端可以执行的命令
+var mpdPublicCommands = map[string]bool{
+	"ping":        true,
+	"password":    true,
+	"commands":    true,
+	"notcommands": true,
+	"tagtypes":    true,
+}
+
+// mpdCommands 支持的命令,数据库相关命令返回空结果,不支持的修改操作返回错误
+var mpdCommands map[string]mpdCommand
+
+func init() {
+	var (
+		empty    = func(c *Mpd, conn *mpdConn, args []string) error { return nil }
+		readonly = func(c *Mpd, conn *mpdConn, args []string) error {
+			return mpdErrorf(mpdErrPermission, "stored playlists are read only")
+		}
+		denied = func(c *Mpd, conn *mpdConn, args []string) error {
+			return mpdErrorf(mpdErrPermission, "permission denied")
+		}
+		noMixer = func(c *Mpd, conn *mpdConn, args []string) error {
+			return mpdErrorf(mpdErrSystem, "No mixer")
+		}
+		noSeek = func(c *Mpd, conn *mpdConn, args []string) error {
+			return mpdErrorf(mpdErrSystem, "seek is not supported")
+		}
+	)
+	mpdCommands = map[string]mpdCommand{
+		"ping":               empty,
+		"password":           (*Mpd).password,
+		"commands":           (*Mpd).commands,
+		"notcommands":        empty,
+		"tagtypes":           (*Mpd).tagtypes,
+		"urlhandlers":        (*Mpd).urlhandlers,
+		"decoders":           empty,
+		"outputs":            (*Mpd).outputs,
+		"enableoutput":       empty,
+		"disableoutput":      empty,
+		"toggleoutput":       empty,
+		"status":             (*Mpd).status,
+		"stats":              (*Mpd).stats,
+		"currentsong":        (*Mpd).currentsong,
+		"replay_gain_status": (*Mpd).replayGainStatus,
+		"replay_gain_mode":   empty,
+		"crossfade":          empty,
+		"mixrampdb":          empty,
+		"mixrampdelay":       empty,
+		"setvol":             noMixer,
+		"volume":             noMixer,
+		"getvol":             noMixer,
+		"play":               (*Mpd).play,
+		"playid":             (*Mpd).playid,
+		"pause":              (*Mpd).pause,
+		"stop":               (*Mpd).stop,
+		"next":               (*Mpd).next,
+		"previous":           (*Mpd).previous,
+		"seek":               noSeek,
+		"seekid":             noSeek,
+		"seekcur":            noSeek,
+		"random":             mpdOption(func(q *mpdQueue, on bool) { q.random = on }),
+		"repeat":             mpdOption(func(q *mpdQueue, on bool) { q.repeat = on }),
+		"single":             mpdOption(func(q *mpdQueue, on bool) { q.single = on }),
+		"consume":            mpdOption(func(q *mpdQueue, on bool) { q.consume = on }),
+		"add":                (*Mpd).add,
+		"addid":              (*Mpd).addid,
+		"delete":             (*Mpd).delete,
+		"deleteid":           (*Mpd).deleteid,
+		"clear":              (*Mpd).clear,
+		"move":               (*Mpd).move,
+		"moveid":             (*Mpd).moveid,
+		"shuffle":            (*Mpd).shuffle,
+		"playlistinfo":       (*Mpd).playlistinfo,
+		"playlistid":         (*Mpd).playlistid,
+		"plchanges":          (*Mpd).plchanges,
+		"plchangesposid":     (*Mpd).plchangesposid,
+		"playlistfind":       (*Mpd).playlistsearch,
+		"playlistsearch":     (*Mpd).playlistsearch,
+		"listplaylists":      (*Mpd).listplaylists,
+		"listplaylist":       (*Mpd).listplaylist,
+		"listplaylistinfo":   (*Mpd).listplaylistinfo,
+		"load":               (*Mpd).load,
+		"save":               readonly,
+		"rm":                 readonly,
+		"rename":             readonly,
+		"playlistadd":        readonly,
+		"playlistclear":      readonly,
+		"playlistdelete":     readonly,
+		"playlistmove":       readonly,
+		"lsinfo":             (*Mpd).lsinfo,
+		"listall":            empty,
+		"listallinfo":        empty,
+		"listfiles":          empty,
+		"list":               empty,
+		"count":              (*Mpd).count,
+		"search":             (*Mpd).find,
+		"find":               (*Mpd).find,
+		"searchadd":          (*Mpd).findadd,
+		"findadd":            (*Mpd).findadd,
+		"update":             (*Mpd).update,
+		"rescan":             (*Mpd).update,
+		"channels":           empty,
+		"readmessages":       empty,
+		"subscribe":          empty,
+		"unsubscribe":        empty,
+		"sendmessage":        empty,
+		"binarylimit":        empty,
+		"listmounts":         empty,
+		"listneighbors":      empty,
+		"config":             denied,
+		"kill":               denied,
+	}
+}
+
+func (c *Mpd) password(conn *mpdConn, args []string) error {
+	if len(args) != 1 || args[0] != c.opts.Password {
+		return mpdErrorf(mpdErrPassword, "incorrect password")
+	}
+	conn.authed = true
+	return nil
+}
+
+func (c *Mpd) commands(conn *mpdConn, args []string) error {
+	var list = make([]string, 0, len(mpdCommands))
+	for name := range mpdCommands {
+		if conn.authed || mpdPublicCommands[name] {
+			list = append(list, name)
+		}
+	}
+	list = append(list, "close", "idle", "noidle")
+	sort.Strings(list)
+	for _, name := range list {
+		conn.printf("command: %s\n", name)
+	}
+	return nil
+}
+
+func (c *Mpd) tagtypes(conn *mpdConn, args []string) error {
+	if len(args) > 0 {
+		return nil
+	}
+	for _, tag := range []string{"Artist", "Album", "Title", "Track", "Date"} {
+		conn.printf("tagtype: %s\n", tag)
+	}
+	return nil
+}
+
+func (c *Mpd) urlhandlers(conn *mpdConn, args []string) error {
+	conn.printf("handler: ncm://\n")
+	return nil
+}
+
+func (c *Mpd) outputs(conn *mpdConn, args []string) error {
+	conn.printf("outputid: 0\noutputname: %s\nplugin: external\noutputenabled: 1\n", c.player[0])
+	return nil
+}
+
+func (c *Mpd) status(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var (
+		q     = &c.queue
+		pos   = q.pos(q.current)
+		mpdOn = func(b bool) int {
+			if b {
+				return 1
+			}
+			return 0
+		}
+	)
+	conn.printf("repeat: %d\nrandom: %d\nsingle: %d\nconsume: %d\n", mpdOn(q.repeat), mpdOn(q.random), mpdOn(q.single), mpdOn(q.consume))
+	conn.printf("playlist: %d\nplaylistlength: %d\nstate: %s\n", q.version, len(q.songs), q.state)
+	if pos < 0 {
+		return nil
+	}
+	conn.printf("song: %d\nsongid: %d\n", pos, q.current)
+	if q.state != "stop" {
+		var (
+			elapsed  = q.elapsedTime().Seconds()
+			duration = float64(q.songs[pos].Time) / 1000
+		)
+		conn.printf("time: %d:%d\nelapsed: %.3f\nduration: %.3f\nbitrate: %d\n", int64(elapsed), int64(duration), elapsed, duration, q.bitrate)
+	}
+	if next := q.next(false); next >= 0 && !q.random {
+		conn.printf("nextsong: %d\nnextsongid: %d\n", next, q.songs[next].Id)
+	}
+	return nil
+}
+
+func (c *Mpd) stats(conn *mpdConn, args []string) error {
+	conn.printf("artists: 0\nalbums: 0\nsongs: 0\nuptime: %d\nplaytime: 0\ndb_playtime: 0\ndb_update: 0\n", int64(time.Since(c.started).Seconds()))
+	return nil
+}
+
+func (c *Mpd) currentsong(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pos := c.queue.pos(c.queue.current); pos >= 0 {
+		writeSong(conn, c.queue.songs[pos], pos)
+	}
+	return nil
+}
+
+func (c *Mpd) replayGainStatus(conn *mpdConn, args []string) error {
+	conn.printf("replay_gain_mode: off\n")
+	return nil
+}
+
+// play 播放指定位置的歌曲,未指定时恢复播放或从当前歌曲开始播放
+func (c *Mpd) play(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var q = &c.queue
+	if len(args) > 0 {
+		pos, err := mpdArgInt(args, 0)
+		if err != nil {
+			return err
+		}
+		if pos < 0 || pos >= int64(len(q.songs)) {
+			return mpdErrorf(mpdErrArg, "Bad song index")
+		}
+		c.playLocked(int(pos))
+		return nil
+	}
+	return c.resumeLocked()
+}
+
+func (c *Mpd) playid(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var q = &c.queue
+	if len(args) <= 0 {
+		return c.resumeLocked()
+	}
+	id, err := mpdArgInt(args, 0)
+	if err != nil {
+		return err
+	}
+	pos := q.pos(id)
+	if pos < 0 {
+		return mpdErrorf(mpdErrNoExist, "No such song")
+	}
+	c.playLocked(pos)
+	return nil
+}
+
+// resumeLocked 暂停时恢复播放,停止时从当前歌曲或第一首开始播放
+func (c *Mpd) resumeLocked() error {
+	var q = &c.queue
+	switch {
+	case q.state == "pause":
+		return c.pauseLocked(false)
+	case q.state == "play" || len(q.songs) <= 0:
+		return nil
+	}
+	c.playLocked(max(q.pos(q.current), 0))
+	return nil
+}
+
+// pause 暂停或恢复播放,未指定参数时切换状态
+func (c *Mpd) pause(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var pause = c.queue.state == "play"
+	if len(args) > 0 {
+		pause = args[0] == "1"
+	}
+	return c.pauseLocked(pause)
+}
+
+func (c *Mpd) stop(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopLocked()
+	return nil
+}
+
+func (c *Mpd) next(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.queue.state == "stop" {
+		return nil
+	}
+	c.advanceLocked(false)
+	return nil
+}
+
+func (c *Mpd) previous(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var q = &c.queue
+	if q.state == "stop" || len(q.songs) <= 0 {
+		return nil
+	}
+	var pos = q.pos(q.current) - 1
+	if pos < 0 {
+		if !q.repeat {
+			pos = 0
+		} else {
+			pos = len(q.songs) - 1
+		}
+	}
+	c.playLocked(pos)
+	return nil
+}
+
+// mpdOption 设置random、repeat、single、consume播放选项
+func mpdOption(set func(q *mpdQueue, on bool)) mpdCommand {
+	return func(c *Mpd, conn *mpdConn, args []string) error {
+		if len(args) != 1 {
+			return mpdErrorf(mpdErrArg, "wrong number of arguments")
+		}
+		var on bool
+		switch args[0] {
+		case "0":
+		case "1", "oneshot":
+			on = true
+		default:
+			return mpdErrorf(mpdErrArg, "Boolean (0/1) expected: %s", args[0])
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		set(&c.queue, on)
+		c.emitLocked("options")
+		return nil
+	}
+}
+
+func (c *Mpd) add(conn *mpdConn, args []string) error {
+	_, err := c.addUri(args)
+	return err
+}
+
+func (c *Mpd) addid(conn *mpdConn, args []string) error {
+	id, err := c.addUri(args)
+	if err != nil {
+		return err
+	}
+	conn.printf("Id: %d\n", id)
+	return nil
+}
+
+// addUri 添加歌曲、专辑或歌单链接到队列,可指定插入位置
+func (c *Mpd) addUri(args []string) (int64, error) {
+	if len(args) <= 0 {
+		return 0, mpdErrorf(mpdErrArg, "wrong number of arguments")
+	}
+	var pos int64 = -1
+	if len(args) > 1 {
+		v, err := mpdArgInt(args, 1)
+		if err != nil {
+			return 0, err
+		}
+		pos = v
+	}
+	songs, err := c.songs(c.ctx, args[0])
+	if err != nil {
+		return 0, err
+	}
+	if len(songs) <= 0 {
+		return 0, mpdErrorf(mpdErrNoExist, "No such song")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.addLocked(songs, int(pos)), nil
+}
+
+func (c *Mpd) delete(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(args) <= 0 {
+		return mpdErrorf(mpdErrArg, "wrong number of arguments")
+	}
+	start, end, err := mpdRange(args[0], len(c.queue.songs))
+	if err != nil {
+		return err
+	}
+	c.deleteLocked(start, end)
+	return nil
+}
+
+func (c *Mpd) deleteid(conn *mpdConn, args []string) error {
+	id, err := mpdArgInt(args, 0)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pos := c.queue.pos(id)
+	if pos < 0 {
+		return mpdErrorf(mpdErrNoExist, "No such song")
+	}
+	c.deleteLocked(pos, pos+1)
+	return nil
+}
+
+func (c *Mpd) clear(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopLocked()
+	c.queue.songs, c.queue.current = nil, 0
+	c.changedLocked()
+	return nil
+}
+
+// move 移动歌曲 move FROM[:TO] TO
+func (c *Mpd) move(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(args) != 2 {
+		return mpdErrorf(mpdErrArg, "wrong number of arguments")
+	}
+	start, end, err := mpdRange(args[0], len(c.queue.songs))
+	if err != nil {
+		return err
+	}
+	to, err := mpdArgInt(args, 1)
+	if err != nil {
+		return err
+	}
+	return c.moveLocked(start, end, int(to))
+}
+
+func (c *Mpd) moveid(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, err := mpdArgInt(args, 0)
+	if err != nil {
+		return err
+	}
+	to, err := mpdArgInt(args, 1)
+	if err != nil {
+		return err
+	}
+	pos := c.queue.pos(id)
+	if pos < 0 {
+		return mpdErrorf(mpdErrNoExist, "No such song")
+	}
+	return c.moveLocked(pos, pos+1, int(to))
+}
+
+func (c *Mpd) moveLocked(start, end, to int) error {
+	var q = &c.queue
+	if to < 0 || to+end-start > len(q.songs) {
+		return mpdErrorf(mpdErrArg, "Bad song index")
+	}
+	var moved = slices.Clone(q.songs[start:end])
+	q.songs = slices.Insert(slices.Delete(q.songs, start, end), to, moved...)
+	c.changedLocked()
+	return nil
+}
+
+func (c *Mpd) shuffle(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var songs = c.queue.songs
+	rand.Shuffle(len(songs), func(i, j int) { songs[i], songs[j] = songs[j], songs[i] })
+	c.changedLocked()
+	return nil
+}
+
+// playlistinfo 输出队列中的歌曲,可指定位置或范围
+func (c *Mpd) playlistinfo(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var start, end = 0, len(c.queue.songs)
+	if len(args) > 0 {
+		s, e, err := mpdRange(args[0], len(c.queue.songs))
+		if err != nil {
+			return err
+		}
+		start, end = s, e
+	}
+	for i := start; i < end; i++ {
+		writeSong(conn, c.queue.songs[i], i)
+	}
+	return nil
+}
+
+// playlistid 输出指定id的歌曲,未指定时输出全部歌曲
+func (c *Mpd) playlistid(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(args) <= 0 {
+		for i, s := range c.queue.songs {
+			writeSong(conn, s, i)
+		}
+		return nil
+	}
+	id, err := mpdArgInt(args, 0)
+	if err != nil {
+		return err
+	}
+	pos := c.queue.pos(id)
+	if pos < 0 {
+		return mpdErrorf(mpdErrNoExist, "No such song")
+	}
+	writeSong(conn, c.queue.songs[pos], pos)
+	return nil
+}
+
+// plchanges 不记录队列修改历史,返回全部歌曲
+func (c *Mpd) plchanges(conn *mpdConn, args []string) error {
+	return c.playlistinfo(conn, nil)
+}
+
+func (c *Mpd) plchangesposid(conn *mpdConn, args []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, s := range c.queue.songs {
+		conn.printf("cpos: %d\nId: %d\n", i, s.Id)
+	}
+	return nil
+}
+
+// playlistsearch 在队列中按关键字查找歌曲名称、歌手及专辑
+func (c *Mpd) playlistsearch(conn *mpdConn, args []string) error {
+	var keyword = strings.ToLower(mpdKeyword(args))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, s := range c.queue.songs {
+		var text = strings.ToLower(s.Name + " " + artistNames(s.Artist) + " " + s.Album.Name)
+		if strings.Contains(text, keyword) {
+			writeSong(conn, s, i)
+		}
+	}
+	return nil
+}
+
+func (c *Mpd) listplaylists(conn *mpdConn, args []string) error {
+	list, err := c.loadPlaylists(c.ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range list {
+		conn.printf("playlist: %s\nLast-Modified: %s\n", p.Name, playlistUpdateTime(p).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (c *Mpd) listplaylist(conn *mpdConn, args []string) error {
+	if len(args) <= 0 {
+		return mpdErrorf(mpdErrArg, "wrong number of arguments")
+	}
+	songs, err := c.playlistSongs(c.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	for _, s := range songs {
+		conn.printf("file: %s%d\n", mpdSongScheme, s.Id)
+	}
+	return nil
+}
+
+func (c *Mpd) listplaylistinfo(conn *mpdConn, args []string) error {
+	if len(args) <= 0 {
+		return mpdErrorf(mpdErrArg, "wrong number of arguments")
+	}
+	songs, err := c.playlistSongs(c.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	for _, s := range songs {
+		writeSong(conn, mpdSong{Music: s}, -1)
+	}
+	return nil
+}
+
+// load 将歌单歌曲添加到队列 load NAME [START:END] [POSITION]
+func (c *Mpd) load(conn *mpdConn, args []string) error {
+	if len(args) <= 0 {
+		return mpdErrorf(mpdErrArg, "wrong number of arguments")
+	}
+	songs, err := c.playlistSongs(c.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		start, end, err := mpdRange(args[1], len(songs))
+		if err != nil {
+			return err
+		}
+		songs = songs[start:end]
+	}
+	var pos int64 = -1
+	if len(args) > 2 {
+		if pos, err = mpdArgInt(args, 2); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addLocked(songs, int(pos))
+	return nil
+}
+
+// lsinfo 根目录下列出账号歌单,指定歌曲uri时输出歌曲信息
+func (c *Mpd) lsinfo(conn *mpdConn, args []string) error {
+	if len(args) <= 0 || args[0] == "" || args[0] == "/" {
+		return c.listplaylists(conn, nil)
+	}
+	if !strings.HasPrefix(args[0], mpdSongScheme) {
+		return mpdErrorf(mpdErrNoExist, "No such directory")
+	}
+	songs, err := c.songs(c.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	for _, s := range songs {
+		writeSong(conn, mpdSong{Music: s}, -1)
+	}
+	return nil
+}
+
+func (c *Mpd) count(conn *mpdConn, args []string) error {
+	conn.printf("songs: 0\nplaytime: 0\n")
+	return nil
+}
+
+// find 搜索网易云音乐歌曲
+func (c *Mpd) find(conn *mpdConn, args []string) error {
+	songs, err := c.search(c.ctx, args)
+	if err != nil {
+		return err
+	}
+	for _, s := range songs {
+		writeSong(conn, mpdSong{Music: s}, -1)
+	}
+	return nil
+}
+
+func (c *Mpd) findadd(conn *mpdConn, args []string) error {
+	songs, err := c.search(c.ctx, args)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addLocked(songs, -1)
+	return nil
+}
+
+// update 重新加载账号歌单
+func (c *Mpd) update(conn *mpdConn, args []string) error {
+	if _, err := c.loadPlaylists(c.ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.emitLocked("stored_playlist", "update", "database")
+	c.mu.Unlock()
+	conn.printf("updating_db: 1\n")
+	return nil
+}
@@ -0,0 +1,299 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"math/rand/v2"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+)
+
+// mpdSong 播放队列中的歌曲,Id在队列中唯一
+type mpdSong struct {
+	Music
+	Id int64
+}
+
+// mpdQueue 播放队列及播放状态
+type mpdQueue struct {
+	songs    []mpdSong
+	nextId   int64 // 下一首加入队列歌曲的id
+	version  int64 // 队列版本号,每次修改递增
+	current  int64 // 当前歌曲id,0表示没有
+	state    string
+	random   bool
+	repeat   bool
+	single   bool
+	consume  bool
+	started  time.Time     // 开始或恢复播放的时间
+	elapsed  time.Duration // 暂停前已播放的时长
+	bitrate  int64         // 当前歌曲码率kbps
+	proc     *exec.Cmd
+	cancel   context.CancelFunc
+	gen      int64 // 每次启动播放器时递增,用于区分播放器被中止还是播放结束
+	failures int   // 连续播放失败次数,避免队列中歌曲均无法播放时反复重试
+}
+
+// pos 返回歌曲id在队列中的位置
+func (q *mpdQueue) pos(id int64) int {
+	return slices.IndexFunc(q.songs, func(s mpdSong) bool { return s.Id == id })
+}
+
+// elapsedTime 当前歌曲已播放的时长
+func (q *mpdQueue) elapsedTime() time.Duration {
+	if q.state == "play" && !q.started.IsZero() {
+		return q.elapsed + time.Since(q.started)
+	}
+	return q.elapsed
+}
+
+// next 返回下一首歌曲的位置,没有时返回-1。auto为true表示当前歌曲自然播放结束
+func (q *mpdQueue) next(auto bool) int {
+	var (
+		n   = len(q.songs)
+		pos = q.pos(q.current)
+	)
+	switch {
+	case n <= 0:
+		return -1
+	case auto && q.single:
+		if q.repeat && pos >= 0 {
+			return pos
+		}
+		return -1
+	case q.random:
+		return rand.IntN(n)
+	case pos+1 < n:
+		return pos + 1
+	case q.repeat:
+		return 0
+	default:
+		return -1
+	}
+}
+
+// add 添加歌曲到队列中的指定位置,pos小于0时追加到末尾,返回第一首歌曲的id
+func (c *Mpd) addLocked(songs []Music, pos int) int64 {
+	var (
+		q     = &c.queue
+		list  = make([]mpdSong, 0, len(songs))
+		first int64
+	)
+	for _, m := range songs {
+		q.nextId++
+		if first == 0 {
+			first = q.nextId
+		}
+		list = append(list, mpdSong{Music: m, Id: q.nextId})
+	}
+	if pos < 0 || pos > len(q.songs) {
+		pos = len(q.songs)
+	}
+	q.songs = slices.Insert(q.songs, pos, list...)
+	c.changedLocked()
+	return first
+}
+
+// changedLocked 队列发生变化
+func (c *Mpd) changedLocked() {
+	c.queue.version++
+	c.emitLocked("playlist")
+}
+
+// deleteLocked 删除队列中[start,end)范围的歌曲,删除正在播放的歌曲时继续播放之后的歌曲
+func (c *Mpd) deleteLocked(start, end int) {
+	var (
+		q       = &c.queue
+		pos     = q.pos(q.current)
+		playing = pos >= start && pos < end
+	)
+	q.songs = slices.Delete(q.songs, start, end)
+	c.changedLocked()
+	if !playing {
+		return
+	}
+	if q.state != "stop" && start < len(q.songs) {
+		c.playLocked(start)
+		return
+	}
+	c.stopLocked()
+	q.current = 0
+}
+
+// playLocked 播放队列中指定位置的歌曲
+func (c *Mpd) playLocked(pos int) {
+	var q = &c.queue
+	c.killLocked()
+	var song = q.songs[pos]
+	q.current = song.Id
+	q.state = "play"
+	q.started, q.elapsed, q.bitrate = time.Now(), 0, 0
+	q.gen++
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	q.cancel = cancel
+	go c.run(ctx, q.gen, song)
+	c.emitLocked("player")
+}
+
+// killLocked 中止正在运行的播放器
+func (c *Mpd) killLocked() {
+	var q = &c.queue
+	q.gen++
+	if q.proc != nil && q.state == "pause" {
+		_ = pauseProcess(q.proc.Process, false)
+	}
+	if q.cancel != nil {
+		q.cancel()
+	}
+	q.proc, q.cancel = nil, nil
+}
+
+// stopLocked 停止播放
+func (c *Mpd) stopLocked() {
+	var q = &c.queue
+	c.killLocked()
+	if q.state != "stop" {
+		q.state = "stop"
+		q.started, q.elapsed, q.bitrate = time.Time{}, 0, 0
+		c.emitLocked("player")
+	}
+}
+
+// pauseLocked 暂停或恢复播放
+func (c *Mpd) pauseLocked(pause bool) error {
+	var q = &c.queue
+	switch {
+	case pause && q.state == "play":
+		if q.proc != nil {
+			if err := pauseProcess(q.proc.Process, true); err != nil {
+				return err
+			}
+		}
+		q.elapsed += time.Since(q.started)
+		q.state = "pause"
+	case !pause && q.state == "pause":
+		if q.proc != nil {
+			if err := pauseProcess(q.proc.Process, false); err != nil {
+				return err
+			}
+		}
+		q.started = time.Now()
+		q.state = "play"
+	default:
+		return nil
+	}
+	c.emitLocked("player")
+	return nil
+}
+
+// advanceLocked 当前歌曲播放结束或播放失败后播放下一首
+func (c *Mpd) advanceLocked(auto bool) {
+	var q = &c.queue
+	var next = q.next(auto)
+	if q.consume && auto {
+		if pos := q.pos(q.current); pos >= 0 {
+			q.songs = slices.Delete(q.songs, pos, pos+1)
+			c.changedLocked()
+			if next > pos {
+				next--
+			} else if next == pos && !q.random {
+				next = -1
+			}
+		}
+	}
+	if next < 0 || next >= len(q.songs) {
+		c.stopLocked()
+		return
+	}
+	c.playLocked(next)
+}
+
+// run 获取歌曲地址并调用外部播放器播放,播放结束后自动播放下一首
+func (c *Mpd) run(ctx context.Context, gen int64, song mpdSong) {
+	stream, err := c.resolver.StreamURL(ctx, song.Music.Id, types.Level(c.opts.Level))
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn("[mpd] %s skip: %s", song.Music, err)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.queue.gen != gen {
+			return
+		}
+		c.queue.failures++
+		if c.queue.failures >= len(c.queue.songs) {
+			log.Warn("[mpd] all songs in queue failed, stop playing")
+			c.queue.failures = 0
+			c.stopLocked()
+			return
+		}
+		c.advanceLocked(true)
+		return
+	}
+
+	var cmd = exec.CommandContext(ctx, c.player[0], append(c.player[1:], stream.Url)...)
+	c.mu.Lock()
+	if c.queue.gen != gen {
+		c.mu.Unlock()
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		log.Error("[mpd] player: %s", err)
+		c.stopLocked()
+		c.mu.Unlock()
+		return
+	}
+	c.queue.proc = cmd
+	c.queue.bitrate = stream.Br / 1000
+	c.queue.failures = 0
+	if c.queue.state == "pause" {
+		_ = pauseProcess(cmd.Process, true)
+	}
+	log.Info("[mpd] playing %s (%s)", song.Music, types.LevelString[stream.Level])
+	c.mu.Unlock()
+
+	err = cmd.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.queue.gen != gen {
+		return
+	}
+	if err != nil {
+		log.Warn("[mpd] player exit: %s", err)
+	}
+	var played = c.queue.elapsedTime()
+	if c.opts.Scrobble {
+		go reportPlay(c.ctx, c.request, song.Music, "list", played)
+	}
+	go forwardPlay(c.ctx, c.forwarder, song.Music, time.Now().Add(-played), played)
+	c.queue.proc = nil
+	c.advanceLocked(true)
+}
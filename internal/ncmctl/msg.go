@@ -0,0 +1,167 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type MsgOpts struct {
+	All   bool  // 包含已读消息
+	Limit int64 // 每类消息获取的数量
+}
+
+type Msg struct {
+	root *Root
+	cmd  *cobra.Command
+	opts MsgOpts
+	l    *log.Logger
+}
+
+func NewMsg(root *Root, l *log.Logger) *Msg {
+	c := &Msg{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "msg",
+			Short: "[need login] List unread private messages, mentions and notices, or send a message",
+			Example: "  ncmctl msg\n" +
+				"  ncmctl msg --all -n 10\n" +
+				"  ncmctl msg send 32953014 \"hello\"\n" +
+				"  ncmctl msg send 32953014 \"listen to this\" --song 2161154646",
+			Args: cobra.NoArgs,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	c.Add(msgSend(c, l))
+	return c
+}
+
+func (c *Msg) addFlags() {
+	c.cmd.Flags().BoolVar(&c.opts.All, "all", false, "include read messages")
+	c.cmd.Flags().Int64VarP(&c.opts.Limit, "limit", "n", 30, "number of each kind of messages to fetch")
+}
+
+func (c *Msg) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Msg) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Msg) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	private, err := request.MsgPrivate(ctx, &weapi.MsgPrivateReq{Limit: c.opts.Limit})
+	if err != nil {
+		return fmt.Errorf("MsgPrivate: %w", err)
+	}
+	forwards, err := request.MsgForwards(ctx, &weapi.MsgForwardsReq{Limit: c.opts.Limit})
+	if err != nil {
+		return fmt.Errorf("MsgForwards: %w", err)
+	}
+	notices, err := request.MsgNotices(ctx, &weapi.MsgNoticesReq{Limit: c.opts.Limit})
+	if err != nil {
+		return fmt.Errorf("MsgNotices: %w", err)
+	}
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "PRIVATE MESSAGES(%d unread)\n", private.NewMsgCount)
+	fmt.Fprintln(w, "USERID\tNICKNAME\tUNREAD\tTIME\tLAST MESSAGE")
+	for _, v := range private.Msgs {
+		if !c.opts.All && v.NewMsgCount <= 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", v.FromUser.UserId, v.FromUser.Nickname, v.NewMsgCount, msgTime(v.LastMsgTime), msgText(v.LastMsg))
+	}
+
+	fmt.Fprintf(w, "\nMENTIONS(%d unread)\n", forwards.NewCount)
+	fmt.Fprintln(w, "USERID\tNICKNAME\tTIME\tCONTENT")
+	for i, v := range forwards.Forwards {
+		// 按时间倒序返回,前NewCount条为未读
+		if !c.opts.All && int64(i) >= forwards.NewCount {
+			break
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", v.User.UserId, v.User.Nickname, msgTime(v.Time), msgText(v.Json))
+	}
+
+	fmt.Fprintln(w, "\nNOTICES")
+	fmt.Fprintln(w, "TIME\tTYPE\tCONTENT")
+	for _, v := range notices.Notices {
+		if !c.opts.All && v.Read {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", msgTime(v.Time), v.Type, msgText(v.Notice))
+	}
+	return w.Flush()
+}
+
+func msgTime(ms int64) string {
+	return time.UnixMilli(ms).Format("2006-01-02 15:04")
+}
+
+// msgText 解析消息中的json内容为单行文本,无法解析时返回原始内容
+func msgText(raw string) string {
+	var content weapi.MsgContent
+	if err := json.Unmarshal([]byte(raw), &content); err != nil {
+		return strings.Join(strings.Fields(raw), " ")
+	}
+	var text = strings.Join(strings.Fields(content.Msg), " ")
+	if s := content.Song; s != nil {
+		var artists = make([]string, 0, len(s.Artists))
+		for _, ar := range s.Artists {
+			artists = append(artists, ar.Name)
+		}
+		text = strings.TrimSpace(fmt.Sprintf("%s [song] %s - %s(%d)", text, strings.Join(artists, "/"), s.Name, s.Id))
+	}
+	if text == "" {
+		return strings.Join(strings.Fields(raw), " ")
+	}
+	return text
+}
@@ -0,0 +1,115 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type msgSendCmd struct {
+	root *Msg
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	song string // 分享的歌曲id或链接
+}
+
+func msgSend(root *Msg, l *log.Logger) *cobra.Command {
+	c := &msgSendCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "send <userId> [text]",
+		Short: "[need login] Send a text or song share message to a followed user",
+		Example: "  ncmctl msg send 32953014 \"hello\"\n" +
+			"  ncmctl msg send 32953014 --song 'https://music.163.com/song?id=2161154646'",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args[0], strings.Join(args[1:], " "))
+		},
+	}
+	c.cmd.Flags().StringVar(&c.song, "song", "", "share the song id or link")
+	return c.cmd
+}
+
+func (c *msgSendCmd) execute(ctx context.Context, user, text string) error {
+	uid, err := strconv.ParseInt(user, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %s", user)
+	}
+	var req = weapi.MsgSendReq{UserIds: fmt.Sprintf("[%d]", uid), Msg: text, Type: "text"}
+	if c.song != "" {
+		kind, id, err := Parse(c.song)
+		if err != nil {
+			return fmt.Errorf("Parse(%s): %w", c.song, err)
+		}
+		if kind != "song" {
+			return fmt.Errorf("%s is not a song link", c.song)
+		}
+		req.Type, req.Id = "song", fmt.Sprintf("%d", id)
+	}
+	if strings.TrimSpace(text) == "" && req.Type == "text" {
+		return fmt.Errorf("text is empty")
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	// 只允许给关注的用户发送私信,避免误发给陌生人
+	detail, err := request.GetUserInfoDetail(ctx, &weapi.GetUserInfoDetailReq{UserId: uid})
+	if err != nil {
+		return fmt.Errorf("GetUserInfoDetail: %w", err)
+	}
+	if !detail.Profile.Followed {
+		return fmt.Errorf("user %d(%s) is not followed", uid, detail.Profile.Nickname)
+	}
+
+	resp, err := request.MsgSend(ctx, &req)
+	if err != nil {
+		return fmt.Errorf("MsgSend: %w", err)
+	}
+	if len(resp.SendBlacklist) > 0 {
+		return fmt.Errorf("send to %d(%s) failed, blocked by the user", uid, detail.Profile.Nickname)
+	}
+	c.cmd.Printf("send to %d(%s) ok\n", uid, detail.Profile.Nickname)
+	return nil
+}
@@ -34,6 +34,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/chaunsin/netease-cloud-music/pkg/checksum"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
 	"github.com/chaunsin/netease-cloud-music/pkg/ncm"
 	"github.com/chaunsin/netease-cloud-music/pkg/ncm/tag"
@@ -45,9 +46,11 @@ import (
 )
 
 type NCMOpts struct {
-	Output   string // 生成文件路径
-	Parallel int64
-	Tag      bool
+	Output    string // 生成文件路径
+	Parallel  int64
+	Tag       bool
+	Checksum  string // 记录解密文件校验和使用的算法 see: checksum.Algorithm
+	ExecAfter string // 每个文件解密成功后执行的命令 see: hookVars
 }
 
 type NCM struct {
@@ -78,12 +81,17 @@ func (c *NCM) addFlags() {
 	c.cmd.PersistentFlags().StringVarP(&c.opts.Output, "output", "o", "./ncm", "output music dir")
 	c.cmd.PersistentFlags().Int64VarP(&c.opts.Parallel, "parallel", "p", 10, "concurrent decrypt count")
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Tag, "tag", false, "disable set a music tag info")
+	c.cmd.PersistentFlags().StringVar(&c.opts.ExecAfter, "exec-after", "", "command run after each file is decrypted, eg: 'beet import {file}'. variables: {id}、{file}、{dir}、{title}、{artist}、{album}、{quality}")
+	c.cmd.PersistentFlags().StringVar(&c.opts.Checksum, "checksum", string(checksum.MD5), "checksum algorithm recorded for decrypted files, used by verify command. support: md5、sha256、blake3")
 }
 
 func (c *NCM) validate() error {
 	if c.opts.Parallel > 50 || c.opts.Parallel < 1 {
 		return fmt.Errorf("parallel must be between 1 and 50")
 	}
+	if _, err := checksum.New(checksum.Algorithm(c.opts.Checksum)); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -183,7 +191,7 @@ func (c *NCM) execute(ctx context.Context, input []string) error {
 					log.Error("decode fail [%s]: %v, stack:%v", file, x, stack)
 				}
 			}()
-			if err := c.decode(file); err != nil {
+			if err := c.decode(ctx, file); err != nil {
 				c.cmd.Printf("decode[%s]: %v\n", file, err)
 				log.Error("decode[%s]: %v", file, err)
 				return
@@ -197,7 +205,7 @@ func (c *NCM) execute(ctx context.Context, input []string) error {
 	return nil
 }
 
-func (c *NCM) decode(filename string) error {
+func (c *NCM) decode(ctx context.Context, filename string) error {
 	_ncm, err := ncm.Open(filename)
 	if err != nil {
 		return fmt.Errorf("open: %w", err)
@@ -206,13 +214,16 @@ func (c *NCM) decode(filename string) error {
 
 	var (
 		meta   = _ncm.Metadata()
+		music  *ncm.MetadataMusic
 		format string
 	)
 	switch meta.GetType() {
 	case ncm.MetadataTypeMusic:
-		format = meta.GetMusic().Format
+		music = meta.GetMusic()
+		format = music.Format
 	case ncm.MetadataTypeDJ:
-		format = meta.GetDJ().MainMusic.Format
+		music = &meta.GetDJ().MainMusic
+		format = music.Format
 	}
 
 	var (
@@ -262,5 +273,31 @@ func (c *NCM) decode(filename string) error {
 	if err := os.Chmod(dest, 0644); err != nil {
 		return fmt.Errorf("chmod: %w", err)
 	}
+
+	// 记录写入tag后最终文件的校验和,供 verify 命令校验文件是否损坏
+	var alg = checksum.Algorithm(c.opts.Checksum)
+	sum, err := checksum.Sum(dest, alg)
+	if err != nil {
+		log.Warn("checksum %s err: %s", dest, err)
+	} else if err := checksum.Append(c.opts.Output, filepath.Base(dest), alg, sum); err != nil {
+		log.Warn("record checksum %s err: %s", dest, err)
+	}
+
+	// hook命令执行失败不影响解密结果
+	var vars = hookVars{File: dest, Dir: filepath.Dir(dest), Title: name, Quality: extend}
+	if music != nil {
+		vars.Id, vars.Title, vars.Album = music.Id, music.Name, music.Album
+		var artists = make([]string, 0, len(music.Artists))
+		for _, ar := range music.Artists {
+			artists = append(artists, ar.Name)
+		}
+		vars.Artist = strings.Join(artists, ",")
+		if music.BitRate > 0 {
+			vars.Quality = fmt.Sprintf("%s-%dk", extend, music.BitRate/1000)
+		}
+	}
+	if err := runHook(ctx, c.opts.ExecAfter, vars); err != nil {
+		log.Warn("[hook] %s %s", filename, err)
+	}
 	return nil
 }
@@ -24,11 +24,16 @@
 package ncmctl
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"time"
 
+	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/config"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
 	"github.com/chaunsin/netease-cloud-music/pkg/utils"
@@ -36,12 +41,22 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var profileRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
 const title = "                       _    _\n ___  ___  _____  ___ | |_ | |\n|   ||  _||     ||  _||  _|| |\n|_|_||___||_|_|_||___||_|  |_|\n"
 
 type RootOpts struct {
-	Debug  bool   // 是否开启命令行debug模式
-	Config string // 配置文件路径
-	Home   string
+	Debug          bool   // 是否开启命令行debug模式
+	Config         string // 配置文件路径
+	Home           string
+	Profile        string        // 账号配置名称,用于多账号切换
+	Proxy          string        // 代理地址,优先级大于配置文件
+	Output         string        // 全局输出格式 json、table、plain
+	LogLevel       string        // 日志级别,优先级大于配置文件
+	LogFile        string        // 日志文件路径,优先级大于配置文件
+	RequestTimeout time.Duration // 单次请求超时时间,优先级大于配置文件
+	Deadline       string        // 整个命令的最长执行时长或截止时间
+	DryRun         bool          // 修改账号数据的接口只输出请求内容不实际发送
 }
 
 type Root struct {
@@ -49,6 +64,105 @@ type Root struct {
 	Opts RootOpts
 	cmd  *cobra.Command
 	l    *log.Logger
+	home string      // 解析后的home路径
+	dirs config.Dirs // 配置、状态、缓存及数据目录
+	// defaultCookie 未指定profile时的cookie文件路径
+	defaultCookie string
+	// defaultDevice 未指定profile时的设备指纹文件路径
+	defaultDevice string
+	// defaultScrobbler 未指定profile时的听歌记录同步进度目录
+	defaultScrobbler string
+	// version 程序版本号
+	version string
+	// cfgPath 配置文件路径,未指定且默认位置不存在时为默认位置,供config命令读写
+	cfgPath string
+	// deadline 命令截止时间,未设置时为零值
+	deadline time.Time
+	cancel   context.CancelFunc
+	// monitor 守护进程及api服务的监控指标,未开启时为nil
+	monitor *monitor
+}
+
+// load 确定数据目录并加载配置文件及账号,补全命令不会执行PersistentPreRunE,需要时单独调用
+func (c *Root) load(cmd *cobra.Command) error {
+	var (
+		cfgPath = utils.Ternary(c.Opts.Config != "", c.Opts.Config, os.Getenv(config.EnvConfig))
+		home    = utils.Ternary(c.Opts.Home != "", filepath.Clean(c.Opts.Home), "")
+		getenv  = os.Getenv
+	)
+	// 指定--home时只根据home确定目录,忽略NCMCTL_HOME及XDG环境变量
+	if cmd.Flags().Changed("home") {
+		getenv = func(string) string { return "" }
+	}
+	dirs, err := config.ResolveDirs(home, getenv)
+	if err != nil {
+		return err
+	}
+	// config命令可指定尚不存在的配置文件,由 config set/edit 创建
+	if cfgPath != "" && !utils.FileExists(cfgPath) {
+		if !annotated(cmd, lenientConfig) {
+			return fmt.Errorf("config file not exists: %s", cfgPath)
+		}
+		c.cfgPath, cfgPath = cfgPath, ""
+	}
+	// 未指定配置文件时使用配置目录下的配置文件,不存在则使用默认配置
+	if cfgPath == "" && utils.FileExists(defaultConfigPath(dirs)) {
+		cfgPath = defaultConfigPath(dirs)
+	}
+	if c.cfgPath == "" {
+		c.cfgPath = utils.Ternary(cfgPath != "", cfgPath, defaultConfigPath(dirs))
+	}
+	if cfgPath != "" {
+		c.Cfg, err = config.New(cfgPath)
+		if err != nil && !annotated(cmd, lenientConfig) {
+			return fmt.Errorf("init config error: %s", err)
+		}
+	}
+	if c.Cfg == nil {
+		c.Cfg = config.GetDefault()
+	}
+
+	c.Cfg.ReplaceMagicVariables(dirs.Variables(home))
+	c.home, c.dirs, c.defaultCookie, c.defaultDevice = home, dirs, c.Cfg.Network.Cookie.Filepath, c.Cfg.Network.Device.Filepath
+	if c.Cfg.Scrobbler != nil {
+		c.defaultScrobbler = c.Cfg.Scrobbler.Path
+	}
+	if c.Opts.Profile == "" {
+		c.Opts.Profile = c.Cfg.Profile
+	}
+	if c.Opts.Profile != "" {
+		if !profileRegexp.MatchString(c.Opts.Profile) {
+			return fmt.Errorf("invalid profile name: %s", c.Opts.Profile)
+		}
+		c.Cfg.Network.Cookie.Filepath = profileCookiePath(dirs, c.Opts.Profile)
+		if c.Cfg.Network.Device.Filepath != "" {
+			c.Cfg.Network.Device.Filepath = profileDevicePath(dirs, c.Opts.Profile)
+		}
+		if c.Cfg.Scrobbler != nil {
+			c.Cfg.Scrobbler.Path = profileScrobblerPath(dirs, c.Opts.Profile)
+		}
+	}
+	return nil
+}
+
+// defaultConfigPath 未指定配置文件时默认加载的配置文件路径
+func defaultConfigPath(dirs config.Dirs) string {
+	return filepath.Join(dirs.Config, "config.yaml")
+}
+
+// profileCookiePath 不同账号的cookie相互隔离存储在 ${STATE}/profiles/<profile>/ 目录下
+func profileCookiePath(dirs config.Dirs, profile string) string {
+	return filepath.Join(dirs.State, "profiles", profile, "cookie.json")
+}
+
+// profileDevicePath 不同账号使用各自的设备指纹
+func profileDevicePath(dirs config.Dirs, profile string) string {
+	return filepath.Join(dirs.State, "profiles", profile, "device.json")
+}
+
+// profileScrobblerPath 不同账号使用各自的听歌记录同步进度
+func profileScrobblerPath(dirs config.Dirs, profile string) string {
+	return filepath.Join(dirs.State, "profiles", profile, "scrobbler")
 }
 
 func New() *Root {
@@ -57,30 +171,43 @@ func New() *Root {
 			Use:     "ncmctl",
 			Short:   "ncmctl command",
 			Long:    "ncmctl is a toolbox for netease cloud music\n\nMIT License Copyright (c) 2024 chaunsin\nhttps://github.com/chaunsin/netease-cloud-music\n" + title,
-			Example: "  ncmctl cloud\n  ncmctl crypto\n  ncmctl login\n  ncmctl curl\n  ncmctl partner\n  ncmctl scrobble\n  ncmctl sign\n  ncmctl task",
+			Example: "  ncmctl api\n  ncmctl art\n  ncmctl backup\n  ncmctl cast\n  ncmctl chart\n  ncmctl check\n  ncmctl cloud\n  ncmctl comment\n  ncmctl completion\n  ncmctl config\n  ncmctl crypto\n  ncmctl daemon\n  ncmctl daily\n  ncmctl digest\n  ncmctl login\n  ncmctl msg\n  ncmctl curl\n  ncmctl fm\n  ncmctl follow\n  ncmctl history\n  ncmctl info\n  ncmctl keepalive\n  ncmctl library\n  ncmctl like\n  ncmctl liked\n  ncmctl listens\n  ncmctl lyric\n  ncmctl mpd\n  ncmctl partner\n  ncmctl play\n  ncmctl podcast\n  ncmctl playlist\n  ncmctl prerelease\n  ncmctl profile\n  ncmctl recommend\n  ncmctl restore\n  ncmctl scan\n  ncmctl scrobble\n  ncmctl search\n  ncmctl server\n  ncmctl share\n  ncmctl sign\n  ncmctl signin\n  ncmctl sub\n  ncmctl subsonic\n  ncmctl task\n  ncmctl tui\n  ncmctl unfollow\n  ncmctl verify\n  ncmctl vip\n  ncmctl watch\n  ncmctl yunbei",
 		},
 	}
 	c.cmd.SetVersionTemplate(`{{printf "%s\n" .Version}}`)
 	c.cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		var (
-			cfgPath = c.Opts.Config
-			home    = filepath.Clean(utils.Ternary(c.Opts.Home != "", c.Opts.Home, config.HomeDir))
-		)
-		if c.Opts.Config != "" {
-			var err error
-			if !utils.FileExists(c.Opts.Config) {
-				return fmt.Errorf("config file not exists: %s", c.Opts.Config)
-			}
-			c.Cfg, err = config.New(c.Opts.Config)
+		if err := c.load(cmd); err != nil {
+			return err
+		}
+		if c.Opts.Proxy != "" {
+			c.Cfg.Network.Proxy.Url = c.Opts.Proxy
+		}
+		if c.Opts.LogLevel != "" {
+			c.Cfg.Log.Level = c.Opts.LogLevel
+		}
+		if c.Opts.LogFile != "" {
+			c.Cfg.Log.Rotate.Filename = c.Opts.LogFile
+		}
+		if c.Opts.RequestTimeout < 0 {
+			return fmt.Errorf("request-timeout must be >= 0")
+		}
+		if c.Opts.RequestTimeout > 0 {
+			c.Cfg.Network.Timeout = c.Opts.RequestTimeout
+		}
+		c.Cfg.Network.DryRun = c.Opts.DryRun
+		if c.Opts.Deadline != "" {
+			deadline, err := parseDeadline(c.Opts.Deadline, time.Now())
 			if err != nil {
-				return fmt.Errorf("init config error: %s", err)
+				return fmt.Errorf("deadline: %w", err)
 			}
-		} else {
-			cfgPath = "default"
-			c.Cfg = config.GetDefault()
+			// 截止时间通过context传递给所有接口请求及下载,到期后中止
+			ctx, cancel := context.WithDeadline(cmd.Context(), deadline)
+			c.deadline, c.cancel = deadline, cancel
+			cmd.SetContext(ctx)
+		}
+		if err := validOutput(c.Opts.Output); err != nil {
+			return err
 		}
-
-		c.Cfg.ReplaceMagicVariables("HOME", home)
 		if err := c.Cfg.Validate(); err != nil {
 			return fmt.Errorf("config validate error: %s", err)
 		}
@@ -90,14 +217,28 @@ func New() *Root {
 		// 命令行开启了debug模式优先级大于配置文件中得优先级
 		if c.Opts.Debug {
 			c.Cfg.Log.Stdout = true
-			c.Cfg.Log.Level = "debug"
 			c.Cfg.Network.Debug = true
+			if c.Opts.LogLevel == "" {
+				c.Cfg.Log.Level = "debug"
+			}
 		}
 
 		// init logger
 		c.l = log.New(c.Cfg.Log)
 		log.Default = c.l
-		log.Debug("[config] init home=%s path=%s log=%+v network=%+v", home, cfgPath, c.Cfg.Log, c.Cfg.Network)
+
+		// 终端交互时由用户完成风控安全验证后继续执行
+		if isTerminal(os.Stdin) && isTerminal(os.Stderr) {
+			api.DefaultChallengeHandler = new(challengePrompt).handle
+		}
+		log.Debug("[config] init home=%s dirs=%+v path=%s log=%+v network=%+v", c.home, c.dirs, c.cfgPath, c.Cfg.Log, c.Cfg.Network)
+
+		// 检查登录cookie有效期,临近过期时自动刷新
+		if !annotated(cmd, skipKeepAlive) && utils.FileExists(c.Cfg.Network.Cookie.Filepath) {
+			if err := c.keepAlive(cmd.Context()); err != nil {
+				log.Warn("[keepalive] %s", err)
+			}
+		}
 		return nil
 	}
 	c.cmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
@@ -114,20 +255,74 @@ func New() *Root {
 	c.Add(NewCurl(c, c.l).Command())
 	c.Add(NewCloud(c, c.l).Command())
 	c.Add(NewTask(c, c.l).Command())
+	c.Add(NewDaemon(c, c.l).Command())
 	c.Add(NewScrobble(c, c.l).Command())
 	c.Add(NewSignIn(c, c.l).Command())
+	c.Add(NewDailySignIn(c, c.l).Command())
 	c.Add(NewNCM(c, c.l).Command())
 	c.Add(NewDownload(c, c.l).Command())
+	c.Add(NewExport(c, c.l).Command())
+	c.Add(NewShare(c, c.l).Command())
+	c.Add(NewKeepAlive(c, c.l).Command())
+	c.Add(NewPlaylist(c, c.l).Command())
+	c.Add(NewDaily(c, c.l).Command())
+	c.Add(NewVip(c, c.l).Command())
+	c.Add(NewSearch(c, c.l).Command())
+	c.Add(NewPreRelease(c, c.l).Command())
+	c.Add(NewTui(c, c.l).Command())
+	c.Add(NewPlay(c, c.l).Command())
+	c.Add(NewFm(c, c.l).Command())
+	c.Add(NewRecommend(c, c.l).Command())
+	c.Add(NewVerify(c, c.l).Command())
+	c.Add(NewLibrary(c, c.l).Command())
+	c.Add(NewDigest(c, c.l).Command())
+	c.Add(NewProfile(c, c.l).Command())
+	c.Add(NewApi(c, c.l).Command())
+	c.Add(NewBackup(c, c.l).Command())
+	c.Add(NewRestore(c, c.l).Command())
+	c.Add(NewLike(c, c.l).Command())
+	c.Add(NewLiked(c, c.l).Command())
+	c.Add(NewComment(c, c.l).Command())
+	c.Add(NewPodcast(c, c.l).Command())
+	c.Add(NewMsg(c, c.l).Command())
+	c.Add(NewFollow(c, c.l).Command())
+	c.Add(NewUnfollow(c, c.l).Command())
+	c.Add(NewSub(c, c.l).Command())
+	c.Add(NewCheck(c, c.l).Command())
+	c.Add(NewInfo(c, c.l).Command())
+	c.Add(NewScan(c, c.l).Command())
+	c.Add(NewLyric(c, c.l).Command())
+	c.Add(NewChart(c, c.l).Command())
+	c.Add(NewWatch(c, c.l).Command())
+	c.Add(NewConfig(c, c.l).Command())
+	c.Add(NewCompletion(c, c.l).Command())
+	c.Add(NewServer(c, c.l).Command())
+	c.Add(NewArt(c, c.l).Command())
+	c.Add(NewSubsonic(c, c.l).Command())
+	c.Add(NewMpd(c, c.l).Command())
+	c.Add(NewCast(c, c.l).Command())
+	c.Add(NewListens(c, c.l).Command())
+	c.Add(NewHistory(c, c.l).Command())
+	c.Add(NewYunBei(c, c.l).Command())
 	return c
 }
 
 func (c *Root) addFlags() {
 	c.cmd.PersistentFlags().BoolVar(&c.Opts.Debug, "debug", false, "run in debug mode")
-	c.cmd.PersistentFlags().StringVarP(&c.Opts.Config, "config", "c", "", "configuration file path")
-	c.cmd.PersistentFlags().StringVar(&c.Opts.Home, "home", config.HomeDir, "configuration home path. the home path is used to store running information")
+	c.cmd.PersistentFlags().StringVarP(&c.Opts.Config, "config", "c", "", "configuration file path, also can be set by NCMCTL_CONFIG env. default ${CONFIG}/config.yaml if exists")
+	c.cmd.PersistentFlags().StringVar(&c.Opts.Home, "home", config.HomeDir, "user home path. directories follow XDG_CONFIG_HOME、XDG_STATE_HOME、XDG_CACHE_HOME、XDG_DATA_HOME unless specified, NCMCTL_HOME env stores everything in one directory")
+	c.cmd.PersistentFlags().StringVar(&c.Opts.Profile, "profile", os.Getenv("NCMCTL_PROFILE"), "account profile name, each profile has its own login cookie. also can be set by NCMCTL_PROFILE env")
+	c.cmd.PersistentFlags().StringVar(&c.Opts.Proxy, "proxy", "", "proxy url, support http、https、socks5 eg: socks5://127.0.0.1:1080")
+	c.cmd.PersistentFlags().StringVar(&c.Opts.LogLevel, "log-level", "", "log level, support: debug、info、warn、error. overrides the configuration file")
+	c.cmd.PersistentFlags().StringVar(&c.Opts.LogFile, "log-file", "", "log file path, rotated by size and by day when log.daily is enabled. overrides the configuration file")
+	c.cmd.PersistentFlags().StringVar(&c.Opts.Output, "output-format", "", "result output format for scripts, support: json、table、plain. commands keep their own default when empty")
+	c.cmd.PersistentFlags().DurationVar(&c.Opts.RequestTimeout, "request-timeout", 0, "per request timeout, downloads abort when no data is received within it. overrides the configuration file. distinct from the --timeout of cast, curl and login")
+	c.cmd.PersistentFlags().BoolVar(&c.Opts.DryRun, "dry-run", false, "print api calls and payloads that modify account data (playlist edits, likes, cloud uploads, scrobbles, comments) instead of sending them. commands with their own --dry-run keep their own behavior")
+	c.cmd.PersistentFlags().StringVar(&c.Opts.Deadline, "deadline", "", "abort the whole command after the duration or at the time, eg: 2h、06:00、2006-01-02T15:04:05+08:00")
 }
 
 func (c *Root) Version(version, buildTime, commitHash string) {
+	c.version = version
 	c.cmd.Version = fmt.Sprintf("%s\n Version: \t%s\n Go version: \t%s\n Git commit: \t%s\n OS/Arch: \t%s\n Build time: \t%s",
 		title, version, runtime.Version(), commitHash, runtime.GOOS+"/"+runtime.GOARCH, buildTime)
 }
@@ -137,8 +332,84 @@ func (c *Root) Add(command ...*cobra.Command) {
 }
 
 func (c *Root) Execute() {
-	if err := c.cmd.Execute(); err != nil {
+	err := c.cmd.Execute()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if err != nil {
 		c.cmd.PrintErrln(err)
-		os.Exit(1)
+		// 超过截止时间中止的命令统一使用超时退出码
+		if !c.deadline.IsZero() && !time.Now().Before(c.deadline) {
+			c.cmd.PrintErrf("deadline %s exceeded\n", c.deadline.Format(time.DateTime))
+			os.Exit(ExitTimeout)
+		}
+		var e *exitError
+		if errors.As(err, &e) {
+			os.Exit(e.code)
+		}
+		// 接口返回需要登录的业务错误
+		if errors.Is(err, api.ErrNeedLogin) {
+			os.Exit(ExitNeedLogin)
+		}
+		os.Exit(ExitFailure)
+	}
+}
+
+// 命令退出码,便于脚本或定时任务根据退出码判断执行结果
+const (
+	ExitSuccess        = 0
+	ExitFailure        = 1
+	ExitNeedLogin      = 2
+	ExitPartialFailure = 3
+	ExitTimeout        = 124 // 超过--deadline截止时间
+	ExitInterrupted    = 130 // 用户中断,比如下载时按下ctrl+c
+)
+
+// parseDeadline 解析截止时间,支持时长(2h)、当天时刻(06:00,已过则为次日)及RFC3339时间
+func parseDeadline(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("duration must be > 0: %s", s)
+		}
+		return now.Add(d), nil
+	}
+	if t, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		var deadline = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !deadline.After(now) {
+			deadline = deadline.AddDate(0, 0, 1)
+		}
+		return deadline, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unsupported format: %s", s)
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("%s is already passed", s)
+	}
+	return t, nil
+}
+
+// exitError 携带退出码的错误
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// annotated 判断命令及其父命令是否设置了指定注解
+func annotated(cmd *cobra.Command, key string) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if _, ok := cmd.Annotations[key]; ok {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,66 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/alert"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+)
+
+// notify 根据配置文件中的alert配置发送通知,未配置时直接忽略
+func (c *Root) notify(ctx context.Context, title, content string) {
+	c.notifyData(ctx, title, content, nil)
+}
+
+// notifyData 发送通知,http webhook的json内容中额外携带data字段,便于接收方解析处理
+func (c *Root) notifyData(ctx context.Context, title, content string, data any) {
+	var cfg = c.Cfg.Alert
+	if cfg == nil || cfg.Module == "" {
+		return
+	}
+
+	a, err := alert.New(cfg.Module, cfg)
+	if err != nil {
+		log.Warn("[notify] alert.New: %s", err)
+		return
+	}
+	defer a.Close(ctx)
+
+	var msg = fmt.Sprintf("%s\n%s", title, content)
+	if cfg.Module == alert.ModuleHTTP {
+		var payload = map[string]any{"title": title, "content": content}
+		if data != nil {
+			payload["data"] = data
+		}
+		body, _ := json.Marshal(payload)
+		msg = string(body)
+	}
+	if err := a.Send(ctx, msg); err != nil {
+		log.Warn("[notify] send %s: %s", title, err)
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"math/rand"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
@@ -44,8 +45,10 @@ import (
 
 type PartnerOpts struct {
 	Star    []int64
+	Weight  []int64 // 基础评分权重,与Star一一对应,为空则等概率
 	ExtStar []int64
 	ExtNum  string
+	TagNum  int64 // 每首歌曲随机选取标签的最大数量
 }
 
 type Partner struct {
@@ -62,7 +65,7 @@ func NewPartner(root *Root, l *log.Logger) *Partner {
 		cmd: &cobra.Command{
 			Use:     "partner",
 			Short:   "[need login] Executive music partner daily reviews, rule details: https://y.music.163.com/g/yida/9fecf6a378be49a7a109ae9befb1b8d3",
-			Example: "  ncmctl partner (default)\n  ncmctl partner -s 3,4 (set the base song evaluation score level random range 3-4)\n  ncmctl partner -s 3,4 -e 2,3,4 (set the random range of song evaluation rating 3-4, and the random range of additional songs 2-4)\n  ncmctl partner -n 5 (set the number of additional evaluation songs)",
+			Example: "  ncmctl partner (default)\n  ncmctl partner -s 3,4 (set the base song evaluation score level random range 3-4)\n  ncmctl partner -s 3,4 -e 2,3,4 (set the random range of song evaluation rating 3-4, and the random range of additional songs 2-4)\n  ncmctl partner -n 5 (set the number of additional evaluation songs)\n  ncmctl partner -s 3,4,5 -w 30,60,10 (score 3 with 30%, 4 with 60%, 5 with 10% probability)\n  ncmctl partner -t 2 (randomly select 1-2 tags for each song)",
 		},
 	}
 	c.addFlags()
@@ -78,6 +81,8 @@ func (c *Partner) addFlags() {
 	c.cmd.PersistentFlags().Int64SliceVarP(&c.opts.Star, "star", "s", []int64{3, 4}, "set the base song evaluation score level random range 1-5")
 	c.cmd.PersistentFlags().Int64SliceVarP(&c.opts.ExtStar, "extra", "e", []int64{2, 3, 4}, "set the extra song evaluation score level random range 1-5")
 	c.cmd.PersistentFlags().StringVarP(&c.opts.ExtNum, "num", "n", "random", "extra evaluation number of songs,'random' means 2 to 7")
+	c.cmd.PersistentFlags().Int64SliceVarP(&c.opts.Weight, "weight", "w", nil, "set the probability weight of each base score level in --star order, eg: -s 3,4 -w 30,70")
+	c.cmd.PersistentFlags().Int64VarP(&c.opts.TagNum, "tags", "t", 3, "max number of tags randomly selected for each song 1-5")
 }
 
 func (c *Partner) validate() error {
@@ -111,6 +116,25 @@ func (c *Partner) validate() error {
 		return fmt.Errorf("extra star level must be unique")
 	}
 
+	if len(c.opts.Weight) > 0 {
+		if len(c.opts.Weight) != len(c.opts.Star) {
+			return fmt.Errorf("weight must correspond to star one by one")
+		}
+		var total int64
+		for _, w := range c.opts.Weight {
+			if w < 0 {
+				return fmt.Errorf("weight must be >= 0")
+			}
+			total += w
+		}
+		if total <= 0 {
+			return fmt.Errorf("sum of weight must be > 0")
+		}
+	}
+	if c.opts.TagNum < 1 || c.opts.TagNum > 5 {
+		return fmt.Errorf("tags must be range 1-5")
+	}
+
 	if c.opts.ExtNum == "" {
 		return fmt.Errorf("num is empty")
 	}
@@ -161,15 +185,12 @@ func (c *Partner) do(ctx context.Context) error {
 
 	// 判断是否有音乐合伙人资格
 	info, err := request.PartnerUserinfo(ctx, &weapi.PartnerUserinfoReq{ReqCommon: types.ReqCommon{}})
+	if e, ok := api.AsError(err); ok && e.Code == 703 {
+		return fmt.Errorf("您不是音乐合伙人不能进行测评: %w", err)
+	}
 	if err != nil {
 		return fmt.Errorf("PartnerUserinfo: %w", err)
 	}
-	if info.Code == 703 {
-		return fmt.Errorf("您不是音乐合伙人不能进行测评 detail: %+v\n", info)
-	}
-	if info.Code != 200 {
-		return fmt.Errorf("PartnerUserinfo err: %+v\n", info)
-	}
 	switch status := info.Data.Status; status {
 	case "NORMAL":
 	case "ELIMINATED":
@@ -203,10 +224,9 @@ func (c *Partner) do(ctx context.Context) error {
 		// 模拟听歌消耗得时间,随机15-25秒
 		time.Sleep(time.Second * time.Duration(15+int(rand.Int31n(10))))
 
-		// 随机一个分数,然后从对应分数组中取一个tag
-		star := c.opts.Star[rand.Int31n(int32(len(c.opts.Star)))]
-		group := weapi.PartnerTagsGroup[star]
-		tags := group[rand.Int31n(int32(len(group)))]
+		// 按权重随机一个分数,然后从对应分数组中随机取若干tag
+		star := c.score()
+		tags := c.tags(star)
 
 		// 上报
 		var reportReq = &weapi.PartnerExtraReportReq{
@@ -216,15 +236,11 @@ func (c *Partner) do(ctx context.Context) error {
 			BizResourceId: "",
 			InteractType:  "PLAY_END",
 		}
-		resp, err := request.PartnerExtraReport(ctx, reportReq)
-		if err != nil {
-			return fmt.Errorf("PartnerExtraReport: %w", err)
-		}
-		switch resp.Code {
-		case 200:
-			// ok
-		default:
-			log.Error("PartnerExtraReport(%+v) err: %+v\n", reportReq, resp)
+		if _, err := request.PartnerExtraReport(ctx, reportReq); err != nil {
+			if _, ok := api.AsError(err); !ok {
+				return fmt.Errorf("PartnerExtraReport: %w", err)
+			}
+			log.Error("PartnerExtraReport(%+v) err: %s\n", reportReq, err)
 			continue
 		}
 
@@ -235,7 +251,7 @@ func (c *Partner) do(ctx context.Context) error {
 		// 执行测评
 		var extScore = make(map[string]int64, 3)
 		for _, t := range work.SupportExtraEvaTypes {
-			extScore[fmt.Sprintf("%v", t)] = c.opts.ExtStar[rand.Int31n(int32(len(c.opts.Star)))]
+			extScore[fmt.Sprintf("%v", t)] = c.opts.ExtStar[rand.Int31n(int32(len(c.opts.ExtStar)))]
 		}
 		extraScore, err := json.Marshal(extScore)
 		if err != nil {
@@ -257,18 +273,19 @@ func (c *Partner) do(ctx context.Context) error {
 		}
 		evalResp, err := request.PartnerEvaluate(ctx, req)
 		if err != nil {
-			return fmt.Errorf("PartnerEvaluate: %w", err)
+			if _, ok := api.AsError(err); !ok {
+				return fmt.Errorf("PartnerEvaluate: %w", err)
+			}
+			log.Error("PartnerEvaluate(%+v) err: %s\n", req, err)
+			continue
 		}
 		switch evalResp.Code {
 		case 200:
 			baseNum++
-			// ok
+			log.Info("[partner] base %s - %s score=%v tags=%s extra=%s", work.Work.Name, work.Work.AuthorName, star, tags, extraScore)
 		case 405:
 			baseNum++
 			// 当前任务歌曲已完成评
-		default:
-			log.Error("PartnerEvaluate(%+v) err: %+v\n", req, resp)
-			// return fmt.Errorf("PartnerEvaluate: %v", resp.Message)
 		}
 	}
 
@@ -300,10 +317,9 @@ func (c *Partner) do(ctx context.Context) error {
 			// 模拟听歌消耗得时间,随机15-25秒
 			time.Sleep(time.Second * time.Duration(15+int(rand.Int31n(10))))
 
-			// 随机一个分数,然后从对应分数组中取一个tag
-			star := c.opts.Star[rand.Int31n(int32(len(c.opts.Star)))]
-			group := weapi.PartnerTagsGroup[star]
-			tags := group[rand.Int31n(int32(len(group)))]
+			// 按权重随机一个分数,然后从对应分数组中随机取若干tag
+			star := c.score()
+			tags := c.tags(star)
 
 			// 上报听歌事件
 
@@ -317,22 +333,18 @@ func (c *Partner) do(ctx context.Context) error {
 				BizResourceId: "",
 				InteractType:  "PLAY_END",
 			}
-			resp, err := request.PartnerExtraReport(ctx, req)
-			if err != nil {
-				return fmt.Errorf("PartnerExtraReport: %w", err)
-			}
-			switch resp.Code {
-			case 200:
-				// ok
-			default:
-				log.Error("PartnerExtraReport(%+v) err: %+v\n", req, resp)
+			if _, err := request.PartnerExtraReport(ctx, req); err != nil {
+				if _, ok := api.AsError(err); !ok {
+					return fmt.Errorf("PartnerExtraReport: %w", err)
+				}
+				log.Error("PartnerExtraReport(%+v) err: %s\n", req, err)
 				continue
 			}
 
 			// 执行测评
 			var extScore = make(map[string]int64, 3)
 			for _, t := range work.SupportExtraEvaTypes {
-				extScore[fmt.Sprintf("%v", t)] = c.opts.ExtStar[rand.Int31n(int32(len(c.opts.Star)))]
+				extScore[fmt.Sprintf("%v", t)] = c.opts.ExtStar[rand.Int31n(int32(len(c.opts.ExtStar)))]
 			}
 			extraScore, err := json.Marshal(extScore)
 			if err != nil {
@@ -354,11 +366,16 @@ func (c *Partner) do(ctx context.Context) error {
 			}
 			evaluateResp, err := request.PartnerEvaluate(ctx, evaluateReq)
 			if err != nil {
-				return fmt.Errorf("PartnerEvaluate: %w", err)
+				if _, ok := api.AsError(err); !ok {
+					return fmt.Errorf("PartnerEvaluate: %w", err)
+				}
+				log.Error("PartnerEvaluate(%+v) err: %s\n", evaluateReq, err)
+				continue
 			}
 			switch evaluateResp.Code {
 			case 200:
 				extNum++
+				log.Info("[partner] extra %s - %s score=%v tags=%s extra=%s", work.Work.Name, work.Work.AuthorName, star, tags, extraScore)
 				executeNum--
 				if executeNum <= 0 {
 					goto end
@@ -366,18 +383,47 @@ func (c *Partner) do(ctx context.Context) error {
 			case 405:
 				extNum++
 				// 当前任务歌曲已完成评
-			default:
-				log.Error("PartnerEvaluate(%+v) err: %+v\n", req, resp)
-				// return fmt.Errorf("PartnerEvaluate: %v", resp.Message)
 			}
 		}
 	}
 end:
 
 	// 刷新token过期时间
-	refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-	if err != nil || refresh.Code != 200 {
-		log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+	if _, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{}); err != nil {
+		log.Warn("TokenRefresh: %s", err)
 	}
 	return nil
 }
+
+// score 按权重随机选取一个基础评分
+func (c *Partner) score() int64 {
+	if len(c.opts.Weight) != len(c.opts.Star) {
+		return c.opts.Star[rand.Int31n(int32(len(c.opts.Star)))]
+	}
+	var total int64
+	for _, w := range c.opts.Weight {
+		total += w
+	}
+	n := rand.Int63n(total)
+	for i, w := range c.opts.Weight {
+		if n < w {
+			return c.opts.Star[i]
+		}
+		n -= w
+	}
+	return c.opts.Star[len(c.opts.Star)-1]
+}
+
+// tags 从评分对应的标签组中随机选取1~TagNum个不重复标签
+func (c *Partner) tags(star int64) weapi.PartnerTags {
+	group := slices.Clone(weapi.PartnerTagsGroup[star])
+	rand.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+	num := 1 + rand.Int63n(max(c.opts.TagNum, 1))
+	num = min(num, int64(len(group)))
+
+	var list = make([]string, 0, num)
+	for _, t := range group[:num] {
+		list = append(list, string(t))
+	}
+	return weapi.PartnerTags(strings.Join(list, ","))
+}
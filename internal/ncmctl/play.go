@@ -0,0 +1,211 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/resolver"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type PlayOpts struct {
+	Player   string // 播放器命令及参数
+	Level    string // 播放音质
+	Shuffle  bool   // 随机播放
+	Scrobble bool   // 播放结束后上报听歌记录
+}
+
+type Play struct {
+	root *Root
+	cmd  *cobra.Command
+	opts PlayOpts
+	l    *log.Logger
+}
+
+func NewPlay(root *Root, l *log.Logger) *Play {
+	c := &Play{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "play",
+			Short: "[need login] Play songs, albums or playlists through an external player",
+			Long: "Play songs, albums or playlists through an external player(default mpv).\n" +
+				"Playback controls are provided by the player, for mpv: space pause, left/right seek, q next song.\n" +
+				"Press ctrl+c to stop playing.",
+			Example: "  ncmctl play 2161154646\n" +
+				"  ncmctl play --shuffle 'https://music.163.com/#/playlist?id=3136952023'\n" +
+				"  ncmctl play --player 'ffplay -nodisp -autoexit' 2161154646",
+			Args: cobra.MinimumNArgs(1),
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args)
+	}
+	return c
+}
+
+func (c *Play) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Player, "player", "mpv --no-video", "player command, the song url is appended as the last argument")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelExhigh), "song quality level. support: standard,higher,exhigh,lossless,hires")
+	c.cmd.Flags().BoolVar(&c.opts.Shuffle, "shuffle", false, "play in random order")
+	c.cmd.Flags().BoolVar(&c.opts.Scrobble, "scrobble", true, "report the play record after each song")
+}
+
+func (c *Play) validate() error {
+	if len(strings.Fields(c.opts.Player)) <= 0 {
+		return fmt.Errorf("player is empty")
+	}
+	if _, ok := types.LevelString[types.Level(c.opts.Level)]; !ok {
+		return fmt.Errorf("[%s] quality is not support", c.opts.Level)
+	}
+	return nil
+}
+
+func (c *Play) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Play) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Play) execute(ctx context.Context, args []string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	var player = strings.Fields(c.opts.Player)
+	if _, err := exec.LookPath(player[0]); err != nil {
+		return fmt.Errorf("player %s not found: %w", player[0], err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	songs, err := NewDownload(c.root, c.l).inputParse(ctx, args, request)
+	if err != nil {
+		return fmt.Errorf("inputParse: %w", err)
+	}
+	if c.opts.Shuffle {
+		rand.Shuffle(len(songs), func(i, j int) { songs[i], songs[j] = songs[j], songs[i] })
+	}
+
+	var (
+		r         = resolver.New(request, nil)
+		forwarder = newForwarder(c.root)
+	)
+	for i, song := range songs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		stream, err := r.StreamURL(ctx, song.Id, types.Level(c.opts.Level))
+		if err != nil {
+			log.Warn("[play] %s skip: %s", song, err)
+			continue
+		}
+		c.cmd.Printf("[%d/%d] %s - %s (%s)\n", i+1, len(songs), artistNames(song.Artist), song.Name, types.LevelString[stream.Level])
+
+		var start = time.Now()
+		played, err := runPlayer(ctx, player, stream.Url)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c.opts.Scrobble {
+			reportPlay(ctx, request, song, "list", played)
+		}
+		forwardPlay(ctx, forwarder, song, start, played)
+	}
+	return nil
+}
+
+// runPlayer 调用外部播放器播放url并返回播放时长,播放器异常退出时只记录日志
+func runPlayer(ctx context.Context, player []string, url string) (time.Duration, error) {
+	var (
+		start = time.Now()
+		cmd   = exec.CommandContext(ctx, player[0], append(player[1:], url)...)
+	)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	var played = time.Since(start)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return played, fmt.Errorf("player: %w", err)
+		}
+		log.Warn("[play] player exit: %s", err)
+	}
+	return played, nil
+}
+
+// reportPlay 上报听歌记录,播放时长不足歌曲时长90%视为中途切歌
+func reportPlay(ctx context.Context, request *weapi.Api, song Music, source string, played time.Duration) {
+	var end = "playend"
+	if song.Time > 0 && played < time.Duration(song.Time)*time.Millisecond*9/10 {
+		end = "interrupt"
+	}
+	var req = &weapi.WebLogReq{CsrfToken: "", Logs: []map[string]interface{}{
+		{
+			"action": "play",
+			"json": map[string]interface{}{
+				"type":     "song",
+				"wifi":     0,
+				"download": 0,
+				"id":       song.Id,
+				"time":     int64(played.Seconds()),
+				"end":      end,
+				"source":   source,
+				"sourceId": "",
+				"mainsite": "1",
+				"content":  "",
+			},
+		},
+	}}
+	if _, err := request.WebLog(ctx, req); err != nil {
+		log.Warn("[play] WebLog(%d): %s", song.Id, err)
+	}
+}
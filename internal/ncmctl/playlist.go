@@ -0,0 +1,164 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type Playlist struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewPlaylist(root *Root, l *log.Logger) *Playlist {
+	c := &Playlist{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "playlist",
+			Short: "[need login] Manage created and subscribed playlists",
+			Example: "  ncmctl playlist list --subscribed\n" +
+				"  ncmctl playlist sub 'https://music.163.com/#/playlist?id=19723756'\n" +
+				"  ncmctl playlist unsub 19723756\n" +
+				"  ncmctl playlist unsub --stale 2y --dry-run\n" +
+				"  ncmctl playlist heartbeat --seed 2161154646 --download\n" +
+				"  ncmctl playlist create 'my favorites'\n" +
+				"  cat ids.txt | ncmctl playlist add-tracks 19723756\n" +
+				"  ncmctl playlist export 19723756 -d ./download --format m3u8\n" +
+				"  ncmctl playlist mirror 19723756 -o ./mirror --prune",
+		},
+	}
+	c.addFlags()
+	c.Add(playlistList(c, l))
+	c.Add(playlistSub(c, l))
+	c.Add(playlistUnsub(c, l))
+	c.Add(playlistHeartbeat(c, l))
+	c.Add(playlistCreate(c, l))
+	c.Add(playlistRm(c, l))
+	c.Add(playlistRename(c, l))
+	c.Add(playlistAddTracks(c, l))
+	c.Add(playlistDelTracks(c, l))
+	c.Add(playlistExport(c, l))
+	c.Add(playlistMirror(c, l))
+	return c
+}
+
+func (c *Playlist) addFlags() {}
+
+func (c *Playlist) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Playlist) Command() *cobra.Command {
+	return c.cmd
+}
+
+// userPlaylists 分页获取用户创建及收藏得全部歌单
+func userPlaylists(ctx context.Context, request *weapi.Api, uid int64) ([]weapi.PlaylistRespList, error) {
+	const limit = 1000
+	var list []weapi.PlaylistRespList
+	for offset := 0; ; offset += limit {
+		resp, err := request.Playlist(ctx, &weapi.PlaylistReq{
+			Uid:    strconv.FormatInt(uid, 10),
+			Offset: strconv.Itoa(offset),
+			Limit:  strconv.Itoa(limit),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Playlist: %w", err)
+		}
+		list = append(list, resp.Playlist...)
+		if !resp.More || len(resp.Playlist) <= 0 {
+			return list, nil
+		}
+	}
+}
+
+// playlistUpdateTime 歌单最近一次更新歌曲的时间
+func playlistUpdateTime(p weapi.PlaylistRespList) time.Time {
+	if p.TrackUpdateTime > 0 {
+		return time.UnixMilli(p.TrackUpdateTime)
+	}
+	return time.UnixMilli(p.UpdateTime)
+}
+
+// parsePlaylistId 解析歌单id,支持歌单id及歌单分享链接
+func parsePlaylistId(source string) (int64, error) {
+	if id, err := strconv.ParseInt(source, 10, 64); err == nil {
+		return id, nil
+	}
+	kind, id, err := Parse(source)
+	if err != nil {
+		return 0, err
+	}
+	if kind != "playlist" {
+		return 0, fmt.Errorf("%s is not a playlist link", source)
+	}
+	return id, nil
+}
+
+// playlistSongs 获取歌单名称以及按歌单顺序排列的全部歌曲,歌曲详情批量查询后按歌单中的顺序还原
+func playlistSongs(ctx context.Context, root *Root, request *weapi.Api, pid int64) (string, []Music, error) {
+	detail, err := request.PlaylistDetail(ctx, &weapi.PlaylistDetailReq{Id: fmt.Sprintf("%d", pid)})
+	if err != nil {
+		return "", nil, fmt.Errorf("PlaylistDetail(%v): %w", pid, err)
+	}
+	if len(detail.Playlist.TrackIds) <= 0 {
+		return detail.Playlist.Name, nil, nil
+	}
+
+	var args = make([]string, 0, len(detail.Playlist.TrackIds))
+	for _, v := range detail.Playlist.TrackIds {
+		args = append(args, fmt.Sprintf("%d", v.Id))
+	}
+	list, err := NewDownload(root, root.l).inputParse(ctx, args, request)
+	if err != nil {
+		return "", nil, fmt.Errorf("inputParse: %w", err)
+	}
+	var (
+		detailMap = make(map[int64]Music, len(list))
+		songs     = make([]Music, 0, len(list))
+	)
+	for _, s := range list {
+		detailMap[s.Id] = s
+	}
+	for _, v := range detail.Playlist.TrackIds {
+		song, ok := detailMap[v.Id]
+		if !ok {
+			log.Warn("[playlist] %d song %d detail not found, skip", pid, v.Id)
+			continue
+		}
+		songs = append(songs, song)
+	}
+	return detail.Playlist.Name, songs, nil
+}
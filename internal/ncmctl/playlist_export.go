@@ -0,0 +1,255 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/resolver"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/checksum"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/normalize"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// outerUrl 歌曲外链地址,长期有效但只能获取免费音质
+const outerUrl = "https://music.163.com/song/media/outer/url?id=%d.mp3"
+
+type playlistExportCmd struct {
+	root *Playlist
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	format   string // 导出格式 m3u、m3u8
+	dir      string // 已下载歌曲目录
+	output   string // 导出文件路径
+	relative bool   // 本地文件使用相对导出文件的路径
+	resolve  bool   // 未下载歌曲使用实时解析的播放地址
+	level    string // 解析播放地址的音质
+}
+
+func playlistExport(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistExportCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "export <playlist>",
+		Short: "Export a playlist to m3u/m3u8, referencing downloaded local files when present",
+		Long: "Export a playlist to m3u/m3u8. Songs already downloaded into --dir (found through the .checksums\n" +
+			"manifests written by download) reference the local file, other songs fall back to a stream url.\n" +
+			"The default stream url never expires but only provides the free quality, --resolve uses the real\n" +
+			"stream url of --level which expires in a while.",
+		Example: "  ncmctl playlist export 19723756\n" +
+			"  ncmctl playlist export 19723756 -d ./download -o ./download/playlist.m3u8 --relative\n" +
+			"  ncmctl playlist export 19723756 --format m3u --resolve -l lossless -o - | vlc -",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(root.root.completePlaylists, cobra.NoFileCompletions),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args[0])
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *playlistExportCmd) addFlags() {
+	c.cmd.Flags().StringVar(&c.format, "format", "m3u8", "export format, support: m3u、m3u8")
+	c.cmd.Flags().StringVarP(&c.dir, "dir", "d", "./download", "downloaded music directory, empty means always using stream url")
+	c.cmd.Flags().StringVarP(&c.output, "output", "o", "", "export file path, '-' means stdout. default <playlist name>.<format> in current directory")
+	c.cmd.Flags().BoolVar(&c.relative, "relative", false, "local file path relative to the export file, suitable for MPD music directory")
+	c.cmd.Flags().BoolVar(&c.resolve, "resolve", false, "use real stream url for songs not downloaded, the url expires in a while")
+	c.cmd.Flags().StringVarP(&c.level, "level", "l", string(types.LevelExhigh), "stream url quality level when --resolve. support: standard,higher,exhigh,lossless,hires")
+}
+
+func (c *playlistExportCmd) validate() error {
+	if c.format != "m3u" && c.format != "m3u8" {
+		return fmt.Errorf("format is not support: %s", c.format)
+	}
+	if _, ok := types.LevelString[types.Level(c.level)]; !ok {
+		return fmt.Errorf("[%s] quality is not support", c.level)
+	}
+	if c.relative && c.output == "-" {
+		return fmt.Errorf("--relative can not be used with stdout")
+	}
+	return nil
+}
+
+func (c *playlistExportCmd) execute(ctx context.Context, source string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	pid, err := parsePlaylistId(source)
+	if err != nil {
+		return fmt.Errorf("parsePlaylistId: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	name, songs, err := playlistSongs(ctx, c.root.root, request, pid)
+	if err != nil {
+		return err
+	}
+	if len(songs) <= 0 {
+		return fmt.Errorf("playlist %d is empty", pid)
+	}
+	n, err := normalize.New(c.root.root.Cfg.Normalize)
+	if err != nil {
+		return fmt.Errorf("normalize: %w", err)
+	}
+
+	var output = c.output
+	if output == "" {
+		output = fmt.Sprintf("%s.%s", utils.Filename(name, "_"), c.format)
+	}
+	if output != "-" {
+		if output, err = utils.ExpandTilde(output); err != nil {
+			return fmt.Errorf("ExpandTilde: %w", err)
+		}
+		if output, err = filepath.Abs(output); err != nil {
+			return fmt.Errorf("Abs: %w", err)
+		}
+	}
+	local, err := c.localFiles()
+	if err != nil {
+		return err
+	}
+
+	var (
+		r       = resolver.New(request, nil)
+		entries = make([]string, 0, len(songs))
+		found   int
+	)
+	for _, song := range songs {
+		song = normalizeMusic(n, song)
+		var location string
+		if path, ok := local[fmt.Sprintf("%s - %s", song.ArtistString(), song.NameString())]; ok {
+			found++
+			location = path
+			if c.relative {
+				if location, err = filepath.Rel(filepath.Dir(output), path); err != nil {
+					return fmt.Errorf("Rel: %w", err)
+				}
+			}
+		} else if c.resolve {
+			stream, err := r.StreamURL(ctx, song.Id, types.Level(c.level))
+			if err != nil {
+				log.Warn("[export] %s stream url: %s, use outer url", song, err)
+				location = fmt.Sprintf(outerUrl, song.Id)
+			} else {
+				location = stream.Url
+			}
+		} else {
+			location = fmt.Sprintf(outerUrl, song.Id)
+		}
+		entries = append(entries, fmt.Sprintf("#EXTINF:%d,%s - %s\n%s", song.Time/1000, artistNames(song.Artist), song.Name, location))
+	}
+
+	if output == "-" {
+		return writeM3u(os.Stdout, entries)
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	defer file.Close()
+	if err := writeM3u(file, entries); err != nil {
+		return err
+	}
+	c.cmd.Printf("exported %d songs(%d local) to %s\n", len(entries), found, output)
+	return nil
+}
+
+// localFiles 读取下载目录中的校验和清单,返回 "歌手 - 歌名"->文件绝对路径,与download生成的文件名规则一致
+func (c *playlistExportCmd) localFiles() (map[string]string, error) {
+	var files = make(map[string]string)
+	if c.dir == "" {
+		return files, nil
+	}
+	dir, err := utils.ExpandTilde(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return nil, fmt.Errorf("Abs: %w", err)
+	}
+	if !utils.DirExists(dir) {
+		log.Warn("[export] %s not found, use stream url for all songs", dir)
+		return files, nil
+	}
+	for _, alg := range []checksum.Algorithm{checksum.MD5, checksum.SHA256} {
+		sums, err := manifests(dir, alg)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range checksum.Names(sums) {
+			var (
+				path = filepath.Join(dir, filepath.FromSlash(name))
+				key  = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+			)
+			if _, ok := files[key]; ok {
+				continue
+			}
+			// 清单中可能存在已被删除的文件
+			if !utils.FileExists(path) {
+				continue
+			}
+			files[key] = path
+		}
+	}
+	return files, nil
+}
+
+// writeM3u 写入扩展m3u播放列表,entries为包含#EXTINF的条目
+func writeM3u(w io.Writer, entries []string) error {
+	var buf = bufio.NewWriter(w)
+	_, _ = fmt.Fprintln(buf, "#EXTM3U")
+	for _, e := range entries {
+		_, _ = fmt.Fprintln(buf, e)
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
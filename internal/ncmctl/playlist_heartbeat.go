@@ -0,0 +1,171 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type playlistHeartbeatCmd struct {
+	root *Playlist
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	seed     string // 种子歌曲id或链接
+	playlist string // 歌单id,默认"我喜欢的音乐"
+	download bool
+	output   string
+	level    string
+	parallel int64
+}
+
+func playlistHeartbeat(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistHeartbeatCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "heartbeat",
+		Short: "Generate the heartbeat mode queue from a favorite song",
+		Example: "  ncmctl playlist heartbeat --seed 2161154646\n" +
+			"  ncmctl playlist heartbeat --seed 'https://music.163.com/song?id=2161154646' --download -o ./heartbeat",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *playlistHeartbeatCmd) addFlags() {
+	c.cmd.Flags().StringVar(&c.seed, "seed", "", "seed song id or link, usually a song in the liked playlist")
+	c.cmd.Flags().StringVar(&c.playlist, "playlist", "", "playlist id or link the seed song belongs to. default the liked playlist")
+	c.cmd.Flags().BoolVar(&c.download, "download", false, "download the generated songs")
+	c.cmd.Flags().StringVarP(&c.output, "output", "o", "./download", "music file output path")
+	c.cmd.Flags().StringVarP(&c.level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().Int64VarP(&c.parallel, "parallel", "p", 5, "concurrent download count")
+	_ = c.cmd.MarkFlagRequired("seed")
+}
+
+func (c *playlistHeartbeatCmd) execute(ctx context.Context) error {
+	seed, err := strconv.ParseInt(c.seed, 10, 64)
+	if err != nil {
+		kind, id, err := Parse(c.seed)
+		if err != nil {
+			return fmt.Errorf("Parse: %w", err)
+		}
+		if kind != "song" {
+			return fmt.Errorf("%s is not a song link", c.seed)
+		}
+		seed = id
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var pid int64
+	if c.playlist != "" {
+		if pid, err = parsePlaylistId(c.playlist); err != nil {
+			return fmt.Errorf("parsePlaylistId: %w", err)
+		}
+	} else {
+		if pid, err = likedPlaylistId(ctx, request, user.Account.Id); err != nil {
+			return err
+		}
+	}
+
+	resp, err := request.PlaymodeIntelligenceList(ctx, &weapi.PlaymodeIntelligenceListReq{SongId: seed, PlaylistId: pid})
+	if err != nil {
+		return fmt.Errorf("PlaymodeIntelligenceList: %w", err)
+	}
+	if len(resp.Data) <= 0 {
+		c.cmd.Println("heartbeat mode returned no songs")
+		return nil
+	}
+
+	var (
+		w   = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		ids = make([]string, 0, len(resp.Data))
+	)
+	fmt.Fprintln(w, "#\tID\tNAME\tARTIST\tALBUM\tDURATION\tRECOMMENDED")
+	for i, v := range resp.Data {
+		var (
+			s = v.SongInfo
+			d = time.Duration(s.Dt) * time.Millisecond
+		)
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%02d:%02d\t%v\n", i+1, v.Id, s.Name, artistNames(s.Ar), s.Al.Name, int(d.Minutes()), int(d.Seconds())%60, v.Recommended)
+		ids = append(ids, strconv.FormatInt(v.Id, 10))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !c.download {
+		return nil
+	}
+
+	d := NewDownload(c.root.root, c.l)
+	d.opts.Output = c.output
+	d.opts.Level = c.level
+	d.opts.Parallel = c.parallel
+	return d.execute(ctx, ids)
+}
+
+// likedPlaylistId 获取用户"我喜欢的音乐"歌单id
+func likedPlaylistId(ctx context.Context, request *weapi.Api, uid int64) (int64, error) {
+	list, err := userPlaylists(ctx, request, uid)
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range list {
+		// specialType 5 为"我喜欢的音乐"歌单
+		if v.SpecialType == 5 && v.UserId == uid {
+			return v.Id, nil
+		}
+	}
+	return 0, fmt.Errorf("liked playlist not found")
+}
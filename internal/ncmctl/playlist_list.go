@@ -0,0 +1,107 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type playlistListCmd struct {
+	root *Playlist
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	mine       bool // 只显示自己创建的歌单
+	subscribed bool // 只显示收藏的歌单
+}
+
+func playlistList(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistListCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "list",
+		Short:   "List created and subscribed playlists",
+		Example: "  ncmctl playlist list\n  ncmctl playlist list --mine\n  ncmctl playlist list --subscribed",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *playlistListCmd) addFlags() {
+	c.cmd.Flags().BoolVar(&c.mine, "mine", false, "only list playlists created by yourself")
+	c.cmd.Flags().BoolVar(&c.subscribed, "subscribed", false, "only list subscribed playlists")
+}
+
+func (c *playlistListCmd) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	list, err := userPlaylists(ctx, request, user.Account.Id)
+	if err != nil {
+		return err
+	}
+	c.root.root.cachePlaylists(list)
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tTRACKS\tUPDATED\tCREATOR\tNAME")
+	for _, v := range list {
+		var mine = v.UserId == user.Account.Id
+		if (c.mine && !mine) || (c.subscribed && mine) {
+			continue
+		}
+		var kind = "subscribed"
+		if mine {
+			kind = "mine"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\n", v.Id, kind, v.TrackCount,
+			playlistUpdateTime(v).Format("2006-01-02"), v.Creator.Nickname, v.Name)
+	}
+	return w.Flush()
+}
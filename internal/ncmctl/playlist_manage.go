@@ -0,0 +1,277 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+// playlistTracksBatch 每次添加或删除歌曲的数量
+const playlistTracksBatch = 100
+
+type playlistManageCmd struct {
+	root *Playlist
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	private bool // 创建隐私歌单
+}
+
+func playlistCreate(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistManageCmd{root: root, l: l}
+	c.cmd = &cobra.Command{
+		Use:     "create <name>",
+		Short:   "Create a playlist and print its id",
+		Example: "  ncmctl playlist create 'my favorites'\n  ncmctl playlist create secret --private",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(cmd.Context(), func(ctx context.Context, request *weapi.Api) error {
+				return c.create(ctx, request, args[0])
+			})
+		},
+	}
+	c.cmd.Flags().BoolVar(&c.private, "private", false, "create a private playlist")
+	return c.cmd
+}
+
+func playlistRm(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistManageCmd{root: root, l: l}
+	c.cmd = &cobra.Command{
+		Use:               "rm <playlist>...",
+		Short:             "Delete playlists created by yourself",
+		Example:           "  ncmctl playlist rm 19723756 19723757",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: root.root.completePlaylists,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(cmd.Context(), func(ctx context.Context, request *weapi.Api) error {
+				return c.remove(ctx, request, args)
+			})
+		},
+	}
+	return c.cmd
+}
+
+func playlistRename(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistManageCmd{root: root, l: l}
+	c.cmd = &cobra.Command{
+		Use:               "rename <playlist> <name>",
+		Short:             "Rename a playlist",
+		Example:           "  ncmctl playlist rename 19723756 'new name'",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeArgs(root.root.completePlaylists, cobra.NoFileCompletions),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(cmd.Context(), func(ctx context.Context, request *weapi.Api) error {
+				return c.rename(ctx, request, args[0], args[1])
+			})
+		},
+	}
+	return c.cmd
+}
+
+func playlistAddTracks(root *Playlist, l *log.Logger) *cobra.Command {
+	return playlistTracks(root, l, "add")
+}
+
+func playlistDelTracks(root *Playlist, l *log.Logger) *cobra.Command {
+	return playlistTracks(root, l, "del")
+}
+
+func playlistTracks(root *Playlist, l *log.Logger, op string) *cobra.Command {
+	c := &playlistManageCmd{root: root, l: l}
+	var short = "Add songs to a playlist"
+	if op == "del" {
+		short = "Remove songs from a playlist"
+	}
+	c.cmd = &cobra.Command{
+		Use:   op + "-tracks <playlist> [song|-]...",
+		Short: short + ", read song ids or links from stdin when no song is given or song is '-'",
+		Example: fmt.Sprintf("  ncmctl playlist %[1]s-tracks 19723756 2161154646 'https://music.163.com/song?id=1820944399'\n"+
+			"  cat ids.txt | ncmctl playlist %[1]s-tracks 19723756", op),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeArgs(root.root.completePlaylists, root.root.completeSongs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(cmd.Context(), func(ctx context.Context, request *weapi.Api) error {
+				return c.tracks(ctx, request, op, args[0], args[1:])
+			})
+		},
+	}
+	return c.cmd
+}
+
+// run 创建客户端并检查登录状态后执行fn
+func (c *playlistManageCmd) run(ctx context.Context, fn func(ctx context.Context, request *weapi.Api) error) error {
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	return fn(ctx, request)
+}
+
+func (c *playlistManageCmd) create(ctx context.Context, request *weapi.Api, name string) error {
+	var privacy = "0"
+	if c.private {
+		privacy = "10"
+	}
+	resp, err := request.PlaylistCreate(ctx, &weapi.PlaylistCreateReq{Name: name, Privacy: privacy})
+	if err != nil {
+		return fmt.Errorf("PlaylistCreate: %w", err)
+	}
+	c.cmd.Println(resp.Id)
+	return nil
+}
+
+func (c *playlistManageCmd) remove(ctx context.Context, request *weapi.Api, args []string) error {
+	var ids = make(types.IntsString, 0, len(args))
+	for _, arg := range args {
+		id, err := parsePlaylistId(arg)
+		if err != nil {
+			return fmt.Errorf("parsePlaylistId: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := request.PlaylistRemove(ctx, &weapi.PlaylistRemoveReq{Ids: ids}); err != nil {
+		return fmt.Errorf("PlaylistRemove: %w", err)
+	}
+	c.cmd.Printf("%d playlists removed\n", len(ids))
+	return nil
+}
+
+func (c *playlistManageCmd) rename(ctx context.Context, request *weapi.Api, source, name string) error {
+	id, err := parsePlaylistId(source)
+	if err != nil {
+		return fmt.Errorf("parsePlaylistId: %w", err)
+	}
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("name is empty")
+	}
+	_, err = request.PlaylistUpdateName(ctx, &weapi.PlaylistUpdateNameReq{Id: id, Name: name})
+	if err != nil {
+		return fmt.Errorf("PlaylistUpdateName: %w", err)
+	}
+	c.cmd.Printf("%d renamed to %s\n", id, name)
+	return nil
+}
+
+func (c *playlistManageCmd) tracks(ctx context.Context, request *weapi.Api, op, source string, args []string) error {
+	pid, err := parsePlaylistId(source)
+	if err != nil {
+		return fmt.Errorf("parsePlaylistId: %w", err)
+	}
+	if len(args) <= 0 || (len(args) == 1 && args[0] == "-") {
+		if args, err = readFields(os.Stdin); err != nil {
+			return fmt.Errorf("read stdin: %w", err)
+		}
+	}
+	ids, err := parseSongIds(args)
+	if err != nil {
+		return err
+	}
+	if len(ids) <= 0 {
+		return fmt.Errorf("no song entered")
+	}
+	c.root.root.cacheSongs(completionSongIds(ids)...)
+
+	var failed int
+	for i := 0; i < len(ids); i += playlistTracksBatch {
+		var batch = ids[i:min(i+playlistTracksBatch, len(ids))]
+		resp, err := request.PlaylistAddOrDel(ctx, &weapi.PlaylistAddOrDelReq{Op: op, Pid: pid, TrackIds: batch, Imme: true})
+		if err != nil {
+			failed += len(batch)
+			log.Error("[playlist] %s-tracks %d %v: %s", op, pid, batch, err)
+			c.cmd.PrintErrf("%d songs failed: %s\n", len(batch), err)
+			continue
+		}
+		c.cmd.Printf("%s %d songs, playlist now has %d songs\n", op, len(batch), resp.Count)
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(ids):
+		return fmt.Errorf("all %d songs failed", failed)
+	default:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d songs failed", failed, len(ids))}
+	}
+}
+
+// readFields 读取以空白字符分隔的全部内容,忽略以#开头的行
+func readFields(r io.Reader) ([]string, error) {
+	var (
+		list    []string
+		scanner = bufio.NewScanner(r)
+	)
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list = append(list, strings.Fields(line)...)
+	}
+	return list, scanner.Err()
+}
+
+// parseSongIds 解析歌曲id,支持歌曲id及歌曲分享链接,重复的歌曲只保留一个
+func parseSongIds(args []string) (types.IntsString, error) {
+	var (
+		ids = make(types.IntsString, 0, len(args))
+		set = make(map[int64]struct{}, len(args))
+	)
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			kind, v, err := Parse(arg)
+			if err != nil {
+				return nil, fmt.Errorf("Parse(%s): %w", arg, err)
+			}
+			if kind != "song" {
+				return nil, fmt.Errorf("%s is not a song link", arg)
+			}
+			id = v
+		}
+		if _, ok := set[id]; ok {
+			continue
+		}
+		set[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
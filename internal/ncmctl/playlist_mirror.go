@@ -0,0 +1,359 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/config"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/plan"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// mirrorManifestFile 镜像目录中的同步清单,记录歌单歌曲与本地文件的对应关系
+const mirrorManifestFile = ".ncmctl-mirror.json"
+
+type playlistMirrorCmd struct {
+	root *Playlist
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	mirror config.Mirror // 命令行指定歌单时使用的镜像配置
+	dryRun bool
+}
+
+// mirrorManifest 镜像同步清单,Songs按歌单顺序排列,Orphans为已从歌单移除但未删除本地文件的歌曲
+type mirrorManifest struct {
+	Playlist int64        `json:"playlist"`
+	Name     string       `json:"name"`
+	SyncTime int64        `json:"syncTime"`
+	Songs    []mirrorSong `json:"songs"`
+	Orphans  []mirrorSong `json:"orphans,omitempty"`
+}
+
+type mirrorSong struct {
+	Id     int64  `json:"id"`
+	Name   string `json:"name"`
+	Artist string `json:"artist"`
+	Time   int64  `json:"time"`           // 时长毫秒
+	File   string `json:"file,omitempty"` // 相对镜像目录的文件名,未下载成功时为空
+}
+
+func playlistMirror(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistMirrorCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "mirror [playlist]",
+		Short: "[need login] Mirror playlists to local directories, usually scheduled by daemon",
+		Long: "Mirror playlists to local directories: download songs added to the playlist, optionally delete the\n" +
+			"local files of removed songs, and write a playlist file in the playlist order. Without arguments all\n" +
+			"playlists declared in the mirrors section of the config file are synced.",
+		Example: "  ncmctl playlist mirror -c ./config.yaml\n" +
+			"  ncmctl playlist mirror 19723756 -o ./mirror/hot --prune\n" +
+			"  ncmctl playlist mirror 19723756 -o ./mirror/hot --prune --dry-run",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeArgs(root.root.completePlaylists, cobra.NoFileCompletions),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *playlistMirrorCmd) addFlags() {
+	c.cmd.Flags().StringVarP(&c.mirror.Output, "output", "o", "", "mirror directory, required when the playlist is specified")
+	c.cmd.Flags().StringVarP(&c.mirror.Level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().BoolVar(&c.mirror.Prune, "prune", false, "delete the local files of songs removed from the playlist")
+	c.cmd.Flags().StringVar(&c.mirror.Format, "format", "m3u8", "playlist file format, support: m3u、m3u8, empty to disable")
+	c.cmd.Flags().BoolVar(&c.dryRun, "dry-run", false, "only print the mirror plan")
+}
+
+func (c *playlistMirrorCmd) execute(ctx context.Context, args []string) error {
+	var mirrors = c.root.root.Cfg.Mirrors
+	if len(args) > 0 {
+		if c.mirror.Output == "" {
+			return fmt.Errorf("--output is required when the playlist is specified")
+		}
+		var m = c.mirror
+		m.Playlist = args[0]
+		mirrors = []*config.Mirror{&m}
+	}
+	if len(mirrors) <= 0 {
+		return fmt.Errorf("no playlist to mirror, please specify the playlist or declare mirrors in the config file")
+	}
+	for _, m := range mirrors {
+		switch m.Format {
+		case "", "m3u", "m3u8":
+		default:
+			return fmt.Errorf("format is not support: %s", m.Format)
+		}
+		if m.Output == "" {
+			return fmt.Errorf("mirror %s output is empty", m.Playlist)
+		}
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var (
+		failed int
+		p      plan.Plan
+	)
+	for _, m := range mirrors {
+		if err := c.sync(ctx, request, m, &p); err != nil {
+			failed++
+			c.cmd.PrintErrf("%s: %s\n", m.Playlist, err)
+			log.Error("[mirror] %s sync err: %s", m.Playlist, err)
+		}
+	}
+	if c.dryRun {
+		if err := p.Write(c.cmd.OutOrStdout()); err != nil {
+			return err
+		}
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(mirrors):
+		return fmt.Errorf("all %d playlists failed", failed)
+	default:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d playlists failed", failed, len(mirrors))}
+	}
+}
+
+// sync 对比远程歌单与本地清单,下载新增歌曲、处理已移除的歌曲并更新清单及播放列表,
+// dry-run时只把这些操作记录到p中
+func (c *playlistMirrorCmd) sync(ctx context.Context, request *weapi.Api, m *config.Mirror, p *plan.Plan) error {
+	pid, err := parsePlaylistId(m.Playlist)
+	if err != nil {
+		return fmt.Errorf("parsePlaylistId: %w", err)
+	}
+	output, err := utils.ExpandTilde(m.Output)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if !c.dryRun {
+		if err := utils.MkdirIfNotExist(output, 0755); err != nil {
+			return fmt.Errorf("MkdirIfNotExist: %w", err)
+		}
+	}
+
+	name, songs, err := playlistSongs(ctx, c.root.root, request, pid)
+	if err != nil {
+		return err
+	}
+	manifest, err := loadMirrorManifest(output)
+	if err != nil {
+		return err
+	}
+
+	// 本地已存在的歌曲,文件被手动删除的歌曲重新下载
+	var local = make(map[int64]mirrorSong)
+	for _, s := range append(manifest.Songs, manifest.Orphans...) {
+		if s.File != "" && utils.FileExists(filepath.Join(output, s.File)) {
+			local[s.Id] = s
+		}
+	}
+	var (
+		remote    = make(map[int64]struct{}, len(songs))
+		additions []Music
+	)
+	for _, s := range songs {
+		remote[s.Id] = struct{}{}
+		if _, ok := local[s.Id]; !ok {
+			additions = append(additions, s)
+		}
+	}
+
+	if c.dryRun {
+		c.plan(p, output, name, m, additions, local, remote)
+		return nil
+	}
+
+	var downloadErr error
+	if len(additions) > 0 {
+		d := NewDownload(c.root.root, c.l)
+		d.opts.Output = output
+		d.opts.Level = m.Level
+		d.opts.Report = ""
+		downloadErr = d.run(ctx, func(ctx context.Context, request *weapi.Api) ([]Music, error) {
+			return additions, nil
+		})
+		if d.report != nil {
+			for _, r := range d.report.Songs {
+				if r.Status == downloadOk && r.File != "" {
+					local[r.Id] = mirrorSong{File: filepath.Base(r.File)}
+				}
+			}
+		}
+	}
+
+	var next = mirrorManifest{Playlist: pid, Name: name, SyncTime: time.Now().UnixMilli()}
+	for _, s := range songs {
+		next.Songs = append(next.Songs, mirrorSong{
+			Id:     s.Id,
+			Name:   s.Name,
+			Artist: artistNames(s.Artist),
+			Time:   s.Time,
+			File:   local[s.Id].File,
+		})
+	}
+	var removed int
+	for id, s := range local {
+		if _, ok := remote[id]; ok {
+			continue
+		}
+		removed++
+		if !m.Prune {
+			next.Orphans = append(next.Orphans, s)
+			continue
+		}
+		if err := os.Remove(filepath.Join(output, s.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn("[mirror] remove %s: %s", s.File, err)
+			next.Orphans = append(next.Orphans, s)
+			continue
+		}
+		log.Info("[mirror] %s removed from playlist, delete %s", s.Name, s.File)
+	}
+
+	if err := next.write(output); err != nil {
+		return err
+	}
+	if m.Format != "" {
+		if err := next.playlist(output, m.Format); err != nil {
+			return err
+		}
+	}
+	c.cmd.Printf("%s: %d songs, %d added, %d removed\n", name, len(songs), len(additions), removed)
+	return downloadErr
+}
+
+// plan 记录同步将执行的操作: 下载新增歌曲、--prune 时删除已移除歌曲的本地文件以及重写清单和播放列表
+func (c *playlistMirrorCmd) plan(p *plan.Plan, output, name string, m *config.Mirror, additions []Music, local map[int64]mirrorSong, remote map[int64]struct{}) {
+	for _, s := range additions {
+		p.Add(filepath.Join(output, fmt.Sprintf("%s - %s", s.ArtistString(), s.NameString())), 0)
+	}
+	if m.Prune {
+		var removed []string
+		for id, s := range local {
+			if _, ok := remote[id]; !ok {
+				removed = append(removed, filepath.Join(output, s.File))
+			}
+		}
+		sort.Strings(removed)
+		for _, file := range removed {
+			var size int64
+			if info, err := os.Stat(file); err == nil {
+				size = info.Size()
+			}
+			p.Remove(file, size)
+		}
+	}
+
+	var rewrite = []string{mirrorManifestFile}
+	if m.Format != "" {
+		rewrite = append(rewrite, fmt.Sprintf("%s.%s", utils.Filename(name, "_"), m.Format))
+	}
+	for _, f := range rewrite {
+		var file = filepath.Join(output, f)
+		// 新内容在下载完成后才能确定,因此不显示重写后的大小
+		if utils.FileExists(file) {
+			p.Change(file, 0, 0)
+		} else {
+			p.Add(file, 0)
+		}
+	}
+}
+
+func loadMirrorManifest(dir string) (*mirrorManifest, error) {
+	var manifest mirrorManifest
+	data, err := os.ReadFile(filepath.Join(dir, mirrorManifestFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &manifest, nil
+		}
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return &manifest, nil
+}
+
+// write 先写入临时文件再重命名,避免中断时清单损坏
+func (m *mirrorManifest) write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	var file = filepath.Join(dir, mirrorManifestFile)
+	if err := os.WriteFile(file+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
+// playlist 按歌单顺序生成播放列表,只包含已下载的歌曲,路径相对镜像目录
+func (m *mirrorManifest) playlist(dir, format string) error {
+	var entries = make([]string, 0, len(m.Songs))
+	for _, s := range m.Songs {
+		if s.File == "" {
+			continue
+		}
+		entries = append(entries, fmt.Sprintf("#EXTINF:%d,%s - %s\n%s", s.Time/1000, s.Artist, s.Name, s.File))
+	}
+	file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s.%s", utils.Filename(m.Name, "_"), format)))
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	defer file.Close()
+	return writeM3u(file, entries)
+}
@@ -0,0 +1,251 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/plan"
+
+	"github.com/spf13/cobra"
+)
+
+type playlistSubCmd struct {
+	root *Playlist
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	unsub  bool
+	stale  string // 取消收藏超过该时长未更新的歌单
+	match  string // 取消收藏名称匹配该正则的歌单
+	dryRun bool
+}
+
+func playlistSub(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistSubCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "sub",
+		Short:   "Subscribe playlists",
+		Example: "  ncmctl playlist sub 19723756\n  ncmctl playlist sub 'https://music.163.com/#/playlist?id=19723756'",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	return c.cmd
+}
+
+func playlistUnsub(root *Playlist, l *log.Logger) *cobra.Command {
+	c := &playlistSubCmd{
+		root:  root,
+		l:     l,
+		unsub: true,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "unsub",
+		Short: "Unsubscribe playlists by id or in bulk by filter",
+		Example: "  ncmctl playlist unsub 19723756\n" +
+			"  ncmctl playlist unsub --stale 2y --dry-run\n" +
+			"  ncmctl playlist unsub --match '(?i)demo' --stale 180d",
+		ValidArgsFunction: root.root.completePlaylists,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	c.cmd.Flags().StringVar(&c.stale, "stale", "", "unsubscribe playlists not updated within the duration. support units: h、d、y, eg: 720h、180d、2y")
+	c.cmd.Flags().StringVar(&c.match, "match", "", "unsubscribe playlists whose name matches the regular expression")
+	c.cmd.Flags().BoolVar(&c.dryRun, "dry-run", false, "only print the plan of playlists that would be unsubscribed")
+	return c.cmd
+}
+
+func (c *playlistSubCmd) execute(ctx context.Context, args []string) error {
+	var filter = c.stale != "" || c.match != ""
+	if c.unsub && len(args) > 0 && filter {
+		return fmt.Errorf("playlist ids and filter flags can not be used together")
+	}
+	if c.unsub && len(args) <= 0 && !filter {
+		return fmt.Errorf("please enter playlist ids or use --stale/--match filter")
+	}
+
+	var ids = make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := parsePlaylistId(arg)
+		if err != nil {
+			return fmt.Errorf("parsePlaylistId: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var names = make(map[int64]string)
+	if filter {
+		ids, names, err = c.filter(ctx, request)
+		if err != nil {
+			return err
+		}
+		if len(ids) <= 0 {
+			c.cmd.Println("no playlist matched")
+			return nil
+		}
+	}
+	if c.dryRun {
+		var p plan.Plan
+		for _, id := range ids {
+			var name = strings.TrimSpace(fmt.Sprintf("%d %s", id, names[id]))
+			if c.unsub {
+				p.Remove(name, 0)
+			} else {
+				p.Add(name, 0)
+			}
+		}
+		return p.Write(c.cmd.OutOrStdout())
+	}
+
+	var failed int
+	for _, id := range ids {
+		if err := c.subscribe(ctx, request, id); err != nil {
+			failed++
+			c.cmd.PrintErrf("%d: %s\n", id, err)
+			log.Error("[playlist] %d: %s", id, err)
+			continue
+		}
+		c.cmd.Printf("%s: ok\n", strings.TrimSpace(fmt.Sprintf("%d %s", id, names[id])))
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(ids):
+		return fmt.Errorf("all %d playlists failed", failed)
+	default:
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d playlists failed", failed, len(ids))}
+	}
+}
+
+func (c *playlistSubCmd) subscribe(ctx context.Context, request *weapi.Api, id int64) error {
+	if c.unsub {
+		if _, err := request.PlaylistUnsubscribe(ctx, &weapi.PlaylistUnsubscribeReq{Id: strconv.FormatInt(id, 10)}); err != nil {
+			return fmt.Errorf("PlaylistUnsubscribe: %w", err)
+		}
+		return nil
+	}
+	if _, err := request.PlaylistSubscribe(ctx, &weapi.PlaylistSubscribeReq{Id: strconv.FormatInt(id, 10)}); err != nil {
+		return fmt.Errorf("PlaylistSubscribe: %w", err)
+	}
+	return nil
+}
+
+// filter 从收藏的歌单中筛选需要取消收藏的歌单,多个条件同时满足时才会命中
+// filter 返回符合过滤条件的歌单id以及id对应的歌单名称
+func (c *playlistSubCmd) filter(ctx context.Context, request *weapi.Api) ([]int64, map[int64]string, error) {
+	var (
+		before time.Time
+		reg    *regexp.Regexp
+	)
+	if c.stale != "" {
+		age, err := parseAge(c.stale)
+		if err != nil {
+			return nil, nil, fmt.Errorf("stale: %w", err)
+		}
+		before = time.Now().Add(-age)
+	}
+	if c.match != "" {
+		var err error
+		if reg, err = regexp.Compile(c.match); err != nil {
+			return nil, nil, fmt.Errorf("regexp.Compile: %w", err)
+		}
+	}
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return nil, nil, fmt.Errorf("GetUserInfo: account is empty")
+	}
+	list, err := userPlaylists(ctx, request, user.Account.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.root.root.cachePlaylists(list)
+
+	var (
+		ids   []int64
+		names = make(map[int64]string)
+	)
+	for _, v := range list {
+		// 自己创建的歌单不能取消收藏
+		if v.UserId == user.Account.Id {
+			continue
+		}
+		var updated = playlistUpdateTime(v)
+		if !before.IsZero() && updated.After(before) {
+			continue
+		}
+		if reg != nil && !reg.MatchString(v.Name) {
+			continue
+		}
+		ids = append(ids, v.Id)
+		names[v.Id] = fmt.Sprintf("%s %s", updated.Format("2006-01-02"), v.Name)
+	}
+	return ids, names, nil
+}
+
+// parseAge 解析时长,在time.ParseDuration基础上增加d(天)、y(年)单位
+func parseAge(s string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "y"):
+		unit = 365 * 24 * time.Hour
+	default:
+		return time.ParseDuration(s)
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s[:len(s)-1]), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,217 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+var radioReg = regexp.MustCompile(`/(?:dj)?radio\?id=(\d+)`)
+
+type Podcast struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewPodcast(root *Root, l *log.Logger) *Podcast {
+	c := &Podcast{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "podcast",
+			Short: "List subscribed podcasts(dj radio) and download episodes",
+			Example: "  ncmctl podcast list\n" +
+				"  ncmctl podcast episodes 336355127\n" +
+				"  ncmctl podcast download 'https://music.163.com/#/djradio?id=336355127' -n 10",
+		},
+	}
+	c.addFlags()
+	c.Add(podcastList(c, l))
+	c.Add(podcastEpisodes(c, l))
+	c.Add(podcastDownload(c, l))
+	return c
+}
+
+func (c *Podcast) addFlags() {}
+
+func (c *Podcast) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Podcast) Command() *cobra.Command {
+	return c.cmd
+}
+
+type podcastListCmd struct {
+	root *Podcast
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func podcastList(root *Podcast, l *log.Logger) *cobra.Command {
+	c := &podcastListCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "list",
+		Short:   "[need login] List subscribed podcasts",
+		Example: "  ncmctl podcast list",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	return c.cmd
+}
+
+func (c *podcastListCmd) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	resp, err := request.DjRadioSub(ctx, &weapi.DjRadioSub{TargetUserId: fmt.Sprintf("%d", user.Account.Id)})
+	if err != nil {
+		return fmt.Errorf("DjRadioSub: %w", err)
+	}
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tDJ\tEPISODES\tLAST UPDATE")
+	for _, v := range resp.DjRadios {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", v.Id, v.Name, v.Dj.Nickname, v.ProgramCount, time.UnixMilli(v.LastProgramCreateTime).Format(time.DateOnly))
+	}
+	return w.Flush()
+}
+
+type podcastEpisodesCmd struct {
+	root *Podcast
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	limit int64 // 获取的节目数量,0表示全部
+	asc   bool  // 按发布时间升序
+}
+
+func podcastEpisodes(root *Podcast, l *log.Logger) *cobra.Command {
+	c := &podcastEpisodesCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "episodes <radioId>",
+		Short: "List episodes of a podcast",
+		Example: "  ncmctl podcast episodes 336355127\n" +
+			"  ncmctl podcast episodes 336355127 -n 0 --asc",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args[0])
+		},
+	}
+	c.cmd.Flags().Int64VarP(&c.limit, "limit", "n", 30, "number of episodes to list, 0 means all")
+	c.cmd.Flags().BoolVar(&c.asc, "asc", false, "sort by publish time ascending")
+	return c.cmd
+}
+
+func (c *podcastEpisodesCmd) execute(ctx context.Context, source string) error {
+	rid, err := parseRadioId(source)
+	if err != nil {
+		return err
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	programs, err := radioPrograms(ctx, request, rid, c.limit, c.asc)
+	if err != nil {
+		return err
+	}
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NO.\tID\tNAME\tPUBLISHED\tDURATION")
+	for _, v := range programs {
+		var d = time.Duration(v.Duration) * time.Millisecond
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%02d:%02d\n", v.SerialNum, v.Id, v.Name, time.UnixMilli(v.CreateTime).Format(time.DateOnly), int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return w.Flush()
+}
+
+// parseRadioId 解析电台id或电台链接
+func parseRadioId(source string) (int64, error) {
+	if id, err := strconv.ParseInt(source, 10, 64); err == nil {
+		return id, nil
+	}
+	matched := radioReg.FindStringSubmatch(source)
+	if len(matched) < 2 {
+		return 0, fmt.Errorf("could not parse the radio: %s", source)
+	}
+	return strconv.ParseInt(matched[1], 10, 64)
+}
+
+// radioPrograms 分页获取电台节目,limit为0时获取全部节目
+func radioPrograms(ctx context.Context, request *weapi.Api, rid, limit int64, asc bool) ([]weapi.DjProgramRespPrograms, error) {
+	const pageSize = 100
+	var list []weapi.DjProgramRespPrograms
+	for {
+		var size int64 = pageSize
+		if limit > 0 {
+			size = min(pageSize, limit-int64(len(list)))
+		}
+		resp, err := request.DjProgram(ctx, &weapi.DjProgramReq{RadioId: fmt.Sprintf("%d", rid), Limit: size, Offset: int64(len(list)), Asc: asc})
+		if err != nil {
+			return nil, fmt.Errorf("DjProgram: %w", err)
+		}
+		list = append(list, resp.Programs...)
+		if !resp.More || len(resp.Programs) <= 0 || (limit > 0 && int64(len(list)) >= limit) {
+			break
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,136 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+type podcastDownloadCmd struct {
+	root *Podcast
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	limit    int64 // 下载最新的节目数量,0表示全部
+	output   string
+	level    string
+	parallel int64
+}
+
+func podcastDownload(root *Podcast, l *log.Logger) *cobra.Command {
+	c := &podcastDownloadCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "download <radioId>",
+		Short: "[need login] Download episodes of a podcast",
+		Long: "Download episodes of a podcast into <output>/<podcast name>, files are named by episode number\n" +
+			"eg: 012 - episode name.mp3, the episode number, podcast name and publish date are written into tags.",
+		Example: "  ncmctl podcast download 336355127\n" +
+			"  ncmctl podcast download 336355127 -n 10 -o ./podcast",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args[0])
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *podcastDownloadCmd) addFlags() {
+	c.cmd.Flags().Int64VarP(&c.limit, "limit", "n", 0, "download the latest n episodes, 0 means all")
+	c.cmd.Flags().StringVarP(&c.output, "output", "o", "./podcast", "episode file output path")
+	c.cmd.Flags().StringVarP(&c.level, "level", "l", string(types.LevelExhigh), "episode quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ")
+	c.cmd.Flags().Int64VarP(&c.parallel, "parallel", "p", 3, "concurrent download count")
+}
+
+func (c *podcastDownloadCmd) execute(ctx context.Context, source string) error {
+	rid, err := parseRadioId(source)
+	if err != nil {
+		return err
+	}
+
+	d := NewDownload(c.root.root, c.l)
+	d.opts.Output = c.output
+	d.opts.Level = c.level
+	d.opts.Parallel = c.parallel
+	return d.run(ctx, func(ctx context.Context, request *weapi.Api) ([]Music, error) {
+		detail, err := request.DjRadioDetail(ctx, &weapi.DjRadioDetailReq{Id: fmt.Sprintf("%d", rid)})
+		if err != nil {
+			return nil, fmt.Errorf("DjRadioDetail: %w", err)
+		}
+		var radio = detail.Data
+
+		// 每个电台单独一个目录,目录名依赖电台详情因此在获取节目时创建
+		d.opts.Output = filepath.Join(c.output, utils.Filename(radio.Name, "_"))
+		if err := utils.MkdirIfNotExist(d.opts.Output, 0755); err != nil {
+			return nil, fmt.Errorf("MkdirIfNotExist: %w", err)
+		}
+
+		programs, err := radioPrograms(ctx, request, rid, c.limit, false)
+		if err != nil {
+			return nil, err
+		}
+		c.cmd.Printf("%s: %d episodes\n", radio.Name, len(programs))
+		return podcastMusic(radio, programs), nil
+	})
+}
+
+// podcastMusic 将节目转换为下载的歌曲,文件名以期数开头并按最大期数补齐位数便于排序
+func podcastMusic(radio weapi.DjRadioDetailRespData, programs []weapi.DjProgramRespPrograms) []Music {
+	var width = len(fmt.Sprintf("%d", radio.ProgramCount))
+	for _, p := range programs {
+		width = max(width, len(fmt.Sprintf("%d", p.SerialNum)))
+	}
+	width = max(width, 3)
+
+	var list = make([]Music, 0, len(programs))
+	for _, p := range programs {
+		var cover = p.CoverUrl
+		if cover == "" {
+			cover = radio.PicUrl
+		}
+		list = append(list, Music{
+			Id:          p.MainSong.Id,
+			Name:        p.Name,
+			Artist:      []types.Artist{{Id: radio.Dj.UserId, Name: radio.Dj.Nickname}},
+			Album:       types.Album{Id: radio.Id, Name: radio.Name, PicUrl: cover},
+			Time:        p.Duration,
+			Track:       p.SerialNum,
+			PublishTime: p.CreateTime,
+			Filename:    fmt.Sprintf("%0*d - %s", width, p.SerialNum, utils.Filename(p.Name, "_")),
+		})
+	}
+	return list
+}
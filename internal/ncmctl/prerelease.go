@@ -0,0 +1,292 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/database"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type PreReleaseOpts struct {
+	Download bool   // 发行后自动下载
+	Output   string // 下载输出目录
+	Level    string // 下载歌曲品质
+	Albums   int64  // 每个歌手检查最近的专辑数量
+}
+
+type PreRelease struct {
+	root *Root
+	cmd  *cobra.Command
+	opts PreReleaseOpts
+	l    *log.Logger
+}
+
+// preReleaseAlbum 跟踪中的预售专辑
+type preReleaseAlbum struct {
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	Artist      string `json:"artist"`
+	PublishTime int64  `json:"publishTime"` // 发行时间毫秒
+	Released    bool   `json:"released"`    // 是否已发行通知
+	Downloaded  bool   `json:"downloaded"`  // 是否已下载
+}
+
+func NewPreRelease(root *Root, l *log.Logger) *PreRelease {
+	c := &PreRelease{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "prerelease",
+			Short: "[need login] Watch pre-release albums of followed artists and download them on release day",
+			Example: "  ncmctl prerelease\n" +
+				"  ncmctl prerelease --download -l SQ -o ./download",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *PreRelease) addFlags() {
+	c.cmd.Flags().BoolVar(&c.opts.Download, "download", false, "download the tracked albums once released")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./download", "music file output path")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().Int64Var(&c.opts.Albums, "albums", 5, "number of latest albums checked for each artist")
+}
+
+func (c *PreRelease) validate() error {
+	if c.opts.Albums <= 0 || c.opts.Albums > 100 {
+		return fmt.Errorf("albums must be range 1-100")
+	}
+	return nil
+}
+
+func (c *PreRelease) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *PreRelease) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *PreRelease) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	var uid = fmt.Sprintf("%d", user.Account.Id)
+
+	db, err := database.New(c.root.Cfg.Database)
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	defer db.Close(ctx)
+
+	tracked, err := c.load(ctx, db, uid)
+	if err != nil {
+		return err
+	}
+
+	// 发现新的预售专辑
+	found, err := c.scan(ctx, request)
+	if err != nil {
+		return err
+	}
+	for _, v := range found {
+		if _, ok := tracked[v.Id]; ok {
+			continue
+		}
+		tracked[v.Id] = v
+		log.Info("[prerelease] new album %s - %s publish at %s", v.Artist, v.Name, time.UnixMilli(v.PublishTime))
+		c.root.notify(ctx, "预售专辑", fmt.Sprintf("%s - %s 将于 %s 发行", v.Artist, v.Name, time.UnixMilli(v.PublishTime).Format(time.DateTime)))
+	}
+
+	// 处理已到发行时间的专辑
+	var (
+		now    = time.Now().UnixMilli()
+		failed int
+	)
+	for _, v := range tracked {
+		if v.PublishTime > now {
+			continue
+		}
+		if !v.Released {
+			v.Released = true
+			c.root.notify(ctx, "专辑已发行", fmt.Sprintf("%s - %s 已发行", v.Artist, v.Name))
+		}
+		if !c.opts.Download || v.Downloaded {
+			continue
+		}
+		d := NewDownload(c.root, c.l)
+		d.opts.Output = c.opts.Output
+		d.opts.Level = c.opts.Level
+		if err := d.execute(ctx, []string{fmt.Sprintf("https://music.163.com/album?id=%d", v.Id)}); err != nil {
+			failed++
+			log.Error("[prerelease] download album %d: %s", v.Id, err)
+			continue
+		}
+		v.Downloaded = true
+		c.root.notify(ctx, "专辑已下载", fmt.Sprintf("%s - %s 已下载到 %s", v.Artist, v.Name, c.opts.Output))
+	}
+
+	if err := c.save(ctx, db, uid, tracked); err != nil {
+		return err
+	}
+	if err := c.table(tracked); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d albums download failed", failed)}
+	}
+	return nil
+}
+
+// scan 获取关注歌手最近专辑中发行时间在未来的专辑
+func (c *PreRelease) scan(ctx context.Context, request *weapi.Api) ([]*preReleaseAlbum, error) {
+	var (
+		list []*preReleaseAlbum
+		now  = time.Now().UnixMilli()
+	)
+	for offset := int64(0); ; {
+		artists, err := request.ArtistSublist(ctx, &weapi.ArtistSublistReq{Limit: 100, Offset: offset, Total: true})
+		if err != nil {
+			return nil, fmt.Errorf("ArtistSublist: %w", err)
+		}
+		for _, ar := range artists.Data {
+			albums, err := request.ArtistAlbums(ctx, &weapi.ArtistAlbumsReq{Id: ar.Id, Limit: c.opts.Albums})
+			if err != nil {
+				if _, ok := api.AsError(err); !ok {
+					return nil, fmt.Errorf("ArtistAlbums(%d): %w", ar.Id, err)
+				}
+				log.Warn("[prerelease] ArtistAlbums(%d): %s", ar.Id, err)
+				continue
+			}
+			for _, al := range albums.HotAlbums {
+				if al.PublishTime <= now {
+					continue
+				}
+				list = append(list, &preReleaseAlbum{
+					Id:          al.Id,
+					Name:        al.Name,
+					Artist:      ar.Name,
+					PublishTime: al.PublishTime,
+				})
+			}
+		}
+		offset += int64(len(artists.Data))
+		if !artists.HasMore || len(artists.Data) <= 0 {
+			return list, nil
+		}
+	}
+}
+
+func (c *PreRelease) load(ctx context.Context, db database.Database, uid string) (map[int64]*preReleaseAlbum, error) {
+	var tracked = make(map[int64]*preReleaseAlbum)
+	value, err := db.Get(ctx, preReleaseKey(uid))
+	if err != nil {
+		if strings.Contains(err.Error(), "Key not found") {
+			return tracked, nil
+		}
+		return nil, fmt.Errorf("get tracked albums: %w", err)
+	}
+	var list []*preReleaseAlbum
+	if err := json.Unmarshal([]byte(value), &list); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	for _, v := range list {
+		tracked[v.Id] = v
+	}
+	return tracked, nil
+}
+
+// save 保存跟踪状态,已下载或发行超过30天的专辑不再跟踪
+func (c *PreRelease) save(ctx context.Context, db database.Database, uid string, tracked map[int64]*preReleaseAlbum) error {
+	var (
+		list   = make([]*preReleaseAlbum, 0, len(tracked))
+		expire = time.Now().AddDate(0, 0, -30).UnixMilli()
+	)
+	for _, v := range tracked {
+		if v.Downloaded || v.PublishTime < expire {
+			continue
+		}
+		list = append(list, v)
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := db.Set(ctx, preReleaseKey(uid), string(data)); err != nil {
+		return fmt.Errorf("set tracked albums: %w", err)
+	}
+	return nil
+}
+
+func (c *PreRelease) table(tracked map[int64]*preReleaseAlbum) error {
+	var list = make([]*preReleaseAlbum, 0, len(tracked))
+	for _, v := range tracked {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].PublishTime < list[j].PublishTime })
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPUBLISH\tRELEASED\tDOWNLOADED\tARTIST\tNAME")
+	for _, v := range list {
+		fmt.Fprintf(w, "%d\t%s\t%v\t%v\t%s\t%s\n", v.Id, time.UnixMilli(v.PublishTime).Format(time.DateTime), v.Released, v.Downloaded, v.Artist, v.Name)
+	}
+	return w.Flush()
+}
+
+func preReleaseKey(uid string) string {
+	return fmt.Sprintf("prerelease:albums:%v", uid)
+}
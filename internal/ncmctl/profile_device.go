@@ -0,0 +1,111 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type profileDeviceCmd struct {
+	root *Profile
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	rotate bool   // 重新生成设备指纹
+	os     string // 重新生成时使用的设备系统
+}
+
+func profileDevice(root *Profile, l *log.Logger) *cobra.Command {
+	c := &profileDeviceCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "device",
+		Short: "Show or rotate the device fingerprint of current profile",
+		Example: "  ncmctl profile device\n" +
+			"  ncmctl profile device --rotate\n" +
+			"  ncmctl profile device --rotate --os android\n" +
+			"  ncmctl profile device --profile work",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *profileDeviceCmd) addFlags() {
+	c.cmd.Flags().BoolVar(&c.rotate, "rotate", false, "generate a new device fingerprint and replace the saved one")
+	c.cmd.Flags().StringVar(&c.os, "os", "", "device os used by --rotate, pc、osx、android、iphone. empty means keep config")
+}
+
+func (c *profileDeviceCmd) execute(ctx context.Context) error {
+	var cfg = *c.root.root.Cfg.Network
+	if cfg.Device.Filepath == "" {
+		return fmt.Errorf("device fingerprint is disabled, set network.device.filepath in config")
+	}
+	if c.os != "" {
+		if !c.rotate {
+			return fmt.Errorf("--os must be used with --rotate")
+		}
+		cfg.Device.Os = c.os
+		if err := cfg.Device.Validate(); err != nil {
+			return err
+		}
+	}
+
+	cli, err := api.NewClient(&cfg, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+
+	var device = cli.Device()
+	if c.rotate {
+		if device, err = cli.RotateDevice(); err != nil {
+			return fmt.Errorf("RotateDevice: %w", err)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "FILE\t%s\n", cfg.Device.Filepath)
+	_, _ = fmt.Fprintf(w, "CREATED\t%s\n", device.CreateTime.Format("2006-01-02 15:04:05"))
+	_, _ = fmt.Fprintf(w, "USER-AGENT\t%s\n", device.UserAgent)
+	for _, cookie := range device.Cookies() {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", cookie.Name, cookie.Value)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("Flush: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultProfile 未指定 --profile 时使用的账号名称
+const defaultProfile = "default"
+
+// profileWarnBefore cookie或vip在该时长内过期时提示
+const profileWarnBefore = 7 * 24 * time.Hour
+
+const (
+	healthOk   = "ok"
+	healthWarn = "warn"
+	healthBad  = "bad"
+)
+
+// healthColors 终端输出颜色,所有颜色码长度一致以免影响表格对齐
+var healthColors = map[string]string{
+	healthOk:   "\033[32m",
+	healthWarn: "\033[33m",
+	healthBad:  "\033[31m",
+}
+
+type profileStatusCmd struct {
+	root *Profile
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	noColor bool // 不使用颜色输出
+}
+
+// profileHealth 单个账号的检查结果
+type profileHealth struct {
+	Name      string
+	Nickname  string
+	Health    string
+	Cookie    time.Time // 登录cookie过期时间,零值表示会话cookie
+	VipExpire time.Time // 黑胶vip过期时间,零值表示非vip
+	Detail    string    // 异常说明
+	Action    string    // 建议操作
+}
+
+func profileStatus(root *Profile, l *log.Logger) *cobra.Command {
+	c := &profileStatusCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "status",
+		Short: "Concurrently check cookie validity, vip expiry and risk control state of all profiles",
+		Example: "  ncmctl profile status\n" +
+			"  ncmctl profile status --no-color",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *profileStatusCmd) addFlags() {
+	c.cmd.Flags().BoolVar(&c.noColor, "no-color", false, "disable colored output, also disabled when stdout is not a terminal or NO_COLOR is set")
+}
+
+func (c *profileStatusCmd) execute(ctx context.Context) error {
+	profiles, err := c.root.root.profiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles) <= 0 {
+		c.cmd.Println("no profile found, login first")
+		return nil
+	}
+
+	var (
+		names  = make([]string, 0, len(profiles))
+		result = make([]profileHealth, len(profiles))
+		wg     sync.WaitGroup
+	)
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result[i] = c.check(ctx, name, profiles[name])
+		}()
+	}
+	wg.Wait()
+
+	var (
+		color = !c.noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+		w     = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		bad   int
+	)
+	_, _ = fmt.Fprintln(w, "PROFILE\tHEALTH\tNICKNAME\tCOOKIE EXPIRES\tVIP EXPIRES\tDETAIL\tACTION")
+	for _, r := range result {
+		var health = r.Health
+		if color {
+			health = healthColors[r.Health] + r.Health + "\033[0m"
+		}
+		if r.Health == healthBad {
+			bad++
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, health, utils.Ternary(r.Nickname != "", r.Nickname, "-"),
+			formatExpire(r.Cookie, "session"), formatExpire(r.VipExpire, "-"), utils.Ternary(r.Detail != "", r.Detail, "-"), utils.Ternary(r.Action != "", r.Action, "-"))
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("Flush: %w", err)
+	}
+	if bad > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d profiles unhealthy", bad)}
+	}
+	return nil
+}
+
+// check 检查单个账号,每个账号使用独立的客户端及cookie
+func (c *profileStatusCmd) check(ctx context.Context, name, cookie string) profileHealth {
+	var (
+		result = profileHealth{Name: name, Health: healthOk}
+		login  = fmt.Sprintf("ncmctl login --profile %s", name)
+		cfg    = *c.root.root.Cfg.Network
+	)
+	if name == defaultProfile {
+		login = "ncmctl login"
+	}
+	cfg.Cookie.Filepath = cookie
+	if cfg.Device.Filepath != "" {
+		cfg.Device.Filepath = utils.Ternary(name == defaultProfile, c.root.root.defaultDevice, profileDevicePath(c.root.root.dirs, name))
+	}
+
+	cli, err := api.NewClient(&cfg, c.l)
+	if err != nil {
+		result.Health, result.Detail = healthBad, err.Error()
+		return result
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	expires, ok := loginExpires(cli)
+	if !ok {
+		result.Health, result.Detail, result.Action = healthBad, "not logged in", login
+		return result
+	}
+	result.Cookie = expires
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	switch {
+	case errors.Is(err, types.ErrRiskControl):
+		result.Health, result.Detail, result.Action = healthBad, err.Error(), "verify in the official app or change network, then retry later"
+		return result
+	case err != nil:
+		result.Health, result.Detail, result.Action = healthBad, err.Error(), "retry later"
+		return result
+	case user.Account == nil:
+		result.Health, result.Detail, result.Action = healthBad, "cookie expired", login
+		return result
+	}
+	if user.Profile != nil {
+		result.Nickname = user.Profile.Nickname
+	}
+	if user.Account.Ban != 0 {
+		result.Health, result.Detail, result.Action = healthBad, fmt.Sprintf("account banned(%d)", user.Account.Ban), "check account state in the official app"
+		return result
+	}
+
+	vip, err := request.VipInfo(ctx, &weapi.VipInfoReq{})
+	if err != nil {
+		log.Warn("[profile] %s VipInfo: %s", name, err)
+	} else if vip.Data.Associator.ExpireTime > 0 {
+		result.VipExpire = time.UnixMilli(vip.Data.Associator.ExpireTime)
+	}
+
+	switch {
+	case !expires.IsZero() && time.Until(expires) < profileWarnBefore:
+		result.Health, result.Detail, result.Action = healthWarn, "cookie expires soon", fmt.Sprintf("ncmctl keepalive%s", utils.Ternary(name == defaultProfile, "", " --profile "+name))
+	case !result.VipExpire.IsZero() && time.Until(result.VipExpire) < 0:
+		result.Health, result.Detail, result.Action = healthWarn, "vip expired", "renew vip to download lossless songs"
+	case !result.VipExpire.IsZero() && time.Until(result.VipExpire) < profileWarnBefore:
+		result.Health, result.Detail, result.Action = healthWarn, "vip expires soon", "renew vip"
+	}
+	return result
+}
+
+// formatExpire 格式化过期时间,零值返回zero
+func formatExpire(t time.Time, zero string) string {
+	if t.IsZero() {
+		return zero
+	}
+	if d := time.Until(t); d > 0 {
+		return fmt.Sprintf("%s(%dd)", t.Format(time.DateOnly), int(d.Hours()/24))
+	}
+	return t.Format(time.DateOnly) + "(expired)"
+}
+
+// isTerminal 判断是否输出到终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,173 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/cheggaaa/pb/v3"
+)
+
+// 下载进度显示方式,通过 --progress 指定
+const (
+	progressAuto  = "auto"  // 标准错误为终端时使用bar,否则使用plain
+	progressBar   = "bar"   // 终端进度条
+	progressPlain = "plain" // 定时输出一行汇总进度,不包含终端控制字符,适用于CI及重定向到文件
+	progressNone  = "none"  // 不显示进度
+)
+
+// plainInterval plain模式下输出汇总进度的间隔
+const plainInterval = 5 * time.Second
+
+func validProgress(mode string) error {
+	switch mode {
+	case progressAuto, progressBar, progressPlain, progressNone:
+		return nil
+	}
+	return fmt.Errorf("progress mode is not support: %s", mode)
+}
+
+// progressMode 返回实际使用的进度显示方式
+func progressMode(mode string) string {
+	switch mode {
+	case progressAuto:
+		if consoleBars() {
+			return progressBar
+		}
+		return progressPlain
+	case progressBar:
+		// Windows下没有控制台时无法定位光标,强制使用进度条会导致程序崩溃
+		if runtime.GOOS == "windows" && !consoleBars() {
+			return progressPlain
+		}
+	}
+	return mode
+}
+
+// startPool 创建进度条池,进度条统一输出到标准错误,避免与标准输出中的命令结果混在一起
+func startPool() (*pb.Pool, error) {
+	var pool = pb.NewPool()
+	pool.Output = os.Stderr
+	if err := pool.Start(); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// batchBarTemplate 汇总进度条模板,例如: 12/240 files · 1.20 GiB/4.80 GiB · 6.10 MiB/s
+const batchBarTemplate = `{{string . "files"}} · {{counters . "%s/%s"}} · {{speed . "%s/s" "-"}}{{string . "state"}}`
+
+// batchBar 批量下载的汇总进度条,显示在各歌曲进度条上方。
+// 歌曲大小在获取下载地址后才能确定,因此总大小随下载进度逐步增加。
+type batchBar struct {
+	bar   *pb.ProgressBar
+	total int
+	done  atomic.Int64
+}
+
+func newBatchBar(total int) *batchBar {
+	var b = &batchBar{
+		total: total,
+		bar:   pb.New64(0).Set(pb.Bytes, true).SetTemplateString(batchBarTemplate),
+	}
+	b.bar.Set("files", b.files())
+	return b
+}
+
+func (b *batchBar) files() string {
+	return fmt.Sprintf("%d/%d files", b.done.Load(), b.total)
+}
+
+// writer 统计写入w的字节数,size为歌曲文件大小
+func (b *batchBar) writer(w io.Writer, size int64) io.Writer {
+	if b == nil {
+		return w
+	}
+	b.bar.AddTotal(size)
+	return &batchWriter{w: w, bar: b.bar}
+}
+
+// state 显示下载队列状态,比如暂停
+func (b *batchBar) state(s string) {
+	if s != "" {
+		s = " · " + s
+	}
+	b.bar.Set("state", s)
+}
+
+// step 完成一首歌曲,包括下载失败及跳过的歌曲
+func (b *batchBar) step() {
+	if b == nil {
+		return
+	}
+	b.done.Add(1)
+	b.bar.Set("files", b.files())
+}
+
+// print 每隔interval向w输出一行汇总进度,返回的函数停止输出并打印最终进度
+func (b *batchBar) print(w io.Writer, interval time.Duration) (stop func()) {
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				_, _ = fmt.Fprintln(w, b.bar.String())
+				return
+			case <-ticker.C:
+				_, _ = fmt.Fprintln(w, b.bar.String())
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+type batchWriter struct {
+	w   io.Writer
+	bar *pb.ProgressBar
+}
+
+func (w *batchWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.bar.Add(n)
+	return n, err
+}
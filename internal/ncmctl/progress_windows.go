@@ -0,0 +1,54 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+//go:build windows
+
+package ncmctl
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// consoleBars 判断能否显示终端进度条。Windows下进度条通过控制台API定位光标,
+// 标准输出及标准错误都需要是控制台,否则(如mintty、重定向输出)会定位失败。
+// 同时尝试开启虚拟终端处理,使旧版控制台也能正确处理日志等输出中的ANSI控制字符。
+func consoleBars() bool {
+	var ok = true
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		var (
+			handle = windows.Handle(f.Fd())
+			mode   uint32
+		)
+		if err := windows.GetConsoleMode(handle, &mode); err != nil {
+			ok = false
+			continue
+		}
+		if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING == 0 {
+			// Windows 10 之前的控制台不支持,忽略错误
+			_ = windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+		}
+	}
+	return ok
+}
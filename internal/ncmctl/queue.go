@@ -0,0 +1,432 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"golang.org/x/term"
+)
+
+// downloadStateFile 下载中断时保存未完成歌曲的文件,位于输出目录下,通过 --resume 继续下载
+const downloadStateFile = ".ncmctl-download.json"
+
+// queue 可暂停、取消的下载队列,暂停及取消只控制后续歌曲是否开始下载,
+// 正在下载的歌曲可以通过序号单独取消
+type queue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	paused   bool
+	canceled bool
+	jobs     map[int]queueJob // 正在下载的歌曲,key为歌曲序号
+}
+
+// queueJob 正在下载的歌曲
+type queueJob struct {
+	name   string
+	cancel context.CancelFunc
+}
+
+func newQueue() *queue {
+	var q = &queue{jobs: make(map[int]queueJob)}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// wait 暂停时阻塞直到恢复或取消,返回false表示队列已取消
+func (q *queue) wait() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.paused && !q.canceled {
+		q.cond.Wait()
+	}
+	return !q.canceled
+}
+
+// toggle 暂停或恢复队列,返回是否处于暂停状态
+func (q *queue) toggle() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.paused = !q.paused
+	q.cond.Broadcast()
+	return q.paused
+}
+
+// pause 暂停队列,返回false表示之前已经暂停
+func (q *queue) pause() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.paused {
+		return false
+	}
+	q.paused = true
+	return true
+}
+
+// isPaused 队列是否处于暂停状态
+func (q *queue) isPaused() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.paused
+}
+
+// cancel 取消队列,返回false表示之前已经取消
+func (q *queue) cancel() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.canceled {
+		return false
+	}
+	q.canceled = true
+	q.cond.Broadcast()
+	return true
+}
+
+// start 登记开始下载的歌曲,n为歌曲序号,cancel用于单独取消该歌曲
+func (q *queue) start(n int, name string, cancel context.CancelFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs[n] = queueJob{name: name, cancel: cancel}
+}
+
+// finish 歌曲下载结束
+func (q *queue) finish(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.jobs, n)
+}
+
+// cancelJob 取消序号为n的歌曲,返回歌曲名称,歌曲不在下载中时返回false
+func (q *queue) cancelJob(n int) (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[n]
+	if !ok {
+		return "", false
+	}
+	job.cancel()
+	delete(q.jobs, n)
+	return job.name, true
+}
+
+type jobNumberKey struct{}
+
+// withJobNumber 记录歌曲在下载队列中的序号,显示在进度条中用于单独取消
+func withJobNumber(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, jobNumberKey{}, n)
+}
+
+// jobNumberFrom 获取context中的歌曲序号,不存在时返回0
+func jobNumberFrom(ctx context.Context) int {
+	n, _ := ctx.Value(jobNumberKey{}).(int)
+	return n
+}
+
+// interactive 是否由用户在终端直接执行下载命令,被其他命令调用或daemon调度时不处理信号及快捷键
+func (c *Download) interactive(ctx context.Context) bool {
+	return c.cmd.CalledAs() != "" && jobStatsFrom(ctx) == nil
+}
+
+// interrupter 返回中断处理函数。队列运行时中断会暂停下载后续歌曲并通过save保存未完成的歌曲,
+// 暂停期间再次中断则取消队列并中止正在下载的歌曲。keys表示是否可以通过快捷键恢复下载。
+func (c *Download) interrupter(q *queue, abort context.CancelFunc, save func() error, keys bool) func() {
+	return func() {
+		if !q.pause() {
+			q.cancel()
+			c.status("aborting")
+			abort()
+			return
+		}
+		if err := save(); err != nil {
+			log.Warn("save download state err: %s", err)
+		}
+		if keys {
+			c.status("paused, progress saved: press p to resume, ctrl+c again to abort")
+		} else {
+			c.status("paused, progress saved: press ctrl+c again to abort")
+		}
+	}
+}
+
+// watchInterrupt 收到中断信号时调用interrupt,返回的函数停止监听信号
+func (c *Download) watchInterrupt(interrupt func()) (stop func()) {
+	var (
+		sig  = make(chan os.Signal, 2)
+		done = make(chan struct{})
+	)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sig:
+				interrupt()
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
+
+// watchKeys 把终端切换为raw模式处理下载快捷键,无需回车: p 暂停或恢复下载,
+// 输入进度条中的歌曲序号后按 c 取消该歌曲,esc 清除输入的序号。
+// raw模式下ctrl+c不再产生中断信号,因此同样交给interrupt处理。返回的函数恢复终端模式。
+func (c *Download) watchKeys(q *queue, interrupt func()) (stop func()) {
+	var fd = int(os.Stdin.Fd())
+	old, err := term.MakeRaw(fd)
+	if err != nil {
+		log.Warn("MakeRaw: %s", err)
+		return func() {}
+	}
+	rawMode.Store(true)
+
+	var (
+		done = make(chan struct{})
+		// show 显示快捷键提示,暂停期间保留暂停状态
+		show = func(s string) {
+			if q.isPaused() {
+				s = strings.TrimSuffix("paused · "+s, " · ")
+			}
+			c.status(s)
+		}
+		selected string
+	)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case chunk, ok := <-stdinReader():
+				if !ok {
+					return
+				}
+				for i, b := range chunk {
+					if b == keyInterrupt {
+						interrupt()
+						continue
+					}
+					// 交互提示(如安全验证)等待输入时,其余输入交给提示处理
+					if prompting.Load() > 0 {
+						select {
+						case answers <- chunk[i:]:
+						default:
+						}
+						break
+					}
+					switch {
+					case b == 'p' || b == 'P':
+						if q.toggle() {
+							c.status("paused, press p to resume")
+						} else {
+							c.status("")
+						}
+					case b >= '0' && b <= '9':
+						selected += string(b)
+						show(fmt.Sprintf("press c to cancel #%s, esc to clear", selected))
+					case b == keyBackspace || b == '\b':
+						if selected != "" {
+							selected = selected[:len(selected)-1]
+						}
+						show(fmt.Sprintf("press c to cancel #%s, esc to clear", selected))
+					case b == keyEscape:
+						selected = ""
+						show("")
+					case b == 'c' || b == 'C':
+						n, err := strconv.Atoi(selected)
+						selected = ""
+						if err != nil {
+							show("type the song number shown in the progress bar, then press c to cancel it")
+							continue
+						}
+						if name, ok := q.cancelJob(n); ok {
+							show(fmt.Sprintf("canceled #%d %s", n, name))
+						} else {
+							show(fmt.Sprintf("#%d is not downloading", n))
+						}
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		rawMode.Store(false)
+		_ = term.Restore(fd, old)
+	}
+}
+
+// status 显示下载队列状态,批量下载时显示在汇总进度条中
+func (c *Download) status(s string) {
+	if c.batch != nil {
+		c.batch.state(s)
+		return
+	}
+	if s != "" {
+		_, _ = fmt.Fprintln(os.Stderr, s)
+	}
+}
+
+// raw模式下的按键
+const (
+	keyInterrupt = 0x03 // ctrl+c
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+)
+
+var (
+	stdinOnce   sync.Once
+	stdinChunks chan []byte
+	// rawMode 终端是否处于raw模式,raw模式下交互提示需要自行回显输入
+	rawMode atomic.Bool
+	// prompting 正在等待输入的交互提示数量
+	prompting atomic.Int32
+	// answers 交互提示期间由快捷键监听转交的输入
+	answers = make(chan []byte, 1)
+)
+
+// stdinReader 返回读取标准输入的通道。整个进程只有一个读取协程,
+// 避免下载快捷键与交互提示同时读取标准输入时互相抢占输入。
+// 终端处于raw模式时每次按键即可读取,否则按行读取。
+func stdinReader() <-chan []byte {
+	stdinOnce.Do(func() {
+		stdinChunks = make(chan []byte)
+		go func() {
+			defer close(stdinChunks)
+			for {
+				var buf = make([]byte, 256)
+				n, err := os.Stdin.Read(buf)
+				if n > 0 {
+					stdinChunks <- buf[:n]
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+	})
+	return stdinChunks
+}
+
+// promptLine 交互提示读取一行输入,终端处于raw模式时自行回显输入及处理退格
+func promptLine(ctx context.Context) (string, error) {
+	prompting.Add(1)
+	defer prompting.Add(-1)
+
+	var (
+		line []byte
+		echo = func(s string) {
+			if rawMode.Load() {
+				_, _ = fmt.Fprint(os.Stderr, s)
+			}
+		}
+	)
+	for {
+		var chunk []byte
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case chunk = <-answers:
+		case b, ok := <-stdinReader():
+			if !ok {
+				if len(line) > 0 {
+					return strings.TrimSpace(string(line)), nil
+				}
+				return "", io.EOF
+			}
+			chunk = b
+		}
+		for _, b := range chunk {
+			switch b {
+			case '\r', '\n':
+				echo("\r\n")
+				return strings.TrimSpace(string(line)), nil
+			case keyBackspace, '\b':
+				if len(line) > 0 {
+					line = line[:len(line)-1]
+					echo("\b \b")
+				}
+			default:
+				line = append(line, b)
+				echo(string(b))
+			}
+		}
+	}
+}
+
+// downloadState 下载中断时未完成的歌曲
+type downloadState struct {
+	Time  time.Time `json:"time"`
+	Songs []int64   `json:"songs"`
+}
+
+// saveState 保存未完成的歌曲,全部完成时删除之前保存的进度
+func (c *Download) saveState(songs []int64) error {
+	var file = filepath.Join(c.opts.Output, downloadStateFile)
+	if len(songs) == 0 {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(downloadState{Time: time.Now(), Songs: songs}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// loadState 读取上次中断时未完成的歌曲id
+func (c *Download) loadState() ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(c.opts.Output, downloadStateFile))
+	if err != nil {
+		return nil, err
+	}
+	var state downloadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	var ids = make([]string, 0, len(state.Songs))
+	for _, id := range state.Songs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	return ids, nil
+}
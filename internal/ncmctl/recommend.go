@@ -0,0 +1,272 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+type RecommendOpts struct {
+	Download  bool   // 下载每日推荐歌曲
+	Playlists bool   // 同时下载每日推荐歌单
+	Dislike   bool   // 交互式标记不感兴趣的歌曲
+	Output    string // 输出目录,歌曲保存在以日期命名的子目录中
+	Level     string // 歌曲品质
+	Parallel  int64  // 并发下载数量
+}
+
+type Recommend struct {
+	root *Root
+	cmd  *cobra.Command
+	opts RecommendOpts
+	l    *log.Logger
+}
+
+// recommendSong 每日推荐歌曲
+type recommendSong struct {
+	Id     int64
+	Name   string
+	Artist string
+	Album  string
+	Reason string
+}
+
+func NewRecommend(root *Root, l *log.Logger) *Recommend {
+	c := &Recommend{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "recommend",
+			Short: "[need login] Show or download today's recommended songs and playlists",
+			Example: "  ncmctl recommend\n" +
+				"  ncmctl recommend --dislike\n" +
+				"  ncmctl recommend --download -o ./Daily -l SQ\n" +
+				"  ncmctl recommend --download --playlists",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *Recommend) addFlags() {
+	c.cmd.Flags().BoolVar(&c.opts.Download, "download", false, "download the recommended songs into a dated folder")
+	c.cmd.Flags().BoolVar(&c.opts.Playlists, "playlists", false, "also download the recommended playlists, require --download")
+	c.cmd.Flags().BoolVar(&c.opts.Dislike, "dislike", false, "interactively mark songs as not interested to refresh the recommendations")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./Daily", "output path, songs are saved in a sub directory named by date")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().Int64VarP(&c.opts.Parallel, "parallel", "p", 5, "concurrent download count")
+}
+
+func (c *Recommend) validate() error {
+	if c.opts.Playlists && !c.opts.Download {
+		return fmt.Errorf("--playlists require --download")
+	}
+	return nil
+}
+
+func (c *Recommend) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Recommend) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Recommend) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	songs, err := c.songs(ctx, request)
+	if err != nil {
+		return err
+	}
+	playlists, err := request.RecommendResource(ctx, &weapi.RecommendResourceReq{})
+	if err != nil {
+		return fmt.Errorf("RecommendResource: %w", err)
+	}
+
+	c.table(songs, playlists.Recommend)
+	if c.opts.Dislike {
+		if songs, err = c.dislike(ctx, request, songs); err != nil {
+			return err
+		}
+	}
+	if !c.opts.Download {
+		return nil
+	}
+
+	var (
+		dir    = filepath.Join(c.opts.Output, time.Now().Format(time.DateOnly))
+		ids    = make([]string, 0, len(songs))
+		failed int
+	)
+	for _, v := range songs {
+		ids = append(ids, strconv.FormatInt(v.Id, 10))
+	}
+	if err := c.download(ctx, dir, ids); err != nil {
+		return fmt.Errorf("download songs: %w", err)
+	}
+	if !c.opts.Playlists {
+		return nil
+	}
+	for _, v := range playlists.Recommend {
+		var (
+			out    = filepath.Join(dir, utils.Filename(v.Name, "_"))
+			source = fmt.Sprintf("https://music.163.com/playlist?id=%d", v.Id)
+		)
+		if err := c.download(ctx, out, []string{source}); err != nil {
+			failed++
+			log.Error("[recommend] download playlist %d: %s", v.Id, err)
+		}
+	}
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d playlists failed", failed, len(playlists.Recommend))}
+	}
+	return nil
+}
+
+func (c *Recommend) songs(ctx context.Context, request *weapi.Api) ([]recommendSong, error) {
+	resp, err := request.RecommendSongs(ctx, &weapi.RecommendSongsReq{})
+	if err != nil {
+		return nil, fmt.Errorf("RecommendSongs: %w", err)
+	}
+
+	var reasons = make(map[int64]string, len(resp.Data.RecommendReasons))
+	for _, v := range resp.Data.RecommendReasons {
+		reasons[v.SongId] = v.Reason
+	}
+	var list = make([]recommendSong, 0, len(resp.Data.DailySongs))
+	for _, v := range resp.Data.DailySongs {
+		var artists = make([]string, 0, len(v.Ar))
+		for _, ar := range v.Ar {
+			artists = append(artists, ar.Name)
+		}
+		list = append(list, recommendSong{
+			Id:     v.Id,
+			Name:   v.Name,
+			Artist: strings.Join(artists, "/"),
+			Album:  v.Al.Name,
+			Reason: reasons[v.Id],
+		})
+	}
+	return list, nil
+}
+
+// dislike 交互式标记不感兴趣的歌曲,被标记的歌曲会替换为接口返回的新推荐歌曲
+func (c *Recommend) dislike(ctx context.Context, request *weapi.Api, songs []recommendSong) ([]recommendSong, error) {
+	var reader = bufio.NewReader(os.Stdin)
+	for {
+		c.cmd.Printf("dislike [1-%d, eg: 1,3-5], enter to finish: ", len(songs))
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return songs, nil
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			return songs, nil
+		}
+		index, err := parseSelection(line, len(songs))
+		if err != nil {
+			c.cmd.Printf("invalid input: %s\n", err)
+			continue
+		}
+		for _, i := range index {
+			var old = songs[i-1]
+			resp, err := request.RecommendSongsDislike(ctx, &weapi.RecommendSongsDislikeReq{ResId: old.Id})
+			if err != nil {
+				if _, ok := api.AsError(err); !ok {
+					return nil, fmt.Errorf("RecommendSongsDislike: %w", err)
+				}
+				log.Warn("[recommend] RecommendSongsDislike(%d): %s", old.Id, err)
+				continue
+			}
+			if resp.Data.Id == 0 {
+				c.cmd.Printf("%s disliked, no replacement\n", old.Name)
+				continue
+			}
+			songs[i-1] = recommendSong{
+				Id:     resp.Data.Id,
+				Name:   resp.Data.Name,
+				Artist: artistNames(resp.Data.Ar),
+				Album:  resp.Data.Al.Name,
+			}
+			c.cmd.Printf("%s replaced by %s - %s\n", old.Name, songs[i-1].Artist, songs[i-1].Name)
+		}
+		c.table(songs, nil)
+	}
+}
+
+func (c *Recommend) download(ctx context.Context, output string, sources []string) error {
+	d := NewDownload(c.root, c.l)
+	d.opts.Output = output
+	d.opts.Level = c.opts.Level
+	d.opts.Parallel = c.opts.Parallel
+	return d.execute(ctx, sources)
+}
+
+func (c *Recommend) table(songs []recommendSong, playlists []weapi.RecommendResourceRespPlaylist) {
+	var w = tabwriter.NewWriter(c.cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tID\tNAME\tARTIST\tALBUM\tREASON")
+	for i, v := range songs {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\n", i+1, v.Id, v.Name, v.Artist, v.Album, v.Reason)
+	}
+	if len(playlists) > 0 {
+		fmt.Fprintln(w, "\n#\tPLAYLIST\tNAME\tTRACKS\tCREATOR\tCOPYWRITER")
+		for i, v := range playlists {
+			fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%s\t%s\n", i+1, v.Id, v.Name, v.TrackCount, v.Creator.Nickname, v.Copywriter)
+		}
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,94 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// 全局输出格式,通过 --output-format 指定,便于脚本解析命令结果
+const (
+	outputJson  = "json"  // json格式,输出完整的结果数据
+	outputTable = "table" // 对齐的表格,包含表头
+	outputPlain = "plain" // 每行一条记录,字段以tab分隔,不包含表头
+)
+
+func validOutput(format string) error {
+	switch format {
+	case "", outputJson, outputTable, outputPlain:
+		return nil
+	}
+	return fmt.Errorf("output format is not support: %s", format)
+}
+
+// view 命令输出结果,Data用于json输出,Header及Rows用于table及plain输出
+type view struct {
+	Data   any
+	Header []string
+	Rows   [][]string
+}
+
+// render 按格式输出结果,format为空时使用表格输出
+func render(w io.Writer, format string, v view) error {
+	switch format {
+	case outputJson:
+		var data = v.Data
+		// 空切片输出[]而不是null,便于脚本处理
+		if rv := reflect.ValueOf(data); rv.Kind() == reflect.Slice && rv.IsNil() {
+			data = []any{}
+		}
+		var encoder = json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case outputPlain:
+		for _, row := range v.Rows {
+			if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		var tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if len(v.Header) > 0 {
+			_, _ = fmt.Fprintln(tw, strings.Join(v.Header, "\t"))
+		}
+		for _, row := range v.Rows {
+			_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// outputFormat 返回全局输出格式,未指定时返回命令自身的格式
+func (c *Root) outputFormat(format string) string {
+	if c.Opts.Output != "" {
+		return c.Opts.Output
+	}
+	return format
+}
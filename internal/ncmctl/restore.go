@@ -0,0 +1,332 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/plan"
+
+	"github.com/spf13/cobra"
+)
+
+type RestoreOpts struct {
+	Items      []string // 需要恢复的内容 playlist、subscribed、liked
+	Substitute bool     // 歌曲不存在时通过搜索替换为同名歌曲
+	DryRun     bool     // 仅输出恢复计划
+}
+
+type Restore struct {
+	root *Root
+	cmd  *cobra.Command
+	opts RestoreOpts
+	l    *log.Logger
+
+	failed  int // 接口调用失败的数量
+	missing int // 不存在且未能替换的歌曲数量
+}
+
+func NewRestore(root *Root, l *log.Logger) *Restore {
+	c := &Restore{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "restore <snapshot>",
+			Short: "[need login] Restore playlists and liked songs from a backup snapshot",
+			Example: "  ncmctl restore ./backup/backup-1-20240101-000000.json\n" +
+				"  ncmctl restore ./backup/backup-1-20240101-000000.json --dry-run\n" +
+				"  ncmctl restore ./backup/backup-1-20240101-000000.ndjson --items liked --substitute",
+			Args: cobra.ExactArgs(1),
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args[0])
+	}
+	return c
+}
+
+func (c *Restore) addFlags() {
+	c.cmd.Flags().StringSliceVar(&c.opts.Items, "items", []string{"playlist", "subscribed", "liked"}, "items to restore, support: playlist、subscribed、liked")
+	c.cmd.Flags().BoolVar(&c.opts.Substitute, "substitute", false, "replace songs that no longer exist with the same name song found by search")
+	c.cmd.Flags().BoolVar(&c.opts.DryRun, "dry-run", false, "only print the restore plan")
+}
+
+func (c *Restore) validate() error {
+	for _, v := range c.opts.Items {
+		switch v {
+		case "playlist", "subscribed", "liked":
+		default:
+			return fmt.Errorf("items is not support: %s", v)
+		}
+	}
+	return nil
+}
+
+func (c *Restore) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Restore) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Restore) execute(ctx context.Context, path string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	snap, err := loadSnapshot(path)
+	if err != nil {
+		return err
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	var uid = user.Account.Id
+	if uid != snap.UserId {
+		c.cmd.Printf("restore snapshot of user %d(%s) to user %d\n", snap.UserId, snap.Nickname, uid)
+	}
+
+	// 当前账号已有的歌单,用于重复执行时复用同名歌单避免重复创建
+	list, err := userPlaylists(ctx, request, uid)
+	if err != nil {
+		return err
+	}
+	var (
+		owned      = make(map[string]int64)
+		subscribed = make(map[int64]bool)
+		likedPid   int64
+	)
+	for _, v := range list {
+		switch {
+		case v.UserId != uid:
+			subscribed[v.Id] = true
+		case v.SpecialType == 5:
+			likedPid = v.Id
+		default:
+			if _, ok := owned[v.Name]; !ok {
+				owned[v.Name] = v.Id
+			}
+		}
+	}
+
+	var p plan.Plan
+	if slices.Contains(c.opts.Items, "playlist") {
+		for _, v := range snap.Playlists {
+			if v.Subscribed {
+				continue
+			}
+			var pid = owned[v.Name]
+			if pid == 0 {
+				if c.opts.DryRun {
+					p.Add(fmt.Sprintf("playlist/%s", v.Name), 0)
+				} else if pid, err = c.create(ctx, request, v); err != nil {
+					c.failed++
+					log.Error("[restore] create playlist %s: %s", v.Name, err)
+					c.cmd.PrintErrf("create playlist %s failed: %s\n", v.Name, err)
+					continue
+				}
+			}
+			if err := c.restoreTracks(ctx, request, &p, "playlist/"+v.Name, pid, v.Tracks); err != nil {
+				return err
+			}
+		}
+	}
+
+	if slices.Contains(c.opts.Items, "liked") && len(snap.Liked) > 0 {
+		if likedPid == 0 {
+			return fmt.Errorf("liked playlist of user %d not found", uid)
+		}
+		if err := c.restoreTracks(ctx, request, &p, "liked", likedPid, snap.Liked); err != nil {
+			return err
+		}
+	}
+
+	if slices.Contains(c.opts.Items, "subscribed") {
+		for _, v := range snap.Playlists {
+			if !v.Subscribed || subscribed[v.Id] || v.Id == 0 {
+				continue
+			}
+			var name = fmt.Sprintf("subscribed/%s(%d)", v.Name, v.Id)
+			if c.opts.DryRun {
+				p.Add(name, 0)
+				continue
+			}
+			_, err := request.PlaylistSubscribe(ctx, &weapi.PlaylistSubscribeReq{Id: fmt.Sprintf("%d", v.Id)})
+			if err != nil {
+				c.failed++
+				log.Error("[restore] subscribe %s: %s", name, err)
+				c.cmd.PrintErrf("subscribe %s failed: %s\n", name, err)
+				continue
+			}
+			c.cmd.Printf("subscribe %s\n", name)
+		}
+	}
+
+	if c.opts.DryRun {
+		return p.Write(c.cmd.OutOrStdout())
+	}
+	c.cmd.Printf("restore finished, %d songs missing, %d failed\n", c.missing, c.failed)
+	if c.failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d restore operations failed", c.failed)}
+	}
+	return nil
+}
+
+func (c *Restore) create(ctx context.Context, request *weapi.Api, v backupPlaylist) (int64, error) {
+	var privacy = "0"
+	if v.Privacy == 10 {
+		privacy = "10"
+	}
+	resp, err := request.PlaylistCreate(ctx, &weapi.PlaylistCreateReq{Name: v.Name, Privacy: privacy})
+	if err != nil {
+		return 0, fmt.Errorf("PlaylistCreate: %w", err)
+	}
+	c.cmd.Printf("create playlist %s(%d)\n", v.Name, resp.Id)
+	return resp.Id, nil
+}
+
+// restoreTracks 将快照中的歌曲补充到歌单中,已存在的歌曲跳过,不存在的歌曲报告或者通过搜索替换
+func (c *Restore) restoreTracks(ctx context.Context, request *weapi.Api, p *plan.Plan, name string, pid int64, tracks []backupTrack) error {
+	var exist = make(map[int64]bool)
+	if pid != 0 {
+		_, songs, err := playlistSongs(ctx, c.root, request, pid)
+		if err != nil {
+			return err
+		}
+		for _, s := range songs {
+			exist[s.Id] = true
+		}
+	}
+
+	var ids = make([]int64, 0, len(tracks))
+	for _, t := range tracks {
+		if !exist[t.Id] {
+			ids = append(ids, t.Id)
+		}
+	}
+	available, err := songsAvailable(ctx, request, ids)
+	if err != nil {
+		return err
+	}
+
+	var add types.IntsString
+	for _, t := range tracks {
+		if exist[t.Id] {
+			continue
+		}
+		var id = t.Id
+		if !available[t.Id] {
+			id = 0
+			if c.opts.Substitute {
+				if id, err = substituteSong(ctx, request, t); err != nil {
+					return err
+				}
+			}
+			if id == 0 || exist[id] {
+				if id == 0 {
+					c.missing++
+					c.cmd.PrintErrf("missing: %s/%s\n", name, t)
+				}
+				continue
+			}
+			c.cmd.Printf("substitute: %s/%s -> %d\n", name, t, id)
+		}
+		exist[id] = true
+		if c.opts.DryRun {
+			p.Add(fmt.Sprintf("%s/%s", name, t), 0)
+			continue
+		}
+		add = append(add, id)
+	}
+
+	for i := 0; i < len(add); i += playlistTracksBatch {
+		var batch = add[i:min(i+playlistTracksBatch, len(add))]
+		_, err := request.PlaylistAddOrDel(ctx, &weapi.PlaylistAddOrDelReq{Op: "add", Pid: pid, TrackIds: batch, Imme: true})
+		if err != nil {
+			c.failed++
+			log.Error("[restore] add %s %v: %s", name, batch, err)
+			c.cmd.PrintErrf("%s add %d songs failed: %s\n", name, len(batch), err)
+			continue
+		}
+		c.cmd.Printf("%s add %d songs\n", name, len(batch))
+	}
+	return nil
+}
+
+// songsAvailable 查询歌曲是否仍然存在,返回存在的歌曲id集合
+func songsAvailable(ctx context.Context, request *weapi.Api, ids []int64) (map[int64]bool, error) {
+	var available = make(map[int64]bool, len(ids))
+	resp, err := request.SongDetailBatch(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("SongDetailBatch: %w", err)
+	}
+	for _, s := range resp.Songs {
+		available[s.Id] = true
+	}
+	return available, nil
+}
+
+// substituteSong 搜索标题相同的歌曲,优先选择歌手也相同的结果,未找到返回0
+func substituteSong(ctx context.Context, request *weapi.Api, t backupTrack) (int64, error) {
+	var keyword = strings.TrimSpace(t.Name + " " + strings.Join(t.Artists, " "))
+	resp, err := request.CloudSearch(ctx, &weapi.CloudSearchReq{S: keyword, Type: weapi.SearchTypeSong, Limit: 10})
+	if err != nil {
+		return 0, fmt.Errorf("CloudSearch: %w", err)
+	}
+	var candidate int64
+	for _, s := range resp.Result.Songs {
+		if s.Id == t.Id || !strings.EqualFold(strings.TrimSpace(s.Name), strings.TrimSpace(t.Name)) {
+			continue
+		}
+		for _, ar := range s.Ar {
+			if slices.Contains(t.Artists, ar.Name) {
+				return s.Id, nil
+			}
+		}
+		if candidate == 0 {
+			candidate = s.Id
+		}
+	}
+	return candidate, nil
+}
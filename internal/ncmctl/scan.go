@@ -0,0 +1,455 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/normalize"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/dhowden/tag"
+	"github.com/spf13/cobra"
+)
+
+// scanMappingFile 本地文件与网易云歌曲id的映射文件,默认位于扫描目录下
+const scanMappingFile = ".ncmctl-scan.json"
+
+// scanBracket 匹配标题中的括号后缀,例如 (Live)、[伴奏]、（Remix）
+var scanBracket = regexp.MustCompile(`\s*[(\[（【][^)\]）】]*[)\]）】]\s*`)
+
+type ScanOpts struct {
+	Mapping  string        // 映射文件路径
+	MinScore float64       // 接受匹配结果的最低置信度
+	WriteTag bool          // 将匹配到的歌曲id写入文件标签
+	Interval time.Duration // 每次搜索请求的间隔
+	Retry    bool          // 重新匹配之前未匹配成功的文件
+}
+
+type Scan struct {
+	root *Root
+	cmd  *cobra.Command
+	opts ScanOpts
+	l    *log.Logger
+}
+
+// scanMapping 扫描结果,Files按文件路径排序
+type scanMapping struct {
+	Time  time.Time   `json:"time"`
+	Files []scanEntry `json:"files"`
+}
+
+type scanEntry struct {
+	File      string  `json:"file"`                // 相对扫描目录的路径
+	Id        int64   `json:"id"`                  // 网易云歌曲id,为0表示未匹配
+	Name      string  `json:"name,omitempty"`      // 匹配到的歌曲名称
+	Artist    string  `json:"artist,omitempty"`    // 匹配到的歌手
+	Album     string  `json:"album,omitempty"`     // 匹配到的专辑
+	Score     float64 `json:"score"`               // 匹配置信度0-1,来自文件标签时为1
+	Source    string  `json:"source"`              // tag: 文件已有的歌曲id标签 search: 搜索匹配
+	Candidate int64   `json:"candidate,omitempty"` // 未匹配时置信度最高的候选歌曲,便于人工确认
+}
+
+func NewScan(root *Root, l *log.Logger) *Scan {
+	c := &Scan{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "scan",
+			Short: "Match local music files to netease song ids and write a mapping file",
+			Long: "Walk all music files under dir, read the title/artist/album tags (or the \"artist - title\" file name\n" +
+				"when tags are missing) and search netease for the best match. The result is written to a mapping file\n" +
+				"(default <dir>/" + scanMappingFile + ") which can be used by other commands such as lyric --for-library.\n" +
+				"Files already carrying the NCM_ID tag are mapped directly, matched files are skipped when scanning again.",
+			Example: "  ncmctl scan ./music\n" +
+				"  ncmctl scan ./music --min-score 0.8 --write-tag\n" +
+				"  ncmctl scan ./music --mapping ./music.json --retry",
+			Args: cobra.ExactArgs(1),
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args[0])
+	}
+	return c
+}
+
+func (c *Scan) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Mapping, "mapping", "", "mapping file path, default <dir>/"+scanMappingFile)
+	c.cmd.Flags().Float64Var(&c.opts.MinScore, "min-score", 0.7, "minimum match confidence between 0 and 1")
+	c.cmd.Flags().BoolVar(&c.opts.WriteTag, "write-tag", false, "write the matched song id into the NCM_ID tag of mp3/flac files")
+	c.cmd.Flags().DurationVar(&c.opts.Interval, "interval", time.Second, "wait time between two search requests")
+	c.cmd.Flags().BoolVar(&c.opts.Retry, "retry", false, "match the previously unmatched files again")
+}
+
+func (c *Scan) validate() error {
+	if c.opts.MinScore < 0 || c.opts.MinScore > 1 {
+		return fmt.Errorf("min-score must be between 0 and 1")
+	}
+	if c.opts.Interval < 0 {
+		return fmt.Errorf("interval must be >= 0")
+	}
+	return nil
+}
+
+func (c *Scan) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Scan) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Scan) execute(ctx context.Context, dir string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	dir, err := utils.ExpandTilde(dir)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if !utils.DirExists(dir) {
+		return fmt.Errorf("%s not found", dir)
+	}
+	var mapping = c.opts.Mapping
+	if mapping == "" {
+		mapping = filepath.Join(dir, scanMappingFile)
+	}
+
+	names, err := scanFiles(dir)
+	if err != nil {
+		return err
+	}
+	prev, err := loadScanMapping(mapping)
+	if err != nil {
+		return err
+	}
+	var done = make(map[string]scanEntry, len(prev.Files))
+	for _, e := range prev.Files {
+		if e.Id > 0 || !c.opts.Retry {
+			done[e.File] = e
+		}
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	var (
+		next     = scanMapping{Files: make([]scanEntry, 0, len(names))}
+		searched int
+		matched  int
+		failed   int
+		last     time.Time
+	)
+	for _, name := range names {
+		if e, ok := done[name]; ok {
+			next.Files = append(next.Files, e)
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		var path = filepath.Join(dir, filepath.FromSlash(name))
+		file, err := scanTags(path)
+		if err != nil {
+			log.Warn("[scan] read %s: %s", name, err)
+			failed++
+			continue
+		}
+		if file.Id > 0 {
+			next.Files = append(next.Files, scanEntry{File: name, Id: file.Id, Name: file.Title, Artist: file.Artist, Album: file.Album, Score: 1, Source: "tag"})
+			matched++
+			continue
+		}
+
+		// 搜索请求之间保持间隔,避免曲库较大时触发风控
+		if wait := c.opts.Interval - time.Since(last); !last.IsZero() && wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				break
+			}
+		}
+		last = time.Now()
+		searched++
+
+		entry, err := c.match(ctx, request, name, file)
+		if err != nil {
+			log.Warn("[scan] match %s: %s", name, err)
+			failed++
+			continue
+		}
+		if entry.Id <= 0 {
+			c.cmd.Printf("unmatched: %s\n", name)
+			next.Files = append(next.Files, *entry)
+			continue
+		}
+		matched++
+		c.cmd.Printf("matched: %s => %s - %s(%d) score %.2f\n", name, entry.Artist, entry.Name, entry.Id, entry.Score)
+		if c.opts.WriteTag {
+			if err := scanWriteTag(path, entry.Id); err != nil {
+				log.Warn("[scan] write tag %s: %s", name, err)
+			}
+		}
+		next.Files = append(next.Files, *entry)
+	}
+
+	// 中断或读取失败时保留之前的记录,已删除的文件不再保留
+	var (
+		exists  = make(map[string]bool, len(names))
+		scanned = make(map[string]bool, len(next.Files))
+	)
+	for _, name := range names {
+		exists[name] = true
+	}
+	for _, e := range next.Files {
+		scanned[e.File] = true
+	}
+	for _, e := range prev.Files {
+		if exists[e.File] && !scanned[e.File] {
+			next.Files = append(next.Files, e)
+		}
+	}
+	slices.SortFunc(next.Files, func(a, b scanEntry) int { return strings.Compare(a.File, b.File) })
+	next.Time = time.Now()
+	if err := next.write(mapping); err != nil {
+		return err
+	}
+
+	var unmatched int
+	for _, e := range next.Files {
+		if e.Id <= 0 {
+			unmatched++
+		}
+	}
+	c.cmd.Printf("scanned %d files, %d searched, %d newly matched, %d failed, %d unmatched total, mapping: %s\n",
+		len(names), searched, matched, failed, unmatched, mapping)
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d files failed", failed)}
+	}
+	return nil
+}
+
+// match 根据标签或文件名搜索歌曲,返回置信度最高的结果,低于最低置信度时只记录候选歌曲
+func (c *Scan) match(ctx context.Context, request *weapi.Api, name string, file *libraryFile) (*scanEntry, error) {
+	var title, artist = file.Title, file.Artist
+	if title == "" {
+		var base = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		if ar, t, ok := strings.Cut(base, " - "); ok {
+			artist, title = ar, t
+		} else {
+			title = base
+		}
+	}
+	var keyword = strings.TrimSpace(title + " " + artist)
+	resp, err := request.CloudSearch(ctx, &weapi.CloudSearchReq{S: keyword, Type: weapi.SearchTypeSong, Limit: 10})
+	if err != nil {
+		return nil, fmt.Errorf("CloudSearch: %w", err)
+	}
+
+	var (
+		entry = scanEntry{File: name, Source: "search"}
+		best  *weapi.CloudSearchRespSong
+	)
+	for i, s := range resp.Result.Songs {
+		if score := scanScore(title, artist, file.Album, s); score > entry.Score {
+			entry.Score, best = score, &resp.Result.Songs[i]
+		}
+	}
+	if best == nil {
+		return &entry, nil
+	}
+	if entry.Score < c.opts.MinScore {
+		entry.Candidate = best.Id
+		return &entry, nil
+	}
+	entry.Id, entry.Name, entry.Artist, entry.Album = best.Id, best.Name, artistNames(best.Ar), best.Al.Name
+	return &entry, nil
+}
+
+// scanScore 计算搜索结果与本地文件的匹配置信度(0-1),标题权重0.6、歌手0.3、专辑0.1,
+// 本地缺失的字段不参与计算,标题不相符时直接返回0
+func scanScore(title, artist, album string, s weapi.CloudSearchRespSong) float64 {
+	var titleScore float64
+	switch a, b := scanNormalize(title), scanNormalize(s.Name); {
+	case a == b:
+		titleScore = 1
+	case scanNormalize(scanBracket.ReplaceAllString(title, "")) == scanNormalize(scanBracket.ReplaceAllString(s.Name, "")):
+		// 仅括号后缀不同,例如 Live、Remix 等版本
+		titleScore = 0.8
+	case a != "" && b != "" && (strings.Contains(a, b) || strings.Contains(b, a)):
+		titleScore = 0.5
+	default:
+		return 0
+	}
+
+	var score, weight = titleScore * 0.6, 0.6
+	if locals := scanArtists(artist); len(locals) > 0 {
+		var hit int
+		for _, ar := range locals {
+			if slices.ContainsFunc(s.Ar, func(v types.Artist) bool { return scanNormalize(v.Name) == ar }) {
+				hit++
+			}
+		}
+		score += 0.3 * float64(hit) / float64(len(locals))
+		weight += 0.3
+	}
+	if album != "" {
+		if scanNormalize(album) == scanNormalize(s.Al.Name) {
+			score += 0.1
+		}
+		weight += 0.1
+	}
+	return score / weight
+}
+
+// scanArtists 拆分本地标签中以常见分隔符连接的多个歌手
+func scanArtists(s string) []string {
+	var list []string
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune("/,;&、，；", r) }) {
+		if v = scanNormalize(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func scanNormalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(normalize.Halfwidth(s)), " "))
+}
+
+// scanFiles 递归获取目录下所有音乐文件,跳过隐藏目录,返回排序后相对dir的路径
+func scanFiles(dir string) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !utils.IsMusicExt(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("WalkDir: %w", err)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// scanTags 读取文件标签,mp3/flac支持读取歌曲id标签,其余格式只读取标题、歌手、专辑
+func scanTags(path string) (*libraryFile, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3", ".flac":
+		return readLibraryFile(path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Open: %w", err)
+	}
+	defer file.Close()
+	metadata, err := tag.ReadFrom(file)
+	if err != nil {
+		if errors.Is(err, tag.ErrNoTagsFound) {
+			return &libraryFile{}, nil
+		}
+		return nil, fmt.Errorf("ReadFrom: %w", err)
+	}
+	return &libraryFile{Title: metadata.Title(), Artist: metadata.Artist(), Album: metadata.Album()}, nil
+}
+
+// scanWriteTag 将歌曲id写入mp3/flac文件标签,其余格式忽略
+func scanWriteTag(path string, id int64) error {
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		err = backfillID3v2(path, id, "", nil)
+	case ".flac":
+		err = backfillFlac(path, id, "", nil)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	updateLibraryChecksum(path)
+	return nil
+}
+
+func loadScanMapping(path string) (*scanMapping, error) {
+	var mapping scanMapping
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &mapping, nil
+		}
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return &mapping, nil
+}
+
+// write 先写入临时文件再重命名,避免中断时映射文件损坏
+func (m *scanMapping) write(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
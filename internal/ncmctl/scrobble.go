@@ -26,6 +26,7 @@ package ncmctl
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"strconv"
 	"strings"
 	"time"
@@ -41,9 +42,15 @@ import (
 )
 
 type ScrobbleOpts struct {
-	Num int64
+	Num    int64
+	Count  int64           // 从用户歌单中随机选取歌曲听歌数量,用于完成"听歌N首"等成就
+	Pace   []time.Duration // 每首歌上报间隔随机范围
+	DryRun bool            // 仅输出将要上报得内容不实际执行
 }
 
+// scrobbleCountPace --count 模式下默认上报间隔随机范围
+var scrobbleCountPace = []time.Duration{3 * time.Second, 10 * time.Second}
+
 type Scrobble struct {
 	root *Root
 	cmd  *cobra.Command
@@ -56,9 +63,11 @@ func NewScrobble(root *Root, l *log.Logger) *Scrobble {
 		root: root,
 		l:    l,
 		cmd: &cobra.Command{
-			Use:     "scrobble",
-			Short:   "[need login] Scrobble execute refresh 300 songs",
-			Example: `  ncmctl scrobble`,
+			Use:   "scrobble",
+			Short: "[need login] Scrobble execute refresh 300 songs",
+			Example: `  ncmctl scrobble
+  ncmctl scrobble --count 100
+  ncmctl scrobble --count 100 --pace 5s,20s --dry-run`,
 		},
 	}
 	c.addFlags()
@@ -70,12 +79,30 @@ func NewScrobble(root *Root, l *log.Logger) *Scrobble {
 
 func (c *Scrobble) addFlags() {
 	c.cmd.PersistentFlags().Int64VarP(&c.opts.Num, "num", "n", 300, "num of songs")
+	c.cmd.PersistentFlags().Int64Var(&c.opts.Count, "count", 0, "listen num of random songs from your playlists with randomized durations, max 1000")
+	c.cmd.PersistentFlags().DurationSliceVar(&c.opts.Pace, "pace", nil, "random interval range between two songs, eg: 3s,10s. --count default 3s,10s otherwise 100ms")
+	c.cmd.PersistentFlags().BoolVar(&c.opts.DryRun, "dry-run", false, "only print what would be sent")
 }
 
 func (c *Scrobble) validate() error {
 	if c.opts.Num <= 0 || c.opts.Num > 300 {
 		return fmt.Errorf("num <= 0 or > 300")
 	}
+	if c.opts.Count < 0 || c.opts.Count > 1000 {
+		return fmt.Errorf("count < 0 or > 1000")
+	}
+	switch len(c.opts.Pace) {
+	case 0:
+	case 1:
+		c.opts.Pace = []time.Duration{c.opts.Pace[0], c.opts.Pace[0]}
+		fallthrough
+	case 2:
+		if c.opts.Pace[0] < 0 || c.opts.Pace[0] > c.opts.Pace[1] {
+			return fmt.Errorf("pace range %v is invalid", c.opts.Pace)
+		}
+	default:
+		return fmt.Errorf("pace range must be min,max")
+	}
 	return nil
 }
 
@@ -104,19 +131,20 @@ func (c *Scrobble) execute(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("UserInfo: %w", err)
 	}
-	if user.Code != 200 || user.Profile == nil || user.Account == nil {
+	if user.Profile == nil || user.Account == nil {
 		return fmt.Errorf("need login")
 	}
 	var uid = fmt.Sprintf("%v", user.Account.Id)
 
+	if c.opts.Count > 0 {
+		return c.count(ctx, request, uid)
+	}
+
 	// 判断是否满级，满级则不再执行。
 	detail, err := request.GetUserInfoDetail(ctx, &weapi.GetUserInfoDetailReq{UserId: user.Account.Id})
 	if err != nil {
 		return fmt.Errorf("GetUserInfoDetail: %w", err)
 	}
-	if detail.Code != 200 {
-		return fmt.Errorf("GetUserInfoDetail: %w", err)
-	}
 	if detail.Level >= 10 {
 		c.cmd.Println("账号已满级")
 		return nil
@@ -124,9 +152,8 @@ func (c *Scrobble) execute(ctx context.Context) error {
 
 	// 刷新token过期时间
 	defer func() {
-		refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-		if err != nil || refresh.Code != 200 {
-			log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+		if _, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{}); err != nil {
+			log.Warn("TokenRefresh: %s", err)
 		}
 	}()
 
@@ -181,52 +208,142 @@ func (c *Scrobble) execute(ctx context.Context) error {
 	}
 
 	// 执行刷歌
+	if c.opts.DryRun {
+		c.dryRun(list, c.pace(100*time.Millisecond, 100*time.Millisecond))
+		return nil
+	}
 	for _, v := range list {
-		var req = &weapi.WebLogReq{CsrfToken: "", Logs: []map[string]interface{}{
-			{
-				"action": "play",
-				"json": map[string]interface{}{
-					"type":     "song",
-					"wifi":     0,
-					"download": 0,
-					"id":       v.SongsId,                        // 歌曲id
-					"time":     v.SongsTime,                      // 听歌消耗时间单位秒
-					"end":      "playend",                        // 何种方式结束听歌 eg:ui(在网页端播放完成之后的状态) playend:参考https://gitlab.com/Binaryify/neteasecloudmusicapi/-/blob/main/module/scrobble.js interrupt:播放中途切歌
-					"source":   v.Source,                         // 播放歌曲资源来源 例如toplist等
-					"sourceId": v.SourceId,                       // [选填] 歌单id或者专辑id
-					"mainsite": "1",                              // 未知暂时为1
-					"content":  fmt.Sprintf("id=%v", v.SourceId), // 格式 "id=1981392816" 其中id通常为歌单id也就是和sourceId一样
-				},
-			},
-		}}
-
-		resp, err := request.WebLog(ctx, req)
-		if err != nil {
-			log.Error("[scrobble] WebLog: %w", err)
+		if !c.weblog(ctx, request, v) {
+			time.Sleep(time.Second)
 			continue
 		}
-		if resp.Code != 200 {
-			log.Error("[scrobble] WebLog err: %+v\n", resp)
-			time.Sleep(time.Second)
+		if err := db.Set(ctx, scrobbleRecordKey(uid, v.SongsId), fmt.Sprintf("%v", time.Now().UnixMilli())); err != nil {
+			log.Warn("[scrobble] set %v record err: %s", v.SongsId, err)
+		}
+		_, err := db.Increment(ctx, scrobbleTodayNumKey(uid), 1, expire)
+		if err != nil {
+			log.Warn("[scrobble] set %v record err: %s", v.SongsId, err)
+		}
+		total++
+		bar.Increment()
+		if err := sleep(ctx, c.pace(100*time.Millisecond, 100*time.Millisecond)()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count 从用户歌单中随机选取歌曲,以随机听歌时长及间隔上报听歌记录
+func (c *Scrobble) count(ctx context.Context, request *weapi.Api, uid string) error {
+	list, err := c.playlistSongs(ctx, request, uid, c.opts.Count)
+	if err != nil {
+		return fmt.Errorf("playlistSongs: %w", err)
+	}
+	if len(list) <= 0 {
+		return fmt.Errorf("no songs found in your playlists")
+	}
+	// 听歌时长随机为歌曲时长的60%~100%,模拟中途切歌等真实场景
+	for i := range list {
+		if list[i].SongsTime > 0 {
+			list[i].SongsTime = list[i].SongsTime * (60 + rand.Int64N(41)) / 100
+		}
+	}
+
+	var pace = c.pace(scrobbleCountPace[0], scrobbleCountPace[1])
+	if c.opts.DryRun {
+		c.dryRun(list, pace)
+		return nil
+	}
+
+	var (
+		total int64
+		bar   = pb.Full.Start64(int64(len(list)))
+	)
+	defer func() {
+		log.Debug("scrobble count success: %d", total)
+		bar.Finish()
+	}()
+	for i, v := range list {
+		if !c.weblog(ctx, request, v) {
 			continue
 		}
-		if resp.Code == 200 {
-			if err := db.Set(ctx, scrobbleRecordKey(uid, v.SongsId), fmt.Sprintf("%v", time.Now().UnixMilli())); err != nil {
-				log.Warn("[scrobble] set %v record err: %w", v.SongsId, err)
-			}
-			_, err := db.Increment(ctx, scrobbleTodayNumKey(uid), 1, expire)
-			if err != nil {
-				log.Warn("[scrobble] set %v record err: %w", v.SongsId, err)
-			}
-			total++
-			bar.Increment()
-			time.Sleep(time.Millisecond * 100)
+		total++
+		bar.Increment()
+		if i == len(list)-1 {
+			break
+		}
+		if err := sleep(ctx, pace()); err != nil {
+			return err
 		}
 	}
+	if total < int64(len(list)) {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("scrobble %d/%d songs", total, len(list))}
+	}
 	return nil
 }
 
+// weblog 上报一条听歌记录,返回是否上报成功
+func (c *Scrobble) weblog(ctx context.Context, request *weapi.Api, v NeverHeardSongsList) bool {
+	var req = &weapi.WebLogReq{CsrfToken: "", Logs: []map[string]interface{}{
+		{
+			"action": "play",
+			"json": map[string]interface{}{
+				"type":     "song",
+				"wifi":     0,
+				"download": 0,
+				"id":       v.SongsId,                        // 歌曲id
+				"time":     v.SongsTime,                      // 听歌消耗时间单位秒
+				"end":      "playend",                        // 何种方式结束听歌 eg:ui(在网页端播放完成之后的状态) playend:参考https://gitlab.com/Binaryify/neteasecloudmusicapi/-/blob/main/module/scrobble.js interrupt:播放中途切歌
+				"source":   v.Source,                         // 播放歌曲资源来源 例如toplist等
+				"sourceId": v.SourceId,                       // [选填] 歌单id或者专辑id
+				"mainsite": "1",                              // 未知暂时为1
+				"content":  fmt.Sprintf("id=%v", v.SourceId), // 格式 "id=1981392816" 其中id通常为歌单id也就是和sourceId一样
+			},
+		},
+	}}
+
+	if _, err := request.WebLog(ctx, req); err != nil {
+		log.Error("[scrobble] WebLog: %s", err)
+		return false
+	}
+	return true
+}
+
+// pace 返回上报间隔生成函数,未指定 --pace 时使用[min,max]范围
+func (c *Scrobble) pace(min, max time.Duration) func() time.Duration {
+	if len(c.opts.Pace) == 2 {
+		min, max = c.opts.Pace[0], c.opts.Pace[1]
+	}
+	return func() time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + rand.N(max-min)
+	}
+}
+
+// dryRun 输出将要上报得听歌记录
+func (c *Scrobble) dryRun(list []NeverHeardSongsList, pace func() time.Duration) {
+	var wait time.Duration
+	for i, v := range list {
+		c.cmd.Printf("[dry-run] %d/%d id=%s name=%q time=%ds source=%s sourceId=%s at=+%s\n",
+			i+1, len(list), v.SongsId, v.Name, v.SongsTime, v.Source, v.SourceId, wait.Round(time.Second))
+		wait += pace()
+	}
+}
+
+// sleep 等待指定时长,ctx取消时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
 type NeverHeardSongsList struct {
+	Name      string // 歌曲名称
 	Source    string // 资源类型
 	SourceId  string // 歌单id
 	SongsId   string // 歌单歌曲id
@@ -239,16 +356,13 @@ func (c *Scrobble) neverHeardSongs(ctx context.Context, request *weapi.Api, db d
 	if err != nil {
 		return nil, fmt.Errorf("TopList: %w", err)
 	}
-	if tops.Code != 200 {
-		return nil, fmt.Errorf("TopList err: %+v\n", tops)
-	}
 	if len(tops.List) <= 0 {
 		return nil, fmt.Errorf("TopList is empty")
 	}
 
 	// 根据歌单返回顺序顺次刷歌直到300首歌曲
 	var (
-		req = make([]weapi.SongDetailReqList, 0, num)
+		ids = make([]int64, 0, num)
 		set = make(map[int64]string) // k:歌曲id v:歌单id
 	)
 	for _, list := range tops.List {
@@ -257,9 +371,6 @@ func (c *Scrobble) neverHeardSongs(ctx context.Context, request *weapi.Api, db d
 		if err != nil {
 			return nil, fmt.Errorf("PlaylistDetail(%v): %w", list.Id, err)
 		}
-		if info.Code != 200 {
-			return nil, fmt.Errorf("PlaylistDetail(%v) err: %+v\n", list.Id, info)
-		}
 		if len(info.Playlist.TrackIds) <= 0 {
 			log.Warn("PlaylistDetail(%v) is empty", list.Id)
 			continue
@@ -267,7 +378,7 @@ func (c *Scrobble) neverHeardSongs(ctx context.Context, request *weapi.Api, db d
 
 		var sourceId = list.Id
 		for _, v := range info.Playlist.TrackIds {
-			if int64(len(req)) >= num {
+			if int64(len(ids)) >= num {
 				break
 			}
 
@@ -280,23 +391,24 @@ func (c *Scrobble) neverHeardSongs(ctx context.Context, request *weapi.Api, db d
 			// 由于同一首歌可能会在不同得歌单中存在因此需要去重
 			if _, ok := set[v.Id]; !ok {
 				set[v.Id] = fmt.Sprintf("%d", sourceId)
-				req = append(req, weapi.SongDetailReqList{Id: fmt.Sprintf("%d", v.Id), V: 0})
+				ids = append(ids, v.Id)
 			}
 		}
-		if int64(len(req)) >= num {
-			log.Debug("SongDetailReqList num(%d)", len(req))
+		if int64(len(ids)) >= num {
+			log.Debug("SongDetail ids num(%d)", len(ids))
 			break
 		}
 	}
 
 	// 根据歌单trickIds.Id查询歌曲详情信息
 	var resp = make([]NeverHeardSongsList, 0, num)
-	details, err := request.SongDetail(ctx, &weapi.SongDetailReq{C: req})
+	details, err := request.SongDetailBatch(ctx, ids)
 	if err != nil {
-		return nil, fmt.Errorf("SongDetail: %w", err)
+		return nil, fmt.Errorf("SongDetailBatch: %w", err)
 	}
 	for _, v := range details.Songs {
 		resp = append(resp, NeverHeardSongsList{
+			Name:      v.Name,
 			Source:    "toplist",
 			SourceId:  set[v.Id],
 			SongsId:   fmt.Sprintf("%v", v.Id),
@@ -307,6 +419,62 @@ func (c *Scrobble) neverHeardSongs(ctx context.Context, request *weapi.Api, db d
 	return resp, nil
 }
 
+// playlistSongs 从用户创建及收藏得歌单中随机选取num首歌曲
+func (c *Scrobble) playlistSongs(ctx context.Context, request *weapi.Api, uid string, num int64) ([]NeverHeardSongsList, error) {
+	playlists, err := request.Playlist(ctx, &weapi.PlaylistReq{Uid: uid, Offset: "0"})
+	if err != nil {
+		return nil, fmt.Errorf("Playlist: %w", err)
+	}
+
+	var (
+		ids []int64
+		set = make(map[int64]string) // k:歌曲id v:歌单id
+	)
+	for _, list := range playlists.Playlist {
+		if list.TrackCount <= 0 {
+			continue
+		}
+		info, err := request.PlaylistDetail(ctx, &weapi.PlaylistDetailReq{Id: fmt.Sprintf("%v", list.Id)})
+		if err != nil {
+			if _, ok := api.AsError(err); !ok {
+				return nil, fmt.Errorf("PlaylistDetail(%v): %w", list.Id, err)
+			}
+			log.Warn("PlaylistDetail(%v): %s", list.Id, err)
+			continue
+		}
+		for _, v := range info.Playlist.TrackIds {
+			if _, ok := set[v.Id]; !ok {
+				set[v.Id] = fmt.Sprintf("%d", list.Id)
+				ids = append(ids, v.Id)
+			}
+		}
+	}
+
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	if int64(len(ids)) > num {
+		ids = ids[:num]
+	}
+	if len(ids) <= 0 {
+		return nil, nil
+	}
+
+	details, err := request.SongDetailBatch(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("SongDetailBatch: %w", err)
+	}
+	var resp = make([]NeverHeardSongsList, 0, len(details.Songs))
+	for _, v := range details.Songs {
+		resp = append(resp, NeverHeardSongsList{
+			Name:      v.Name,
+			Source:    "list",
+			SourceId:  set[v.Id],
+			SongsId:   fmt.Sprintf("%v", v.Id),
+			SongsTime: v.Dt / 1000,
+		})
+	}
+	return resp, nil
+}
+
 func scrobbleRecordKey(uid string, songId string) string {
 	return fmt.Sprintf("scrobble:record:%v:%v", uid, songId)
 }
@@ -0,0 +1,333 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+// searchTypes 命令行搜索类型与接口搜索类型映射
+var searchTypes = map[string]weapi.SearchType{
+	"song":     weapi.SearchTypeSong,
+	"album":    weapi.SearchTypeAlbum,
+	"artist":   weapi.SearchTypeArtist,
+	"playlist": weapi.SearchTypePlaylist,
+	"djradio":  weapi.SearchTypeDjRadio,
+	"lyric":    weapi.SearchTypeLyric,
+}
+
+type SearchOpts struct {
+	Type        string // 搜索类型
+	Limit       int64  // 每页数量
+	Page        int64  // 页码从1开始
+	Json        bool   // 以json格式输出
+	Interactive bool   // 交互式选择下载
+	Output      string // 下载输出目录
+	Level       string // 下载歌曲品质
+}
+
+type Search struct {
+	root *Root
+	cmd  *cobra.Command
+	opts SearchOpts
+	l    *log.Logger
+}
+
+func NewSearch(root *Root, l *log.Logger) *Search {
+	c := &Search{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "search",
+			Short: "Search songs, albums, artists, playlists, djradios or lyrics",
+			Example: "  ncmctl search 晴天\n" +
+				"  ncmctl search -t album 叶惠美 --page 2\n" +
+				"  ncmctl search -t playlist 华语 --json\n" +
+				"  ncmctl search 周杰伦 -i -l SQ -o ./download",
+			Args: cobra.MinimumNArgs(1),
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), strings.Join(args, " "))
+	}
+	return c
+}
+
+func (c *Search) addFlags() {
+	c.cmd.Flags().StringVarP(&c.opts.Type, "type", "t", "song", "search type. support: song、album、artist、playlist、djradio、lyric")
+	c.cmd.Flags().Int64VarP(&c.opts.Limit, "limit", "n", 20, "number of results per page, max 100")
+	c.cmd.Flags().Int64VarP(&c.opts.Page, "page", "P", 1, "page number, start from 1")
+	c.cmd.Flags().BoolVar(&c.opts.Json, "json", false, "output the search result in json format, same as --output-format json")
+	c.cmd.Flags().BoolVarP(&c.opts.Interactive, "interactive", "i", false, "interactively select results to download")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./download", "music file output path when interactive download")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "song quality level when interactive download. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+}
+
+func (c *Search) validate() error {
+	if _, ok := searchTypes[c.opts.Type]; !ok {
+		return fmt.Errorf("unsupported search type: %s", c.opts.Type)
+	}
+	if c.opts.Limit <= 0 || c.opts.Limit > 100 {
+		return fmt.Errorf("limit must be range 1-100")
+	}
+	if c.opts.Page <= 0 {
+		return fmt.Errorf("page must be >= 1")
+	}
+	if c.opts.Interactive && c.format() != outputTable {
+		return fmt.Errorf("--interactive only support table output format")
+	}
+	if c.opts.Interactive && c.opts.Type == "djradio" {
+		return fmt.Errorf("djradio does not support download")
+	}
+	return nil
+}
+
+// format 输出格式,--json 等同于 --output-format json
+func (c *Search) format() string {
+	if c.opts.Json {
+		return outputJson
+	}
+	return c.root.outputFormat(outputTable)
+}
+
+func (c *Search) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Search) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Search) execute(ctx context.Context, keyword string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	var reader = bufio.NewReader(os.Stdin)
+	for page := c.opts.Page; ; {
+		result, total, err := c.search(ctx, request, keyword, page)
+		if err != nil {
+			return err
+		}
+		var format = c.format()
+		if err := render(c.cmd.OutOrStdout(), format, c.view(result)); err != nil {
+			return err
+		}
+		if format != outputTable {
+			return nil
+		}
+
+		var pages = (total + c.opts.Limit - 1) / c.opts.Limit
+		c.cmd.Printf("page %d/%d total %d\n", page, pages, total)
+		if !c.opts.Interactive {
+			return nil
+		}
+
+		var sources = c.sources(result)
+	input:
+		c.cmd.Printf("download [1-%d, eg: 1,3-5], n: next page, p: previous page, q: quit (default q): ", len(sources))
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil
+		}
+		switch line = strings.TrimSpace(line); line {
+		case "", "q":
+			return nil
+		case "n":
+			if page < pages {
+				page++
+			}
+			continue
+		case "p":
+			if page > 1 {
+				page--
+			}
+			continue
+		}
+		index, err := parseSelection(line, len(sources))
+		if err != nil {
+			c.cmd.Printf("invalid input: %s\n", err)
+			goto input
+		}
+		var selected = make([]string, 0, len(index))
+		for _, i := range index {
+			selected = append(selected, sources[i-1])
+		}
+
+		d := NewDownload(c.root, c.l)
+		d.opts.Output = c.opts.Output
+		d.opts.Level = c.opts.Level
+		if err := d.execute(ctx, selected); err != nil {
+			return fmt.Errorf("download: %w", err)
+		}
+		return nil
+	}
+}
+
+// search 返回当前搜索类型对应的结果列表以及结果总数
+func (c *Search) search(ctx context.Context, request *weapi.Api, keyword string, page int64) (any, int64, error) {
+	resp, err := request.CloudSearch(ctx, &weapi.CloudSearchReq{
+		S:      keyword,
+		Type:   searchTypes[c.opts.Type],
+		Limit:  c.opts.Limit,
+		Offset: (page - 1) * c.opts.Limit,
+		Total:  true,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("CloudSearch: %w", err)
+	}
+
+	var r = resp.Result
+	switch c.opts.Type {
+	case "album":
+		return r.Albums, r.AlbumCount, nil
+	case "artist":
+		return r.Artists, r.ArtistCount, nil
+	case "playlist":
+		return r.Playlists, r.PlaylistCount, nil
+	case "djradio":
+		return r.DjRadios, r.DjRadiosCount, nil
+	default:
+		return r.Songs, r.SongCount, nil
+	}
+}
+
+// view 将搜索结果转换为输出内容
+func (c *Search) view(result any) view {
+	var v = view{Data: result}
+	switch list := result.(type) {
+	case []weapi.CloudSearchRespSong:
+		v.Header = []string{"#", "ID", "NAME", "ARTIST", "ALBUM", "DURATION"}
+		for i, s := range list {
+			d := time.Duration(s.Dt) * time.Millisecond
+			v.Rows = append(v.Rows, []string{strconv.Itoa(i + 1), strconv.FormatInt(s.Id, 10), s.Name, artistNames(s.Ar), s.Al.Name, fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)})
+		}
+	case []weapi.CloudSearchRespAlbum:
+		v.Header = []string{"#", "ID", "NAME", "ARTIST", "SIZE", "PUBLISHED"}
+		for i, s := range list {
+			v.Rows = append(v.Rows, []string{strconv.Itoa(i + 1), strconv.FormatInt(s.Id, 10), s.Name, s.Artist.Name, strconv.FormatInt(s.Size, 10), time.UnixMilli(s.PublishTime).Format(time.DateOnly)})
+		}
+	case []weapi.CloudSearchRespArtist:
+		v.Header = []string{"#", "ID", "NAME", "ALBUMS", "ALIAS"}
+		for i, s := range list {
+			v.Rows = append(v.Rows, []string{strconv.Itoa(i + 1), strconv.FormatInt(s.Id, 10), s.Name, strconv.FormatInt(s.AlbumSize, 10), strings.Join(s.Alias, "/")})
+		}
+	case []weapi.CloudSearchRespPlaylist:
+		v.Header = []string{"#", "ID", "NAME", "TRACKS", "PLAYS", "CREATOR"}
+		for i, s := range list {
+			v.Rows = append(v.Rows, []string{strconv.Itoa(i + 1), strconv.FormatInt(s.Id, 10), s.Name, strconv.FormatInt(s.TrackCount, 10), strconv.FormatInt(s.PlayCount, 10), s.Creator.Nickname})
+		}
+	case []weapi.CloudSearchRespDjRadio:
+		v.Header = []string{"#", "ID", "NAME", "PROGRAMS", "DJ"}
+		for i, s := range list {
+			v.Rows = append(v.Rows, []string{strconv.Itoa(i + 1), strconv.FormatInt(s.Id, 10), s.Name, strconv.FormatInt(s.ProgramCount, 10), s.Dj.Nickname})
+		}
+	}
+	return v
+}
+
+// sources 将搜索结果转换为 download 命令可识别的输入
+func (c *Search) sources(result any) []string {
+	var list []string
+	switch v := result.(type) {
+	case []weapi.CloudSearchRespSong:
+		for _, s := range v {
+			list = append(list, strconv.FormatInt(s.Id, 10))
+		}
+	case []weapi.CloudSearchRespAlbum:
+		for _, s := range v {
+			list = append(list, fmt.Sprintf("https://music.163.com/album?id=%d", s.Id))
+		}
+	case []weapi.CloudSearchRespArtist:
+		for _, s := range v {
+			list = append(list, fmt.Sprintf("https://music.163.com/artist?id=%d", s.Id))
+		}
+	case []weapi.CloudSearchRespPlaylist:
+		for _, s := range v {
+			list = append(list, fmt.Sprintf("https://music.163.com/playlist?id=%d", s.Id))
+		}
+	}
+	return list
+}
+
+// parseSelection 解析用户选择的序号,支持逗号分隔及区间,例如 1,3-5
+func parseSelection(s string, n int) ([]int, error) {
+	var (
+		list []int
+		set  = make(map[int]struct{})
+	)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var from, to = part, part
+		if i := strings.Index(part, "-"); i > 0 {
+			from, to = part[:i], part[i+1:]
+		}
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a number", from)
+		}
+		end, err := strconv.Atoi(strings.TrimSpace(to))
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a number", to)
+		}
+		if start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("%s out of range 1-%d", part, n)
+		}
+		for i := start; i <= end; i++ {
+			if _, ok := set[i]; !ok {
+				set[i] = struct{}{}
+				list = append(list, i)
+			}
+		}
+	}
+	if len(list) <= 0 {
+		return nil, fmt.Errorf("nothing selected")
+	}
+	return list, nil
+}
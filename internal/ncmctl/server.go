@@ -0,0 +1,599 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/resolver"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/ncm/archive"
+	"github.com/chaunsin/netease-cloud-music/pkg/nohup"
+	"github.com/chaunsin/netease-cloud-music/pkg/normalize"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// serverQueueSize 等待执行的下载任务数量上限
+const serverQueueSize = 100
+
+// serverJobHistory 保留的最近下载任务数量,超出后丢弃最早的任务。
+// 任务按提交顺序执行,未完成的任务最多 serverQueueSize+1 个且总是最新的,因此不会被丢弃
+const serverJobHistory = 2 * serverQueueSize
+
+// 下载任务状态
+const (
+	jobQueued  = "queued"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+type ServerOpts struct {
+	Addr        string // 监听地址
+	Token       string // 访问令牌,为空时不校验
+	AllowOrigin string // 允许跨域访问的来源,为空时不允许跨域
+	Output      string // 下载输出目录
+	Level       string // 默认下载品质
+}
+
+type Server struct {
+	root *Root
+	cmd  *cobra.Command
+	opts ServerOpts
+	l    *log.Logger
+
+	cli      *api.Client
+	request  *weapi.Api
+	resolver *resolver.Resolver
+	mu       sync.Mutex
+	seq      int64
+	jobs     []*serverJob
+	queue    chan *serverJob
+}
+
+// serverJob 通过接口提交的下载任务
+type serverJob struct {
+	Id        int64     `json:"id"`
+	Sources   []string  `json:"sources"`
+	Level     string    `json:"level"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	StartedAt time.Time `json:"startedAt,omitzero"`
+	EndedAt   time.Time `json:"endedAt,omitzero"`
+}
+
+// serverSongUrl 歌曲播放地址,歌曲不可播放时Error为不可播放原因
+type serverSongUrl struct {
+	Id     int64     `json:"id"`
+	Url    string    `json:"url,omitempty"`
+	Level  string    `json:"level,omitempty"`
+	Br     int64     `json:"br,omitempty"`
+	Size   int64     `json:"size,omitempty"`
+	Md5    string    `json:"md5,omitempty"`
+	Type   string    `json:"type,omitempty"`
+	Expire time.Time `json:"expire,omitzero"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// serverError 接口错误,code同时作为http状态码
+type serverError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *serverError) Error() string {
+	return e.Message
+}
+
+func badRequest(format string, args ...any) error {
+	return &serverError{Code: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewServer(root *Root, l *log.Logger) *Server {
+	c := &Server{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "server",
+			Short: "Run a local http api server so that other programs can drive this client",
+			Example: `  ncmctl server
+  ncmctl server --addr :3000 --token secret -o ./download
+  curl 'http://127.0.0.1:3000/search?keywords=晴天'
+  curl -X POST 'http://127.0.0.1:3000/download?id=2161154646'`,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *Server) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Addr, "addr", "127.0.0.1:3000", "listen address")
+	c.cmd.Flags().StringVar(&c.opts.Token, "token", "", "access token, requests must carry 'Authorization: Bearer <token>' or ?token=<token>. empty to disable")
+	c.cmd.Flags().StringVar(&c.opts.AllowOrigin, "allow-origin", "", "value of Access-Control-Allow-Origin for web frontends, eg: * or http://localhost:8080. empty to disable")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./download", "music file output path of download jobs")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "default song quality level of download jobs. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+}
+
+func (c *Server) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Server) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Server) execute(ctx context.Context) error {
+	c.root.enableMetrics()
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	c.cli = cli
+	c.request = weapi.New(cli)
+	c.resolver = resolver.New(c.request, &resolver.Options{EncodeType: "flac"})
+	c.queue = make(chan *serverJob, serverQueueSize)
+
+	// 先监听端口,以便端口被占用等错误能直接返回
+	ln, err := net.Listen("tcp", c.opts.Addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go c.worker(ctx)
+
+	var srv = &http.Server{Handler: c.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("[server] serve: %s", err)
+		}
+	}()
+	c.cmd.Printf("api server listening on http://%s\n", ln.Addr())
+	log.Info("[server] listening on %s", ln.Addr())
+
+	nohup.Daemon(nohup.CloseHook(func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	}))
+	return nil
+}
+
+// handler 注册接口路由,路径及参数尽量与 NeteaseCloudMusicApi 保持一致
+func (c *Server) handler() http.Handler {
+	var mux = http.NewServeMux()
+	mux.HandleFunc("GET /search", c.handle(c.search))
+	mux.HandleFunc("GET /song/url", c.handle(c.songUrl))
+	mux.HandleFunc("GET /lyric", c.handle(c.lyric))
+	mux.HandleFunc("GET /playlist/detail", c.handle(c.playlistDetail))
+	mux.HandleFunc("GET /login/status", c.handle(c.loginStatus))
+	mux.HandleFunc("POST /download", c.handle(c.enqueue))
+	mux.HandleFunc("GET /download/jobs", c.handle(c.listJobs))
+	mux.HandleFunc("GET /album/zip", c.zip(c.albumSongs))
+	mux.HandleFunc("GET /playlist/zip", c.zip(c.playlistSongs))
+	if c.root.monitor != nil {
+		mux.Handle("GET /metrics", c.root.monitor.reg)
+	}
+	return c.middleware(mux)
+}
+
+// middleware 处理跨域及访问令牌校验
+func (c *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.opts.AllowOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", c.opts.AllowOrigin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		if c.opts.Token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				token = r.URL.Query().Get("token")
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(c.opts.Token)) != 1 {
+				writeJson(w, http.StatusUnauthorized, &serverError{Code: http.StatusUnauthorized, Message: "invalid token"})
+				return
+			}
+		}
+		log.Debug("[server] %s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handle 将处理函数的返回结果以json格式输出,错误转换为对应的状态码
+func (c *Server) handle(fn func(r *http.Request) (int, any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		code, data, err := fn(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeJson(w, code, data)
+	}
+}
+
+// writeError 将错误转换为对应的状态码以json格式输出
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var e *serverError
+	switch {
+	case errors.As(err, &e):
+	case errors.Is(err, api.ErrNeedLogin):
+		e = &serverError{Code: http.StatusUnauthorized, Message: err.Error()}
+	default:
+		e = &serverError{Code: http.StatusInternalServerError, Message: err.Error()}
+	}
+	log.Warn("[server] %s %s: %s", r.Method, r.URL.Path, err)
+	writeJson(w, e.Code, e)
+}
+
+func writeJson(w http.ResponseWriter, code int, data any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Warn("[server] write response: %s", err)
+	}
+}
+
+// queryInt 解析整数查询参数,为空时返回默认值
+func queryInt(r *http.Request, key string, def int64) (int64, error) {
+	var v = r.URL.Query().Get(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, badRequest("%s must be a number", key)
+	}
+	return n, nil
+}
+
+// queryIds 解析逗号分隔的id列表
+func queryIds(r *http.Request, key string) ([]int64, error) {
+	var ids []int64
+	for _, v := range strings.Split(r.URL.Query().Get(key), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, badRequest("%s must be numbers separated by comma", key)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) <= 0 {
+		return nil, badRequest("%s is required", key)
+	}
+	return ids, nil
+}
+
+// search 搜索 /search?keywords=晴天&type=song&limit=30&offset=0
+func (c *Server) search(r *http.Request) (int, any, error) {
+	var query = r.URL.Query()
+	keywords := query.Get("keywords")
+	if keywords == "" {
+		return 0, nil, badRequest("keywords is required")
+	}
+	var kind = query.Get("type")
+	if kind == "" {
+		kind = "song"
+	}
+	typ, ok := searchTypes[kind]
+	if !ok {
+		return 0, nil, badRequest("unsupported search type: %s", kind)
+	}
+	limit, err := queryInt(r, "limit", 30)
+	if err != nil {
+		return 0, nil, err
+	}
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	resp, err := c.request.CloudSearch(r.Context(), &weapi.CloudSearchReq{S: keywords, Type: typ, Limit: limit, Offset: offset, Total: true})
+	if err != nil {
+		return 0, nil, fmt.Errorf("CloudSearch: %w", err)
+	}
+	return http.StatusOK, resp, nil
+}
+
+// songUrl 获取歌曲播放地址 /song/url?id=2161154646,1&level=exhigh
+func (c *Server) songUrl(r *http.Request) (int, any, error) {
+	ids, err := queryIds(r, "id")
+	if err != nil {
+		return 0, nil, err
+	}
+	var level = types.Level(r.URL.Query().Get("level"))
+	if level == "" {
+		level = types.LevelExhigh
+	}
+
+	var list = make([]serverSongUrl, 0, len(ids))
+	for _, id := range ids {
+		stream, err := c.resolver.StreamURL(r.Context(), id, level)
+		if errors.Is(err, resolver.ErrNotFound) || errors.Is(err, resolver.ErrNoCopyright) || errors.Is(err, resolver.ErrNoSource) {
+			list = append(list, serverSongUrl{Id: id, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("StreamURL(%v): %w", id, err)
+		}
+		list = append(list, serverSongUrl{
+			Id:     id,
+			Url:    stream.Url,
+			Level:  string(stream.Level),
+			Br:     stream.Br,
+			Size:   stream.Size,
+			Md5:    stream.Md5,
+			Type:   stream.Type,
+			Expire: stream.Expire,
+		})
+	}
+	return http.StatusOK, list, nil
+}
+
+// lyric 获取歌词 /lyric?id=2161154646
+func (c *Server) lyric(r *http.Request) (int, any, error) {
+	id, err := queryInt(r, "id", 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	if id <= 0 {
+		return 0, nil, badRequest("id is required")
+	}
+
+	resp, err := c.request.Lyric(r.Context(), &weapi.LyricReq{Id: id})
+	if err != nil {
+		return 0, nil, fmt.Errorf("Lyric: %w", err)
+	}
+	return http.StatusOK, resp, nil
+}
+
+// playlistDetail 获取歌单详情 /playlist/detail?id=3778678
+func (c *Server) playlistDetail(r *http.Request) (int, any, error) {
+	id, err := queryInt(r, "id", 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	if id <= 0 {
+		return 0, nil, badRequest("id is required")
+	}
+
+	resp, err := c.request.PlaylistDetail(r.Context(), &weapi.PlaylistDetailReq{Id: strconv.FormatInt(id, 10)})
+	if err != nil {
+		return 0, nil, fmt.Errorf("PlaylistDetail: %w", err)
+	}
+	return http.StatusOK, resp, nil
+}
+
+// loginStatus 获取当前账号登录状态 /login/status
+func (c *Server) loginStatus(r *http.Request) (int, any, error) {
+	resp, err := c.request.GetUserInfo(r.Context(), &weapi.GetUserInfoReq{})
+	if err != nil {
+		return 0, nil, fmt.Errorf("GetUserInfo: %w", err)
+	}
+	return http.StatusOK, map[string]any{"login": resp.Profile != nil, "account": resp.Account, "profile": resp.Profile}, nil
+}
+
+// enqueue 提交下载任务 POST /download?id=2161154646,https://music.163.com/album?id=34608111&level=SQ
+// id 支持 download 命令可识别的歌曲id及各类分享链接
+func (c *Server) enqueue(r *http.Request) (int, any, error) {
+	if err := r.ParseForm(); err != nil {
+		return 0, nil, badRequest("ParseForm: %s", err)
+	}
+	var sources []string
+	for _, v := range r.Form["id"] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				sources = append(sources, s)
+			}
+		}
+	}
+	if len(sources) <= 0 {
+		return 0, nil, badRequest("id is required")
+	}
+	var level = r.Form.Get("level")
+	if level == "" {
+		level = c.opts.Level
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	var job = &serverJob{Id: c.seq, Sources: sources, Level: level, Status: jobQueued, CreatedAt: time.Now()}
+	select {
+	case c.queue <- job:
+	default:
+		return 0, nil, &serverError{Code: http.StatusServiceUnavailable, Message: "too many download jobs in queue"}
+	}
+	c.jobs = append(c.jobs, job)
+	if n := len(c.jobs) - serverJobHistory; n > 0 {
+		c.jobs = slices.Delete(c.jobs, 0, n)
+	}
+	return http.StatusAccepted, *job, nil
+}
+
+// listJobs 查看下载任务 /download/jobs
+func (c *Server) listJobs(r *http.Request) (int, any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var list = make([]serverJob, 0, len(c.jobs))
+	for _, job := range c.jobs {
+		list = append(list, *job)
+	}
+	return http.StatusOK, list, nil
+}
+
+// zip 将专辑或歌单中已下载到输出目录的歌曲打包为zip输出,ncm文件解密后打包,
+// songs根据请求返回名称及歌曲列表,例如 /album/zip?id=34608111
+func (c *Server) zip(songs func(r *http.Request, id int64) (string, []Music, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := queryInt(r, "id", 0)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if id <= 0 {
+			writeError(w, r, badRequest("id is required"))
+			return
+		}
+		name, list, err := songs(r, id)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		files, err := c.localSongs(list)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if len(files) <= 0 {
+			writeError(w, r, &serverError{Code: http.StatusNotFound, Message: fmt.Sprintf("no song of %s found in %s", name, c.opts.Output)})
+			return
+		}
+		log.Info("[server] zip %s: %d/%d songs", name, len(files), len(list))
+		if err := archive.Serve(w, utils.Filename(name, "_"), files); err != nil {
+			log.Warn("[server] %s %s: %s", r.Method, r.URL.Path, err)
+		}
+	}
+}
+
+// albumSongs 获取专辑名称及歌曲
+func (c *Server) albumSongs(r *http.Request, id int64) (string, []Music, error) {
+	resp, err := c.request.Album(r.Context(), &weapi.AlbumReq{Id: strconv.FormatInt(id, 10)})
+	if err != nil {
+		return "", nil, fmt.Errorf("Album: %w", err)
+	}
+	var list = make([]Music, 0, len(resp.Songs))
+	for _, v := range resp.Songs {
+		list = append(list, Music{Id: v.Id, Name: v.Name, Artist: v.Ar, Album: v.Al, AlbumId: v.Al.Id, Time: v.Dt})
+	}
+	return resp.Album.Name, list, nil
+}
+
+// playlistSongs 获取歌单名称及歌曲
+func (c *Server) playlistSongs(r *http.Request, id int64) (string, []Music, error) {
+	return playlistSongs(r.Context(), c.root, c.request, id)
+}
+
+// localSongs 按download生成的文件名规则在输出目录中查找歌曲,同一首歌曲优先使用非ncm文件,未下载的歌曲跳过
+func (c *Server) localSongs(songs []Music) ([]archive.File, error) {
+	n, err := normalize.New(c.root.Cfg.Normalize)
+	if err != nil {
+		return nil, fmt.Errorf("normalize: %w", err)
+	}
+	var local = make(map[string]string)
+	err = filepath.WalkDir(c.opts.Output, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		var ext = strings.ToLower(filepath.Ext(path))
+		if d.IsDir() || (ext != ".ncm" && !utils.IsMusicExt(path)) {
+			return nil
+		}
+		var key = strings.TrimSuffix(d.Name(), filepath.Ext(path))
+		if exist, ok := local[key]; ok && !strings.EqualFold(filepath.Ext(exist), ".ncm") {
+			return nil
+		}
+		local[key] = path
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("WalkDir: %w", err)
+	}
+
+	var files = make([]archive.File, 0, len(songs))
+	for _, song := range songs {
+		song = normalizeMusic(n, song)
+		var name = fmt.Sprintf("%s - %s", song.ArtistString(), song.NameString())
+		if path, ok := local[name]; ok {
+			files = append(files, archive.File{Path: path, Name: name})
+		}
+	}
+	return files, nil
+}
+
+// worker 依次执行下载任务,避免多个任务同时下载触发风控
+func (c *Server) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-c.queue:
+			c.update(job, func(j *serverJob) {
+				j.Status, j.StartedAt = jobRunning, time.Now()
+			})
+
+			d := NewDownload(c.root, c.l)
+			d.opts.Output = c.opts.Output
+			d.opts.Level = job.Level
+			d.opts.Progress = progressNone
+			err := d.execute(ctx, job.Sources)
+			if err != nil {
+				log.Warn("[server] download job %d: %s", job.Id, err)
+			}
+			c.root.monitor.taskDone("server", "download", utils.Ternary(err != nil, "failed", "success"), time.Since(job.StartedAt))
+
+			c.update(job, func(j *serverJob) {
+				j.Status, j.EndedAt = jobDone, time.Now()
+				if err != nil {
+					j.Status, j.Error = jobFailed, err.Error()
+				}
+			})
+		}
+	}
+}
+
+func (c *Server) update(job *serverJob, fn func(j *serverJob)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(job)
+}
@@ -0,0 +1,146 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	qrcode2 "github.com/skip2/go-qrcode"
+	"github.com/spf13/cobra"
+)
+
+type ShareOpts struct {
+	Short  bool   // 是否生成短链接
+	Invert bool   // 终端二维码反色显示
+	Output string // 二维码图片输出路径
+	Level  int    // 二维码恢复能力等级
+}
+
+type Share struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+	opts ShareOpts
+}
+
+func NewShare(root *Root, l *log.Logger) *Share {
+	c := &Share{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "share",
+			Short: "Share song, artist, album or playlist by terminal qrcode",
+			Example: "  ncmctl share 1955097630\n" +
+				"  ncmctl share https://music.163.com/#/playlist?id=2829883282 --short\n" +
+				"  ncmctl share https://music.163.com/album?id=34608111 -o ./share.png",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args)
+	}
+	return c
+}
+
+func (c *Share) addFlags() {
+	c.cmd.Flags().BoolVarP(&c.opts.Short, "short", "s", false, "generate short link")
+	c.cmd.Flags().BoolVar(&c.opts.Invert, "invert", false, "invert terminal qrcode color, use it when the terminal background is light")
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "", "qrcode image output file path, eg: ./share.png")
+	c.cmd.Flags().IntVarP(&c.opts.Level, "level", "l", 1, "qrcode recovery capacity,0->7% 1->15%(default) 2->25% 3->30%")
+}
+
+func (c *Share) validate() error {
+	if c.opts.Level < 0 || c.opts.Level > 3 {
+		return fmt.Errorf("qrcode level must be 0-3")
+	}
+	return nil
+}
+
+func (c *Share) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Share) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Share) execute(ctx context.Context, args []string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	if len(args) <= 0 {
+		return fmt.Errorf("nothing was entered")
+	}
+
+	kind, id, err := Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("Parse: %w", err)
+	}
+	var link = fmt.Sprintf("https://music.163.com/%s?id=%d", kind, id)
+
+	if c.opts.Short {
+		cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+		if err != nil {
+			return fmt.Errorf("NewClient: %w", err)
+		}
+		defer cli.Close(ctx)
+		request := weapi.New(cli)
+
+		// 短链接生成失败时不影响分享,使用原始链接即可
+		resp, err := request.ShareShortUrl(ctx, &weapi.ShareShortUrlReq{Url: link})
+		switch {
+		case err != nil:
+			log.Warn("ShareShortUrl(%s) err: %s", link, err)
+		case resp.Data.ShortUrl == "":
+			log.Warn("ShareShortUrl(%s) resp: %+v", link, resp)
+		default:
+			link = resp.Data.ShortUrl
+		}
+	}
+
+	qr, err := qrcode2.New(link, qrcode2.RecoveryLevel(c.opts.Level))
+	if err != nil {
+		return fmt.Errorf("qrcode: %w", err)
+	}
+	c.cmd.Printf("%s\n", qr.ToSmallString(c.opts.Invert))
+	c.cmd.Printf("share %s: %s\n", kind, link)
+
+	if c.opts.Output != "" {
+		data, err := qr.PNG(256)
+		if err != nil {
+			return fmt.Errorf("PNG: %w", err)
+		}
+		if err := os.WriteFile(c.opts.Output, data, os.ModePerm); err != nil {
+			return fmt.Errorf("WriteFile: %w", err)
+		}
+		c.cmd.Printf("qrcode file: %s\n", c.opts.Output)
+	}
+	return nil
+}
@@ -100,9 +100,6 @@ func (c *SignIn) execute(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("YunBeiSignIn: %w", err)
 	}
-	if resp.Code != 200 {
-		return fmt.Errorf("YunBeiSignIn: %+v", resp)
-	}
 	if resp.Data.Sign {
 		c.cmd.Println("云贝签到成功")
 	} else {
@@ -135,28 +132,12 @@ func (c *SignIn) execute(ctx context.Context) error {
 		}
 
 		// 完成当前时刻可以领取的任务奖励
-		task, err := request.YunBeiTaskTodo(ctx, &weapi.YunBeiTaskTodoReq{})
+		claimed, err := claimYunBeiTasks(ctx, request)
 		if err != nil {
-			return fmt.Errorf("YunBeiTaskTodo: %w", err)
+			return err
 		}
-		for _, v := range task.Data {
-			if !v.Completed {
-				continue
-			}
-
-			reply, err := request.YunBeiTaskFinish(ctx, &weapi.YunBeiTaskFinishReq{
-				Period:      fmt.Sprintf("%d", v.Period),
-				UserTaskId:  fmt.Sprintf("%d", v.UserTaskId),
-				DepositCode: fmt.Sprintf("%d", v.DepositCode),
-			})
-			if err != nil {
-				log.Error("YunBeiTaskFinish(%v): %w", v.UserTaskId, err)
-			}
-			if reply.Code != 200 {
-				log.Error("YunBeiTaskFinish(%v) detail:%+v", v.UserTaskId, reply)
-			} else {
-				c.cmd.Printf("云贝 [%s] 任务完成获得云贝数量 %v\n", v.TaskName, v.TaskPoint)
-			}
+		for _, v := range claimed {
+			c.cmd.Printf("云贝 [%s] 任务完成获得云贝数量 %v\n", v.TaskName, v.TaskPoint)
 		}
 	}
 
@@ -165,9 +146,6 @@ func (c *SignIn) execute(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("VipGrowPoint: %w", err)
 	}
-	if vip.Code != 200 {
-		return fmt.Errorf("VipGrowPoint: %+v", vip)
-	}
 	if vip.Data.UserLevel.LatestVipStatus != 1 {
 		c.cmd.Printf("暂无会员权益: %v\n", vip.Data.UserLevel.LatestVipStatus)
 		return nil
@@ -202,9 +180,8 @@ func (c *SignIn) execute(ctx context.Context) error {
 	}
 
 	// 刷新token过期时间
-	refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-	if err != nil || refresh.Code != 200 {
-		log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+	if _, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{}); err != nil {
+		log.Warn("TokenRefresh: %s", err)
 	}
 	return nil
 }
@@ -0,0 +1,207 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type DailySignInOpts struct {
+	Scrobble bool // 是否执行每日听歌300首任务
+}
+
+type DailySignIn struct {
+	root *Root
+	cmd  *cobra.Command
+	opts DailySignInOpts
+	l    *log.Logger
+}
+
+func NewDailySignIn(root *Root, l *log.Logger) *DailySignIn {
+	c := &DailySignIn{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "signin",
+			Short: "[need login] Daily PC and mobile sign-in, suitable for running from cron",
+			Long: "Daily PC and mobile sign-in, and report points earned.\n\n" +
+				"Exit codes:\n" +
+				"  0  all sign-in succeeded or already signed in today\n" +
+				"  1  unexpected error\n" +
+				"  2  need login\n" +
+				"  3  part of the sign-in failed",
+			Example: "  ncmctl signin\n  ncmctl signin --scrobble",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *DailySignIn) addFlags() {
+	c.cmd.Flags().BoolVar(&c.opts.Scrobble, "scrobble", false, "also run the daily listen 300 songs task")
+}
+
+func (c *DailySignIn) validate() error {
+	return nil
+}
+
+func (c *DailySignIn) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *DailySignIn) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *DailySignIn) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	request := weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	before, err := request.YunBeiUserInfo(ctx, &weapi.YunBeiUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("YunBeiUserInfo: %w", err)
+	}
+
+	var (
+		failed  int
+		results []signinResult
+		format  = c.root.Opts.Output
+		list    = []struct {
+			name   string
+			kind   int64
+			signed bool
+		}{
+			{name: "pc", kind: 1, signed: before.PcSign},
+			{name: "mobile", kind: 0, signed: before.MobileSign},
+		}
+	)
+	// report 记录任务结果,未指定 --output-format 时直接输出便于阅读的文本
+	var report = func(r signinResult) {
+		if r.Status == signinFailed {
+			failed++
+		}
+		results = append(results, r)
+		if format != "" {
+			return
+		}
+		switch r.Status {
+		case signinOk:
+			c.cmd.Printf("[%s] sign in success, points: %d\n", r.Task, r.Points)
+		case signinSigned:
+			c.cmd.Printf("[%s] already signed in today\n", r.Task)
+		default:
+			c.cmd.Printf("[%s] failed: %s\n", r.Task, r.Error)
+		}
+	}
+	for _, v := range list {
+		if v.signed {
+			report(signinResult{Task: v.name, Status: signinSigned})
+			continue
+		}
+		resp, err := request.SignIn(ctx, &weapi.SignInReq{Type: v.kind})
+		if err != nil {
+			report(signinResult{Task: v.name, Status: signinFailed, Error: err.Error()})
+			continue
+		}
+		switch resp.Code {
+		case 200:
+			report(signinResult{Task: v.name, Status: signinOk, Points: resp.Point})
+		case -2: // 重复签到
+			report(signinResult{Task: v.name, Status: signinSigned})
+		default:
+			report(signinResult{Task: v.name, Status: signinFailed, Error: resp.Err().Error()})
+		}
+	}
+
+	// 听歌300首任务
+	if c.opts.Scrobble {
+		s := NewScrobble(c.root, c.l)
+		s.opts.Num = 300
+		s.cmd.SetOut(c.cmd.OutOrStdout())
+		if err := s.execute(ctx); err != nil {
+			report(signinResult{Task: "scrobble", Status: signinFailed, Error: err.Error()})
+		} else {
+			results = append(results, signinResult{Task: "scrobble", Status: signinOk})
+		}
+	}
+
+	after, err := request.YunBeiUserInfo(ctx, &weapi.YunBeiUserInfoReq{})
+	if err != nil {
+		log.Warn("YunBeiUserInfo err: %s", err)
+	} else if format == "" {
+		c.cmd.Printf("points earned: %d, balance: %d\n", after.UserPoint.Balance-before.UserPoint.Balance, after.UserPoint.Balance)
+	}
+
+	if format != "" {
+		var v = view{Data: results, Header: []string{"TASK", "STATUS", "POINTS", "ERROR"}}
+		for _, r := range results {
+			v.Rows = append(v.Rows, []string{r.Task, r.Status, strconv.FormatInt(r.Points, 10), r.Error})
+		}
+		if err := render(c.cmd.OutOrStdout(), format, v); err != nil {
+			return fmt.Errorf("render: %w", err)
+		}
+	}
+
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d sign-in failed", failed)}
+	}
+	return nil
+}
+
+const (
+	signinOk     = "ok"
+	signinSigned = "signed" // 今日已签到
+	signinFailed = "failed"
+)
+
+// signinResult 单项签到任务的执行结果
+type signinResult struct {
+	Task   string `json:"task"`
+	Status string `json:"status"`
+	Points int64  `json:"points,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
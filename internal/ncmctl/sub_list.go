@@ -0,0 +1,244 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+// subRecord 导出的收藏记录,不同类型不使用的字段为空
+type subRecord struct {
+	Type     string   `json:"type"`
+	Id       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Artists  []string `json:"artists,omitempty"`
+	Album    string   `json:"album,omitempty"`
+	Count    int64    `json:"count,omitempty"`    // 歌手为专辑数量,专辑为歌曲数量
+	Duration int64    `json:"duration,omitempty"` // 歌曲时长毫秒
+	Time     int64    `json:"time,omitempty"`     // 收藏时间毫秒
+}
+
+type subListCmd struct {
+	root *Sub
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	kind   string // artist、album、song
+	format string // table、csv、json
+	output string
+}
+
+func subList(root *Sub, l *log.Logger) *cobra.Command {
+	c := &subListCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "list",
+		Short: "[need login] List subscribed artists, albums or liked songs and export to csv/json",
+		Example: "  ncmctl sub list --type artist\n" +
+			"  ncmctl sub list --type album --format csv -o albums.csv\n" +
+			"  ncmctl sub list --type song --format json | jq '.[].name'",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *subListCmd) addFlags() {
+	c.cmd.Flags().StringVar(&c.kind, "type", "artist", "subscription type, support: artist、album、song(liked songs)")
+	c.cmd.Flags().StringVar(&c.format, "format", "table", "output format, support: table、csv、json")
+	c.cmd.Flags().StringVarP(&c.output, "output", "o", "-", "output file path, '-' means stdout")
+}
+
+func (c *subListCmd) validate() error {
+	switch c.kind {
+	case "artist", "album", "song":
+	default:
+		return fmt.Errorf("type is not support: %s", c.kind)
+	}
+	switch c.format {
+	case "table", "csv", "json":
+	default:
+		return fmt.Errorf("format is not support: %s", c.format)
+	}
+	return nil
+}
+
+func (c *subListCmd) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var records []subRecord
+	switch c.kind {
+	case "artist":
+		list, err := artistSublist(ctx, request)
+		if err != nil {
+			return err
+		}
+		for _, v := range list {
+			records = append(records, subRecord{Type: c.kind, Id: v.Id, Name: v.Name, Count: v.AlbumSize})
+		}
+	case "album":
+		list, err := albumSublist(ctx, request)
+		if err != nil {
+			return err
+		}
+		for _, v := range list {
+			var artists = make([]string, 0, len(v.Artists))
+			for _, ar := range v.Artists {
+				artists = append(artists, ar.Name)
+			}
+			records = append(records, subRecord{Type: c.kind, Id: v.Id, Name: v.Name, Artists: artists, Count: v.Size, Time: v.SubTime})
+		}
+	case "song":
+		pid, err := likedPlaylistId(ctx, request, user.Account.Id)
+		if err != nil {
+			return err
+		}
+		_, songs, err := playlistSongs(ctx, c.root.root, request, pid)
+		if err != nil {
+			return err
+		}
+		for _, s := range songs {
+			var artists = make([]string, 0, len(s.Artist))
+			for _, ar := range s.Artist {
+				artists = append(artists, ar.Name)
+			}
+			records = append(records, subRecord{Type: c.kind, Id: s.Id, Name: s.Name, Artists: artists, Album: s.Album.Name, Duration: s.Time})
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if c.output != "-" {
+		f, err := os.Create(c.output)
+		if err != nil {
+			return fmt.Errorf("Create: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeSubRecords(w, c.format, records); err != nil {
+		return err
+	}
+	if c.output != "-" {
+		c.cmd.Printf("export %d %ss to %s\n", len(records), c.kind, c.output)
+	}
+	return nil
+}
+
+// writeSubRecords 按格式输出收藏记录,csv包含表头便于导入其他音乐平台
+func writeSubRecords(w io.Writer, format string, records []subRecord) error {
+	if format == "json" {
+		var encoder = json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if records == nil {
+			records = []subRecord{}
+		}
+		return encoder.Encode(records)
+	}
+
+	var header = []string{"TYPE", "ID", "NAME", "ARTISTS", "ALBUM", "COUNT", "DURATION", "TIME"}
+	var row = func(r subRecord) []string {
+		var duration, subTime string
+		if r.Duration > 0 {
+			var d = time.Duration(r.Duration) * time.Millisecond
+			duration = fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+		}
+		if r.Time > 0 {
+			subTime = time.UnixMilli(r.Time).Format(time.DateTime)
+		}
+		return []string{r.Type, strconv.FormatInt(r.Id, 10), r.Name, strings.Join(r.Artists, "/"), r.Album, strconv.FormatInt(r.Count, 10), duration, subTime}
+	}
+
+	if format == "csv" {
+		var cw = csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := cw.Write(row(r)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	var tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, r := range records {
+		fmt.Fprintln(tw, strings.Join(row(r), "\t"))
+	}
+	return tw.Flush()
+}
+
+// albumSublist 获取收藏的全部专辑
+func albumSublist(ctx context.Context, request *weapi.Api) ([]weapi.AlbumSublistRespData, error) {
+	var list []weapi.AlbumSublistRespData
+	for {
+		resp, err := request.AlbumSublist(ctx, &weapi.AlbumSublistReq{Limit: 100, Offset: int64(len(list)), Total: true})
+		if err != nil {
+			return nil, fmt.Errorf("AlbumSublist: %w", err)
+		}
+		list = append(list, resp.Data...)
+		if !resp.HasMore || len(resp.Data) <= 0 {
+			return list, nil
+		}
+	}
+}
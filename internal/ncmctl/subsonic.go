@@ -0,0 +1,935 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/resolver"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/nohup"
+	"github.com/chaunsin/netease-cloud-music/pkg/scrobbler"
+
+	"github.com/spf13/cobra"
+)
+
+// subsonicVersion 兼容的Subsonic接口版本
+const subsonicVersion = "1.16.1"
+
+// Subsonic 错误码 see: https://www.subsonic.org/pages/api.jsp
+const (
+	subsonicErrGeneric      = 0
+	subsonicErrMissingParam = 10
+	subsonicErrWrongAuth    = 40
+	subsonicErrNotFound     = 70
+)
+
+// 资源id前缀,歌曲id不加前缀
+const (
+	subsonicAlbumPrefix    = "al-"
+	subsonicArtistPrefix   = "ar-"
+	subsonicPlaylistPrefix = "pl-"
+)
+
+type SubsonicOpts struct {
+	Addr     string // 监听地址
+	User     string // 客户端登录用户名
+	Password string // 客户端登录密码
+	Level    string // 播放品质
+}
+
+type Subsonic struct {
+	root *Root
+	cmd  *cobra.Command
+	opts SubsonicOpts
+	l    *log.Logger
+
+	cli       *api.Client
+	request   *weapi.Api
+	resolver  *resolver.Resolver
+	uid       int64
+	covers    sync.Map // 封面id与图片地址映射
+	forwarder *scrobbler.Forwarder
+}
+
+func NewSubsonic(root *Root, l *log.Logger) *Subsonic {
+	c := &Subsonic{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "subsonic",
+			Short: "[need login] Run a Subsonic compatible server so that apps like DSub/Symfonium can play the NetEase library",
+			Example: `  ncmctl subsonic --user admin --password secret
+  ncmctl subsonic --addr :4533 --user admin --password secret --level exhigh`,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *Subsonic) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Addr, "addr", "127.0.0.1:4533", "listen address")
+	c.cmd.Flags().StringVarP(&c.opts.User, "user", "u", "admin", "username used by subsonic clients to login")
+	c.cmd.Flags().StringVar(&c.opts.Password, "password", os.Getenv("NCMCTL_SUBSONIC_PASSWORD"), "password used by subsonic clients to login. also can be set by NCMCTL_SUBSONIC_PASSWORD env")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelExhigh), "stream quality level, lowered by the maxBitRate of clients. support: standard、higher、exhigh、lossless、hires")
+}
+
+func (c *Subsonic) validate() error {
+	if c.opts.User == "" || c.opts.Password == "" {
+		return fmt.Errorf("--user and --password are required")
+	}
+	switch types.Level(c.opts.Level) {
+	case types.LevelStandard, types.LevelHigher, types.LevelExhigh, types.LevelLossless, types.LevelHires:
+	default:
+		return fmt.Errorf("unsupported level: %s", c.opts.Level)
+	}
+	return nil
+}
+
+func (c *Subsonic) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Subsonic) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Subsonic) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	c.cli = cli
+	c.request = weapi.New(cli)
+	c.resolver = resolver.New(c.request, &resolver.Options{EncodeType: "flac"})
+	c.forwarder = newForwarder(c.root)
+
+	user, err := c.request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	c.uid = user.Account.Id
+
+	ln, err := net.Listen("tcp", c.opts.Addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	var srv = &http.Server{Handler: c.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("[subsonic] serve: %s", err)
+		}
+	}()
+	c.cmd.Printf("subsonic server listening on http://%s\n", ln.Addr())
+	log.Info("[subsonic] listening on %s", ln.Addr())
+
+	nohup.Daemon(nohup.CloseHook(func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	}))
+	return nil
+}
+
+// subsonicHandler 接口处理函数,返回nil且未出错时表示已自行输出响应内容,例如音频流
+type subsonicHandler func(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error
+
+func (c *Subsonic) handler() http.Handler {
+	var methods = map[string]subsonicHandler{
+		"ping":                      c.ping,
+		"getLicense":                c.getLicense,
+		"getUser":                   c.getUser,
+		"getOpenSubsonicExtensions": c.getOpenSubsonicExtensions,
+		"getMusicFolders":           c.getMusicFolders,
+		"getIndexes":                c.getIndexes,
+		"getArtists":                c.getArtists,
+		"getMusicDirectory":         c.getMusicDirectory,
+		"getArtist":                 c.getArtist,
+		"getAlbum":                  c.getAlbum,
+		"getSong":                   c.getSong,
+		"getAlbumList":              c.getAlbumList,
+		"getAlbumList2":             c.getAlbumList2,
+		"getPlaylists":              c.getPlaylists,
+		"getPlaylist":               c.getPlaylist,
+		"getStarred":                c.getStarred,
+		"getStarred2":               c.getStarred2,
+		"search2":                   c.search2,
+		"search3":                   c.search3,
+		"stream":                    c.stream,
+		"download":                  c.stream,
+		"getCoverArt":               c.getCoverArt,
+		"scrobble":                  c.scrobble,
+	}
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/rest/{method}", func(w http.ResponseWriter, r *http.Request) {
+		var (
+			name = strings.TrimSuffix(r.PathValue("method"), ".view")
+			resp = &subsonicResponse{Status: "ok"}
+		)
+		if err := r.ParseForm(); err != nil {
+			resp.fail(subsonicErrGeneric, err.Error())
+			c.write(w, r, resp)
+			return
+		}
+		log.Debug("[subsonic] %s %s client=%s", r.Method, name, r.Form.Get("c"))
+
+		fn, ok := methods[name]
+		switch {
+		case !c.auth(r):
+			resp.fail(subsonicErrWrongAuth, "Wrong username or password")
+		case !ok:
+			resp.fail(subsonicErrGeneric, fmt.Sprintf("method %s is not supported", name))
+		default:
+			if err := fn(w, r, resp); err != nil {
+				var e *subsonicError
+				if !errors.As(err, &e) {
+					e = &subsonicError{Code: subsonicErrGeneric, Message: err.Error()}
+				}
+				log.Warn("[subsonic] %s: %s", name, err)
+				resp.fail(e.Code, e.Message)
+			} else if resp.Status == "" {
+				return
+			}
+		}
+		c.write(w, r, resp)
+	})
+	return mux
+}
+
+// auth 校验客户端账号,支持明文密码(含enc:十六进制编码)及token方式 token=md5(password+salt)
+func (c *Subsonic) auth(r *http.Request) bool {
+	var form = r.Form
+	if form.Get("u") != c.opts.User {
+		return false
+	}
+	if token, salt := form.Get("t"), form.Get("s"); token != "" {
+		sum := md5.Sum([]byte(c.opts.Password + salt))
+		return subtle.ConstantTimeCompare([]byte(strings.ToLower(token)), []byte(hex.EncodeToString(sum[:]))) == 1
+	}
+	var password = form.Get("p")
+	if v, ok := strings.CutPrefix(password, "enc:"); ok {
+		data, err := hex.DecodeString(v)
+		if err != nil {
+			return false
+		}
+		password = string(data)
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(c.opts.Password)) == 1
+}
+
+// write 按客户端指定的f参数输出xml或json格式响应
+func (c *Subsonic) write(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) {
+	resp.Version = subsonicVersion
+	resp.Type = "ncmctl"
+	resp.ServerVersion = c.root.version
+	resp.OpenSubsonic = true
+
+	var err error
+	switch f := r.Form.Get("f"); f {
+	case "json":
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err = json.NewEncoder(w).Encode(map[string]any{"subsonic-response": resp})
+	case "jsonp":
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		var data []byte
+		if data, err = json.Marshal(map[string]any{"subsonic-response": resp}); err == nil {
+			_, err = fmt.Fprintf(w, "%s(%s);", r.Form.Get("callback"), data)
+		}
+	default:
+		resp.Xmlns = "http://subsonic.org/restapi"
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		if _, err = io.WriteString(w, xml.Header); err == nil {
+			err = xml.NewEncoder(w).Encode(resp)
+		}
+	}
+	if err != nil {
+		log.Warn("[subsonic] write response: %s", err)
+	}
+}
+
+// param 获取必填参数
+func param(r *http.Request, key string) (string, error) {
+	var v = r.Form.Get(key)
+	if v == "" {
+		return "", &subsonicError{Code: subsonicErrMissingParam, Message: fmt.Sprintf("Required parameter is missing: %s", key)}
+	}
+	return v, nil
+}
+
+// paramInt 获取整数参数,为空时返回默认值
+func paramInt(r *http.Request, key string, def int64) int64 {
+	v, err := strconv.ParseInt(r.Form.Get(key), 10, 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// parseSubsonicId 解析带前缀的资源id
+func parseSubsonicId(id, prefix string) (int64, error) {
+	v, err := strconv.ParseInt(strings.TrimPrefix(id, prefix), 10, 64)
+	if err != nil {
+		return 0, &subsonicError{Code: subsonicErrNotFound, Message: fmt.Sprintf("invalid id: %s", id)}
+	}
+	return v, nil
+}
+
+func (c *Subsonic) ping(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	return nil
+}
+
+func (c *Subsonic) getLicense(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	resp.License = &subsonicLicense{Valid: true}
+	return nil
+}
+
+func (c *Subsonic) getUser(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	resp.User = &subsonicUser{
+		Username:          c.opts.User,
+		ScrobblingEnabled: true,
+		StreamRole:        true,
+		DownloadRole:      true,
+		CoverArtRole:      true,
+		Folder:            []int64{1},
+	}
+	return nil
+}
+
+func (c *Subsonic) getOpenSubsonicExtensions(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	resp.OpenSubsonicExtensions = []subsonicExtension{}
+	return nil
+}
+
+func (c *Subsonic) getMusicFolders(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	resp.MusicFolders = &subsonicMusicFolders{MusicFolder: []subsonicMusicFolder{{Id: 1, Name: "NetEase Cloud Music"}}}
+	return nil
+}
+
+// artistIndexes 将收藏的歌手按首字母分组
+func (c *Subsonic) artistIndexes(ctx context.Context) (*subsonicIndexes, error) {
+	var artists []weapi.ArtistSublistRespData
+	for offset := int64(0); ; offset += 100 {
+		list, err := c.request.ArtistSublist(ctx, &weapi.ArtistSublistReq{Limit: 100, Offset: offset, Total: true})
+		if err != nil {
+			return nil, fmt.Errorf("ArtistSublist: %w", err)
+		}
+		artists = append(artists, list.Data...)
+		if !list.HasMore || len(list.Data) <= 0 {
+			break
+		}
+	}
+
+	var (
+		groups  = make(map[string][]subsonicArtist)
+		indexes = &subsonicIndexes{LastModified: time.Now().UnixMilli(), IgnoredArticles: "The El La Los Las Le Les"}
+	)
+	for _, a := range artists {
+		var name = "#"
+		if r := []rune(strings.TrimSpace(a.Name)); len(r) > 0 && r[0] < unicode.MaxASCII && unicode.IsLetter(r[0]) {
+			name = strings.ToUpper(string(r[0]))
+		}
+		groups[name] = append(groups[name], c.artist(a.Id, a.Name, a.PicUrl, a.AlbumSize))
+	}
+	for name, list := range groups {
+		indexes.Index = append(indexes.Index, subsonicIndex{Name: name, Artist: list})
+	}
+	sort.Slice(indexes.Index, func(i, j int) bool { return indexes.Index[i].Name < indexes.Index[j].Name })
+	return indexes, nil
+}
+
+func (c *Subsonic) getIndexes(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	indexes, err := c.artistIndexes(r.Context())
+	if err != nil {
+		return err
+	}
+	resp.Indexes = indexes
+	return nil
+}
+
+func (c *Subsonic) getArtists(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	indexes, err := c.artistIndexes(r.Context())
+	if err != nil {
+		return err
+	}
+	resp.Artists = indexes
+	return nil
+}
+
+// artistAlbums 获取歌手信息及专辑列表
+func (c *Subsonic) artistAlbums(ctx context.Context, id int64) (*subsonicArtist, error) {
+	var artist = &subsonicArtist{Id: subsonicArtistPrefix + strconv.FormatInt(id, 10)}
+	for offset := int64(0); ; offset += 100 {
+		list, err := c.request.ArtistAlbums(ctx, &weapi.ArtistAlbumsReq{Id: id, Limit: 100, Offset: offset, Total: true})
+		if err != nil {
+			return nil, fmt.Errorf("ArtistAlbums(%v): %w", id, err)
+		}
+		for _, a := range list.HotAlbums {
+			if artist.Name == "" {
+				artist.Name = a.Artist.Name
+			}
+			artist.Album = append(artist.Album, c.album(a.Id, a.Name, a.PicUrl, a.Artist, a.Size, a.PublishTime))
+		}
+		if !list.More || len(list.HotAlbums) <= 0 {
+			break
+		}
+	}
+	artist.AlbumCount = int64(len(artist.Album))
+	return artist, nil
+}
+
+func (c *Subsonic) getArtist(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	v, err := param(r, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseSubsonicId(v, subsonicArtistPrefix)
+	if err != nil {
+		return err
+	}
+	artist, err := c.artistAlbums(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	resp.Artist = artist
+	return nil
+}
+
+// albumSongs 获取专辑信息及歌曲列表
+func (c *Subsonic) albumSongs(ctx context.Context, id int64) (*subsonicAlbum, error) {
+	detail, err := c.request.Album(ctx, &weapi.AlbumReq{Id: strconv.FormatInt(id, 10)})
+	if err != nil {
+		return nil, fmt.Errorf("Album(%v): %w", id, err)
+	}
+	var (
+		a     = detail.Album
+		album = c.album(a.Id, a.Name, a.PicUrl, types.Artist{Id: a.Artist.Id, Name: a.Artist.Name}, int64(len(detail.Songs)), a.PublishTime)
+	)
+	for _, s := range detail.Songs {
+		s.Al.PicUrl = a.PicUrl
+		var song = c.song(Music{Id: s.Id, Name: s.Name, Artist: s.Ar, Album: s.Al, Time: s.Dt, Track: s.No, PublishTime: a.PublishTime})
+		album.Duration += song.Duration
+		album.Song = append(album.Song, song)
+	}
+	return &album, nil
+}
+
+func (c *Subsonic) getAlbum(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	v, err := param(r, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseSubsonicId(v, subsonicAlbumPrefix)
+	if err != nil {
+		return err
+	}
+	album, err := c.albumSongs(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	resp.Album = album
+	return nil
+}
+
+// getMusicDirectory 按目录方式浏览,歌手目录下为专辑,专辑目录下为歌曲
+func (c *Subsonic) getMusicDirectory(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	v, err := param(r, "id")
+	if err != nil {
+		return err
+	}
+	switch {
+	case strings.HasPrefix(v, subsonicArtistPrefix):
+		id, err := parseSubsonicId(v, subsonicArtistPrefix)
+		if err != nil {
+			return err
+		}
+		artist, err := c.artistAlbums(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		resp.Directory = &subsonicDirectory{Id: artist.Id, Name: artist.Name}
+		for _, a := range artist.Album {
+			resp.Directory.Child = append(resp.Directory.Child, a.child())
+		}
+	case strings.HasPrefix(v, subsonicAlbumPrefix):
+		id, err := parseSubsonicId(v, subsonicAlbumPrefix)
+		if err != nil {
+			return err
+		}
+		album, err := c.albumSongs(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		resp.Directory = &subsonicDirectory{Id: album.Id, Parent: album.ArtistId, Name: album.Name, Child: album.Song}
+	default:
+		return &subsonicError{Code: subsonicErrNotFound, Message: fmt.Sprintf("directory not found: %s", v)}
+	}
+	return nil
+}
+
+func (c *Subsonic) getSong(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	v, err := param(r, "id")
+	if err != nil {
+		return err
+	}
+	songs, err := c.songs(r.Context(), []string{v})
+	if err != nil {
+		return err
+	}
+	if len(songs) <= 0 {
+		return &subsonicError{Code: subsonicErrNotFound, Message: fmt.Sprintf("song not found: %s", v)}
+	}
+	resp.Song = &songs[0]
+	return nil
+}
+
+// songs 根据歌曲id获取歌曲详情
+func (c *Subsonic) songs(ctx context.Context, ids []string) ([]subsonicChild, error) {
+	list, err := NewDownload(c.root, c.l).inputParse(ctx, ids, c.request)
+	if err != nil {
+		return nil, fmt.Errorf("inputParse: %w", err)
+	}
+	var songs = make([]subsonicChild, 0, len(list))
+	for _, m := range list {
+		songs = append(songs, c.song(m))
+	}
+	return songs, nil
+}
+
+// subscribedAlbums 获取收藏的专辑,offset及size为Subsonic分页参数
+func (c *Subsonic) subscribedAlbums(ctx context.Context, offset, size int64) ([]subsonicAlbum, error) {
+	if size <= 0 || size > 500 {
+		size = 10
+	}
+	list, err := c.request.AlbumSublist(ctx, &weapi.AlbumSublistReq{Limit: size, Offset: offset, Total: true})
+	if err != nil {
+		return nil, fmt.Errorf("AlbumSublist: %w", err)
+	}
+	var albums = make([]subsonicAlbum, 0, len(list.Data))
+	for _, a := range list.Data {
+		var artist types.Artist
+		if len(a.Artists) > 0 {
+			artist = a.Artists[0]
+		}
+		album := c.album(a.Id, a.Name, a.PicUrl, artist, a.Size, 0)
+		album.Created = time.UnixMilli(a.SubTime).Format(time.RFC3339)
+		albums = append(albums, album)
+	}
+	return albums, nil
+}
+
+// getAlbumList 网易云没有与Subsonic排序方式对应的专辑列表,统一返回收藏的专辑
+func (c *Subsonic) getAlbumList(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	albums, err := c.subscribedAlbums(r.Context(), paramInt(r, "offset", 0), paramInt(r, "size", 10))
+	if err != nil {
+		return err
+	}
+	resp.AlbumList = &subsonicAlbumList{Album: []subsonicChild{}}
+	for _, a := range albums {
+		resp.AlbumList.Album = append(resp.AlbumList.Album, a.child())
+	}
+	return nil
+}
+
+func (c *Subsonic) getAlbumList2(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	albums, err := c.subscribedAlbums(r.Context(), paramInt(r, "offset", 0), paramInt(r, "size", 10))
+	if err != nil {
+		return err
+	}
+	resp.AlbumList2 = &subsonicAlbumList2{Album: albums}
+	return nil
+}
+
+func (c *Subsonic) getPlaylists(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	list, err := userPlaylists(r.Context(), c.request, c.uid)
+	if err != nil {
+		return err
+	}
+	resp.Playlists = &subsonicPlaylists{Playlist: []subsonicPlaylist{}}
+	for _, p := range list {
+		var id = subsonicPlaylistPrefix + strconv.FormatInt(p.Id, 10)
+		c.covers.Store(id, p.CoverImgUrl)
+		resp.Playlists.Playlist = append(resp.Playlists.Playlist, subsonicPlaylist{
+			Id:        id,
+			Name:      p.Name,
+			Owner:     p.Creator.Nickname,
+			Public:    true,
+			SongCount: p.TrackCount,
+			Created:   time.UnixMilli(p.CreateTime).Format(time.RFC3339),
+			Changed:   playlistUpdateTime(p).Format(time.RFC3339),
+			CoverArt:  id,
+		})
+	}
+	return nil
+}
+
+func (c *Subsonic) getPlaylist(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	v, err := param(r, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseSubsonicId(v, subsonicPlaylistPrefix)
+	if err != nil {
+		return err
+	}
+	name, songs, err := playlistSongs(r.Context(), c.root, c.request, id)
+	if err != nil {
+		return err
+	}
+	var playlist = &subsonicPlaylist{Id: subsonicPlaylistPrefix + strconv.FormatInt(id, 10), Name: name, Public: true, CoverArt: subsonicPlaylistPrefix + strconv.FormatInt(id, 10)}
+	for _, m := range songs {
+		var song = c.song(m)
+		playlist.Duration += song.Duration
+		playlist.Entry = append(playlist.Entry, song)
+	}
+	playlist.SongCount = int64(len(playlist.Entry))
+	resp.Playlist = playlist
+	return nil
+}
+
+// starred 喜欢的歌曲、收藏的专辑及歌手
+func (c *Subsonic) starred(ctx context.Context) (*subsonicSearchResult3, error) {
+	pid, err := likedPlaylistId(ctx, c.request, c.uid)
+	if err != nil {
+		return nil, err
+	}
+	_, songs, err := playlistSongs(ctx, c.root, c.request, pid)
+	if err != nil {
+		return nil, err
+	}
+	albums, err := c.subscribedAlbums(ctx, 0, 500)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := c.artistIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = &subsonicSearchResult3{Album: albums}
+	for _, index := range indexes.Index {
+		result.Artist = append(result.Artist, index.Artist...)
+	}
+	for _, m := range songs {
+		result.Song = append(result.Song, c.song(m))
+	}
+	return result, nil
+}
+
+func (c *Subsonic) getStarred(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	result, err := c.starred(r.Context())
+	if err != nil {
+		return err
+	}
+	resp.Starred = result.legacy()
+	return nil
+}
+
+func (c *Subsonic) getStarred2(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	result, err := c.starred(r.Context())
+	if err != nil {
+		return err
+	}
+	resp.Starred2 = result
+	return nil
+}
+
+// search 分别搜索歌手、专辑及歌曲
+func (c *Subsonic) search(ctx context.Context, r *http.Request) (*subsonicSearchResult3, error) {
+	// 客户端同步曲库时可能传入空字符串或""
+	var query = strings.Trim(strings.TrimSpace(r.Form.Get("query")), `"`)
+	var result = &subsonicSearchResult3{}
+	if query == "" {
+		return result, nil
+	}
+
+	var search = func(typ weapi.SearchType, count, offset int64) (*weapi.CloudSearchResp, error) {
+		if count <= 0 {
+			return nil, nil
+		}
+		resp, err := c.request.CloudSearch(ctx, &weapi.CloudSearchReq{S: query, Type: typ, Limit: min(count, 100), Offset: offset})
+		if err != nil {
+			return nil, fmt.Errorf("CloudSearch: %w", err)
+		}
+		return resp, nil
+	}
+
+	artists, err := search(weapi.SearchTypeArtist, paramInt(r, "artistCount", 20), paramInt(r, "artistOffset", 0))
+	if err != nil {
+		return nil, err
+	}
+	if artists != nil {
+		for _, a := range artists.Result.Artists {
+			result.Artist = append(result.Artist, c.artist(a.Id, a.Name, a.PicUrl, a.AlbumSize))
+		}
+	}
+	albums, err := search(weapi.SearchTypeAlbum, paramInt(r, "albumCount", 20), paramInt(r, "albumOffset", 0))
+	if err != nil {
+		return nil, err
+	}
+	if albums != nil {
+		for _, a := range albums.Result.Albums {
+			result.Album = append(result.Album, c.album(a.Id, a.Name, a.PicUrl, a.Artist, a.Size, a.PublishTime))
+		}
+	}
+	songs, err := search(weapi.SearchTypeSong, paramInt(r, "songCount", 20), paramInt(r, "songOffset", 0))
+	if err != nil {
+		return nil, err
+	}
+	if songs != nil {
+		for _, s := range songs.Result.Songs {
+			result.Song = append(result.Song, c.song(Music{Id: s.Id, Name: s.Name, Artist: s.Ar, Album: s.Al, Time: s.Dt, PublishTime: s.PublishTime}))
+		}
+	}
+	return result, nil
+}
+
+func (c *Subsonic) search2(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	result, err := c.search(r.Context(), r)
+	if err != nil {
+		return err
+	}
+	resp.SearchResult2 = result.legacy()
+	return nil
+}
+
+func (c *Subsonic) search3(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	result, err := c.search(r.Context(), r)
+	if err != nil {
+		return err
+	}
+	resp.SearchResult3 = result
+	return nil
+}
+
+// level 根据客户端限制的最大码率(kbps)选择播放品质
+func (c *Subsonic) level(maxBitRate int64) types.Level {
+	switch {
+	case maxBitRate <= 0:
+		return types.Level(c.opts.Level)
+	case maxBitRate <= 128:
+		return types.LevelStandard
+	case maxBitRate <= 192:
+		return types.LevelHigher
+	case maxBitRate <= 320:
+		return types.LevelExhigh
+	default:
+		return types.Level(c.opts.Level)
+	}
+}
+
+// stream 获取歌曲播放地址并代理音频流,支持客户端Range请求拖动进度
+func (c *Subsonic) stream(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	v, err := param(r, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseSubsonicId(v, "")
+	if err != nil {
+		return err
+	}
+
+	var ctx = r.Context()
+	stream, err := c.resolver.StreamURL(ctx, id, c.level(paramInt(r, "maxBitRate", 0)))
+	if errors.Is(err, resolver.ErrNotFound) || errors.Is(err, resolver.ErrNoCopyright) || errors.Is(err, resolver.ErrNoSource) {
+		return &subsonicError{Code: subsonicErrNotFound, Message: fmt.Sprintf("song %v is unavailable", id)}
+	}
+	if err != nil {
+		return fmt.Errorf("StreamURL(%v): %w", id, err)
+	}
+	log.Debug("[subsonic] stream %v level=%s br=%v type=%s", id, stream.Level, stream.Br, stream.Type)
+
+	if err := proxyStream(w, r, c.cli, stream.Url); err != nil {
+		// 地址可能已失效,下次请求重新获取
+		c.resolver.Invalidate(id)
+		return err
+	}
+	resp.Status = ""
+	return nil
+}
+
+// getCoverArt 代理封面图片,size参数使用网易云图片缩放参数
+func (c *Subsonic) getCoverArt(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	v, err := param(r, "id")
+	if err != nil {
+		return err
+	}
+	url, ok := c.covers.Load(v)
+	if !ok && strings.HasPrefix(v, subsonicAlbumPrefix) {
+		// 服务重启后缓存丢失,专辑封面可重新获取
+		id, err := parseSubsonicId(v, subsonicAlbumPrefix)
+		if err != nil {
+			return err
+		}
+		if _, err := c.albumSongs(r.Context(), id); err != nil {
+			return err
+		}
+		url, ok = c.covers.Load(v)
+	}
+	if !ok || url.(string) == "" {
+		return &subsonicError{Code: subsonicErrNotFound, Message: fmt.Sprintf("cover art not found: %s", v)}
+	}
+
+	var link = url.(string)
+	if size := paramInt(r, "size", 0); size > 0 {
+		link = fmt.Sprintf("%s?param=%dy%d", link, size, size)
+	}
+	if err := proxyStream(w, r, c.cli, link); err != nil {
+		return err
+	}
+	resp.Status = ""
+	return nil
+}
+
+// scrobble 播放完成时上报听歌记录,正在播放的通知忽略
+func (c *Subsonic) scrobble(w http.ResponseWriter, r *http.Request, resp *subsonicResponse) error {
+	if r.Form.Get("submission") == "false" {
+		return nil
+	}
+	ids := r.Form["id"]
+	if len(ids) <= 0 {
+		return &subsonicError{Code: subsonicErrMissingParam, Message: "Required parameter is missing: id"}
+	}
+	list, err := NewDownload(c.root, c.l).inputParse(r.Context(), ids, c.request)
+	if err != nil {
+		return fmt.Errorf("inputParse: %w", err)
+	}
+	// 客户端提交的time为开始播放时间,多首歌曲时依次对应
+	var times = r.Form["time"]
+	for i, m := range list {
+		var played = time.Duration(m.Time) * time.Millisecond
+		reportPlay(r.Context(), c.request, m, "list", played)
+
+		var start = time.Now().Add(-played)
+		if i < len(times) {
+			if ms, err := strconv.ParseInt(times[i], 10, 64); err == nil && ms > 0 {
+				start = time.UnixMilli(ms)
+			}
+		}
+		forwardPlay(r.Context(), c.forwarder, m, start, played)
+	}
+	return nil
+}
+
+// artist 转换为Subsonic歌手并记录封面地址
+func (c *Subsonic) artist(id int64, name, pic string, albums int64) subsonicArtist {
+	var sid = subsonicArtistPrefix + strconv.FormatInt(id, 10)
+	var artist = subsonicArtist{Id: sid, Name: name, AlbumCount: albums}
+	if pic != "" {
+		c.covers.Store(sid, pic)
+		artist.CoverArt = sid
+	}
+	return artist
+}
+
+// album 转换为Subsonic专辑并记录封面地址
+func (c *Subsonic) album(id int64, name, pic string, artist types.Artist, size, publish int64) subsonicAlbum {
+	var sid = subsonicAlbumPrefix + strconv.FormatInt(id, 10)
+	var album = subsonicAlbum{
+		Id:        sid,
+		Name:      name,
+		Artist:    artist.Name,
+		SongCount: size,
+		Created:   time.UnixMilli(publish).Format(time.RFC3339),
+	}
+	if artist.Id > 0 {
+		album.ArtistId = subsonicArtistPrefix + strconv.FormatInt(artist.Id, 10)
+	}
+	if publish > 0 {
+		album.Year = int64(time.UnixMilli(publish).Year())
+	}
+	if pic != "" {
+		c.covers.Store(sid, pic)
+		album.CoverArt = sid
+	}
+	return album
+}
+
+// song 转换为Subsonic歌曲,音频格式取决于播放品质
+func (c *Subsonic) song(m Music) subsonicChild {
+	var albumId = m.Album.Id
+	if albumId == 0 {
+		albumId = m.AlbumId
+	}
+	var song = subsonicChild{
+		Id:          strconv.FormatInt(m.Id, 10),
+		Title:       m.Name,
+		Album:       m.Album.Name,
+		Artist:      artistNames(m.Artist),
+		Track:       m.Track,
+		Duration:    m.Time / 1000,
+		Suffix:      "mp3",
+		ContentType: "audio/mpeg",
+		Type:        "music",
+	}
+	switch types.Level(c.opts.Level) {
+	case types.LevelLossless, types.LevelHires:
+		song.Suffix, song.ContentType = "flac", "audio/flac"
+	}
+	if albumId > 0 {
+		song.AlbumId = subsonicAlbumPrefix + strconv.FormatInt(albumId, 10)
+		song.Parent = song.AlbumId
+		if m.Album.PicUrl != "" {
+			c.covers.Store(song.AlbumId, m.Album.PicUrl)
+			song.CoverArt = song.AlbumId
+		}
+	}
+	if len(m.Artist) > 0 && m.Artist[0].Id > 0 {
+		song.ArtistId = subsonicArtistPrefix + strconv.FormatInt(m.Artist[0].Id, 10)
+	}
+	if m.PublishTime > 0 {
+		song.Year = int64(time.UnixMilli(m.PublishTime).Year())
+	}
+	return song
+}
@@ -0,0 +1,232 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"encoding/xml"
+)
+
+// subsonicResponse Subsonic接口响应,xml格式时列表为子元素其他字段为属性,json格式时外层包裹subsonic-response
+// see: https://www.subsonic.org/pages/api.jsp https://opensubsonic.netlify.app/docs/responses/
+type subsonicResponse struct {
+	XMLName       xml.Name `xml:"subsonic-response" json:"-"`
+	Xmlns         string   `xml:"xmlns,attr" json:"-"`
+	Status        string   `xml:"status,attr" json:"status"`
+	Version       string   `xml:"version,attr" json:"version"`
+	Type          string   `xml:"type,attr" json:"type"`
+	ServerVersion string   `xml:"serverVersion,attr" json:"serverVersion"`
+	OpenSubsonic  bool     `xml:"openSubsonic,attr" json:"openSubsonic"`
+
+	Error                  *subsonicError         `xml:"error,omitempty" json:"error,omitempty"`
+	License                *subsonicLicense       `xml:"license,omitempty" json:"license,omitempty"`
+	User                   *subsonicUser          `xml:"user,omitempty" json:"user,omitempty"`
+	OpenSubsonicExtensions []subsonicExtension    `xml:"openSubsonicExtensions,omitempty" json:"openSubsonicExtensions,omitempty"`
+	MusicFolders           *subsonicMusicFolders  `xml:"musicFolders,omitempty" json:"musicFolders,omitempty"`
+	Indexes                *subsonicIndexes       `xml:"indexes,omitempty" json:"indexes,omitempty"`
+	Artists                *subsonicIndexes       `xml:"artists,omitempty" json:"artists,omitempty"`
+	Directory              *subsonicDirectory     `xml:"directory,omitempty" json:"directory,omitempty"`
+	Artist                 *subsonicArtist        `xml:"artist,omitempty" json:"artist,omitempty"`
+	Album                  *subsonicAlbum         `xml:"album,omitempty" json:"album,omitempty"`
+	Song                   *subsonicChild         `xml:"song,omitempty" json:"song,omitempty"`
+	AlbumList              *subsonicAlbumList     `xml:"albumList,omitempty" json:"albumList,omitempty"`
+	AlbumList2             *subsonicAlbumList2    `xml:"albumList2,omitempty" json:"albumList2,omitempty"`
+	Playlists              *subsonicPlaylists     `xml:"playlists,omitempty" json:"playlists,omitempty"`
+	Playlist               *subsonicPlaylist      `xml:"playlist,omitempty" json:"playlist,omitempty"`
+	Starred                *subsonicSearchResult2 `xml:"starred,omitempty" json:"starred,omitempty"`
+	Starred2               *subsonicSearchResult3 `xml:"starred2,omitempty" json:"starred2,omitempty"`
+	SearchResult2          *subsonicSearchResult2 `xml:"searchResult2,omitempty" json:"searchResult2,omitempty"`
+	SearchResult3          *subsonicSearchResult3 `xml:"searchResult3,omitempty" json:"searchResult3,omitempty"`
+}
+
+// fail 设置错误响应
+func (r *subsonicResponse) fail(code int, message string) {
+	r.Status = "failed"
+	r.Error = &subsonicError{Code: code, Message: message}
+}
+
+type subsonicError struct {
+	Code    int    `xml:"code,attr" json:"code"`
+	Message string `xml:"message,attr" json:"message"`
+}
+
+func (e *subsonicError) Error() string {
+	return e.Message
+}
+
+type subsonicLicense struct {
+	Valid bool `xml:"valid,attr" json:"valid"`
+}
+
+type subsonicUser struct {
+	Username          string  `xml:"username,attr" json:"username"`
+	ScrobblingEnabled bool    `xml:"scrobblingEnabled,attr" json:"scrobblingEnabled"`
+	AdminRole         bool    `xml:"adminRole,attr" json:"adminRole"`
+	SettingsRole      bool    `xml:"settingsRole,attr" json:"settingsRole"`
+	DownloadRole      bool    `xml:"downloadRole,attr" json:"downloadRole"`
+	UploadRole        bool    `xml:"uploadRole,attr" json:"uploadRole"`
+	PlaylistRole      bool    `xml:"playlistRole,attr" json:"playlistRole"`
+	CoverArtRole      bool    `xml:"coverArtRole,attr" json:"coverArtRole"`
+	CommentRole       bool    `xml:"commentRole,attr" json:"commentRole"`
+	PodcastRole       bool    `xml:"podcastRole,attr" json:"podcastRole"`
+	StreamRole        bool    `xml:"streamRole,attr" json:"streamRole"`
+	JukeboxRole       bool    `xml:"jukeboxRole,attr" json:"jukeboxRole"`
+	ShareRole         bool    `xml:"shareRole,attr" json:"shareRole"`
+	Folder            []int64 `xml:"folder" json:"folder"`
+}
+
+type subsonicExtension struct {
+	Name     string  `xml:"name,attr" json:"name"`
+	Versions []int64 `xml:"versions" json:"versions"`
+}
+
+type subsonicMusicFolders struct {
+	MusicFolder []subsonicMusicFolder `xml:"musicFolder" json:"musicFolder"`
+}
+
+type subsonicMusicFolder struct {
+	Id   int64  `xml:"id,attr" json:"id"`
+	Name string `xml:"name,attr" json:"name"`
+}
+
+type subsonicIndexes struct {
+	LastModified    int64           `xml:"lastModified,attr" json:"lastModified"`
+	IgnoredArticles string          `xml:"ignoredArticles,attr" json:"ignoredArticles"`
+	Index           []subsonicIndex `xml:"index" json:"index"`
+}
+
+type subsonicIndex struct {
+	Name   string           `xml:"name,attr" json:"name"`
+	Artist []subsonicArtist `xml:"artist" json:"artist"`
+}
+
+type subsonicArtist struct {
+	Id         string          `xml:"id,attr" json:"id"`
+	Name       string          `xml:"name,attr" json:"name"`
+	CoverArt   string          `xml:"coverArt,attr,omitempty" json:"coverArt,omitempty"`
+	AlbumCount int64           `xml:"albumCount,attr" json:"albumCount"`
+	Album      []subsonicAlbum `xml:"album,omitempty" json:"album,omitempty"`
+}
+
+type subsonicAlbum struct {
+	Id        string          `xml:"id,attr" json:"id"`
+	Name      string          `xml:"name,attr" json:"name"`
+	Artist    string          `xml:"artist,attr,omitempty" json:"artist,omitempty"`
+	ArtistId  string          `xml:"artistId,attr,omitempty" json:"artistId,omitempty"`
+	CoverArt  string          `xml:"coverArt,attr,omitempty" json:"coverArt,omitempty"`
+	SongCount int64           `xml:"songCount,attr" json:"songCount"`
+	Duration  int64           `xml:"duration,attr" json:"duration"`
+	Year      int64           `xml:"year,attr,omitempty" json:"year,omitempty"`
+	Created   string          `xml:"created,attr" json:"created"`
+	Song      []subsonicChild `xml:"song,omitempty" json:"song,omitempty"`
+}
+
+// child 转换为目录方式浏览时的专辑目录
+func (a subsonicAlbum) child() subsonicChild {
+	return subsonicChild{
+		Id:       a.Id,
+		Parent:   a.ArtistId,
+		IsDir:    true,
+		Title:    a.Name,
+		Album:    a.Name,
+		Artist:   a.Artist,
+		Year:     a.Year,
+		CoverArt: a.CoverArt,
+		AlbumId:  a.Id,
+		ArtistId: a.ArtistId,
+	}
+}
+
+// subsonicChild 歌曲或目录
+type subsonicChild struct {
+	Id          string `xml:"id,attr" json:"id"`
+	Parent      string `xml:"parent,attr,omitempty" json:"parent,omitempty"`
+	IsDir       bool   `xml:"isDir,attr" json:"isDir"`
+	Title       string `xml:"title,attr" json:"title"`
+	Album       string `xml:"album,attr,omitempty" json:"album,omitempty"`
+	Artist      string `xml:"artist,attr,omitempty" json:"artist,omitempty"`
+	Track       int64  `xml:"track,attr,omitempty" json:"track,omitempty"`
+	Year        int64  `xml:"year,attr,omitempty" json:"year,omitempty"`
+	CoverArt    string `xml:"coverArt,attr,omitempty" json:"coverArt,omitempty"`
+	ContentType string `xml:"contentType,attr,omitempty" json:"contentType,omitempty"`
+	Suffix      string `xml:"suffix,attr,omitempty" json:"suffix,omitempty"`
+	Duration    int64  `xml:"duration,attr,omitempty" json:"duration,omitempty"`
+	AlbumId     string `xml:"albumId,attr,omitempty" json:"albumId,omitempty"`
+	ArtistId    string `xml:"artistId,attr,omitempty" json:"artistId,omitempty"`
+	Type        string `xml:"type,attr,omitempty" json:"type,omitempty"`
+}
+
+type subsonicDirectory struct {
+	Id     string          `xml:"id,attr" json:"id"`
+	Parent string          `xml:"parent,attr,omitempty" json:"parent,omitempty"`
+	Name   string          `xml:"name,attr" json:"name"`
+	Child  []subsonicChild `xml:"child" json:"child"`
+}
+
+type subsonicAlbumList struct {
+	Album []subsonicChild `xml:"album" json:"album"`
+}
+
+type subsonicAlbumList2 struct {
+	Album []subsonicAlbum `xml:"album" json:"album"`
+}
+
+type subsonicPlaylists struct {
+	Playlist []subsonicPlaylist `xml:"playlist" json:"playlist"`
+}
+
+type subsonicPlaylist struct {
+	Id        string          `xml:"id,attr" json:"id"`
+	Name      string          `xml:"name,attr" json:"name"`
+	Owner     string          `xml:"owner,attr,omitempty" json:"owner,omitempty"`
+	Public    bool            `xml:"public,attr" json:"public"`
+	SongCount int64           `xml:"songCount,attr" json:"songCount"`
+	Duration  int64           `xml:"duration,attr" json:"duration"`
+	Created   string          `xml:"created,attr" json:"created"`
+	Changed   string          `xml:"changed,attr" json:"changed"`
+	CoverArt  string          `xml:"coverArt,attr,omitempty" json:"coverArt,omitempty"`
+	Entry     []subsonicChild `xml:"entry,omitempty" json:"entry,omitempty"`
+}
+
+// subsonicSearchResult2 目录方式的搜索结果,专辑为目录
+type subsonicSearchResult2 struct {
+	Artist []subsonicArtist `xml:"artist" json:"artist"`
+	Album  []subsonicChild  `xml:"album" json:"album"`
+	Song   []subsonicChild  `xml:"song" json:"song"`
+}
+
+// subsonicSearchResult3 ID3方式的搜索结果
+type subsonicSearchResult3 struct {
+	Artist []subsonicArtist `xml:"artist" json:"artist"`
+	Album  []subsonicAlbum  `xml:"album" json:"album"`
+	Song   []subsonicChild  `xml:"song" json:"song"`
+}
+
+// legacy 转换为目录方式的结果
+func (r *subsonicSearchResult3) legacy() *subsonicSearchResult2 {
+	var result = &subsonicSearchResult2{Artist: r.Artist, Song: r.Song}
+	for _, a := range r.Album {
+		result.Album = append(result.Album, a.child())
+	}
+	return result
+}
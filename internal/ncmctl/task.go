@@ -27,6 +27,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
@@ -39,8 +40,9 @@ import (
 )
 
 type TaskOpts struct {
-	Location string
-	RunAll   bool
+	Location    string
+	RunAll      bool
+	AllAccounts bool // 在所有已登录账号上分别执行任务
 
 	Partner            bool
 	PartnerOptsCrontab string
@@ -53,6 +55,10 @@ type TaskOpts struct {
 	SignIn            bool
 	SignInOptsCrontab string
 	SignInOpts
+
+	KeepAlive            bool
+	KeepAliveOptsCrontab string
+	KeepAliveOpts
 }
 
 type Task struct {
@@ -68,8 +74,9 @@ func NewTask(root *Root, l *log.Logger) *Task {
 		l:    l,
 		cmd: &cobra.Command{
 			Use:     "task",
-			Short:   "[need login] Daily tasks are executed asynchronously [partner、scrobble、sign]",
-			Example: `  ncmctl task`,
+			Short:   "[need login] Daily tasks are executed asynchronously [partner、scrobble、sign、keepalive]",
+			Example: `  ncmctl task
+  ncmctl task --sign --all-accounts`,
 		},
 	}
 	c.addFlags()
@@ -82,12 +89,15 @@ func NewTask(root *Root, l *log.Logger) *Task {
 func (c *Task) addFlags() {
 	c.cmd.PersistentFlags().StringVarP(&c.opts.Location, "location", "l", "Asia/Shanghai", "crontab time zone setting")
 	c.cmd.PersistentFlags().BoolVar(&c.opts.RunAll, "runAll", false, "default enabled all task")
+	c.cmd.PersistentFlags().BoolVar(&c.opts.AllAccounts, "all-accounts", false, "run tasks on every logged in profile, each with its own cookie and rate limit")
 
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Partner, "partner", false, "enabled partner task")
 	c.cmd.PersistentFlags().StringVar(&c.opts.PartnerOptsCrontab, "partner.cron", "0 18 * * *", "partner crontab expression. usage detail: https://crontab.guru")
 	c.cmd.PersistentFlags().Int64SliceVar(&c.opts.PartnerOpts.Star, "partner.star", []int64{3, 4}, "set the base song evaluation score level random range 1-5")
 	c.cmd.PersistentFlags().Int64SliceVar(&c.opts.PartnerOpts.ExtStar, "partner.extStar", []int64{2, 3, 4}, "set the extra song evaluation score level random range 1-5")
 	c.cmd.PersistentFlags().StringVar(&c.opts.PartnerOpts.ExtNum, "partner.extNum", "random", "extra evaluation number of songs,'random' means 2 to 7")
+	c.cmd.PersistentFlags().Int64SliceVar(&c.opts.PartnerOpts.Weight, "partner.weight", nil, "set the probability weight of each base score level in partner.star order")
+	c.cmd.PersistentFlags().Int64Var(&c.opts.PartnerOpts.TagNum, "partner.tags", 3, "max number of tags randomly selected for each song 1-5")
 
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Scrobble, "scrobble", false, "enabled scrobble task")
 	c.cmd.PersistentFlags().StringVar(&c.opts.ScrobbleOptsCrontab, "scrobble.cron", "0 18 * * *", "scrobble crontab expression. usage detail: https://crontab.guru")
@@ -96,6 +106,10 @@ func (c *Task) addFlags() {
 	c.cmd.PersistentFlags().BoolVar(&c.opts.SignIn, "sign", false, "enabled sign task")
 	c.cmd.PersistentFlags().StringVar(&c.opts.SignInOptsCrontab, "sign.cron", "0 10 * * *", "sign crontab expression. usage detail: https://crontab.guru")
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Automatic, "sign.automatic", false, "automatically claim sign-in rewards")
+
+	c.cmd.PersistentFlags().BoolVar(&c.opts.KeepAlive, "keepalive", false, "enabled keepalive task")
+	c.cmd.PersistentFlags().StringVar(&c.opts.KeepAliveOptsCrontab, "keepalive.cron", "0 */6 * * *", "keepalive crontab expression. usage detail: https://crontab.guru")
+	c.cmd.PersistentFlags().DurationVar(&c.opts.Threshold, "keepalive.threshold", keepAliveThreshold, "refresh when the login cookie expires within this duration")
 }
 
 func (c *Task) validate() error {
@@ -127,11 +141,20 @@ func (c *Task) validate() error {
 			}
 			return nil
 		}
+		keepAlive = func() error {
+			if c.opts.KeepAliveOptsCrontab == "" {
+				return fmt.Errorf("keepalive.crontab is required")
+			}
+			if _, err := cron.ParseStandard(c.opts.KeepAliveOptsCrontab); err != nil {
+				return fmt.Errorf("ParseStandard: %w", err)
+			}
+			return nil
+		}
 	)
 
 	var o = c.opts
-	if o.RunAll || (!o.SignIn && !o.Partner && !o.Scrobble) {
-		return errors.Join(signIn(), partner(), scrobble())
+	if o.RunAll || (!o.SignIn && !o.Partner && !o.Scrobble && !o.KeepAlive) {
+		return errors.Join(signIn(), partner(), scrobble(), keepAlive())
 	} else {
 		if o.SignIn {
 			if err := signIn(); err != nil {
@@ -148,6 +171,11 @@ func (c *Task) validate() error {
 				return err
 			}
 		}
+		if o.KeepAlive {
+			if err := keepAlive(); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -171,15 +199,24 @@ func (c *Task) execute(ctx context.Context, args []string) error {
 		return fmt.Errorf("wrong time zone: %w", err)
 	}
 
-	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
-	if err != nil {
-		return fmt.Errorf("NewClient: %w", err)
-	}
-	defer cli.Close(ctx)
+	// 指定 --all-accounts 时各账号登录状态由任务执行时检查
+	if c.opts.AllAccounts {
+		names, err := c.root.profileNames()
+		if err != nil {
+			return err
+		}
+		c.cmd.Printf("accounts: %s\n", strings.Join(names, ", "))
+	} else {
+		cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+		if err != nil {
+			return fmt.Errorf("NewClient: %w", err)
+		}
+		defer cli.Close(ctx)
 
-	request := weapi.New(cli)
-	if request.NeedLogin(ctx) {
-		return fmt.Errorf("need login")
+		request := weapi.New(cli)
+		if request.NeedLogin(ctx) {
+			return fmt.Errorf("need login")
+		}
 	}
 
 	var (
@@ -195,12 +232,12 @@ func (c *Task) execute(ctx context.Context, args []string) error {
 			}
 
 			id, err := job.AddFunc(c.opts.PartnerOptsCrontab, func() {
-				log.Info("[partner] task start")
-				if err := partner.Command().ExecuteContext(ctx); err != nil {
-					log.Error("[partner] execute err: %s", err)
-					return
-				}
-				log.Info("[partner] execute success")
+				c.run(ctx, "partner", func(root *Root) *cobra.Command {
+					cmd := NewPartner(root, c.l)
+					cmd.cmd.DisableFlagParsing = true
+					cmd.opts = c.opts.PartnerOpts
+					return cmd.Command()
+				})
 			})
 			if err != nil {
 				return fmt.Errorf("crontab error: %v", err)
@@ -219,12 +256,12 @@ func (c *Task) execute(ctx context.Context, args []string) error {
 			}
 
 			id, err := job.AddFunc(c.opts.ScrobbleOptsCrontab, func() {
-				log.Info("[scrobble] task start")
-				if err := s.Command().ExecuteContext(ctx); err != nil {
-					log.Error("[scrobble] execute err: %s", err)
-					return
-				}
-				log.Info("[scrobble] execute success")
+				c.run(ctx, "scrobble", func(root *Root) *cobra.Command {
+					cmd := NewScrobble(root, c.l)
+					cmd.cmd.DisableFlagParsing = true
+					cmd.opts = c.opts.ScrobbleOpts
+					return cmd.Command()
+				})
 			})
 			if err != nil {
 				return fmt.Errorf("[scrobble] crontab error: %v", err)
@@ -243,12 +280,12 @@ func (c *Task) execute(ctx context.Context, args []string) error {
 			}
 
 			id, err := job.AddFunc(c.opts.SignInOptsCrontab, func() {
-				log.Info("[sign] task start")
-				if err := signIn.Command().ExecuteContext(ctx); err != nil {
-					log.Error("[sign] execute err: %s", err)
-					return
-				}
-				log.Info("[sign] execute success")
+				c.run(ctx, "sign", func(root *Root) *cobra.Command {
+					cmd := NewSignIn(root, c.l)
+					cmd.cmd.DisableFlagParsing = true
+					cmd.opts = c.opts.SignInOpts
+					return cmd.Command()
+				})
 			})
 			if err != nil {
 				return fmt.Errorf("[sign] crontab error: %v", err)
@@ -256,11 +293,35 @@ func (c *Task) execute(ctx context.Context, args []string) error {
 			log.Info("[sign] next execute: %s", job.Entry(id).Schedule.Next(time.Now()))
 			return nil
 		}
+		keepAlive = func() error {
+			c.cmd.Println("[keepalive] task register")
+			log.Info("[keepalive] task register")
+			k := NewKeepAlive(c.root, c.l)
+			k.cmd.DisableFlagParsing = true
+			k.opts = c.opts.KeepAliveOpts
+			if err := k.validate(); err != nil {
+				return fmt.Errorf("validate: %w", err)
+			}
+
+			id, err := job.AddFunc(c.opts.KeepAliveOptsCrontab, func() {
+				c.run(ctx, "keepalive", func(root *Root) *cobra.Command {
+					cmd := NewKeepAlive(root, c.l)
+					cmd.cmd.DisableFlagParsing = true
+					cmd.opts = c.opts.KeepAliveOpts
+					return cmd.Command()
+				})
+			})
+			if err != nil {
+				return fmt.Errorf("[keepalive] crontab error: %v", err)
+			}
+			log.Info("[keepalive] next execute: %s", job.Entry(id).Schedule.Next(time.Now()))
+			return nil
+		}
 	)
 
 	var o = c.opts
-	if o.RunAll || (!o.SignIn && !o.Partner && !o.Scrobble) {
-		if err := errors.Join(signIn(), partner(), scrobble()); err != nil {
+	if o.RunAll || (!o.SignIn && !o.Partner && !o.Scrobble && !o.KeepAlive) {
+		if err := errors.Join(signIn(), partner(), scrobble(), keepAlive()); err != nil {
 			return err
 		}
 	} else {
@@ -279,6 +340,11 @@ func (c *Task) execute(ctx context.Context, args []string) error {
 				return err
 			}
 		}
+		if o.KeepAlive {
+			if err := keepAlive(); err != nil {
+				return err
+			}
+		}
 	}
 
 	job.Start()
@@ -289,3 +355,25 @@ func (c *Task) execute(ctx context.Context, args []string) error {
 	}))
 	return nil
 }
+
+// run 执行任务,指定 --all-accounts 时在所有已登录账号上分别执行,每次执行都创建新的命令实例
+func (c *Task) run(ctx context.Context, name string, command func(root *Root) *cobra.Command) {
+	log.Info("[%s] task start", name)
+	if !c.opts.AllAccounts {
+		if err := command(c.root).ExecuteContext(ctx); err != nil {
+			log.Error("[%s] execute err: %s", name, err)
+			return
+		}
+		log.Info("[%s] execute success", name)
+		return
+	}
+
+	results, err := c.root.eachProfile(ctx, accountCommands[name], func(ctx context.Context, root *Root) error {
+		return command(root).ExecuteContext(ctx)
+	})
+	if err != nil {
+		log.Error("[%s] execute err: %s", name, err)
+		return
+	}
+	_ = reportAccounts(name, results)
+}
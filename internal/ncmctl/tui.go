@@ -0,0 +1,337 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+const tuiHelp = `commands:
+  s [type] <keyword>  search, type support: song、album、artist、playlist (default song)
+  n / p               next / previous search page
+  l                   list my playlists (need login)
+  v                   show the download queue
+  a <1,3-5>           add the selected items of current pane to the queue
+  r <1,3-5>           remove the selected items from the queue
+  d                   download all items in the queue
+  h                   show this help
+  q                   quit`
+
+type TuiOpts struct {
+	Output   string // 下载输出目录
+	Level    string // 下载歌曲品质
+	Parallel int64  // 并发下载数量
+	Limit    int64  // 搜索每页数量
+}
+
+type Tui struct {
+	root *Root
+	cmd  *cobra.Command
+	opts TuiOpts
+	l    *log.Logger
+}
+
+// tuiItem 面板中可加入下载队列的条目
+type tuiItem struct {
+	Kind   string // 类型 song、album、artist、playlist
+	Name   string // 展示名称
+	Source string // download 命令可识别的输入
+}
+
+// tuiState 交互过程中的状态
+type tuiState struct {
+	pane    string // 当前面板 search、playlist、queue
+	items   []tuiItem
+	queue   []tuiItem
+	keyword string
+	page    int64
+	pages   int64
+}
+
+func NewTui(root *Root, l *log.Logger) *Tui {
+	c := &Tui{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "tui",
+			Short: "Interactively browse search results and playlists and queue downloads",
+			Example: "  ncmctl tui\n" +
+				"  ncmctl tui -l SQ -o ./download",
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *Tui) addFlags() {
+	c.cmd.Flags().StringVarP(&c.opts.Output, "output", "o", "./download", "music file output path")
+	c.cmd.Flags().StringVarP(&c.opts.Level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+	c.cmd.Flags().Int64VarP(&c.opts.Parallel, "parallel", "p", 5, "concurrent download count")
+	c.cmd.Flags().Int64VarP(&c.opts.Limit, "limit", "n", 20, "number of search results per page, max 100")
+}
+
+func (c *Tui) validate() error {
+	if c.opts.Limit <= 0 || c.opts.Limit > 100 {
+		return fmt.Errorf("limit must be range 1-100")
+	}
+	return nil
+}
+
+func (c *Tui) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Tui) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Tui) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var (
+		request = weapi.New(cli)
+		search  = NewSearch(c.root, c.l)
+		state   = &tuiState{pane: "queue"}
+		reader  = bufio.NewReader(os.Stdin)
+		out     = c.cmd.OutOrStdout()
+	)
+	search.opts.Limit = c.opts.Limit
+
+	fmt.Fprintln(out, tuiHelp)
+	for {
+		fmt.Fprintf(out, "\n[%s] queue: %d > ", state.pane, len(state.queue))
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil
+		}
+		var (
+			fields = strings.Fields(line)
+			cmd    string
+			arg    string
+		)
+		if len(fields) > 0 {
+			cmd, arg = fields[0], strings.Join(fields[1:], " ")
+		}
+
+		switch cmd {
+		case "":
+			continue
+		case "q", "quit", "exit":
+			return nil
+		case "h", "help":
+			fmt.Fprintln(out, tuiHelp)
+		case "s":
+			var kind = "song"
+			if t, rest, ok := strings.Cut(arg, " "); ok && t != "djradio" && t != "lyric" {
+				if _, has := searchTypes[t]; has {
+					kind, arg = t, strings.TrimSpace(rest)
+				}
+			}
+			if arg == "" {
+				fmt.Fprintln(out, "keyword is empty")
+				continue
+			}
+			search.opts.Type = kind
+			state.keyword, state.page = arg, 1
+			if err := c.search(ctx, request, search, state, out); err != nil {
+				log.Error("%s", err)
+			}
+		case "n", "p":
+			if state.pane != "search" {
+				fmt.Fprintln(out, "paging only works in search pane")
+				continue
+			}
+			if cmd == "n" && state.page < state.pages {
+				state.page++
+			} else if cmd == "p" && state.page > 1 {
+				state.page--
+			}
+			if err := c.search(ctx, request, search, state, out); err != nil {
+				log.Error("%s", err)
+			}
+		case "l":
+			if err := c.playlists(ctx, request, state, out); err != nil {
+				log.Error("%s", err)
+			}
+		case "v":
+			state.pane = "queue"
+			c.table(out, state.queue)
+		case "a":
+			if state.pane == "queue" {
+				fmt.Fprintln(out, "switch to search or playlist pane first")
+				continue
+			}
+			index, err := parseSelection(arg, len(state.items))
+			if err != nil {
+				fmt.Fprintf(out, "invalid input: %s\n", err)
+				continue
+			}
+			for _, i := range index {
+				state.queue = append(state.queue, state.items[i-1])
+			}
+			fmt.Fprintf(out, "%d items added to queue\n", len(index))
+		case "r":
+			index, err := parseSelection(arg, len(state.queue))
+			if err != nil {
+				fmt.Fprintf(out, "invalid input: %s\n", err)
+				continue
+			}
+			var removed = make(map[int]struct{}, len(index))
+			for _, i := range index {
+				removed[i-1] = struct{}{}
+			}
+			var queue = make([]tuiItem, 0, len(state.queue))
+			for i, v := range state.queue {
+				if _, ok := removed[i]; !ok {
+					queue = append(queue, v)
+				}
+			}
+			state.queue, state.pane = queue, "queue"
+			c.table(out, state.queue)
+		case "d":
+			if len(state.queue) <= 0 {
+				fmt.Fprintln(out, "queue is empty")
+				continue
+			}
+			var sources = make([]string, 0, len(state.queue))
+			for _, v := range state.queue {
+				sources = append(sources, v.Source)
+			}
+			d := NewDownload(c.root, c.l)
+			d.opts.Output = c.opts.Output
+			d.opts.Level = c.opts.Level
+			d.opts.Parallel = c.opts.Parallel
+			if err := d.execute(ctx, sources); err != nil {
+				log.Error("download: %s", err)
+				continue
+			}
+			state.queue = nil
+		default:
+			fmt.Fprintf(out, "unknown command: %s, enter h for help\n", cmd)
+		}
+	}
+}
+
+// search 搜索并展示当前页结果
+func (c *Tui) search(ctx context.Context, request *weapi.Api, search *Search, state *tuiState, out io.Writer) error {
+	result, total, err := search.search(ctx, request, state.keyword, state.page)
+	if err != nil {
+		return err
+	}
+	var sources = search.sources(result)
+	state.pane = "search"
+	state.pages = (total + search.opts.Limit - 1) / search.opts.Limit
+	state.items = make([]tuiItem, 0, len(sources))
+	for i, name := range tuiNames(result) {
+		state.items = append(state.items, tuiItem{Kind: search.opts.Type, Name: name, Source: sources[i]})
+	}
+	if err := render(out, outputTable, search.view(result)); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "page %d/%d total %d\n", state.page, state.pages, total)
+	return nil
+}
+
+// playlists 展示当前登录用户的歌单
+func (c *Tui) playlists(ctx context.Context, request *weapi.Api, state *tuiState, out io.Writer) error {
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return fmt.Errorf("need login")
+	}
+	list, err := userPlaylists(ctx, request, user.Account.Id)
+	if err != nil {
+		return err
+	}
+	c.root.cachePlaylists(list)
+	state.pane = "playlist"
+	state.items = make([]tuiItem, 0, len(list))
+	for _, v := range list {
+		state.items = append(state.items, tuiItem{
+			Kind:   "playlist",
+			Name:   fmt.Sprintf("%s (%d)", v.Name, v.TrackCount),
+			Source: fmt.Sprintf("https://music.163.com/playlist?id=%d", v.Id),
+		})
+	}
+	c.table(out, state.items)
+	return nil
+}
+
+func (c *Tui) table(out io.Writer, items []tuiItem) {
+	var w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTYPE\tNAME")
+	for i, v := range items {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", i+1, v.Kind, v.Name)
+	}
+	_ = w.Flush()
+}
+
+// tuiNames 返回搜索结果的展示名称,顺序与 Search.sources 一致
+func tuiNames(result any) []string {
+	var list []string
+	switch v := result.(type) {
+	case []weapi.CloudSearchRespSong:
+		for _, s := range v {
+			list = append(list, fmt.Sprintf("%s - %s", artistNames(s.Ar), s.Name))
+		}
+	case []weapi.CloudSearchRespAlbum:
+		for _, s := range v {
+			list = append(list, fmt.Sprintf("%s - %s", s.Artist.Name, s.Name))
+		}
+	case []weapi.CloudSearchRespArtist:
+		for _, s := range v {
+			list = append(list, s.Name)
+		}
+	case []weapi.CloudSearchRespPlaylist:
+		for _, s := range v {
+			list = append(list, s.Name)
+		}
+	}
+	return list
+}
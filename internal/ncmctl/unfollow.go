@@ -0,0 +1,229 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/plan"
+
+	"github.com/spf13/cobra"
+)
+
+type UnfollowOpts struct {
+	InactiveSince string // 超过该时长没有活动的用户或歌手
+	Type          string // user、artist
+	DryRun        bool
+}
+
+type Unfollow struct {
+	root *Root
+	cmd  *cobra.Command
+	opts UnfollowOpts
+	l    *log.Logger
+}
+
+// followEntry 关注的用户或歌手及其最近活动时间
+type followEntry struct {
+	id     int64
+	name   string
+	active time.Time // 零值表示无法判断
+}
+
+func NewUnfollow(root *Root, l *log.Logger) *Unfollow {
+	c := &Unfollow{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "unfollow",
+			Short: "[need login] Unfollow users and artists, or those inactive for a long time",
+			Long: "Unfollow users and artists by id, or unfollow those inactive for a long time with --inactive-since.\n" +
+				"The activity of a user is the latest update time of the playlists created by the user, the activity\n" +
+				"of an artist is the publish time of the latest album. Those whose activity is unknown are skipped.",
+			Example: "  ncmctl unfollow user 32953014\n" +
+				"  ncmctl unfollow artist 6452\n" +
+				"  ncmctl unfollow --inactive-since 1y --dry-run\n" +
+				"  ncmctl unfollow --inactive-since 2y --type artist",
+			Args: cobra.NoArgs,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	c.Add(followTarget(root, l, "user", false))
+	c.Add(followTarget(root, l, "artist", false))
+	return c
+}
+
+func (c *Unfollow) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.InactiveSince, "inactive-since", "", "unfollow those inactive longer than the duration, eg: 180d、1y")
+	c.cmd.Flags().StringVar(&c.opts.Type, "type", "user", "unfollow type with --inactive-since, support: user、artist")
+	c.cmd.Flags().BoolVar(&c.opts.DryRun, "dry-run", false, "only print the plan of those would be unfollowed")
+}
+
+func (c *Unfollow) validate() error {
+	if c.opts.InactiveSince == "" {
+		return fmt.Errorf("please enter ids with user/artist subcommand or use --inactive-since")
+	}
+	if c.opts.Type != "user" && c.opts.Type != "artist" {
+		return fmt.Errorf("type is not support: %s", c.opts.Type)
+	}
+	return nil
+}
+
+func (c *Unfollow) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Unfollow) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Unfollow) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	age, err := parseAge(c.opts.InactiveSince)
+	if err != nil {
+		return fmt.Errorf("inactive-since: %w", err)
+	}
+	var before = time.Now().Add(-age)
+
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	var entries []followEntry
+	if c.opts.Type == "user" {
+		entries, err = c.users(ctx, request, user.Account.Id)
+	} else {
+		entries, err = c.artists(ctx, request)
+	}
+	if err != nil {
+		return err
+	}
+
+	var (
+		inactive []followEntry
+		unknown  int
+	)
+	for _, e := range entries {
+		switch {
+		case e.active.IsZero():
+			unknown++
+		case e.active.Before(before):
+			inactive = append(inactive, e)
+		}
+	}
+	if unknown > 0 {
+		c.cmd.Printf("skip %d %ss whose activity is unknown\n", unknown, c.opts.Type)
+	}
+
+	if c.opts.DryRun {
+		var p plan.Plan
+		for _, e := range inactive {
+			p.Remove(fmt.Sprintf("%s %d %s(last active %s)", c.opts.Type, e.id, e.name, e.active.Format(time.DateOnly)), 0)
+		}
+		return p.Write(c.cmd.OutOrStdout())
+	}
+
+	var failed int
+	for _, e := range inactive {
+		if err := setFollow(ctx, request, c.opts.Type, e.id, false); err != nil {
+			failed++
+			log.Error("[unfollow] %s %d: %s", c.opts.Type, e.id, err)
+			c.cmd.PrintErrf("%s %d %s: %s\n", c.opts.Type, e.id, e.name, err)
+			continue
+		}
+		c.cmd.Printf("%s %d %s(last active %s): ok\n", c.opts.Type, e.id, e.name, e.active.Format(time.DateOnly))
+	}
+	if failed > 0 {
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d/%d %ss failed", failed, len(inactive), c.opts.Type)}
+	}
+	return nil
+}
+
+// users 获取关注的用户,以用户创建的歌单最近更新时间作为活动时间
+func (c *Unfollow) users(ctx context.Context, request *weapi.Api, uid int64) ([]followEntry, error) {
+	follows, err := userFollows(ctx, request, uid)
+	if err != nil {
+		return nil, err
+	}
+	var list = make([]followEntry, 0, len(follows))
+	for _, v := range follows {
+		var e = followEntry{id: v.UserId, name: v.Nickname}
+		playlists, err := userPlaylists(ctx, request, v.UserId)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range playlists {
+			if p.UserId != v.UserId {
+				continue
+			}
+			if t := playlistUpdateTime(p); t.After(e.active) {
+				e.active = t
+			}
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
+
+// artists 获取关注的歌手,以最新专辑的发行时间作为活动时间
+func (c *Unfollow) artists(ctx context.Context, request *weapi.Api) ([]followEntry, error) {
+	artists, err := artistSublist(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	var list = make([]followEntry, 0, len(artists))
+	for _, v := range artists {
+		var e = followEntry{id: v.Id, name: v.Name}
+		resp, err := request.ArtistAlbums(ctx, &weapi.ArtistAlbumsReq{Id: v.Id, Limit: 1})
+		if err != nil {
+			return nil, fmt.Errorf("ArtistAlbums: %w", err)
+		}
+		if len(resp.HotAlbums) > 0 && resp.HotAlbums[0].PublishTime > 0 {
+			e.active = time.UnixMilli(resp.HotAlbums[0].PublishTime)
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
@@ -34,8 +34,11 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/types"
 	"github.com/chaunsin/netease-cloud-music/pkg/cookiecloud"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/normalize"
 	"github.com/chaunsin/netease-cloud-music/pkg/utils"
 
 	"github.com/spf13/cobra"
@@ -69,7 +72,7 @@ func writeFile(cmd *cobra.Command, out string, data []byte) error {
 }
 
 var (
-	urlPattern = "/(song|artist|album|playlist)\\?id=(\\d+)"
+	urlPattern = "/(song|artist|album|playlist|mv)\\?id=(\\d+)"
 	reg        = regexp.MustCompile(urlPattern)
 )
 
@@ -166,6 +169,10 @@ type Music struct {
 	Album   types.Album
 	AlbumId int64
 	Time    int64
+
+	Track       int64  // 曲目序号,电台节目为期数
+	PublishTime int64  // 发布时间毫秒时间戳,写入日期标签
+	Filename    string // 不为空时作为下载文件名,不包含扩展名
 }
 
 // NameString 返回去除特殊符号的歌曲名
@@ -173,6 +180,19 @@ func (m Music) NameString() string {
 	return utils.Filename(m.Name, "_")
 }
 
+// normalizeMusic 按规范化规则处理歌曲名、专辑名及歌手名
+func normalizeMusic(n *normalize.Normalizer, m Music) Music {
+	m.Name = n.Title(m.Name)
+	m.Album.Name = n.Album(m.Album.Name)
+	var artists = make([]types.Artist, 0, len(m.Artist))
+	for _, ar := range m.Artist {
+		ar.Name = n.Artist(ar.Name)
+		artists = append(artists, ar)
+	}
+	m.Artist = artists
+	return m
+}
+
 func (m Music) ArtistString() string {
 	if len(m.Artist) <= 0 {
 		return ""
@@ -194,3 +214,62 @@ func (m Music) String() string {
 	)
 	return fmt.Sprintf("%s-%s(%v) [%s]", m.ArtistString(), m.Name, m.Id, format)
 }
+
+// artistNames 返回以"/"连接的歌手名称
+func artistNames(artists []types.Artist) string {
+	var list = make([]string, 0, len(artists))
+	for _, ar := range artists {
+		list = append(list, ar.Name)
+	}
+	return strings.Join(list, "/")
+}
+
+// channelLayout 将接口返回的声道布局转换为字符串,字符串直接返回,其他类型以json格式返回
+func channelLayout(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
+
+// proxyStream 转发请求远程资源,转发Range请求头及相关响应头
+func proxyStream(w http.ResponseWriter, r *http.Request, cli *api.Client, url string) error {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("Referer", "https://music.163.com")
+	if v := r.Header.Get("Range"); v != "" {
+		req.Header.Set("Range", v)
+	}
+
+	// 音频流持续时间较长,不使用接口请求的超时时间
+	var client = &http.Client{Transport: cli.GetClient().Transport}
+	response, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("proxy: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("proxy: http status code: %d", response.StatusCode)
+	}
+
+	for _, k := range []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges", "Last-Modified", "ETag", "Cache-Control"} {
+		if v := response.Header.Get(k); v != "" {
+			w.Header().Set(k, v)
+		}
+	}
+	w.WriteHeader(response.StatusCode)
+	if _, err := io.Copy(w, response.Body); err != nil {
+		log.Debug("[proxy] %s: %s", url, err)
+	}
+	return nil
+}
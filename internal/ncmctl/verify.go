@@ -0,0 +1,275 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/checksum"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyStateSuffix 轮转校验进度及结果文件名后缀,文件位于校验目录下,文件名为清单文件名加该后缀
+const verifyStateSuffix = ".verify"
+
+const (
+	verifyOk      = "ok"
+	verifyCorrupt = "corrupt"
+	verifyMissing = "missing"
+)
+
+type VerifyOpts struct {
+	Checksum    string        // 校验和算法
+	Batch       int64         // 每次校验文件数量
+	Rate        string        // 读取文件限速
+	MaxDuration time.Duration // 单次运行最长时间
+}
+
+type Verify struct {
+	root *Root
+	cmd  *cobra.Command
+	opts VerifyOpts
+	l    *log.Logger
+}
+
+// verifyState 轮转校验状态,Cursor为上次校验到的文件
+type verifyState struct {
+	Cursor  string                  `json:"cursor"`
+	Results map[string]verifyResult `json:"results"`
+}
+
+type verifyResult struct {
+	Time   time.Time `json:"time"`
+	Status string    `json:"status"`
+}
+
+func NewVerify(root *Root, l *log.Logger) *Verify {
+	c := &Verify{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "verify",
+			Short: "Slowly re-hash a rotating subset of downloaded files against recorded checksums",
+			Long: "Re-hash downloaded music files against the checksums recorded by download(.checksums files).\n" +
+				"Each run continues from where the last run stopped, so scheduling it in idle hours through\n" +
+				"the daemon eventually covers the whole library. Results are recorded in .checksums[.<algorithm>].verify.",
+			Example: "  ncmctl verify ./download\n" +
+				"  ncmctl verify ./download -n 500 --rate 5MB --max-duration 2h",
+			Args: cobra.ExactArgs(1),
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context(), args[0])
+	}
+	return c
+}
+
+func (c *Verify) addFlags() {
+	c.cmd.Flags().StringVar(&c.opts.Checksum, "checksum", string(checksum.MD5), "checksum algorithm, must be the same as download --checksum. support: md5、sha256、blake3")
+	c.cmd.Flags().Int64VarP(&c.opts.Batch, "batch", "n", 200, "number of files verified in one run")
+	c.cmd.Flags().StringVar(&c.opts.Rate, "rate", "10MB", "maximum read speed per second, empty means unlimited. supporting unit:b、k/kb/KB、m/mb/MB")
+	c.cmd.Flags().DurationVar(&c.opts.MaxDuration, "max-duration", 0, "stop after the duration even if the batch is not finished, 0 means unlimited")
+}
+
+func (c *Verify) validate() error {
+	if c.opts.Batch <= 0 {
+		return fmt.Errorf("batch must be > 0")
+	}
+	if c.opts.MaxDuration < 0 {
+		return fmt.Errorf("max-duration must be >= 0")
+	}
+	if _, err := checksum.New(checksum.Algorithm(c.opts.Checksum)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Verify) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Verify) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Verify) execute(ctx context.Context, dir string) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	var rate int64
+	if c.opts.Rate != "" {
+		size, err := utils.ParseBytes(c.opts.Rate)
+		if err != nil {
+			return fmt.Errorf("ParseBytes: %w", err)
+		}
+		rate = size
+	}
+
+	dir, err := utils.ExpandTilde(dir)
+	if err != nil {
+		return fmt.Errorf("ExpandTilde: %w", err)
+	}
+	if !utils.DirExists(dir) {
+		return fmt.Errorf("%s not found", dir)
+	}
+
+	var alg = checksum.Algorithm(c.opts.Checksum)
+	sums, err := manifests(dir, alg)
+	if err != nil {
+		return err
+	}
+	if len(sums) <= 0 {
+		c.cmd.Printf("no checksums recorded in %s\n", dir)
+		return nil
+	}
+	state, err := c.load(dir, alg)
+	if err != nil {
+		return err
+	}
+
+	var (
+		names = checksum.Names(sums)
+		start = sort.SearchStrings(names, state.Cursor)
+		total = min(int(c.opts.Batch), len(names))
+		begin = time.Now()
+		bad   []string
+		count int
+	)
+	// 从上次校验的下一个文件开始
+	if start < len(names) && names[start] == state.Cursor {
+		start++
+	}
+	for ; count < total; count++ {
+		if ctx.Err() != nil || (c.opts.MaxDuration > 0 && time.Since(begin) >= c.opts.MaxDuration) {
+			break
+		}
+		var (
+			name   = names[(start+count)%len(names)]
+			status = verifyOk
+		)
+		sum, err := checksum.SumRate(ctx, filepath.Join(dir, filepath.FromSlash(name)), alg, rate)
+		if ctx.Err() != nil {
+			break
+		}
+		switch {
+		case os.IsNotExist(err):
+			status = verifyMissing
+		case err != nil:
+			log.Warn("[verify] %s: %s", name, err)
+			continue
+		case sum != sums[name]:
+			status = verifyCorrupt
+		}
+		if status != verifyOk {
+			bad = append(bad, fmt.Sprintf("%s: %s", status, name))
+			log.Warn("[verify] %s %s", status, name)
+		}
+		state.Cursor = name
+		state.Results[name] = verifyResult{Time: time.Now(), Status: status}
+	}
+
+	if err := c.save(dir, alg, state); err != nil {
+		return err
+	}
+	c.cmd.Printf("verified %d/%d files in %s, %d problems\n", count, len(names), time.Since(begin).Truncate(time.Second), len(bad))
+	for _, v := range bad {
+		c.cmd.Println(v)
+	}
+	if len(bad) > 0 {
+		c.root.notify(ctx, "音乐文件校验失败", fmt.Sprintf("%s 中 %d 个文件校验失败:\n%s", dir, len(bad), strings.Join(bad, "\n")))
+		return &exitError{code: ExitPartialFailure, err: fmt.Errorf("%d files failed verification", len(bad))}
+	}
+	return nil
+}
+
+// manifests 递归读取目录下指定算法的所有清单文件,返回 相对dir的路径->校验和
+func manifests(dir string, alg checksum.Algorithm) (map[string]string, error) {
+	var sums = make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != checksum.Manifest(alg) {
+			return nil
+		}
+		var base = filepath.Dir(path)
+		list, err := checksum.Load(base, alg)
+		if err != nil {
+			return fmt.Errorf("load %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(dir, base)
+		if err != nil {
+			return err
+		}
+		for name, sum := range list {
+			sums[filepath.ToSlash(filepath.Join(rel, name))] = sum
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("WalkDir: %w", err)
+	}
+	return sums, nil
+}
+
+func (c *Verify) load(dir string, alg checksum.Algorithm) (*verifyState, error) {
+	var state = verifyState{Results: make(map[string]verifyResult)}
+	data, err := os.ReadFile(filepath.Join(dir, checksum.Manifest(alg)+verifyStateSuffix))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &state, nil
+		}
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if state.Results == nil {
+		state.Results = make(map[string]verifyResult)
+	}
+	return &state, nil
+}
+
+func (c *Verify) save(dir string, alg checksum.Algorithm, state *verifyState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, checksum.Manifest(alg)+verifyStateSuffix), data, 0644); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	return nil
+}
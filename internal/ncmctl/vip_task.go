@@ -0,0 +1,162 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type vipTaskCmd struct {
+	root *Vip
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	sign  bool // 执行黑胶乐签
+	claim bool // 领取所有可领取的成长值
+}
+
+func vipTask(root *Vip, l *log.Logger) *cobra.Command {
+	c := &vipTaskCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "task",
+		Short: "Show vip level and growth point tasks, optionally sign in and claim rewards",
+		Example: "  ncmctl vip task\n" +
+			"  ncmctl vip task --sign\n" +
+			"  ncmctl vip task --sign --claim",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *vipTaskCmd) addFlags() {
+	c.cmd.Flags().BoolVar(&c.sign, "sign", false, "vip sign in (黑胶乐签)")
+	c.cmd.Flags().BoolVar(&c.claim, "claim", false, "claim all the growth points that can be received")
+}
+
+func (c *vipTaskCmd) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	if err := c.level(ctx, request); err != nil {
+		return err
+	}
+
+	if c.sign {
+		resp, err := request.VipTaskSign(ctx, &weapi.VipTaskSignReq{})
+		if err != nil {
+			return fmt.Errorf("VipTaskSign: %w", err)
+		}
+		if resp.Data {
+			c.cmd.Println("vip乐签成功")
+		} else {
+			c.cmd.Println("vip乐签失败或今日已签到")
+		}
+	}
+
+	if c.claim {
+		resp, err := request.VipRewardGetAll(ctx, &weapi.VipRewardGetAllReq{})
+		if err != nil {
+			return fmt.Errorf("VipRewardGetAll: %w", err)
+		}
+		if resp.Data.Result {
+			c.cmd.Println("vip成长值领取成功")
+		} else {
+			c.cmd.Println("暂无可领取的vip成长值")
+		}
+	}
+
+	return c.tasks(ctx, request)
+}
+
+// level 输出当前vip等级及成长值
+func (c *vipTaskCmd) level(ctx context.Context, request *weapi.Api) error {
+	point, err := request.VipGrowPoint(ctx, &weapi.VipGrowPointReq{})
+	if err != nil {
+		return fmt.Errorf("VipGrowPoint: %w", err)
+	}
+	var lv = point.Data.UserLevel
+	if lv.LatestVipStatus != 1 {
+		c.cmd.Printf("暂无会员权益: %v\n", lv.LatestVipStatus)
+	}
+	c.cmd.Printf("等级: %s(%d) 成长值: %d 昨日: %+d 满级: %v\n", lv.LevelName, lv.Level, lv.GrowthPoint, lv.YesterdayPoint, lv.MaxLevel)
+
+	score, err := request.VipMAXScore(ctx, &weapi.VipMAXScoreReq{})
+	if err != nil {
+		log.Warn("VipMAXScore: %s", err)
+		return nil
+	}
+	c.cmd.Printf("本月任务成长值上限: %d 剩余可获得: %d\n", score.Data.MaxTaskScore, score.Data.Gap)
+	return nil
+}
+
+// tasks 输出成长值任务列表
+func (c *vipTaskCmd) tasks(ctx context.Context, request *weapi.Api) error {
+	resp, err := request.VipTaskV2(ctx, &weapi.VipTaskV2Req{})
+	if err != nil {
+		return fmt.Errorf("VipTaskV2: %w", err)
+	}
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tTASK\tPOINT\tPROGRESS\tSTATUS")
+	for _, group := range resp.Data.TaskList {
+		for _, item := range group.TaskItems {
+			var (
+				info   = item.CurrentInfo
+				status = "-"
+			)
+			if info.NeedReceive {
+				status = "claimable"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d/%d\t%s\n", group.SeqName, info.Name, info.GrowthPoint, info.CurrentProgress, info.TargetWorth, status)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	c.cmd.Printf("任务成长值: %d 待领取: %d\n", resp.Data.TaskScore, resp.Data.UnGetAllScore)
+	return nil
+}
@@ -0,0 +1,355 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/database"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+// watchOverlap 每次检查时向前多查的时长,避免接口延迟收录导致遗漏,重复的作品通过已通知记录去重
+const watchOverlap = 24 * time.Hour
+
+type watchArtistsCmd struct {
+	root *Watch
+	cmd  *cobra.Command
+	l    *log.Logger
+
+	since    string // 首次运行时检查的时长,未指定时仅记录检查点
+	albums   int64  // 每个歌手检查最近的专辑数量,0表示不检查专辑
+	download bool
+	output   string
+	level    string
+}
+
+// watchRelease 关注歌手的新作品
+type watchRelease struct {
+	Kind        string `json:"kind"` // song、album
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	Artist      string `json:"artist"`
+	PublishTime int64  `json:"publishTime"` // 发行时间毫秒
+}
+
+func (r *watchRelease) key() string {
+	return fmt.Sprintf("%s:%d", r.Kind, r.Id)
+}
+
+func (r *watchRelease) source() string {
+	return fmt.Sprintf("https://music.163.com/%s?id=%d", r.Kind, r.Id)
+}
+
+// watchArtistsState 检查状态,Notified为已通知的作品,Pending为下载失败待重试的作品
+type watchArtistsState struct {
+	Checkpoint int64           `json:"checkpoint"`
+	Notified   []*watchRelease `json:"notified"`
+	Pending    []*watchRelease `json:"pending"`
+}
+
+func watchArtists(root *Watch, l *log.Logger) *cobra.Command {
+	c := &watchArtistsCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:   "artists",
+		Short: "[need login] Check new songs and albums of followed artists, download them and send notifications",
+		Long: "Check new songs and albums of followed artists released since the last check, download them and\n" +
+			"send a notification through the alert configuration, the http webhook carries the releases in the data field.\n" +
+			"The first run only records the checkpoint unless --since is specified.",
+		Example: "  ncmctl watch artists\n" +
+			"  ncmctl watch artists --since 7d -l SQ -o ./download\n" +
+			"  ncmctl watch artists --download=false --albums 0",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	c.addFlags()
+	return c.cmd
+}
+
+func (c *watchArtistsCmd) addFlags() {
+	c.cmd.Flags().StringVar(&c.since, "since", "", "check releases within the duration on the first run, eg: 7d、48h")
+	c.cmd.Flags().Int64Var(&c.albums, "albums", 3, "number of latest albums checked for each artist, 0 to only check the new songs feed")
+	c.cmd.Flags().BoolVar(&c.download, "download", true, "download the new releases")
+	c.cmd.Flags().StringVarP(&c.output, "output", "o", "./download", "music file output path")
+	c.cmd.Flags().StringVarP(&c.level, "level", "l", string(types.LevelLossless), "song quality level. support: standard/128,higher/192,exhigh/HQ/320,lossless/SQ,hires/HR")
+}
+
+func (c *watchArtistsCmd) validate() error {
+	if c.albums < 0 || c.albums > 100 {
+		return fmt.Errorf("albums must be range 0-100")
+	}
+	if c.since != "" {
+		if _, err := parseAge(c.since); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *watchArtistsCmd) execute(ctx context.Context) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Account == nil {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+	var uid = fmt.Sprintf("%d", user.Account.Id)
+
+	db, err := database.New(c.root.root.Cfg.Database)
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	defer db.Close(ctx)
+
+	state, err := c.load(ctx, db, uid)
+	if err != nil {
+		return err
+	}
+
+	var now = time.Now()
+	if state.Checkpoint <= 0 && c.since == "" {
+		state.Checkpoint = now.UnixMilli()
+		c.cmd.Println("first run, checkpoint recorded. releases after now will be downloaded next time, use --since to check earlier releases")
+		return c.save(ctx, db, uid, state)
+	}
+	var since = state.Checkpoint - watchOverlap.Milliseconds()
+	if c.since != "" {
+		age, _ := parseAge(c.since)
+		since = now.Add(-age).UnixMilli()
+	}
+
+	found, err := c.scan(ctx, request, since, now.UnixMilli())
+	if err != nil {
+		return err
+	}
+	var (
+		notified = make(map[string]struct{}, len(state.Notified))
+		fresh    []*watchRelease
+	)
+	for _, v := range state.Notified {
+		notified[v.key()] = struct{}{}
+	}
+	for _, v := range found {
+		if _, ok := notified[v.key()]; ok {
+			continue
+		}
+		notified[v.key()] = struct{}{}
+		fresh = append(fresh, v)
+		state.Notified = append(state.Notified, v)
+		log.Info("[watch] new %s %s - %s", v.Kind, v.Artist, v.Name)
+	}
+
+	if len(fresh) > 0 {
+		var lines = make([]string, 0, len(fresh))
+		for _, v := range fresh {
+			lines = append(lines, fmt.Sprintf("[%s] %s - %s", v.Kind, v.Artist, v.Name))
+		}
+		c.root.root.notifyData(ctx, "关注歌手新作品", strings.Join(lines, "\n"), fresh)
+	}
+
+	var failed error
+	if c.download {
+		var todo = append(state.Pending, fresh...)
+		if len(todo) > 0 {
+			var args = make([]string, 0, len(todo))
+			for _, v := range todo {
+				args = append(args, v.source())
+			}
+			d := NewDownload(c.root.root, c.l)
+			d.opts.Output = c.output
+			d.opts.Level = c.level
+			if failed = d.execute(ctx, args); failed != nil {
+				// 下载失败的作品下次检查时重新下载
+				state.Pending = todo
+				log.Error("[watch] download %d releases: %s", len(todo), failed)
+			} else {
+				state.Pending = nil
+			}
+		}
+	}
+
+	state.Checkpoint = now.UnixMilli()
+	if err := c.save(ctx, db, uid, state); err != nil {
+		return err
+	}
+	if err := c.table(fresh); err != nil {
+		return err
+	}
+	return failed
+}
+
+// scan 获取关注歌手在[since, until]期间发行的新歌及新专辑,新专辑中的歌曲不再单独列出
+func (c *watchArtistsCmd) scan(ctx context.Context, request *weapi.Api, since, until int64) ([]*watchRelease, error) {
+	var (
+		list   []*watchRelease
+		albums = make(map[int64]struct{})
+	)
+	if c.albums > 0 {
+		for offset := int64(0); ; {
+			artists, err := request.ArtistSublist(ctx, &weapi.ArtistSublistReq{Limit: 100, Offset: offset, Total: true})
+			if err != nil {
+				return nil, fmt.Errorf("ArtistSublist: %w", err)
+			}
+			for _, ar := range artists.Data {
+				resp, err := request.ArtistAlbums(ctx, &weapi.ArtistAlbumsReq{Id: ar.Id, Limit: c.albums})
+				if err != nil {
+					if _, ok := api.AsError(err); !ok {
+						return nil, fmt.Errorf("ArtistAlbums(%d): %w", ar.Id, err)
+					}
+					log.Warn("[watch] ArtistAlbums(%d): %s", ar.Id, err)
+					continue
+				}
+				for _, al := range resp.HotAlbums {
+					if al.PublishTime < since || al.PublishTime > until {
+						continue
+					}
+					if _, ok := albums[al.Id]; ok {
+						continue
+					}
+					albums[al.Id] = struct{}{}
+					list = append(list, &watchRelease{Kind: "album", Id: al.Id, Name: al.Name, Artist: ar.Name, PublishTime: al.PublishTime})
+				}
+			}
+			offset += int64(len(artists.Data))
+			if !artists.HasMore || len(artists.Data) <= 0 {
+				break
+			}
+		}
+	}
+
+	for cursor := until; ; {
+		resp, err := request.ArtistNewSongs(ctx, &weapi.ArtistNewSongsReq{Limit: 50, StartTimestamp: cursor})
+		if err != nil {
+			return nil, fmt.Errorf("ArtistNewSongs: %w", err)
+		}
+		var works = resp.Data.NewWorks
+		for _, v := range works {
+			if v.PublishTime < since {
+				continue
+			}
+			if _, ok := albums[v.Al.Id]; ok {
+				continue
+			}
+			var artists = make([]string, 0, len(v.Ar))
+			for _, ar := range v.Ar {
+				artists = append(artists, ar.Name)
+			}
+			list = append(list, &watchRelease{Kind: "song", Id: v.Id, Name: v.Name, Artist: strings.Join(artists, ","), PublishTime: v.PublishTime})
+		}
+		if !resp.Data.HasMore || len(works) <= 0 {
+			break
+		}
+		var last = works[len(works)-1].PublishTime
+		if last < since || last >= cursor {
+			break
+		}
+		cursor = last
+	}
+	return list, nil
+}
+
+func (c *watchArtistsCmd) load(ctx context.Context, db database.Database, uid string) (*watchArtistsState, error) {
+	var state watchArtistsState
+	value, err := db.Get(ctx, watchArtistsKey(uid))
+	if err != nil {
+		if strings.Contains(err.Error(), "Key not found") {
+			return &state, nil
+		}
+		return nil, fmt.Errorf("get watch state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return &state, nil
+}
+
+// save 保存检查状态,只保留最近30天发行的已通知作品用于去重
+func (c *watchArtistsCmd) save(ctx context.Context, db database.Database, uid string, state *watchArtistsState) error {
+	var (
+		expire   = time.Now().AddDate(0, 0, -30).UnixMilli()
+		notified = make([]*watchRelease, 0, len(state.Notified))
+	)
+	for _, v := range state.Notified {
+		if v.PublishTime >= expire {
+			notified = append(notified, v)
+		}
+	}
+	state.Notified = notified
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := db.Set(ctx, watchArtistsKey(uid), string(data)); err != nil {
+		return fmt.Errorf("set watch state: %w", err)
+	}
+	return nil
+}
+
+func (c *watchArtistsCmd) table(list []*watchRelease) error {
+	if len(list) <= 0 {
+		c.cmd.Println("no new releases")
+		return nil
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].PublishTime > list[j].PublishTime })
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tID\tPUBLISH\tARTIST\tNAME")
+	for _, v := range list {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", v.Kind, v.Id, time.UnixMilli(v.PublishTime).Format(time.DateOnly), v.Artist, v.Name)
+	}
+	return w.Flush()
+}
+
+func watchArtistsKey(uid string) string {
+	return fmt.Sprintf("watch:artists:%v", uid)
+}
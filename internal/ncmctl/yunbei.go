@@ -0,0 +1,202 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type YunBeiOpts struct {
+	Sign  bool // 云贝签到
+	Claim bool // 领取已完成任务的云贝奖励
+}
+
+type YunBei struct {
+	root *Root
+	cmd  *cobra.Command
+	opts YunBeiOpts
+	l    *log.Logger
+}
+
+func NewYunBei(root *Root, l *log.Logger) *YunBei {
+	c := &YunBei{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:   "yunbei",
+			Short: "[need login] Show cloud bean(云贝) balance and tasks, optionally sign in and claim task rewards",
+			Example: "  ncmctl yunbei\n" +
+				"  ncmctl yunbei --claim\n" +
+				"  ncmctl yunbei --sign --claim",
+			Args: cobra.NoArgs,
+		},
+	}
+	c.addFlags()
+	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return c.execute(cmd.Context())
+	}
+	return c
+}
+
+func (c *YunBei) addFlags() {
+	c.cmd.Flags().BoolVar(&c.opts.Sign, "sign", false, "cloud bean daily sign in")
+	c.cmd.Flags().BoolVar(&c.opts.Claim, "claim", false, "claim the rewards of all completed tasks")
+}
+
+func (c *YunBei) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *YunBei) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *YunBei) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	var request = weapi.New(cli)
+
+	if request.NeedLogin(ctx) {
+		return &exitError{code: ExitNeedLogin, err: fmt.Errorf("need login")}
+	}
+
+	if c.opts.Sign {
+		resp, err := request.YunBeiSignIn(ctx, &weapi.YunBeiSignInReq{})
+		if err != nil {
+			return fmt.Errorf("YunBeiSignIn: %w", err)
+		}
+		if resp.Data.Sign {
+			c.cmd.Println("云贝签到成功")
+		} else {
+			c.cmd.Println("云贝已签到")
+		}
+	}
+
+	if c.opts.Claim {
+		claimed, err := claimYunBeiTasks(ctx, request)
+		if err != nil {
+			return err
+		}
+		for _, v := range claimed {
+			c.cmd.Printf("云贝 [%s] 任务完成获得云贝数量 %v\n", v.TaskName, v.TaskPoint)
+		}
+		if len(claimed) <= 0 {
+			c.cmd.Println("暂无可领取的云贝任务奖励")
+		}
+	}
+
+	if err := c.balance(ctx, request); err != nil {
+		return err
+	}
+	return c.tasks(ctx, request)
+}
+
+// balance 输出云贝余额及即将过期的数量
+func (c *YunBei) balance(ctx context.Context, request *weapi.Api) error {
+	resp, err := request.YunBeiBalance(ctx, &weapi.YunBeiBalanceReq{})
+	if err != nil {
+		return fmt.Errorf("YunBeiBalance: %w", err)
+	}
+	c.cmd.Printf("云贝余额: %d 冻结: %d\n", resp.Data.Balance, resp.Data.BlockBalance)
+
+	expire, err := request.YunBeiExpire(ctx, &weapi.YunBeiExpireReq{})
+	if err != nil {
+		log.Warn("YunBeiExpire: %s", err)
+		return nil
+	}
+	if expire.Data.ExpireAmount > 0 {
+		c.cmd.Printf("%d天内即将过期: %d\n", expire.Data.Day, expire.Data.ExpireAmount)
+	}
+	return nil
+}
+
+// tasks 输出云贝任务列表,已完成未领取的任务标记为claimable
+func (c *YunBei) tasks(ctx context.Context, request *weapi.Api) error {
+	resp, err := request.YunBeiTaskList(ctx, &weapi.YunBeiTaskListReq{})
+	if err != nil {
+		return fmt.Errorf("YunBeiTaskList: %w", err)
+	}
+
+	var claimable = make(map[int64]bool)
+	todo, err := request.YunBeiTaskTodo(ctx, &weapi.YunBeiTaskTodoReq{})
+	if err != nil {
+		log.Warn("YunBeiTaskTodo: %s", err)
+	} else {
+		for _, v := range todo.Data {
+			claimable[v.UserTaskId] = v.Completed
+		}
+	}
+
+	var w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tPOINT\tDESCRIPTION\tSTATUS")
+	for _, v := range resp.Data {
+		var status = "-"
+		switch {
+		case claimable[v.UserTaskId]:
+			status = "claimable"
+		case v.Completed:
+			status = "done"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", v.TaskName, v.TaskPoint, v.TaskDescription, status)
+	}
+	return w.Flush()
+}
+
+// claimYunBeiTasks 领取所有已完成任务的云贝奖励,一次只能领取一个任务,单个任务领取失败时记录日志继续领取其他任务
+func claimYunBeiTasks(ctx context.Context, request *weapi.Api) ([]weapi.YunBeiTaskTodoRespData, error) {
+	task, err := request.YunBeiTaskTodo(ctx, &weapi.YunBeiTaskTodoReq{})
+	if err != nil {
+		return nil, fmt.Errorf("YunBeiTaskTodo: %w", err)
+	}
+
+	var claimed []weapi.YunBeiTaskTodoRespData
+	for _, v := range task.Data {
+		if !v.Completed {
+			continue
+		}
+		_, err := request.YunBeiTaskFinish(ctx, &weapi.YunBeiTaskFinishReq{
+			Period:      fmt.Sprintf("%d", v.Period),
+			UserTaskId:  fmt.Sprintf("%d", v.UserTaskId),
+			DepositCode: fmt.Sprintf("%d", v.DepositCode),
+		})
+		if err != nil {
+			log.Error("YunBeiTaskFinish(%v): %s", v.UserTaskId, err)
+			continue
+		}
+		claimed = append(claimed, v)
+	}
+	return claimed, nil
+}
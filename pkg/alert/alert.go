@@ -26,23 +26,33 @@ package alert
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/chaunsin/netease-cloud-music/pkg/alert/bark"
 	"github.com/chaunsin/netease-cloud-music/pkg/alert/http"
 	"github.com/chaunsin/netease-cloud-music/pkg/alert/mail"
+	"github.com/chaunsin/netease-cloud-music/pkg/alert/serverchan"
+	"github.com/chaunsin/netease-cloud-music/pkg/alert/telegram"
 )
 
 type Config struct {
-	Module Module       `json:"module" yaml:"module"`
-	Mail   *mail.Config `json:"mail" yaml:"mail"`
-	HTTP   *http.Config `json:"http" yaml:"http"`
+	Module     Module             `json:"module" yaml:"module"`
+	Mail       *mail.Config       `json:"mail" yaml:"mail"`
+	HTTP       *http.Config       `json:"http" yaml:"http"`
+	Telegram   *telegram.Config   `json:"telegram" yaml:"telegram"`
+	Bark       *bark.Config       `json:"bark" yaml:"bark"`
+	ServerChan *serverchan.Config `json:"serverchan" yaml:"serverchan"`
 }
 
 type Module string
 
 const (
-	ModuleMail Module = "mail"
-	ModuleHTTP Module = "http"
-	ModuleVX   Module = "vx"
+	ModuleMail       Module = "mail"
+	ModuleHTTP       Module = "http"
+	ModuleVX         Module = "vx"
+	ModuleTelegram   Module = "telegram"
+	ModuleBark       Module = "bark"
+	ModuleServerChan Module = "serverchan"
 )
 
 type Alert interface {
@@ -51,11 +61,19 @@ type Alert interface {
 }
 
 func New(module Module, cfg *Config) (a Alert, err error) {
-	switch module {
-	case ModuleMail:
+	switch {
+	case module == ModuleMail && cfg.Mail != nil:
 		a, err = mail.New(cfg.Mail)
-	case ModuleHTTP:
+	case module == ModuleHTTP && cfg.HTTP != nil:
 		a, err = http.New(cfg.HTTP)
+	case module == ModuleTelegram && cfg.Telegram != nil:
+		a, err = telegram.New(cfg.Telegram)
+	case module == ModuleBark && cfg.Bark != nil:
+		a, err = bark.New(cfg.Bark)
+	case module == ModuleServerChan && cfg.ServerChan != nil:
+		a, err = serverchan.New(cfg.ServerChan)
+	case module == ModuleMail, module == ModuleHTTP, module == ModuleTelegram, module == ModuleBark, module == ModuleServerChan:
+		return nil, fmt.Errorf("alert.%s is not configured", module)
 	default:
 		return nil, errors.New("invalid module")
 	}
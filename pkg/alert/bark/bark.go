@@ -0,0 +1,118 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package bark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Config Bark(iOS推送)通知配置,key为Bark App中显示的设备key
+type Config struct {
+	// Host 服务地址,默认 https://api.day.app,自建服务时配置为自己的地址
+	Host    string        `json:"host" yaml:"host"`
+	Key     string        `json:"key" yaml:"key"`
+	Group   string        `json:"group" yaml:"group"` // 消息分组
+	Sound   string        `json:"sound" yaml:"sound"` // 推送铃声
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+func (c Config) Validate() error {
+	if c.Key == "" {
+		return errors.New("key is empty")
+	}
+	return nil
+}
+
+type Client struct {
+	cli *resty.Client
+	cfg *Config
+}
+
+func New(cfg *Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("bark: Validate: %w", err)
+	}
+
+	var host = strings.TrimSuffix(cfg.Host, "/")
+	if host == "" {
+		host = "https://api.day.app"
+	}
+	cli := resty.New()
+	cli.SetBaseURL(host)
+	cli.SetTimeout(cfg.Timeout)
+
+	m := &Client{
+		cli: cli,
+		cfg: cfg,
+	}
+	return m, nil
+}
+
+type pushReq struct {
+	DeviceKey string `json:"device_key"`
+	Title     string `json:"title,omitempty"`
+	Body      string `json:"body"`
+	Group     string `json:"group,omitempty"`
+	Sound     string `json:"sound,omitempty"`
+}
+
+type pushResp struct {
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+// Send 发送推送,content第一行作为标题
+func (c *Client) Send(ctx context.Context, content string) error {
+	var (
+		reply pushResp
+		req   = pushReq{DeviceKey: c.cfg.Key, Body: content, Group: c.cfg.Group, Sound: c.cfg.Sound}
+	)
+	if title, body, ok := strings.Cut(content, "\n"); ok {
+		req.Title, req.Body = title, body
+	}
+	resp, err := c.cli.R().
+		SetContext(ctx).
+		SetBody(req).
+		SetResult(&reply).
+		SetError(&reply).
+		Post("/push")
+	if err != nil {
+		return err
+	}
+	if reply.Code != 200 {
+		return fmt.Errorf("bark: status code: %d code: %d message: %s", resp.StatusCode(), reply.Code, reply.Message)
+	}
+	return nil
+}
+
+func (c *Client) Close(ctx context.Context) error {
+	c.cli.SetCloseConnection(true)
+	return nil
+}
@@ -0,0 +1,108 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package serverchan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Config Server酱(微信推送)通知配置,sendKey在 https://sct.ftqq.com 获取
+type Config struct {
+	// Host 服务地址,默认 https://sctapi.ftqq.com
+	Host    string        `json:"host" yaml:"host"`
+	SendKey string        `json:"sendKey" yaml:"sendKey"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+func (c Config) Validate() error {
+	if c.SendKey == "" {
+		return errors.New("sendKey is empty")
+	}
+	return nil
+}
+
+type Client struct {
+	cli *resty.Client
+	cfg *Config
+}
+
+func New(cfg *Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("serverchan: Validate: %w", err)
+	}
+
+	var host = strings.TrimSuffix(cfg.Host, "/")
+	if host == "" {
+		host = "https://sctapi.ftqq.com"
+	}
+	cli := resty.New()
+	cli.SetBaseURL(host)
+	cli.SetTimeout(cfg.Timeout)
+
+	m := &Client{
+		cli: cli,
+		cfg: cfg,
+	}
+	return m, nil
+}
+
+type sendResp struct {
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+// Send 发送消息,content第一行作为标题,其余内容作为消息正文(支持markdown)
+func (c *Client) Send(ctx context.Context, content string) error {
+	var (
+		reply       sendResp
+		title, desp = content, ""
+	)
+	if t, d, ok := strings.Cut(content, "\n"); ok {
+		title, desp = t, d
+	}
+	resp, err := c.cli.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{"title": title, "desp": desp}).
+		SetResult(&reply).
+		SetError(&reply).
+		Post("/" + c.cfg.SendKey + ".send")
+	if err != nil {
+		return err
+	}
+	if reply.Code != 0 {
+		return fmt.Errorf("serverchan: status code: %d code: %d message: %s", resp.StatusCode(), reply.Code, reply.Message)
+	}
+	return nil
+}
+
+func (c *Client) Close(ctx context.Context) error {
+	c.cli.SetCloseConnection(true)
+	return nil
+}
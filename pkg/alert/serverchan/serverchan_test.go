@@ -0,0 +1,61 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package serverchan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSend(t *testing.T) {
+	var title, desp string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/SCT123.send" {
+			t.Errorf("path = %s, want /SCT123.send", r.URL.Path)
+		}
+		title, desp = r.FormValue("title"), r.FormValue("desp")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":0,"message":"","data":{}}`))
+	}))
+	defer srv.Close()
+
+	cli, err := New(&Config{Host: srv.URL, SendKey: "SCT123"})
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+	if err := cli.Send(context.Background(), "title\ncontent"); err != nil {
+		t.Fatalf("Send: %s", err)
+	}
+	if title != "title" || desp != "content" {
+		t.Fatalf("title = %q desp = %q", title, desp)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if _, err := New(&Config{}); err == nil {
+		t.Fatal("New should fail when sendKey is empty")
+	}
+}
@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Config Telegram机器人通知配置,通过 @BotFather 创建机器人获取token
+type Config struct {
+	// Host 接口地址,默认 https://api.telegram.org,国内网络可配置为反向代理地址
+	Host    string        `json:"host" yaml:"host"`
+	Token   string        `json:"token" yaml:"token"`
+	ChatId  string        `json:"chatId" yaml:"chatId"`
+	Proxy   string        `json:"proxy" yaml:"proxy"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+func (c Config) Validate() error {
+	if c.Token == "" {
+		return errors.New("token is empty")
+	}
+	if c.ChatId == "" {
+		return errors.New("chatId is empty")
+	}
+	return nil
+}
+
+type Client struct {
+	cli *resty.Client
+	cfg *Config
+}
+
+func New(cfg *Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("telegram: Validate: %w", err)
+	}
+
+	var host = strings.TrimSuffix(cfg.Host, "/")
+	if host == "" {
+		host = "https://api.telegram.org"
+	}
+	cli := resty.New()
+	cli.SetBaseURL(host)
+	cli.SetTimeout(cfg.Timeout)
+	if cfg.Proxy != "" {
+		cli.SetProxy(cfg.Proxy)
+	}
+
+	m := &Client{
+		cli: cli,
+		cfg: cfg,
+	}
+	return m, nil
+}
+
+type sendMessageResp struct {
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Send 发送文本消息
+func (c *Client) Send(ctx context.Context, content string) error {
+	var reply sendMessageResp
+	resp, err := c.cli.R().
+		SetContext(ctx).
+		SetBody(map[string]string{"chat_id": c.cfg.ChatId, "text": content}).
+		SetResult(&reply).
+		SetError(&reply).
+		Post("/bot" + c.cfg.Token + "/sendMessage")
+	if err != nil {
+		return err
+	}
+	if !reply.Ok {
+		return fmt.Errorf("telegram: status code: %d description: %s", resp.StatusCode(), reply.Description)
+	}
+	return nil
+}
+
+func (c *Client) Close(ctx context.Context) error {
+	c.cli.SetCloseConnection(true)
+	return nil
+}
@@ -0,0 +1,213 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package checksum 维护下载目录中音乐文件的校验和清单,用于后续校验文件是否损坏.
+// 清单文件格式与 md5sum/sha256sum 命令输出一致,可直接使用 md5sum -c 等命令校验.
+package checksum
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lukechampine.com/blake3"
+)
+
+// Algorithm 校验和算法
+type Algorithm string
+
+const (
+	// MD5 与接口返回的md5一致,便于和服务端比较
+	MD5 Algorithm = "md5"
+	// SHA256 适合长期归档校验
+	SHA256 Algorithm = "sha256"
+	// BLAKE3 大文件校验速度更快,输出256位
+	BLAKE3 Algorithm = "blake3"
+)
+
+// ManifestName md5清单文件名,位于下载目录下,其他算法的清单文件名为 ManifestName.<algorithm>
+const ManifestName = ".checksums"
+
+var (
+	mu      sync.Mutex
+	hashMu  sync.RWMutex
+	hashers = map[Algorithm]func() hash.Hash{
+		MD5:    md5.New,
+		SHA256: sha256.New,
+		BLAKE3: func() hash.Hash { return blake3.New(32, nil) },
+	}
+)
+
+// Register 注册校验和算法,已存在时覆盖
+func Register(alg Algorithm, fn func() hash.Hash) {
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	hashers[alg] = fn
+}
+
+// New 返回指定算法的hash实现
+func New(alg Algorithm) (hash.Hash, error) {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	fn, ok := hashers[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported checksum algorithm: %s", alg)
+	}
+	return fn(), nil
+}
+
+// Manifest 返回指定算法的清单文件名
+func Manifest(alg Algorithm) string {
+	if alg == MD5 {
+		return ManifestName
+	}
+	return ManifestName + "." + string(alg)
+}
+
+// Sum 计算文件校验和
+func Sum(path string, alg Algorithm) (string, error) {
+	return SumRate(context.Background(), path, alg, 0)
+}
+
+// SumRate 以不超过rate字节每秒的速度计算文件校验和,rate<=0时不限速
+func SumRate(ctx context.Context, path string, alg Algorithm, rate int64) (string, error) {
+	h, err := New(alg)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var (
+		buf   = make([]byte, 256*1024)
+		start = time.Now()
+		read  int64
+	)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			read += int64(n)
+			if rate > 0 {
+				// 读取速度超过限制时等待
+				var expect = time.Duration(float64(read) / float64(rate) * float64(time.Second))
+				if wait := expect - time.Since(start); wait > 0 {
+					select {
+					case <-ctx.Done():
+						return "", ctx.Err()
+					case <-time.After(wait):
+					}
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Append 追加记录dir目录下name文件的校验和,name为相对dir的路径
+func Append(dir, name string, alg Algorithm, sum string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(dir, Manifest(alg)), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "%s  %s\n", sum, filepath.ToSlash(name)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load 读取dir目录下指定算法的清单,返回 相对路径->校验和,同一文件多次记录时以最后一次为准.
+// 清单不存在时返回空集合
+func Load(dir string, alg Algorithm) (map[string]string, error) {
+	h, err := New(alg)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		list = make(map[string]string)
+		name = Manifest(alg)
+	)
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return list, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var scanner = bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var text = strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		sum, file, ok := strings.Cut(text, "  ")
+		if !ok || len(sum) != h.Size()*2 || file == "" {
+			return nil, fmt.Errorf("%s line %d: invalid format", name, line)
+		}
+		list[file] = sum
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Names 返回清单中排序后的文件名
+func Names(list map[string]string) []string {
+	var names = make([]string, 0, len(list))
+	for k := range list {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
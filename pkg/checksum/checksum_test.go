@@ -0,0 +1,126 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package checksum
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "a.mp3")
+	assert.NoError(t, os.WriteFile(path, []byte("hello"), 0644))
+
+	sum, err := Sum(path, MD5)
+	assert.NoError(t, err)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", sum)
+
+	sum, err = Sum(path, SHA256)
+	assert.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", sum)
+
+	sum, err = Sum(path, BLAKE3)
+	assert.NoError(t, err)
+	assert.Equal(t, "ea8f163db38682925e4491c5e58d4bb3506ef8c14eb78a86e908c5624a67200f", sum)
+
+	_, err = Sum(path, "crc32")
+	assert.Error(t, err)
+
+	_, err = Sum(filepath.Join(t.TempDir(), "not-exist.mp3"), MD5)
+	assert.Error(t, err)
+}
+
+func TestSumRate(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "a.flac")
+	assert.NoError(t, os.WriteFile(path, make([]byte, 2048), 0644))
+
+	var start = time.Now()
+	sum, err := SumRate(context.Background(), path, MD5, 10*1024)
+	assert.NoError(t, err)
+	assert.Equal(t, "c99a74c555371a433d121f551d6c6398", sum)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = SumRate(ctx, path, MD5, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestManifest(t *testing.T) {
+	var dir = t.TempDir()
+	list, err := Load(dir, MD5)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	assert.NoError(t, Append(dir, "b.mp3", MD5, "5d41402abc4b2a76b9719d911017c592"))
+	assert.NoError(t, Append(dir, filepath.Join("sub", "a.flac"), MD5, "c99a74c555371a433d121f551d6c6398"))
+	assert.NoError(t, Append(dir, "b.mp3", MD5, "00000000000000000000000000000000"))
+	assert.NoError(t, Append(dir, "b.mp3", SHA256, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"))
+
+	list, err = Load(dir, MD5)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"b.mp3":      "00000000000000000000000000000000",
+		"sub/a.flac": "c99a74c555371a433d121f551d6c6398",
+	}, list)
+	assert.Equal(t, []string{"b.mp3", "sub/a.flac"}, Names(list))
+
+	list, err = Load(dir, SHA256)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"b.mp3": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}, list)
+	assert.FileExists(t, filepath.Join(dir, ".checksums.sha256"))
+
+	// md5长度的校验和不能出现在sha256清单中
+	assert.NoError(t, Append(dir, "c.mp3", SHA256, "5d41402abc4b2a76b9719d911017c592"))
+	_, err = Load(dir, SHA256)
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ManifestName), []byte("bad line\n"), 0644))
+	_, err = Load(dir, MD5)
+	assert.Error(t, err)
+}
+
+func TestRegister(t *testing.T) {
+	const alg Algorithm = "test-sha256"
+	_, err := New(alg)
+	assert.Error(t, err)
+
+	Register(alg, sha256.New)
+	sum, err := Sum(writeTemp(t, "hello"), alg)
+	assert.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", sum)
+	assert.Equal(t, ".checksums.test-sha256", Manifest(alg))
+}
+
+func writeTemp(t *testing.T, content string) string {
+	var path = filepath.Join(t.TempDir(), "a.mp3")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
@@ -33,6 +33,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 )
@@ -81,7 +82,7 @@ func NewCookie(opts ...Option) (*Cookie, error) {
 		done:  make(chan struct{}),
 		async: true,
 	}
-	if cfg.Interval <= 0 {
+	if cfg.Interval <= 0 || cfg.Filepath == "" {
 		p.async = false
 	}
 	if err := p.init(); err != nil {
@@ -106,6 +107,55 @@ func (c *Cookie) Cookies(u *url.URL) []*http.Cookie {
 	return c.jar.Cookies(u)
 }
 
+// All 获取所有未过期的cookie,包含domain、path、expires等完整属性,用于导出cookie
+func (c *Cookie) All() []*http.Cookie {
+	c.jar.mu.Lock()
+	defer c.jar.mu.Unlock()
+
+	var (
+		now  = time.Now()
+		list = make([]*http.Cookie, 0)
+	)
+	for _, cookies := range c.jar.entries {
+		for _, e := range cookies {
+			if e.Persistent && !e.Expires.After(now) {
+				continue
+			}
+			var domain = e.Domain
+			if !e.HostOnly {
+				domain = "." + domain
+			}
+			var ck = &http.Cookie{
+				Name:     e.Name,
+				Value:    e.Value,
+				Domain:   domain,
+				Path:     e.Path,
+				Secure:   e.Secure,
+				HttpOnly: e.HttpOnly,
+			}
+			if e.Persistent {
+				ck.Expires = e.Expires
+			}
+			switch e.SameSite {
+			case "SameSite=Strict":
+				ck.SameSite = http.SameSiteStrictMode
+			case "SameSite=Lax":
+				ck.SameSite = http.SameSiteLaxMode
+			case "SameSite=None":
+				ck.SameSite = http.SameSiteNoneMode
+			}
+			list = append(list, ck)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Domain != list[j].Domain {
+			return list[i].Domain < list[j].Domain
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
 func (c *Cookie) Close(ctx context.Context) error {
 	c.closeOnce.Do(func() {
 		close(c.done)
@@ -133,6 +183,10 @@ func (c *Cookie) sync() {
 }
 
 func (c *Cookie) init() error {
+	// 未指定文件路径时仅保存在内存中
+	if c.cfg.Filepath == "" {
+		return nil
+	}
 	// 如果文件存在则读取配置文件
 	if !fileExists(c.cfg.Filepath) {
 		log.Printf("cookie: warnning %s file not found", c.cfg.Filepath)
@@ -183,6 +237,9 @@ func (c *Cookie) init() error {
 }
 
 func (c *Cookie) export() error {
+	if c.cfg.Filepath == "" {
+		return nil
+	}
 	c.jar.mu.Lock()
 	defer c.jar.mu.Unlock()
 
@@ -262,7 +319,7 @@ func WithSyncInterval(interval time.Duration) Option {
 	})
 }
 
-// WithFilePath sets the file path.
+// WithFilePath sets the file path. An empty path keeps cookies in memory only.
 func WithFilePath(filePath string) Option {
 	return optionFunc(func(p *Config) {
 		p.Filepath = filePath
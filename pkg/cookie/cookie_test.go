@@ -24,10 +24,12 @@
 package cookie
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -51,3 +53,49 @@ func TestSync(t *testing.T) {
 	t.Logf("data:%s\n", string(data))
 	// assert.JSONEq(t, string(data), target)
 }
+
+func TestAll(t *testing.T) {
+	filepath := os.TempDir() + "cookie_all.json"
+	t.Cleanup(func() {
+		_ = os.Remove(filepath)
+	})
+
+	jar, err := NewCookie(WithSyncInterval(0), WithFilePath(filepath))
+	assert.NoError(t, err)
+
+	u := &url.URL{Scheme: "https", Host: "music.163.com"}
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "MUSIC_U", Value: "token", Domain: ".163.com", Path: "/", Expires: time.Now().Add(time.Hour), HttpOnly: true},
+		{Name: "__csrf", Value: "csrf", Path: "/"},
+		{Name: "expired", Value: "v", Expires: time.Now().Add(-time.Hour)},
+	})
+
+	list := jar.All()
+	assert.Len(t, list, 2)
+	assert.Equal(t, "MUSIC_U", list[0].Name)
+	assert.Equal(t, ".163.com", list[0].Domain)
+	assert.True(t, list[0].HttpOnly)
+	assert.False(t, list[0].Expires.IsZero())
+	assert.Equal(t, "__csrf", list[1].Name)
+	assert.Equal(t, "music.163.com", list[1].Domain)
+	assert.True(t, list[1].Expires.IsZero())
+}
+
+func TestMemory(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	jar, err := NewCookie(WithFilePath(""))
+	assert.NoError(t, err)
+
+	u := &url.URL{Scheme: "https", Host: "music.163.com"}
+	jar.SetCookies(u, []*http.Cookie{{Name: "MUSIC_U", Value: "token"}})
+	assert.Len(t, jar.Cookies(u), 1)
+	assert.NoError(t, jar.Close(context.Background()))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
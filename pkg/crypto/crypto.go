@@ -38,6 +38,7 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -302,6 +303,31 @@ func LinuxApiEncrypt(object interface{}) (map[string]string, error) {
 	return map[string]string{"eparams": ciphertext}, nil
 }
 
+// LinuxApiForwardUrl linux客户端请求统一转发地址,真实接口地址及参数加密后放在eparams中
+const LinuxApiForwardUrl = "https://music.163.com/api/linux/forward"
+
+// LinuxApiForward 将请求地址及参数包装成linux客户端转发格式后加密,加密后的参数需要请求 LinuxApiForwardUrl
+// 其中url路径的第一段如果是 weapi、eapi、linuxapi 会替换为 api,域名部分不做处理
+func LinuxApiForward(rawUrl string, object interface{}) (map[string]string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	if seg, rest, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/"); seg == "weapi" || seg == "eapi" || seg == "linuxapi" {
+		u.Path = "/api/" + rest
+	}
+	var payload = struct {
+		Method string      `json:"method"`
+		Url    string      `json:"url"`
+		Params interface{} `json:"params"`
+	}{
+		Method: "POST",
+		Url:    u.String(),
+		Params: object,
+	}
+	return LinuxApiEncrypt(payload)
+}
+
 // LinuxApiDecrypt 解密
 func LinuxApiDecrypt(cipherText string) ([]byte, error) {
 	plaintext, err := aesDecrypt(cipherText, linuxApiKey, "", "ecb", "hex")
@@ -433,3 +433,41 @@ func TestHexDigest(t *testing.T) {
 		}
 	}
 }
+
+func TestLinuxApiForward(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "weapi",
+			url:  "https://music.163.com/weapi/song/enhance/player/url",
+			want: `{"method":"POST","url":"https://music.163.com/api/song/enhance/player/url","params":{"ids":"[1]"}}`,
+		},
+		{
+			name: "linuxapi",
+			url:  "https://music.163.com/linuxapi/v1/playlist/detail",
+			want: `{"method":"POST","url":"https://music.163.com/api/v1/playlist/detail","params":{"ids":"[1]"}}`,
+		},
+		{
+			name: "api host",
+			url:  "https://openapi.music.163.com/eapi/v1/playlist/detail",
+			want: `{"method":"POST","url":"https://openapi.music.163.com/api/v1/playlist/detail","params":{"ids":"[1]"}}`,
+		},
+		{
+			name: "api in path",
+			url:  "https://music.163.com/api/openapi/detail",
+			want: `{"method":"POST","url":"https://music.163.com/api/openapi/detail","params":{"ids":"[1]"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LinuxApiForward(tt.url, map[string]string{"ids": "[1]"})
+			assert.NoError(t, err)
+			plaintext, err := LinuxApiDecrypt(got["eparams"])
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(plaintext))
+		})
+	}
+}
@@ -0,0 +1,352 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package dlna 通过SSDP发现局域网中的DLNA/UPnP媒体渲染器(智能音箱、电视等),
+// 并通过AVTransport服务推送播放地址及控制播放.
+package dlna
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// ssdpAddr SSDP组播地址
+	ssdpAddr = "239.255.255.250:1900"
+	// AVTransport AVTransport服务类型
+	AVTransport = "urn:schemas-upnp-org:service:AVTransport:1"
+)
+
+// 播放状态 see: AVTransport:1 TransportState
+const (
+	StatePlaying        = "PLAYING"
+	StateStopped        = "STOPPED"
+	StatePaused         = "PAUSED_PLAYBACK"
+	StateTransitioning  = "TRANSITIONING"
+	StateNoMediaPresent = "NO_MEDIA_PRESENT"
+)
+
+// Device 支持AVTransport服务的媒体渲染器
+type Device struct {
+	Name       string // 设备名称
+	Model      string // 设备型号
+	UDN        string // 设备唯一标识
+	Location   string // 设备描述文件地址
+	ControlURL string // AVTransport服务控制地址
+}
+
+func (d *Device) String() string {
+	return fmt.Sprintf("%s (%s)", d.Name, d.Host())
+}
+
+// Host 设备地址
+func (d *Device) Host() string {
+	u, err := url.Parse(d.Location)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// Discover 在timeout时间内搜索局域网中支持AVTransport服务的设备,重复响应的设备只返回一次
+func Discover(ctx context.Context, timeout time.Duration) ([]*Device, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, fmt.Errorf("ListenPacket: %w", err)
+	}
+	defer conn.Close()
+
+	addr, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	if err != nil {
+		return nil, fmt.Errorf("ResolveUDPAddr: %w", err)
+	}
+	var search = "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddr + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n" +
+		"ST: " + AVTransport + "\r\n\r\n"
+	// UDP可能丢包,多发送几次
+	for i := 0; i < 3; i++ {
+		if _, err := conn.WriteTo([]byte(search), addr); err != nil {
+			return nil, fmt.Errorf("WriteTo: %w", err)
+		}
+	}
+
+	var deadline = time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("SetReadDeadline: %w", err)
+	}
+
+	var (
+		locations = make(map[string]struct{})
+		devices   []*Device
+		buf       = make([]byte, 8192)
+	)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				return devices, nil
+			}
+			return devices, fmt.Errorf("ReadFrom: %w", err)
+		}
+		location, err := parseSearchResponse(buf[:n])
+		if err != nil {
+			continue
+		}
+		if _, ok := locations[location]; ok {
+			continue
+		}
+		locations[location] = struct{}{}
+
+		device, err := Describe(ctx, location)
+		if err != nil {
+			continue
+		}
+		devices = append(devices, device)
+	}
+}
+
+// parseSearchResponse 解析M-SEARCH响应中的设备描述文件地址
+func parseSearchResponse(data []byte) (string, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	var location = resp.Header.Get("Location")
+	if location == "" {
+		return "", errors.New("location is empty")
+	}
+	return location, nil
+}
+
+// description 设备描述文件
+type description struct {
+	URLBase string `xml:"URLBase"`
+	Device  struct {
+		device
+		DeviceList []device `xml:"deviceList>device"`
+	} `xml:"device"`
+}
+
+type device struct {
+	FriendlyName string `xml:"friendlyName"`
+	ModelName    string `xml:"modelName"`
+	UDN          string `xml:"UDN"`
+	ServiceList  []struct {
+		ServiceType string `xml:"serviceType"`
+		ControlURL  string `xml:"controlURL"`
+	} `xml:"serviceList>service"`
+}
+
+// Describe 获取设备描述文件并查找AVTransport服务,支持AVTransport服务位于嵌套设备中的情况
+func Describe(ctx context.Context, location string) (*Device, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("NewRequestWithContext: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("describe: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("describe: http status code: %d", resp.StatusCode)
+	}
+
+	var desc description
+	if err := xml.NewDecoder(resp.Body).Decode(&desc); err != nil {
+		return nil, fmt.Errorf("decode description: %w", err)
+	}
+	base, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("parse location: %w", err)
+	}
+	if desc.URLBase != "" {
+		if u, err := url.Parse(desc.URLBase); err == nil {
+			base = u
+		}
+	}
+
+	for _, d := range append([]device{desc.Device.device}, desc.Device.DeviceList...) {
+		for _, s := range d.ServiceList {
+			if !strings.HasPrefix(s.ServiceType, "urn:schemas-upnp-org:service:AVTransport:") {
+				continue
+			}
+			control, err := base.Parse(strings.TrimSpace(s.ControlURL))
+			if err != nil {
+				return nil, fmt.Errorf("parse controlURL: %w", err)
+			}
+			var name = d.FriendlyName
+			if name == "" {
+				name = desc.Device.FriendlyName
+			}
+			return &Device{
+				Name:       name,
+				Model:      d.ModelName,
+				UDN:        d.UDN,
+				Location:   location,
+				ControlURL: control.String(),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s does not support AVTransport", desc.Device.FriendlyName)
+}
+
+// Metadata 推送的媒体信息,渲染器用于显示歌曲名称、封面等
+type Metadata struct {
+	Title       string
+	Artist      string
+	Album       string
+	AlbumArtURI string
+	ContentType string // 音频类型 eg: audio/mpeg、audio/flac
+}
+
+// didl 生成DIDL-Lite格式的媒体信息
+func (m *Metadata) didl(uri string) string {
+	if m == nil {
+		return ""
+	}
+	var contentType = m.ContentType
+	if contentType == "" {
+		contentType = "audio/mpeg"
+	}
+	var b strings.Builder
+	b.WriteString(`<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/">`)
+	b.WriteString(`<item id="0" parentID="-1" restricted="1">`)
+	fmt.Fprintf(&b, "<dc:title>%s</dc:title>", html.EscapeString(m.Title))
+	if m.Artist != "" {
+		fmt.Fprintf(&b, "<upnp:artist>%s</upnp:artist><dc:creator>%s</dc:creator>", html.EscapeString(m.Artist), html.EscapeString(m.Artist))
+	}
+	if m.Album != "" {
+		fmt.Fprintf(&b, "<upnp:album>%s</upnp:album>", html.EscapeString(m.Album))
+	}
+	if m.AlbumArtURI != "" {
+		fmt.Fprintf(&b, "<upnp:albumArtURI>%s</upnp:albumArtURI>", html.EscapeString(m.AlbumArtURI))
+	}
+	b.WriteString("<upnp:class>object.item.audioItem.musicTrack</upnp:class>")
+	fmt.Fprintf(&b, `<res protocolInfo="http-get:*:%s:*">%s</res>`, contentType, html.EscapeString(uri))
+	b.WriteString("</item></DIDL-Lite>")
+	return b.String()
+}
+
+// SetURI 设置播放地址
+func (d *Device) SetURI(ctx context.Context, uri string, meta *Metadata) error {
+	_, err := d.call(ctx, "SetAVTransportURI", [][2]string{
+		{"InstanceID", "0"},
+		{"CurrentURI", uri},
+		{"CurrentURIMetaData", meta.didl(uri)},
+	})
+	return err
+}
+
+// Play 开始播放
+func (d *Device) Play(ctx context.Context) error {
+	_, err := d.call(ctx, "Play", [][2]string{{"InstanceID", "0"}, {"Speed", "1"}})
+	return err
+}
+
+// Pause 暂停播放
+func (d *Device) Pause(ctx context.Context) error {
+	_, err := d.call(ctx, "Pause", [][2]string{{"InstanceID", "0"}})
+	return err
+}
+
+// Stop 停止播放
+func (d *Device) Stop(ctx context.Context) error {
+	_, err := d.call(ctx, "Stop", [][2]string{{"InstanceID", "0"}})
+	return err
+}
+
+// TransportState 获取当前播放状态 eg: PLAYING、STOPPED
+func (d *Device) TransportState(ctx context.Context) (string, error) {
+	data, err := d.call(ctx, "GetTransportInfo", [][2]string{{"InstanceID", "0"}})
+	if err != nil {
+		return "", err
+	}
+	var reply struct {
+		State string `xml:"Body>GetTransportInfoResponse>CurrentTransportState"`
+	}
+	if err := xml.Unmarshal(data, &reply); err != nil {
+		return "", fmt.Errorf("decode GetTransportInfo: %w", err)
+	}
+	return reply.State, nil
+}
+
+// soapFault 渲染器返回的错误
+type soapFault struct {
+	Code        int    `xml:"Body>Fault>detail>UPnPError>errorCode"`
+	Description string `xml:"Body>Fault>detail>UPnPError>errorDescription"`
+}
+
+// call 调用AVTransport服务的SOAP接口,参数需按接口定义的顺序传入
+func (d *Device) call(ctx context.Context, action string, args [][2]string) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	b.WriteString(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(&b, `<u:%s xmlns:u="%s">`, action, AVTransport)
+	for _, arg := range args {
+		fmt.Fprintf(&b, "<%s>%s</%s>", arg[0], html.EscapeString(arg[1]), arg[0])
+	}
+	fmt.Fprintf(&b, "</u:%s></s:Body></s:Envelope>", action)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.ControlURL, strings.NewReader(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", fmt.Sprintf(`"%s#%s"`, AVTransport, action))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", action, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var fault soapFault
+		if err := xml.Unmarshal(data, &fault); err == nil && fault.Code != 0 {
+			return nil, fmt.Errorf("%s: upnp error %d %s", action, fault.Code, fault.Description)
+		}
+		return nil, fmt.Errorf("%s: http status code: %d", action, resp.StatusCode)
+	}
+	return data, nil
+}
@@ -0,0 +1,119 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package dlna
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:MediaRenderer:1</deviceType>
+    <friendlyName>客厅音箱</friendlyName>
+    <modelName>Speaker</modelName>
+    <UDN>uuid:1234</UDN>
+    <deviceList>
+      <device>
+        <friendlyName>客厅音箱 Renderer</friendlyName>
+        <modelName>Renderer</modelName>
+        <UDN>uuid:5678</UDN>
+        <serviceList>
+          <service>
+            <serviceType>urn:schemas-upnp-org:service:AVTransport:1</serviceType>
+            <controlURL>/upnp/control/AVTransport1</controlURL>
+          </service>
+        </serviceList>
+      </device>
+    </deviceList>
+  </device>
+</root>`
+
+func TestParseSearchResponse(t *testing.T) {
+	location, err := parseSearchResponse([]byte("HTTP/1.1 200 OK\r\nCACHE-CONTROL: max-age=1800\r\nLOCATION: http://192.168.1.10:49152/description.xml\r\nST: urn:schemas-upnp-org:service:AVTransport:1\r\n\r\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "http://192.168.1.10:49152/description.xml", location)
+
+	_, err = parseSearchResponse([]byte("HTTP/1.1 200 OK\r\nST: upnp:rootdevice\r\n\r\n"))
+	assert.Error(t, err)
+}
+
+func TestDevice(t *testing.T) {
+	var actions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/description.xml":
+			_, _ = io.WriteString(w, testDescription)
+		case "/upnp/control/AVTransport1":
+			body, _ := io.ReadAll(r.Body)
+			var action = r.Header.Get("SOAPAction")
+			actions = append(actions, action)
+			switch {
+			case strings.HasSuffix(action, `#SetAVTransportURI"`):
+				assert.Contains(t, string(body), "&lt;dc:title&gt;晴天&lt;/dc:title&gt;")
+				assert.Contains(t, string(body), "<CurrentURI>http://127.0.0.1/1.mp3?a=1&amp;b=2</CurrentURI>")
+			case strings.HasSuffix(action, `#GetTransportInfo"`):
+				_, _ = io.WriteString(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:GetTransportInfoResponse xmlns:u="urn:schemas-upnp-org:service:AVTransport:1"><CurrentTransportState>PLAYING</CurrentTransportState><CurrentTransportStatus>OK</CurrentTransportStatus><CurrentSpeed>1</CurrentSpeed></u:GetTransportInfoResponse></s:Body></s:Envelope>`)
+			case strings.HasSuffix(action, `#Pause"`):
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = io.WriteString(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail><UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>701</errorCode><errorDescription>Transition not available</errorDescription></UPnPError></detail></s:Fault></s:Body></s:Envelope>`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var ctx = context.Background()
+	device, err := Describe(ctx, srv.URL+"/description.xml")
+	assert.NoError(t, err)
+	assert.Equal(t, "客厅音箱 Renderer", device.Name)
+	assert.Equal(t, "uuid:5678", device.UDN)
+	assert.Equal(t, srv.URL+"/upnp/control/AVTransport1", device.ControlURL)
+
+	assert.NoError(t, device.SetURI(ctx, "http://127.0.0.1/1.mp3?a=1&b=2", &Metadata{Title: "晴天", Artist: "周杰伦"}))
+	assert.NoError(t, device.Play(ctx))
+	state, err := device.TransportState(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, StatePlaying, state)
+
+	err = device.Pause(ctx)
+	assert.ErrorContains(t, err, "upnp error 701 Transition not available")
+	assert.Equal(t, []string{
+		`"urn:schemas-upnp-org:service:AVTransport:1#SetAVTransportURI"`,
+		`"urn:schemas-upnp-org:service:AVTransport:1#Play"`,
+		`"urn:schemas-upnp-org:service:AVTransport:1#GetTransportInfo"`,
+		`"urn:schemas-upnp-org:service:AVTransport:1#Pause"`,
+	}, actions)
+
+	_, err = Describe(ctx, srv.URL+"/not-found.xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package hook 通过系统shell执行用户配置的外部命令.
+// 命令中的变量以环境变量引用的方式展开,变量值不会被shell解析,即使歌曲名称中包含引号、&、|、;等特殊字符也不会造成命令注入.
+package hook
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Var 命令中可用的变量,命令中的 {Name} 会被替换为对环境变量 Env 的引用
+type Var struct {
+	Name  string // 变量名 eg: file
+	Env   string // 环境变量名 eg: NCM_FILE
+	Value string // 变量值
+}
+
+// Expand 将命令中的变量替换为对应环境变量的引用,返回替换后的命令以及需要设置的环境变量。
+// 引用两边已加上引号,命令中无需再加引号。
+func Expand(command string, vars []Var) (string, []string) {
+	var (
+		pairs = make([]string, 0, len(vars)*2)
+		env   = make([]string, 0, len(vars))
+	)
+	for _, v := range vars {
+		pairs = append(pairs, "{"+v.Name+"}", ref(v.Env))
+		env = append(env, v.Env+"="+v.Value)
+	}
+	return strings.NewReplacer(pairs...).Replace(command), env
+}
+
+// Command 返回通过系统shell执行command的命令,Windows下使用cmd,其他系统使用sh。
+// 返回的命令继承当前进程的环境变量,并追加vars对应的环境变量。
+func Command(ctx context.Context, command string, vars []Var) *exec.Cmd {
+	line, env := Expand(command, vars)
+	cmd := shell(ctx, line)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd
+}
@@ -0,0 +1,59 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package hook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	line, env := Expand("beet import {file} {unknown}", []Var{{Name: "file", Env: "NCM_FILE", Value: "a b.flac"}})
+	assert.Equal(t, "beet import "+ref("NCM_FILE")+" {unknown}", line)
+	assert.Equal(t, []string{"NCM_FILE=a b.flac"}, env)
+}
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is not available")
+	}
+	var (
+		dir     = t.TempDir()
+		out     = filepath.Join(dir, "out")
+		pwned   = filepath.Join(dir, "pwned")
+		hostile = `a'"; touch ` + pwned + ` & $(touch ` + pwned + `) | ^%PATH%<>` + "`touch " + pwned + "`"
+	)
+	cmd := Command(context.Background(), "printf %s {title} > "+out, []Var{{Name: "title", Env: "NCM_TITLE", Value: hostile}})
+	assert.NoError(t, cmd.Run())
+
+	data, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Equal(t, hostile, string(data))
+	assert.NoFileExists(t, pwned)
+}
@@ -24,12 +24,15 @@
 package log
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -60,17 +63,43 @@ type Config struct {
 	Format string            `json:"format,omitempty" yaml:"format"` // text(default) json
 	Level  string            `json:"level,omitempty" yaml:"level"`   // debug(default) < info < warn < error
 	Stdout bool              `json:"stdout,omitempty" yaml:"stdout"`
+	Daily  bool              `json:"daily,omitempty" yaml:"daily"` // 是否按天滚动日志文件,同时仍按 Rotate.MaxSize 大小滚动
 	Rotate lumberjack.Logger `json:"rotate" yaml:"rotate"`
 }
 
 func (c *Config) Validate() error {
+	if _, err := ParseLevel(c.Level); err != nil {
+		return err
+	}
+	switch c.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("log format is not support: %s", c.Format)
+	}
 	return nil
 }
 
+// ParseLevel 解析日志级别,为空时返回debug级别
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "", "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelDebug, fmt.Errorf("log level is not support: %s", level)
+	}
+}
+
 type Logger struct {
-	cfg   *Config
-	l     *slog.Logger
-	level *slog.LevelVar
+	cfg     *Config
+	l       *slog.Logger
+	level   *slog.LevelVar
+	console *console
 }
 
 func New(cfg *Config) *Logger {
@@ -82,18 +111,8 @@ func New(cfg *Config) *Logger {
 	}
 
 	var level slog.LevelVar
-	switch cfg.Level {
-	case "debug":
-		level.Set(slog.LevelDebug)
-	case "info":
-		level.Set(slog.LevelInfo)
-	case "level":
-		level.Set(slog.LevelWarn)
-	case "error":
-		level.Set(slog.LevelError)
-	default:
-		level.Set(slog.LevelDebug)
-	}
+	lv, _ := ParseLevel(cfg.Level)
+	level.Set(lv)
 
 	var opts = slog.HandlerOptions{
 		AddSource:   true,
@@ -101,11 +120,19 @@ func New(cfg *Config) *Logger {
 		ReplaceAttr: nil,
 	}
 
-	var w []io.Writer
+	var (
+		w   []io.Writer
+		con *console
+	)
 	if cfg.Stdout {
-		w = append(w, os.Stderr)
+		con = &console{w: os.Stderr}
+		w = append(w, con)
+	}
+	if cfg.Daily {
+		w = append(w, &daily{rotate: &cfg.Rotate})
+	} else {
+		w = append(w, &cfg.Rotate)
 	}
-	w = append(w, &cfg.Rotate)
 
 	var h slog.Handler
 	switch cfg.Format {
@@ -119,13 +146,25 @@ func New(cfg *Config) *Logger {
 	h = h.WithAttrs([]slog.Attr{slog.String("app", cfg.App)})
 
 	l := Logger{
-		cfg:   cfg,
-		l:     slog.New(h),
-		level: &level,
+		cfg:     cfg,
+		l:       slog.New(h),
+		level:   &level,
+		console: con,
 	}
 	return &l
 }
 
+// Hold 暂存输出到终端的日志直到调用返回的函数,避免日志打乱进度条等终端输出,
+// 日志文件不受影响。可嵌套调用,全部释放后按顺序输出暂存的日志。
+func (l *Logger) Hold() (release func()) {
+	if l == nil || l.console == nil {
+		return func() {}
+	}
+	l.console.hold()
+	var once sync.Once
+	return func() { once.Do(l.console.release) }
+}
+
 func (l *Logger) Close() error {
 	if l == nil || l.cfg == nil {
 		return nil
@@ -138,9 +177,57 @@ func (l *Logger) Logger() *slog.Logger {
 }
 
 func (l *Logger) SetLevel(level slog.Level) {
+	if l.level == nil {
+		return
+	}
 	l.level.Set(level)
 }
 
+// FromSlog 使用已有的 slog.Logger 创建日志对象,用于将日志输出到调用方的日志系统中
+func FromSlog(l *slog.Logger) *Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &Logger{l: l}
+}
+
+// Discard 返回丢弃所有日志的日志对象
+func Discard() *Logger {
+	return FromSlog(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// handler 返回日志处理器,l 为空时使用 Default
+func (l *Logger) handler() slog.Handler {
+	if l == nil || l.l == nil {
+		return handler()
+	}
+	return l.l.Handler()
+}
+
+func (l *Logger) Debug(format string, args ...any) {
+	log(l.handler(), slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Info(format string, args ...any) {
+	log(l.handler(), slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Warn(format string, args ...any) {
+	log(l.handler(), slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Error(format string, args ...any) {
+	log(l.handler(), slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// handler 返回默认日志处理器,未初始化 Default 时使用 slog.Default
+func handler() slog.Handler {
+	if Default == nil || Default.l == nil {
+		return slog.Default().Handler()
+	}
+	return Default.l.Handler()
+}
+
 func log(h slog.Handler, lv slog.Level, msg string, args ...any) {
 	// 需要检查是否满足日志级别？
 	if !h.Enabled(ctx, lv) {
@@ -156,43 +243,122 @@ func log(h slog.Handler, lv slog.Level, msg string, args ...any) {
 }
 
 func Debug(format string, args ...any) {
-	log(Default.l.Handler(), slog.LevelDebug, fmt.Sprintf(format, args...))
+	log(handler(), slog.LevelDebug, fmt.Sprintf(format, args...))
 }
 
 func Info(format string, args ...any) {
-	log(Default.l.Handler(), slog.LevelInfo, fmt.Sprintf(format, args...))
+	log(handler(), slog.LevelInfo, fmt.Sprintf(format, args...))
 }
 
 func Warn(format string, args ...any) {
-	log(Default.l.Handler(), slog.LevelWarn, fmt.Sprintf(format, args...))
+	log(handler(), slog.LevelWarn, fmt.Sprintf(format, args...))
 }
 
 func Error(format string, args ...any) {
-	log(Default.l.Handler(), slog.LevelError, fmt.Sprintf(format, args...))
+	log(handler(), slog.LevelError, fmt.Sprintf(format, args...))
 }
 
 func Fatal(format string, args ...any) {
-	log(Default.l.Handler(), slog.LevelError, fmt.Sprintf(format, args...))
+	log(handler(), slog.LevelError, fmt.Sprintf(format, args...))
 	os.Exit(1)
 }
 
 func DebugW(msg string, args ...any) {
-	log(Default.l.Handler(), slog.LevelDebug, msg, args...)
+	log(handler(), slog.LevelDebug, msg, args...)
 }
 
 func InfoW(msg string, args ...any) {
-	log(Default.l.Handler(), slog.LevelInfo, msg, args...)
+	log(handler(), slog.LevelInfo, msg, args...)
 }
 
 func WarnW(msg string, args ...any) {
-	log(Default.l.Handler(), slog.LevelWarn, msg, args...)
+	log(handler(), slog.LevelWarn, msg, args...)
 }
 
 func ErrorW(msg string, args ...any) {
-	log(Default.l.Handler(), slog.LevelError, msg, args...)
+	log(handler(), slog.LevelError, msg, args...)
 }
 
 func FatalW(msg string, args ...any) {
-	log(Default.l.Handler(), slog.LevelError, msg, args...)
+	log(handler(), slog.LevelError, msg, args...)
 	os.Exit(1)
 }
+
+// console 终端日志输出,hold期间日志暂存到缓冲区
+type console struct {
+	mu    sync.Mutex
+	w     io.Writer
+	held  int
+	cache bytes.Buffer
+}
+
+func (c *console) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.held > 0 {
+		return c.cache.Write(p)
+	}
+	return c.w.Write(p)
+}
+
+func (c *console) hold() {
+	c.mu.Lock()
+	c.held++
+	c.mu.Unlock()
+}
+
+func (c *console) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.held--; c.held > 0 {
+		return
+	}
+	if c.cache.Len() > 0 {
+		_, _ = c.w.Write(c.cache.Bytes())
+		c.cache.Reset()
+	}
+}
+
+// daily 按天滚动日志文件,日期变化后写入前先滚动
+type daily struct {
+	mu     sync.Mutex
+	rotate *lumberjack.Logger
+	day    string
+}
+
+func (d *daily) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var now = time.Now()
+	if !d.rotate.LocalTime {
+		now = now.UTC()
+	}
+	var day = now.Format(time.DateOnly)
+	if d.day == "" {
+		d.day = d.lastDay()
+	}
+	if d.day != "" && d.day != day {
+		if err := d.rotate.Rotate(); err != nil {
+			return 0, err
+		}
+	}
+	d.day = day
+	return d.rotate.Write(p)
+}
+
+// lastDay 返回已存在日志文件的最后修改日期,程序重启后跨天时也能及时滚动
+func (d *daily) lastDay() string {
+	var name = d.rotate.Filename
+	if name == "" {
+		return ""
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return ""
+	}
+	var mod = info.ModTime()
+	if !d.rotate.LocalTime {
+		mod = mod.UTC()
+	}
+	return mod.Format(time.DateOnly)
+}
@@ -24,9 +24,16 @@
 package log
 
 import (
+	"bytes"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func init() {
@@ -42,3 +49,88 @@ func TestPrint(t *testing.T) {
 	Info("can not print")
 	Fatal("hello fatal")
 }
+
+func TestParseLevel(t *testing.T) {
+	var tests = []struct {
+		level   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{level: "", want: slog.LevelDebug},
+		{level: "debug", want: slog.LevelDebug},
+		{level: "INFO", want: slog.LevelInfo},
+		{level: "warn", want: slog.LevelWarn},
+		{level: "error", want: slog.LevelError},
+		{level: "level", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.level)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseLevel(%q) err = %v, wantErr %v", tt.level, err, tt.wantErr)
+		}
+		if err == nil && got != tt.want {
+			t.Fatalf("ParseLevel(%q) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestHold(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		l   = New(&Config{Level: "info", Stdout: true, Rotate: lumberjack.Logger{Filename: filepath.Join(t.TempDir(), "ncm.log")}})
+	)
+	defer l.Close()
+	l.console.w = &buf
+
+	release := l.Hold()
+	l.Logger().Info("held")
+	if buf.Len() != 0 {
+		t.Fatalf("log should be held, got: %s", buf.String())
+	}
+	release()
+	release()
+	if !strings.Contains(buf.String(), "held") {
+		t.Fatalf("log should be flushed after release, got: %s", buf.String())
+	}
+	l.Logger().Info("direct")
+	if !strings.Contains(buf.String(), "direct") {
+		t.Fatalf("log should be written directly, got: %s", buf.String())
+	}
+}
+
+func TestDaily(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		rotate = &lumberjack.Logger{Filename: filepath.Join(dir, "ncm.log"), LocalTime: true}
+		d      = &daily{rotate: rotate}
+	)
+	defer rotate.Close()
+
+	if _, err := d.Write([]byte("today\n")); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+	d.day = time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+	if _, err := d.Write([]byte("tomorrow\n")); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %s", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("want 2 log files after rotate, got %d", len(entries))
+	}
+}
+
+func TestFromSlog(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		l   = FromSlog(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	)
+	l.Debug("hidden %d", 1)
+	l.Info("hello %s", "sdk")
+	l.SetLevel(slog.LevelDebug)
+	if got := buf.String(); strings.Contains(got, "hidden") || !strings.Contains(got, "hello sdk") {
+		t.Fatalf("FromSlog output = %q", got)
+	}
+}
@@ -0,0 +1,232 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package metrics 以 Prometheus 文本格式输出计数器、仪表盘等指标,
+// 仅实现守护进程监控需要的部分功能,不依赖 Prometheus 客户端库.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 指标类型
+const (
+	TypeCounter = "counter"
+	TypeGauge   = "gauge"
+)
+
+// Registry 指标注册表,并发安全
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*Vec
+}
+
+// NewRegistry 创建指标注册表
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter 注册只增不减的计数器,labels为标签名称
+func (r *Registry) Counter(name, help string, labels ...string) *Vec {
+	return r.register(name, help, TypeCounter, labels)
+}
+
+// Gauge 注册可任意设置的仪表盘
+func (r *Registry) Gauge(name, help string, labels ...string) *Vec {
+	return r.register(name, help, TypeGauge, labels)
+}
+
+func (r *Registry) register(name, help, kind string, labels []string) *Vec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.metrics {
+		if m.name == name {
+			panic(fmt.Sprintf("metrics: %s already registered", name))
+		}
+	}
+	v := &Vec{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series)}
+	r.metrics = append(r.metrics, v)
+	return v
+}
+
+// WriteTo 按注册顺序以 Prometheus 文本格式输出所有指标
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	var list = append([]*Vec(nil), r.metrics...)
+	r.mu.Unlock()
+
+	var (
+		cw  = &countWriter{w: w}
+		buf = bufio.NewWriter(cw)
+	)
+	for _, m := range list {
+		m.write(buf)
+	}
+	err := buf.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP 实现 http.Handler 用于 Prometheus 抓取
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+// Vec 一组名称相同、标签值不同的指标
+type Vec struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labels string // 格式化后的标签 eg: {code="200"}
+	value  float64
+}
+
+// Add 累加指标值,values为标签值,数量需要与注册时的标签名称一致。计数器不允许累加负数
+func (v *Vec) Add(delta float64, values ...string) {
+	if v.kind == TypeCounter && delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s can not decrease", v.name))
+	}
+	v.with(values, func(s *series) { s.value += delta })
+}
+
+// Inc 指标值加1
+func (v *Vec) Inc(values ...string) {
+	v.Add(1, values...)
+}
+
+// Set 设置仪表盘的值
+func (v *Vec) Set(value float64, values ...string) {
+	if v.kind != TypeGauge {
+		panic(fmt.Sprintf("metrics: %s is not a gauge", v.name))
+	}
+	v.with(values, func(s *series) { s.value = value })
+}
+
+// Value 返回指标当前值,不存在时返回0
+func (v *Vec) Value(values ...string) float64 {
+	var key = v.key(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok := v.series[key]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (v *Vec) with(values []string, fn func(s *series)) {
+	var key = v.key(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labels: key}
+		v.series[key] = s
+	}
+	fn(s)
+}
+
+// key 格式化标签作为序列的唯一标识
+func (v *Vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s want %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range v.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escape(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func (v *Vec) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(v.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.kind)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// 没有标签的指标即使没有数据也输出0,便于告警规则判断
+	if len(v.labels) == 0 && len(v.series) == 0 {
+		fmt.Fprintf(w, "%s 0\n", v.name)
+		return
+	}
+	var keys = make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", v.name, k, formatValue(v.series[k].value))
+	}
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
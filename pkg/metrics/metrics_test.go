@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	var (
+		r          = NewRegistry()
+		calls      = r.Counter("api_calls_total", "api calls", "endpoint", "code")
+		downloaded = r.Counter("download_bytes_total", "downloaded bytes")
+		last       = r.Gauge("task_last_run", "last run", "job")
+		idle       = r.Counter("idle_total", "never increased")
+	)
+	_ = idle
+	calls.Inc("/weapi/b", "200")
+	calls.Inc("/weapi/a", "200")
+	calls.Add(2, "/weapi/a", "200")
+	calls.Inc("/weapi/a", `-"1`)
+	downloaded.Add(1.5e9)
+	last.Set(1700000000, "sign")
+	last.Set(1700000001, "sign")
+
+	if got := calls.Value("/weapi/a", "200"); got != 3 {
+		t.Fatalf("Value = %v, want 3", got)
+	}
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %s", err)
+	}
+	want := `# HELP api_calls_total api calls
+# TYPE api_calls_total counter
+api_calls_total{endpoint="/weapi/a",code="-\"1"} 1
+api_calls_total{endpoint="/weapi/a",code="200"} 3
+api_calls_total{endpoint="/weapi/b",code="200"} 1
+# HELP download_bytes_total downloaded bytes
+# TYPE download_bytes_total counter
+download_bytes_total 1.5e+09
+# HELP task_last_run last run
+# TYPE task_last_run gauge
+task_last_run{job="sign"} 1.700000001e+09
+# HELP idle_total never increased
+# TYPE idle_total counter
+idle_total 0
+`
+	if buf.String() != want {
+		t.Fatalf("WriteTo got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	var r = NewRegistry()
+	r.Counter("runs_total", "runs").Inc()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("Content-Type = %s", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "runs_total 1\n") {
+		t.Fatalf("body = %s", rec.Body.String())
+	}
+}
+
+func TestPanics(t *testing.T) {
+	var r = NewRegistry()
+	c := r.Counter("c_total", "c", "a")
+	for name, fn := range map[string]func(){
+		"decrease":  func() { c.Add(-1, "x") },
+		"labels":    func() { c.Inc() },
+		"set":       func() { c.Set(1, "x") },
+		"duplicate": func() { r.Gauge("c_total", "c") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
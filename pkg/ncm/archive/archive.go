@@ -0,0 +1,181 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package archive 将本地歌曲打包为zip并以流的方式输出,ncm文件在打包时解密并写入歌曲tag.
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/ncm"
+	"github.com/chaunsin/netease-cloud-music/pkg/ncm/tag"
+)
+
+// File 待打包的本地歌曲文件
+type File struct {
+	Path string // 本地文件路径
+	Name string // zip中的文件名,不包含扩展名,为空时使用本地文件名
+}
+
+// Serve 以附件形式输出zip,name为下载文件名,不包含扩展名。
+// 开始输出后出错时响应已无法修改,只能中断输出,因此错误交由调用方记录。
+func Serve(w http.ResponseWriter, name string, files []File) error {
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + ".zip"}))
+	return Write(w, files)
+}
+
+// Write 将files依次写入zip,音频已经过压缩因此只存储不再压缩。
+// ncm文件解密并写入tag后打包,扩展名使用实际的音频格式,其他文件原样打包。
+func Write(w io.Writer, files []File) error {
+	var (
+		zw    = zip.NewWriter(w)
+		names = make(map[string]int, len(files))
+	)
+	for _, f := range files {
+		if err := add(zw, names, f); err != nil {
+			return fmt.Errorf("%s: %w", f.Path, err)
+		}
+	}
+	return zw.Close()
+}
+
+func add(zw *zip.Writer, names map[string]int, f File) error {
+	var (
+		path = f.Path
+		ext  = filepath.Ext(path)
+	)
+	if strings.EqualFold(ext, ".ncm") {
+		tmp, format, err := decode(path)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp)
+		path, ext = tmp, "."+format
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	var base = f.Name
+	if base == "" {
+		base = strings.TrimSuffix(filepath.Base(f.Path), filepath.Ext(f.Path))
+	}
+	// 同名文件依次添加序号
+	var name = base + ext
+	if n := names[name]; n > 0 {
+		names[name]++
+		name = fmt.Sprintf("%s(%d)%s", base, n, ext)
+	} else {
+		names[name] = 1
+	}
+
+	dst, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: info.ModTime()})
+	if err != nil {
+		return fmt.Errorf("CreateHeader: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("copy: %w", err)
+	}
+	return nil
+}
+
+// decode 将ncm文件解密到临时文件并写入tag,返回临时文件路径及音频格式,调用方负责删除临时文件。
+// 写入tag失败时仍返回解密后的音频。
+func decode(path string) (string, string, error) {
+	file, err := ncm.Open(path)
+	if err != nil {
+		return "", "", fmt.Errorf("ncm.Open: %w", err)
+	}
+	defer file.Close()
+
+	tmp, err := os.CreateTemp("", "ncm-archive-*")
+	if err != nil {
+		return "", "", fmt.Errorf("CreateTemp: %w", err)
+	}
+	if err := file.DecodeMusic(tmp); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", "", fmt.Errorf("DecodeMusic: %w", err)
+	}
+	// 先关闭文件再写入tag,避免Windows下无法修改已打开的文件
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", "", fmt.Errorf("close: %w", err)
+	}
+
+	format, err := audioFormat(file.NCM, tmp.Name())
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", "", err
+	}
+	if err := tag.NewFromNCM(file.NCM, tmp.Name()); err != nil {
+		log.Warn("[archive] %s set tag: %s", path, err)
+	}
+	return tmp.Name(), format, nil
+}
+
+// audioFormat 返回ncm中记录的音频格式,未记录时根据解密后的文件头判断
+func audioFormat(n *ncm.NCM, decoded string) (string, error) {
+	if meta := n.Metadata(); meta != nil {
+		switch meta.GetType() {
+		case ncm.MetadataTypeMusic:
+			if f := meta.GetMusic().Format; f != "" {
+				return strings.ToLower(f), nil
+			}
+		case ncm.MetadataTypeDJ:
+			if f := meta.GetDJ().MainMusic.Format; f != "" {
+				return strings.ToLower(f), nil
+			}
+		}
+	}
+	file, err := os.Open(decoded)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	var head = make([]byte, 4)
+	if _, err := io.ReadFull(file, head); err != nil {
+		return "", fmt.Errorf("read header: %w", err)
+	}
+	if bytes.Equal(head, []byte("fLaC")) {
+		return "flac", nil
+	}
+	return "mp3", nil
+}
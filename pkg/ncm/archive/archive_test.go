@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/dhowden/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe(t *testing.T) {
+	var files = []File{
+		{Path: "../testdata/BOE - 822.ncm"},
+		{Path: "../testdata/not_supported_by_encoding.mp3", Name: "song"},
+		{Path: "../testdata/not_supported_by_encoding.mp3", Name: "song"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, Serve(w, "专辑", files))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "attachment; filename*=utf-8''%E4%B8%93%E8%BE%91.zip", resp.Header.Get("Content-Disposition"))
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	assert.NoError(t, err)
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		assert.Equal(t, zip.Store, f.Method)
+	}
+	assert.Equal(t, []string{"BOE - 822.mp3", "song.mp3", "song(1).mp3"}, names)
+
+	// ncm解密后带有歌曲tag
+	rc, err := zr.File[0].Open()
+	assert.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	_ = rc.Close()
+	assert.NoError(t, err)
+	meta, err := tag.ReadFrom(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, tag.MP3, meta.FileType())
+	assert.NotEmpty(t, meta.Title())
+	assert.NotEmpty(t, meta.Artist())
+
+	// 普通文件原样打包
+	raw, err := os.ReadFile("../testdata/not_supported_by_encoding.mp3")
+	assert.NoError(t, err)
+	rc, err = zr.File[1].Open()
+	assert.NoError(t, err)
+	data, err = io.ReadAll(rc)
+	_ = rc.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, raw, data)
+}
+
+func TestWriteMissingFile(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, Write(&buf, []File{{Path: "../testdata/not_exist.mp3"}}))
+}
@@ -26,6 +26,8 @@ package ncm
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
 )
 
 type Artist struct {
@@ -40,7 +42,7 @@ func (a *Artist) UnmarshalJSON(data []byte) error {
 	}
 
 	if len(v) != 2 {
-		fmt.Printf("ncm: parse artist err,len:%v type:%T value:%+v\n", len(v), v, v)
+		log.Warn("ncm: parse artist err,len:%v type:%T value:%+v", len(v), v, v)
 	}
 
 	var ok bool
@@ -82,7 +84,10 @@ type MetadataMusic struct {
 	Duration      int64         `json:"duration"` // 单位毫秒
 	Format        string        `json:"format"`   // eg: flac
 
-	Comment string `json:"-"` // 为了方便放到此处，此字段不属于ncm内容
+	Comment       string `json:"-"` // 为了方便放到此处，此字段不属于ncm内容
+	ChannelLayout string `json:"-"` // 空间音频声道布局,同上不属于ncm内容
+	Track         int64  `json:"-"` // 曲目序号,同上不属于ncm内容
+	Date          string `json:"-"` // 发行日期 eg: 2024-01-02,同上不属于ncm内容
 }
 
 type MetadataDJ struct {
@@ -32,6 +32,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
 )
 
 // IsNCMFile check whether the file is ncm file
@@ -276,7 +278,7 @@ func decodeCover(rs io.ReadSeeker) ([]byte, int64, error) {
 	}
 	// imgLen <= 0 that means no cover image
 	if imgLen <= 0 {
-		fmt.Println("ncm: invalid cover image length or no cover data")
+		log.Debug("ncm: invalid cover image length or no cover data")
 		return nil, 0, nil
 	}
 
@@ -28,11 +28,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
 	"github.com/chaunsin/netease-cloud-music/pkg/ncm"
 )
 
@@ -107,7 +107,7 @@ func SetMetadata(tag Tagger, imgData []byte, meta *ncm.MetadataMusic) error {
 	if imgData == nil && meta.AlbumPic != "" {
 		coverData, err := fetchUrl(meta.AlbumPic)
 		if err != nil {
-			log.Printf("[ncm] fetch %s err:%s", meta.AlbumPic, err)
+			log.Warn("[ncm] fetch %s err:%s", meta.AlbumPic, err)
 		} else {
 			imgData = coverData
 		}
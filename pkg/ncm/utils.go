@@ -29,6 +29,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
 )
 
 type CoverType string
@@ -92,7 +94,7 @@ func DetectCoverType(data []byte) CoverType {
 	if bytes.HasPrefix(data, gifPrefix) {
 		return CoverTypeGif
 	}
-	fmt.Printf("ncm: unknown magic type string=%s byte=%v", string(data), hex.EncodeToString(data))
+	log.Debug("ncm: unknown magic type string=%s byte=%v", string(data), hex.EncodeToString(data))
 	return CoverTypeUnknown
 }
 
@@ -0,0 +1,179 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package normalize 提供歌曲名、专辑名、歌手名的规范化规则,用于生成文件名及写入tag之前统一处理.
+package normalize
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Field string
+
+const (
+	FieldTitle  Field = "title"
+	FieldAlbum  Field = "album"
+	FieldArtist Field = "artist"
+)
+
+// Replace 正则替换规则
+type Replace struct {
+	Pattern string `json:"pattern" yaml:"pattern"` // 正则表达式
+	Replace string `json:"replace" yaml:"replace"` // 替换内容,支持$1等分组引用
+}
+
+type Config struct {
+	Title     bool      `json:"title" yaml:"title"`         // 是否处理歌曲名
+	Album     bool      `json:"album" yaml:"album"`         // 是否处理专辑名
+	Artist    bool      `json:"artist" yaml:"artist"`       // 是否处理歌手名
+	Halfwidth bool      `json:"halfwidth" yaml:"halfwidth"` // 全角标点符号转换为半角
+	Strip     []string  `json:"strip" yaml:"strip"`         // 去除末尾括号后缀关键字,比如 Explicit 会去除 "(Explicit)"、"[Explicit Version]"
+	Replace   []Replace `json:"replace" yaml:"replace"`     // 正则替换规则,按顺序执行
+}
+
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for _, r := range c.Replace {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("replace pattern %q: %w", r.Pattern, err)
+		}
+	}
+	return nil
+}
+
+type replace struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+type Normalizer struct {
+	cfg     Config
+	strip   *regexp.Regexp
+	replace []replace
+}
+
+// New 创建规范化处理器,cfg为nil时返回的处理器不做任何处理
+func New(cfg *Config) (*Normalizer, error) {
+	var n = &Normalizer{}
+	if cfg == nil {
+		return n, nil
+	}
+	n.cfg = *cfg
+
+	if len(cfg.Strip) > 0 {
+		var keywords = make([]string, 0, len(cfg.Strip))
+		for _, k := range cfg.Strip {
+			if k = strings.TrimSpace(k); k != "" {
+				keywords = append(keywords, regexp.QuoteMeta(k))
+			}
+		}
+		if len(keywords) > 0 {
+			// 匹配末尾由括号包裹且以关键字开头的后缀,比如 " (Deluxe Edition)"、"【Explicit】"
+			n.strip = regexp.MustCompile(`(?i)\s*[(\[（【]\s*(?:` + strings.Join(keywords, "|") + `)\b[^)\]）】]*[)\]）】]\s*$`)
+		}
+	}
+
+	for _, r := range cfg.Replace {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("replace pattern %q: %w", r.Pattern, err)
+		}
+		n.replace = append(n.replace, replace{re: re, repl: r.Replace})
+	}
+	return n, nil
+}
+
+// Enabled 判断字段是否开启了规范化处理
+func (n *Normalizer) Enabled(field Field) bool {
+	switch field {
+	case FieldTitle:
+		return n.cfg.Title
+	case FieldAlbum:
+		return n.cfg.Album
+	case FieldArtist:
+		return n.cfg.Artist
+	}
+	return false
+}
+
+// Apply 对字段值执行规范化处理,执行顺序为 全角转半角 -> 去除括号后缀 -> 正则替换.
+// 字段未开启或处理后结果为空时返回原始值
+func (n *Normalizer) Apply(field Field, s string) string {
+	if !n.Enabled(field) || s == "" {
+		return s
+	}
+	var out = s
+	if n.cfg.Halfwidth {
+		out = Halfwidth(out)
+	}
+	if n.strip != nil {
+		// 可能存在多个后缀,比如 "Song (Explicit) (Deluxe)"
+		for {
+			next := n.strip.ReplaceAllString(out, "")
+			if next == out {
+				break
+			}
+			out = next
+		}
+	}
+	for _, r := range n.replace {
+		out = r.re.ReplaceAllString(out, r.repl)
+	}
+	if out = strings.TrimSpace(out); out == "" {
+		return s
+	}
+	return out
+}
+
+func (n *Normalizer) Title(s string) string {
+	return n.Apply(FieldTitle, s)
+}
+
+func (n *Normalizer) Album(s string) string {
+	return n.Apply(FieldAlbum, s)
+}
+
+func (n *Normalizer) Artist(s string) string {
+	return n.Apply(FieldArtist, s)
+}
+
+// Halfwidth 将全角标点符号及全角空格转换为半角,全角字母数字保持不变
+func Halfwidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			h := r - 0xfee0
+			if ('0' <= h && h <= '9') || ('a' <= h && h <= 'z') || ('A' <= h && h <= 'Z') {
+				return r
+			}
+			return h
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,104 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHalfwidth(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "empty", in: "", want: ""},
+		{name: "ascii", in: "Hello (World)", want: "Hello (World)"},
+		{name: "punct", in: "你好，世界！（Live）", want: "你好,世界!(Live)"},
+		{name: "space", in: "a　b", want: "a b"},
+		{name: "keep letters and digits", in: "ＡＢＣ１２３", want: "ＡＢＣ１２３"},
+		{name: "keep cjk punct", in: "晴天。", want: "晴天。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Halfwidth(tt.in))
+		})
+	}
+}
+
+func TestNormalizer(t *testing.T) {
+	n, err := New(&Config{
+		Title:     true,
+		Album:     true,
+		Halfwidth: true,
+		Strip:     []string{"Explicit", "Deluxe"},
+		Replace: []Replace{
+			{Pattern: `\s*feat\..*$`, Replace: ""},
+			{Pattern: `\s{2,}`, Replace: " "},
+		},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		field Field
+		in    string
+		want  string
+	}{
+		{name: "strip", field: FieldTitle, in: "Song (Explicit)", want: "Song"},
+		{name: "strip case insensitive", field: FieldTitle, in: "Song [explicit version]", want: "Song"},
+		{name: "strip multiple", field: FieldAlbum, in: "Album (Explicit) (Deluxe Edition)", want: "Album"},
+		{name: "strip fullwidth", field: FieldAlbum, in: "专辑（Deluxe）", want: "专辑"},
+		{name: "strip cjk bracket", field: FieldAlbum, in: "专辑【Deluxe】", want: "专辑"},
+		{name: "strip middle untouched", field: FieldTitle, in: "Song (Deluxe) Remix", want: "Song (Deluxe) Remix"},
+		{name: "strip keyword prefix only", field: FieldTitle, in: "Song (Deluxely)", want: "Song (Deluxely)"},
+		{name: "other bracket untouched", field: FieldTitle, in: "Song (Live)", want: "Song (Live)"},
+		{name: "replace", field: FieldTitle, in: "Song feat. Someone", want: "Song"},
+		{name: "replace spaces", field: FieldTitle, in: "A   B", want: "A B"},
+		{name: "halfwidth", field: FieldTitle, in: "你好，世界", want: "你好,世界"},
+		{name: "field disabled", field: FieldArtist, in: "Singer (Explicit)", want: "Singer (Explicit)"},
+		{name: "empty result keep origin", field: FieldTitle, in: "(Explicit)", want: "(Explicit)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, n.Apply(tt.field, tt.in))
+		})
+	}
+}
+
+func TestNil(t *testing.T) {
+	n, err := New(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Song (Explicit)", n.Title("Song (Explicit)"))
+	assert.NoError(t, (*Config)(nil).Validate())
+}
+
+func TestInvalidPattern(t *testing.T) {
+	var cfg = &Config{Title: true, Replace: []Replace{{Pattern: "("}}}
+	assert.Error(t, cfg.Validate())
+	_, err := New(cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package plan 为同步类命令(云盘上传、歌单同步等)提供统一的执行计划及diff风格的dry-run输出.
+package plan
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+)
+
+type Action string
+
+const (
+	ActionAdd    Action = "+"
+	ActionRemove Action = "-"
+	ActionChange Action = "~"
+)
+
+// Item 计划中的一项变更,Size为0时表示大小未知或不适用
+type Item struct {
+	Action  Action
+	Name    string
+	Size    int64 // 变更后大小,删除时为原大小
+	OldSize int64 // 变更前大小,仅 ActionChange 有效
+}
+
+// Plan 执行计划
+type Plan struct {
+	Items []Item
+}
+
+func (p *Plan) Add(name string, size int64) {
+	p.Items = append(p.Items, Item{Action: ActionAdd, Name: name, Size: size})
+}
+
+func (p *Plan) Remove(name string, size int64) {
+	p.Items = append(p.Items, Item{Action: ActionRemove, Name: name, Size: size})
+}
+
+func (p *Plan) Change(name string, oldSize, size int64) {
+	p.Items = append(p.Items, Item{Action: ActionChange, Name: name, Size: size, OldSize: oldSize})
+}
+
+// Empty 是否没有任何变更
+func (p *Plan) Empty() bool {
+	return len(p.Items) <= 0
+}
+
+// Summary 统计新增、删除、修改数量以及执行后大小的变化量
+func (p *Plan) Summary() (added, removed, changed int, delta int64) {
+	for _, v := range p.Items {
+		switch v.Action {
+		case ActionAdd:
+			added++
+			delta += v.Size
+		case ActionRemove:
+			removed++
+			delta -= v.Size
+		case ActionChange:
+			changed++
+			delta += v.Size - v.OldSize
+		}
+	}
+	return
+}
+
+// Write 以diff风格输出计划,最后一行为汇总信息
+func (p *Plan) Write(w io.Writer) error {
+	for _, v := range p.Items {
+		var line = fmt.Sprintf("%s %s", v.Action, v.Name)
+		switch {
+		case v.Action == ActionChange && (v.Size > 0 || v.OldSize > 0):
+			line += fmt.Sprintf(" (%s -> %s)", Size(v.OldSize), Size(v.Size))
+		case v.Action != ActionChange && v.Size > 0:
+			line += fmt.Sprintf(" (%s)", Size(v.Size))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	added, removed, changed, delta := p.Summary()
+	var sign = "+"
+	if delta < 0 {
+		sign, delta = "-", -delta
+	}
+	_, err := fmt.Fprintf(w, "%d added, %d removed, %d changed, size %s%s\n", added, removed, changed, sign, Size(delta))
+	return err
+}
+
+// Diff 对比变更前后的 名称->大小 集合生成计划,结果按名称排序
+func Diff(before, after map[string]int64) *Plan {
+	var names = make([]string, 0, len(before)+len(after))
+	for k := range before {
+		names = append(names, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	var p = new(Plan)
+	for _, name := range names {
+		oldSize, inBefore := before[name]
+		size, inAfter := after[name]
+		switch {
+		case !inBefore:
+			p.Add(name, size)
+		case !inAfter:
+			p.Remove(name, oldSize)
+		case oldSize != size:
+			p.Change(name, oldSize, size)
+		}
+	}
+	return p
+}
+
+// Size 格式化文件大小
+func Size(n int64) string {
+	switch {
+	case n >= utils.GB:
+		return fmt.Sprintf("%.2fG", float64(n)/float64(utils.GB))
+	case n >= utils.MB:
+		return fmt.Sprintf("%.2fM", float64(n)/float64(utils.MB))
+	case n >= utils.KB:
+		return fmt.Sprintf("%.2fK", float64(n)/float64(utils.KB))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
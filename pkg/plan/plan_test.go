@@ -0,0 +1,75 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	var (
+		before = map[string]int64{"a.flac": 10 * utils.MB, "b.mp3": 3 * utils.MB, "c.mp3": 2 * utils.MB}
+		after  = map[string]int64{"a.flac": 10 * utils.MB, "b.mp3": 4 * utils.MB, "d.flac": 20 * utils.MB}
+		p      = Diff(before, after)
+	)
+	assert.Equal(t, []Item{
+		{Action: ActionChange, Name: "b.mp3", Size: 4 * utils.MB, OldSize: 3 * utils.MB},
+		{Action: ActionRemove, Name: "c.mp3", Size: 2 * utils.MB},
+		{Action: ActionAdd, Name: "d.flac", Size: 20 * utils.MB},
+	}, p.Items)
+
+	added, removed, changed, delta := p.Summary()
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, 19*utils.MB, delta)
+
+	assert.True(t, Diff(before, before).Empty())
+}
+
+func TestPlanWrite(t *testing.T) {
+	var p Plan
+	p.Add("new.flac", 30*utils.MB)
+	p.Remove("playlist 123", 0)
+	p.Change("old.mp3", 2*utils.MB, 3*utils.MB/2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, p.Write(&buf))
+	assert.Equal(t, "+ new.flac (30.00M)\n"+
+		"- playlist 123\n"+
+		"~ old.mp3 (2.00M -> 1.50M)\n"+
+		"1 added, 1 removed, 1 changed, size +29.50M\n", buf.String())
+}
+
+func TestSize(t *testing.T) {
+	assert.Equal(t, "0B", Size(0))
+	assert.Equal(t, "512B", Size(512))
+	assert.Equal(t, "1.50K", Size(1536))
+	assert.Equal(t, "1.00G", Size(utils.GB))
+}
@@ -0,0 +1,216 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package scrobbler
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// lastfmBatch track.scrobble 单次最多提交50条记录
+const lastfmBatch = 50
+
+// LastfmConfig Last.fm配置,apiKey及secret在 https://www.last.fm/api/account/create 创建应用获取,
+// sessionKey 通过 ncmctl listens auth 命令授权获取
+type LastfmConfig struct {
+	// Host 接口地址,默认 https://ws.audioscrobbler.com/2.0/
+	Host       string        `json:"host" yaml:"host"`
+	ApiKey     string        `json:"apiKey" yaml:"apiKey"`
+	Secret     string        `json:"secret" yaml:"secret"`
+	SessionKey string        `json:"sessionKey" yaml:"sessionKey"`
+	Timeout    time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+func (c LastfmConfig) Validate() error {
+	if c.ApiKey == "" {
+		return errors.New("apiKey is empty")
+	}
+	if c.Secret == "" {
+		return errors.New("secret is empty")
+	}
+	return nil
+}
+
+type Lastfm struct {
+	cli *resty.Client
+	cfg *LastfmConfig
+}
+
+// NewLastfm 创建Last.fm客户端,授权获取sessionKey时sessionKey可以为空
+func NewLastfm(cfg *LastfmConfig) (*Lastfm, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("lastfm: Validate: %w", err)
+	}
+	var host = cfg.Host
+	if host == "" {
+		host = "https://ws.audioscrobbler.com/2.0/"
+	}
+	cli := resty.New()
+	cli.SetBaseURL(host)
+	cli.SetTimeout(cfg.Timeout)
+	return &Lastfm{cli: cli, cfg: cfg}, nil
+}
+
+func (l *Lastfm) Name() string {
+	return "lastfm"
+}
+
+type lastfmError struct {
+	Error   int64  `json:"error"`
+	Message string `json:"message"`
+}
+
+// sign 按Last.fm规则生成api_sig:参数按名称排序后拼接名称与值,末尾拼接secret后取md5
+func (l *Lastfm) sign(params url.Values) string {
+	var keys = make([]string, 0, len(params))
+	for k := range params {
+		if k == "format" || k == "callback" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteString(params.Get(k))
+	}
+	b.WriteString(l.cfg.Secret)
+	sum := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// call 调用需要签名的接口
+func (l *Lastfm) call(ctx context.Context, method string, params url.Values, result any) error {
+	params.Set("method", method)
+	params.Set("api_key", l.cfg.ApiKey)
+	params.Set("api_sig", l.sign(params))
+	params.Set("format", "json")
+
+	var reply lastfmError
+	resp, err := l.cli.R().
+		SetContext(ctx).
+		SetFormDataFromValues(params).
+		SetResult(result).
+		SetError(&reply).
+		Post("")
+	if err != nil {
+		return fmt.Errorf("lastfm: %s: %w", method, err)
+	}
+	// 部分错误返回200状态码,需要再次检查响应内容
+	if resp.IsSuccess() {
+		_ = json.Unmarshal(resp.Body(), &reply)
+	}
+	if reply.Error != 0 {
+		return fmt.Errorf("lastfm: %s: error %d %s", method, reply.Error, reply.Message)
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("lastfm: %s: http status code: %d", method, resp.StatusCode())
+	}
+	return nil
+}
+
+type lastfmScrobbleResp struct {
+	Scrobbles struct {
+		Attr struct {
+			Accepted int64 `json:"accepted"`
+			Ignored  int64 `json:"ignored"`
+		} `json:"@attr"`
+	} `json:"scrobbles"`
+}
+
+// Scrobble 提交听歌记录,超过50条时分批提交.被Last.fm忽略的记录(比如超过14天)不视为错误
+func (l *Lastfm) Scrobble(ctx context.Context, tracks []Track) error {
+	if l.cfg.SessionKey == "" {
+		return errors.New("lastfm: sessionKey is empty")
+	}
+	for start := 0; start < len(tracks); start += lastfmBatch {
+		var (
+			batch  = tracks[start:min(start+lastfmBatch, len(tracks))]
+			params = url.Values{"sk": {l.cfg.SessionKey}}
+			reply  lastfmScrobbleResp
+		)
+		for i, t := range batch {
+			var idx = "[" + strconv.Itoa(i) + "]"
+			params.Set("artist"+idx, t.Artist)
+			params.Set("track"+idx, t.Title)
+			params.Set("timestamp"+idx, strconv.FormatInt(t.Timestamp.Unix(), 10))
+			if t.Album != "" {
+				params.Set("album"+idx, t.Album)
+			}
+			if t.Duration > 0 {
+				params.Set("duration"+idx, strconv.FormatInt(int64(t.Duration.Seconds()), 10))
+			}
+		}
+		if err := l.call(ctx, "track.scrobble", params, &reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type lastfmTokenResp struct {
+	Token string `json:"token"`
+}
+
+// Token 获取授权token,用户需要在 AuthURL 页面授权后调用 Session 获取sessionKey
+func (l *Lastfm) Token(ctx context.Context) (string, error) {
+	var reply lastfmTokenResp
+	if err := l.call(ctx, "auth.getToken", url.Values{}, &reply); err != nil {
+		return "", err
+	}
+	return reply.Token, nil
+}
+
+// AuthURL 用户授权页面地址
+func (l *Lastfm) AuthURL(token string) string {
+	return fmt.Sprintf("https://www.last.fm/api/auth/?api_key=%s&token=%s", url.QueryEscape(l.cfg.ApiKey), url.QueryEscape(token))
+}
+
+type lastfmSessionResp struct {
+	Session struct {
+		Name string `json:"name"`
+		Key  string `json:"key"`
+	} `json:"session"`
+}
+
+// Session 使用已授权的token获取用户名及sessionKey,sessionKey长期有效
+func (l *Lastfm) Session(ctx context.Context, token string) (name, key string, err error) {
+	var reply lastfmSessionResp
+	if err := l.call(ctx, "auth.getSession", url.Values{"token": {token}}, &reply); err != nil {
+		return "", "", err
+	}
+	return reply.Session.Name, reply.Session.Key, nil
+}
@@ -0,0 +1,99 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package scrobbler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastfmSign(t *testing.T) {
+	l, err := NewLastfm(&LastfmConfig{ApiKey: "key", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("NewLastfm: %s", err)
+	}
+	// md5("api_keykeymethodauth.getTokensecret")
+	var params = url.Values{"method": {"auth.getToken"}, "api_key": {"key"}, "format": {"json"}}
+	if got, want := l.sign(params), "b4705499705a550b07ca058a15bde9b0"; got != want {
+		t.Fatalf("sign = %s, want %s", got, want)
+	}
+}
+
+func TestLastfmScrobble(t *testing.T) {
+	var forms []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		forms = append(forms, r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"scrobbles":{"@attr":{"accepted":1,"ignored":0}}}`))
+	}))
+	defer srv.Close()
+
+	l, err := NewLastfm(&LastfmConfig{Host: srv.URL, ApiKey: "key", Secret: "secret", SessionKey: "sk"})
+	if err != nil {
+		t.Fatalf("NewLastfm: %s", err)
+	}
+	var tracks = make([]Track, 51)
+	for i := range tracks {
+		tracks[i] = Track{Artist: "artist", Title: "title", Album: "album", Duration: 3 * time.Minute, Timestamp: time.Unix(1700000000, 0)}
+	}
+	if err := l.Scrobble(context.Background(), tracks); err != nil {
+		t.Fatalf("Scrobble: %s", err)
+	}
+	if len(forms) != 2 {
+		t.Fatalf("requests = %d, want 2", len(forms))
+	}
+	var form = forms[0]
+	if form.Get("method") != "track.scrobble" || form.Get("sk") != "sk" || form.Get("api_sig") == "" {
+		t.Fatalf("unexpected form: %v", form)
+	}
+	if form.Get("artist[49]") != "artist" || form.Get("timestamp[0]") != "1700000000" || form.Get("duration[0]") != "180" {
+		t.Fatalf("unexpected form: %v", form)
+	}
+	if forms[1].Get("track[0]") != "title" || forms[1].Get("track[1]") != "" {
+		t.Fatalf("unexpected second batch: %v", forms[1])
+	}
+}
+
+func TestLastfmError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"error":9,"message":"Invalid session key - Please re-authenticate"}`))
+	}))
+	defer srv.Close()
+
+	l, err := NewLastfm(&LastfmConfig{Host: srv.URL, ApiKey: "key", Secret: "secret", SessionKey: "sk"})
+	if err != nil {
+		t.Fatalf("NewLastfm: %s", err)
+	}
+	err = l.Scrobble(context.Background(), []Track{{Artist: "a", Title: "t", Timestamp: time.Now()}})
+	if err == nil || !strings.Contains(err.Error(), "error 9") {
+		t.Fatalf("Scrobble err = %v, want error 9", err)
+	}
+}
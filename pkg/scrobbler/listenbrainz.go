@@ -0,0 +1,153 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// listenBrainzBatch 单次提交的记录数量,接口限制最多1000条
+const listenBrainzBatch = 500
+
+// ListenBrainzConfig ListenBrainz配置,token在 https://listenbrainz.org/settings/ 获取
+type ListenBrainzConfig struct {
+	// Host 接口地址,默认 https://api.listenbrainz.org,自建服务时配置为自己的地址
+	Host    string        `json:"host" yaml:"host"`
+	Token   string        `json:"token" yaml:"token"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+func (c ListenBrainzConfig) Validate() error {
+	if c.Token == "" {
+		return errors.New("token is empty")
+	}
+	return nil
+}
+
+type ListenBrainz struct {
+	cli *resty.Client
+	cfg *ListenBrainzConfig
+}
+
+func NewListenBrainz(cfg *ListenBrainzConfig) (*ListenBrainz, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("listenbrainz: Validate: %w", err)
+	}
+	var host = strings.TrimSuffix(cfg.Host, "/")
+	if host == "" {
+		host = "https://api.listenbrainz.org"
+	}
+	cli := resty.New()
+	cli.SetBaseURL(host)
+	cli.SetTimeout(cfg.Timeout)
+	cli.SetHeader("Authorization", "Token "+cfg.Token)
+	return &ListenBrainz{cli: cli, cfg: cfg}, nil
+}
+
+func (l *ListenBrainz) Name() string {
+	return "listenbrainz"
+}
+
+type listenBrainzSubmitReq struct {
+	ListenType string               `json:"listen_type"` // single、import、playing_now
+	Payload    []listenBrainzListen `json:"payload"`
+}
+
+type listenBrainzListen struct {
+	ListenedAt    int64                     `json:"listened_at,omitempty"`
+	TrackMetadata listenBrainzTrackMetadata `json:"track_metadata"`
+}
+
+type listenBrainzTrackMetadata struct {
+	ArtistName     string         `json:"artist_name"`
+	TrackName      string         `json:"track_name"`
+	ReleaseName    string         `json:"release_name,omitempty"`
+	AdditionalInfo map[string]any `json:"additional_info,omitempty"`
+}
+
+type listenBrainzResp struct {
+	Status string `json:"status"`
+	Code   int64  `json:"code"`
+	Error  string `json:"error"`
+}
+
+// Scrobble 提交听歌记录,单条记录使用single类型,多条记录使用import类型分批提交
+func (l *ListenBrainz) Scrobble(ctx context.Context, tracks []Track) error {
+	for start := 0; start < len(tracks); start += listenBrainzBatch {
+		var (
+			batch = tracks[start:min(start+listenBrainzBatch, len(tracks))]
+			req   = listenBrainzSubmitReq{ListenType: "import", Payload: make([]listenBrainzListen, 0, len(batch))}
+			reply listenBrainzResp
+		)
+		if len(tracks) == 1 {
+			req.ListenType = "single"
+		}
+		for _, t := range batch {
+			req.Payload = append(req.Payload, listenBrainzListen{
+				ListenedAt:    t.Timestamp.Unix(),
+				TrackMetadata: t.listenBrainz(),
+			})
+		}
+		resp, err := l.cli.R().
+			SetContext(ctx).
+			SetBody(req).
+			SetResult(&reply).
+			SetError(&reply).
+			Post("/1/submit-listens")
+		if err != nil {
+			return fmt.Errorf("listenbrainz: %w", err)
+		}
+		if !resp.IsSuccess() || reply.Status != "ok" {
+			return fmt.Errorf("listenbrainz: status code: %d error: %s", resp.StatusCode(), reply.Error)
+		}
+	}
+	return nil
+}
+
+// listenBrainz 生成track_metadata,没有MusicBrainz id时通过来源信息辅助匹配
+func (t Track) listenBrainz() listenBrainzTrackMetadata {
+	var info = map[string]any{
+		"media_player":      "ncmctl",
+		"submission_client": "ncmctl",
+		"music_service":     "music.163.com",
+	}
+	if t.Duration > 0 {
+		info["duration_ms"] = t.Duration.Milliseconds()
+	}
+	if t.SongId > 0 {
+		info["origin_url"] = fmt.Sprintf("https://music.163.com/song?id=%d", t.SongId)
+	}
+	return listenBrainzTrackMetadata{
+		ArtistName:     t.Artist,
+		TrackName:      t.Title,
+		ReleaseName:    t.Album,
+		AdditionalInfo: info,
+	}
+}
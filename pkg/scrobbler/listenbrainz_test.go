@@ -0,0 +1,96 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package scrobbler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListenBrainzScrobble(t *testing.T) {
+	var (
+		got  listenBrainzSubmitReq
+		auth string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/submit-listens" {
+			t.Errorf("path = %s, want /1/submit-listens", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	l, err := NewListenBrainz(&ListenBrainzConfig{Host: srv.URL, Token: "token"})
+	if err != nil {
+		t.Fatalf("NewListenBrainz: %s", err)
+	}
+	var track = Track{SongId: 1, Artist: "artist", Title: "title", Album: "album", Duration: time.Minute, Timestamp: time.Unix(1700000000, 0)}
+	if err := l.Scrobble(context.Background(), []Track{track}); err != nil {
+		t.Fatalf("Scrobble: %s", err)
+	}
+	if auth != "Token token" {
+		t.Fatalf("Authorization = %s", auth)
+	}
+	if got.ListenType != "single" || len(got.Payload) != 1 {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	var listen = got.Payload[0]
+	if listen.ListenedAt != 1700000000 || listen.TrackMetadata.ArtistName != "artist" || listen.TrackMetadata.ReleaseName != "album" {
+		t.Fatalf("unexpected listen: %+v", listen)
+	}
+	if listen.TrackMetadata.AdditionalInfo["origin_url"] != "https://music.163.com/song?id=1" {
+		t.Fatalf("unexpected additional_info: %+v", listen.TrackMetadata.AdditionalInfo)
+	}
+
+	if err := l.Scrobble(context.Background(), []Track{track, track}); err != nil {
+		t.Fatalf("Scrobble: %s", err)
+	}
+	if got.ListenType != "import" || len(got.Payload) != 2 {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+}
+
+func TestListenBrainzError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"code":401,"error":"Invalid authorization token."}`))
+	}))
+	defer srv.Close()
+
+	l, err := NewListenBrainz(&ListenBrainzConfig{Host: srv.URL, Token: "token"})
+	if err != nil {
+		t.Fatalf("NewListenBrainz: %s", err)
+	}
+	if err := l.Scrobble(context.Background(), []Track{{Artist: "a", Title: "t", Timestamp: time.Now()}}); err == nil {
+		t.Fatal("Scrobble should fail")
+	}
+}
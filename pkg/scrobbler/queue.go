@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package scrobbler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	queueFile = "queue.json"
+	stateFile = "state.json"
+	// dedupWindow 同一首歌曲在该时间窗口(另加歌曲时长)内的记录视为同一次播放.
+	// 播放模式实时提交的是开始播放时间,同步最近播放提交的是网易云记录的播放时间,两者存在偏差
+	dedupWindow = 10 * time.Minute
+	// stateRetention 去重记录保留时长
+	stateRetention = 30 * 24 * time.Hour
+)
+
+// queue 离线队列及同步进度,以json文件形式保存
+type queue struct {
+	dir string
+}
+
+// state 同步进度及已提交记录
+type state struct {
+	Checkpoint int64            `json:"checkpoint"` // 最近播放同步进度,毫秒时间戳
+	Recent     map[string]int64 `json:"recent"`     // 歌曲id与最近一次提交的播放时间(秒)
+}
+
+func (s *state) seen(t Track) bool {
+	if t.SongId <= 0 {
+		return false
+	}
+	last, ok := s.Recent[strconv.FormatInt(t.SongId, 10)]
+	if !ok {
+		return false
+	}
+	var diff = t.Timestamp.Sub(time.Unix(last, 0)).Abs()
+	return diff < t.Duration+dedupWindow
+}
+
+func (s *state) mark(t Track) {
+	if t.SongId <= 0 {
+		return
+	}
+	s.Recent[strconv.FormatInt(t.SongId, 10)] = t.Timestamp.Unix()
+}
+
+func (q *queue) load() (map[string][]Track, error) {
+	var pending = make(map[string][]Track)
+	if err := q.read(queueFile, &pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func (q *queue) save(pending map[string][]Track) error {
+	if len(pending) <= 0 {
+		if err := os.Remove(filepath.Join(q.dir, queueFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("scrobbler: remove queue: %w", err)
+		}
+		return nil
+	}
+	return q.write(queueFile, pending)
+}
+
+// len 队列中的记录数量
+func (q *queue) len() (int, error) {
+	pending, err := q.load()
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, list := range pending {
+		n += len(list)
+	}
+	return n, nil
+}
+
+func (q *queue) loadState() (*state, error) {
+	var st = state{Recent: make(map[string]int64)}
+	if err := q.read(stateFile, &st); err != nil {
+		return nil, err
+	}
+	if st.Recent == nil {
+		st.Recent = make(map[string]int64)
+	}
+	return &st, nil
+}
+
+func (q *queue) saveState(st *state) error {
+	var expire = time.Now().Add(-stateRetention).Unix()
+	for k, v := range st.Recent {
+		if v < expire {
+			delete(st.Recent, k)
+		}
+	}
+	return q.write(stateFile, st)
+}
+
+func (q *queue) read(name string, v any) error {
+	data, err := os.ReadFile(filepath.Join(q.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("scrobbler: ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("scrobbler: decode %s: %w", name, err)
+	}
+	return nil
+}
+
+// write 先写入临时文件再重命名,避免写入中断导致文件损坏
+func (q *queue) write(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("scrobbler: encode %s: %w", name, err)
+	}
+	var (
+		file = filepath.Join(q.dir, name)
+		tmp  = file + ".tmp"
+	)
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("scrobbler: WriteFile: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("scrobbler: Rename: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package scrobbler 将听歌记录转发到 Last.fm、ListenBrainz 等第三方听歌统计服务.
+// 网易云音乐不提供MusicBrainz id,只提交歌手、歌曲名、专辑及时长信息,由服务自行匹配.
+// 提交失败的记录保存在离线队列中,下次提交时一并重试.
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotConfigured 未配置任何服务
+var ErrNotConfigured = errors.New("scrobbler is not configured")
+
+// Config 听歌记录转发配置
+type Config struct {
+	// Path 离线队列及同步进度保存目录
+	Path         string              `json:"path" yaml:"path"`
+	Lastfm       *LastfmConfig       `json:"lastfm" yaml:"lastfm"`
+	ListenBrainz *ListenBrainzConfig `json:"listenbrainz" yaml:"listenbrainz"`
+}
+
+// Track 一次听歌记录
+type Track struct {
+	SongId    int64         `json:"songId,omitempty"` // 网易云音乐歌曲id,用于去重及生成来源链接
+	Artist    string        `json:"artist"`
+	Title     string        `json:"title"`
+	Album     string        `json:"album,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"` // 歌曲时长
+	Timestamp time.Time     `json:"timestamp"`          // 开始播放时间
+}
+
+func (t Track) String() string {
+	return fmt.Sprintf("%s - %s", t.Artist, t.Title)
+}
+
+// Scrobbler 听歌统计服务
+type Scrobbler interface {
+	Name() string
+	// Scrobble 批量提交听歌记录
+	Scrobble(ctx context.Context, tracks []Track) error
+}
+
+// Eligible 按 Last.fm 规则判断是否需要提交:歌曲时长超过30秒且播放超过一半或4分钟,时长未知时播放超过30秒即可
+func Eligible(duration, played time.Duration) bool {
+	if duration <= 0 {
+		return played >= 30*time.Second
+	}
+	if duration <= 30*time.Second {
+		return false
+	}
+	return played >= min(duration/2, 4*time.Minute)
+}
+
+// Forwarder 将听歌记录转发到所有已配置的服务
+type Forwarder struct {
+	services []Scrobbler
+	queue    *queue
+	mu       sync.Mutex
+}
+
+// New 根据配置创建转发器,未配置任何服务时返回 ErrNotConfigured
+func New(cfg *Config) (*Forwarder, error) {
+	if cfg == nil {
+		return nil, ErrNotConfigured
+	}
+	var services []Scrobbler
+	if cfg.Lastfm != nil && cfg.Lastfm.SessionKey != "" {
+		s, err := NewLastfm(cfg.Lastfm)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, s)
+	}
+	if cfg.ListenBrainz != nil && cfg.ListenBrainz.Token != "" {
+		s, err := NewListenBrainz(cfg.ListenBrainz)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, s)
+	}
+	if len(services) <= 0 {
+		return nil, ErrNotConfigured
+	}
+	return NewForwarder(cfg.Path, services...)
+}
+
+// NewForwarder 使用指定的服务创建转发器,dir为离线队列及同步进度保存目录
+func NewForwarder(dir string, services ...Scrobbler) (*Forwarder, error) {
+	if dir == "" {
+		return nil, errors.New("scrobbler: path is empty")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("scrobbler: MkdirAll: %w", err)
+	}
+	return &Forwarder{services: services, queue: &queue{dir: dir}}, nil
+}
+
+// Services 已配置的服务名称
+func (f *Forwarder) Services() []string {
+	var names = make([]string, 0, len(f.services))
+	for _, s := range f.services {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// Scrobble 提交听歌记录,已提交过的记录会被忽略.同时重试离线队列中的记录,提交失败的记录保存到离线队列.
+// 返回实际提交的记录数量,部分服务提交失败时返回错误
+func (f *Forwarder) Scrobble(ctx context.Context, tracks ...Track) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	st, err := f.queue.loadState()
+	if err != nil {
+		return 0, err
+	}
+	var fresh = make([]Track, 0, len(tracks))
+	for _, t := range tracks {
+		if st.seen(t) {
+			continue
+		}
+		st.mark(t)
+		fresh = append(fresh, t)
+	}
+	// 提交失败的记录已保存到离线队列,同样视为已提交避免重复入队
+	if err := f.queue.saveState(st); err != nil {
+		return 0, err
+	}
+	if err := f.submit(ctx, fresh); err != nil {
+		return len(fresh), err
+	}
+	return len(fresh), nil
+}
+
+// Flush 重试离线队列中的记录,返回队列中剩余的记录数量
+func (f *Forwarder) Flush(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.submit(ctx, nil); err != nil {
+		return 0, err
+	}
+	return f.queue.len()
+}
+
+// submit 将离线队列中的记录与tracks一并提交到各服务,失败的记录重新放回队列
+func (f *Forwarder) submit(ctx context.Context, tracks []Track) error {
+	pending, err := f.queue.load()
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, s := range f.services {
+		var list = append(append([]Track{}, pending[s.Name()]...), tracks...)
+		if len(list) <= 0 {
+			delete(pending, s.Name())
+			continue
+		}
+		if err := s.Scrobble(ctx, list); err != nil {
+			pending[s.Name()] = list
+			errs = append(errs, fmt.Sprintf("%s: %s", s.Name(), err))
+			continue
+		}
+		delete(pending, s.Name())
+	}
+	if err := f.queue.save(pending); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("scrobble failed, saved to queue: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Checkpoint 获取听歌记录同步进度,未同步过时返回零值
+func (f *Forwarder) Checkpoint() (time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st, err := f.queue.loadState()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if st.Checkpoint <= 0 {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(st.Checkpoint), nil
+}
+
+// SetCheckpoint 保存听歌记录同步进度
+func (f *Forwarder) SetCheckpoint(t time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st, err := f.queue.loadState()
+	if err != nil {
+		return err
+	}
+	st.Checkpoint = t.UnixMilli()
+	return f.queue.saveState(st)
+}
@@ -0,0 +1,118 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeScrobbler struct {
+	fail bool
+	got  []Track
+}
+
+func (f *fakeScrobbler) Name() string {
+	return "fake"
+}
+
+func (f *fakeScrobbler) Scrobble(ctx context.Context, tracks []Track) error {
+	if f.fail {
+		return errors.New("offline")
+	}
+	f.got = append(f.got, tracks...)
+	return nil
+}
+
+func TestEligible(t *testing.T) {
+	var tests = []struct {
+		duration, played time.Duration
+		want             bool
+	}{
+		{0, 20 * time.Second, false},
+		{0, 40 * time.Second, true},
+		{20 * time.Second, 20 * time.Second, false},
+		{3 * time.Minute, time.Minute, false},
+		{3 * time.Minute, 2 * time.Minute, true},
+		{20 * time.Minute, 5 * time.Minute, true},
+	}
+	for _, tt := range tests {
+		if got := Eligible(tt.duration, tt.played); got != tt.want {
+			t.Errorf("Eligible(%s, %s) = %v, want %v", tt.duration, tt.played, got, tt.want)
+		}
+	}
+}
+
+func TestForwarder(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		fake = &fakeScrobbler{fail: true}
+		now  = time.Now().Truncate(time.Second)
+	)
+	f, err := NewForwarder(t.TempDir(), fake)
+	if err != nil {
+		t.Fatalf("NewForwarder: %s", err)
+	}
+
+	// 提交失败保存到离线队列
+	var track = Track{SongId: 1, Artist: "artist", Title: "title", Duration: 3 * time.Minute, Timestamp: now}
+	if _, err := f.Scrobble(ctx, track); err == nil {
+		t.Fatal("Scrobble should fail")
+	}
+	if n, err := f.queue.len(); err != nil || n != 1 {
+		t.Fatalf("queue len = %d, %v, want 1", n, err)
+	}
+
+	// 同一次播放的记录被忽略,离线队列中的记录一并提交
+	fake.fail = false
+	var dup = track
+	dup.Timestamp = now.Add(4 * time.Minute)
+	var other = Track{SongId: 2, Artist: "artist", Title: "other", Timestamp: now}
+	n, err := f.Scrobble(ctx, dup, other)
+	if err != nil {
+		t.Fatalf("Scrobble: %s", err)
+	}
+	if n != 1 || len(fake.got) != 2 || fake.got[0].SongId != 1 || fake.got[1].SongId != 2 {
+		t.Fatalf("scrobbled %d, got %+v", n, fake.got)
+	}
+	if left, err := f.Flush(ctx); err != nil || left != 0 {
+		t.Fatalf("Flush = %d, %v, want 0", left, err)
+	}
+
+	// 间隔较长的重复播放正常提交
+	var replay = track
+	replay.Timestamp = now.Add(time.Hour)
+	if n, err := f.Scrobble(ctx, replay); err != nil || n != 1 {
+		t.Fatalf("Scrobble = %d, %v, want 1", n, err)
+	}
+
+	if err := f.SetCheckpoint(now); err != nil {
+		t.Fatalf("SetCheckpoint: %s", err)
+	}
+	if got, err := f.Checkpoint(); err != nil || !got.Equal(now) {
+		t.Fatalf("Checkpoint = %s, %v, want %s", got, err, now)
+	}
+}
@@ -0,0 +1,119 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package unlock
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	Register("kugou", func(cli *http.Client) Provider {
+		return &kugou{cli: cli, host: "http://mobilecdn.kugou.com", tracker: "http://trackercdn.kugou.com"}
+	})
+}
+
+// kugou 酷狗音乐
+type kugou struct {
+	cli     *http.Client
+	host    string
+	tracker string
+}
+
+type kugouSearchResp struct {
+	Status int64 `json:"status"`
+	Data   struct {
+		Info []struct {
+			Hash       string `json:"hash"`
+			SongName   string `json:"songname"`
+			SingerName string `json:"singername"`
+			AlbumName  string `json:"album_name"`
+			Duration   int64  `json:"duration"` // 单位秒
+			HQHash     string `json:"320hash"`
+			SQHash     string `json:"sqhash"`
+		} `json:"info"`
+	} `json:"data"`
+}
+
+type kugouUrlResp struct {
+	Status   int64    `json:"status"`
+	Url      []string `json:"url"`
+	BitRate  int64    `json:"bitRate"`
+	ExtName  string   `json:"extName"`
+	FileSize int64    `json:"fileSize"`
+}
+
+func (k *kugou) Name() string {
+	return "kugou"
+}
+
+func (k *kugou) Search(ctx context.Context, keyword string) ([]Candidate, error) {
+	var (
+		reply kugouSearchResp
+		addr  = fmt.Sprintf("%s/api/v3/search/song?format=json&page=1&pagesize=10&keyword=%s", k.host, url.QueryEscape(keyword))
+	)
+	if err := getJSON(ctx, k.cli, addr, nil, &reply); err != nil {
+		return nil, err
+	}
+	var list = make([]Candidate, 0, len(reply.Data.Info))
+	for _, v := range reply.Data.Info {
+		// 优先使用无损、高品质音源
+		var hash = v.Hash
+		for _, h := range []string{v.SQHash, v.HQHash} {
+			if h != "" {
+				hash = h
+				break
+			}
+		}
+		list = append(list, Candidate{
+			Id:       hash,
+			Name:     v.SongName,
+			Artists:  strings.Split(v.SingerName, "、"),
+			Album:    v.AlbumName,
+			Duration: v.Duration * 1000,
+		})
+	}
+	return list, nil
+}
+
+func (k *kugou) Url(ctx context.Context, c Candidate) (*Stream, error) {
+	var (
+		reply kugouUrlResp
+		sum   = md5.Sum([]byte(strings.ToLower(c.Id) + "kgcloudv2"))
+		addr  = fmt.Sprintf("%s/i/v2/?appid=1005&pid=2&cmd=25&behavior=play&hash=%s&key=%s", k.tracker, c.Id, hex.EncodeToString(sum[:]))
+	)
+	if err := getJSON(ctx, k.cli, addr, nil, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Status != 1 || len(reply.Url) <= 0 {
+		return nil, errors.New("url is empty")
+	}
+	return &Stream{Url: reply.Url[0], Type: strings.ToLower(reply.ExtName), Br: reply.BitRate * 1000, Size: reply.FileSize}, nil
+}
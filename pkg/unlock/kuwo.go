@@ -0,0 +1,101 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package unlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register("kuwo", func(cli *http.Client) Provider {
+		return &kuwo{cli: cli, host: "http://search.kuwo.cn", anti: "http://antiserver.kuwo.cn"}
+	})
+}
+
+// kuwo 酷我音乐
+type kuwo struct {
+	cli  *http.Client
+	host string
+	anti string
+}
+
+type kuwoSearchResp struct {
+	AbsList []struct {
+		MusicRid string `json:"MUSICRID"` // eg: MUSIC_123456
+		SongName string `json:"SONGNAME"`
+		Artist   string `json:"ARTIST"` // 多个歌手以&分隔
+		Album    string `json:"ALBUM"`
+		Duration string `json:"DURATION"` // 单位秒
+	} `json:"abslist"`
+}
+
+func (k *kuwo) Name() string {
+	return "kuwo"
+}
+
+func (k *kuwo) Search(ctx context.Context, keyword string) ([]Candidate, error) {
+	var (
+		reply kuwoSearchResp
+		addr  = fmt.Sprintf("%s/r.s?ft=music&itemset=web_2013&client=kt&rformat=json&encoding=utf8&pn=0&rn=10&all=%s", k.host, url.QueryEscape(keyword))
+	)
+	if err := getJSON(ctx, k.cli, addr, nil, &reply); err != nil {
+		return nil, err
+	}
+	var list = make([]Candidate, 0, len(reply.AbsList))
+	for _, v := range reply.AbsList {
+		duration, _ := strconv.ParseInt(v.Duration, 10, 64)
+		list = append(list, Candidate{
+			Id:       strings.TrimPrefix(v.MusicRid, "MUSIC_"),
+			Name:     v.SongName,
+			Artists:  strings.Split(v.Artist, "&"),
+			Album:    v.Album,
+			Duration: duration * 1000,
+		})
+	}
+	return list, nil
+}
+
+func (k *kuwo) Url(ctx context.Context, c Candidate) (*Stream, error) {
+	var addr = fmt.Sprintf("%s/anti.s?type=convert_url&format=mp3&response=url&rid=MUSIC_%s", k.anti, c.Id)
+	body, err := get(ctx, k.cli, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	var link = strings.TrimSpace(string(body))
+	if !strings.HasPrefix(link, "http") {
+		return nil, errors.New("url is empty")
+	}
+	var ext = strings.TrimPrefix(path.Ext(strings.SplitN(link, "?", 2)[0]), ".")
+	if ext == "" {
+		ext = "mp3"
+	}
+	return &Stream{Url: link, Type: ext}, nil
+}
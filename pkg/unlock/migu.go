@@ -0,0 +1,93 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package unlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+func init() {
+	Register("migu", func(cli *http.Client) Provider { return &migu{cli: cli, host: "https://m.music.migu.cn"} })
+}
+
+// migu 咪咕音乐
+type migu struct {
+	cli  *http.Client
+	host string
+}
+
+type miguSearchResp struct {
+	Musics []struct {
+		CopyrightId string `json:"copyrightId"`
+		SongName    string `json:"songName"`
+		SingerName  string `json:"singerName"` // 多个歌手以,分隔
+		AlbumName   string `json:"albumName"`
+		Mp3         string `json:"mp3"`
+	} `json:"musics"`
+}
+
+func (m *migu) Name() string {
+	return "migu"
+}
+
+func (m *migu) Search(ctx context.Context, keyword string) ([]Candidate, error) {
+	var (
+		reply   miguSearchResp
+		addr    = fmt.Sprintf("%s/migu/remoting/scr_search_tag?type=2&rows=10&pgc=1&keyword=%s", m.host, url.QueryEscape(keyword))
+		headers = map[string]string{"Referer": m.host + "/"}
+	)
+	if err := getJSON(ctx, m.cli, addr, headers, &reply); err != nil {
+		return nil, err
+	}
+	var list = make([]Candidate, 0, len(reply.Musics))
+	for _, v := range reply.Musics {
+		list = append(list, Candidate{
+			Id:      v.CopyrightId,
+			Name:    v.SongName,
+			Artists: strings.Split(v.SingerName, ","),
+			Album:   v.AlbumName,
+			Url:     v.Mp3,
+		})
+	}
+	return list, nil
+}
+
+func (m *migu) Url(ctx context.Context, c Candidate) (*Stream, error) {
+	// 搜索结果中已经包含资源地址
+	var link = c.Url
+	if link == "" {
+		return nil, errors.New("url is empty")
+	}
+	var ext = strings.TrimPrefix(path.Ext(strings.SplitN(link, "?", 2)[0]), ".")
+	if ext == "" {
+		ext = "mp3"
+	}
+	return &Stream{Url: link, Type: ext}, nil
+}
@@ -0,0 +1,240 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package unlock 当网易云歌曲无版权或已下架时,从第三方平台(酷狗、酷我、咪咕等)查找同一首歌曲的音源作为替代。
+// 第三方平台通过 Provider 接口适配,新增平台只需实现该接口并调用 Register 注册。
+package unlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ErrNotFound 所有音源均未找到匹配的歌曲
+var ErrNotFound = errors.New("no matched source")
+
+// Song 需要查找的歌曲信息
+type Song struct {
+	Name     string
+	Artists  []string
+	Album    string
+	Duration int64 // 歌曲时长,单位毫秒,为0时不校验时长
+}
+
+// Keyword 搜索关键字
+func (s Song) Keyword() string {
+	return strings.TrimSpace(s.Name + " " + strings.Join(s.Artists, " "))
+}
+
+// Candidate 第三方平台搜索结果
+type Candidate struct {
+	Id       string // 第三方平台歌曲id
+	Name     string
+	Artists  []string
+	Album    string
+	Duration int64  // 歌曲时长,单位毫秒,未知为0
+	Url      string // 搜索结果中直接返回的资源地址,部分平台才有
+}
+
+// Stream 第三方平台音源信息
+type Stream struct {
+	Provider string // 音源平台名称
+	Id       string // 第三方平台歌曲id
+	Url      string // 资源地址
+	Type     string // 文件类型 eg: mp3、flac
+	Br       int64  // 码率,未知为0
+	Size     int64  // 文件大小单位字节,未知为0
+	Md5      string // 文件MD5值,未知为空
+}
+
+// Provider 第三方音源平台
+type Provider interface {
+	// Name 平台名称
+	Name() string
+	// Search 根据关键字搜索歌曲
+	Search(ctx context.Context, keyword string) ([]Candidate, error)
+	// Url 获取歌曲资源地址
+	Url(ctx context.Context, c Candidate) (*Stream, error)
+}
+
+// Factory 根据http客户端创建 Provider
+type Factory func(cli *http.Client) Provider
+
+var (
+	mux       sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register 注册第三方音源平台,已存在时覆盖
+func Register(name string, f Factory) {
+	mux.Lock()
+	defer mux.Unlock()
+	factories[name] = f
+}
+
+// Providers 返回已注册的平台名称
+func Providers() []string {
+	mux.RLock()
+	defer mux.RUnlock()
+	return providerNames()
+}
+
+// DurationTolerance 匹配歌曲时允许的时长误差,单位毫秒
+const DurationTolerance = 10 * 1000
+
+type Unlocker struct {
+	providers []Provider
+}
+
+// New 按names顺序创建音源平台,查找时依次尝试,cli为空时使用 http.DefaultClient
+func New(cli *http.Client, names ...string) (*Unlocker, error) {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	mux.RLock()
+	defer mux.RUnlock()
+	var u Unlocker
+	for _, name := range names {
+		f, ok := factories[name]
+		if !ok {
+			return nil, fmt.Errorf("provider %s not found, support: %s", name, strings.Join(providerNames(), ","))
+		}
+		u.providers = append(u.providers, f(cli))
+	}
+	if len(u.providers) <= 0 {
+		return nil, errors.New("providers is empty")
+	}
+	return &u, nil
+}
+
+// NewWithProviders 使用自定义的音源平台创建
+func NewWithProviders(providers ...Provider) *Unlocker {
+	return &Unlocker{providers: providers}
+}
+
+func providerNames() []string {
+	var names = make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find 依次从各平台查找匹配的歌曲并返回资源地址,单个平台出错时继续尝试下一个平台
+func (u *Unlocker) Find(ctx context.Context, song Song) (*Stream, error) {
+	var errs []error
+	for _, p := range u.providers {
+		list, err := p.Search(ctx, song.Keyword())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s search: %w", p.Name(), err))
+			continue
+		}
+		for _, c := range Match(song, list) {
+			s, err := p.Url(ctx, c)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s url(%s): %w", p.Name(), c.Id, err))
+				continue
+			}
+			if s == nil || s.Url == "" {
+				continue
+			}
+			s.Provider, s.Id = p.Name(), c.Id
+			return s, nil
+		}
+	}
+	return nil, errors.Join(append([]error{ErrNotFound}, errs...)...)
+}
+
+// Match 过滤出歌名相同、歌手有交集且时长误差在 DurationTolerance 内的候选歌曲,歌手及时长越接近越靠前
+func Match(song Song, list []Candidate) []Candidate {
+	type scored struct {
+		c     Candidate
+		score int64
+	}
+	var (
+		name    = normalize(song.Name)
+		artists = make(map[string]struct{}, len(song.Artists))
+		matched []scored
+	)
+	for _, ar := range song.Artists {
+		artists[normalize(ar)] = struct{}{}
+	}
+	for _, c := range list {
+		if normalize(c.Name) != name {
+			continue
+		}
+		var hit bool
+		for _, ar := range c.Artists {
+			if _, ok := artists[normalize(ar)]; ok {
+				hit = true
+				break
+			}
+		}
+		if len(artists) > 0 && !hit {
+			continue
+		}
+		var diff int64
+		if song.Duration > 0 && c.Duration > 0 {
+			diff = song.Duration - c.Duration
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > DurationTolerance {
+				continue
+			}
+		}
+		var score = DurationTolerance - diff
+		if c.Album != "" && normalize(c.Album) == normalize(song.Album) {
+			score += DurationTolerance
+		}
+		matched = append(matched, scored{c: c, score: score})
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].score > matched[j].score })
+	var result = make([]Candidate, 0, len(matched))
+	for _, m := range matched {
+		result = append(result, m.c)
+	}
+	return result
+}
+
+var bracketReg = regexp.MustCompile(`[(（\[【].*?[)）\]】]`)
+
+// normalize 去除括号内容、空白及标点并转为小写,用于比较不同平台的歌名
+func normalize(s string) string {
+	s = bracketReg.ReplaceAllString(s, "")
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
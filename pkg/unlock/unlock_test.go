@@ -0,0 +1,119 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package unlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProvider struct {
+	name string
+	list []Candidate
+	err  error
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) Search(ctx context.Context, keyword string) ([]Candidate, error) {
+	return f.list, f.err
+}
+
+func (f *fakeProvider) Url(ctx context.Context, c Candidate) (*Stream, error) {
+	return &Stream{Url: "http://example.com/" + c.Id + ".mp3", Type: "mp3"}, nil
+}
+
+func TestMatch(t *testing.T) {
+	var song = Song{Name: "晴天", Artists: []string{"周杰伦"}, Album: "叶惠美", Duration: 269000}
+	var list = []Candidate{
+		{Id: "1", Name: "晴天", Artists: []string{"其他歌手"}, Duration: 269000},
+		{Id: "2", Name: "晴天 (Live)", Artists: []string{"周杰伦"}, Duration: 300000},
+		{Id: "3", Name: "晴天", Artists: []string{"周杰伦"}, Duration: 265000},
+		{Id: "4", Name: "晴天", Artists: []string{"周杰伦"}, Album: "叶惠美", Duration: 268000},
+		{Id: "5", Name: "雨天", Artists: []string{"周杰伦"}, Duration: 269000},
+		{Id: "6", Name: "晴天", Artists: []string{"周杰伦", "其他"}},
+	}
+	var ids []string
+	for _, c := range Match(song, list) {
+		ids = append(ids, c.Id)
+	}
+	assert.Equal(t, []string{"4", "6", "3"}, ids)
+}
+
+func TestFind(t *testing.T) {
+	var (
+		song = Song{Name: "Animals", Artists: []string{"Maroon 5"}, Duration: 231000}
+		u    = NewWithProviders(
+			&fakeProvider{name: "error", err: errors.New("timeout")},
+			&fakeProvider{name: "empty", list: []Candidate{{Id: "1", Name: "Other", Artists: []string{"Maroon 5"}}}},
+			&fakeProvider{name: "ok", list: []Candidate{{Id: "2", Name: "animals", Artists: []string{"maroon 5"}, Duration: 231500}}},
+		)
+	)
+	s, err := u.Find(context.Background(), song)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", s.Provider)
+	assert.Equal(t, "2", s.Id)
+	assert.Equal(t, "http://example.com/2.mp3", s.Url)
+
+	_, err = NewWithProviders(&fakeProvider{name: "empty"}).Find(context.Background(), song)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, []string{"kugou", "kuwo", "migu"}, Providers())
+	u, err := New(nil, "kuwo", "kugou")
+	assert.NoError(t, err)
+	assert.Len(t, u.providers, 2)
+	_, err = New(nil, "unknown")
+	assert.Error(t, err)
+	_, err = New(nil)
+	assert.Error(t, err)
+}
+
+func TestKuwo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/r.s":
+			assert.Equal(t, "晴天 周杰伦", r.URL.Query().Get("all"))
+			fmt.Fprint(w, `{"abslist":[{"MUSICRID":"MUSIC_123","SONGNAME":"晴天","ARTIST":"周杰伦","ALBUM":"叶惠美","DURATION":"269"}]}`)
+		case "/anti.s":
+			assert.Equal(t, "MUSIC_123", r.URL.Query().Get("rid"))
+			fmt.Fprint(w, "http://other.example.com/a/123.mp3?k=v\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var u = NewWithProviders(&kuwo{cli: srv.Client(), host: srv.URL, anti: srv.URL})
+	s, err := u.Find(context.Background(), Song{Name: "晴天", Artists: []string{"周杰伦"}, Duration: 269000})
+	assert.NoError(t, err)
+	assert.Equal(t, &Stream{Provider: "kuwo", Id: "123", Url: "http://other.example.com/a/123.mp3?k=v", Type: "mp3"}, s)
+}
@@ -303,3 +303,33 @@ func IsGzipHeader(data []byte) bool {
 	// 压缩方法校验（0x08 = DEFLATE）
 	return data[2] == 0x08
 }
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTime 解析时间字符串,支持unix时间戳(秒或毫秒)、RFC3339以及常见的日期格式,
+// 不带时区的格式使用本地时区解析
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("time is empty")
+	}
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// 13位及以上认为是毫秒
+		if ts >= 1e12 {
+			return time.UnixMilli(ts), nil
+		}
+		return time.Unix(ts, 0), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format: %s", value)
+}
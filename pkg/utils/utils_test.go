@@ -572,3 +572,31 @@ func TestExpandTilde(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "empty", input: "", wantErr: true},
+		{name: "invalid", input: "yesterday", wantErr: true},
+		{name: "unix", input: "1717200000", want: time.Unix(1717200000, 0)},
+		{name: "unix milli", input: "1717200000123", want: time.UnixMilli(1717200000123)},
+		{name: "rfc3339", input: "2024-06-01T08:00:00+08:00", want: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "datetime", input: "2024-06-01 12:30:05", want: time.Date(2024, 6, 1, 12, 30, 5, 0, time.Local)},
+		{name: "date", input: " 2024-06-01 ", want: time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "ParseTime(%v) = %v, want %v", tt.input, got, tt.want)
+		})
+	}
+}